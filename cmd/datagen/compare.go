@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"log"
+	"strings"
+
+	"github.com/codegangsta/cli"
+)
+
+var (
+	compareFlag = cli.StringFlag{
+		Name:  "compare",
+		Usage: "func(a, b KEY) int ordering the keys, instead of their builtin order or Compare method",
+	}
+	lessFlag = cli.StringFlag{
+		Name:  "less",
+		Usage: "func(a, b KEY) bool ordering the keys, instead of their builtin order or Compare method",
+	}
+	compareFieldFlag = cli.BoolFlag{
+		Name:  "compare-field",
+		Usage: "give the func(a, b KEY) int ordering the keys to the constructor, so that each instance can have its own order",
+	}
+)
+
+// orderFlags are understood by the commands generating datastructures that
+// order their keys.
+var orderFlags = []cli.Flag{compareFlag, lessFlag, compareFieldFlag}
+
+// order returns the source of the `compare` method for ktype, on receiver
+// recv (such as "h Heap"), as chosen with the flags, and the imports it needs.
+// If field is true, the comparison is a field of the datastructure. Like with
+// compareFunc, an empty source means the method of the template is kept.
+func order(ctx *cli.Context, recv string, ktype *typeExpr) (src string, imports []string, field bool) {
+	cmp := ctx.String(compareFlag.Name)
+	less := ctx.String(lessFlag.Name)
+	field = ctx.Bool(compareFieldFlag.Name)
+
+	given := 0
+	for _, set := range []bool{cmp != "", less != "", field} {
+		if set {
+			given++
+		}
+	}
+	if given > 1 {
+		log.Fatalf("only one of -%s, -%s and -%s can be given",
+			compareFlag.Name, lessFlag.Name, compareFieldFlag.Name)
+	}
+
+	recvName := strings.Fields(recv)[0]
+	switch {
+	case field:
+		src = fmt.Sprintf("func (%s) compare(a, b KType) int { return %s.compareFunc(a, b) }", recv, recvName)
+		return src, nil, true
+
+	case cmp != "":
+		fn, imports := funcOrDefault(compareFlag, cmp)
+		src = fmt.Sprintf("func (%s) compare(a, b KType) int { return %s(a, b) }", recv, fn)
+		return src, imports, false
+
+	case less != "":
+		fn, imports := funcOrDefault(lessFlag, less)
+		src = fmt.Sprintf(`
+func (%[1]s) compare(a, b KType) int {
+	if %[2]s(a, b) {
+		return -1
+	}
+	if %[2]s(b, a) {
+		return 1
+	}
+	return 0
+}`, recv, fn)
+		return src, imports, false
+	}
+
+	src, imports = compareFunc(recv, ktype.expr)
+	return src, imports, false
+}
+
+// funcOrDefault parses the name of the func given to flag f, which can be
+// qualified by the package declaring it.
+func funcOrDefault(f cli.StringFlag, name string) (string, []string) {
+	expr, imports, err := parseQualified(name)
+	if err != nil {
+		log.Fatalf("-%s: invalid func %q: %v", f.Name, name, err)
+	}
+	switch expr.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+	default:
+		log.Fatalf("-%s: %q is not the name of a func", f.Name, name)
+	}
+	return types.ExprString(expr), imports
+}
+
+// compareFunc returns the source of a `compare` method for ktype, on receiver
+// recv (such as "h Heap"), and the imports it needs. If ktype has no builtin
+// ordering, the method of the template is kept and an empty source is
+// returned; ktype then needs to implement a Compare func.
+func compareFunc(recv, ktype string) (src string, imports []string) {
+	switch ktype {
+
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"byte", "rune", "string":
+		// subtracting could overflow, compare instead
+		src = `
+func (%s) compare(a, b KType) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}`
+
+	case "float32", "float64":
+		src = `
+// compare orders NaNs before any other value, including -Inf, and considers
+// them equal to each other. -0 and +0 are equal.
+func (%s) compare(a, b KType) int {
+	aNaN, bNaN := a != a, b != b
+	switch {
+	case aNaN && bNaN:
+		return 0
+	case aNaN:
+		return -1
+	case bNaN:
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}`
+
+	case "[]byte":
+		log.Printf("WARNING: using []byte as keys can lead to undefined behavior if the []byte are modified after insertion!!!")
+		src = `// WARNING: using []byte as keys can lead to undefined behavior if the
+// []byte are modified after insertion!!!
+func (%s) compare(a, b KType) int { return bytes.Compare(a, b) }`
+		imports = []string{"bytes"}
+
+	default:
+
+		// if storing slices, use `len()` for comparison
+		if len(ktype) > 2 && ktype[:2] == "[]" {
+			log.Printf("%s: order will be determined based on value of len(%s)", ktype, ktype)
+			src = "func (%s) compare(a, b KType) int { return len(a)-len(b) }"
+		} else {
+			l := 0
+			if []rune(ktype)[0] == '*' {
+				l = 1
+			}
+			// otherwise don't change anything by default, let the user
+			// provide a `Compare` func
+			log.Printf("type %q will need to implement a Compare func, or be ordered with -compare or -less: %s",
+				ktype,
+				fmt.Sprintf(`
+	func (%[1]s %s) Compare(other %s) int {
+		if %[1]s > other {
+			return 1
+		} else if %[1]s < other {
+			return -1
+		}
+		return 0
+	}`, strings.ToLower(ktype[l:l+1]), ktype, ktype))
+			return "", nil
+		}
+
+	}
+
+	return fmt.Sprintf(src, recv), imports
+}
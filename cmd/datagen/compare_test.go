@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	texttemplate "text/template"
+)
+
+// compareTests lists, for each builtin type, groups of values in increasing
+// order. Values in a group compare equal.
+var compareTests = []struct {
+	ktype  string
+	groups [][]string
+}{
+	{"int", [][]string{{"math.MinInt64"}, {"-1"}, {"0"}, {"1"}, {"math.MaxInt64"}}},
+	{"int8", [][]string{{"math.MinInt8"}, {"-1"}, {"0"}, {"1"}, {"math.MaxInt8"}}},
+	{"int16", [][]string{{"math.MinInt16"}, {"-1"}, {"0"}, {"1"}, {"math.MaxInt16"}}},
+	{"int32", [][]string{{"math.MinInt32"}, {"-1"}, {"0"}, {"1"}, {"math.MaxInt32"}}},
+	{"int64", [][]string{{"math.MinInt64"}, {"math.MinInt64 + 1"}, {"-1"}, {"0"}, {"1"}, {"math.MaxInt64 - 1"}, {"math.MaxInt64"}}},
+	{"uint", [][]string{{"0"}, {"1"}, {"math.MaxUint64 / 2"}, {"math.MaxUint64/2 + 1"}, {"math.MaxUint64"}}},
+	{"uint8", [][]string{{"0"}, {"1"}, {"math.MaxInt8"}, {"math.MaxInt8 + 1"}, {"math.MaxUint8"}}},
+	{"uint16", [][]string{{"0"}, {"1"}, {"math.MaxInt16"}, {"math.MaxInt16 + 1"}, {"math.MaxUint16"}}},
+	{"uint32", [][]string{{"0"}, {"1"}, {"math.MaxInt32"}, {"math.MaxInt32 + 1"}, {"math.MaxUint32"}}},
+	{"uint64", [][]string{{"0"}, {"1"}, {"math.MaxInt64"}, {"math.MaxInt64 + 1"}, {"math.MaxUint64"}}},
+	{"uintptr", [][]string{{"0"}, {"1"}, {"math.MaxUint32"}}},
+	{"byte", [][]string{{"0"}, {"'a'"}, {"math.MaxUint8"}}},
+	{"rune", [][]string{{"math.MinInt32"}, {"0"}, {"'a'"}, {"'é'"}, {"math.MaxInt32"}}},
+	{"string", [][]string{{`""`}, {`"A"`}, {`"a"`}, {`"aa"`}, {`"b"`}}},
+	{"float32", [][]string{
+		{"float32(math.NaN())", "-float32(math.NaN())"},
+		{"float32(math.Inf(-1))"},
+		{"-math.MaxFloat32"},
+		{"-1"},
+		{"-math.SmallestNonzeroFloat32"},
+		{"0", "float32(math.Copysign(0, -1))"},
+		{"math.SmallestNonzeroFloat32"},
+		{"1"},
+		{"1 + 1e-7"},
+		{"math.MaxFloat32"},
+		{"float32(math.Inf(1))"},
+	}},
+	{"float64", [][]string{
+		{"math.NaN()", "-math.NaN()"},
+		{"math.Inf(-1)"},
+		{"-math.MaxFloat64"},
+		{"-1"},
+		{"-math.SmallestNonzeroFloat64"},
+		{"0", "math.Copysign(0, -1)"},
+		{"math.SmallestNonzeroFloat64"},
+		{"1"},
+		{"math.Nextafter(1, 2)"},
+		{"math.MaxFloat64"},
+		{"math.Inf(1)"},
+	}},
+}
+
+// compareTestSrc checks the generated comparators and, through a sorted set,
+// that equal values are merged and that the others are kept in order.
+const compareTestSrc = `package cmptest
+
+import (
+	"math"
+	"testing"
+)
+
+func sign(i int) int {
+	switch {
+	case i < 0:
+		return -1
+	case i > 0:
+		return 1
+	}
+	return 0
+}
+{{range .}}
+func Test{{.Name}}(t *testing.T) {
+	groups := [][]{{.KType}}{ {{range .Groups}}
+		{ {{range .}}{{.}}, {{end}} },{{end}}
+	}
+	set := NewSorted{{.Name}}Set()
+	for i := len(groups) - 1; i >= 0; i-- {
+		for _, a := range groups[i] {
+			set.Put(a)
+		}
+	}
+	for i, gi := range groups {
+		for j, gj := range groups {
+			for _, a := range gi {
+				for _, b := range gj {
+					if got, want := sign(set.compare(a, b)), sign(i-j); got != want {
+						t.Errorf("compare(%v, %v): want %d, got %d", a, b, want, got)
+					}
+				}
+			}
+		}
+		if k, ok := set.Select(i); !ok || set.compare(k, gi[0]) != 0 {
+			t.Errorf("select %d: want %v, got %v", i, gi[0], k)
+		}
+	}
+	if set.Size() != len(groups) {
+		t.Errorf("want size %d, got %d", len(groups), set.Size())
+	}
+}
+{{end}}`
+
+func TestBuiltinComparators(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs the generated code")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	dir := t.TempDir()
+	out := &output{dir: dir, pkgname: "cmptest"}
+	writeFile(t, filepath.Join(dir, "go.mod"), []byte("module cmptest\n"))
+
+	type testCase struct {
+		Name, KType string
+		Groups      [][]string
+	}
+	var cases []testCase
+	for _, tt := range compareTests {
+		ktype, err := parseType(tt.ktype)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := ktype.name
+		compare, imports := compareFunc("r RedBlack", ktype.expr)
+		if compare == "" {
+			t.Fatalf("%s: no builtin comparator", tt.ktype)
+		}
+		tmpl := &template{
+			src:    redblackbstSetSrc,
+			params: map[string]string{"KType": ktype.expr},
+			renames: map[string]string{
+				"RedBlack":                "Sorted" + name + "Set",
+				"NewRedBlack":             "NewSorted" + name + "Set",
+				"NewRedBlackFromSorted":   "NewSorted" + name + "SetFromSorted",
+				"NewRedBlackFromUnsorted": "NewSorted" + name + "SetFromUnsorted",
+				"treenode":                "node" + name,
+				"Cursor":                  name + "Cursor",
+				"Bound":                   name + "Bound",
+			},
+			compare: compare,
+			imports: imports,
+		}
+		src, err := tmpl.instantiate(out.pkgname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if src, err = render(out, src); err != nil {
+			t.Fatalf("%s: %v", tt.ktype, err)
+		}
+		writeFile(t, filepath.Join(dir, strings.ToLower(name)+".go"), src)
+		cases = append(cases, testCase{Name: name, KType: ktype.expr, Groups: tt.groups})
+	}
+
+	var test bytes.Buffer
+	if err := texttemplate.Must(texttemplate.New("").Parse(compareTestSrc)).Execute(&test, cases); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "compare_test.go"), test.Bytes())
+
+	cmd := exec.Command(gobin, "test", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GO111MODULE=on", "GOFLAGS=")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, output)
+	}
+}
+
+func writeFile(t *testing.T, filename string, data []byte) {
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
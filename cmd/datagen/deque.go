@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/codegangsta/cli"
+)
+
+func deque() cli.Command {
+
+	keyTypeFlag := cli.StringFlag{
+		Name:  "key",
+		Usage: "type that will be held in the deque",
+	}
+
+	return cli.Command{
+		Name:      "deque",
+		ShortName: "dq",
+		Usage:     "Create a double-ended queue customized for your types.",
+		Description: `Create a double-ended queue customized for your types. The
+implementation is the ring buffer of the queue, with pushes and pops at both
+ends in O(1), and indexed access, insertion and removal anywhere in it.
+With -sync, a wrapper safe for concurrent use is generated too.
+With -tests and -bench, the tests and benchmarks are generated for your
+types too.`,
+		Flags: append(append([]cli.Flag{keyTypeFlag}, testFlags...), commonFlags...),
+		Action: func(ctx *cli.Context) {
+			ktype := typeOrDefault(ctx, keyTypeFlag)
+
+			typeName := nameOrDefault(ctx, ktype.name+"Deque")
+
+			tmpl := &template{
+				name:   "Deque",
+				src:    dequeSrc,
+				params: map[string]string{"KType": ktype.expr},
+				renames: map[string]string{
+					"Deque":    typeName,
+					"NewDeque": "New" + typeName,
+					"nilKType": "nil" + typeName,
+				},
+				imports: ktype.imports,
+			}
+
+			desc := fmt.Sprintf("deque -key=%q", ktype.expr)
+			if syncTemplate(ctx, tmpl, typeName, dequeReaders...) {
+				desc += " -sync"
+			}
+
+			tests := testTemplate(ctx, tmpl, dequeTestSrc, typeName, sampledKeys(ktype))
+			bench := benchTemplate(ctx, tmpl, dequeBenchSrc, typeName, sampledKeys(ktype))
+			emit(ctx, desc, tmpl, tests, bench)
+		},
+	}
+}
+
+// dequeReaders are the methods of the deque that don't modify it.
+var dequeReaders = []string{"Len", "PeekFront", "TryPeekFront", "PeekBack", "TryPeekBack", "Get"}
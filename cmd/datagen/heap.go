@@ -1,12 +1,8 @@
 package main
 
 import (
-	"bytes"
 	"fmt"
 	"log"
-	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/codegangsta/cli"
 )
@@ -24,112 +20,84 @@ func heap() cli.Command {
 		Usage:     "Create a heap (priority queue) customized for your types.",
 		Description: `Create a heap customized for your types. The implementation
 has good performance and is well tested, with 100% test coverage.
-(the tests are not generated with the custom type)`,
-		Flags: []cli.Flag{keyTypeFlag},
+It is a max-heap, unless -order=min is given. With -sync, a wrapper safe for
+concurrent use is generated too.
+With -tests and -bench, the tests and benchmarks are generated for your
+types too.`,
+		Flags: append(append(append([]cli.Flag{keyTypeFlag, heapOrderFlag}, orderFlags...), testFlags...), commonFlags...),
 		Action: func(ctx *cli.Context) {
-			ktype := valOrDefault(ctx, keyTypeFlag)
+			ktype := typeOrDefault(ctx, keyTypeFlag)
+
+			desc := fmt.Sprintf("heap -key=%q", ktype.expr)
+			suffix := "Heap"
+			minFirst := isMinHeap(ctx)
+			if minFirst {
+				suffix = "MinHeap"
+				desc += " -order=min"
+			}
 
-			kname := ktype
-			if len(kname) > 1 && []byte(kname)[0] == '*' {
-				kname = kname[1:]
+			typeName := nameOrDefault(ctx, ktype.name+suffix)
+
+			compare, imports, field := order(ctx, "h Heap", ktype)
+			tmpl := &template{
+				name:   "Heap",
+				src:    heapSrc,
+				params: map[string]string{"KType": ktype.expr},
+				renames: map[string]string{
+					"Heap":    typeName,
+					"NewHeap": "New" + typeName,
+				},
+				compare:      compare,
+				compareField: field,
+				imports:      append(imports, ktype.imports...),
 			}
-			if len(kname) > 2 && kname[:2] == "[]" {
-				kname = strings.Title(kname[2:]) + "s"
+			if minFirst {
+				minHeap(tmpl)
+			}
+			if syncTemplate(ctx, tmpl, typeName, heapReaders...) {
+				desc += " -sync"
 			}
 
-			typeName := fmt.Sprintf("%sHeap", strings.Title(kname))
-
-			cwd, _ := os.Getwd()
-			pkgname := fmt.Sprintf("package %s", filepath.Base(cwd))
-
-			src := []byte(heapSrc)
-			src = bytes.Replace(src, []byte("package heap"), []byte(pkgname), 1)
-
-			// need to replace Compare before replacing KType
-			src = replaceHeapCompareFunc(ktype, src)
-			src = bytes.Replace(src, []byte("KType"), []byte(ktype), -1)
-			src = bytes.Replace(src, []byte("Heap"), []byte(typeName), -1)
-
-			fmt.Println(string(src))
+			tests := testTemplate(ctx, tmpl, heapTestSrc, typeName, sampledKeys(ktype))
+			bench := benchTemplate(ctx, tmpl, heapBenchSrc, typeName, sampledKeys(ktype))
+			emit(ctx, desc, tmpl, tests, bench)
 		},
 	}
 }
 
-func replaceHeapCompareFunc(ktype string, src []byte) []byte {
-	var tmpl string
-	orig := "func (h Heap) compare(a, b KType) int { return a.Compare(b) }"
-
-	switch ktype {
-
-	case "int", "int8", "int16", "int32", "int64",
-		"uint", "uint8", "uint16", "uint32", "uint64":
-		tmpl = "func (h Heap) compare(a, b KType) int { return int(a) - int(b) }"
-
-	case "float32", "float64":
-		tmpl = `
-func (h Heap) compare(a, b KType) int {
-	const e = 0.00000001
-
-    diff := (a-b)/a
-    if diff < -e {
-        return -1
-    } else if diff > e {
-        return 1
-    }
-    return 0
-}`
+// heapReaders are the methods of the heap that don't modify it.
+var heapReaders = []string{"Len", "Peek", "TryPeek"}
 
-	case "string":
-		tmpl = `
-func (h Heap) compare(a, b KType) int {
-    if a < b {
-        return -1
-    }
-    if a > b {
-        return 1
-    }
-    return 0
-}`
-
-	case "[]byte":
-		log.Printf("WARNING: using []byte as keys can lead to undefined behavior if the []byte are modified after insertion!!!")
-		tmpl = `import "bytes"
-
-// WARNING: using []byte as keys can lead to undefined behavior if the
-// []byte are modified after insertion!!!
-func (h Heap) compare(a, b KType) int { return bytes.Compare(a, b) }`
+// heapOrderFlag chooses the key coming out of a heap first.
+var heapOrderFlag = cli.StringFlag{
+	Name:  "order",
+	Value: "max",
+	Usage: "whether the largest (max) or the smallest (min) key comes out of the heap first",
+}
 
+// isMinHeap tells if a min-heap was asked for with -order.
+func isMinHeap(ctx *cli.Context) bool {
+	switch o := valOrDefault(ctx, heapOrderFlag); o {
+	case "max":
+		return false
+	case "min":
+		return true
 	default:
-
-		// if storing slices, use `len()` for comparison
-		if len(ktype) > 2 && ktype[:2] == "[]" {
-			log.Printf("%s: order will be determined based on value of len(%s)", ktype, ktype)
-			tmpl = fmt.Sprintf(
-				"func (h Heap) compare(a, b %s) int { return len(a)-len(b) }",
-				ktype,
-			)
-		} else {
-			l := 0
-			if []rune(ktype)[0] == '*' {
-				l = 1
-			}
-			// otherwise don't change anything by default, let the user
-			// provide a `Compare` func
-			log.Printf("type %q will need to implement a Compare func: %s",
-				ktype,
-				fmt.Sprintf(`
-	func (%[1]s %s) Compare(other %s) int {
-		if %[1]s > other {
-			return 1
-		} else if %[1]s < other {
-			return -1
-		}
-		return 0
-	}`, strings.ToLower(ktype[l:l+1]), ktype, ktype))
-			return src
-		}
-
+		log.Fatalf("-%s: want min or max, got %q", heapOrderFlag.Name, o)
 	}
+	return false
+}
 
-	return bytes.Replace(src, []byte(orig), []byte(tmpl), -1)
+// minHeap turns a heap template, a max-heap, into a min-heap.
+func minHeap(tmpl *template) {
+	tmpl.methods = map[string]string{
+		"before": fmt.Sprintf("func (h %s) before(a, b KType) bool { return h.compare(a, b) < 0 }", tmpl.name),
+	}
+	tmpl.words = map[string]string{
+		"max-heap":   "min-heap",
+		"largest":    "smallest",
+		"larger":     "smaller",
+		"smaller":    "larger",
+		"decreasing": "increasing",
+	}
 }
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/codegangsta/cli"
+)
+
+func indexedHeap() cli.Command {
+
+	keyTypeFlag := cli.StringFlag{
+		Name:  "key",
+		Usage: "type of the keys ordering the ids in the heap",
+	}
+	idTypeFlag := cli.StringFlag{
+		Name:  "id",
+		Usage: "type of the ids held in the heap, which must be comparable",
+	}
+
+	flags := append(append([]cli.Flag{keyTypeFlag, idTypeFlag, heapOrderFlag}, orderFlags...), testFlags...)
+	flags = append(append(flags, genIDFlag), commonFlags...)
+
+	return cli.Command{
+		Name:      "indexed-heap",
+		ShortName: "iheap",
+		Usage:     "Create an indexed heap (priority queue) customized for your types.",
+		Description: `Create an indexed heap customized for your types. The heap holds
+ids, ordered by a key each. Knowing where each id is in the heap, the key of
+an id can be updated and the id removed in O(log(n)), as needed by Dijkstra's
+algorithm or schedulers. It is a max-heap, unless -order=min is given.
+With -sync, a wrapper safe for concurrent use is generated too.
+With -tests and -bench, the tests and benchmarks are generated for your
+types too.`,
+		Flags: flags,
+		Action: func(ctx *cli.Context) {
+			ktype := typeOrDefault(ctx, keyTypeFlag)
+			idtype := typeOrDefault(ctx, idTypeFlag)
+
+			desc := fmt.Sprintf("indexed-heap -key=%q -id=%q", ktype.expr, idtype.expr)
+			suffix := "IndexedHeap"
+			minFirst := isMinHeap(ctx)
+			if minFirst {
+				suffix = "IndexedMinHeap"
+				desc += " -order=min"
+			}
+
+			typeName := nameOrDefault(ctx, idtype.name+"By"+ktype.name+suffix)
+
+			compare, imports, field := order(ctx, "h IndexedHeap", ktype)
+			imports = append(imports, ktype.imports...)
+			tmpl := &template{
+				name:   "IndexedHeap",
+				src:    indexedHeapSrc,
+				params: map[string]string{"KType": ktype.expr, "IDType": idtype.expr},
+				renames: map[string]string{
+					"IndexedHeap":    typeName,
+					"NewIndexedHeap": "New" + typeName,
+					"indexedEntry":   "entry" + typeName,
+				},
+				compare:      compare,
+				compareField: field,
+				imports:      append(imports, idtype.imports...),
+			}
+			if minFirst {
+				minHeap(tmpl)
+			}
+			if syncTemplate(ctx, tmpl, typeName, indexedHeapReaders...) {
+				desc += " -sync"
+			}
+
+			tests := testTemplate(ctx, tmpl, indexedHeapTestSrc, typeName, sampledKeys(ktype), sampledIDs(idtype))
+			bench := benchTemplate(ctx, tmpl, indexedHeapBenchSrc, typeName, sampledKeys(ktype), sampledIDs(idtype))
+			emit(ctx, desc, tmpl, tests, bench)
+		},
+	}
+}
+
+// indexedHeapReaders are the methods of the indexed heap that don't modify it.
+var indexedHeapReaders = []string{"Len", "Contains", "Key", "Peek", "TryPeek"}
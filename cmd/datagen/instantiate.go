@@ -0,0 +1,460 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// template describes how the source of a datastructure is instantiated
+// for specific types.
+type template struct {
+	// src is the source of the template, a package using placeholder
+	// types for the types it holds.
+	src string
+	// params maps the placeholder types (KType, VType) to the type
+	// expressions that replace them.
+	params map[string]string
+	// renames maps declarations of the template to their new names.
+	renames map[string]string
+	// name of the datastructure's type in the template.
+	name string
+	// compare is the source of a `compare` method replacing the one of the
+	// template, if not empty.
+	compare string
+	// compareField makes the comparison a field of the datastructure,
+	// given to its constructor.
+	compareField bool
+	// methods maps methods of the template to the source of the methods
+	// replacing them.
+	methods map[string]string
+	// words maps words of the template's comments to their replacement,
+	// for the documentation to describe the instantiated datastructure.
+	words map[string]string
+	// imports are added to the instantiated source, if it refers to them.
+	imports []string
+	// syncName is the name of a wrapper of the datastructure safe for
+	// concurrent use, generated along with it if not empty.
+	syncName string
+	// readers are the methods that don't modify the datastructure, which
+	// its sync wrapper calls under a read lock.
+	readers []string
+	// decls are appended to the instantiated source, as is.
+	decls string
+	// drop names the funcs, methods and struct fields of the template that
+	// are left out of the instantiated source, with their documentation.
+	// Those the template doesn't declare are ignored, so that a datastructure
+	// and its tests can drop the same names.
+	drop []string
+}
+
+// instantiate the template in package pkgname. Identifiers are rewritten
+// by resolving the objects they refer to, so a placeholder name appearing in
+// a string, a field or as part of another identifier is left untouched.
+// Whole words of comments naming a renamed declaration are rewritten too, in
+// order to keep the documentation accurate.
+func (t *template) instantiate(pkgname string) ([]byte, error) {
+	src := []byte(t.src)
+	if len(t.drop) != 0 {
+		var err error
+		src, err = dropDecls(src, t.drop)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if t.compareField {
+		var err error
+		src, err = t.addCompareField(src)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if t.compare != "" {
+		var err error
+		src, err = replaceMethod(src, "compare", t.compare)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for name, method := range t.methods {
+		var err error
+		src, err = replaceMethod(src, name, method)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "template.go", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %v", err)
+	}
+
+	targets, err := t.resolve(fset, file)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+
+	var edits editList
+	edits.add(offset(file.Name.Pos()), file.Name.Name, pkgname)
+	ast.Inspect(file, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if name, ok := targets[id]; ok {
+			edits.add(offset(id.Pos()), id.Name, name)
+		}
+		return true
+	})
+
+	words := make(map[string]string, len(t.params)+len(t.renames)+len(t.words))
+	for from, to := range t.words {
+		words[from] = to
+	}
+	for from, to := range t.params {
+		words[from] = to
+	}
+	for from, to := range t.renames {
+		words[from] = to
+	}
+	re := wordRegexp(words)
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			for _, loc := range re.FindAllStringIndex(c.Text, -1) {
+				word := c.Text[loc[0]:loc[1]]
+				edits.add(offset(c.Pos())+loc[0], word, words[word])
+			}
+		}
+	}
+
+	src = edits.apply(src)
+	if t.syncName != "" {
+		wrapper, err := syncWrapper(src, t.renames[t.name], t.syncName, t.readers)
+		if err != nil {
+			return nil, err
+		}
+		src = append(src, wrapper...)
+	}
+	return t.addImports(append(src, t.decls...))
+}
+
+// addImports adds the imports of the template that src refers to, since the
+// placeholders they're needed for aren't necessarily used.
+func (t *template) addImports(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "template.go", src, 0)
+	if err != nil {
+		return nil, fmt.Errorf("parsing instantiated template: %v", err)
+	}
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			// qualifiers aren't resolved by the parser
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+
+	var imports bytes.Buffer
+	seen := make(map[string]bool)
+	for _, path := range t.imports {
+		if !seen[path] && used[importName(path)] && !hasImport(file, path) {
+			fmt.Fprintf(&imports, "\n\nimport %s", strconv.Quote(path))
+		}
+		seen[path] = true
+	}
+	var edits editList
+	edits.add(fset.Position(file.Name.End()).Offset, "", imports.String())
+	return edits.apply(src), nil
+}
+
+// replaceMethod swaps the method called name in src for the method in repl.
+func replaceMethod(src []byte, name, repl string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "template.go", src, 0)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %v", err)
+	}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Name.Name != name {
+			continue
+		}
+		var edits editList
+		start, end := fset.Position(fn.Pos()).Offset, fset.Position(fn.End()).Offset
+		edits.add(start, string(src[start:end]), repl)
+		return edits.apply(src), nil
+	}
+	return nil, fmt.Errorf("template has no %s method", name)
+}
+
+// dropDecls removes the funcs, methods and struct fields called by one of
+// names from src, along with their documentation and the rest of their line.
+func dropDecls(src []byte, names []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "template.go", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %v", err)
+	}
+	drop := make(map[string]bool, len(names))
+	for _, name := range names {
+		drop[name] = true
+	}
+
+	var edits editList
+	remove := func(doc *ast.CommentGroup, from, to token.Pos) {
+		if doc != nil {
+			from = doc.Pos()
+		}
+		start, end := fset.Position(from).Offset, fset.Position(to).Offset
+		for start > 0 && (src[start-1] == ' ' || src[start-1] == '\t') {
+			start--
+		}
+		if i := bytes.IndexByte(src[end:], '\n'); i >= 0 {
+			end += i + 1
+		}
+		edits.add(start, string(src[start:end]), "")
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if drop[n.Name.Name] {
+				remove(n.Doc, n.Pos(), n.End())
+			}
+			return false
+		case *ast.StructType:
+			for _, field := range n.Fields.List {
+				if len(field.Names) == 1 && drop[field.Names[0].Name] {
+					remove(field.Doc, field.Pos(), field.End())
+				}
+			}
+		}
+		return true
+	})
+	return edits.apply(src), nil
+}
+
+// addCompareField adds a `compareFunc` field to the datastructure, which is
+// given as the first argument of its constructors.
+func (t *template) addCompareField(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "template.go", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %v", err)
+	}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+
+	var edits editList
+	var hasField, hasParam bool
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.TypeSpec:
+			st, ok := n.Type.(*ast.StructType)
+			if ok && n.Name.Name == t.name {
+				edits.add(offset(st.Fields.Opening)+1, "",
+					"\n\t// compareFunc orders the keys, as given to the constructor.\n\tcompareFunc func(a, b KType) int\n")
+				hasField = true
+			}
+		case *ast.FuncDecl:
+			// the constructors are named after the datastructure
+			if n.Recv != nil || !strings.HasPrefix(n.Name.Name, "New"+t.name) {
+				return false
+			}
+			param := "compareFunc func(a, b KType) int"
+			if n.Type.Params.NumFields() != 0 {
+				param += ", "
+			}
+			edits.add(offset(n.Type.Params.Opening)+1, "", param)
+			if n.Doc != nil {
+				edits.add(offset(n.Doc.End()), "", "\n// The keys are ordered by compareFunc.")
+			}
+			hasParam = true
+		case *ast.CompositeLit:
+			// only found in the constructors
+			if id, ok := n.Type.(*ast.Ident); ok && id.Name == t.name {
+				elt := "compareFunc: compareFunc"
+				if len(n.Elts) != 0 {
+					if fset.Position(n.Elts[0].Pos()).Line != fset.Position(n.Lbrace).Line {
+						elt = "\n" + elt
+					}
+					elt += ", "
+				}
+				edits.add(offset(n.Lbrace)+1, "", elt)
+			}
+		}
+		return true
+	})
+	if !hasField || !hasParam {
+		return nil, fmt.Errorf("template has no %s struct or New%[1]s constructor", t.name)
+	}
+	return edits.apply(src), nil
+}
+
+// resolve finds the identifiers referring to the placeholder types and to
+// the renamed declarations, and what they should be replaced with. The
+// placeholder types are declared in a stub file, as they are in the
+// template's package.
+func (t *template) resolve(fset *token.FileSet, file *ast.File) (map[*ast.Ident]string, error) {
+	stub := bytes.NewBufferString("package " + file.Name.Name + "\n")
+	for param := range t.params {
+		fmt.Fprintf(stub, "type %s interface{}\n", param)
+	}
+	stubFile, err := parser.ParseFile(fset, "stub.go", stub, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+	// the template isn't expected to type check once its compare method
+	// has been replaced or its imports are missing; what matters is that
+	// the identifiers get resolved.
+	conf := types.Config{Error: func(error) {}}
+	pkg, _ := conf.Check(file.Name.Name, fset, []*ast.File{stubFile, file}, info)
+
+	objs := make(map[types.Object]string, len(t.params)+len(t.renames))
+	for from, to := range t.params {
+		objs[pkg.Scope().Lookup(from)] = to
+	}
+	for from, to := range t.renames {
+		obj := pkg.Scope().Lookup(from)
+		if obj == nil && t.dropped(from) {
+			continue
+		}
+		if obj == nil {
+			return nil, fmt.Errorf("template doesn't declare %q", from)
+		}
+		objs[obj] = to
+	}
+
+	targets := make(map[*ast.Ident]string)
+	for _, uses := range []map[*ast.Ident]types.Object{info.Defs, info.Uses} {
+		for id, obj := range uses {
+			if name, ok := objs[obj]; ok && id.Pos().IsValid() {
+				targets[id] = name
+			}
+		}
+	}
+
+	// expressions depending on missing imports aren't checked, leaving
+	// their identifiers unresolved, such as KType in l.Front().(KType).
+	// Those can only refer to the package scope, unless they name a field.
+	fields := make(map[*ast.Ident]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			fields[n.Sel] = true
+		case *ast.KeyValueExpr:
+			if id, ok := n.Key.(*ast.Ident); ok {
+				fields[id] = true
+			}
+		case *ast.Ident:
+			_, def := info.Defs[n]
+			_, use := info.Uses[n]
+			if def || use || fields[n] {
+				break
+			}
+			if name, ok := objs[pkg.Scope().Lookup(n.Name)]; ok {
+				targets[n] = name
+			}
+		}
+		return true
+	})
+	return targets, nil
+}
+
+// dropped tells if the declaration called name is left out of the
+// instantiated source.
+func (t *template) dropped(name string) bool {
+	for _, drop := range t.drop {
+		if drop == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasImport(file *ast.File, path string) bool {
+	for _, imp := range file.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// wordRegexp matches any of the words, when they're not part of a longer
+// word.
+func wordRegexp(words map[string]string) *regexp.Regexp {
+	var alts []string
+	for word := range words {
+		alts = append(alts, regexp.QuoteMeta(word))
+	}
+	// longest first, so that the longest match wins
+	sort.Sort(sort.Reverse(byLen(alts)))
+	var buf bytes.Buffer
+	buf.WriteString(`\b(`)
+	for i, alt := range alts {
+		if i != 0 {
+			buf.WriteByte('|')
+		}
+		buf.WriteString(alt)
+	}
+	buf.WriteString(`)\b`)
+	return regexp.MustCompile(buf.String())
+}
+
+type byLen []string
+
+func (b byLen) Len() int           { return len(b) }
+func (b byLen) Less(i, j int) bool { return len(b[i]) < len(b[j]) }
+func (b byLen) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+// edits
+
+type edit struct {
+	offset int
+	old    string
+	new    string
+}
+
+// editList is a set of replacements to apply to a source.
+type editList []edit
+
+func (e *editList) add(offset int, old, new string) {
+	*e = append(*e, edit{offset: offset, old: old, new: new})
+}
+
+// apply the edits to src, which is left unmodified.
+func (e editList) apply(src []byte) []byte {
+	sort.Sort(byOffset(e))
+	var buf bytes.Buffer
+	last := 0
+	for _, ed := range e {
+		buf.Write(src[last:ed.offset])
+		buf.WriteString(ed.new)
+		last = ed.offset + len(ed.old)
+	}
+	buf.Write(src[last:])
+	return buf.Bytes()
+}
+
+type byOffset editList
+
+func (b byOffset) Len() int           { return len(b) }
+func (b byOffset) Less(i, j int) bool { return b[i].offset < b[j].offset }
+func (b byOffset) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInstantiateIsHygienic(t *testing.T) {
+	tmpl := &template{
+		name:   "Heap",
+		src:    heapSrc,
+		params: map[string]string{"KType": "HeapItem"},
+		renames: map[string]string{
+			"Heap":    "HeapItemHeap",
+			"NewHeap": "NewHeapItemHeap",
+		},
+	}
+	src, err := tmpl.instantiate("items")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"package items\n",
+		"type HeapItemHeap struct {",
+		"func NewHeapItemHeap(keys ...HeapItem) *HeapItemHeap {",
+		"// HeapItemHeap is a max-heap of HeapItem,",
+		"pq []HeapItem",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("should contain %q:\n%s", want, src)
+		}
+	}
+	if strings.Contains(string(src), "HeapItemHeapItem") {
+		t.Errorf("type names were mangled:\n%s", src)
+	}
+}
+
+func TestInstantiateMinHeap(t *testing.T) {
+	tmpl := &template{
+		name:    "Heap",
+		src:     heapSrc,
+		params:  map[string]string{"KType": "int"},
+		renames: map[string]string{"Heap": "IntMinHeap", "NewHeap": "NewIntMinHeap"},
+	}
+	minHeap(tmpl)
+	src, err := tmpl.instantiate("ints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"func (h IntMinHeap) before(a, b int) bool { return h.compare(a, b) < 0 }",
+		"// IntMinHeap is a min-heap of int,",
+		"// retrieved in their increasing order",
+		"// Peek at the smallest element",
+		"// Pop removes the smallest element",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("should contain %q:\n%s", want, src)
+		}
+	}
+	for _, unwanted := range []string{"max-heap", "largest", "decreasing"} {
+		if strings.Contains(string(src), unwanted) {
+			t.Errorf("should not contain %q:\n%s", unwanted, src)
+		}
+	}
+}
+
+func TestInstantiateCompareField(t *testing.T) {
+	tests := []struct {
+		name, src, recv string
+		params          map[string]string
+		methods         map[string]string
+		drop            []string
+	}{
+		{"Heap", heapSrc, "h Heap", map[string]string{"KType": "int"}, nil, nil},
+		{"RedBlack", redblackbstMapSrc, "r RedBlack", map[string]string{"KType": "int", "VType": "string"},
+			map[string]string{"update": sizeUpdateMethod}, aggregateDecls},
+		{"RedBlack", redblackbstSetSrc, "r RedBlack", map[string]string{"KType": "int"}, nil, nil},
+	}
+	for _, tt := range tests {
+		tmpl := &template{
+			name:         tt.name,
+			src:          tt.src,
+			params:       tt.params,
+			renames:      map[string]string{tt.name: "Ordered", "New" + tt.name: "NewOrdered"},
+			compare:      "func (" + tt.recv + ") compare(a, b KType) int { return " + tt.recv[:1] + ".compareFunc(a, b) }",
+			compareField: true,
+			methods:      tt.methods,
+			drop:         tt.drop,
+		}
+		src, err := tmpl.instantiate("ordered")
+		if err != nil {
+			t.Fatal(err)
+		}
+		src, err = render(&output{dir: t.TempDir(), pkgname: "ordered"}, src)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if want := "func NewOrdered(compareFunc func(a, b int) int"; !strings.Contains(string(src), want) {
+			t.Errorf("%s: should contain %q:\n%s", tt.name, want, src)
+		}
+		if want := "FromUnsorted(compareFunc func(a, b int) int, keys []int"; tt.name == "RedBlack" && !strings.Contains(string(src), want) {
+			t.Errorf("%s: should contain %q:\n%s", tt.name, want, src)
+		}
+	}
+}
+
+func TestInstantiateDropsDecls(t *testing.T) {
+	tmpl := &template{
+		name:    "RedBlack",
+		src:     redblackbstMapSrc,
+		params:  map[string]string{"KType": "int", "VType": "string"},
+		renames: map[string]string{"RedBlack": "IntMap", "NewRedBlack": "NewIntMap", "Aggregate": "IntMapAggregate"},
+		methods: map[string]string{"update": sizeUpdateMethod},
+		drop:    aggregateDecls,
+	}
+	src, err := tmpl.instantiate("ints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"func (r *IntMap) update(h *mapnode) { h.n = 1 + h.left.size() + h.right.size() }",
+		"\tcolorRed    bool\n}",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("should contain %q:\n%s", want, src)
+		}
+	}
+	for _, unwanted := range []string{"AType", "agg", "measure", "combine", "Aggregate"} {
+		if strings.Contains(string(src), unwanted) {
+			t.Errorf("should not contain %q:\n%s", unwanted, src)
+		}
+	}
+}
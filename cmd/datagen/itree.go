@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/codegangsta/cli"
+)
+
+func intervalTree() cli.Command {
+
+	keyTypeFlag := cli.StringFlag{
+		Name:  "key",
+		Usage: "type of the ends of the intervals",
+	}
+	valTypeFlag := cli.StringFlag{
+		Name:  "val",
+		Usage: "type that will be used for values",
+	}
+
+	flags := append(append([]cli.Flag{keyTypeFlag, valTypeFlag}, orderFlags...), testFlags...)
+	flags = append(append(flags, genValFlag), commonFlags...)
+
+	return cli.Command{
+		Name:      "interval-tree",
+		ShortName: "itree",
+		Usage:     "Create an interval tree customized for your types.",
+		Description: `Create an interval tree customized for your types. The tree maps
+closed intervals to values, and finds those overlapping an interval or
+containing a point. It's built on the left leaning red black balanced search
+tree of the sorted map, whose nodes hold the largest end of the intervals
+under them. An interval inserted several times keeps all its values. With
+-sync, a wrapper safe for concurrent use is generated too. With -tests and
+-bench, the tests and benchmarks are generated for your types too.`,
+		Flags: flags,
+		Action: func(ctx *cli.Context) {
+			ktype := typeOrDefault(ctx, keyTypeFlag)
+			vtype := typeOrDefault(ctx, valTypeFlag)
+
+			typeName := nameOrDefault(ctx, fmt.Sprintf("%sTo%sIntervalTree", ktype.name, vtype.name))
+
+			compare, imports, field := order(ctx, "r IntervalTree", ktype)
+			imports = append(imports, ktype.imports...)
+			tmpl := &template{
+				name:   "IntervalTree",
+				src:    intervalTreeSrc,
+				params: map[string]string{"KType": ktype.expr, "VType": vtype.expr},
+				renames: map[string]string{
+					"IntervalTree":    typeName,
+					"NewIntervalTree": "New" + typeName,
+					"intervalnode":    "node" + typeName,
+				},
+				compare:      compare,
+				compareField: field,
+				imports:      append(imports, vtype.imports...),
+			}
+
+			desc := fmt.Sprintf("interval-tree -key=%q -val=%q", ktype.expr, vtype.expr)
+			if syncTemplate(ctx, tmpl, typeName, intervalTreeReaders...) {
+				desc += " -sync"
+			}
+
+			tests := testTemplate(ctx, tmpl, intervalTreeTestSrc, typeName, sampledKeys(ktype), sampledVals(vtype))
+			bench := benchTemplate(ctx, tmpl, intervalTreeBenchSrc, typeName, sampledKeys(ktype), sampledVals(vtype))
+			emit(ctx, desc, tmpl, tests, bench)
+		},
+	}
+}
+
+// intervalTreeReaders are the methods of the interval tree that don't modify
+// it.
+var intervalTreeReaders = []string{"IsEmpty", "Size", "GetAll", "Count", "Intervals", "Overlapping", "Stabbing"}
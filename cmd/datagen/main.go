@@ -17,6 +17,7 @@
 package main
 
 import (
+	"go/token"
 	"log"
 	"os"
 
@@ -26,6 +27,13 @@ import (
 func main() {
 	log.SetFlags(0)
 	log.SetPrefix("datagen: ")
+	if err := newApp().Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newApp returns the datagen app, with a command for each datastructure.
+func newApp() *cli.App {
 	app := cli.NewApp()
 
 	app.Name = "datagen"
@@ -34,13 +42,47 @@ func main() {
 	app.Version = "0.1.1"
 	app.Usage = "Generate datastructures for your types."
 	app.Commands = append(app.Commands, sortedMap())
+	app.Commands = append(app.Commands, sortedMultimap())
 	app.Commands = append(app.Commands, sortedSet())
+	app.Commands = append(app.Commands, sortedMultiset())
+	app.Commands = append(app.Commands, intervalTree())
 	app.Commands = append(app.Commands, heap())
+	app.Commands = append(app.Commands, indexedHeap())
 	app.Commands = append(app.Commands, queue())
+	app.Commands = append(app.Commands, deque())
+	app.Commands = append(app.Commands, ringq())
+	return app
+}
 
-	if err := app.Run(os.Args); err != nil {
-		log.Fatal(err)
+// nameFlag overrides the name of the generated datastructure.
+var nameFlag = cli.StringFlag{
+	Name:  "name",
+	Usage: "name of the generated type, instead of one derived from the types it holds",
+}
+
+// commonFlags are understood by every command.
+var commonFlags = []cli.Flag{nameFlag, outFlag, pkgFlag, syncFlag}
+
+// typeOrDefault parses the type expression given to flag f, or its default
+// value.
+func typeOrDefault(ctx *cli.Context, f cli.StringFlag) *typeExpr {
+	t, err := parseType(valOrDefault(ctx, f))
+	if err != nil {
+		log.Fatalf("-%s: %v", f.Name, err)
 	}
+	return t
+}
+
+// nameOrDefault is the name given with -name, or def if none was given.
+func nameOrDefault(ctx *cli.Context, def string) string {
+	name := ctx.String(nameFlag.Name)
+	if name == "" {
+		return def
+	}
+	if !token.IsIdentifier(name) {
+		log.Fatalf("-%s: %q is not a valid identifier", nameFlag.Name, name)
+	}
+	return name
 }
 
 func valOrDefault(ctx *cli.Context, f cli.StringFlag) string {
@@ -55,3 +97,49 @@ func valOrDefault(ctx *cli.Context, f cli.StringFlag) string {
 	}
 	return f.Value
 }
+
+// emit instantiates the template and writes the generated source to the
+// output, once it's been verified and formatted. The tests and benchmarks, if
+// not nil, are written next to it. If the source is invalid, the errors are
+// reported along with desc and datagen exits with a non-zero status.
+func emit(ctx *cli.Context, desc string, tmpl, tests, bench *template) {
+	out, err := outputOrDefault(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// found before anything is written
+	var testsOut, benchOut *output
+	if tests != nil {
+		if testsOut, err = out.sibling("_test.go"); err != nil {
+			log.Fatalf("-%s: %v", testsFlag.Name, err)
+		}
+	}
+	if bench != nil {
+		if benchOut, err = out.sibling("_bench_test.go"); err != nil {
+			log.Fatalf("-%s: %v", benchFlag.Name, err)
+		}
+	}
+
+	emitTo(out, desc, tmpl)
+	// written after the datastructure, so they're checked against it
+	if tests != nil {
+		emitTo(testsOut, desc+" -tests", tests)
+	}
+	if bench != nil {
+		emitTo(benchOut, desc+" -bench", bench)
+	}
+}
+
+func emitTo(out *output, desc string, tmpl *template) {
+	src, err := tmpl.instantiate(out.pkgname)
+	if err != nil {
+		log.Fatalf("%s: %v", desc, err)
+	}
+	src, err = render(out, append([]byte(header), src...))
+	if err != nil {
+		log.Fatalf("%s: %v", desc, err)
+	}
+	if err := out.write(src); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/codegangsta/cli"
+)
+
+// header marks the generated files, following the convention that tools
+// such as golint and code review systems recognize.
+const header = "// Code generated by datagen; DO NOT EDIT.\n\n"
+
+var (
+	outFlag = cli.StringFlag{
+		Name:  "o",
+		Usage: "file where the generated code is written, instead of stdout",
+	}
+	pkgFlag = cli.StringFlag{
+		Name:  "pkg",
+		Usage: "package of the generated code, instead of the one found where it's written",
+	}
+)
+
+// output is where generated code goes.
+type output struct {
+	// filename is empty when writing to stdout.
+	filename string
+	// path is the absolute path of filename.
+	path    string
+	dir     string
+	pkgname string
+}
+
+func outputOrDefault(ctx *cli.Context) (*output, error) {
+	out := &output{filename: ctx.String(outFlag.Name)}
+
+	if out.filename == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		out.dir = cwd
+	} else {
+		abs, err := filepath.Abs(out.filename)
+		if err != nil {
+			return nil, err
+		}
+		out.path = abs
+		out.dir = filepath.Dir(abs)
+	}
+
+	out.pkgname = ctx.String(pkgFlag.Name)
+	if out.pkgname == "" {
+		out.pkgname = packageName(out.dir, out.isTest())
+	}
+	return out, nil
+}
+
+func (o *output) isTest() bool { return strings.HasSuffix(o.filename, "_test.go") }
+
+// sibling is the output of a file of the same package as o, named after it
+// with suffix instead of the .go extension, such as _test.go. Test files
+// have no siblings, which would be named after the tests.
+func (o *output) sibling(suffix string) (*output, error) {
+	if o.isTest() {
+		return nil, fmt.Errorf("%s is a test file, the %s file can't be written next to it", o.filename, suffix)
+	}
+	s := *o
+	s.filename = strings.TrimSuffix(o.filename, ".go") + suffix
+	s.path = strings.TrimSuffix(o.path, ".go") + suffix
+	return &s, nil
+}
+
+// write the generated source to the output.
+func (o *output) write(src []byte) error {
+	if o.filename == "" {
+		_, err := os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(o.filename, src, 0644)
+}
+
+// packageName finds the package of the code to be written in dir. When
+// invoked by `go generate` in dir, the package is known. Otherwise, it's the
+// package of the Go files found in dir, the package of the test files if test
+// code is generated. In an empty directory, the package is named after the
+// directory.
+func packageName(dir string, test bool) string {
+	if pkg := os.Getenv("GOPACKAGE"); pkg != "" {
+		if cwd, err := os.Getwd(); err == nil && cwd == dir {
+			return pkg
+		}
+	}
+
+	var names, testNames []string
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	sort.Strings(paths)
+	for _, path := range paths {
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly)
+		if err != nil {
+			continue
+		}
+		if strings.HasSuffix(path, "_test.go") {
+			testNames = append(testNames, f.Name.Name)
+		} else {
+			names = append(names, f.Name.Name)
+		}
+	}
+	if test && len(testNames) != 0 {
+		return testNames[0]
+	}
+	if len(names) != 0 {
+		return names[0]
+	}
+
+	name := importName(filepath.Base(dir))
+	if !token.IsIdentifier(name) {
+		return "main"
+	}
+	return name
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// chdir changes the working directory to dir until the test ends.
+func chdir(t *testing.T, dir string) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(cwd); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		name string
+		// dir is created under a temporary directory, with files mapping
+		// file names to their package clause.
+		dir   string
+		files map[string]string
+		// gopackage is set as by `go generate`, invoked in dir if inDir.
+		gopackage string
+		inDir     bool
+		test      bool
+		want      string
+	}{
+		{
+			name: "go generate", dir: "widgets", files: map[string]string{"a.go": "widget"},
+			gopackage: "generated", inDir: true, want: "generated",
+		},
+		{
+			name: "go generate elsewhere", dir: "widgets", files: map[string]string{"a.go": "widget"},
+			gopackage: "generated", want: "widget",
+		},
+		{
+			name: "package clause", dir: "widgets",
+			files: map[string]string{"a.go": "widget", "a_test.go": "widget_test"},
+			want:  "widget",
+		},
+		{
+			name: "test package", dir: "widgets",
+			files: map[string]string{"a.go": "widget", "a_test.go": "widget_test"},
+			test:  true, want: "widget_test",
+		},
+		{
+			name: "test without test files", dir: "widgets", files: map[string]string{"a.go": "widget"},
+			test: true, want: "widget",
+		},
+		{name: "directory name", dir: "go-widgets", want: "widgets"},
+		{name: "invalid directory name", dir: "1widgets", want: "main"},
+		{
+			name: "main", dir: "widgets", files: map[string]string{"main.go": "main"},
+			want: "main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), tt.dir)
+			if err := os.Mkdir(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			for filename, pkg := range tt.files {
+				writeFile(t, filepath.Join(dir, filename), []byte("package "+pkg+"\n"))
+			}
+			t.Setenv("GOPACKAGE", tt.gopackage)
+			if tt.inDir {
+				chdir(t, dir)
+				// the working directory is what os.Getwd says it is
+				var err error
+				if dir, err = os.Getwd(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := packageName(dir, tt.test); got != tt.want {
+				t.Errorf("want package %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOutputSibling(t *testing.T) {
+	out := &output{filename: "x.go", path: "/pkg/x.go"}
+	sibling, err := out.sibling("_bench_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sibling.filename != "x_bench_test.go" || sibling.path != "/pkg/x_bench_test.go" {
+		t.Errorf("want x_bench_test.go in /pkg, got %q at %q", sibling.filename, sibling.path)
+	}
+
+	// the tests of x_test.go would be in x_test_test.go
+	out = &output{filename: "x_test.go", path: "/pkg/x_test.go"}
+	if sibling, err := out.sibling("_test.go"); err == nil {
+		t.Errorf("test files should have no siblings, got %q", sibling.filename)
+	}
+}
+
+func TestOutputIsWritten(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.go"), []byte("package widget\n"))
+
+	tests := []struct {
+		name     string
+		args     []string
+		filename string
+		pkg      string
+	}{
+		{"package found", nil, "queue.go", "widget"},
+		{"package given", []string{"-pkg=other"}, "other.go", "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(dir, tt.filename)
+			args := append([]string{"datagen", "queue", "-key=int", "-o", filename}, tt.args...)
+			if err := newApp().Run(args); err != nil {
+				t.Fatal(err)
+			}
+			src, err := ioutil.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(src, []byte(header)) {
+				t.Errorf("want the file to start with %q, got %q", header, src[:len(header)])
+			}
+			if want := "\npackage " + tt.pkg + "\n"; !bytes.Contains(src, []byte(want)) {
+				t.Errorf("want %q in the file", want)
+			}
+		})
+	}
+}
+
+func TestOutputIsWrittenToStdout(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "widgets")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, dir)
+	t.Setenv("GOPACKAGE", "")
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	read := make(chan []byte)
+	go func() {
+		src, _ := ioutil.ReadAll(r)
+		read <- src
+	}()
+
+	err = newApp().Run([]string{"datagen", "queue", "-key=int"})
+	w.Close()
+	src := <-read
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(src), header+"package widgets\n") {
+		t.Errorf("want the header and package widgets, got %q", src)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Errorf("want no files written, got %v", files)
+	}
+}
@@ -1,11 +1,8 @@
 package main
 
 import (
-	"bytes"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
+	"log"
 
 	"github.com/codegangsta/cli"
 )
@@ -16,6 +13,14 @@ func queue() cli.Command {
 		Name:  "key",
 		Usage: "type that will be held in the queue",
 	}
+	boundedFlag := cli.BoolFlag{
+		Name:  "bounded",
+		Usage: "hold at most the capacity given to the constructor, rejecting or overwriting pushes when full",
+	}
+	blockingFlag := cli.BoolFlag{
+		Name:  "blocking",
+		Usage: "hold at most the capacity given to the constructor, safe for concurrent use, waiting to push or pop",
+	}
 
 	return cli.Command{
 		Name:      "queue",
@@ -23,32 +28,64 @@ func queue() cli.Command {
 		Usage:     "Create a queue (list) customized for your types.",
 		Description: `Create a queue customized for your types. The implementation
 is based on a ring buffer, which has good performance and is well tested.
-(the tests are not generated with the custom type)`,
-		Flags: []cli.Flag{keyTypeFlag},
+With -bounded, the ring buffer doesn't grow past the capacity it's created
+with: pushing onto a full queue is rejected, or overwrites its oldest element,
+as chosen when creating it. With -sync, a wrapper safe for concurrent use is
+generated too.
+With -blocking, the queue is bounded and safe for concurrent use, and popping
+from an empty queue or pushing onto a full one can wait for another goroutine
+to make it possible, until a context is done.
+With -tests and -bench, the tests and benchmarks are generated for your
+types too.`,
+		Flags: append(append([]cli.Flag{keyTypeFlag, boundedFlag, blockingFlag}, testFlags...), commonFlags...),
 		Action: func(ctx *cli.Context) {
-			ktype := valOrDefault(ctx, keyTypeFlag)
+			ktype := typeOrDefault(ctx, keyTypeFlag)
 
-			kname := ktype
-			if len(kname) > 1 && []byte(kname)[0] == '*' {
-				kname = kname[1:]
-			}
-			if len(kname) > 2 && kname[:2] == "[]" {
-				kname = strings.Title(kname[2:]) + "s"
+			desc := fmt.Sprintf("queue -key=%q", ktype.expr)
+			name, src, testSrc, benchSrc := "Queue", queueSrc, queueTestSrc, queueBenchSrc
+			readers := queueReaders
+			switch bounded, blocking := ctx.Bool(boundedFlag.Name), ctx.Bool(blockingFlag.Name); {
+			case blocking && (bounded || ctx.Bool(syncFlag.Name)):
+				log.Fatalf("-%s queues are already bounded and safe for concurrent use, -%s and -%s can't be given",
+					blockingFlag.Name, boundedFlag.Name, syncFlag.Name)
+			case blocking:
+				name, src, testSrc, benchSrc = "BlockingQueue", blockingQueueSrc, blockingQueueTestSrc, blockingQueueBenchSrc
+				desc += " -blocking"
+			case bounded:
+				name, src, testSrc, benchSrc = "BoundedQueue", boundedQueueSrc, boundedQueueTestSrc, boundedQueueBenchSrc
+				readers = boundedQueueReaders
+				desc += " -bounded"
 			}
 
-			typeName := fmt.Sprintf("%sQueue", strings.Title(kname))
-
-			cwd, _ := os.Getwd()
-			pkgname := fmt.Sprintf("package %s", filepath.Base(cwd))
-
-			src := []byte(queueSrc)
-			src = bytes.Replace(src, []byte("package queue"), []byte(pkgname), 1)
+			typeName := nameOrDefault(ctx, ktype.name+name)
 
-			src = bytes.Replace(src, []byte("nilKType"), []byte("nil"+kname), -1) // before KType's replace
-			src = bytes.Replace(src, []byte("KType"), []byte(ktype), -1)
-			src = bytes.Replace(src, []byte("Queue"), []byte(typeName), -1)
+			tmpl := &template{
+				name:   name,
+				src:    src,
+				params: map[string]string{"KType": ktype.expr},
+				renames: map[string]string{
+					name:         typeName,
+					"New" + name: "New" + typeName,
+				},
+				imports: ktype.imports,
+			}
+			if name == "Queue" {
+				tmpl.renames["nilKType"] = "nil" + typeName
+			}
+			if syncTemplate(ctx, tmpl, typeName, readers...) {
+				desc += " -sync"
+			}
 
-			fmt.Println(string(src))
+			tests := testTemplate(ctx, tmpl, testSrc, typeName, sampledKeys(ktype))
+			bench := benchTemplate(ctx, tmpl, benchSrc, typeName, sampledKeys(ktype))
+			emit(ctx, desc, tmpl, tests, bench)
 		},
 	}
 }
+
+// queueReaders are the methods of the queue that don't modify it.
+var queueReaders = []string{"Len", "Peek", "TryPeek", "Get"}
+
+// boundedQueueReaders are the methods of the bounded queue that don't modify
+// it.
+var boundedQueueReaders = []string{"Len", "Cap", "Peek", "TryPeek", "Get"}
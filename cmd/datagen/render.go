@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// generatedFilename is the name given to the generated source while it is
+// being checked.
+const generatedFilename = "datagen.go"
+
+// render verifies that src is valid Go source and returns it gofmt'd. The
+// source is type checked along with the other files of the package it's
+// written to, so that key types declared in that package are resolved. Errors
+// point at the offending lines of the generated source.
+func render(out *output, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, generatedFilename, src, parser.ParseComments)
+	if err != nil {
+		return nil, newSourceError(src, err)
+	}
+
+	if err := typecheck(fset, out, file); err != nil {
+		return nil, newSourceError(src, err)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// typecheck the generated file against the package it's written to. Only
+// errors located in the generated file are reported. If a dependency can't be
+// imported, the check is skipped with a warning since it's likely to be an
+// issue with the environment rather than with the generated code.
+func typecheck(fset *token.FileSet, out *output, file *ast.File) error {
+	files := append(packageFiles(fset, out), file)
+
+	var errs scanner.ErrorList
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			terr, ok := err.(types.Error)
+			if !ok {
+				return
+			}
+			pos := terr.Fset.Position(terr.Pos)
+			if pos.Filename != generatedFilename {
+				return
+			}
+			errs.Add(pos, terr.Msg)
+		},
+	}
+	_, _ = conf.Check(file.Name.Name, fset, files, nil)
+
+	for _, err := range errs {
+		if strings.HasPrefix(err.Msg, "could not import") {
+			log.Printf("WARNING: skipping type check, %s", err.Msg)
+			return nil
+		}
+	}
+	errs.Sort()
+	return errs.Err()
+}
+
+// packageFiles parses the Go files of the package the generated code is
+// written to. The file being overwritten is left out, as are the files that
+// don't parse, such as a truncated file about to receive the generated code.
+func packageFiles(fset *token.FileSet, out *output) []*ast.File {
+	// files that don't parse are reported in the error, but the rest of the
+	// package is still found
+	bpkg, _ := build.ImportDir(out.dir, 0)
+	if bpkg == nil {
+		return nil
+	}
+	var names []string
+	switch out.pkgname {
+	case bpkg.Name:
+		names = bpkg.GoFiles
+		if out.isTest() {
+			names = append(names, bpkg.TestGoFiles...)
+		}
+	case bpkg.Name + "_test":
+		names = bpkg.XTestGoFiles
+	default:
+		return nil
+	}
+	var files []*ast.File
+	for _, name := range names {
+		path := filepath.Join(out.dir, name)
+		if path == out.path {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			continue
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// sourceError reports errors found in generated source, along with the
+// lines that caused them.
+type sourceError struct {
+	lines []string
+	errs  scanner.ErrorList
+}
+
+func newSourceError(src []byte, err error) error {
+	errs, ok := err.(scanner.ErrorList)
+	if !ok {
+		return err
+	}
+	return &sourceError{
+		lines: strings.Split(string(src), "\n"),
+		errs:  errs,
+	}
+}
+
+func (s *sourceError) Error() string {
+	sort.Sort(s.errs)
+	buf := bytes.NewBufferString("generated code is invalid:")
+	for _, err := range s.errs {
+		line := err.Pos.Line
+		fmt.Fprintf(buf, "\n\tline %d: %s", line, err.Msg)
+		if line > 0 && line <= len(s.lines) {
+			fmt.Fprintf(buf, "\n\t\t%s", strings.TrimSpace(s.lines[line-1]))
+		}
+	}
+	return buf.String()
+}
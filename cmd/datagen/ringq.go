@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/codegangsta/cli"
+)
+
+func ringq() cli.Command {
+
+	keyTypeFlag := cli.StringFlag{
+		Name:  "key",
+		Usage: "type that will be held in the queue",
+	}
+	flavorFlag := cli.StringFlag{
+		Name:  "flavor",
+		Value: "spsc",
+		Usage: "spsc for a single producer and a single consumer, mpmc for any number of them",
+	}
+
+	return cli.Command{
+		Name:      "ringq",
+		ShortName: "rq",
+		Usage:     "Create a lock-free ring queue customized for your types.",
+		Description: `Create a queue customized for your types, shared by goroutines
+without locks. The implementation is the ring buffer of the queue, with a
+fixed capacity rounded up to a power of two, and sync/atomic counters telling
+the producers and the consumers where to push and pop. Pushing onto a full
+queue and popping from an empty one fail at once rather than wait.
+With -flavor=spsc, the default, the queue is shared between a single producer
+and a single consumer. With -flavor=mpmc, any number of goroutines can push
+and pop.
+With -tests and -bench, the tests and benchmarks are generated for your
+types too. Run the tests with -race.`,
+		Flags: append(append([]cli.Flag{keyTypeFlag, flavorFlag}, testFlags...), commonFlags...),
+		Action: func(ctx *cli.Context) {
+			ktype := typeOrDefault(ctx, keyTypeFlag)
+			if ctx.Bool(syncFlag.Name) {
+				log.Fatalf("ring queues are already safe for concurrent use, -%s can't be given", syncFlag.Name)
+			}
+
+			var name, src, testSrc, benchSrc string
+			switch flavor := valOrDefault(ctx, flavorFlag); flavor {
+			case "spsc":
+				name, src, testSrc, benchSrc = "SPSCQueue", spscQueueSrc, spscQueueTestSrc, spscQueueBenchSrc
+			case "mpmc":
+				name, src, testSrc, benchSrc = "MPMCQueue", mpmcQueueSrc, mpmcQueueTestSrc, mpmcQueueBenchSrc
+			default:
+				log.Fatalf("-%s: want spsc or mpmc, got %q", flavorFlag.Name, flavor)
+			}
+
+			typeName := nameOrDefault(ctx, ktype.name+name)
+
+			tmpl := &template{
+				name:   name,
+				src:    src,
+				params: map[string]string{"KType": ktype.expr},
+				renames: map[string]string{
+					name:         typeName,
+					"New" + name: "New" + typeName,
+				},
+				imports: ktype.imports,
+			}
+			if name == "MPMCQueue" {
+				tmpl.renames["mpmcSlot"] = "slot" + typeName
+			}
+
+			desc := fmt.Sprintf("ringq -key=%q -flavor=%s", ktype.expr, valOrDefault(ctx, flavorFlag))
+			tests := testTemplate(ctx, tmpl, testSrc, typeName, sampledKeys(ktype))
+			bench := benchTemplate(ctx, tmpl, benchSrc, typeName, sampledKeys(ktype))
+			emit(ctx, desc, tmpl, tests, bench)
+		},
+	}
+}
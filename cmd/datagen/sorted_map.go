@@ -1,12 +1,8 @@
 package main
 
 import (
-	"bytes"
 	"fmt"
 	"log"
-	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/codegangsta/cli"
 )
@@ -21,6 +17,27 @@ func sortedMap() cli.Command {
 		Name:  "val",
 		Usage: "type that will be used for values",
 	}
+	persistentFlag := cli.BoolFlag{
+		Name:  "persistent",
+		Usage: "copy the nodes the writes modify once they're shared with a snapshot, so that snapshots are taken in O(1)",
+	}
+
+	aggregateFlag := cli.StringFlag{
+		Name:  "aggregate",
+		Usage: "type of the aggregates of ranges of keys/values, which the nodes hold for RangeAggregate, as computed by -measure and -combine",
+	}
+	measureFlag := cli.StringFlag{
+		Name:  "measure",
+		Usage: "func(k KEY, v VAL) AGGREGATE giving the aggregate of a single key/value",
+	}
+	combineFlag := cli.StringFlag{
+		Name:  "combine",
+		Usage: "associative func(a, b AGGREGATE) AGGREGATE giving the aggregate of two consecutive ranges, in order",
+	}
+
+	flags := append([]cli.Flag{keyTypeFlag, valTypeFlag, persistentFlag, aggregateFlag, measureFlag, combineFlag}, orderFlags...)
+	flags = append(flags, testFlags...)
+	flags = append(append(flags, genValFlag), commonFlags...)
 
 	return cli.Command{
 		Name:      "sorted-map",
@@ -28,123 +45,152 @@ func sortedMap() cli.Command {
 		Usage:     "Create a sorted map customized for your types.",
 		Description: `Create a sorted map customized for your types. The map is built
 on a left leaning red black balanced search tree. The implementation has good
-performance and is well tested, with 100% test coverage. (the tests are not
-generated with the custom type)`,
-		Flags: []cli.Flag{keyTypeFlag, valTypeFlag},
+performance and is well tested, with 100% test coverage. With -persistent,
+the map keeps the nodes it shares with its snapshots as they are, copying
+those it writes to instead, so that a snapshot is taken in O(1) and can be
+read while the map is written to. A persistent map lacks the cursors, the
+descending and bounded ranges, DeleteRange and the conversions from and to
+slices, and can't be given -aggregate. With -aggregate, each node holds the
+aggregate of the keys/values under it, as computed by the funcs given with
+-measure and -combine, so that the aggregate of any range of keys is computed
+in O(log n) by RangeAggregate. With -sync, a wrapper safe for concurrent
+use is generated too. With -tests and -bench, the tests and benchmarks are
+generated for your types too.`,
+		Flags: flags,
 		Action: func(ctx *cli.Context) {
-			ktype := valOrDefault(ctx, keyTypeFlag)
-			vtype := valOrDefault(ctx, valTypeFlag)
-
-			kname := ktype
-			vname := vtype
-			if len(kname) > 1 && []byte(kname)[0] == '*' {
-				kname = kname[1:]
+			ktype := typeOrDefault(ctx, keyTypeFlag)
+			vtype := typeOrDefault(ctx, valTypeFlag)
+
+			persistent := ctx.Bool(persistentFlag.Name)
+			augmented := ctx.String(aggregateFlag.Name) != ""
+			for _, f := range []cli.StringFlag{measureFlag, combineFlag} {
+				if !augmented && ctx.String(f.Name) != "" {
+					log.Fatalf("-%s needs -%s, the sorted map has no aggregates without it", f.Name, aggregateFlag.Name)
+				}
 			}
-			if len(kname) > 2 && kname[:2] == "[]" {
-				kname = strings.Title(kname[2:]) + "s"
+			if persistent && augmented {
+				log.Fatalf("-%s maps can't be augmented with -%s, the persistent tree has no aggregates",
+					persistentFlag.Name, aggregateFlag.Name)
 			}
-			if len(vname) > 1 && []byte(vname)[0] == '*' {
-				vname = vname[1:]
+			typeName := fmt.Sprintf("Sorted%sTo%sMap", ktype.name, vtype.name)
+			if persistent {
+				typeName = "Persistent" + typeName
 			}
-			if len(vname) > 2 && vname[:2] == "[]" {
-				vname = strings.Title(vname[2:]) + "s"
+			if augmented {
+				typeName = "Augmented" + typeName
+			}
+			typeName = nameOrDefault(ctx, typeName)
+			nodeName := "node" + typeName
+			desc := fmt.Sprintf("sorted-map -key=%q -val=%q", ktype.expr, vtype.expr)
+
+			name, src, testSrc, benchSrc := "RedBlack", redblackbstMapSrc, redblackbstMapTestSrc, redblackbstMapBenchSrc
+			renames := map[string]string{
+				"RedBlack":                typeName,
+				"NewRedBlack":             "New" + typeName,
+				"NewRedBlackFromSorted":   "New" + typeName + "FromSorted",
+				"NewRedBlackFromUnsorted": "New" + typeName + "FromUnsorted",
+				"mapnode":                 nodeName,
+				"Cursor":                  typeName + "Cursor",
+				"Bound":                   typeName + "Bound",
+			}
+			readers := sortedMapReaders
+			if persistent {
+				name, src, testSrc, benchSrc = "PersistentRedBlack", persistentMapSrc, persistentMapTestSrc, persistentMapBenchSrc
+				renames = map[string]string{
+					"PersistentRedBlack":    typeName,
+					"NewPersistentRedBlack": "New" + typeName,
+					"persistentnode":        nodeName,
+				}
+				readers = persistentMapReaders
+				desc += " -persistent"
+			}
+			params := map[string]string{"KType": ktype.expr, "VType": vtype.expr}
+			var methods map[string]string
+			var drop []string
+			var imports []string
+			if augmented {
+				readers = augmentedMapReaders
+
+				atype := typeOrDefault(ctx, aggregateFlag)
+				measure, measureImports := funcOrDefault(measureFlag, valOrDefault(ctx, measureFlag))
+				combine, combineImports := funcOrDefault(combineFlag, valOrDefault(ctx, combineFlag))
+				params["AType"] = atype.expr
+				methods = aggregateMethods("r "+name, measure, combine)
+				imports = append(append(append(imports, atype.imports...), measureImports...), combineImports...)
+				desc += fmt.Sprintf(" -aggregate=%q -measure=%q -combine=%q", atype.expr, measure, combine)
+			} else if !persistent {
+				// the nodes only hold the sizes of their trees
+				methods = map[string]string{"update": sizeUpdateMethod}
+				drop = aggregateDecls
 			}
-			typeName := fmt.Sprintf("Sorted%sTo%sMap", strings.Title(kname), strings.Title(vname))
-			nodeName := fmt.Sprintf("node%sTo%s", strings.Title(kname), strings.Title(vname))
-
-			cwd, _ := os.Getwd()
-			pkgname := fmt.Sprintf("package %s", filepath.Base(cwd))
 
-			src := []byte(redblackbstMapSrc)
-			src = bytes.Replace(src, []byte("package redblackbst"), []byte(pkgname), 1)
+			compare, orderImports, field := order(ctx, "r "+name, ktype)
+			imports = append(append(imports, orderImports...), ktype.imports...)
+			tmpl := &template{
+				name:         name,
+				src:          src,
+				params:       params,
+				renames:      renames,
+				compare:      compare,
+				compareField: field,
+				methods:      methods,
+				drop:         drop,
+				imports:      append(imports, vtype.imports...),
+			}
 
-			// need to replace Compare before replacing KType
-			src = replaceRbstCompareFunc(ktype, src)
-			src = bytes.Replace(src, []byte("KType"), []byte(ktype), -1)
-			src = bytes.Replace(src, []byte("VType"), []byte(vtype), -1)
-			src = bytes.Replace(src, []byte("RedBlack"), []byte(typeName), -1)
-			src = bytes.Replace(src, []byte("mapnode"), []byte(nodeName), -1)
+			if syncTemplate(ctx, tmpl, typeName, readers...) {
+				desc += " -sync"
+			}
 
-			fmt.Println(string(src))
+			tests := testTemplate(ctx, tmpl, testSrc, typeName, sampledKeys(ktype), sampledVals(vtype))
+			bench := benchTemplate(ctx, tmpl, benchSrc, typeName, sampledKeys(ktype), sampledVals(vtype))
+			emit(ctx, desc, tmpl, tests, bench)
 		},
 	}
 }
 
-func replaceRbstCompareFunc(ktype string, src []byte) []byte {
-	var tmpl string
-	orig := "func (r RedBlack) compare(a, b KType) int { return a.Compare(b) }"
-
-	switch ktype {
-
-	case "int", "int8", "int16", "int32", "int64",
-		"uint", "uint8", "uint16", "uint32", "uint64":
-		tmpl = "func (r RedBlack) compare(a, b KType) int { return int(a) - int(b) }"
-
-	case "float32", "float64":
-		tmpl = `
-func (r RedBlack) compare(a, b KType) int {
-	const e = 0.00000001
-
-    diff := (a-b)/a
-    if diff < -e {
-        return -1
-    } else if diff > e {
-        return 1
-    }
-    return 0
-}`
-
-	case "string":
-		tmpl = `
-func (r RedBlack) compare(a, b KType) int {
-    if a < b {
-        return -1
-    }
-    if a > b {
-        return 1
-    }
-    return 0
-}`
-
-	case "[]byte":
-		log.Printf("WARNING: using []byte as keys can lead to undefined behavior if the []byte are modified after insertion!!!")
-		tmpl = `import "bytes"
-
-// WARNING: using []byte as keys can lead to undefined behavior if the
-// []byte are modified after insertion!!!
-func (r RedBlack) compare(a, b KType) int { return bytes.Compare(a, b) }`
-
-	default:
-
-		// if storing slices, use `len()` for comparison
-		if len(ktype) > 2 && ktype[:2] == "[]" {
-			log.Printf("%s: order will be determined based on value of len(%s)", ktype, ktype)
-			tmpl = fmt.Sprintf(
-				"func (r RedBlack) compare(a, b %s) int { return len(a)-len(b) }",
-				ktype,
-			)
-		} else {
-			l := 0
-			if []rune(ktype)[0] == '*' {
-				l = 1
-			}
-			// otherwise don't change anything by default, let the user
-			// provide a `Compare` func
-			log.Printf("type %q will need to implement a Compare func: %s",
-				ktype,
-				fmt.Sprintf(`
-	func (%[1]s %s) Compare(other %s) int {
-		if %[1]s > other {
-			return 1
-		} else if %[1]s < other {
-			return -1
-		}
-		return 0
-	}`, strings.ToLower(ktype[l:l+1]), ktype, ktype))
-			return src
-		}
+// sortedMapReaders are the methods of the sorted map that don't modify it.
+var sortedMapReaders = []string{
+	"IsEmpty", "Size", "Get", "Has", "Min", "Max", "Floor", "Ceiling", "Lower", "Higher",
+	"Select", "Rank", "Keys", "RangedKeys", "ReverseKeys", "RangedKeysDesc",
+	"RangeCount", "BoundedKeys", "ToSlice", "KeysSlice", "ValuesSlice",
+}
 
-	}
+// persistentMapReaders are the methods of the persistent sorted map that
+// don't modify it. Snapshot isn't one of them, since the sorted map doesn't
+// own its nodes anymore once it's taken.
+var persistentMapReaders = []string{
+	"IsEmpty", "Size", "Get", "Has", "Min", "Max", "Floor", "Ceiling",
+	"Select", "Rank", "Keys", "RangedKeys",
+}
 
-	return bytes.Replace(src, []byte(orig), []byte(tmpl), -1)
+// augmentedMapReaders are the methods of the augmented sorted map that don't
+// modify it.
+var augmentedMapReaders = []string{
+	"IsEmpty", "Size", "Get", "Has", "Min", "Max", "Floor", "Ceiling", "Lower", "Higher",
+	"Select", "Rank", "Keys", "RangedKeys", "ReverseKeys", "RangedKeysDesc",
+	"RangeCount", "BoundedKeys", "ToSlice", "KeysSlice", "ValuesSlice",
+	"Aggregate", "RangeAggregate",
+}
+
+// sizeUpdateMethod is the `update` method of the sorted maps that aren't
+// augmented, whose nodes only hold the sizes of their trees.
+const sizeUpdateMethod = "func (r *RedBlack) update(h *mapnode) { h.n = 1 + h.left.size() + h.right.size() }"
+
+// aggregateDecls are the declarations of the sorted map, and of its tests and
+// benchmarks, that only augmented sorted maps are generated with.
+var aggregateDecls = []string{
+	"agg", "measure", "combine", "Aggregate", "RangeAggregate", "fromLo", "upToHi", "join", "aggregate",
+	"checkRedBlackAggregates", "scaleOfRedBlackAggregates", "floatOfRedBlackAggregate", "equalRedBlackAggregates",
+	"TestRedBlackAggregatesMatchReference", "BenchmarkRedBlackRangeAggregate",
+}
+
+// aggregateMethods returns the sources of the `measure` and `combine`
+// methods of the augmented sorted map, on receiver recv, which call the funcs
+// measure and combine.
+func aggregateMethods(recv, measure, combine string) map[string]string {
+	return map[string]string{
+		"measure": fmt.Sprintf("func (%s) measure(k KType, v VType) AType { return %s(k, v) }", recv, measure),
+		"combine": fmt.Sprintf("func (%s) combine(a, b AType) AType { return %s(a, b) }", recv, combine),
+	}
 }
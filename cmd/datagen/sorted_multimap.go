@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/codegangsta/cli"
+)
+
+func sortedMultimap() cli.Command {
+
+	keyTypeFlag := cli.StringFlag{
+		Name:  "key",
+		Usage: "type that will be used for keys",
+	}
+	valTypeFlag := cli.StringFlag{
+		Name:  "val",
+		Usage: "type that will be used for values",
+	}
+
+	flags := append(append([]cli.Flag{keyTypeFlag, valTypeFlag}, orderFlags...), testFlags...)
+	flags = append(append(flags, genValFlag), commonFlags...)
+
+	return cli.Command{
+		Name:      "sorted-multimap",
+		ShortName: "smultimap",
+		Usage:     "Create a sorted multimap customized for your types.",
+		Description: `Create a sorted multimap customized for your types. Unlike the
+sorted map, it keeps all the values put at a key, in the order they were put.
+Its sizes, ranks and selections count each value of each key. It's built on
+the left leaning red black balanced search tree of the sorted map. With
+-sync, a wrapper safe for concurrent use is generated too. With -tests and
+-bench, the tests and benchmarks are generated for your types too.`,
+		Flags: flags,
+		Action: func(ctx *cli.Context) {
+			ktype := typeOrDefault(ctx, keyTypeFlag)
+			vtype := typeOrDefault(ctx, valTypeFlag)
+
+			typeName := nameOrDefault(ctx, fmt.Sprintf("Sorted%sTo%sMultimap", ktype.name, vtype.name))
+
+			compare, imports, field := order(ctx, "r MultiRedBlack", ktype)
+			imports = append(imports, ktype.imports...)
+			tmpl := &template{
+				name:   "MultiRedBlack",
+				src:    multimapSrc,
+				params: map[string]string{"KType": ktype.expr, "VType": vtype.expr},
+				renames: map[string]string{
+					"MultiRedBlack":    typeName,
+					"NewMultiRedBlack": "New" + typeName,
+					"multinode":        "node" + typeName,
+				},
+				compare:      compare,
+				compareField: field,
+				imports:      append(imports, vtype.imports...),
+			}
+
+			desc := fmt.Sprintf("sorted-multimap -key=%q -val=%q", ktype.expr, vtype.expr)
+			if syncTemplate(ctx, tmpl, typeName, sortedMultimapReaders...) {
+				desc += " -sync"
+			}
+
+			tests := testTemplate(ctx, tmpl, multimapTestSrc, typeName, sampledKeys(ktype), sampledVals(vtype))
+			bench := benchTemplate(ctx, tmpl, multimapBenchSrc, typeName, sampledKeys(ktype), sampledVals(vtype))
+			emit(ctx, desc, tmpl, tests, bench)
+		},
+	}
+}
+
+// sortedMultimapReaders are the methods of the sorted multimap that don't
+// modify it.
+var sortedMultimapReaders = []string{
+	"IsEmpty", "Size", "GetAll", "Has", "Count", "Min", "Max",
+	"Select", "Rank", "Keys", "RangedKeys",
+}
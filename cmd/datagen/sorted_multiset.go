@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/codegangsta/cli"
+)
+
+func sortedMultiset() cli.Command {
+
+	keyTypeFlag := cli.StringFlag{
+		Name:  "key",
+		Usage: "type that will be held in the multiset",
+	}
+
+	return cli.Command{
+		Name:      "sorted-multiset",
+		ShortName: "smultiset",
+		Usage:     "Create a sorted multiset customized for your types.",
+		Description: `Create a sorted multiset customized for your types. Unlike the
+sorted set, it counts the occurrences of each key, which are added and
+removed several at a time. Its sizes, ranks and selections count each
+occurrence, for percentiles and leaderboards. It's built on the left leaning
+red black balanced search tree of the sorted set. With -sync, a wrapper safe
+for concurrent use is generated too. With -tests and -bench, the tests and
+benchmarks are generated for your types too.`,
+		Flags: append(append(append([]cli.Flag{keyTypeFlag}, orderFlags...), testFlags...), commonFlags...),
+		Action: func(ctx *cli.Context) {
+			ktype := typeOrDefault(ctx, keyTypeFlag)
+
+			typeName := nameOrDefault(ctx, "Sorted"+ktype.name+"Multiset")
+
+			compare, imports, field := order(ctx, "r MultiRedBlack", ktype)
+			tmpl := &template{
+				name:   "MultiRedBlack",
+				src:    multisetSrc,
+				params: map[string]string{"KType": ktype.expr},
+				renames: map[string]string{
+					"MultiRedBlack":    typeName,
+					"NewMultiRedBlack": "New" + typeName,
+					"multinode":        "node" + typeName,
+				},
+				compare:      compare,
+				compareField: field,
+				imports:      append(imports, ktype.imports...),
+			}
+
+			desc := fmt.Sprintf("sorted-multiset -key=%q", ktype.expr)
+			if syncTemplate(ctx, tmpl, typeName, sortedMultisetReaders...) {
+				desc += " -sync"
+			}
+
+			tests := testTemplate(ctx, tmpl, multisetTestSrc, typeName, sampledKeys(ktype))
+			bench := benchTemplate(ctx, tmpl, multisetBenchSrc, typeName, sampledKeys(ktype))
+			emit(ctx, desc, tmpl, tests, bench)
+		},
+	}
+}
+
+// sortedMultisetReaders are the methods of the sorted multiset that don't
+// modify it.
+var sortedMultisetReaders = []string{
+	"IsEmpty", "Size", "Distinct", "Count", "Contains", "Min", "Max",
+	"Select", "Rank", "Keys", "RangedKeys",
+}
@@ -1,11 +1,7 @@
 package main
 
 import (
-	"bytes"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/codegangsta/cli"
 )
@@ -23,36 +19,50 @@ func sortedSet() cli.Command {
 		Usage:     "Create a sorted set customized for your types.",
 		Description: `Create a sorted set customized for your types. The set is built
 on a left leaning red black balanced search tree. The implementation has good
-performance and is well tested, with 100% test coverage. (the tests are not
-generated with the custom type)`,
-		Flags: []cli.Flag{keyTypeFlag},
+performance and is well tested, with 100% test coverage. With -sync, a
+wrapper safe for concurrent use is generated too. With -tests and -bench, the
+tests and benchmarks are generated for your types too.`,
+		Flags: append(append(append([]cli.Flag{keyTypeFlag}, orderFlags...), testFlags...), commonFlags...),
 		Action: func(ctx *cli.Context) {
-			ktype := valOrDefault(ctx, keyTypeFlag)
+			ktype := typeOrDefault(ctx, keyTypeFlag)
 
-			kname := ktype
-			if len(kname) > 1 && []byte(kname)[0] == '*' {
-				kname = kname[1:]
-			}
-			if len(kname) > 2 && kname[:2] == "[]" {
-				kname = strings.Title(kname[2:]) + "s"
-			}
-
-			typeName := fmt.Sprintf("Sorted%sSet", strings.Title(kname))
-			nodeName := fmt.Sprintf("node%s", strings.Title(kname))
-
-			cwd, _ := os.Getwd()
-			pkgname := fmt.Sprintf("package %s", filepath.Base(cwd))
+			typeName := nameOrDefault(ctx, "Sorted"+ktype.name+"Set")
+			nodeName := "node" + typeName
 
-			src := []byte(redblackbstSetSrc)
-			src = bytes.Replace(src, []byte("package redblackbst"), []byte(pkgname), 1)
+			compare, imports, field := order(ctx, "r RedBlack", ktype)
+			tmpl := &template{
+				name:   "RedBlack",
+				src:    redblackbstSetSrc,
+				params: map[string]string{"KType": ktype.expr},
+				renames: map[string]string{
+					"RedBlack":                typeName,
+					"NewRedBlack":             "New" + typeName,
+					"NewRedBlackFromSorted":   "New" + typeName + "FromSorted",
+					"NewRedBlackFromUnsorted": "New" + typeName + "FromUnsorted",
+					"treenode":                nodeName,
+					"Cursor":                  typeName + "Cursor",
+					"Bound":                   typeName + "Bound",
+				},
+				compare:      compare,
+				compareField: field,
+				imports:      append(imports, ktype.imports...),
+			}
 
-			// need to replace Compare before replacing KType
-			src = replaceRbstCompareFunc(ktype, src)
-			src = bytes.Replace(src, []byte("KType"), []byte(ktype), -1)
-			src = bytes.Replace(src, []byte("RedBlack"), []byte(typeName), -1)
-			src = bytes.Replace(src, []byte("treenode"), []byte(nodeName), -1)
+			desc := fmt.Sprintf("sorted-set -key=%q", ktype.expr)
+			if syncTemplate(ctx, tmpl, typeName, sortedSetReaders...) {
+				desc += " -sync"
+			}
 
-			fmt.Println(string(src))
+			tests := testTemplate(ctx, tmpl, redblackbstSetTestSrc, typeName, sampledKeys(ktype))
+			bench := benchTemplate(ctx, tmpl, redblackbstSetBenchSrc, typeName, sampledKeys(ktype))
+			emit(ctx, desc, tmpl, tests, bench)
 		},
 	}
 }
+
+// sortedSetReaders are the methods of the sorted set that don't modify it.
+var sortedSetReaders = []string{
+	"IsEmpty", "Size", "Contains", "Min", "Max", "Floor", "Ceiling", "Lower", "Higher",
+	"Select", "Rank", "Keys", "RangedKeys", "ReverseKeys", "RangedKeysDesc",
+	"RangeCount", "BoundedKeys", "ToSlice",
+}
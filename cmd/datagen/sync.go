@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/codegangsta/cli"
+)
+
+// syncFlag generates a wrapper of the datastructure safe for concurrent use.
+var syncFlag = cli.BoolFlag{
+	Name:  "sync",
+	Usage: "also generate a Sync wrapper of the datastructure, safe for concurrent use",
+}
+
+// syncTemplate makes tmpl generate a wrapper of its datastructure, named
+// typeName once generated, safe for concurrent use, if asked for with -sync.
+// readers are the methods of the datastructure that don't modify it, which
+// the wrapper calls under a read lock. It tells if the wrapper was asked for.
+func syncTemplate(ctx *cli.Context, tmpl *template, typeName string, readers ...string) bool {
+	if !ctx.Bool(syncFlag.Name) {
+		return false
+	}
+	tmpl.syncName = "Sync" + typeName
+	tmpl.readers = readers
+	tmpl.imports = append(tmpl.imports, "sync")
+	return true
+}
+
+// syncWrapper returns the source of a type called name wrapping the
+// datastructure typeName declared in src, along with its constructors. Each
+// exported method of the datastructure is called under a lock, a read lock
+// for the readers.
+func syncWrapper(src []byte, typeName, name string, readers []string) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "template.go", src, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("parsing instantiated template: %v", err)
+	}
+	text := func(from, to token.Pos) string {
+		return string(src[fset.Position(from).Offset:fset.Position(to).Offset])
+	}
+
+	isReader := make(map[string]bool, len(readers))
+	for _, r := range readers {
+		isReader[r] = true
+	}
+	found := make(map[string]bool)
+
+	// the other types of the datastructure, such as cursors, read it
+	declared := make(map[string]bool)
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.TYPE {
+			for _, spec := range gen.Specs {
+				if name := spec.(*ast.TypeSpec).Name.Name; name != typeName {
+					declared[name] = true
+				}
+			}
+		}
+	}
+
+	var ctors, methods bytes.Buffer
+	var callbacks bool
+	var escaping, pairing []string
+	self := map[string]bool{typeName: true}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !fn.Name.IsExported() {
+			continue
+		}
+		params := text(fn.Type.Params.Opening+1, fn.Type.Params.Closing)
+		var args []string
+		for _, field := range fn.Type.Params.List {
+			if len(field.Names) == 0 {
+				return "", fmt.Errorf("%s has unnamed parameters", fn.Name.Name)
+			}
+			for _, id := range field.Names {
+				args = append(args, id.Name)
+			}
+			switch field.Type.(type) {
+			case *ast.Ellipsis:
+				args[len(args)-1] += "..."
+			case *ast.FuncType:
+				callbacks = true
+			}
+		}
+		var doc string
+		if fn.Doc != nil {
+			doc = text(fn.Doc.Pos(), fn.Doc.End()) + "\n"
+		}
+
+		if fn.Recv == nil {
+			// constructors, named after the datastructure
+			ctor := strings.Replace(fn.Name.Name, typeName, name, 1)
+			if ctor == fn.Name.Name || !returnsPointer(fn, typeName) {
+				continue
+			}
+			doc = strings.Replace(doc, "// "+fn.Name.Name+" ", "// "+ctor+" ", 1)
+			doc = wordRegexp(map[string]string{typeName: name}).ReplaceAllString(doc, name)
+			fmt.Fprintf(&ctors, "\n%sfunc %s(%s) *%s {\n\treturn &%[4]s{ds: %s(%s)}\n}\n",
+				doc, ctor, params, name, fn.Name.Name, strings.Join(args, ", "))
+			continue
+		}
+		if baseType(fn.Recv.List[0].Type) != typeName {
+			continue
+		}
+
+		if mentions(fn.Type.Results, declared) {
+			// what they return would read the datastructure without the lock
+			escaping = append(escaping, fn.Name.Name)
+			continue
+		}
+		if mentions(fn.Type.Params, self) {
+			// they take another datastructure, and locking both could deadlock
+			pairing = append(pairing, fn.Name.Name)
+			continue
+		}
+
+		lock, unlock := "Lock", "Unlock"
+		if isReader[fn.Name.Name] {
+			lock, unlock = "RLock", "RUnlock"
+			found[fn.Name.Name] = true
+		}
+		call := fmt.Sprintf("s.ds.%s(%s)", fn.Name.Name, strings.Join(args, ", "))
+		var results string
+		if res := fn.Type.Results; res != nil {
+			results = " " + text(res.Pos(), res.End())
+			call = "return " + call
+		}
+		fmt.Fprintf(&methods, "\n%sfunc (s *%s) %s(%s)%s {\n\ts.mu.%s()\n\tdefer s.mu.%s()\n\t%s\n}\n",
+			doc, name, fn.Name.Name, params, results, lock, unlock, call)
+	}
+	for _, r := range readers {
+		if !found[r] {
+			return "", fmt.Errorf("%s has no %s method", typeName, r)
+		}
+	}
+
+	doc := fmt.Sprintf("%s wraps %s to be safe for concurrent use. Its methods are those of %[2]s, "+
+		"guarded by a sync.RWMutex: the ones only reading it share the lock.", name, typeName)
+	if callbacks {
+		doc += fmt.Sprintf(" The funcs given to its methods are called with the lock held, "+
+			"so they must not call the methods of %s.", name)
+	}
+	if len(escaping) != 0 {
+		doc += fmt.Sprintf(" It has no %s %s, since what it returns would read %s without the lock.",
+			list(escaping), plural("method", len(escaping)), typeName)
+	}
+	if len(pairing) != 0 {
+		doc += fmt.Sprintf(" Nor has it the %s %s of %s, since locking two wrappers at once could deadlock.",
+			list(pairing), plural("method", len(pairing)), typeName)
+	}
+	var buf bytes.Buffer
+	buf.WriteString("\n" + comment(doc))
+	fmt.Fprintf(&buf, "type %s struct {\n\tmu sync.RWMutex\n\tds *%s\n}\n", name, typeName)
+	buf.Write(ctors.Bytes())
+	buf.Write(methods.Bytes())
+	return buf.String(), nil
+}
+
+// returnsPointer tells if fn returns a single pointer to typeName.
+func returnsPointer(fn *ast.FuncDecl, typeName string) bool {
+	res := fn.Type.Results
+	if res == nil || len(res.List) != 1 || len(res.List[0].Names) > 1 {
+		return false
+	}
+	star, ok := res.List[0].Type.(*ast.StarExpr)
+	return ok && baseType(star) == typeName
+}
+
+// mentions tells if the types of fields refer to one of the types named.
+func mentions(fields *ast.FieldList, types map[string]bool) bool {
+	if fields == nil {
+		return false
+	}
+	var found bool
+	ast.Inspect(fields, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && types[id.Name] {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// baseType is the name of the type expr refers to, through a pointer, or
+// an empty string if it's not a named type.
+func baseType(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if id, ok := expr.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// plural returns word, made plural unless n is 1.
+func plural(word string, n int) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// list returns words as an enumeration, the last two joined by "or".
+func list(words []string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " or " + words[len(words)-1]
+}
+
+// comment returns text as a line comment, wrapped at 80 columns.
+func comment(text string) string {
+	var buf bytes.Buffer
+	line := "//"
+	for _, word := range strings.Fields(text) {
+		if len(line)+1+len(word) > 80 && line != "//" {
+			buf.WriteString(line + "\n")
+			line = "//"
+		}
+		line += " " + word
+	}
+	buf.WriteString(line + "\n")
+	return buf.String()
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInstantiateSync(t *testing.T) {
+	tmpl := &template{
+		name:   "Heap",
+		src:    heapSrc,
+		params: map[string]string{"KType": "int"},
+		renames: map[string]string{
+			"Heap":    "IntHeap",
+			"NewHeap": "NewIntHeap",
+		},
+		syncName: "SyncIntHeap",
+		readers:  heapReaders,
+		imports:  []string{"sync"},
+	}
+	src, err := tmpl.instantiate("ints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"import \"sync\"",
+		"// SyncIntHeap wraps IntHeap to be safe for concurrent use.",
+		"type SyncIntHeap struct {\n\tmu sync.RWMutex\n\tds *IntHeap\n}",
+		"// NewSyncIntHeap creates a heap,",
+		"func NewSyncIntHeap(keys ...int) *SyncIntHeap {\n\treturn &SyncIntHeap{ds: NewIntHeap(keys...)}\n}",
+		"func (s *SyncIntHeap) Peek() int {\n\ts.mu.RLock()\n\tdefer s.mu.RUnlock()\n\treturn s.ds.Peek()\n}",
+		"func (s *SyncIntHeap) TryPop() (k int, ok bool) {\n\ts.mu.Lock()",
+		"func (s *SyncIntHeap) Fix() {\n\ts.mu.Lock()\n\tdefer s.mu.Unlock()\n\ts.ds.Fix()\n}",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("should contain %q:\n%s", want, src)
+		}
+	}
+	for _, unwanted := range []string{"swim", "funcs given to its methods"} {
+		if strings.Contains(string(src[strings.Index(string(src), "SyncIntHeap wraps"):]), unwanted) {
+			t.Errorf("the wrapper should not contain %q:\n%s", unwanted, src)
+		}
+	}
+}
+
+func TestInstantiateSyncCallbacks(t *testing.T) {
+	tmpl := &template{
+		name:   "RedBlack",
+		src:    redblackbstSetSrc,
+		params: map[string]string{"KType": "int"},
+		renames: map[string]string{
+			"RedBlack":    "IntSet",
+			"NewRedBlack": "NewIntSet",
+			"treenode":    "nodeIntSet",
+		},
+		syncName: "SyncIntSet",
+		readers:  sortedSetReaders,
+		imports:  []string{"sync"},
+	}
+	src, err := tmpl.instantiate("ints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"must not call the methods of SyncIntSet.",
+		"func (s *SyncIntSet) Keys(visit func(int) bool) {\n\ts.mu.RLock()",
+		"func (s *SyncIntSet) Put(k int) (already bool) {\n\ts.mu.Lock()",
+		"func (s *SyncIntSet) RangedKeysDesc(lo, hi int, visit func(int) bool) {\n\ts.mu.RLock()",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("should contain %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestInstantiateSyncSkipsCursors(t *testing.T) {
+	tmpl := &template{
+		name:   "RedBlack",
+		src:    redblackbstMapSrc,
+		params: map[string]string{"KType": "int", "VType": "string"},
+		renames: map[string]string{
+			"RedBlack":    "IntMap",
+			"NewRedBlack": "NewIntMap",
+			"mapnode":     "nodeIntMap",
+			"Cursor":      "IntMapCursor",
+		},
+		syncName: "SyncIntMap",
+		readers:  sortedMapReaders,
+		imports:  []string{"sync"},
+	}
+	src, err := tmpl.instantiate("ints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "It has no NewCursor method") {
+		t.Errorf("the wrapper should tell it has no NewCursor method:\n%s", src)
+	}
+	if strings.Contains(string(src), "func (s *SyncIntMap) NewCursor") {
+		t.Errorf("the wrapper should have no NewCursor method:\n%s", src)
+	}
+}
+
+func TestInstantiateSyncSkipsSetAlgebra(t *testing.T) {
+	tmpl := &template{
+		name:   "RedBlack",
+		src:    redblackbstSetSrc,
+		params: map[string]string{"KType": "int"},
+		renames: map[string]string{
+			"RedBlack":    "IntSet",
+			"NewRedBlack": "NewIntSet",
+			"treenode":    "nodeIntSet",
+			"Cursor":      "IntSetCursor",
+			"Bound":       "IntSetBound",
+		},
+		syncName: "SyncIntSet",
+		readers:  sortedSetReaders,
+		imports:  []string{"sync"},
+	}
+	src, err := tmpl.instantiate("ints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "could deadlock") {
+		t.Errorf("the wrapper should tell why it has no Union method:\n%s", src)
+	}
+	for _, method := range []string{"Union", "IntersectionWith", "IsSubset", "Equal"} {
+		if strings.Contains(string(src), "func (s *SyncIntSet) "+method+"(") {
+			t.Errorf("the wrapper should have no %s method:\n%s", method, src)
+		}
+	}
+	if !strings.Contains(string(src), "func (s *SyncIntSet) RangeCount(lo, hi IntSetBound) int {") {
+		t.Errorf("the wrapper should have a RangeCount method:\n%s", src)
+	}
+}
+
+func TestInstantiateSyncUnknownReader(t *testing.T) {
+	tmpl := &template{
+		name:     "Heap",
+		src:      heapSrc,
+		params:   map[string]string{"KType": "int"},
+		renames:  map[string]string{"Heap": "IntHeap", "NewHeap": "NewIntHeap"},
+		syncName: "SyncIntHeap",
+		readers:  []string{"Len", "Get"},
+	}
+	if _, err := tmpl.instantiate("ints"); err == nil || !strings.Contains(err.Error(), "no Get method") {
+		t.Errorf("want an error about the Get method, got %v", err)
+	}
+}
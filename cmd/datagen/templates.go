@@ -2,12 +2,88 @@ package main
 
 //go:generate embed file --var redblackbstMapSrc --source ../../map/redblackbst/rbbst.go
 //go:generate embed file --var redblackbstSetSrc --source ../../set/redblackbst/rbbst.go
+//go:generate embed file --var redblackbstMapTestSrc --source ../../map/redblackbst/props_test.go
+//go:generate embed file --var redblackbstSetTestSrc --source ../../set/redblackbst/props_test.go
+//go:generate embed file --var heapTestSrc --source ../../heap/props_test.go
+//go:generate embed file --var queueTestSrc --source ../../queue/props_test.go
+//go:generate embed file --var redblackbstMapBenchSrc --source ../../map/redblackbst/bench_test.go
+//go:generate embed file --var redblackbstSetBenchSrc --source ../../set/redblackbst/bench_test.go
+//go:generate embed file --var persistentMapSrc --source ../../map/redblackbst/persistent.go
+//go:generate embed file --var persistentMapTestSrc --source ../../map/redblackbst/persistent_props_test.go
+//go:generate embed file --var persistentMapBenchSrc --source ../../map/redblackbst/persistent_bench_test.go
+//go:generate embed file --var multimapSrc --source ../../map/redblackbst/multimap.go
+//go:generate embed file --var multimapTestSrc --source ../../map/redblackbst/multimap_props_test.go
+//go:generate embed file --var multimapBenchSrc --source ../../map/redblackbst/multimap_bench_test.go
+//go:generate embed file --var multisetSrc --source ../../set/redblackbst/multiset.go
+//go:generate embed file --var multisetTestSrc --source ../../set/redblackbst/multiset_props_test.go
+//go:generate embed file --var multisetBenchSrc --source ../../set/redblackbst/multiset_bench_test.go
+//go:generate embed file --var intervalTreeSrc --source ../../itree/itree.go
+//go:generate embed file --var intervalTreeTestSrc --source ../../itree/props_test.go
+//go:generate embed file --var intervalTreeBenchSrc --source ../../itree/bench_test.go
+//go:generate embed file --var heapBenchSrc --source ../../heap/bench_test.go
+//go:generate embed file --var queueBenchSrc --source ../../queue/bench_test.go
 //go:generate embed file --var heapSrc --source ../../heap/heap.go
+//go:generate embed file --var indexedHeapSrc --source ../../heap/indexed.go
+//go:generate embed file --var indexedHeapTestSrc --source ../../heap/indexed_props_test.go
+//go:generate embed file --var indexedHeapBenchSrc --source ../../heap/indexed_bench_test.go
 //go:generate embed file --var queueSrc --source ../../queue/queue.go
+//go:generate embed file --var boundedQueueSrc --source ../../queue/bounded.go
+//go:generate embed file --var boundedQueueTestSrc --source ../../queue/bounded_props_test.go
+//go:generate embed file --var boundedQueueBenchSrc --source ../../queue/bounded_bench_test.go
+//go:generate embed file --var blockingQueueSrc --source ../../queue/blocking.go
+//go:generate embed file --var blockingQueueTestSrc --source ../../queue/blocking_props_test.go
+//go:generate embed file --var blockingQueueBenchSrc --source ../../queue/blocking_bench_test.go
+//go:generate embed file --var dequeSrc --source ../../deque/deque.go
+//go:generate embed file --var dequeTestSrc --source ../../deque/props_test.go
+//go:generate embed file --var dequeBenchSrc --source ../../deque/bench_test.go
+//go:generate embed file --var spscQueueSrc --source ../../ringq/spsc.go
+//go:generate embed file --var spscQueueTestSrc --source ../../ringq/spsc_props_test.go
+//go:generate embed file --var spscQueueBenchSrc --source ../../ringq/spsc_bench_test.go
+//go:generate embed file --var mpmcQueueSrc --source ../../ringq/mpmc.go
+//go:generate embed file --var mpmcQueueTestSrc --source ../../ringq/mpmc_props_test.go
+//go:generate embed file --var mpmcQueueBenchSrc --source ../../ringq/mpmc_bench_test.go
 
 const (
-	redblackbstMapSrc = "package redblackbst\n\nfunc (r RedBlack) compare(a, b KType) int { return a.Compare(b) }\n\n// RedBlack is a sorted map built on a left leaning red black balanced\n// search sorted map. It stores VType values, keyed by KType.\ntype RedBlack struct {\n\troot *mapnode\n}\n\n// NewRedBlack creates a sorted map.\nfunc NewRedBlack() *RedBlack { return &RedBlack{} }\n\n// IsEmpty tells if the sorted map contains no key/value.\nfunc (r RedBlack) IsEmpty() bool {\n\treturn r.root == nil\n}\n\n// Size of the sorted map.\nfunc (r RedBlack) Size() int { return r.root.size() }\n\n// Clear all the values in the sorted map.\nfunc (r *RedBlack) Clear() { r.root = nil }\n\n// Put a value in the sorted map at key `k`. The old value at `k` is returned\n// if the key was already present.\nfunc (r *RedBlack) Put(k KType, v VType) (old VType, overwrite bool) {\n\tr.root, old, overwrite = r.put(r.root, k, v)\n\treturn\n}\n\nfunc (r *RedBlack) put(h *mapnode, k KType, v VType) (_ *mapnode, old VType, overwrite bool) {\n\tif h == nil {\n\t\tn := &mapnode{key: k, val: v, n: 1, colorRed: true}\n\t\treturn n, old, overwrite\n\t}\n\n\tcmp := r.compare(k, h.key)\n\tif cmp < 0 {\n\t\th.left, old, overwrite = r.put(h.left, k, v)\n\t} else if cmp > 0 {\n\t\th.right, old, overwrite = r.put(h.right, k, v)\n\t} else {\n\t\toverwrite = true\n\t\told = h.val\n\t\th.val = v\n\t}\n\n\tif h.right.isRed() && !h.left.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\th.n = h.left.size() + h.right.size() + 1\n\treturn h, old, overwrite\n}\n\n// Get a value from the sorted map at key `k`. Returns false\n// if the key doesn't exist.\nfunc (r RedBlack) Get(k KType) (VType, bool) {\n\treturn r.loopGet(r.root, k)\n}\n\nfunc (r RedBlack) loopGet(h *mapnode, k KType) (v VType, ok bool) {\n\tfor h != nil {\n\t\tcmp := r.compare(k, h.key)\n\t\tif cmp == 0 {\n\t\t\treturn h.val, true\n\t\t} else if cmp < 0 {\n\t\t\th = h.left\n\t\t} else if cmp > 0 {\n\t\t\th = h.right\n\t\t}\n\t}\n\treturn\n}\n\n// Has tells if a value exists at key `k`. This is short hand for `Get.\nfunc (r RedBlack) Has(k KType) bool {\n\t_, ok := r.loopGet(r.root, k)\n\treturn ok\n}\n\n// Min returns the smallest key/value in the sorted map, if it exists.\nfunc (r RedBlack) Min() (k KType, v VType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\th := r.min(r.root)\n\treturn h.key, h.val, true\n}\n\nfunc (r RedBlack) min(x *mapnode) *mapnode {\n\tif x.left == nil {\n\t\treturn x\n\t}\n\treturn r.min(x.left)\n}\n\n// Max returns the largest key/value in the sorted map, if it exists.\nfunc (r RedBlack) Max() (k KType, v VType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\th := r.max(r.root)\n\treturn h.key, h.val, true\n}\n\nfunc (r RedBlack) max(x *mapnode) *mapnode {\n\tif x.right == nil {\n\t\treturn x\n\t}\n\treturn r.max(x.right)\n}\n\n// Floor returns the largest key/value in the sorted map that is smaller than\n// `k`.\nfunc (r RedBlack) Floor(key KType) (k KType, v VType, ok bool) {\n\tx := r.floor(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, x.val, true\n}\n\nfunc (r RedBlack) floor(h *mapnode, k KType) *mapnode {\n\tif h == nil {\n\t\treturn nil\n\t}\n\tcmp := r.compare(k, h.key)\n\tif cmp == 0 {\n\t\treturn h\n\t}\n\tif cmp < 0 {\n\t\treturn r.floor(h.left, k)\n\t}\n\tt := r.floor(h.right, k)\n\tif t != nil {\n\t\treturn t\n\t}\n\treturn h\n}\n\n// Ceiling returns the smallest key/value in the sorted map that is larger than\n// `k`.\nfunc (r RedBlack) Ceiling(key KType) (k KType, v VType, ok bool) {\n\tx := r.ceiling(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, x.val, true\n}\n\nfunc (r RedBlack) ceiling(h *mapnode, k KType) *mapnode {\n\tif h == nil {\n\t\treturn nil\n\t}\n\tcmp := r.compare(k, h.key)\n\tif cmp == 0 {\n\t\treturn h\n\t}\n\tif cmp > 0 {\n\t\treturn r.ceiling(h.right, k)\n\t}\n\tt := r.ceiling(h.left, k)\n\tif t != nil {\n\t\treturn t\n\t}\n\treturn h\n}\n\n// Select key of rank k, meaning the k-th biggest KType in the sorted map.\nfunc (r RedBlack) Select(key int) (k KType, v VType, ok bool) {\n\tx := r.nodeselect(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, x.val, true\n}\n\nfunc (r RedBlack) nodeselect(x *mapnode, k int) *mapnode {\n\tif x == nil {\n\t\treturn nil\n\t}\n\tt := x.left.size()\n\tif t > k {\n\t\treturn r.nodeselect(x.left, k)\n\t} else if t < k {\n\t\treturn r.nodeselect(x.right, k-t-1)\n\t} else {\n\t\treturn x\n\t}\n}\n\n// Rank is the number of keys less than `k`.\nfunc (r RedBlack) Rank(k KType) int {\n\treturn r.keyrank(k, r.root)\n}\n\nfunc (r RedBlack) keyrank(k KType, h *mapnode) int {\n\tif h == nil {\n\t\treturn 0\n\t}\n\tcmp := r.compare(k, h.key)\n\tif cmp < 0 {\n\t\treturn r.keyrank(k, h.left)\n\t} else if cmp > 0 {\n\t\treturn 1 + h.left.size() + r.keyrank(k, h.right)\n\t} else {\n\t\treturn h.left.size()\n\t}\n}\n\n// Keys visit each keys in the sorted map, in order.\n// It stops when visit returns false.\nfunc (r RedBlack) Keys(visit func(KType, VType) bool) {\n\tmin, _, ok := r.Min()\n\tif !ok {\n\t\treturn\n\t}\n\t// if the min exists, then the max must exist\n\tmax, _, _ := r.Max()\n\tr.RangedKeys(min, max, visit)\n}\n\n// RangedKeys visit each keys between lo and hi in the sorted map, in order.\n// It stops when visit returns false.\nfunc (r RedBlack) RangedKeys(lo, hi KType, visit func(KType, VType) bool) {\n\tr.keys(r.root, visit, lo, hi)\n}\n\nfunc (r RedBlack) keys(h *mapnode, visit func(KType, VType) bool, lo, hi KType) bool {\n\tif h == nil {\n\t\treturn true\n\t}\n\tcmplo := r.compare(lo, h.key)\n\tcmphi := r.compare(hi, h.key)\n\tif cmplo < 0 {\n\t\tif !r.keys(h.left, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmplo <= 0 && cmphi >= 0 {\n\t\tif !visit(h.key, h.val) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmphi > 0 {\n\t\tif !r.keys(h.right, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\n// DeleteMin removes the smallest key and its value from the sorted map.\nfunc (r *RedBlack) DeleteMin() (oldk KType, oldv VType, ok bool) {\n\tr.root, oldk, oldv, ok = r.deleteMin(r.root)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\nfunc (r *RedBlack) deleteMin(h *mapnode) (_ *mapnode, oldk KType, oldv VType, ok bool) {\n\tif h == nil {\n\t\treturn nil, oldk, oldv, false\n\t}\n\n\tif h.left == nil {\n\t\treturn nil, h.key, h.val, true\n\t}\n\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\th = r.moveRedLeft(h)\n\t}\n\th.left, oldk, oldv, ok = r.deleteMin(h.left)\n\treturn r.balance(h), oldk, oldv, ok\n}\n\n// DeleteMax removes the largest key and its value from the sorted map.\nfunc (r *RedBlack) DeleteMax() (oldk KType, oldv VType, ok bool) {\n\tr.root, oldk, oldv, ok = r.deleteMax(r.root)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\nfunc (r *RedBlack) deleteMax(h *mapnode) (_ *mapnode, oldk KType, oldv VType, ok bool) {\n\tif h == nil {\n\t\treturn nil, oldk, oldv, ok\n\t}\n\tif h.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.right == nil {\n\t\treturn nil, h.key, h.val, true\n\t}\n\tif !h.right.isRed() && !h.right.left.isRed() {\n\t\th = r.moveRedRight(h)\n\t}\n\th.right, oldk, oldv, ok = r.deleteMax(h.right)\n\treturn r.balance(h), oldk, oldv, ok\n}\n\n// Delete key `k` from sorted map, if it exists.\nfunc (r *RedBlack) Delete(k KType) (old VType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\tr.root, old, ok = r.delete(r.root, k)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\nfunc (r *RedBlack) delete(h *mapnode, k KType) (_ *mapnode, old VType, ok bool) {\n\n\tif h == nil {\n\t\treturn h, old, false\n\t}\n\n\tif r.compare(k, h.key) < 0 {\n\t\tif h.left == nil {\n\t\t\treturn h, old, false\n\t\t}\n\n\t\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\t\th = r.moveRedLeft(h)\n\t\t}\n\n\t\th.left, old, ok = r.delete(h.left, k)\n\t\th = r.balance(h)\n\t\treturn h, old, ok\n\t}\n\n\tif h.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\n\tif r.compare(k, h.key) == 0 && h.right == nil {\n\t\treturn nil, h.val, true\n\t}\n\n\tif h.right != nil && !h.right.isRed() && !h.right.left.isRed() {\n\t\th = r.moveRedRight(h)\n\t}\n\n\tif r.compare(k, h.key) == 0 {\n\n\t\tvar subk KType\n\t\tvar subv VType\n\t\th.right, subk, subv, ok = r.deleteMin(h.right)\n\n\t\told, h.key, h.val = h.val, subk, subv\n\t\tok = true\n\t} else {\n\t\th.right, old, ok = r.delete(h.right, k)\n\t}\n\n\th = r.balance(h)\n\treturn h, old, ok\n}\n\n// deletions\n\nfunc (r *RedBlack) moveRedLeft(h *mapnode) *mapnode {\n\tr.flipColors(h)\n\tif h.right.left.isRed() {\n\t\th.right = r.rotateRight(h.right)\n\t\th = r.rotateLeft(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *RedBlack) moveRedRight(h *mapnode) *mapnode {\n\tr.flipColors(h)\n\tif h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *RedBlack) balance(h *mapnode) *mapnode {\n\tif h.right.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\th.n = h.left.size() + h.right.size() + 1\n\treturn h\n}\n\nfunc (r *RedBlack) rotateLeft(h *mapnode) *mapnode {\n\tx := h.right\n\th.right = x.left\n\tx.left = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tx.n = h.n\n\th.n = 1 + h.left.size() + h.right.size()\n\treturn x\n}\n\nfunc (r *RedBlack) rotateRight(h *mapnode) *mapnode {\n\tx := h.left\n\th.left = x.right\n\tx.right = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tx.n = h.n\n\th.n = 1 + h.left.size() + h.right.size()\n\treturn x\n}\n\nfunc (r *RedBlack) flipColors(h *mapnode) {\n\th.colorRed = !h.colorRed\n\th.left.colorRed = !h.left.colorRed\n\th.right.colorRed = !h.right.colorRed\n}\n\n// nodes\n\ntype mapnode struct {\n\tkey         KType\n\tval         VType\n\tleft, right *mapnode\n\tn           int\n\tcolorRed    bool\n}\n\nfunc (x *mapnode) isRed() bool { return (x != nil) && (x.colorRed == true) }\n\nfunc (x *mapnode) size() int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\treturn x.n\n}\n"
-	redblackbstSetSrc = "package redblackbst\n\nfunc (r RedBlack) compare(a, b KType) int { return a.Compare(b) }\n\n// RedBlack is a sorted set built on a left leaning red black balanced\n// search sorted set. It stores unique KType values.\ntype RedBlack struct {\n\troot *treenode\n}\n\n// NewRedBlack creates a sorted set.\nfunc NewRedBlack() *RedBlack { return &RedBlack{} }\n\n// IsEmpty tells if the sorted set contains no key.\nfunc (r RedBlack) IsEmpty() bool {\n\treturn r.root == nil\n}\n\n// Size of the sorted set.\nfunc (r RedBlack) Size() int { return r.root.size() }\n\n// Clear all the values in the sorted set.\nfunc (r *RedBlack) Clear() { r.root = nil }\n\n// Put the key `k` in the sorted set. If the value was already there,\n// true is returned.\nfunc (r *RedBlack) Put(k KType) (already bool) {\n\tr.root, already = r.put(r.root, k)\n\treturn\n}\n\nfunc (r *RedBlack) put(h *treenode, k KType) (_ *treenode, already bool) {\n\tif h == nil {\n\t\tn := &treenode{key: k, n: 1, colorRed: true}\n\t\treturn n, already\n\t}\n\n\tcmp := r.compare(k, h.key)\n\tif cmp < 0 {\n\t\th.left, already = r.put(h.left, k)\n\t} else if cmp > 0 {\n\t\th.right, already = r.put(h.right, k)\n\t} else {\n\t\talready = true\n\t}\n\n\tif h.right.isRed() && !h.left.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\th.n = h.left.size() + h.right.size() + 1\n\treturn h, already\n}\n\n// Contains tells if `k` is a member of the set.\nfunc (r RedBlack) Contains(k KType) bool {\n\treturn r.loopContains(r.root, k)\n}\n\nfunc (r RedBlack) loopContains(h *treenode, k KType) (ok bool) {\n\tfor h != nil {\n\t\tcmp := r.compare(k, h.key)\n\t\tif cmp == 0 {\n\t\t\treturn true\n\t\t} else if cmp < 0 {\n\t\t\th = h.left\n\t\t} else if cmp > 0 {\n\t\t\th = h.right\n\t\t}\n\t}\n\treturn\n}\n\n// Min returns the smallest key in the sorted set, if it exists.\nfunc (r RedBlack) Min() (k KType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\th := r.min(r.root)\n\treturn h.key, true\n}\n\nfunc (r RedBlack) min(x *treenode) *treenode {\n\tif x.left == nil {\n\t\treturn x\n\t}\n\treturn r.min(x.left)\n}\n\n// Max returns the largest key in the sorted set, if it exists.\nfunc (r RedBlack) Max() (k KType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\th := r.max(r.root)\n\treturn h.key, true\n}\n\nfunc (r RedBlack) max(x *treenode) *treenode {\n\tif x.right == nil {\n\t\treturn x\n\t}\n\treturn r.max(x.right)\n}\n\n// Floor returns the largest key in the sorted set that is smaller than\n// `k`.\nfunc (r RedBlack) Floor(key KType) (k KType, ok bool) {\n\tx := r.floor(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, true\n}\n\nfunc (r RedBlack) floor(h *treenode, k KType) *treenode {\n\tif h == nil {\n\t\treturn nil\n\t}\n\tcmp := r.compare(k, h.key)\n\tif cmp == 0 {\n\t\treturn h\n\t}\n\tif cmp < 0 {\n\t\treturn r.floor(h.left, k)\n\t}\n\tt := r.floor(h.right, k)\n\tif t != nil {\n\t\treturn t\n\t}\n\treturn h\n}\n\n// Ceiling returns the smallest key in the sorted set that is larger than\n// `k`.\nfunc (r RedBlack) Ceiling(key KType) (k KType, ok bool) {\n\tx := r.ceiling(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, true\n}\n\nfunc (r RedBlack) ceiling(h *treenode, k KType) *treenode {\n\tif h == nil {\n\t\treturn nil\n\t}\n\tcmp := r.compare(k, h.key)\n\tif cmp == 0 {\n\t\treturn h\n\t}\n\tif cmp > 0 {\n\t\treturn r.ceiling(h.right, k)\n\t}\n\tt := r.ceiling(h.left, k)\n\tif t != nil {\n\t\treturn t\n\t}\n\treturn h\n}\n\n// Select key of rank k, meaning the k-th biggest KType in the sorted set.\nfunc (r RedBlack) Select(key int) (k KType, ok bool) {\n\tx := r.nodeselect(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, true\n}\n\nfunc (r RedBlack) nodeselect(x *treenode, k int) *treenode {\n\tif x == nil {\n\t\treturn nil\n\t}\n\tt := x.left.size()\n\tif t > k {\n\t\treturn r.nodeselect(x.left, k)\n\t} else if t < k {\n\t\treturn r.nodeselect(x.right, k-t-1)\n\t} else {\n\t\treturn x\n\t}\n}\n\n// Rank is the number of keys less than `k`.\nfunc (r RedBlack) Rank(k KType) int {\n\treturn r.keyrank(k, r.root)\n}\n\nfunc (r RedBlack) keyrank(k KType, h *treenode) int {\n\tif h == nil {\n\t\treturn 0\n\t}\n\tcmp := r.compare(k, h.key)\n\tif cmp < 0 {\n\t\treturn r.keyrank(k, h.left)\n\t} else if cmp > 0 {\n\t\treturn 1 + h.left.size() + r.keyrank(k, h.right)\n\t} else {\n\t\treturn h.left.size()\n\t}\n}\n\n// Keys visit each keys in the sorted set, in order.\n// It stops when visit returns false.\nfunc (r RedBlack) Keys(visit func(KType) bool) {\n\tmin, ok := r.Min()\n\tif !ok {\n\t\treturn\n\t}\n\t// if the min exists, then the max must exist\n\tmax, _ := r.Max()\n\tr.RangedKeys(min, max, visit)\n}\n\n// RangedKeys visit each keys between lo and hi in the sorted set, in order.\n// It stops when visit returns false.\nfunc (r RedBlack) RangedKeys(lo, hi KType, visit func(KType) bool) {\n\tr.keys(r.root, visit, lo, hi)\n}\n\nfunc (r RedBlack) keys(h *treenode, visit func(KType) bool, lo, hi KType) bool {\n\tif h == nil {\n\t\treturn true\n\t}\n\tcmplo := r.compare(lo, h.key)\n\tcmphi := r.compare(hi, h.key)\n\tif cmplo < 0 {\n\t\tif !r.keys(h.left, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmplo <= 0 && cmphi >= 0 {\n\t\tif !visit(h.key) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmphi > 0 {\n\t\tif !r.keys(h.right, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\n// DeleteMin removes the smallest key from the sorted set.\nfunc (r *RedBlack) DeleteMin() (oldk KType, ok bool) {\n\tr.root, oldk, ok = r.deleteMin(r.root)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\nfunc (r *RedBlack) deleteMin(h *treenode) (_ *treenode, oldk KType, ok bool) {\n\tif h == nil {\n\t\treturn nil, oldk, false\n\t}\n\n\tif h.left == nil {\n\t\treturn nil, h.key, true\n\t}\n\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\th = r.moveRedLeft(h)\n\t}\n\th.left, oldk, ok = r.deleteMin(h.left)\n\treturn r.balance(h), oldk, ok\n}\n\n// DeleteMax removes the largest key from the sorted set.\nfunc (r *RedBlack) DeleteMax() (oldk KType, ok bool) {\n\tr.root, oldk, ok = r.deleteMax(r.root)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\nfunc (r *RedBlack) deleteMax(h *treenode) (_ *treenode, oldk KType, ok bool) {\n\tif h == nil {\n\t\treturn nil, oldk, ok\n\t}\n\tif h.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.right == nil {\n\t\treturn nil, h.key, true\n\t}\n\tif !h.right.isRed() && !h.right.left.isRed() {\n\t\th = r.moveRedRight(h)\n\t}\n\th.right, oldk, ok = r.deleteMax(h.right)\n\treturn r.balance(h), oldk, ok\n}\n\n// Delete key `k` from sorted set, if it exists.\nfunc (r *RedBlack) Delete(k KType) (ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\tr.root, ok = r.delete(r.root, k)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\nfunc (r *RedBlack) delete(h *treenode, k KType) (_ *treenode, ok bool) {\n\n\tif h == nil {\n\t\treturn h, false\n\t}\n\n\tif r.compare(k, h.key) < 0 {\n\t\tif h.left == nil {\n\t\t\treturn h, false\n\t\t}\n\n\t\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\t\th = r.moveRedLeft(h)\n\t\t}\n\n\t\th.left, ok = r.delete(h.left, k)\n\t\th = r.balance(h)\n\t\treturn h, ok\n\t}\n\n\tif h.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\n\tif r.compare(k, h.key) == 0 && h.right == nil {\n\t\treturn nil, true\n\t}\n\n\tif h.right != nil && !h.right.isRed() && !h.right.left.isRed() {\n\t\th = r.moveRedRight(h)\n\t}\n\n\tif r.compare(k, h.key) == 0 {\n\n\t\tvar subk KType\n\t\th.right, subk, ok = r.deleteMin(h.right)\n\t\th.key = subk\n\t\tok = true\n\t} else {\n\t\th.right, ok = r.delete(h.right, k)\n\t}\n\n\th = r.balance(h)\n\treturn h, ok\n}\n\n// deletions\n\nfunc (r *RedBlack) moveRedLeft(h *treenode) *treenode {\n\tr.flipColors(h)\n\tif h.right.left.isRed() {\n\t\th.right = r.rotateRight(h.right)\n\t\th = r.rotateLeft(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *RedBlack) moveRedRight(h *treenode) *treenode {\n\tr.flipColors(h)\n\tif h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *RedBlack) balance(h *treenode) *treenode {\n\tif h.right.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\th.n = h.left.size() + h.right.size() + 1\n\treturn h\n}\n\nfunc (r *RedBlack) rotateLeft(h *treenode) *treenode {\n\tx := h.right\n\th.right = x.left\n\tx.left = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tx.n = h.n\n\th.n = 1 + h.left.size() + h.right.size()\n\treturn x\n}\n\nfunc (r *RedBlack) rotateRight(h *treenode) *treenode {\n\tx := h.left\n\th.left = x.right\n\tx.right = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tx.n = h.n\n\th.n = 1 + h.left.size() + h.right.size()\n\treturn x\n}\n\nfunc (r *RedBlack) flipColors(h *treenode) {\n\th.colorRed = !h.colorRed\n\th.left.colorRed = !h.left.colorRed\n\th.right.colorRed = !h.right.colorRed\n}\n\n// nodes\n\ntype treenode struct {\n\tkey         KType\n\tleft, right *treenode\n\tn           int\n\tcolorRed    bool\n}\n\nfunc (x *treenode) isRed() bool { return (x != nil) && (x.colorRed == true) }\n\nfunc (x *treenode) size() int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\treturn x.n\n}\n"
-	heapSrc           = "package heap\n\n// Most of the implementation is adapted from Algorithms 4ed by Sedgewick\n// and Wayne.\n\n// Comments are adapted from `container/heap`.\n// \t Copyright 2009 The Go Authors. All rights reserved.\n// \t Use of this source code is governed by a BSD-style\n// \t license that can be found in the LICENSE file.\n\nfunc (h Heap) compare(a, b KType) int { return a.Compare(b) }\n\n// Heap is a container of KType, where the elements can be efficiently\n// retrieved in their decreasing order (according to their comparison\n// rules).\ntype Heap struct {\n\tn  int\n\tpq []KType\n}\n\n// NewHeap creates a heap, optionaly with keys already populating\n// it. The complexity is O(n) where n = len(keys).\nfunc NewHeap(keys ...KType) *Heap {\n\th := &Heap{\n\t\tn:  len(keys),\n\t\tpq: append(make([]KType, 1), keys...),\n\t}\n\th.Fix()\n\treturn h\n}\n\n// Len is the number of elements stored in the heap.\nfunc (h *Heap) Len() int { return h.n }\n\n// Peek at the largest element (according to their comparison rules), without\n// removing it from the heap.\nfunc (h *Heap) Peek() KType { return h.pq[1] }\n\n// Fix re-establishes the heap ordering. This is useful if elements\n// of the heap have had their comparison value changed. It is equivalent to,\n// but less expenasive than, Pop'ing all the elements and Push'ing them\n// again.\n// The complexity is O(n).\nfunc (h *Heap) Fix() {\n\tfor i := (h.n) / 2; i > 0; i-- {\n\t\th.sink(i, h.n)\n\t}\n}\n\n// Push pushes the element k onto the heap. The complexity is\n// O(log(n)) where n == h.Len().\nfunc (h *Heap) Push(k KType) {\n\th.n++\n\th.pq = append(h.pq, k)\n\th.swim(h.n)\n}\n\n// Pop removes the largest element (according to their comparison rules) from\n// the heap and returns it. The complexity is O(log(n)) where n == h.Len().\nfunc (h *Heap) Pop() KType {\n\tval := h.pq[1]\n\th.swap(1, h.n)\n\th.pq = h.pq[:h.n]\n\th.n--\n\th.sink(1, h.n)\n\n\treturn val\n}\n\n// Remove removes k from the heap, if it exists. Equality is defined by\n// Compare == 0.\n// The complexity is O(n+log(n)) where n == h.Len().\nfunc (h *Heap) Remove(k KType) bool {\n\n\tcmp := h.compare(h.pq[1], k)\n\tif cmp == 0 {\n\t\t_ = h.Pop()\n\t\treturn true\n\t}\n\tif cmp < 0 {\n\t\t// larger than largest, don't try to find it\n\t\treturn false\n\t}\n\n\ti := 0\n\tfor _, j := range h.pq[1:] {\n\t\ti++\n\t\tif h.compare(j, k) != 0 {\n\t\t\tcontinue\n\t\t}\n\t\th.swap(i, 1)\n\t\th.sink(i, h.n)\n\t\t_ = h.Pop()\n\t\treturn true\n\t}\n\t// not in the heap\n\treturn false\n}\n\nfunc (h *Heap) swap(i, j int)      { h.pq[i], h.pq[j] = h.pq[j], h.pq[i] }\nfunc (h *Heap) less(i, j int) bool { return h.compare(h.pq[i], h.pq[j]) < 0 }\n\nfunc (h *Heap) swim(k int) {\n\tfor k > 1 && h.less(k/2, k) {\n\t\th.swap(k/2, k)\n\t\tk = k / 2\n\t}\n}\n\nfunc (h *Heap) sink(k, n int) {\n\n\tfor k*2 <= n {\n\t\tj := 2 * k\n\t\tif j < n && h.less(j, j+1) {\n\t\t\tj++\n\t\t}\n\t\tif !h.less(k, j) {\n\t\t\tbreak\n\t\t}\n\t\th.swap(k, j)\n\t\tk = j\n\t}\n}\n"
-	queueSrc          = "package queue\n\n// Implementation adapted from github.com/eapache/queue:\n//    The MIT License (MIT)\n//    Copyright (c) 2014 Evan Huus\n\nvar nilKType KType\n\n// Queue represents a single instance of the queue data structure.\ntype Queue struct {\n\tbuf               []KType\n\thead, tail, count int\n\tminlen            int\n}\n\n// NewQueue constructs and returns a new Queue with an initial capacity.\nfunc NewQueue(capacity int) *Queue {\n\t// min capacity of 16\n\tif capacity < 16 {\n\t\tcapacity = 16\n\t}\n\treturn &Queue{buf: make([]KType, capacity), minlen: capacity}\n}\n\n// Len returns the number of elements currently stored in the queue.\nfunc (q *Queue) Len() int {\n\treturn q.count\n}\n\n// Push puts an element on the end of the queue.\nfunc (q *Queue) Push(elem KType) {\n\tif q.count == len(q.buf) {\n\t\tq.resize()\n\t}\n\n\tq.buf[q.tail] = elem\n\tq.tail = (q.tail + 1) % len(q.buf)\n\tq.count++\n}\n\n// Peek returns the element at the head of the queue. This call panics\n// if the queue is empty.\nfunc (q *Queue) Peek() KType {\n\tif q.Len() <= 0 {\n\t\tpanic(\"queue: empty queue\")\n\t}\n\treturn q.buf[q.head]\n}\n\n// Get returns the element at index i in the queue. If the index is\n// invalid, the call will panic.\nfunc (q *Queue) Get(i int) KType {\n\tif i >= q.Len() || i < 0 {\n\t\tpanic(\"queue: index out of range\")\n\t}\n\tmodi := (q.head + i) % len(q.buf)\n\treturn q.buf[modi]\n}\n\n// Pop removes the element from the front of the queue.\n// This call panics if the queue is empty.\nfunc (q *Queue) Pop() KType {\n\tif q.Len() <= 0 {\n\t\tpanic(\"queue: empty queue\")\n\t}\n\tv := q.buf[q.head]\n\t// set to nil to avoid keeping reference to objects\n\t// that would otherwise be garbage collected\n\tq.buf[q.head] = nilKType\n\tq.head = (q.head + 1) % len(q.buf)\n\tq.count--\n\tif len(q.buf) > q.minlen && q.count*4 <= len(q.buf) {\n\t\tq.resize()\n\t}\n\treturn v\n}\n\nfunc (q *Queue) resize() {\n\tnewBuf := make([]KType, q.count*2)\n\n\tif q.tail > q.head {\n\t\tcopy(newBuf, q.buf[q.head:q.tail])\n\t} else {\n\t\tcopy(newBuf, q.buf[q.head:len(q.buf)])\n\t\tcopy(newBuf[len(q.buf)-q.head:], q.buf[:q.tail])\n\t}\n\n\tq.head = 0\n\tq.tail = q.count\n\tq.buf = newBuf\n}\n"
+	redblackbstMapSrc      = "package redblackbst\n\nimport \"sort\"\n\nfunc (r RedBlack) compare(a, b KType) int { return a.Compare(b) }\n\n// measure is the aggregate of the single key/value `k`, `v`.\nfunc (r RedBlack) measure(k KType, v VType) AType { return 1 }\n\n// combine is the aggregate of two consecutive ranges of keys, whose\n// aggregates are `a` and `b`, in order. It must be associative.\nfunc (r RedBlack) combine(a, b AType) AType { return a.(int) + b.(int) }\n\n// RedBlack is a sorted map built on a left leaning red black balanced\n// search sorted map. It stores VType values, keyed by KType.\ntype RedBlack struct {\n\troot *mapnode\n}\n\n// NewRedBlack creates a sorted map.\nfunc NewRedBlack() *RedBlack { return &RedBlack{} }\n\n// NewRedBlackFromSorted creates a sorted map of the keys, given in order, and\n// of their values, in O(n) rather than the O(n log n) of putting them one by\n// one. Of equal keys, the first one is kept with the value of the last, as\n// putting them would. It panics if the keys aren't in order, or if there\n// isn't a value for each key.\nfunc NewRedBlackFromSorted(keys []KType, vals []VType) *RedBlack {\n\tr := &RedBlack{}\n\tif len(keys) != len(vals) {\n\t\tpanic(\"redblackbst: as many keys as values are needed\")\n\t}\n\tvar dups bool\n\tfor i := 1; i < len(keys); i++ {\n\t\tcmp := r.compare(keys[i-1], keys[i])\n\t\tif cmp > 0 {\n\t\t\tpanic(\"redblackbst: keys aren't sorted\")\n\t\t}\n\t\tdups = dups || cmp == 0\n\t}\n\tif dups {\n\t\t// not to modify the slices given\n\t\tkeys, vals = r.dedup(append([]KType(nil), keys...), append([]VType(nil), vals...))\n\t}\n\tr.root = r.build(keys, vals)\n\treturn r\n}\n\n// NewRedBlackFromUnsorted creates a sorted map of the keys and their values,\n// sorting them first, in O(n log n) but faster than putting them one by one.\n// Of equal keys, the first one is kept with the value of the last, as putting\n// them would. The slices aren't modified. It panics if there isn't a value\n// for each key.\nfunc NewRedBlackFromUnsorted(keys []KType, vals []VType) *RedBlack {\n\tr := &RedBlack{}\n\tif len(keys) != len(vals) {\n\t\tpanic(\"redblackbst: as many keys as values are needed\")\n\t}\n\torder := make([]int, len(keys))\n\tfor i := range order {\n\t\torder[i] = i\n\t}\n\tsort.SliceStable(order, func(i, j int) bool {\n\t\treturn r.compare(keys[order[i]], keys[order[j]]) < 0\n\t})\n\tsortedKeys := make([]KType, len(keys))\n\tsortedVals := make([]VType, len(vals))\n\tfor i, at := range order {\n\t\tsortedKeys[i], sortedVals[i] = keys[at], vals[at]\n\t}\n\tsortedKeys, sortedVals = r.dedup(sortedKeys, sortedVals)\n\tr.root = r.build(sortedKeys, sortedVals)\n\treturn r\n}\n\n// IsEmpty tells if the sorted map contains no key/value.\nfunc (r RedBlack) IsEmpty() bool {\n\treturn r.root == nil\n}\n\n// Size of the sorted map.\nfunc (r RedBlack) Size() int { return r.root.size() }\n\n// Clear all the values in the sorted map.\nfunc (r *RedBlack) Clear() { r.root = nil }\n\n// Put a value in the sorted map at key `k`. The old value at `k` is returned\n// if the key was already present.\nfunc (r *RedBlack) Put(k KType, v VType) (old VType, overwrite bool) {\n\tr.root, old, overwrite = r.put(r.root, k, v)\n\tr.root.colorRed = false\n\treturn\n}\n\nfunc (r *RedBlack) put(h *mapnode, k KType, v VType) (_ *mapnode, old VType, overwrite bool) {\n\tif h == nil {\n\t\th = &mapnode{key: k, val: v, colorRed: true}\n\t\tr.update(h)\n\t\treturn h, old, overwrite\n\t}\n\n\tcmp := r.compare(k, h.key)\n\tif cmp < 0 {\n\t\th.left, old, overwrite = r.put(h.left, k, v)\n\t} else if cmp > 0 {\n\t\th.right, old, overwrite = r.put(h.right, k, v)\n\t} else {\n\t\toverwrite = true\n\t\told = h.val\n\t\th.val = v\n\t}\n\n\tif h.right.isRed() && !h.left.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\tr.update(h)\n\treturn h, old, overwrite\n}\n\n// Get a value from the sorted map at key `k`. Returns false\n// if the key doesn't exist.\nfunc (r RedBlack) Get(k KType) (VType, bool) {\n\treturn r.loopGet(r.root, k)\n}\n\nfunc (r RedBlack) loopGet(h *mapnode, k KType) (v VType, ok bool) {\n\tfor h != nil {\n\t\tcmp := r.compare(k, h.key)\n\t\tif cmp == 0 {\n\t\t\treturn h.val, true\n\t\t} else if cmp < 0 {\n\t\t\th = h.left\n\t\t} else if cmp > 0 {\n\t\t\th = h.right\n\t\t}\n\t}\n\treturn\n}\n\n// Has tells if a value exists at key `k`. This is short hand for `Get.\nfunc (r RedBlack) Has(k KType) bool {\n\t_, ok := r.loopGet(r.root, k)\n\treturn ok\n}\n\n// Min returns the smallest key/value in the sorted map, if it exists.\nfunc (r RedBlack) Min() (k KType, v VType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\th := r.min(r.root)\n\treturn h.key, h.val, true\n}\n\nfunc (r RedBlack) min(x *mapnode) *mapnode {\n\tif x.left == nil {\n\t\treturn x\n\t}\n\treturn r.min(x.left)\n}\n\n// Max returns the largest key/value in the sorted map, if it exists.\nfunc (r RedBlack) Max() (k KType, v VType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\th := r.max(r.root)\n\treturn h.key, h.val, true\n}\n\nfunc (r RedBlack) max(x *mapnode) *mapnode {\n\tif x.right == nil {\n\t\treturn x\n\t}\n\treturn r.max(x.right)\n}\n\n// Floor returns the largest key/value in the sorted map that is smaller than\n// or equal to `k`, if it exists.\nfunc (r RedBlack) Floor(key KType) (k KType, v VType, ok bool) {\n\tx := r.floor(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, x.val, true\n}\n\nfunc (r RedBlack) floor(h *mapnode, k KType) *mapnode {\n\tif h == nil {\n\t\treturn nil\n\t}\n\tcmp := r.compare(k, h.key)\n\tif cmp == 0 {\n\t\treturn h\n\t}\n\tif cmp < 0 {\n\t\treturn r.floor(h.left, k)\n\t}\n\tt := r.floor(h.right, k)\n\tif t != nil {\n\t\treturn t\n\t}\n\treturn h\n}\n\n// Ceiling returns the smallest key/value in the sorted map that is larger than\n// or equal to `k`, if it exists.\nfunc (r RedBlack) Ceiling(key KType) (k KType, v VType, ok bool) {\n\tx := r.ceiling(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, x.val, true\n}\n\nfunc (r RedBlack) ceiling(h *mapnode, k KType) *mapnode {\n\tif h == nil {\n\t\treturn nil\n\t}\n\tcmp := r.compare(k, h.key)\n\tif cmp == 0 {\n\t\treturn h\n\t}\n\tif cmp > 0 {\n\t\treturn r.ceiling(h.right, k)\n\t}\n\tt := r.ceiling(h.left, k)\n\tif t != nil {\n\t\treturn t\n\t}\n\treturn h\n}\n\n// Lower returns the largest key/value in the sorted map that is strictly\n// smaller than `k`, its predecessor, if it exists.\nfunc (r RedBlack) Lower(key KType) (k KType, v VType, ok bool) {\n\tx := r.lower(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, x.val, true\n}\n\nfunc (r RedBlack) lower(h *mapnode, k KType) *mapnode {\n\tif h == nil {\n\t\treturn nil\n\t}\n\tif r.compare(k, h.key) <= 0 {\n\t\treturn r.lower(h.left, k)\n\t}\n\tt := r.lower(h.right, k)\n\tif t != nil {\n\t\treturn t\n\t}\n\treturn h\n}\n\n// Higher returns the smallest key/value in the sorted map that is strictly\n// larger than `k`, its successor, if it exists.\nfunc (r RedBlack) Higher(key KType) (k KType, v VType, ok bool) {\n\tx := r.higher(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, x.val, true\n}\n\nfunc (r RedBlack) higher(h *mapnode, k KType) *mapnode {\n\tif h == nil {\n\t\treturn nil\n\t}\n\tif r.compare(k, h.key) >= 0 {\n\t\treturn r.higher(h.right, k)\n\t}\n\tt := r.higher(h.left, k)\n\tif t != nil {\n\t\treturn t\n\t}\n\treturn h\n}\n\n// Select key of rank k, meaning the k-th biggest KType in the sorted map.\nfunc (r RedBlack) Select(key int) (k KType, v VType, ok bool) {\n\tx := r.nodeselect(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, x.val, true\n}\n\nfunc (r RedBlack) nodeselect(x *mapnode, k int) *mapnode {\n\tif x == nil {\n\t\treturn nil\n\t}\n\tt := x.left.size()\n\tif t > k {\n\t\treturn r.nodeselect(x.left, k)\n\t} else if t < k {\n\t\treturn r.nodeselect(x.right, k-t-1)\n\t} else {\n\t\treturn x\n\t}\n}\n\n// Rank is the number of keys less than `k`.\nfunc (r RedBlack) Rank(k KType) int {\n\treturn r.keyrank(k, r.root)\n}\n\nfunc (r RedBlack) keyrank(k KType, h *mapnode) int {\n\tif h == nil {\n\t\treturn 0\n\t}\n\tcmp := r.compare(k, h.key)\n\tif cmp < 0 {\n\t\treturn r.keyrank(k, h.left)\n\t} else if cmp > 0 {\n\t\treturn 1 + h.left.size() + r.keyrank(k, h.right)\n\t} else {\n\t\treturn h.left.size()\n\t}\n}\n\n// Keys visit each keys in the sorted map, in order.\n// It stops when visit returns false.\nfunc (r RedBlack) Keys(visit func(KType, VType) bool) {\n\tmin, _, ok := r.Min()\n\tif !ok {\n\t\treturn\n\t}\n\t// if the min exists, then the max must exist\n\tmax, _, _ := r.Max()\n\tr.RangedKeys(min, max, visit)\n}\n\n// RangedKeys visit each keys between lo and hi in the sorted map, in order.\n// It stops when visit returns false.\nfunc (r RedBlack) RangedKeys(lo, hi KType, visit func(KType, VType) bool) {\n\tr.keys(r.root, visit, lo, hi)\n}\n\nfunc (r RedBlack) keys(h *mapnode, visit func(KType, VType) bool, lo, hi KType) bool {\n\tif h == nil {\n\t\treturn true\n\t}\n\tcmplo := r.compare(lo, h.key)\n\tcmphi := r.compare(hi, h.key)\n\tif cmplo < 0 {\n\t\tif !r.keys(h.left, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmplo <= 0 && cmphi >= 0 {\n\t\tif !visit(h.key, h.val) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmphi > 0 {\n\t\tif !r.keys(h.right, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\n// ReverseKeys visit each keys in the sorted map, in reverse order.\n// It stops when visit returns false.\nfunc (r RedBlack) ReverseKeys(visit func(KType, VType) bool) {\n\tmin, _, ok := r.Min()\n\tif !ok {\n\t\treturn\n\t}\n\t// if the min exists, then the max must exist\n\tmax, _, _ := r.Max()\n\tr.RangedKeysDesc(min, max, visit)\n}\n\n// RangedKeysDesc visit each keys between lo and hi in the sorted map, in\n// reverse order. It stops when visit returns false.\nfunc (r RedBlack) RangedKeysDesc(lo, hi KType, visit func(KType, VType) bool) {\n\tr.keysDesc(r.root, visit, lo, hi)\n}\n\nfunc (r RedBlack) keysDesc(h *mapnode, visit func(KType, VType) bool, lo, hi KType) bool {\n\tif h == nil {\n\t\treturn true\n\t}\n\tcmplo := r.compare(lo, h.key)\n\tcmphi := r.compare(hi, h.key)\n\tif cmphi > 0 {\n\t\tif !r.keysDesc(h.right, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmplo <= 0 && cmphi >= 0 {\n\t\tif !visit(h.key, h.val) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmplo < 0 {\n\t\tif !r.keysDesc(h.left, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\n// Bound is an end of a range of keys: its Key, included in the range unless\n// it's Exclusive. An Unbounded end doesn't limit the range, whatever its Key.\ntype Bound struct {\n\tKey       KType\n\tExclusive bool\n\tUnbounded bool\n}\n\n// RangeCount is the number of keys between lo and hi in the sorted map. It\n// doesn't visit them, and takes O(log n).\nfunc (r RedBlack) RangeCount(lo, hi Bound) int {\n\tn := r.boundrank(hi, true) - r.boundrank(lo, false)\n\tif n < 0 {\n\t\treturn 0\n\t}\n\treturn n\n}\n\n// boundrank is the number of keys below b, or up to b for an upper bound.\nfunc (r RedBlack) boundrank(b Bound, upper bool) int {\n\tif b.Unbounded {\n\t\tif upper {\n\t\t\treturn r.Size()\n\t\t}\n\t\treturn 0\n\t}\n\t// the key itself is below an exclusive lower bound or an inclusive\n\t// upper bound\n\treturn r.keyrankBound(b.Key, r.root, upper != b.Exclusive)\n}\n\nfunc (r RedBlack) keyrankBound(k KType, h *mapnode, withKey bool) int {\n\tif h == nil {\n\t\treturn 0\n\t}\n\tcmp := r.compare(k, h.key)\n\tif cmp < 0 {\n\t\treturn r.keyrankBound(k, h.left, withKey)\n\t} else if cmp > 0 {\n\t\treturn 1 + h.left.size() + r.keyrankBound(k, h.right, withKey)\n\t} else if withKey {\n\t\treturn h.left.size() + 1\n\t} else {\n\t\treturn h.left.size()\n\t}\n}\n\n// BoundedKeys visit each keys between lo and hi in the sorted map, in order.\n// It stops when visit returns false.\nfunc (r RedBlack) BoundedKeys(lo, hi Bound, visit func(KType, VType) bool) {\n\tr.boundedKeys(r.root, visit, lo, hi)\n}\n\nfunc (r RedBlack) boundedKeys(h *mapnode, visit func(KType, VType) bool, lo, hi Bound) bool {\n\tif h == nil {\n\t\treturn true\n\t}\n\t// whether the keys left and right of h can be in the range\n\tleft, right := true, true\n\tinside := true\n\tif !lo.Unbounded {\n\t\tcmp := r.compare(lo.Key, h.key)\n\t\tleft = cmp < 0\n\t\tinside = cmp < 0 || (cmp == 0 && !lo.Exclusive)\n\t}\n\tif !hi.Unbounded {\n\t\tcmp := r.compare(hi.Key, h.key)\n\t\tright = cmp > 0\n\t\tinside = inside && (cmp > 0 || (cmp == 0 && !hi.Exclusive))\n\t}\n\tif left {\n\t\tif !r.boundedKeys(h.left, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif inside {\n\t\tif !visit(h.key, h.val) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif right {\n\t\tif !r.boundedKeys(h.right, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\n// Aggregate returns the aggregate of all the keys/values of the sorted map,\n// in O(1). It's not ok if the sorted map is empty.\nfunc (r RedBlack) Aggregate() (agg AType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\treturn r.root.agg, true\n}\n\n// RangeAggregate returns the aggregate of the keys/values between lo and hi\n// in the sorted map, in O(log n). It's not ok if there are none.\nfunc (r RedBlack) RangeAggregate(lo, hi KType) (agg AType, ok bool) {\n\t// down to the first node in the range, under which the keys of the\n\t// range are\n\th := r.root\n\tfor h != nil {\n\t\tif r.compare(hi, h.key) < 0 {\n\t\t\th = h.left\n\t\t} else if r.compare(lo, h.key) > 0 {\n\t\t\th = h.right\n\t\t} else {\n\t\t\tbreak\n\t\t}\n\t}\n\tif h == nil {\n\t\treturn\n\t}\n\tagg, ok = r.fromLo(h.left, lo)\n\tagg, ok = r.join(agg, ok, r.measure(h.key, h.val), true)\n\thiAgg, hiOK := r.upToHi(h.right, hi)\n\treturn r.join(agg, ok, hiAgg, hiOK)\n}\n\n// fromLo is the aggregate of the keys of the tree of h that are larger than\n// or equal to lo. The subtrees on the right of the path to lo are entirely\n// in range.\nfunc (r RedBlack) fromLo(h *mapnode, lo KType) (agg AType, ok bool) {\n\tfor h != nil {\n\t\tif r.compare(lo, h.key) > 0 {\n\t\t\th = h.right\n\t\t\tcontinue\n\t\t}\n\t\trightAgg, rightOK := h.right.aggregate()\n\t\tsub, _ := r.join(r.measure(h.key, h.val), true, rightAgg, rightOK)\n\t\tagg, ok = r.join(sub, true, agg, ok)\n\t\th = h.left\n\t}\n\treturn\n}\n\n// upToHi is the aggregate of the keys of the tree of h that are smaller than\n// or equal to hi. The subtrees on the left of the path to hi are entirely\n// in range.\nfunc (r RedBlack) upToHi(h *mapnode, hi KType) (agg AType, ok bool) {\n\tfor h != nil {\n\t\tif r.compare(hi, h.key) < 0 {\n\t\t\th = h.left\n\t\t\tcontinue\n\t\t}\n\t\tleftAgg, leftOK := h.left.aggregate()\n\t\tsub, _ := r.join(leftAgg, leftOK, r.measure(h.key, h.val), true)\n\t\tagg, ok = r.join(agg, ok, sub, true)\n\t\th = h.right\n\t}\n\treturn\n}\n\n// join combines the aggregates a and b that are ok, in order.\nfunc (r RedBlack) join(a AType, aok bool, b AType, bok bool) (AType, bool) {\n\tswitch {\n\tcase aok && bok:\n\t\treturn r.combine(a, b), true\n\tcase aok:\n\t\treturn a, true\n\t}\n\treturn b, bok\n}\n\n// DeleteMin removes the smallest key and its value from the sorted map.\nfunc (r *RedBlack) DeleteMin() (oldk KType, oldv VType, ok bool) {\n\tr.root, oldk, oldv, ok = r.deleteMin(r.root)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\nfunc (r *RedBlack) deleteMin(h *mapnode) (_ *mapnode, oldk KType, oldv VType, ok bool) {\n\tif h == nil {\n\t\treturn nil, oldk, oldv, false\n\t}\n\n\tif h.left == nil {\n\t\treturn nil, h.key, h.val, true\n\t}\n\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\th = r.moveRedLeft(h)\n\t}\n\th.left, oldk, oldv, ok = r.deleteMin(h.left)\n\treturn r.balance(h), oldk, oldv, ok\n}\n\n// DeleteMax removes the largest key and its value from the sorted map.\nfunc (r *RedBlack) DeleteMax() (oldk KType, oldv VType, ok bool) {\n\tr.root, oldk, oldv, ok = r.deleteMax(r.root)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\nfunc (r *RedBlack) deleteMax(h *mapnode) (_ *mapnode, oldk KType, oldv VType, ok bool) {\n\tif h == nil {\n\t\treturn nil, oldk, oldv, ok\n\t}\n\tif h.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.right == nil {\n\t\treturn nil, h.key, h.val, true\n\t}\n\tif !h.right.isRed() && !h.right.left.isRed() {\n\t\th = r.moveRedRight(h)\n\t}\n\th.right, oldk, oldv, ok = r.deleteMax(h.right)\n\treturn r.balance(h), oldk, oldv, ok\n}\n\n// Delete key `k` from sorted map, if it exists.\nfunc (r *RedBlack) Delete(k KType) (old VType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\tr.root, old, ok = r.delete(r.root, k)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\nfunc (r *RedBlack) delete(h *mapnode, k KType) (_ *mapnode, old VType, ok bool) {\n\n\tif h == nil {\n\t\treturn h, old, false\n\t}\n\n\tif r.compare(k, h.key) < 0 {\n\t\tif h.left == nil {\n\t\t\treturn h, old, false\n\t\t}\n\n\t\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\t\th = r.moveRedLeft(h)\n\t\t}\n\n\t\th.left, old, ok = r.delete(h.left, k)\n\t\th = r.balance(h)\n\t\treturn h, old, ok\n\t}\n\n\tif h.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\n\tif r.compare(k, h.key) == 0 && h.right == nil {\n\t\treturn nil, h.val, true\n\t}\n\n\tif h.right != nil && !h.right.isRed() && !h.right.left.isRed() {\n\t\th = r.moveRedRight(h)\n\t}\n\n\tif r.compare(k, h.key) == 0 {\n\n\t\tvar subk KType\n\t\tvar subv VType\n\t\th.right, subk, subv, ok = r.deleteMin(h.right)\n\n\t\told, h.key, h.val = h.val, subk, subv\n\t\tok = true\n\t} else {\n\t\th.right, old, ok = r.delete(h.right, k)\n\t}\n\n\th = r.balance(h)\n\treturn h, old, ok\n}\n\n// ToSlice returns the keys of the sorted map, in order, and their values.\nfunc (r RedBlack) ToSlice() (keys []KType, vals []VType) {\n\tkeys = make([]KType, 0, r.Size())\n\tvals = make([]VType, 0, r.Size())\n\tr.Keys(func(k KType, v VType) bool {\n\t\tkeys = append(keys, k)\n\t\tvals = append(vals, v)\n\t\treturn true\n\t})\n\treturn keys, vals\n}\n\n// KeysSlice returns the keys of the sorted map, in order.\nfunc (r RedBlack) KeysSlice() []KType {\n\tkeys := make([]KType, 0, r.Size())\n\tr.Keys(func(k KType, _ VType) bool {\n\t\tkeys = append(keys, k)\n\t\treturn true\n\t})\n\treturn keys\n}\n\n// ValuesSlice returns the values of the sorted map, in the order of their\n// keys.\nfunc (r RedBlack) ValuesSlice() []VType {\n\tvals := make([]VType, 0, r.Size())\n\tr.Keys(func(_ KType, v VType) bool {\n\t\tvals = append(vals, v)\n\t\treturn true\n\t})\n\treturn vals\n}\n\n// DeleteRange removes the keys between lo and hi and their values from the sorted map,\n// and returns how many it removed. It takes O(m log n) to remove m keys, or\n// O(n) when the range is a large part of the sorted map, which is then\n// rebuilt out of the keys left.\nfunc (r *RedBlack) DeleteRange(lo, hi Bound) int {\n\tfrom, n, size := r.boundrank(lo, false), r.RangeCount(lo, hi), r.Size()\n\tdepth := 0\n\tfor s := size; s > 1; s /= 2 {\n\t\tdepth++\n\t}\n\tif n*depth < size {\n\t\tfor i := 0; i < n; i++ {\n\t\t\t// the keys after those removed take their ranks\n\t\t\tr.Delete(r.nodeselect(r.root, from).key)\n\t\t}\n\t\treturn n\n\t}\n\n\tkeys := make([]KType, 0, size-n)\n\tvals := make([]VType, 0, size-n)\n\ti := 0\n\tr.Keys(func(k KType, v VType) bool {\n\t\tif i < from || i >= from+n {\n\t\t\tkeys = append(keys, k)\n\t\t\tvals = append(vals, v)\n\t\t}\n\t\ti++\n\t\treturn true\n\t})\n\tr.root = r.build(keys, vals)\n\treturn n\n}\n\n// construction\n\n// dedup removes the duplicates from the sorted keys in place, keeping the\n// first of equal keys with the value of the last, as putting them would.\nfunc (r RedBlack) dedup(keys []KType, vals []VType) ([]KType, []VType) {\n\tn := 0\n\tfor i := range keys {\n\t\tif n > 0 && r.compare(keys[n-1], keys[i]) == 0 {\n\t\t\tvals[n-1] = vals[i]\n\t\t\tcontinue\n\t\t}\n\t\tkeys[n], vals[n] = keys[i], vals[i]\n\t\tn++\n\t}\n\treturn keys[:n], vals[:n]\n}\n\n// build returns the root of a tree holding the sorted keys and their values,\n// in O(n). The tree is as balanced as can be, with the nodes of its last\n// level red if it's not full, and is fixed bottom up so that its red links\n// lean left.\nfunc (r *RedBlack) build(keys []KType, vals []VType) *mapnode {\n\tif len(keys) == 0 {\n\t\treturn nil\n\t}\n\t// the depth of the last level, counting from 0, if it's not full\n\tredDepth := -1\n\tif n := len(keys); n&(n+1) != 0 {\n\t\tfor redDepth = 0; n > 1; n /= 2 {\n\t\t\tredDepth++\n\t\t}\n\t}\n\troot := r.buildNode(keys, vals, 0, redDepth)\n\troot.colorRed = false\n\treturn root\n}\n\nfunc (r *RedBlack) buildNode(keys []KType, vals []VType, depth, redDepth int) *mapnode {\n\tif len(keys) == 0 {\n\t\treturn nil\n\t}\n\tmid := len(keys) / 2\n\th := &mapnode{key: keys[mid], val: vals[mid], colorRed: depth == redDepth}\n\th.left = r.buildNode(keys[:mid], vals[:mid], depth+1, redDepth)\n\th.right = r.buildNode(keys[mid+1:], vals[mid+1:], depth+1, redDepth)\n\treturn r.balance(h)\n}\n\n// deletions\n\nfunc (r *RedBlack) moveRedLeft(h *mapnode) *mapnode {\n\tr.flipColors(h)\n\tif h.right.left.isRed() {\n\t\th.right = r.rotateRight(h.right)\n\t\th = r.rotateLeft(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *RedBlack) moveRedRight(h *mapnode) *mapnode {\n\tr.flipColors(h)\n\tif h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *RedBlack) balance(h *mapnode) *mapnode {\n\tif h.right.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\tr.update(h)\n\treturn h\n}\n\nfunc (r *RedBlack) rotateLeft(h *mapnode) *mapnode {\n\tx := h.right\n\th.right = x.left\n\tx.left = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tr.update(h)\n\tr.update(x)\n\treturn x\n}\n\nfunc (r *RedBlack) rotateRight(h *mapnode) *mapnode {\n\tx := h.left\n\th.left = x.right\n\tx.right = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tr.update(h)\n\tr.update(x)\n\treturn x\n}\n\nfunc (r *RedBlack) flipColors(h *mapnode) {\n\th.colorRed = !h.colorRed\n\th.left.colorRed = !h.left.colorRed\n\th.right.colorRed = !h.right.colorRed\n}\n\n// update what h holds about its tree, out of what its children hold.\nfunc (r *RedBlack) update(h *mapnode) {\n\th.n = 1 + h.left.size() + h.right.size()\n\th.agg = r.measure(h.key, h.val)\n\tif h.left != nil {\n\t\th.agg = r.combine(h.left.agg, h.agg)\n\t}\n\tif h.right != nil {\n\t\th.agg = r.combine(h.agg, h.right.agg)\n\t}\n}\n\n// cursors\n\n// Cursor is a position among the keys of a sorted map, moved from key to key\n// in either order. Unlike Keys, it can be paused, or moved along the cursor\n// of another sorted map. It's only valid until the sorted map is modified.\ntype Cursor struct {\n\tr RedBlack\n\t// path from the root to the node under the cursor, empty when the cursor\n\t// isn't on a key\n\tpath []*mapnode\n}\n\n// NewCursor returns a cursor of the sorted map, which isn't on a key until it's\n// moved with First, Last or Seek.\nfunc (r RedBlack) NewCursor() *Cursor { return &Cursor{r: r} }\n\n// Valid tells if the cursor is on a key.\nfunc (c *Cursor) Valid() bool { return len(c.path) != 0 }\n\n// First moves the cursor on the smallest key, and tells if there is one.\nfunc (c *Cursor) First() bool {\n\tc.path = c.path[:0]\n\tfor h := c.r.root; h != nil; h = h.left {\n\t\tc.path = append(c.path, h)\n\t}\n\treturn c.Valid()\n}\n\n// Last moves the cursor on the largest key, and tells if there is one.\nfunc (c *Cursor) Last() bool {\n\tc.path = c.path[:0]\n\tfor h := c.r.root; h != nil; h = h.right {\n\t\tc.path = append(c.path, h)\n\t}\n\treturn c.Valid()\n}\n\n// Seek moves the cursor on the smallest key larger than or equal to `k`, and\n// tells if there is one.\nfunc (c *Cursor) Seek(k KType) bool {\n\tc.path = c.path[:0]\n\t// the ceiling is the last node left of which the search goes\n\tceiling := 0\n\tfor h := c.r.root; h != nil; {\n\t\tc.path = append(c.path, h)\n\t\tcmp := c.r.compare(k, h.key)\n\t\tif cmp == 0 {\n\t\t\treturn true\n\t\t} else if cmp < 0 {\n\t\t\tceiling = len(c.path)\n\t\t\th = h.left\n\t\t} else {\n\t\t\th = h.right\n\t\t}\n\t}\n\tc.path = c.path[:ceiling]\n\treturn c.Valid()\n}\n\n// Next moves the cursor on the following key, and tells if there is one.\n// Once past the largest key, the cursor isn't on a key anymore.\nfunc (c *Cursor) Next() bool {\n\tif !c.Valid() {\n\t\treturn false\n\t}\n\tif h := c.path[len(c.path)-1].right; h != nil {\n\t\tfor ; h != nil; h = h.left {\n\t\t\tc.path = append(c.path, h)\n\t\t}\n\t\treturn true\n\t}\n\t// up to the first node the path comes from the left of\n\tfor n := len(c.path) - 1; n > 0; n-- {\n\t\tif c.path[n-1].left == c.path[n] {\n\t\t\tc.path = c.path[:n]\n\t\t\treturn true\n\t\t}\n\t}\n\tc.path = c.path[:0]\n\treturn false\n}\n\n// Prev moves the cursor on the preceding key, and tells if there is one.\n// Once past the smallest key, the cursor isn't on a key anymore.\nfunc (c *Cursor) Prev() bool {\n\tif !c.Valid() {\n\t\treturn false\n\t}\n\tif h := c.path[len(c.path)-1].left; h != nil {\n\t\tfor ; h != nil; h = h.right {\n\t\t\tc.path = append(c.path, h)\n\t\t}\n\t\treturn true\n\t}\n\t// up to the first node the path comes from the right of\n\tfor n := len(c.path) - 1; n > 0; n-- {\n\t\tif c.path[n-1].right == c.path[n] {\n\t\t\tc.path = c.path[:n]\n\t\t\treturn true\n\t\t}\n\t}\n\tc.path = c.path[:0]\n\treturn false\n}\n\n// Key under the cursor. It panics if the cursor isn't on a key.\nfunc (c *Cursor) Key() KType {\n\treturn c.node().key\n}\n\n// Value under the cursor. It panics if the cursor isn't on a key.\nfunc (c *Cursor) Value() VType {\n\treturn c.node().val\n}\n\nfunc (c *Cursor) node() *mapnode {\n\tif !c.Valid() {\n\t\tpanic(\"redblackbst: cursor isn't on a key\")\n\t}\n\treturn c.path[len(c.path)-1]\n}\n\n// nodes\n\ntype mapnode struct {\n\tkey         KType\n\tval         VType\n\tleft, right *mapnode\n\tn           int\n\tcolorRed    bool\n\t// agg is the aggregate of the keys/values of the tree of the node\n\tagg AType\n}\n\nfunc (x *mapnode) isRed() bool { return (x != nil) && (x.colorRed == true) }\n\nfunc (x *mapnode) size() int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\treturn x.n\n}\n\n// aggregate of the tree of x, which isn't ok if x is nil.\nfunc (x *mapnode) aggregate() (agg AType, ok bool) {\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.agg, true\n}\n"
+	redblackbstSetSrc      = "package redblackbst\n\nimport \"sort\"\n\nfunc (r RedBlack) compare(a, b KType) int { return a.Compare(b) }\n\n// RedBlack is a sorted set built on a left leaning red black balanced\n// search sorted set. It stores unique KType values.\ntype RedBlack struct {\n\troot *treenode\n}\n\n// NewRedBlack creates a sorted set.\nfunc NewRedBlack() *RedBlack { return &RedBlack{} }\n\n// NewRedBlackFromSorted creates a sorted set of the keys, given in order, in\n// O(n) rather than the O(n log n) of putting them one by one. Of equal keys,\n// the first one is kept, as putting them would. It panics if the keys aren't\n// in order.\nfunc NewRedBlackFromSorted(keys []KType) *RedBlack {\n\tr := &RedBlack{}\n\tvar dups bool\n\tfor i := 1; i < len(keys); i++ {\n\t\tcmp := r.compare(keys[i-1], keys[i])\n\t\tif cmp > 0 {\n\t\t\tpanic(\"redblackbst: keys aren't sorted\")\n\t\t}\n\t\tdups = dups || cmp == 0\n\t}\n\tif dups {\n\t\t// not to modify the slice given\n\t\tkeys = r.dedup(append([]KType(nil), keys...))\n\t}\n\tr.root = r.build(keys)\n\treturn r\n}\n\n// NewRedBlackFromUnsorted creates a sorted set of the keys, sorting them\n// first, in O(n log n) but faster than putting them one by one. Of equal\n// keys, the first one is kept, as putting them would. The slice isn't\n// modified.\nfunc NewRedBlackFromUnsorted(keys []KType) *RedBlack {\n\tr := &RedBlack{}\n\tsorted := append([]KType(nil), keys...)\n\tsort.SliceStable(sorted, func(i, j int) bool {\n\t\treturn r.compare(sorted[i], sorted[j]) < 0\n\t})\n\tr.root = r.build(r.dedup(sorted))\n\treturn r\n}\n\n// IsEmpty tells if the sorted set contains no key.\nfunc (r RedBlack) IsEmpty() bool {\n\treturn r.root == nil\n}\n\n// Size of the sorted set.\nfunc (r RedBlack) Size() int { return r.root.size() }\n\n// Clear all the values in the sorted set.\nfunc (r *RedBlack) Clear() { r.root = nil }\n\n// Put the key `k` in the sorted set. If the value was already there,\n// true is returned.\nfunc (r *RedBlack) Put(k KType) (already bool) {\n\tr.root, already = r.put(r.root, k)\n\tr.root.colorRed = false\n\treturn\n}\n\nfunc (r *RedBlack) put(h *treenode, k KType) (_ *treenode, already bool) {\n\tif h == nil {\n\t\tn := &treenode{key: k, n: 1, colorRed: true}\n\t\treturn n, already\n\t}\n\n\tcmp := r.compare(k, h.key)\n\tif cmp < 0 {\n\t\th.left, already = r.put(h.left, k)\n\t} else if cmp > 0 {\n\t\th.right, already = r.put(h.right, k)\n\t} else {\n\t\talready = true\n\t}\n\n\tif h.right.isRed() && !h.left.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\th.n = h.left.size() + h.right.size() + 1\n\treturn h, already\n}\n\n// Contains tells if `k` is a member of the set.\nfunc (r RedBlack) Contains(k KType) bool {\n\treturn r.loopContains(r.root, k)\n}\n\nfunc (r RedBlack) loopContains(h *treenode, k KType) (ok bool) {\n\tfor h != nil {\n\t\tcmp := r.compare(k, h.key)\n\t\tif cmp == 0 {\n\t\t\treturn true\n\t\t} else if cmp < 0 {\n\t\t\th = h.left\n\t\t} else if cmp > 0 {\n\t\t\th = h.right\n\t\t}\n\t}\n\treturn\n}\n\n// Min returns the smallest key in the sorted set, if it exists.\nfunc (r RedBlack) Min() (k KType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\th := r.min(r.root)\n\treturn h.key, true\n}\n\nfunc (r RedBlack) min(x *treenode) *treenode {\n\tif x.left == nil {\n\t\treturn x\n\t}\n\treturn r.min(x.left)\n}\n\n// Max returns the largest key in the sorted set, if it exists.\nfunc (r RedBlack) Max() (k KType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\th := r.max(r.root)\n\treturn h.key, true\n}\n\nfunc (r RedBlack) max(x *treenode) *treenode {\n\tif x.right == nil {\n\t\treturn x\n\t}\n\treturn r.max(x.right)\n}\n\n// Floor returns the largest key in the sorted set that is smaller than\n// or equal to `k`, if it exists.\nfunc (r RedBlack) Floor(key KType) (k KType, ok bool) {\n\tx := r.floor(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, true\n}\n\nfunc (r RedBlack) floor(h *treenode, k KType) *treenode {\n\tif h == nil {\n\t\treturn nil\n\t}\n\tcmp := r.compare(k, h.key)\n\tif cmp == 0 {\n\t\treturn h\n\t}\n\tif cmp < 0 {\n\t\treturn r.floor(h.left, k)\n\t}\n\tt := r.floor(h.right, k)\n\tif t != nil {\n\t\treturn t\n\t}\n\treturn h\n}\n\n// Ceiling returns the smallest key in the sorted set that is larger than\n// or equal to `k`, if it exists.\nfunc (r RedBlack) Ceiling(key KType) (k KType, ok bool) {\n\tx := r.ceiling(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, true\n}\n\nfunc (r RedBlack) ceiling(h *treenode, k KType) *treenode {\n\tif h == nil {\n\t\treturn nil\n\t}\n\tcmp := r.compare(k, h.key)\n\tif cmp == 0 {\n\t\treturn h\n\t}\n\tif cmp > 0 {\n\t\treturn r.ceiling(h.right, k)\n\t}\n\tt := r.ceiling(h.left, k)\n\tif t != nil {\n\t\treturn t\n\t}\n\treturn h\n}\n\n// Lower returns the largest key in the sorted set that is strictly\n// smaller than `k`, its predecessor, if it exists.\nfunc (r RedBlack) Lower(key KType) (k KType, ok bool) {\n\tx := r.lower(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, true\n}\n\nfunc (r RedBlack) lower(h *treenode, k KType) *treenode {\n\tif h == nil {\n\t\treturn nil\n\t}\n\tif r.compare(k, h.key) <= 0 {\n\t\treturn r.lower(h.left, k)\n\t}\n\tt := r.lower(h.right, k)\n\tif t != nil {\n\t\treturn t\n\t}\n\treturn h\n}\n\n// Higher returns the smallest key in the sorted set that is strictly\n// larger than `k`, its successor, if it exists.\nfunc (r RedBlack) Higher(key KType) (k KType, ok bool) {\n\tx := r.higher(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, true\n}\n\nfunc (r RedBlack) higher(h *treenode, k KType) *treenode {\n\tif h == nil {\n\t\treturn nil\n\t}\n\tif r.compare(k, h.key) >= 0 {\n\t\treturn r.higher(h.right, k)\n\t}\n\tt := r.higher(h.left, k)\n\tif t != nil {\n\t\treturn t\n\t}\n\treturn h\n}\n\n// Select key of rank k, meaning the k-th biggest KType in the sorted set.\nfunc (r RedBlack) Select(key int) (k KType, ok bool) {\n\tx := r.nodeselect(r.root, key)\n\tif x == nil {\n\t\treturn\n\t}\n\treturn x.key, true\n}\n\nfunc (r RedBlack) nodeselect(x *treenode, k int) *treenode {\n\tif x == nil {\n\t\treturn nil\n\t}\n\tt := x.left.size()\n\tif t > k {\n\t\treturn r.nodeselect(x.left, k)\n\t} else if t < k {\n\t\treturn r.nodeselect(x.right, k-t-1)\n\t} else {\n\t\treturn x\n\t}\n}\n\n// Rank is the number of keys less than `k`.\nfunc (r RedBlack) Rank(k KType) int {\n\treturn r.keyrank(k, r.root)\n}\n\nfunc (r RedBlack) keyrank(k KType, h *treenode) int {\n\tif h == nil {\n\t\treturn 0\n\t}\n\tcmp := r.compare(k, h.key)\n\tif cmp < 0 {\n\t\treturn r.keyrank(k, h.left)\n\t} else if cmp > 0 {\n\t\treturn 1 + h.left.size() + r.keyrank(k, h.right)\n\t} else {\n\t\treturn h.left.size()\n\t}\n}\n\n// Keys visit each keys in the sorted set, in order.\n// It stops when visit returns false.\nfunc (r RedBlack) Keys(visit func(KType) bool) {\n\tmin, ok := r.Min()\n\tif !ok {\n\t\treturn\n\t}\n\t// if the min exists, then the max must exist\n\tmax, _ := r.Max()\n\tr.RangedKeys(min, max, visit)\n}\n\n// RangedKeys visit each keys between lo and hi in the sorted set, in order.\n// It stops when visit returns false.\nfunc (r RedBlack) RangedKeys(lo, hi KType, visit func(KType) bool) {\n\tr.keys(r.root, visit, lo, hi)\n}\n\nfunc (r RedBlack) keys(h *treenode, visit func(KType) bool, lo, hi KType) bool {\n\tif h == nil {\n\t\treturn true\n\t}\n\tcmplo := r.compare(lo, h.key)\n\tcmphi := r.compare(hi, h.key)\n\tif cmplo < 0 {\n\t\tif !r.keys(h.left, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmplo <= 0 && cmphi >= 0 {\n\t\tif !visit(h.key) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmphi > 0 {\n\t\tif !r.keys(h.right, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\n// ReverseKeys visit each keys in the sorted set, in reverse order.\n// It stops when visit returns false.\nfunc (r RedBlack) ReverseKeys(visit func(KType) bool) {\n\tmin, ok := r.Min()\n\tif !ok {\n\t\treturn\n\t}\n\t// if the min exists, then the max must exist\n\tmax, _ := r.Max()\n\tr.RangedKeysDesc(min, max, visit)\n}\n\n// RangedKeysDesc visit each keys between lo and hi in the sorted set, in\n// reverse order. It stops when visit returns false.\nfunc (r RedBlack) RangedKeysDesc(lo, hi KType, visit func(KType) bool) {\n\tr.keysDesc(r.root, visit, lo, hi)\n}\n\nfunc (r RedBlack) keysDesc(h *treenode, visit func(KType) bool, lo, hi KType) bool {\n\tif h == nil {\n\t\treturn true\n\t}\n\tcmplo := r.compare(lo, h.key)\n\tcmphi := r.compare(hi, h.key)\n\tif cmphi > 0 {\n\t\tif !r.keysDesc(h.right, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmplo <= 0 && cmphi >= 0 {\n\t\tif !visit(h.key) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmplo < 0 {\n\t\tif !r.keysDesc(h.left, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\n// Bound is an end of a range of keys: its Key, included in the range unless\n// it's Exclusive. An Unbounded end doesn't limit the range, whatever its Key.\ntype Bound struct {\n\tKey       KType\n\tExclusive bool\n\tUnbounded bool\n}\n\n// RangeCount is the number of keys between lo and hi in the sorted set. It\n// doesn't visit them, and takes O(log n).\nfunc (r RedBlack) RangeCount(lo, hi Bound) int {\n\tn := r.boundrank(hi, true) - r.boundrank(lo, false)\n\tif n < 0 {\n\t\treturn 0\n\t}\n\treturn n\n}\n\n// boundrank is the number of keys below b, or up to b for an upper bound.\nfunc (r RedBlack) boundrank(b Bound, upper bool) int {\n\tif b.Unbounded {\n\t\tif upper {\n\t\t\treturn r.Size()\n\t\t}\n\t\treturn 0\n\t}\n\t// the key itself is below an exclusive lower bound or an inclusive\n\t// upper bound\n\treturn r.keyrankBound(b.Key, r.root, upper != b.Exclusive)\n}\n\nfunc (r RedBlack) keyrankBound(k KType, h *treenode, withKey bool) int {\n\tif h == nil {\n\t\treturn 0\n\t}\n\tcmp := r.compare(k, h.key)\n\tif cmp < 0 {\n\t\treturn r.keyrankBound(k, h.left, withKey)\n\t} else if cmp > 0 {\n\t\treturn 1 + h.left.size() + r.keyrankBound(k, h.right, withKey)\n\t} else if withKey {\n\t\treturn h.left.size() + 1\n\t} else {\n\t\treturn h.left.size()\n\t}\n}\n\n// BoundedKeys visit each keys between lo and hi in the sorted set, in order.\n// It stops when visit returns false.\nfunc (r RedBlack) BoundedKeys(lo, hi Bound, visit func(KType) bool) {\n\tr.boundedKeys(r.root, visit, lo, hi)\n}\n\nfunc (r RedBlack) boundedKeys(h *treenode, visit func(KType) bool, lo, hi Bound) bool {\n\tif h == nil {\n\t\treturn true\n\t}\n\t// whether the keys left and right of h can be in the range\n\tleft, right := true, true\n\tinside := true\n\tif !lo.Unbounded {\n\t\tcmp := r.compare(lo.Key, h.key)\n\t\tleft = cmp < 0\n\t\tinside = cmp < 0 || (cmp == 0 && !lo.Exclusive)\n\t}\n\tif !hi.Unbounded {\n\t\tcmp := r.compare(hi.Key, h.key)\n\t\tright = cmp > 0\n\t\tinside = inside && (cmp > 0 || (cmp == 0 && !hi.Exclusive))\n\t}\n\tif left {\n\t\tif !r.boundedKeys(h.left, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif inside {\n\t\tif !visit(h.key) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif right {\n\t\tif !r.boundedKeys(h.right, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\n// DeleteMin removes the smallest key from the sorted set.\nfunc (r *RedBlack) DeleteMin() (oldk KType, ok bool) {\n\tr.root, oldk, ok = r.deleteMin(r.root)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\nfunc (r *RedBlack) deleteMin(h *treenode) (_ *treenode, oldk KType, ok bool) {\n\tif h == nil {\n\t\treturn nil, oldk, false\n\t}\n\n\tif h.left == nil {\n\t\treturn nil, h.key, true\n\t}\n\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\th = r.moveRedLeft(h)\n\t}\n\th.left, oldk, ok = r.deleteMin(h.left)\n\treturn r.balance(h), oldk, ok\n}\n\n// DeleteMax removes the largest key from the sorted set.\nfunc (r *RedBlack) DeleteMax() (oldk KType, ok bool) {\n\tr.root, oldk, ok = r.deleteMax(r.root)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\nfunc (r *RedBlack) deleteMax(h *treenode) (_ *treenode, oldk KType, ok bool) {\n\tif h == nil {\n\t\treturn nil, oldk, ok\n\t}\n\tif h.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.right == nil {\n\t\treturn nil, h.key, true\n\t}\n\tif !h.right.isRed() && !h.right.left.isRed() {\n\t\th = r.moveRedRight(h)\n\t}\n\th.right, oldk, ok = r.deleteMax(h.right)\n\treturn r.balance(h), oldk, ok\n}\n\n// Delete key `k` from sorted set, if it exists.\nfunc (r *RedBlack) Delete(k KType) (ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\tr.root, ok = r.delete(r.root, k)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\nfunc (r *RedBlack) delete(h *treenode, k KType) (_ *treenode, ok bool) {\n\n\tif h == nil {\n\t\treturn h, false\n\t}\n\n\tif r.compare(k, h.key) < 0 {\n\t\tif h.left == nil {\n\t\t\treturn h, false\n\t\t}\n\n\t\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\t\th = r.moveRedLeft(h)\n\t\t}\n\n\t\th.left, ok = r.delete(h.left, k)\n\t\th = r.balance(h)\n\t\treturn h, ok\n\t}\n\n\tif h.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\n\tif r.compare(k, h.key) == 0 && h.right == nil {\n\t\treturn nil, true\n\t}\n\n\tif h.right != nil && !h.right.isRed() && !h.right.left.isRed() {\n\t\th = r.moveRedRight(h)\n\t}\n\n\tif r.compare(k, h.key) == 0 {\n\n\t\tvar subk KType\n\t\th.right, subk, ok = r.deleteMin(h.right)\n\t\th.key = subk\n\t\tok = true\n\t} else {\n\t\th.right, ok = r.delete(h.right, k)\n\t}\n\n\th = r.balance(h)\n\treturn h, ok\n}\n\n// ToSlice returns the keys of the sorted set, in order.\nfunc (r RedBlack) ToSlice() []KType {\n\tkeys := make([]KType, 0, r.Size())\n\tr.Keys(func(k KType) bool {\n\t\tkeys = append(keys, k)\n\t\treturn true\n\t})\n\treturn keys\n}\n\n// DeleteRange removes the keys between lo and hi from the sorted set,\n// and returns how many it removed. It takes O(m log n) to remove m keys, or\n// O(n) when the range is a large part of the sorted set, which is then\n// rebuilt out of the keys left.\nfunc (r *RedBlack) DeleteRange(lo, hi Bound) int {\n\tfrom, n, size := r.boundrank(lo, false), r.RangeCount(lo, hi), r.Size()\n\tdepth := 0\n\tfor s := size; s > 1; s /= 2 {\n\t\tdepth++\n\t}\n\tif n*depth < size {\n\t\tfor i := 0; i < n; i++ {\n\t\t\t// the keys after those removed take their ranks\n\t\t\tr.Delete(r.nodeselect(r.root, from).key)\n\t\t}\n\t\treturn n\n\t}\n\n\tkeys := make([]KType, 0, size-n)\n\ti := 0\n\tr.Keys(func(k KType) bool {\n\t\tif i < from || i >= from+n {\n\t\t\tkeys = append(keys, k)\n\t\t}\n\t\ti++\n\t\treturn true\n\t})\n\tr.root = r.build(keys)\n\treturn n\n}\n\n// set algebra\n\n// Union returns a new sorted set of the keys in either r or other, which must\n// order its keys like r. It takes O(n+m).\nfunc (r RedBlack) Union(other *RedBlack) *RedBlack {\n\treturn r.with(r.merge(other, true, true, true))\n}\n\n// Intersection returns a new sorted set of the keys in both r and other,\n// which must order its keys like r. It takes O(n+m).\nfunc (r RedBlack) Intersection(other *RedBlack) *RedBlack {\n\treturn r.with(r.merge(other, false, true, false))\n}\n\n// Difference returns a new sorted set of the keys in r but not in other,\n// which must order its keys like r. It takes O(n+m).\nfunc (r RedBlack) Difference(other *RedBlack) *RedBlack {\n\treturn r.with(r.merge(other, true, false, false))\n}\n\n// SymmetricDifference returns a new sorted set of the keys in either r or\n// other but not in both, other ordering its keys like r. It takes O(n+m).\nfunc (r RedBlack) SymmetricDifference(other *RedBlack) *RedBlack {\n\treturn r.with(r.merge(other, true, false, true))\n}\n\n// UnionWith puts the keys of other in the sorted set, like Union but in\n// place.\nfunc (r *RedBlack) UnionWith(other *RedBlack) {\n\tr.root = r.build(r.merge(other, true, true, true))\n}\n\n// IntersectionWith deletes the keys that aren't in other from the sorted set,\n// like Intersection but in place.\nfunc (r *RedBlack) IntersectionWith(other *RedBlack) {\n\tr.root = r.build(r.merge(other, false, true, false))\n}\n\n// DifferenceWith deletes the keys of other from the sorted set, like\n// Difference but in place.\nfunc (r *RedBlack) DifferenceWith(other *RedBlack) {\n\tr.root = r.build(r.merge(other, true, false, false))\n}\n\n// SymmetricDifferenceWith deletes the keys of other from the sorted set, and\n// puts those it didn't have, like SymmetricDifference but in place.\nfunc (r *RedBlack) SymmetricDifferenceWith(other *RedBlack) {\n\tr.root = r.build(r.merge(other, true, false, true))\n}\n\n// IsSubset tells if every key of the sorted set is in other, which must\n// order its keys like r. It takes O(n+m).\nfunc (r RedBlack) IsSubset(other *RedBlack) bool {\n\tif r.Size() > other.Size() {\n\t\treturn false\n\t}\n\treturn len(r.merge(other, true, false, false)) == 0\n}\n\n// Equal tells if the sorted set and other have the same keys, other ordering\n// its keys like r. It takes O(n).\nfunc (r RedBlack) Equal(other *RedBlack) bool {\n\tif r.Size() != other.Size() {\n\t\treturn false\n\t}\n\ta, b := r.NewCursor(), other.NewCursor()\n\tfor ok := a.First() && b.First(); ok; ok = a.Next() && b.Next() {\n\t\tif r.compare(a.Key(), b.Key()) != 0 {\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\n// merge walks the keys of r and other in order, and returns those only in\n// r, in both or only in other, as asked.\nfunc (r RedBlack) merge(other *RedBlack, onlyR, both, onlyOther bool) []KType {\n\tvar keys []KType\n\ta, b := r.NewCursor(), other.NewCursor()\n\ta.First()\n\tb.First()\n\tfor a.Valid() || b.Valid() {\n\t\tif (!a.Valid() && !onlyOther) || (!b.Valid() && !onlyR) {\n\t\t\tbreak\n\t\t}\n\t\tcmp := 0\n\t\tif !a.Valid() {\n\t\t\tcmp = 1\n\t\t} else if !b.Valid() {\n\t\t\tcmp = -1\n\t\t} else {\n\t\t\tcmp = r.compare(a.Key(), b.Key())\n\t\t}\n\t\tif cmp < 0 {\n\t\t\tif onlyR {\n\t\t\t\tkeys = append(keys, a.Key())\n\t\t\t}\n\t\t\ta.Next()\n\t\t} else if cmp > 0 {\n\t\t\tif onlyOther {\n\t\t\t\tkeys = append(keys, b.Key())\n\t\t\t}\n\t\t\tb.Next()\n\t\t} else {\n\t\t\tif both {\n\t\t\t\tkeys = append(keys, a.Key())\n\t\t\t}\n\t\t\ta.Next()\n\t\t\tb.Next()\n\t\t}\n\t}\n\treturn keys\n}\n\n// with returns a sorted set ordered like r, holding the sorted keys.\nfunc (r RedBlack) with(keys []KType) *RedBlack {\n\tr.root = r.build(keys)\n\treturn &r\n}\n\n// construction\n\n// dedup removes the duplicates from the sorted keys in place, keeping the\n// first of equal keys, as putting them would.\nfunc (r RedBlack) dedup(keys []KType) []KType {\n\tn := 0\n\tfor i := range keys {\n\t\tif n > 0 && r.compare(keys[n-1], keys[i]) == 0 {\n\t\t\tcontinue\n\t\t}\n\t\tkeys[n] = keys[i]\n\t\tn++\n\t}\n\treturn keys[:n]\n}\n\n// build returns the root of a tree holding the sorted keys, in O(n). The tree\n// is as balanced as can be, with the nodes of its last level red if it's not\n// full, and is fixed bottom up so that its red links lean left.\nfunc (r *RedBlack) build(keys []KType) *treenode {\n\tif len(keys) == 0 {\n\t\treturn nil\n\t}\n\t// the depth of the last level, counting from 0, if it's not full\n\tredDepth := -1\n\tif n := len(keys); n&(n+1) != 0 {\n\t\tfor redDepth = 0; n > 1; n /= 2 {\n\t\t\tredDepth++\n\t\t}\n\t}\n\troot := r.buildNode(keys, 0, redDepth)\n\troot.colorRed = false\n\treturn root\n}\n\nfunc (r *RedBlack) buildNode(keys []KType, depth, redDepth int) *treenode {\n\tif len(keys) == 0 {\n\t\treturn nil\n\t}\n\tmid := len(keys) / 2\n\th := &treenode{key: keys[mid], colorRed: depth == redDepth}\n\th.left = r.buildNode(keys[:mid], depth+1, redDepth)\n\th.right = r.buildNode(keys[mid+1:], depth+1, redDepth)\n\treturn r.balance(h)\n}\n\n// deletions\n\nfunc (r *RedBlack) moveRedLeft(h *treenode) *treenode {\n\tr.flipColors(h)\n\tif h.right.left.isRed() {\n\t\th.right = r.rotateRight(h.right)\n\t\th = r.rotateLeft(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *RedBlack) moveRedRight(h *treenode) *treenode {\n\tr.flipColors(h)\n\tif h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *RedBlack) balance(h *treenode) *treenode {\n\tif h.right.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\th.n = h.left.size() + h.right.size() + 1\n\treturn h\n}\n\nfunc (r *RedBlack) rotateLeft(h *treenode) *treenode {\n\tx := h.right\n\th.right = x.left\n\tx.left = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tx.n = h.n\n\th.n = 1 + h.left.size() + h.right.size()\n\treturn x\n}\n\nfunc (r *RedBlack) rotateRight(h *treenode) *treenode {\n\tx := h.left\n\th.left = x.right\n\tx.right = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tx.n = h.n\n\th.n = 1 + h.left.size() + h.right.size()\n\treturn x\n}\n\nfunc (r *RedBlack) flipColors(h *treenode) {\n\th.colorRed = !h.colorRed\n\th.left.colorRed = !h.left.colorRed\n\th.right.colorRed = !h.right.colorRed\n}\n\n// cursors\n\n// Cursor is a position among the keys of a sorted set, moved from key to key\n// in either order. Unlike Keys, it can be paused, or moved along the cursor\n// of another sorted set. It's only valid until the sorted set is modified.\ntype Cursor struct {\n\tr RedBlack\n\t// path from the root to the node under the cursor, empty when the cursor\n\t// isn't on a key\n\tpath []*treenode\n}\n\n// NewCursor returns a cursor of the sorted set, which isn't on a key until it's\n// moved with First, Last or Seek.\nfunc (r RedBlack) NewCursor() *Cursor { return &Cursor{r: r} }\n\n// Valid tells if the cursor is on a key.\nfunc (c *Cursor) Valid() bool { return len(c.path) != 0 }\n\n// First moves the cursor on the smallest key, and tells if there is one.\nfunc (c *Cursor) First() bool {\n\tc.path = c.path[:0]\n\tfor h := c.r.root; h != nil; h = h.left {\n\t\tc.path = append(c.path, h)\n\t}\n\treturn c.Valid()\n}\n\n// Last moves the cursor on the largest key, and tells if there is one.\nfunc (c *Cursor) Last() bool {\n\tc.path = c.path[:0]\n\tfor h := c.r.root; h != nil; h = h.right {\n\t\tc.path = append(c.path, h)\n\t}\n\treturn c.Valid()\n}\n\n// Seek moves the cursor on the smallest key larger than or equal to `k`, and\n// tells if there is one.\nfunc (c *Cursor) Seek(k KType) bool {\n\tc.path = c.path[:0]\n\t// the ceiling is the last node left of which the search goes\n\tceiling := 0\n\tfor h := c.r.root; h != nil; {\n\t\tc.path = append(c.path, h)\n\t\tcmp := c.r.compare(k, h.key)\n\t\tif cmp == 0 {\n\t\t\treturn true\n\t\t} else if cmp < 0 {\n\t\t\tceiling = len(c.path)\n\t\t\th = h.left\n\t\t} else {\n\t\t\th = h.right\n\t\t}\n\t}\n\tc.path = c.path[:ceiling]\n\treturn c.Valid()\n}\n\n// Next moves the cursor on the following key, and tells if there is one.\n// Once past the largest key, the cursor isn't on a key anymore.\nfunc (c *Cursor) Next() bool {\n\tif !c.Valid() {\n\t\treturn false\n\t}\n\tif h := c.path[len(c.path)-1].right; h != nil {\n\t\tfor ; h != nil; h = h.left {\n\t\t\tc.path = append(c.path, h)\n\t\t}\n\t\treturn true\n\t}\n\t// up to the first node the path comes from the left of\n\tfor n := len(c.path) - 1; n > 0; n-- {\n\t\tif c.path[n-1].left == c.path[n] {\n\t\t\tc.path = c.path[:n]\n\t\t\treturn true\n\t\t}\n\t}\n\tc.path = c.path[:0]\n\treturn false\n}\n\n// Prev moves the cursor on the preceding key, and tells if there is one.\n// Once past the smallest key, the cursor isn't on a key anymore.\nfunc (c *Cursor) Prev() bool {\n\tif !c.Valid() {\n\t\treturn false\n\t}\n\tif h := c.path[len(c.path)-1].left; h != nil {\n\t\tfor ; h != nil; h = h.right {\n\t\t\tc.path = append(c.path, h)\n\t\t}\n\t\treturn true\n\t}\n\t// up to the first node the path comes from the right of\n\tfor n := len(c.path) - 1; n > 0; n-- {\n\t\tif c.path[n-1].right == c.path[n] {\n\t\t\tc.path = c.path[:n]\n\t\t\treturn true\n\t\t}\n\t}\n\tc.path = c.path[:0]\n\treturn false\n}\n\n// Key under the cursor. It panics if the cursor isn't on a key.\nfunc (c *Cursor) Key() KType {\n\treturn c.node().key\n}\n\nfunc (c *Cursor) node() *treenode {\n\tif !c.Valid() {\n\t\tpanic(\"redblackbst: cursor isn't on a key\")\n\t}\n\treturn c.path[len(c.path)-1]\n}\n\n// nodes\n\ntype treenode struct {\n\tkey         KType\n\tleft, right *treenode\n\tn           int\n\tcolorRed    bool\n}\n\nfunc (x *treenode) isRed() bool { return (x != nil) && (x.colorRed == true) }\n\nfunc (x *treenode) size() int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\treturn x.n\n}\n"
+	redblackbstMapTestSrc  = "package redblackbst\n\nimport (\n\t\"math/rand\"\n\t\"reflect\"\n\t\"sort\"\n\t\"testing\"\n)\n\n// The tests of this file are generated along with sorted maps, when asked\n// to. The keys and values are generated by randomKType and randomVType. The\n// aggregates of augmented sorted maps are computed with the measure and\n// combine methods of the sorted map, which must be associative for the\n// aggregates to be compared, exactly unless they're floating point numbers.\n\n// checkRedBlack verifies the invariants of the tree: the keys are in order,\n// the sizes of the subtrees are right, red links lean left, no node has two\n// red links and every path from the root to a leaf has as many black links.\nfunc checkRedBlack(t *testing.T, r *RedBlack) {\n\tif r.root.isRed() {\n\t\tt.Fatal(\"root is red\")\n\t}\n\tcheckRedBlackNode(t, r, r.root)\n\n\tvar prev *KType\n\tr.Keys(func(k KType, _ VType) bool {\n\t\tif prev != nil && r.compare(*prev, k) >= 0 {\n\t\t\tt.Fatalf(\"keys out of order: %v before %v\", *prev, k)\n\t\t}\n\t\tprev = &k\n\t\treturn true\n\t})\n}\n\n// checkRedBlackNode returns the number of black links from x to the leaves.\nfunc checkRedBlackNode(t *testing.T, r *RedBlack, x *mapnode) int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\tif x.right.isRed() {\n\t\tt.Fatalf(\"red link leans right under %v\", x.key)\n\t}\n\tif x.isRed() && x.left.isRed() {\n\t\tt.Fatalf(\"two red links in a row under %v\", x.key)\n\t}\n\tif want := 1 + x.left.size() + x.right.size(); x.n != want {\n\t\tt.Fatalf(\"size of %v: want %d, got %d\", x.key, want, x.n)\n\t}\n\tblack := checkRedBlackNode(t, r, x.left)\n\tif right := checkRedBlackNode(t, r, x.right); black != right {\n\t\tt.Fatalf(\"black links under %v: %d on the left, %d on the right\", x.key, black, right)\n\t}\n\tif !x.isRed() {\n\t\tblack++\n\t}\n\treturn black\n}\n\n// refRedBlack is a naive sorted map keeping its entries in a sorted slice,\n// ordered like r.\ntype refRedBlack struct {\n\tr    *RedBlack\n\tkeys []KType\n\tvals []VType\n}\n\n// search returns the index of the first key larger or equal to k.\nfunc (ref *refRedBlack) search(k KType) int {\n\treturn sort.Search(len(ref.keys), func(i int) bool { return ref.r.compare(ref.keys[i], k) >= 0 })\n}\n\nfunc (ref *refRedBlack) has(k KType) (int, bool) {\n\ti := ref.search(k)\n\treturn i, i < len(ref.keys) && ref.r.compare(ref.keys[i], k) == 0\n}\n\nfunc (ref *refRedBlack) put(k KType, v VType) {\n\ti, ok := ref.has(k)\n\tif ok {\n\t\tref.vals[i] = v\n\t\treturn\n\t}\n\tref.keys = append(ref.keys[:i], append([]KType{k}, ref.keys[i:]...)...)\n\tref.vals = append(ref.vals[:i], append([]VType{v}, ref.vals[i:]...)...)\n}\n\nfunc (ref *refRedBlack) delete(i int) {\n\tref.keys = append(ref.keys[:i], ref.keys[i+1:]...)\n\tref.vals = append(ref.vals[:i], ref.vals[i+1:]...)\n}\n\n// bounds returns the indexes of the first key between lo and hi, and of the\n// first key past them.\nfunc (ref *refRedBlack) bounds(lo, hi Bound) (from, to int) {\n\tto = len(ref.keys)\n\tif !lo.Unbounded {\n\t\tvar has bool\n\t\tif from, has = ref.has(lo.Key); has && lo.Exclusive {\n\t\t\tfrom++\n\t\t}\n\t}\n\tif !hi.Unbounded {\n\t\tvar has bool\n\t\tif to, has = ref.has(hi.Key); has && !hi.Exclusive {\n\t\t\tto++\n\t\t}\n\t}\n\tif to < from {\n\t\tto = from\n\t}\n\treturn from, to\n}\n\n// randomRedBlackBound returns an end of a range of keys, of any kind.\nfunc randomRedBlackBound(rnd *rand.Rand) Bound {\n\tswitch rnd.Intn(5) {\n\tcase 0:\n\t\treturn Bound{Unbounded: true}\n\tcase 1:\n\t\treturn Bound{Key: randomKType(rnd), Exclusive: true}\n\tdefault:\n\t\treturn Bound{Key: randomKType(rnd)}\n\t}\n}\n\nfunc TestRedBlackMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tr := NewRedBlack()\n\tref := &refRedBlack{r: r}\n\n\tcheckEntry := func(op string, i int, k KType, v VType, ok bool) {\n\t\tt.Helper()\n\t\twantOK := i >= 0 && i < len(ref.keys)\n\t\tif ok != wantOK {\n\t\t\tt.Fatalf(\"%s: want ok=%v, got %v\", op, wantOK, ok)\n\t\t}\n\t\tif !ok {\n\t\t\treturn\n\t\t}\n\t\tif r.compare(ref.keys[i], k) != 0 || !reflect.DeepEqual(ref.vals[i], v) {\n\t\t\tt.Fatalf(\"%s: want %v=%v, got %v=%v\", op, ref.keys[i], ref.vals[i], k, v)\n\t\t}\n\t}\n\n\tfor n := 0; n < 5000; n++ {\n\t\tk := randomKType(rnd)\n\t\tswitch op := rnd.Intn(14); op {\n\t\tcase 0, 1, 2, 3:\n\t\t\tv := randomVType(rnd)\n\t\t\ti, had := ref.has(k)\n\t\t\tvar want VType\n\t\t\tif had {\n\t\t\t\twant = ref.vals[i]\n\t\t\t}\n\t\t\told, overwrite := r.Put(k, v)\n\t\t\tif overwrite != had || !reflect.DeepEqual(old, want) {\n\t\t\t\tt.Fatalf(\"put %v: want %v, %v, got %v, %v\", k, want, had, old, overwrite)\n\t\t\t}\n\t\t\tref.put(k, v)\n\t\tcase 4:\n\t\t\ti, ok := ref.has(k)\n\t\t\tv, got := r.Get(k)\n\t\t\tif !ok {\n\t\t\t\ti = -1\n\t\t\t}\n\t\t\tcheckEntry(\"get\", i, k, v, got)\n\t\t\tif r.Has(k) != ok {\n\t\t\t\tt.Fatalf(\"has %v: want %v\", k, ok)\n\t\t\t}\n\t\tcase 5:\n\t\t\ti, ok := ref.has(k)\n\t\t\tv, got := r.Delete(k)\n\t\t\tif !ok {\n\t\t\t\ti = -1\n\t\t\t}\n\t\t\tcheckEntry(\"delete\", i, k, v, got)\n\t\t\tif ok {\n\t\t\t\tref.delete(i)\n\t\t\t}\n\t\tcase 6:\n\t\t\tdk, dv, ok := r.DeleteMin()\n\t\t\tcheckEntry(\"delete min\", 0, dk, dv, ok)\n\t\t\tif ok {\n\t\t\t\tref.delete(0)\n\t\t\t}\n\t\tcase 7:\n\t\t\tdk, dv, ok := r.DeleteMax()\n\t\t\tcheckEntry(\"delete max\", len(ref.keys)-1, dk, dv, ok)\n\t\t\tif ok {\n\t\t\t\tref.delete(len(ref.keys) - 1)\n\t\t\t}\n\t\tcase 8:\n\t\t\tmk, mv, ok := r.Min()\n\t\t\tcheckEntry(\"min\", 0, mk, mv, ok)\n\t\t\tmk, mv, ok = r.Max()\n\t\t\tcheckEntry(\"max\", len(ref.keys)-1, mk, mv, ok)\n\t\tcase 9:\n\t\t\ti, ok := ref.has(k)\n\t\t\tif !ok {\n\t\t\t\ti--\n\t\t\t}\n\t\t\tfk, fv, ok := r.Floor(k)\n\t\t\tcheckEntry(\"floor\", i, fk, fv, ok)\n\t\t\tck, cv, ok := r.Ceiling(k)\n\t\t\tcheckEntry(\"ceiling\", ref.search(k), ck, cv, ok)\n\t\t\tlk, lv, ok := r.Lower(k)\n\t\t\tcheckEntry(\"lower\", ref.search(k)-1, lk, lv, ok)\n\t\t\ti, ok = ref.has(k)\n\t\t\tif ok {\n\t\t\t\ti++\n\t\t\t}\n\t\t\thk, hv, ok := r.Higher(k)\n\t\t\tcheckEntry(\"higher\", i, hk, hv, ok)\n\t\tcase 10:\n\t\t\tif want, got := ref.search(k), r.Rank(k); want != got {\n\t\t\t\tt.Fatalf(\"rank %v: want %d, got %d\", k, want, got)\n\t\t\t}\n\t\t\ti := rnd.Intn(len(ref.keys) + 2)\n\t\t\tsk, sv, ok := r.Select(i)\n\t\t\tcheckEntry(\"select\", i, sk, sv, ok)\n\t\tcase 11:\n\t\t\tlo, hi := k, randomKType(rnd)\n\t\t\ti := ref.search(lo)\n\t\t\tr.RangedKeys(lo, hi, func(k KType, v VType) bool {\n\t\t\t\tcheckEntry(\"ranged keys\", i, k, v, true)\n\t\t\t\ti++\n\t\t\t\treturn true\n\t\t\t})\n\t\t\tif i < len(ref.keys) && r.compare(ref.keys[i], hi) <= 0 {\n\t\t\t\tt.Fatalf(\"ranged keys [%v, %v]: missed %v\", lo, hi, ref.keys[i])\n\t\t\t}\n\t\tcase 12:\n\t\t\tlo, hi := randomRedBlackBound(rnd), randomRedBlackBound(rnd)\n\t\t\tfrom, to := ref.bounds(lo, hi)\n\t\t\tif got := r.RangeCount(lo, hi); got != to-from {\n\t\t\t\tt.Fatalf(\"range count %v, %v: want %d, got %d\", lo, hi, to-from, got)\n\t\t\t}\n\t\t\ti := from\n\t\t\tr.BoundedKeys(lo, hi, func(k KType, v VType) bool {\n\t\t\t\tif i == to {\n\t\t\t\t\tt.Fatalf(\"bounded keys %v, %v: want no more keys, got %v\", lo, hi, k)\n\t\t\t\t}\n\t\t\t\tcheckEntry(\"bounded keys\", i, k, v, true)\n\t\t\t\ti++\n\t\t\t\treturn true\n\t\t\t})\n\t\t\tif i != to {\n\t\t\t\tt.Fatalf(\"bounded keys %v, %v: missed %v\", lo, hi, ref.keys[i])\n\t\t\t}\n\t\tcase 13:\n\t\t\tif len(ref.keys) == 0 {\n\t\t\t\tbreak\n\t\t\t}\n\t\t\t// a few neighbouring keys, so that the tree keeps growing\n\t\t\ti := rnd.Intn(len(ref.keys))\n\t\t\tj := i + rnd.Intn(2)\n\t\t\tif j >= len(ref.keys) {\n\t\t\t\tj = len(ref.keys) - 1\n\t\t\t}\n\t\t\tlo := Bound{Key: ref.keys[i], Exclusive: rnd.Intn(2) == 0}\n\t\t\thi := Bound{Key: ref.keys[j], Exclusive: rnd.Intn(2) == 0}\n\t\t\tif rnd.Intn(100) == 0 {\n\t\t\t\tlo, hi = randomRedBlackBound(rnd), randomRedBlackBound(rnd)\n\t\t\t}\n\t\t\tfrom, to := ref.bounds(lo, hi)\n\t\t\tif got := r.DeleteRange(lo, hi); got != to-from {\n\t\t\t\tt.Fatalf(\"delete range %v, %v: want %d deleted, got %d\", lo, hi, to-from, got)\n\t\t\t}\n\t\t\tref.keys = append(ref.keys[:from], ref.keys[to:]...)\n\t\t\tref.vals = append(ref.vals[:from], ref.vals[to:]...)\n\t\t}\n\t\tif want, got := len(ref.keys), r.Size(); want != got {\n\t\t\tt.Fatalf(\"want size %d, got %d\", want, got)\n\t\t}\n\t\tcheckRedBlack(t, r)\n\t}\n\n\ti := 0\n\tr.Keys(func(k KType, v VType) bool {\n\t\tcheckEntry(\"keys\", i, k, v, true)\n\t\ti++\n\t\treturn true\n\t})\n\tif i != len(ref.keys) {\n\t\tt.Fatalf(\"keys: want %d keys, got %d\", len(ref.keys), i)\n\t}\n}\n\n// checkRedBlackAggregates verifies that the nodes of the tree of x hold the\n// aggregates of the keys/values of their trees.\nfunc checkRedBlackAggregates(t *testing.T, r *RedBlack, x *mapnode) {\n\tif x == nil {\n\t\treturn\n\t}\n\tcheckRedBlackAggregates(t, r, x.left)\n\tcheckRedBlackAggregates(t, r, x.right)\n\twant := r.measure(x.key, x.val)\n\tif x.left != nil {\n\t\twant = r.combine(x.left.agg, want)\n\t}\n\tif x.right != nil {\n\t\twant = r.combine(want, x.right.agg)\n\t}\n\tif !reflect.DeepEqual(want, x.agg) {\n\t\tt.Fatalf(\"aggregate of %v: want %v, got %v\", x.key, want, x.agg)\n\t}\n}\n\n// aggregate combines the entries from i to j excluded, one by one. scale is\n// the largest magnitude of the floating point aggregates measured and\n// combined along the way, if they are.\nfunc (ref *refRedBlack) aggregate(i, j int) (agg AType, ok bool, scale float64) {\n\tfor ; i < j; i++ {\n\t\tm := ref.r.measure(ref.keys[i], ref.vals[i])\n\t\tscale = scaleOfRedBlackAggregates(scale, m)\n\t\tif ok {\n\t\t\tm = ref.r.combine(agg, m)\n\t\t}\n\t\tagg, ok = m, true\n\t\tscale = scaleOfRedBlackAggregates(scale, agg)\n\t}\n\treturn\n}\n\n// scaleOfRedBlackAggregates returns the largest of scale and the magnitude\n// of a, if it's a floating point number.\nfunc scaleOfRedBlackAggregates(scale float64, a AType) float64 {\n\tf, isFloat := floatOfRedBlackAggregate(a)\n\tif f < 0 {\n\t\tf = -f\n\t}\n\tif isFloat && f > scale {\n\t\treturn f\n\t}\n\treturn scale\n}\n\n// floatOfRedBlackAggregate returns the value of a, if it's a floating point\n// number.\nfunc floatOfRedBlackAggregate(a AType) (float64, bool) {\n\tv := reflect.ValueOf(a)\n\tif v.IsValid() && (v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64) {\n\t\treturn v.Float(), true\n\t}\n\treturn 0, false\n}\n\n// equalRedBlackAggregates tells if the aggregate got is the one wanted.\n// Floating point aggregates combined in another order than the reference are\n// rounded otherwise, so they only need to be close, relative to the scale of\n// the aggregates the reference combined.\nfunc equalRedBlackAggregates(want, got AType, scale float64) bool {\n\tw, isFloat := floatOfRedBlackAggregate(want)\n\tif !isFloat {\n\t\treturn reflect.DeepEqual(want, got)\n\t}\n\tg, _ := floatOfRedBlackAggregate(got)\n\ttolerance := 1e-9\n\tif reflect.ValueOf(want).Kind() == reflect.Float32 {\n\t\ttolerance = 1e-3\n\t}\n\td := w - g\n\tif d < 0 {\n\t\td = -d\n\t}\n\treturn w == g || d <= tolerance*scale\n}\n\n// TestRedBlackAggregatesMatchReference verifies the aggregates of augmented\n// sorted maps, which are compared to those combined one entry at a time, and\n// must then be the same, or close for floating point aggregates.\nfunc TestRedBlackAggregatesMatchReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tr := NewRedBlack()\n\tref := &refRedBlack{r: r}\n\n\tcheckAggregate := func(op string, want AType, wantOK bool, scale float64, got AType, ok bool) {\n\t\tt.Helper()\n\t\tif ok != wantOK || !equalRedBlackAggregates(want, got, scale) {\n\t\t\tt.Fatalf(\"%s: want %v, %v, got %v, %v\", op, want, wantOK, got, ok)\n\t\t}\n\t}\n\n\tfor n := 0; n < 5000; n++ {\n\t\tk := randomKType(rnd)\n\t\tswitch op := rnd.Intn(8); op {\n\t\tcase 0, 1, 2:\n\t\t\tv := randomVType(rnd)\n\t\t\tr.Put(k, v)\n\t\t\tref.put(k, v)\n\t\tcase 3:\n\t\t\tif i, ok := ref.has(k); ok {\n\t\t\t\tref.delete(i)\n\t\t\t}\n\t\t\tr.Delete(k)\n\t\tcase 4:\n\t\t\tif _, _, ok := r.DeleteMin(); ok {\n\t\t\t\tref.delete(0)\n\t\t\t}\n\t\t\tif _, _, ok := r.DeleteMax(); ok {\n\t\t\t\tref.delete(len(ref.keys) - 1)\n\t\t\t}\n\t\tcase 5:\n\t\t\tif len(ref.keys) == 0 {\n\t\t\t\tbreak\n\t\t\t}\n\t\t\t// a few neighbouring keys, so that the tree keeps growing\n\t\t\ti := rnd.Intn(len(ref.keys))\n\t\t\tlo, hi := Bound{Key: ref.keys[i]}, Bound{Key: ref.keys[i], Exclusive: true}\n\t\t\tif i+1 < len(ref.keys) {\n\t\t\t\thi.Key = ref.keys[i+1]\n\t\t\t}\n\t\t\tfrom, to := ref.bounds(lo, hi)\n\t\t\tr.DeleteRange(lo, hi)\n\t\t\tref.keys = append(ref.keys[:from], ref.keys[to:]...)\n\t\t\tref.vals = append(ref.vals[:from], ref.vals[to:]...)\n\t\tcase 6:\n\t\t\tif rnd.Intn(20) == 0 {\n\t\t\t\tr = NewRedBlackFromSorted(r.ToSlice())\n\t\t\t\tref.r = r\n\t\t\t}\n\t\tcase 7:\n\t\t\tlo, hi := k, randomKType(rnd)\n\t\t\tif rnd.Intn(4) != 0 && r.compare(lo, hi) > 0 {\n\t\t\t\tlo, hi = hi, lo\n\t\t\t}\n\t\t\ti, j := ref.search(lo), ref.search(hi)\n\t\t\tif _, ok := ref.has(hi); ok {\n\t\t\t\tj++\n\t\t\t}\n\t\t\tif i > j {\n\t\t\t\tj = i\n\t\t\t}\n\t\t\twant, wantOK, scale := ref.aggregate(i, j)\n\t\t\tgot, ok := r.RangeAggregate(lo, hi)\n\t\t\tcheckAggregate(\"range aggregate\", want, wantOK, scale, got, ok)\n\t\t}\n\t\twant, wantOK, scale := ref.aggregate(0, len(ref.keys))\n\t\tgot, ok := r.Aggregate()\n\t\tcheckAggregate(\"aggregate\", want, wantOK, scale, got, ok)\n\t\tcheckRedBlack(t, r)\n\t\tcheckRedBlackAggregates(t, r, r.root)\n\t}\n}\n\n// TestRedBlackCursorMatchesKeys verifies that cursors, and the visits in\n// reverse order, go over the keys in the order of Keys, or in reverse.\nfunc TestRedBlackCursorMatchesKeys(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tr := NewRedBlack()\n\tfor n := 0; n < 1000; n++ {\n\t\tr.Put(randomKType(rnd), randomVType(rnd))\n\t}\n\tvar keys []KType\n\tvar vals []VType\n\tr.Keys(func(k KType, v VType) bool {\n\t\tkeys = append(keys, k)\n\t\tvals = append(vals, v)\n\t\treturn true\n\t})\n\n\tcheckEntry := func(op string, i int, k KType, v VType) {\n\t\tt.Helper()\n\t\tif i < 0 || i >= len(keys) {\n\t\t\tt.Fatalf(\"%s: want no more keys, got %v\", op, k)\n\t\t}\n\t\tif r.compare(keys[i], k) != 0 || !reflect.DeepEqual(vals[i], v) {\n\t\t\tt.Fatalf(\"%s: want %v=%v, got %v=%v\", op, keys[i], vals[i], k, v)\n\t\t}\n\t}\n\t// checkCursor verifies that c is on the i-th key, or on none if there's\n\t// no such key\n\tcheckCursor := func(op string, c *Cursor, i int) {\n\t\tt.Helper()\n\t\tif want := i >= 0 && i < len(keys); c.Valid() != want {\n\t\t\tt.Fatalf(\"%s: want the cursor on a key: %v, got %v\", op, want, c.Valid())\n\t\t}\n\t\tif c.Valid() && (r.compare(keys[i], c.Key()) != 0 || !reflect.DeepEqual(vals[i], c.Value())) {\n\t\t\tt.Fatalf(\"%s: want %v=%v, got %v=%v\", op, keys[i], vals[i], c.Key(), c.Value())\n\t\t}\n\t}\n\n\tc := r.NewCursor()\n\tcheckCursor(\"new cursor\", c, -1)\n\ti := 0\n\tfor ok := c.First(); ok; ok = c.Next() {\n\t\tcheckCursor(\"next\", c, i)\n\t\ti++\n\t}\n\tif i != len(keys) {\n\t\tt.Fatalf(\"next: want %d keys, got %d\", len(keys), i)\n\t}\n\tcheckCursor(\"past the last key\", c, i)\n\ti = len(keys) - 1\n\tfor ok := c.Last(); ok; ok = c.Prev() {\n\t\tcheckCursor(\"prev\", c, i)\n\t\ti--\n\t}\n\tif i != -1 {\n\t\tt.Fatalf(\"prev: want %d keys, got %d\", len(keys), len(keys)-1-i)\n\t}\n\n\ti = len(keys) - 1\n\tr.ReverseKeys(func(k KType, v VType) bool {\n\t\tcheckEntry(\"reverse keys\", i, k, v)\n\t\ti--\n\t\treturn true\n\t})\n\tif i != -1 {\n\t\tt.Fatalf(\"reverse keys: want %d keys, got %d\", len(keys), len(keys)-1-i)\n\t}\n\n\tfor n := 0; n < 1000; n++ {\n\t\tlo, hi := randomKType(rnd), randomKType(rnd)\n\t\t// the rank of a key is the index of its ceiling\n\t\ti := r.Rank(lo)\n\t\tif ok := c.Seek(lo); ok != (i < len(keys)) {\n\t\t\tt.Fatalf(\"seek %v: want %v, got %v\", lo, !ok, ok)\n\t\t}\n\t\tcheckCursor(\"seek\", c, i)\n\t\tfor step := 0; step < 10 && c.Valid(); step++ {\n\t\t\tif rnd.Intn(2) == 0 {\n\t\t\t\tc.Next()\n\t\t\t\ti++\n\t\t\t} else {\n\t\t\t\tc.Prev()\n\t\t\t\ti--\n\t\t\t}\n\t\t\tcheckCursor(\"step\", c, i)\n\t\t}\n\n\t\tfirst, last := r.Rank(lo), r.Rank(hi)-1\n\t\tif r.Has(hi) {\n\t\t\tlast++\n\t\t}\n\t\tr.RangedKeysDesc(lo, hi, func(k KType, v VType) bool {\n\t\t\tcheckEntry(\"ranged keys desc\", last, k, v)\n\t\t\tlast--\n\t\t\treturn true\n\t\t})\n\t\tif last >= first {\n\t\t\tt.Fatalf(\"ranged keys desc [%v, %v]: missed %v\", lo, hi, keys[last])\n\t\t}\n\t}\n}\n\n// checkRedBlackSlices verifies that r is a valid tree holding the entries of\n// ref, and that it exports them in order.\nfunc checkRedBlackSlices(t *testing.T, r *RedBlack, ref *refRedBlack) {\n\tcheckRedBlack(t, r)\n\tif r.Size() != len(ref.keys) {\n\t\tt.Fatalf(\"want size %d, got %d\", len(ref.keys), r.Size())\n\t}\n\tkeys, vals := r.ToSlice()\n\tif len(keys) != len(ref.keys) || len(vals) != len(ref.vals) {\n\t\tt.Fatalf(\"want %d keys and values, got %d and %d\", len(ref.keys), len(keys), len(vals))\n\t}\n\tfor i, k := range keys {\n\t\tif r.compare(ref.keys[i], k) != 0 || !reflect.DeepEqual(ref.vals[i], vals[i]) {\n\t\t\tt.Fatalf(\"entry %d: want %v=%v, got %v=%v\", i, ref.keys[i], ref.vals[i], k, vals[i])\n\t\t}\n\t}\n\tif got := r.KeysSlice(); !reflect.DeepEqual(keys, got) {\n\t\tt.Fatalf(\"keys slice: want %v, got %v\", keys, got)\n\t}\n\tif got := r.ValuesSlice(); !reflect.DeepEqual(vals, got) {\n\t\tt.Fatalf(\"values slice: want %v, got %v\", vals, got)\n\t}\n}\n\nfunc TestRedBlackFromSortedMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tfor n := 0; n < 200; n++ {\n\t\tref := &refRedBlack{r: NewRedBlack()}\n\t\tvar keys []KType\n\t\tvar vals []VType\n\t\tfor i := rnd.Intn(5*n + 1); i > 0; i-- {\n\t\t\tk := randomKType(rnd)\n\t\t\tif len(keys) != 0 && rnd.Intn(5) == 0 {\n\t\t\t\tk = keys[rnd.Intn(len(keys))]\n\t\t\t}\n\t\t\tv := randomVType(rnd)\n\t\t\tkeys, vals = append(keys, k), append(vals, v)\n\t\t\tref.put(k, v)\n\t\t}\n\t\tgiven := append([]KType(nil), keys...)\n\t\tcheckRedBlackSlices(t, NewRedBlackFromUnsorted(keys, vals), ref)\n\t\tif !reflect.DeepEqual(given, keys) {\n\t\t\tt.Fatalf(\"the keys given were modified: want %v, got %v\", given, keys)\n\t\t}\n\n\t\t// the keys in order, some of them repeated before their last value\n\t\tkeys, vals = keys[:0], vals[:0]\n\t\tfor i, k := range ref.keys {\n\t\t\tfor j := rnd.Intn(3) - 1; j > 0; j-- {\n\t\t\t\tkeys, vals = append(keys, k), append(vals, randomVType(rnd))\n\t\t\t}\n\t\t\tkeys, vals = append(keys, k), append(vals, ref.vals[i])\n\t\t}\n\t\tcheckRedBlackSlices(t, NewRedBlackFromSorted(keys, vals), ref)\n\t}\n}\n"
+	redblackbstSetTestSrc  = "package redblackbst\n\nimport (\n\t\"math/rand\"\n\t\"reflect\"\n\t\"sort\"\n\t\"testing\"\n)\n\n// The tests of this file are generated along with sorted sets, when asked\n// to. The keys are generated by randomKType.\n\n// checkRedBlack verifies the invariants of the tree: the keys are in order,\n// the sizes of the subtrees are right, red links lean left, no node has two\n// red links and every path from the root to a leaf has as many black links.\nfunc checkRedBlack(t *testing.T, r *RedBlack) {\n\tif r.root.isRed() {\n\t\tt.Fatal(\"root is red\")\n\t}\n\tcheckRedBlackNode(t, r, r.root)\n\n\tvar prev *KType\n\tr.Keys(func(k KType) bool {\n\t\tif prev != nil && r.compare(*prev, k) >= 0 {\n\t\t\tt.Fatalf(\"keys out of order: %v before %v\", *prev, k)\n\t\t}\n\t\tprev = &k\n\t\treturn true\n\t})\n}\n\n// checkRedBlackNode returns the number of black links from x to the leaves.\nfunc checkRedBlackNode(t *testing.T, r *RedBlack, x *treenode) int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\tif x.right.isRed() {\n\t\tt.Fatalf(\"red link leans right under %v\", x.key)\n\t}\n\tif x.isRed() && x.left.isRed() {\n\t\tt.Fatalf(\"two red links in a row under %v\", x.key)\n\t}\n\tif want := 1 + x.left.size() + x.right.size(); x.n != want {\n\t\tt.Fatalf(\"size of %v: want %d, got %d\", x.key, want, x.n)\n\t}\n\tblack := checkRedBlackNode(t, r, x.left)\n\tif right := checkRedBlackNode(t, r, x.right); black != right {\n\t\tt.Fatalf(\"black links under %v: %d on the left, %d on the right\", x.key, black, right)\n\t}\n\tif !x.isRed() {\n\t\tblack++\n\t}\n\treturn black\n}\n\n// refRedBlack is a naive sorted set keeping its keys in a sorted slice,\n// ordered like r.\ntype refRedBlack struct {\n\tr    *RedBlack\n\tkeys []KType\n}\n\n// search returns the index of the first key larger or equal to k.\nfunc (ref *refRedBlack) search(k KType) int {\n\treturn sort.Search(len(ref.keys), func(i int) bool { return ref.r.compare(ref.keys[i], k) >= 0 })\n}\n\nfunc (ref *refRedBlack) has(k KType) (int, bool) {\n\ti := ref.search(k)\n\treturn i, i < len(ref.keys) && ref.r.compare(ref.keys[i], k) == 0\n}\n\nfunc (ref *refRedBlack) put(k KType) {\n\tif i, ok := ref.has(k); !ok {\n\t\tref.keys = append(ref.keys[:i], append([]KType{k}, ref.keys[i:]...)...)\n\t}\n}\n\nfunc (ref *refRedBlack) delete(i int) {\n\tref.keys = append(ref.keys[:i], ref.keys[i+1:]...)\n}\n\n// bounds returns the indexes of the first key between lo and hi, and of the\n// first key past them.\nfunc (ref *refRedBlack) bounds(lo, hi Bound) (from, to int) {\n\tto = len(ref.keys)\n\tif !lo.Unbounded {\n\t\tvar has bool\n\t\tif from, has = ref.has(lo.Key); has && lo.Exclusive {\n\t\t\tfrom++\n\t\t}\n\t}\n\tif !hi.Unbounded {\n\t\tvar has bool\n\t\tif to, has = ref.has(hi.Key); has && !hi.Exclusive {\n\t\t\tto++\n\t\t}\n\t}\n\tif to < from {\n\t\tto = from\n\t}\n\treturn from, to\n}\n\n// randomRedBlackBound returns an end of a range of keys, of any kind.\nfunc randomRedBlackBound(rnd *rand.Rand) Bound {\n\tswitch rnd.Intn(5) {\n\tcase 0:\n\t\treturn Bound{Unbounded: true}\n\tcase 1:\n\t\treturn Bound{Key: randomKType(rnd), Exclusive: true}\n\tdefault:\n\t\treturn Bound{Key: randomKType(rnd)}\n\t}\n}\n\nfunc TestRedBlackMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tr := NewRedBlack()\n\tref := &refRedBlack{r: r}\n\n\tcheckKey := func(op string, i int, k KType, ok bool) {\n\t\tt.Helper()\n\t\twantOK := i >= 0 && i < len(ref.keys)\n\t\tif ok != wantOK {\n\t\t\tt.Fatalf(\"%s: want ok=%v, got %v\", op, wantOK, ok)\n\t\t}\n\t\tif ok && r.compare(ref.keys[i], k) != 0 {\n\t\t\tt.Fatalf(\"%s: want %v, got %v\", op, ref.keys[i], k)\n\t\t}\n\t}\n\n\tfor n := 0; n < 5000; n++ {\n\t\tk := randomKType(rnd)\n\t\tswitch op := rnd.Intn(14); op {\n\t\tcase 0, 1, 2, 3:\n\t\t\t_, had := ref.has(k)\n\t\t\tif already := r.Put(k); already != had {\n\t\t\t\tt.Fatalf(\"put %v: want %v, got %v\", k, had, already)\n\t\t\t}\n\t\t\tref.put(k)\n\t\tcase 4:\n\t\t\tif _, ok := ref.has(k); r.Contains(k) != ok {\n\t\t\t\tt.Fatalf(\"contains %v: want %v\", k, ok)\n\t\t\t}\n\t\tcase 5:\n\t\t\ti, ok := ref.has(k)\n\t\t\tif got := r.Delete(k); got != ok {\n\t\t\t\tt.Fatalf(\"delete %v: want %v, got %v\", k, ok, got)\n\t\t\t}\n\t\t\tif ok {\n\t\t\t\tref.delete(i)\n\t\t\t}\n\t\tcase 6:\n\t\t\tdk, ok := r.DeleteMin()\n\t\t\tcheckKey(\"delete min\", 0, dk, ok)\n\t\t\tif ok {\n\t\t\t\tref.delete(0)\n\t\t\t}\n\t\tcase 7:\n\t\t\tdk, ok := r.DeleteMax()\n\t\t\tcheckKey(\"delete max\", len(ref.keys)-1, dk, ok)\n\t\t\tif ok {\n\t\t\t\tref.delete(len(ref.keys) - 1)\n\t\t\t}\n\t\tcase 8:\n\t\t\tmk, ok := r.Min()\n\t\t\tcheckKey(\"min\", 0, mk, ok)\n\t\t\tmk, ok = r.Max()\n\t\t\tcheckKey(\"max\", len(ref.keys)-1, mk, ok)\n\t\tcase 9:\n\t\t\ti, ok := ref.has(k)\n\t\t\tif !ok {\n\t\t\t\ti--\n\t\t\t}\n\t\t\tfk, ok := r.Floor(k)\n\t\t\tcheckKey(\"floor\", i, fk, ok)\n\t\t\tck, ok := r.Ceiling(k)\n\t\t\tcheckKey(\"ceiling\", ref.search(k), ck, ok)\n\t\t\tlk, ok := r.Lower(k)\n\t\t\tcheckKey(\"lower\", ref.search(k)-1, lk, ok)\n\t\t\ti, ok = ref.has(k)\n\t\t\tif ok {\n\t\t\t\ti++\n\t\t\t}\n\t\t\thk, ok := r.Higher(k)\n\t\t\tcheckKey(\"higher\", i, hk, ok)\n\t\tcase 10:\n\t\t\tif want, got := ref.search(k), r.Rank(k); want != got {\n\t\t\t\tt.Fatalf(\"rank %v: want %d, got %d\", k, want, got)\n\t\t\t}\n\t\t\ti := rnd.Intn(len(ref.keys) + 2)\n\t\t\tsk, ok := r.Select(i)\n\t\t\tcheckKey(\"select\", i, sk, ok)\n\t\tcase 11:\n\t\t\tlo, hi := k, randomKType(rnd)\n\t\t\ti := ref.search(lo)\n\t\t\tr.RangedKeys(lo, hi, func(k KType) bool {\n\t\t\t\tcheckKey(\"ranged keys\", i, k, true)\n\t\t\t\ti++\n\t\t\t\treturn true\n\t\t\t})\n\t\t\tif i < len(ref.keys) && r.compare(ref.keys[i], hi) <= 0 {\n\t\t\t\tt.Fatalf(\"ranged keys [%v, %v]: missed %v\", lo, hi, ref.keys[i])\n\t\t\t}\n\t\tcase 12:\n\t\t\tlo, hi := randomRedBlackBound(rnd), randomRedBlackBound(rnd)\n\t\t\tfrom, to := ref.bounds(lo, hi)\n\t\t\tif got := r.RangeCount(lo, hi); got != to-from {\n\t\t\t\tt.Fatalf(\"range count %v, %v: want %d, got %d\", lo, hi, to-from, got)\n\t\t\t}\n\t\t\ti := from\n\t\t\tr.BoundedKeys(lo, hi, func(k KType) bool {\n\t\t\t\tif i == to {\n\t\t\t\t\tt.Fatalf(\"bounded keys %v, %v: want no more keys, got %v\", lo, hi, k)\n\t\t\t\t}\n\t\t\t\tcheckKey(\"bounded keys\", i, k, true)\n\t\t\t\ti++\n\t\t\t\treturn true\n\t\t\t})\n\t\t\tif i != to {\n\t\t\t\tt.Fatalf(\"bounded keys %v, %v: missed %v\", lo, hi, ref.keys[i])\n\t\t\t}\n\t\tcase 13:\n\t\t\tif len(ref.keys) == 0 {\n\t\t\t\tbreak\n\t\t\t}\n\t\t\t// a few neighbouring keys, so that the tree keeps growing\n\t\t\ti := rnd.Intn(len(ref.keys))\n\t\t\tj := i + rnd.Intn(2)\n\t\t\tif j >= len(ref.keys) {\n\t\t\t\tj = len(ref.keys) - 1\n\t\t\t}\n\t\t\tlo := Bound{Key: ref.keys[i], Exclusive: rnd.Intn(2) == 0}\n\t\t\thi := Bound{Key: ref.keys[j], Exclusive: rnd.Intn(2) == 0}\n\t\t\tif rnd.Intn(100) == 0 {\n\t\t\t\tlo, hi = randomRedBlackBound(rnd), randomRedBlackBound(rnd)\n\t\t\t}\n\t\t\tfrom, to := ref.bounds(lo, hi)\n\t\t\tif got := r.DeleteRange(lo, hi); got != to-from {\n\t\t\t\tt.Fatalf(\"delete range %v, %v: want %d deleted, got %d\", lo, hi, to-from, got)\n\t\t\t}\n\t\t\tref.keys = append(ref.keys[:from], ref.keys[to:]...)\n\t\t}\n\t\tif want, got := len(ref.keys), r.Size(); want != got {\n\t\t\tt.Fatalf(\"want size %d, got %d\", want, got)\n\t\t}\n\t\tcheckRedBlack(t, r)\n\t}\n\n\ti := 0\n\tr.Keys(func(k KType) bool {\n\t\tcheckKey(\"keys\", i, k, true)\n\t\ti++\n\t\treturn true\n\t})\n\tif i != len(ref.keys) {\n\t\tt.Fatalf(\"keys: want %d keys, got %d\", len(ref.keys), i)\n\t}\n}\n\n// TestRedBlackCursorMatchesKeys verifies that cursors, and the visits in\n// reverse order, go over the keys in the order of Keys, or in reverse.\nfunc TestRedBlackCursorMatchesKeys(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tr := NewRedBlack()\n\tfor n := 0; n < 1000; n++ {\n\t\tr.Put(randomKType(rnd))\n\t}\n\tvar keys []KType\n\tr.Keys(func(k KType) bool {\n\t\tkeys = append(keys, k)\n\t\treturn true\n\t})\n\n\tcheckEntry := func(op string, i int, k KType) {\n\t\tt.Helper()\n\t\tif i < 0 || i >= len(keys) {\n\t\t\tt.Fatalf(\"%s: want no more keys, got %v\", op, k)\n\t\t}\n\t\tif r.compare(keys[i], k) != 0 {\n\t\t\tt.Fatalf(\"%s: want %v, got %v\", op, keys[i], k)\n\t\t}\n\t}\n\t// checkCursor verifies that c is on the i-th key, or on none if there's\n\t// no such key\n\tcheckCursor := func(op string, c *Cursor, i int) {\n\t\tt.Helper()\n\t\tif want := i >= 0 && i < len(keys); c.Valid() != want {\n\t\t\tt.Fatalf(\"%s: want the cursor on a key: %v, got %v\", op, want, c.Valid())\n\t\t}\n\t\tif c.Valid() && r.compare(keys[i], c.Key()) != 0 {\n\t\t\tt.Fatalf(\"%s: want %v, got %v\", op, keys[i], c.Key())\n\t\t}\n\t}\n\n\tc := r.NewCursor()\n\tcheckCursor(\"new cursor\", c, -1)\n\ti := 0\n\tfor ok := c.First(); ok; ok = c.Next() {\n\t\tcheckCursor(\"next\", c, i)\n\t\ti++\n\t}\n\tif i != len(keys) {\n\t\tt.Fatalf(\"next: want %d keys, got %d\", len(keys), i)\n\t}\n\tcheckCursor(\"past the last key\", c, i)\n\ti = len(keys) - 1\n\tfor ok := c.Last(); ok; ok = c.Prev() {\n\t\tcheckCursor(\"prev\", c, i)\n\t\ti--\n\t}\n\tif i != -1 {\n\t\tt.Fatalf(\"prev: want %d keys, got %d\", len(keys), len(keys)-1-i)\n\t}\n\n\ti = len(keys) - 1\n\tr.ReverseKeys(func(k KType) bool {\n\t\tcheckEntry(\"reverse keys\", i, k)\n\t\ti--\n\t\treturn true\n\t})\n\tif i != -1 {\n\t\tt.Fatalf(\"reverse keys: want %d keys, got %d\", len(keys), len(keys)-1-i)\n\t}\n\n\tfor n := 0; n < 1000; n++ {\n\t\tlo, hi := randomKType(rnd), randomKType(rnd)\n\t\t// the rank of a key is the index of its ceiling\n\t\ti := r.Rank(lo)\n\t\tif ok := c.Seek(lo); ok != (i < len(keys)) {\n\t\t\tt.Fatalf(\"seek %v: want %v, got %v\", lo, !ok, ok)\n\t\t}\n\t\tcheckCursor(\"seek\", c, i)\n\t\tfor step := 0; step < 10 && c.Valid(); step++ {\n\t\t\tif rnd.Intn(2) == 0 {\n\t\t\t\tc.Next()\n\t\t\t\ti++\n\t\t\t} else {\n\t\t\t\tc.Prev()\n\t\t\t\ti--\n\t\t\t}\n\t\t\tcheckCursor(\"step\", c, i)\n\t\t}\n\n\t\tfirst, last := r.Rank(lo), r.Rank(hi)-1\n\t\tif r.Contains(hi) {\n\t\t\tlast++\n\t\t}\n\t\tr.RangedKeysDesc(lo, hi, func(k KType) bool {\n\t\t\tcheckEntry(\"ranged keys desc\", last, k)\n\t\t\tlast--\n\t\t\treturn true\n\t\t})\n\t\tif last >= first {\n\t\t\tt.Fatalf(\"ranged keys desc [%v, %v]: missed %v\", lo, hi, keys[last])\n\t\t}\n\t}\n}\n\n// TestRedBlackSetAlgebraMatchesReference verifies the set operations, and\n// the trees they build, against the keys of both sets.\nfunc TestRedBlackSetAlgebraMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tfor n := 0; n < 200; n++ {\n\t\ta, b := NewRedBlack(), NewRedBlack()\n\t\trefA, refB := &refRedBlack{r: a}, &refRedBlack{r: b}\n\t\tfor i := rnd.Intn(300); i > 0; i-- {\n\t\t\tk := randomKType(rnd)\n\t\t\ta.Put(k)\n\t\t\trefA.put(k)\n\t\t}\n\t\t// b is often a subset or a superset of a\n\t\tfor _, k := range refA.keys {\n\t\t\tif n%4 == 0 || (n%4 == 1 && rnd.Intn(2) == 0) {\n\t\t\t\tb.Put(k)\n\t\t\t\trefB.put(k)\n\t\t\t}\n\t\t}\n\t\tfor i := rnd.Intn(300); n%4 != 0 && i > 0; i-- {\n\t\t\tk := randomKType(rnd)\n\t\t\tb.Put(k)\n\t\t\trefB.put(k)\n\t\t}\n\n\t\t// all the keys, and whether each is in a and in b\n\t\tall := &refRedBlack{r: a}\n\t\tfor _, k := range append(append([]KType{}, refA.keys...), refB.keys...) {\n\t\t\tall.put(k)\n\t\t}\n\t\tinA := make([]bool, len(all.keys))\n\t\tinB := make([]bool, len(all.keys))\n\t\tfor i, k := range all.keys {\n\t\t\t_, inA[i] = refA.has(k)\n\t\t\t_, inB[i] = refB.has(k)\n\t\t}\n\n\t\tinPlace := func(op func(c *RedBlack)) func() *RedBlack {\n\t\t\treturn func() *RedBlack {\n\t\t\t\tc := NewRedBlack()\n\t\t\t\tc.UnionWith(a)\n\t\t\t\top(c)\n\t\t\t\treturn c\n\t\t\t}\n\t\t}\n\t\tfor _, op := range []struct {\n\t\t\tname string\n\t\t\tset  func() *RedBlack\n\t\t\tkeep func(inA, inB bool) bool\n\t\t}{\n\t\t\t{\"union\", func() *RedBlack { return a.Union(b) }, func(x, y bool) bool { return x || y }},\n\t\t\t{\"intersection\", func() *RedBlack { return a.Intersection(b) }, func(x, y bool) bool { return x && y }},\n\t\t\t{\"difference\", func() *RedBlack { return a.Difference(b) }, func(x, y bool) bool { return x && !y }},\n\t\t\t{\"symmetric difference\", func() *RedBlack { return a.SymmetricDifference(b) }, func(x, y bool) bool { return x != y }},\n\t\t\t{\"union with\", inPlace(func(c *RedBlack) { c.UnionWith(b) }), func(x, y bool) bool { return x || y }},\n\t\t\t{\"intersection with\", inPlace(func(c *RedBlack) { c.IntersectionWith(b) }), func(x, y bool) bool { return x && y }},\n\t\t\t{\"difference with\", inPlace(func(c *RedBlack) { c.DifferenceWith(b) }), func(x, y bool) bool { return x && !y }},\n\t\t\t{\"symmetric difference with\", inPlace(func(c *RedBlack) { c.SymmetricDifferenceWith(b) }), func(x, y bool) bool { return x != y }},\n\t\t} {\n\t\t\tvar want []KType\n\t\t\tfor i, k := range all.keys {\n\t\t\t\tif op.keep(inA[i], inB[i]) {\n\t\t\t\t\twant = append(want, k)\n\t\t\t\t}\n\t\t\t}\n\t\t\tgot := op.set()\n\t\t\tcheckRedBlack(t, got)\n\t\t\tif got.Size() != len(want) {\n\t\t\t\tt.Fatalf(\"%s: want %d keys, got %d\", op.name, len(want), got.Size())\n\t\t\t}\n\t\t\ti := 0\n\t\t\tgot.Keys(func(k KType) bool {\n\t\t\t\tif a.compare(want[i], k) != 0 {\n\t\t\t\t\tt.Fatalf(\"%s: key %d: want %v, got %v\", op.name, i, want[i], k)\n\t\t\t\t}\n\t\t\t\ti++\n\t\t\t\treturn true\n\t\t\t})\n\t\t}\n\n\t\tsubset, equal := true, len(refA.keys) == len(refB.keys)\n\t\tfor i := range all.keys {\n\t\t\tsubset = subset && (!inA[i] || inB[i])\n\t\t\tequal = equal && inA[i] == inB[i]\n\t\t}\n\t\tif got := a.IsSubset(b); got != subset {\n\t\t\tt.Fatalf(\"is subset: want %v, got %v\", subset, got)\n\t\t}\n\t\tif got := a.Equal(b); got != equal {\n\t\t\tt.Fatalf(\"equal: want %v, got %v\", equal, got)\n\t\t}\n\t}\n}\n\n// checkRedBlackSlice verifies that r is a valid tree holding the keys of\n// ref, and that it exports them in order.\nfunc checkRedBlackSlice(t *testing.T, r *RedBlack, ref *refRedBlack) {\n\tcheckRedBlack(t, r)\n\tif r.Size() != len(ref.keys) {\n\t\tt.Fatalf(\"want size %d, got %d\", len(ref.keys), r.Size())\n\t}\n\tkeys := r.ToSlice()\n\tif len(keys) != len(ref.keys) {\n\t\tt.Fatalf(\"want %d keys, got %d\", len(ref.keys), len(keys))\n\t}\n\tfor i, k := range keys {\n\t\tif r.compare(ref.keys[i], k) != 0 {\n\t\t\tt.Fatalf(\"key %d: want %v, got %v\", i, ref.keys[i], k)\n\t\t}\n\t}\n}\n\nfunc TestRedBlackFromSortedMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tfor n := 0; n < 200; n++ {\n\t\tref := &refRedBlack{r: NewRedBlack()}\n\t\tvar keys []KType\n\t\tfor i := rnd.Intn(5*n + 1); i > 0; i-- {\n\t\t\tk := randomKType(rnd)\n\t\t\tif len(keys) != 0 && rnd.Intn(5) == 0 {\n\t\t\t\tk = keys[rnd.Intn(len(keys))]\n\t\t\t}\n\t\t\tkeys = append(keys, k)\n\t\t\tref.put(k)\n\t\t}\n\t\tgiven := append([]KType(nil), keys...)\n\t\tcheckRedBlackSlice(t, NewRedBlackFromUnsorted(keys), ref)\n\t\tif !reflect.DeepEqual(given, keys) {\n\t\t\tt.Fatalf(\"the keys given were modified: want %v, got %v\", given, keys)\n\t\t}\n\n\t\t// the keys in order, some of them repeated\n\t\tkeys = keys[:0]\n\t\tfor _, k := range ref.keys {\n\t\t\tfor j := rnd.Intn(3); j >= 0; j-- {\n\t\t\t\tkeys = append(keys, k)\n\t\t\t}\n\t\t}\n\t\tcheckRedBlackSlice(t, NewRedBlackFromSorted(keys), ref)\n\t}\n}\n"
+	heapTestSrc            = "package heap\n\nimport (\n\t\"math/rand\"\n\t\"testing\"\n)\n\n// The tests of this file are generated along with heaps, when asked to. The\n// keys are generated by randomKType.\n\n// checkHeap verifies that no key of the heap comes out before its parent.\nfunc checkHeap(t *testing.T, h *Heap) {\n\tif len(h.pq) != h.n+1 {\n\t\tt.Fatalf(\"want %d keys, got %d\", h.n, len(h.pq)-1)\n\t}\n\tfor k := 2; k <= h.n; k++ {\n\t\tif h.before(h.pq[k], h.pq[k/2]) {\n\t\t\tt.Fatalf(\"heap order violated: %v at %d comes out before its parent %v at %d\",\n\t\t\t\th.pq[k], k, h.pq[k/2], k/2)\n\t\t}\n\t}\n}\n\n// refHeap is a naive heap keeping its keys in a slice, ordered like h.\ntype refHeap struct {\n\th    *Heap\n\tkeys []KType\n}\n\nfunc (r *refHeap) push(k KType) { r.keys = append(r.keys, k) }\n\n// top is the index of the key coming out first.\nfunc (r *refHeap) top() int {\n\ttop := 0\n\tfor i, k := range r.keys {\n\t\tif r.h.before(k, r.keys[top]) {\n\t\t\ttop = i\n\t\t}\n\t}\n\treturn top\n}\n\nfunc (r *refHeap) remove(i int) KType {\n\tk := r.keys[i]\n\tr.keys = append(r.keys[:i], r.keys[i+1:]...)\n\treturn k\n}\n\nfunc TestHeapMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\th := NewHeap()\n\tref := &refHeap{h: h}\n\n\tfor i := 0; i < 5000; i++ {\n\t\tswitch op := rnd.Intn(10); {\n\t\tcase op < 5:\n\t\t\tk := randomKType(rnd)\n\t\t\th.Push(k)\n\t\t\tref.push(k)\n\t\tcase op < 8:\n\t\t\tif h.Len() == 0 {\n\t\t\t\tcheckHeapEmpty(t, h, randomKType(rnd))\n\t\t\t\tcontinue\n\t\t\t}\n\t\t\tif want, got := ref.keys[ref.top()], h.Peek(); h.compare(want, got) != 0 {\n\t\t\t\tt.Fatalf(\"peek: want %v, got %v\", want, got)\n\t\t\t}\n\t\t\tif got, ok := h.TryPeek(); !ok || h.compare(ref.keys[ref.top()], got) != 0 {\n\t\t\t\tt.Fatalf(\"try peek: want %v, true, got %v, %v\", ref.keys[ref.top()], got, ok)\n\t\t\t}\n\t\t\twant := ref.remove(ref.top())\n\t\t\tif op < 7 {\n\t\t\t\tif got := h.Pop(); h.compare(want, got) != 0 {\n\t\t\t\t\tt.Fatalf(\"pop: want %v, got %v\", want, got)\n\t\t\t\t}\n\t\t\t} else if got, ok := h.TryPop(); !ok || h.compare(want, got) != 0 {\n\t\t\t\tt.Fatalf(\"try pop: want %v, true, got %v, %v\", want, got, ok)\n\t\t\t}\n\t\tdefault:\n\t\t\tif h.Len() == 0 {\n\t\t\t\tcontinue\n\t\t\t}\n\t\t\tk := ref.keys[rnd.Intn(len(ref.keys))]\n\t\t\tif !h.Remove(k) {\n\t\t\t\tt.Fatalf(\"remove %v: want found\", k)\n\t\t\t}\n\t\t\tfor j, rk := range ref.keys {\n\t\t\t\tif h.compare(rk, k) == 0 {\n\t\t\t\t\tref.remove(j)\n\t\t\t\t\tbreak\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t\tif want, got := len(ref.keys), h.Len(); want != got {\n\t\t\tt.Fatalf(\"want len %d, got %d\", want, got)\n\t\t}\n\t\tcheckHeap(t, h)\n\t}\n}\n\nfunc TestHeapPopsInOrder(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tfor _, n := range []int{0, 1, 2, 3, 10, 100, 1000} {\n\t\tkeys := make([]KType, n)\n\t\tfor i := range keys {\n\t\t\tkeys[i] = randomKType(rnd)\n\t\t}\n\t\th := NewHeap(keys...)\n\t\tcheckHeap(t, h)\n\t\tif h.Len() != n {\n\t\t\tt.Fatalf(\"want len %d, got %d\", n, h.Len())\n\t\t}\n\t\tfor i := 1; i < n; i++ {\n\t\t\tprev := h.Pop()\n\t\t\tif h.before(h.Peek(), prev) {\n\t\t\t\tt.Fatalf(\"popped %v before %v\", prev, h.Peek())\n\t\t\t}\n\t\t\tcheckHeap(t, h)\n\t\t}\n\t}\n}\n\n// checkHeapEmpty verifies that the empty heap h has nothing to peek, pop or\n// remove, such as k.\nfunc checkHeapEmpty(t *testing.T, h *Heap, k KType) {\n\tif h.Len() != 0 {\n\t\tt.Fatalf(\"want len 0, got %d\", h.Len())\n\t}\n\tif got, ok := h.TryPeek(); ok {\n\t\tt.Fatalf(\"try peek: want nothing, got %v\", got)\n\t}\n\tif got, ok := h.TryPop(); ok {\n\t\tt.Fatalf(\"try pop: want nothing, got %v\", got)\n\t}\n\tif h.Remove(k) {\n\t\tt.Fatalf(\"remove %v: want not found\", k)\n\t}\n\tfor _, op := range []struct {\n\t\tname string\n\t\tf    func()\n\t}{\n\t\t{\"peek\", func() { h.Peek() }},\n\t\t{\"pop\", func() { h.Pop() }},\n\t} {\n\t\tfunc() {\n\t\t\tdefer func() {\n\t\t\t\tif recover() == nil {\n\t\t\t\t\tt.Fatalf(\"%s: want a panic on an empty heap\", op.name)\n\t\t\t\t}\n\t\t\t}()\n\t\t\top.f()\n\t\t}()\n\t}\n\tcheckHeap(t, h)\n}\n\nfunc TestHeapEmpty(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\th := NewHeap()\n\tcheckHeapEmpty(t, h, randomKType(rnd))\n\n\tk := randomKType(rnd)\n\th.Push(k)\n\tif got, ok := h.TryPop(); !ok || h.compare(k, got) != 0 {\n\t\tt.Fatalf(\"try pop: want %v, true, got %v, %v\", k, got, ok)\n\t}\n\tcheckHeapEmpty(t, h, k)\n\n\tfor i := 0; i < 10; i++ {\n\t\th.Push(randomKType(rnd))\n\t}\n\tfor h.Len() > 0 {\n\t\th.Pop()\n\t}\n\tcheckHeapEmpty(t, h, k)\n}\n"
+	queueTestSrc           = "package queue\n\nimport (\n\t\"math/rand\"\n\t\"reflect\"\n\t\"testing\"\n)\n\n// The tests of this file are generated along with queues, when asked to. The\n// elements are generated by randomKType.\n\n// checkQueue verifies that the head, the tail and the count of the queue\n// agree, and that the buffer never shrinks below its minimum length.\nfunc checkQueue(t *testing.T, q *Queue) {\n\tif len(q.buf) < q.minlen {\n\t\tt.Fatalf(\"buffer of %d shrunk below %d\", len(q.buf), q.minlen)\n\t}\n\tif q.count < 0 || q.count > len(q.buf) {\n\t\tt.Fatalf(\"count %d out of a buffer of %d\", q.count, len(q.buf))\n\t}\n\tif q.head < 0 || q.head >= len(q.buf) {\n\t\tt.Fatalf(\"head %d out of a buffer of %d\", q.head, len(q.buf))\n\t}\n\tif want := (q.head + q.count) % len(q.buf); q.tail != want {\n\t\tt.Fatalf(\"head %d and count %d: want tail %d, got %d\", q.head, q.count, want, q.tail)\n\t}\n}\n\n// checkQueueElems verifies that q holds the elements of ref, in order.\nfunc checkQueueElems(t *testing.T, q *Queue, ref []KType) {\n\tif q.Len() != len(ref) {\n\t\tt.Fatalf(\"want len %d, got %d\", len(ref), q.Len())\n\t}\n\tfor i, want := range ref {\n\t\tif got := q.Get(i); !reflect.DeepEqual(want, got) {\n\t\t\tt.Fatalf(\"get %d: want %v, got %v\", i, want, got)\n\t\t}\n\t}\n}\n\nfunc TestQueueMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tq := NewQueue(0)\n\tvar ref []KType\n\n\tfor i := 0; i < 5000; i++ {\n\t\t// favor pushes, then pops, so the buffer grows and shrinks\n\t\tpush := 6\n\t\tif i%2000 >= 1000 {\n\t\t\tpush = 4\n\t\t}\n\t\tif rnd.Intn(10) < push {\n\t\t\tk := randomKType(rnd)\n\t\t\tq.Push(k)\n\t\t\tref = append(ref, k)\n\t\t} else if len(ref) != 0 {\n\t\t\tif want, got := ref[0], q.Peek(); !reflect.DeepEqual(want, got) {\n\t\t\t\tt.Fatalf(\"peek: want %v, got %v\", want, got)\n\t\t\t}\n\t\t\tif got, ok := q.TryPeek(); !ok || !reflect.DeepEqual(ref[0], got) {\n\t\t\t\tt.Fatalf(\"try peek: want %v, true, got %v, %v\", ref[0], got, ok)\n\t\t\t}\n\t\t\tif i%2 == 0 {\n\t\t\t\tif want, got := ref[0], q.Pop(); !reflect.DeepEqual(want, got) {\n\t\t\t\t\tt.Fatalf(\"pop: want %v, got %v\", want, got)\n\t\t\t\t}\n\t\t\t} else if got, ok := q.TryPop(); !ok || !reflect.DeepEqual(ref[0], got) {\n\t\t\t\tt.Fatalf(\"try pop: want %v, true, got %v, %v\", ref[0], got, ok)\n\t\t\t}\n\t\t\tref = ref[1:]\n\t\t} else {\n\t\t\tcheckQueueEmpty(t, q)\n\t\t}\n\t\tcheckQueue(t, q)\n\t\tif i%100 == 0 {\n\t\t\tcheckQueueElems(t, q, ref)\n\t\t}\n\t}\n\tcheckQueueElems(t, q, ref)\n}\n\nfunc TestQueueWrapsAround(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tq := NewQueue(16)\n\tvar ref []KType\n\tpush := func(n int) {\n\t\tfor i := 0; i < n; i++ {\n\t\t\tk := randomKType(rnd)\n\t\t\tq.Push(k)\n\t\t\tref = append(ref, k)\n\t\t\tcheckQueue(t, q)\n\t\t}\n\t}\n\tpop := func(n int) {\n\t\tfor i := 0; i < n; i++ {\n\t\t\tif want, got := ref[0], q.Pop(); !reflect.DeepEqual(want, got) {\n\t\t\t\tt.Fatalf(\"pop: want %v, got %v\", want, got)\n\t\t\t}\n\t\t\tref = ref[1:]\n\t\t\tcheckQueue(t, q)\n\t\t}\n\t}\n\n\t// move the head to the middle of the buffer, then wrap the tail around it\n\tpush(10)\n\tpop(8)\n\tpush(12)\n\tif q.tail >= q.head {\n\t\tt.Fatalf(\"want the tail wrapped before the head, got head %d, tail %d\", q.head, q.tail)\n\t}\n\tcheckQueueElems(t, q, ref)\n\n\t// fill the buffer while wrapped, growing it\n\tpush(len(q.buf) - q.count + 1)\n\tcheckQueueElems(t, q, ref)\n\n\t// wrap again and shrink\n\tpop(q.count - 4)\n\tpush(len(q.buf) - q.head)\n\tpop(q.count - 2)\n\tcheckQueueElems(t, q, ref)\n\tpop(q.count)\n\tcheckQueueElems(t, q, ref)\n}\n\n// checkQueueEmpty verifies that the empty queue q has nothing to peek, pop or\n// get.\nfunc checkQueueEmpty(t *testing.T, q *Queue) {\n\tif q.Len() != 0 {\n\t\tt.Fatalf(\"want len 0, got %d\", q.Len())\n\t}\n\tif got, ok := q.TryPeek(); ok {\n\t\tt.Fatalf(\"try peek: want nothing, got %v\", got)\n\t}\n\tif got, ok := q.TryPop(); ok {\n\t\tt.Fatalf(\"try pop: want nothing, got %v\", got)\n\t}\n\tfor _, op := range []struct {\n\t\tname string\n\t\tf    func()\n\t}{\n\t\t{\"peek\", func() { q.Peek() }},\n\t\t{\"pop\", func() { q.Pop() }},\n\t\t{\"get\", func() { q.Get(0) }},\n\t} {\n\t\tfunc() {\n\t\t\tdefer func() {\n\t\t\t\tif recover() == nil {\n\t\t\t\t\tt.Fatalf(\"%s: want a panic on an empty queue\", op.name)\n\t\t\t\t}\n\t\t\t}()\n\t\t\top.f()\n\t\t}()\n\t}\n\tcheckQueue(t, q)\n}\n\nfunc TestQueueEmpty(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tq := NewQueue(0)\n\tcheckQueueEmpty(t, q)\n\n\tk := randomKType(rnd)\n\tq.Push(k)\n\tif got, ok := q.TryPop(); !ok || !reflect.DeepEqual(k, got) {\n\t\tt.Fatalf(\"try pop: want %v, true, got %v, %v\", k, got, ok)\n\t}\n\tcheckQueueEmpty(t, q)\n\n\t// empty after wrapping around, and after shrinking\n\tfor i := 0; i < 100; i++ {\n\t\tq.Push(randomKType(rnd))\n\t\tif i%3 == 0 {\n\t\t\tq.Pop()\n\t\t}\n\t}\n\tfor q.Len() > 0 {\n\t\tq.Pop()\n\t}\n\tcheckQueueEmpty(t, q)\n}\n"
+	redblackbstMapBenchSrc = "package redblackbst\n\nimport (\n\t\"math/rand\"\n\t\"strconv\"\n\t\"testing\"\n)\n\n// The benchmarks of this file are generated along with sorted maps, when\n// asked to. The keys and values are generated by benchKType and benchVType.\n\n// benchRedBlackSizes are the numbers of keys in the benchmarked maps.\nvar benchRedBlackSizes = []int{100, 10000, 1000000}\n\n// benchRedBlack runs bench for each size, with a map holding that many\n// random keys, and the keys and values put in it. The keys are the same from\n// one benchmark to the other.\nfunc benchRedBlack(b *testing.B, bench func(b *testing.B, r *RedBlack, keys []KType, vals []VType)) {\n\tfor _, n := range benchRedBlackSizes {\n\t\tn := n\n\t\tvar r *RedBlack\n\t\tvar keys []KType\n\t\tvar vals []VType\n\t\tb.Run(strconv.Itoa(n), func(b *testing.B) {\n\t\t\t// once for all the runs of the benchmark, and only if it's run\n\t\t\tif r == nil {\n\t\t\t\trnd := rand.New(rand.NewSource(int64(n)))\n\t\t\t\tkeys = make([]KType, n)\n\t\t\t\tvals = make([]VType, n)\n\t\t\t\tfor i := range keys {\n\t\t\t\t\tkeys[i], vals[i] = benchKType(rnd), benchVType(rnd)\n\t\t\t\t}\n\t\t\t\tr = NewRedBlack()\n\t\t\t\tfillRedBlack(r, keys, vals)\n\t\t\t}\n\t\t\tbench(b, r, keys, vals)\n\t\t})\n\t}\n}\n\nfunc fillRedBlack(r *RedBlack, keys []KType, vals []VType) {\n\tfor i, k := range keys {\n\t\tr.Put(k, vals[i])\n\t}\n}\n\n// benchRedBlackRefill runs op b.N times, putting the keys back in r every n\n// times. The refill isn't timed.\nfunc benchRedBlackRefill(b *testing.B, r *RedBlack, keys []KType, vals []VType, op func(i int)) {\n\tn := len(keys)\n\tb.ResetTimer()\n\tfor i := 0; i < b.N; i++ {\n\t\top(i % n)\n\t\tif i%n == n-1 {\n\t\t\tb.StopTimer()\n\t\t\tfillRedBlack(r, keys, vals)\n\t\t\tb.StartTimer()\n\t\t}\n\t}\n\tb.StopTimer()\n\tfillRedBlack(r, keys, vals)\n}\n\n// BenchmarkRedBlackPut is to be compared with BenchmarkRedBlackPutLLRB.\nfunc BenchmarkRedBlackPut(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tn := len(keys)\n\t\tr.Clear()\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\t// fill the map up to n keys, over and over\n\t\t\tif i%n == 0 {\n\t\t\t\tr.Clear()\n\t\t\t}\n\t\t\tr.Put(keys[i%n], vals[i%n])\n\t\t}\n\t\tb.StopTimer()\n\t\tfillRedBlack(r, keys, vals)\n\t})\n}\n\n// BenchmarkRedBlackGet is to be compared with BenchmarkRedBlackGetLLRB.\nfunc BenchmarkRedBlackGet(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Get(keys[i%len(keys)])\n\t\t}\n\t})\n}\n\n// llrbRedBlackItem is an item of llrbRedBlack, held as an interface and\n// ordered by its Less method, like the items of GoLLRB.\ntype llrbRedBlackItem interface {\n\tLess(than llrbRedBlackItem) bool\n}\n\n// llrbRedBlackEntry is a key and its value, ordered like RedBlack.\ntype llrbRedBlackEntry struct {\n\tr *RedBlack\n\tk KType\n\tv VType\n}\n\nfunc (e llrbRedBlackEntry) Less(than llrbRedBlackItem) bool {\n\treturn e.r.compare(e.k, than.(llrbRedBlackEntry).k) < 0\n}\n\n// llrbRedBlack is a left leaning red black tree of interface{} items, as\n// implemented by GoLLRB. It only puts and gets, which is all it's\n// benchmarked for.\ntype llrbRedBlack struct {\n\troot *llrbRedBlackNode\n}\n\ntype llrbRedBlackNode struct {\n\titem        llrbRedBlackItem\n\tleft, right *llrbRedBlackNode\n\tblack       bool\n}\n\nfunc (t *llrbRedBlack) put(item llrbRedBlackItem) {\n\tt.root = t.root.insert(item)\n\tt.root.black = true\n}\n\nfunc (t *llrbRedBlack) get(item llrbRedBlackItem) llrbRedBlackItem {\n\th := t.root\n\tfor h != nil {\n\t\tswitch {\n\t\tcase item.Less(h.item):\n\t\t\th = h.left\n\t\tcase h.item.Less(item):\n\t\t\th = h.right\n\t\tdefault:\n\t\t\treturn h.item\n\t\t}\n\t}\n\treturn nil\n}\n\nfunc (h *llrbRedBlackNode) isRed() bool { return h != nil && !h.black }\n\nfunc (h *llrbRedBlackNode) insert(item llrbRedBlackItem) *llrbRedBlackNode {\n\tif h == nil {\n\t\treturn &llrbRedBlackNode{item: item}\n\t}\n\tswitch {\n\tcase item.Less(h.item):\n\t\th.left = h.left.insert(item)\n\tcase h.item.Less(item):\n\t\th.right = h.right.insert(item)\n\tdefault:\n\t\th.item = item\n\t}\n\tif h.right.isRed() && !h.left.isRed() {\n\t\th = h.rotateLeft()\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = h.rotateRight()\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\th.black = !h.black\n\t\th.left.black = true\n\t\th.right.black = true\n\t}\n\treturn h\n}\n\nfunc (h *llrbRedBlackNode) rotateLeft() *llrbRedBlackNode {\n\tx := h.right\n\th.right = x.left\n\tx.left = h\n\tx.black = h.black\n\th.black = false\n\treturn x\n}\n\nfunc (h *llrbRedBlackNode) rotateRight() *llrbRedBlackNode {\n\tx := h.left\n\th.left = x.right\n\tx.right = h\n\tx.black = h.black\n\th.black = false\n\treturn x\n}\n\nfunc BenchmarkRedBlackPutLLRB(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tn := len(keys)\n\t\tt := &llrbRedBlack{}\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\t// fill the tree up to n keys, over and over\n\t\t\tif i%n == 0 {\n\t\t\t\tt.root = nil\n\t\t\t}\n\t\t\tt.put(llrbRedBlackEntry{r: r, k: keys[i%n], v: vals[i%n]})\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackGetLLRB(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tt := &llrbRedBlack{}\n\t\tfor i, k := range keys {\n\t\t\tt.put(llrbRedBlackEntry{r: r, k: k, v: vals[i]})\n\t\t}\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tt.get(llrbRedBlackEntry{r: r, k: keys[i%len(keys)]})\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackHas(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Has(keys[i%len(keys)])\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackDelete(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tbenchRedBlackRefill(b, r, keys, vals, func(i int) { r.Delete(keys[i]) })\n\t})\n}\n\nfunc BenchmarkRedBlackDeleteMin(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tbenchRedBlackRefill(b, r, keys, vals, func(int) { r.DeleteMin() })\n\t})\n}\n\nfunc BenchmarkRedBlackDeleteMax(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tbenchRedBlackRefill(b, r, keys, vals, func(int) { r.DeleteMax() })\n\t})\n}\n\nfunc BenchmarkRedBlackMin(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Min()\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackMax(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Max()\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackFloor(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Floor(keys[i%len(keys)])\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackCeiling(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Ceiling(keys[i%len(keys)])\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackRank(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Rank(keys[i%len(keys)])\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackSelect(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tn := r.Size()\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Select(i % n)\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackKeys(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tvisit := func(KType, VType) bool { return true }\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Keys(visit)\n\t\t}\n\t})\n}\n\n// BenchmarkRedBlackRangedKeys visits a quarter of the keys.\nfunc BenchmarkRedBlackRangedKeys(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tn := r.Size()\n\t\tlo, _, _ := r.Select(n / 4)\n\t\thi, _, _ := r.Select(n / 2)\n\t\tvisit := func(KType, VType) bool { return true }\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.RangedKeys(lo, hi, visit)\n\t\t}\n\t})\n}\n\n// BenchmarkRedBlackRangeAggregate aggregates the ranges between consecutive\n// keys put, of random lengths, in augmented sorted maps.\nfunc BenchmarkRedBlackRangeAggregate(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tn := len(keys)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tlo, hi := keys[i%n], keys[(i+1)%n]\n\t\t\tif r.compare(lo, hi) > 0 {\n\t\t\t\tlo, hi = hi, lo\n\t\t\t}\n\t\t\tr.RangeAggregate(lo, hi)\n\t\t}\n\t})\n}\n\n// BenchmarkRedBlackFromSorted builds the whole map at each iteration.\nfunc BenchmarkRedBlackFromSorted(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tsortedKeys, sortedVals := r.ToSlice()\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tNewRedBlackFromSorted(sortedKeys, sortedVals)\n\t\t}\n\t})\n}\n\n// BenchmarkRedBlackFromUnsorted builds the whole map at each iteration.\nfunc BenchmarkRedBlackFromUnsorted(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tNewRedBlackFromUnsorted(keys, vals)\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackToSlice(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.ToSlice()\n\t\t}\n\t})\n}\n"
+	redblackbstSetBenchSrc = "package redblackbst\n\nimport (\n\t\"math/rand\"\n\t\"strconv\"\n\t\"testing\"\n)\n\n// The benchmarks of this file are generated along with sorted sets, when\n// asked to. The keys are generated by benchKType.\n\n// benchRedBlackSizes are the numbers of keys in the benchmarked sets.\nvar benchRedBlackSizes = []int{100, 10000, 1000000}\n\n// benchRedBlack runs bench for each size, with a set holding that many\n// random keys, and the keys put in it. The keys are the same from one\n// benchmark to the other.\nfunc benchRedBlack(b *testing.B, bench func(b *testing.B, r *RedBlack, keys []KType)) {\n\tfor _, n := range benchRedBlackSizes {\n\t\tn := n\n\t\tvar r *RedBlack\n\t\tvar keys []KType\n\t\tb.Run(strconv.Itoa(n), func(b *testing.B) {\n\t\t\t// once for all the runs of the benchmark, and only if it's run\n\t\t\tif r == nil {\n\t\t\t\trnd := rand.New(rand.NewSource(int64(n)))\n\t\t\t\tkeys = make([]KType, n)\n\t\t\t\tfor i := range keys {\n\t\t\t\t\tkeys[i] = benchKType(rnd)\n\t\t\t\t}\n\t\t\t\tr = NewRedBlack()\n\t\t\t\tfillRedBlack(r, keys)\n\t\t\t}\n\t\t\tbench(b, r, keys)\n\t\t})\n\t}\n}\n\nfunc fillRedBlack(r *RedBlack, keys []KType) {\n\tfor _, k := range keys {\n\t\tr.Put(k)\n\t}\n}\n\n// benchRedBlackRefill runs op b.N times, putting the keys back in r every n\n// times. The refill isn't timed.\nfunc benchRedBlackRefill(b *testing.B, r *RedBlack, keys []KType, op func(i int)) {\n\tn := len(keys)\n\tb.ResetTimer()\n\tfor i := 0; i < b.N; i++ {\n\t\top(i % n)\n\t\tif i%n == n-1 {\n\t\t\tb.StopTimer()\n\t\t\tfillRedBlack(r, keys)\n\t\t\tb.StartTimer()\n\t\t}\n\t}\n\tb.StopTimer()\n\tfillRedBlack(r, keys)\n}\n\n// BenchmarkRedBlackPut is to be compared with BenchmarkRedBlackPutLLRB.\nfunc BenchmarkRedBlackPut(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tn := len(keys)\n\t\tr.Clear()\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\t// fill the set up to n keys, over and over\n\t\t\tif i%n == 0 {\n\t\t\t\tr.Clear()\n\t\t\t}\n\t\t\tr.Put(keys[i%n])\n\t\t}\n\t\tb.StopTimer()\n\t\tfillRedBlack(r, keys)\n\t})\n}\n\n// BenchmarkRedBlackContains is to be compared with\n// BenchmarkRedBlackContainsLLRB.\nfunc BenchmarkRedBlackContains(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Contains(keys[i%len(keys)])\n\t\t}\n\t})\n}\n\n// llrbRedBlackItem is an item of llrbRedBlack, held as an interface and\n// ordered by its Less method, like the items of GoLLRB.\ntype llrbRedBlackItem interface {\n\tLess(than llrbRedBlackItem) bool\n}\n\n// llrbRedBlackEntry is a key, ordered like RedBlack.\ntype llrbRedBlackEntry struct {\n\tr *RedBlack\n\tk KType\n}\n\nfunc (e llrbRedBlackEntry) Less(than llrbRedBlackItem) bool {\n\treturn e.r.compare(e.k, than.(llrbRedBlackEntry).k) < 0\n}\n\n// llrbRedBlack is a left leaning red black tree of interface{} items, as\n// implemented by GoLLRB. It only puts and gets, which is all it's\n// benchmarked for.\ntype llrbRedBlack struct {\n\troot *llrbRedBlackNode\n}\n\ntype llrbRedBlackNode struct {\n\titem        llrbRedBlackItem\n\tleft, right *llrbRedBlackNode\n\tblack       bool\n}\n\nfunc (t *llrbRedBlack) put(item llrbRedBlackItem) {\n\tt.root = t.root.insert(item)\n\tt.root.black = true\n}\n\nfunc (t *llrbRedBlack) get(item llrbRedBlackItem) llrbRedBlackItem {\n\th := t.root\n\tfor h != nil {\n\t\tswitch {\n\t\tcase item.Less(h.item):\n\t\t\th = h.left\n\t\tcase h.item.Less(item):\n\t\t\th = h.right\n\t\tdefault:\n\t\t\treturn h.item\n\t\t}\n\t}\n\treturn nil\n}\n\nfunc (h *llrbRedBlackNode) isRed() bool { return h != nil && !h.black }\n\nfunc (h *llrbRedBlackNode) insert(item llrbRedBlackItem) *llrbRedBlackNode {\n\tif h == nil {\n\t\treturn &llrbRedBlackNode{item: item}\n\t}\n\tswitch {\n\tcase item.Less(h.item):\n\t\th.left = h.left.insert(item)\n\tcase h.item.Less(item):\n\t\th.right = h.right.insert(item)\n\tdefault:\n\t\th.item = item\n\t}\n\tif h.right.isRed() && !h.left.isRed() {\n\t\th = h.rotateLeft()\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = h.rotateRight()\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\th.black = !h.black\n\t\th.left.black = true\n\t\th.right.black = true\n\t}\n\treturn h\n}\n\nfunc (h *llrbRedBlackNode) rotateLeft() *llrbRedBlackNode {\n\tx := h.right\n\th.right = x.left\n\tx.left = h\n\tx.black = h.black\n\th.black = false\n\treturn x\n}\n\nfunc (h *llrbRedBlackNode) rotateRight() *llrbRedBlackNode {\n\tx := h.left\n\th.left = x.right\n\tx.right = h\n\tx.black = h.black\n\th.black = false\n\treturn x\n}\n\nfunc BenchmarkRedBlackPutLLRB(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tn := len(keys)\n\t\tt := &llrbRedBlack{}\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\t// fill the tree up to n keys, over and over\n\t\t\tif i%n == 0 {\n\t\t\t\tt.root = nil\n\t\t\t}\n\t\t\tt.put(llrbRedBlackEntry{r: r, k: keys[i%n]})\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackContainsLLRB(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tt := &llrbRedBlack{}\n\t\tfor _, k := range keys {\n\t\t\tt.put(llrbRedBlackEntry{r: r, k: k})\n\t\t}\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tt.get(llrbRedBlackEntry{r: r, k: keys[i%len(keys)]})\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackDelete(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tbenchRedBlackRefill(b, r, keys, func(i int) { r.Delete(keys[i]) })\n\t})\n}\n\nfunc BenchmarkRedBlackDeleteMin(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tbenchRedBlackRefill(b, r, keys, func(int) { r.DeleteMin() })\n\t})\n}\n\nfunc BenchmarkRedBlackDeleteMax(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tbenchRedBlackRefill(b, r, keys, func(int) { r.DeleteMax() })\n\t})\n}\n\nfunc BenchmarkRedBlackMin(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Min()\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackMax(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Max()\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackFloor(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Floor(keys[i%len(keys)])\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackCeiling(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Ceiling(keys[i%len(keys)])\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackRank(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Rank(keys[i%len(keys)])\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackSelect(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tn := r.Size()\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Select(i % n)\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackKeys(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tvisit := func(KType) bool { return true }\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Keys(visit)\n\t\t}\n\t})\n}\n\n// BenchmarkRedBlackRangedKeys visits a quarter of the keys.\nfunc BenchmarkRedBlackRangedKeys(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tn := r.Size()\n\t\tlo, _ := r.Select(n / 4)\n\t\thi, _ := r.Select(n / 2)\n\t\tvisit := func(KType) bool { return true }\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.RangedKeys(lo, hi, visit)\n\t\t}\n\t})\n}\n\n// BenchmarkRedBlackFromSorted builds the whole set at each iteration.\nfunc BenchmarkRedBlackFromSorted(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tsorted := r.ToSlice()\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tNewRedBlackFromSorted(sorted)\n\t\t}\n\t})\n}\n\n// BenchmarkRedBlackFromUnsorted builds the whole set at each iteration.\nfunc BenchmarkRedBlackFromUnsorted(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tNewRedBlackFromUnsorted(keys)\n\t\t}\n\t})\n}\n\nfunc BenchmarkRedBlackToSlice(b *testing.B) {\n\tbenchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.ToSlice()\n\t\t}\n\t})\n}\n"
+	persistentMapSrc       = "package redblackbst\n\n// The implementation was forked from the one of the sorted maps\n// (map/redblackbst/rbbst.go in datagen), since every write has to copy the\n// nodes the map doesn't own: a fix to the balancing of either tree is to be\n// made to the other. The methods the sorted maps gained since are missing\n// here: Lower, Higher, ReverseKeys, RangedKeysDesc, RangeCount, BoundedKeys,\n// Cursor, DeleteRange, ToSlice, KeysSlice, ValuesSlice and the constructors\n// from sorted and unsorted slices.\n\nfunc (r PersistentRedBlack) compare(a, b KType) int { return a.Compare(b) }\n\n// PersistentRedBlack is a sorted map built on a left leaning red black\n// balanced search tree, whose versions are kept for free. It stores VType\n// values, keyed by KType. Writing to the sorted map copies the nodes on the\n// path to the key instead of modifying them, once they're shared with a\n// snapshot, so that the snapshots aren't affected by the writes.\ntype PersistentRedBlack struct {\n\troot *persistentnode\n\t// owner marks the nodes created by the sorted map since its last\n\t// snapshot, which nothing else refers to, and which are modified in\n\t// place. The other nodes are copied before being modified.\n\towner *int\n}\n\n// NewPersistentRedBlack creates a persistent sorted map.\nfunc NewPersistentRedBlack() *PersistentRedBlack { return &PersistentRedBlack{} }\n\n// Snapshot returns the sorted map as it is, in O(1). The writes to the\n// sorted map and to the snapshot don't affect each other, so that the\n// snapshot can be read while the sorted map is written to, from another\n// goroutine, as long as the snapshot is taken by the writer.\nfunc (r *PersistentRedBlack) Snapshot() *PersistentRedBlack {\n\t// the nodes are now shared, neither of them owns them anymore\n\tr.owner = nil\n\tsnap := *r\n\treturn &snap\n}\n\n// IsEmpty tells if the sorted map contains no key/value.\nfunc (r PersistentRedBlack) IsEmpty() bool {\n\treturn r.root == nil\n}\n\n// Size of the sorted map.\nfunc (r PersistentRedBlack) Size() int { return r.root.size() }\n\n// Clear all the values in the sorted map.\nfunc (r *PersistentRedBlack) Clear() { r.root = nil }\n\n// Put a value in the sorted map at key `k`. The old value at `k` is returned\n// if the key was already present.\nfunc (r *PersistentRedBlack) Put(k KType, v VType) (old VType, overwrite bool) {\n\tr.root, old, overwrite = r.put(r.root, k, v)\n\tr.root.colorRed = false\n\treturn\n}\n\nfunc (r *PersistentRedBlack) put(h *persistentnode, k KType, v VType) (_ *persistentnode, old VType, overwrite bool) {\n\tif h == nil {\n\t\tn := &persistentnode{key: k, val: v, n: 1, colorRed: true, owner: r.ownerToken()}\n\t\treturn n, old, overwrite\n\t}\n\n\th = r.own(h)\n\tcmp := r.compare(k, h.key)\n\tif cmp < 0 {\n\t\th.left, old, overwrite = r.put(h.left, k, v)\n\t} else if cmp > 0 {\n\t\th.right, old, overwrite = r.put(h.right, k, v)\n\t} else {\n\t\toverwrite = true\n\t\told = h.val\n\t\th.val = v\n\t}\n\n\tif h.right.isRed() && !h.left.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\th.n = h.left.size() + h.right.size() + 1\n\treturn h, old, overwrite\n}\n\n// Get a value from the sorted map at key `k`. Returns false\n// if the key doesn't exist.\nfunc (r PersistentRedBlack) Get(k KType) (VType, bool) {\n\treturn r.loopGet(r.root, k)\n}\n\nfunc (r PersistentRedBlack) loopGet(h *persistentnode, k KType) (v VType, ok bool) {\n\tfor h != nil {\n\t\tcmp := r.compare(k, h.key)\n\t\tif cmp == 0 {\n\t\t\treturn h.val, true\n\t\t} else if cmp < 0 {\n\t\t\th = h.left\n\t\t} else {\n\t\t\th = h.right\n\t\t}\n\t}\n\treturn\n}\n\n// Has tells if a value exists at key `k`. This is short hand for `Get.\nfunc (r PersistentRedBlack) Has(k KType) bool {\n\t_, ok := r.loopGet(r.root, k)\n\treturn ok\n}\n\n// Min returns the smallest key/value in the sorted map, if it exists.\nfunc (r PersistentRedBlack) Min() (k KType, v VType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\th := r.root\n\tfor h.left != nil {\n\t\th = h.left\n\t}\n\treturn h.key, h.val, true\n}\n\n// Max returns the largest key/value in the sorted map, if it exists.\nfunc (r PersistentRedBlack) Max() (k KType, v VType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\th := r.root\n\tfor h.right != nil {\n\t\th = h.right\n\t}\n\treturn h.key, h.val, true\n}\n\n// Floor returns the largest key/value in the sorted map that is smaller than\n// or equal to `k`, if it exists.\nfunc (r PersistentRedBlack) Floor(key KType) (k KType, v VType, ok bool) {\n\tvar floor *persistentnode\n\tfor h := r.root; h != nil; {\n\t\tcmp := r.compare(key, h.key)\n\t\tif cmp < 0 {\n\t\t\th = h.left\n\t\t\tcontinue\n\t\t}\n\t\tfloor = h\n\t\tif cmp == 0 {\n\t\t\tbreak\n\t\t}\n\t\th = h.right\n\t}\n\tif floor == nil {\n\t\treturn\n\t}\n\treturn floor.key, floor.val, true\n}\n\n// Ceiling returns the smallest key/value in the sorted map that is larger than\n// or equal to `k`, if it exists.\nfunc (r PersistentRedBlack) Ceiling(key KType) (k KType, v VType, ok bool) {\n\tvar ceiling *persistentnode\n\tfor h := r.root; h != nil; {\n\t\tcmp := r.compare(key, h.key)\n\t\tif cmp > 0 {\n\t\t\th = h.right\n\t\t\tcontinue\n\t\t}\n\t\tceiling = h\n\t\tif cmp == 0 {\n\t\t\tbreak\n\t\t}\n\t\th = h.left\n\t}\n\tif ceiling == nil {\n\t\treturn\n\t}\n\treturn ceiling.key, ceiling.val, true\n}\n\n// Select key of rank k, meaning the k-th biggest KType in the sorted map.\nfunc (r PersistentRedBlack) Select(key int) (k KType, v VType, ok bool) {\n\tfor h := r.root; h != nil; {\n\t\tt := h.left.size()\n\t\tif t > key {\n\t\t\th = h.left\n\t\t} else if t < key {\n\t\t\th, key = h.right, key-t-1\n\t\t} else {\n\t\t\treturn h.key, h.val, true\n\t\t}\n\t}\n\treturn\n}\n\n// Rank is the number of keys less than `k`.\nfunc (r PersistentRedBlack) Rank(k KType) int {\n\trank := 0\n\tfor h := r.root; h != nil; {\n\t\tcmp := r.compare(k, h.key)\n\t\tif cmp < 0 {\n\t\t\th = h.left\n\t\t} else if cmp > 0 {\n\t\t\trank += 1 + h.left.size()\n\t\t\th = h.right\n\t\t} else {\n\t\t\treturn rank + h.left.size()\n\t\t}\n\t}\n\treturn rank\n}\n\n// Keys visit each keys in the sorted map, in order.\n// It stops when visit returns false.\nfunc (r PersistentRedBlack) Keys(visit func(KType, VType) bool) {\n\tmin, _, ok := r.Min()\n\tif !ok {\n\t\treturn\n\t}\n\t// if the min exists, then the max must exist\n\tmax, _, _ := r.Max()\n\tr.RangedKeys(min, max, visit)\n}\n\n// RangedKeys visit each keys between lo and hi in the sorted map, in order.\n// It stops when visit returns false.\nfunc (r PersistentRedBlack) RangedKeys(lo, hi KType, visit func(KType, VType) bool) {\n\tr.keys(r.root, visit, lo, hi)\n}\n\nfunc (r PersistentRedBlack) keys(h *persistentnode, visit func(KType, VType) bool, lo, hi KType) bool {\n\tif h == nil {\n\t\treturn true\n\t}\n\tcmplo := r.compare(lo, h.key)\n\tcmphi := r.compare(hi, h.key)\n\tif cmplo < 0 {\n\t\tif !r.keys(h.left, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmplo <= 0 && cmphi >= 0 {\n\t\tif !visit(h.key, h.val) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmphi > 0 {\n\t\tif !r.keys(h.right, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\n// DeleteMin removes the smallest key and its value from the sorted map.\nfunc (r *PersistentRedBlack) DeleteMin() (oldk KType, oldv VType, ok bool) {\n\tr.root, oldk, oldv, ok = r.deleteMin(r.root)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\nfunc (r *PersistentRedBlack) deleteMin(h *persistentnode) (_ *persistentnode, oldk KType, oldv VType, ok bool) {\n\tif h == nil {\n\t\treturn nil, oldk, oldv, false\n\t}\n\n\tif h.left == nil {\n\t\treturn nil, h.key, h.val, true\n\t}\n\th = r.own(h)\n\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\th = r.moveRedLeft(h)\n\t}\n\th.left, oldk, oldv, ok = r.deleteMin(h.left)\n\treturn r.balance(h), oldk, oldv, ok\n}\n\n// DeleteMax removes the largest key and its value from the sorted map.\nfunc (r *PersistentRedBlack) DeleteMax() (oldk KType, oldv VType, ok bool) {\n\tr.root, oldk, oldv, ok = r.deleteMax(r.root)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\nfunc (r *PersistentRedBlack) deleteMax(h *persistentnode) (_ *persistentnode, oldk KType, oldv VType, ok bool) {\n\tif h == nil {\n\t\treturn nil, oldk, oldv, ok\n\t}\n\th = r.own(h)\n\tif h.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.right == nil {\n\t\treturn nil, h.key, h.val, true\n\t}\n\tif !h.right.isRed() && !h.right.left.isRed() {\n\t\th = r.moveRedRight(h)\n\t}\n\th.right, oldk, oldv, ok = r.deleteMax(h.right)\n\treturn r.balance(h), oldk, oldv, ok\n}\n\n// Delete key `k` from sorted map, if it exists. The nodes aren't copied if\n// it doesn't.\nfunc (r *PersistentRedBlack) Delete(k KType) (old VType, ok bool) {\n\tif !r.Has(k) {\n\t\treturn\n\t}\n\tr.root, old, ok = r.delete(r.root, k)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn\n}\n\n// delete removes `k`, which is in the tree of h.\nfunc (r *PersistentRedBlack) delete(h *persistentnode, k KType) (_ *persistentnode, old VType, ok bool) {\n\th = r.own(h)\n\tif r.compare(k, h.key) < 0 {\n\t\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\t\th = r.moveRedLeft(h)\n\t\t}\n\t\th.left, old, ok = r.delete(h.left, k)\n\t\treturn r.balance(h), old, ok\n\t}\n\n\tif h.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\n\tif r.compare(k, h.key) == 0 && h.right == nil {\n\t\treturn nil, h.val, true\n\t}\n\n\tif !h.right.isRed() && !h.right.left.isRed() {\n\t\th = r.moveRedRight(h)\n\t}\n\n\tif r.compare(k, h.key) == 0 {\n\t\tvar subk KType\n\t\tvar subv VType\n\t\th.right, subk, subv, ok = r.deleteMin(h.right)\n\t\told, h.key, h.val = h.val, subk, subv\n\t} else {\n\t\th.right, old, ok = r.delete(h.right, k)\n\t}\n\treturn r.balance(h), old, ok\n}\n\n// copying\n\n// ownerToken marks the nodes the sorted map creates from now on as its own.\nfunc (r *PersistentRedBlack) ownerToken() *int {\n\tif r.owner == nil {\n\t\tr.owner = new(int)\n\t}\n\treturn r.owner\n}\n\n// own returns h if the sorted map owns it, and otherwise a copy of h it owns,\n// which is modified instead of h.\nfunc (r *PersistentRedBlack) own(h *persistentnode) *persistentnode {\n\tif h.owner == r.ownerToken() {\n\t\treturn h\n\t}\n\tc := *h\n\tc.owner = r.owner\n\treturn &c\n}\n\n// The rotations and color flips below are given nodes the sorted map owns,\n// and own the nodes under them they modify.\n\nfunc (r *PersistentRedBlack) moveRedLeft(h *persistentnode) *persistentnode {\n\tr.flipColors(h)\n\tif h.right.left.isRed() {\n\t\th.right = r.rotateRight(h.right)\n\t\th = r.rotateLeft(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *PersistentRedBlack) moveRedRight(h *persistentnode) *persistentnode {\n\tr.flipColors(h)\n\tif h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *PersistentRedBlack) balance(h *persistentnode) *persistentnode {\n\tif h.right.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\th.n = h.left.size() + h.right.size() + 1\n\treturn h\n}\n\nfunc (r *PersistentRedBlack) rotateLeft(h *persistentnode) *persistentnode {\n\tx := r.own(h.right)\n\th.right = x.left\n\tx.left = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tx.n = h.n\n\th.n = 1 + h.left.size() + h.right.size()\n\treturn x\n}\n\nfunc (r *PersistentRedBlack) rotateRight(h *persistentnode) *persistentnode {\n\tx := r.own(h.left)\n\th.left = x.right\n\tx.right = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tx.n = h.n\n\th.n = 1 + h.left.size() + h.right.size()\n\treturn x\n}\n\nfunc (r *PersistentRedBlack) flipColors(h *persistentnode) {\n\th.left, h.right = r.own(h.left), r.own(h.right)\n\th.colorRed = !h.colorRed\n\th.left.colorRed = !h.left.colorRed\n\th.right.colorRed = !h.right.colorRed\n}\n\n// nodes\n\ntype persistentnode struct {\n\tkey         KType\n\tval         VType\n\tleft, right *persistentnode\n\tn           int\n\tcolorRed    bool\n\t// owner is the owner of the sorted map that created the node\n\towner *int\n}\n\nfunc (x *persistentnode) isRed() bool { return (x != nil) && (x.colorRed == true) }\n\nfunc (x *persistentnode) size() int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\treturn x.n\n}\n"
+	persistentMapTestSrc   = "package redblackbst\n\nimport (\n\t\"fmt\"\n\t\"math/rand\"\n\t\"reflect\"\n\t\"sort\"\n\t\"sync\"\n\t\"testing\"\n)\n\n// The tests of this file are generated along with persistent sorted maps,\n// when asked to. The keys and values are generated by randomKType and\n// randomVType.\n\n// checkPersistentRedBlack verifies the invariants of the tree: the keys are\n// in order, the sizes of the subtrees are right, red links lean left, no node\n// has two red links and every path from the root to a leaf has as many black\n// links.\nfunc checkPersistentRedBlack(t *testing.T, r *PersistentRedBlack) {\n\tif r.root.isRed() {\n\t\tt.Fatal(\"root is red\")\n\t}\n\tcheckPersistentRedBlackNode(t, r, r.root)\n\n\tvar prev *KType\n\tr.Keys(func(k KType, _ VType) bool {\n\t\tif prev != nil && r.compare(*prev, k) >= 0 {\n\t\t\tt.Fatalf(\"keys out of order: %v before %v\", *prev, k)\n\t\t}\n\t\tprev = &k\n\t\treturn true\n\t})\n}\n\n// checkPersistentRedBlackNode returns the number of black links from x to\n// the leaves.\nfunc checkPersistentRedBlackNode(t *testing.T, r *PersistentRedBlack, x *persistentnode) int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\tif x.right.isRed() {\n\t\tt.Fatalf(\"red link leans right under %v\", x.key)\n\t}\n\tif x.isRed() && x.left.isRed() {\n\t\tt.Fatalf(\"two red links in a row under %v\", x.key)\n\t}\n\tif want := 1 + x.left.size() + x.right.size(); x.n != want {\n\t\tt.Fatalf(\"size of %v: want %d, got %d\", x.key, want, x.n)\n\t}\n\tblack := checkPersistentRedBlackNode(t, r, x.left)\n\tif right := checkPersistentRedBlackNode(t, r, x.right); black != right {\n\t\tt.Fatalf(\"black links under %v: %d on the left, %d on the right\", x.key, black, right)\n\t}\n\tif !x.isRed() {\n\t\tblack++\n\t}\n\treturn black\n}\n\n// refPersistentRedBlack is a naive sorted map keeping its entries in a sorted\n// slice, ordered like r.\ntype refPersistentRedBlack struct {\n\tr    *PersistentRedBlack\n\tkeys []KType\n\tvals []VType\n}\n\n// search returns the index of the first key larger or equal to k.\nfunc (ref *refPersistentRedBlack) search(k KType) int {\n\treturn sort.Search(len(ref.keys), func(i int) bool { return ref.r.compare(ref.keys[i], k) >= 0 })\n}\n\nfunc (ref *refPersistentRedBlack) has(k KType) (int, bool) {\n\ti := ref.search(k)\n\treturn i, i < len(ref.keys) && ref.r.compare(ref.keys[i], k) == 0\n}\n\nfunc (ref *refPersistentRedBlack) put(k KType, v VType) {\n\ti, ok := ref.has(k)\n\tif ok {\n\t\tref.vals[i] = v\n\t\treturn\n\t}\n\tref.keys = append(ref.keys[:i], append([]KType{k}, ref.keys[i:]...)...)\n\tref.vals = append(ref.vals[:i], append([]VType{v}, ref.vals[i:]...)...)\n}\n\nfunc (ref *refPersistentRedBlack) delete(i int) {\n\tref.keys = append(ref.keys[:i], ref.keys[i+1:]...)\n\tref.vals = append(ref.vals[:i], ref.vals[i+1:]...)\n}\n\n// snapshot returns a copy of ref, for a snapshot of its sorted map.\nfunc (ref *refPersistentRedBlack) snapshot(r *PersistentRedBlack) *refPersistentRedBlack {\n\treturn &refPersistentRedBlack{\n\t\tr:    r,\n\t\tkeys: append([]KType(nil), ref.keys...),\n\t\tvals: append([]VType(nil), ref.vals...),\n\t}\n}\n\n// diff returns a description of the first difference between the entries of\n// ref and those of its sorted map, or an empty string if they're the same.\nfunc (ref *refPersistentRedBlack) diff() string {\n\tif want, got := len(ref.keys), ref.r.Size(); want != got {\n\t\treturn fmt.Sprintf(\"want size %d, got %d\", want, got)\n\t}\n\tvar diff string\n\ti := 0\n\tref.r.Keys(func(k KType, v VType) bool {\n\t\tif ref.r.compare(ref.keys[i], k) != 0 || !reflect.DeepEqual(ref.vals[i], v) {\n\t\t\tdiff = fmt.Sprintf(\"entry %d: want %v=%v, got %v=%v\", i, ref.keys[i], ref.vals[i], k, v)\n\t\t\treturn false\n\t\t}\n\t\ti++\n\t\treturn true\n\t})\n\treturn diff\n}\n\nfunc TestPersistentRedBlackMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tr := NewPersistentRedBlack()\n\tref := &refPersistentRedBlack{r: r}\n\t// the snapshots taken along the way, each with its own reference\n\tvar snaps []*refPersistentRedBlack\n\n\tcheckEntry := func(op string, i int, k KType, v VType, ok bool) {\n\t\tt.Helper()\n\t\twantOK := i >= 0 && i < len(ref.keys)\n\t\tif ok != wantOK {\n\t\t\tt.Fatalf(\"%s: want ok=%v, got %v\", op, wantOK, ok)\n\t\t}\n\t\tif !ok {\n\t\t\treturn\n\t\t}\n\t\tif r.compare(ref.keys[i], k) != 0 || !reflect.DeepEqual(ref.vals[i], v) {\n\t\t\tt.Fatalf(\"%s: want %v=%v, got %v=%v\", op, ref.keys[i], ref.vals[i], k, v)\n\t\t}\n\t}\n\n\tfor n := 0; n < 5000; n++ {\n\t\tk := randomKType(rnd)\n\t\tswitch op := rnd.Intn(13); op {\n\t\tcase 0, 1, 2, 3:\n\t\t\tv := randomVType(rnd)\n\t\t\ti, had := ref.has(k)\n\t\t\tvar want VType\n\t\t\tif had {\n\t\t\t\twant = ref.vals[i]\n\t\t\t}\n\t\t\told, overwrite := r.Put(k, v)\n\t\t\tif overwrite != had || !reflect.DeepEqual(old, want) {\n\t\t\t\tt.Fatalf(\"put %v: want %v, %v, got %v, %v\", k, want, had, old, overwrite)\n\t\t\t}\n\t\t\tref.put(k, v)\n\t\tcase 4:\n\t\t\ti, ok := ref.has(k)\n\t\t\tv, got := r.Get(k)\n\t\t\tif !ok {\n\t\t\t\ti = -1\n\t\t\t}\n\t\t\tcheckEntry(\"get\", i, k, v, got)\n\t\t\tif r.Has(k) != ok {\n\t\t\t\tt.Fatalf(\"has %v: want %v\", k, ok)\n\t\t\t}\n\t\tcase 5:\n\t\t\ti, ok := ref.has(k)\n\t\t\tv, got := r.Delete(k)\n\t\t\tif !ok {\n\t\t\t\ti = -1\n\t\t\t}\n\t\t\tcheckEntry(\"delete\", i, k, v, got)\n\t\t\tif ok {\n\t\t\t\tref.delete(i)\n\t\t\t}\n\t\tcase 6:\n\t\t\tdk, dv, ok := r.DeleteMin()\n\t\t\tcheckEntry(\"delete min\", 0, dk, dv, ok)\n\t\t\tif ok {\n\t\t\t\tref.delete(0)\n\t\t\t}\n\t\tcase 7:\n\t\t\tdk, dv, ok := r.DeleteMax()\n\t\t\tcheckEntry(\"delete max\", len(ref.keys)-1, dk, dv, ok)\n\t\t\tif ok {\n\t\t\t\tref.delete(len(ref.keys) - 1)\n\t\t\t}\n\t\tcase 8:\n\t\t\tmk, mv, ok := r.Min()\n\t\t\tcheckEntry(\"min\", 0, mk, mv, ok)\n\t\t\tmk, mv, ok = r.Max()\n\t\t\tcheckEntry(\"max\", len(ref.keys)-1, mk, mv, ok)\n\t\t\ti, ok := ref.has(k)\n\t\t\tif !ok {\n\t\t\t\ti--\n\t\t\t}\n\t\t\tfk, fv, ok := r.Floor(k)\n\t\t\tcheckEntry(\"floor\", i, fk, fv, ok)\n\t\t\tck, cv, ok := r.Ceiling(k)\n\t\t\tcheckEntry(\"ceiling\", ref.search(k), ck, cv, ok)\n\t\tcase 9:\n\t\t\tif want, got := ref.search(k), r.Rank(k); want != got {\n\t\t\t\tt.Fatalf(\"rank %v: want %d, got %d\", k, want, got)\n\t\t\t}\n\t\t\ti := rnd.Intn(len(ref.keys) + 2)\n\t\t\tsk, sv, ok := r.Select(i)\n\t\t\tcheckEntry(\"select\", i, sk, sv, ok)\n\t\tcase 10:\n\t\t\tlo, hi := k, randomKType(rnd)\n\t\t\ti := ref.search(lo)\n\t\t\tr.RangedKeys(lo, hi, func(k KType, v VType) bool {\n\t\t\t\tcheckEntry(\"ranged keys\", i, k, v, true)\n\t\t\t\ti++\n\t\t\t\treturn true\n\t\t\t})\n\t\t\tif i < len(ref.keys) && r.compare(ref.keys[i], hi) <= 0 {\n\t\t\t\tt.Fatalf(\"ranged keys [%v, %v]: missed %v\", lo, hi, ref.keys[i])\n\t\t\t}\n\t\tcase 11:\n\t\t\tsnap := r.Snapshot()\n\t\t\tsnaps = append(snaps, ref.snapshot(snap))\n\t\tcase 12:\n\t\t\tif len(snaps) == 0 {\n\t\t\t\tbreak\n\t\t\t}\n\t\t\t// the snapshots can be written to as well, without affecting\n\t\t\t// the sorted map nor the other snapshots\n\t\t\tsnap := snaps[rnd.Intn(len(snaps))]\n\t\t\tif i, ok := snap.has(k); ok && rnd.Intn(2) == 0 {\n\t\t\t\tsnap.r.Delete(k)\n\t\t\t\tsnap.delete(i)\n\t\t\t} else {\n\t\t\t\tv := randomVType(rnd)\n\t\t\t\tsnap.r.Put(k, v)\n\t\t\t\tsnap.put(k, v)\n\t\t\t}\n\t\t\tcheckPersistentRedBlack(t, snap.r)\n\t\t}\n\t\tif want, got := len(ref.keys), r.Size(); want != got {\n\t\t\tt.Fatalf(\"want size %d, got %d\", want, got)\n\t\t}\n\t\tcheckPersistentRedBlack(t, r)\n\n\t\tif n%500 == 0 {\n\t\t\tfor i, snap := range snaps {\n\t\t\t\tif diff := snap.diff(); diff != \"\" {\n\t\t\t\t\tt.Fatalf(\"snapshot %d of %d: %s\", i, len(snaps), diff)\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n\n\tif diff := ref.diff(); diff != \"\" {\n\t\tt.Fatal(diff)\n\t}\n\tfor i, snap := range snaps {\n\t\tcheckPersistentRedBlack(t, snap.r)\n\t\tif diff := snap.diff(); diff != \"\" {\n\t\t\tt.Fatalf(\"snapshot %d of %d: %s\", i, len(snaps), diff)\n\t\t}\n\t}\n}\n\n// nodesOfPersistentRedBlack returns the nodes of the tree of x.\nfunc nodesOfPersistentRedBlack(x *persistentnode, nodes map[*persistentnode]bool) map[*persistentnode]bool {\n\tif x != nil {\n\t\tnodes[x] = true\n\t\tnodesOfPersistentRedBlack(x.left, nodes)\n\t\tnodesOfPersistentRedBlack(x.right, nodes)\n\t}\n\treturn nodes\n}\n\n// TestPersistentRedBlackSharesNodes verifies that a write after a snapshot\n// only copies the nodes around the path to its key, the sorted map and the\n// snapshot sharing the others.\nfunc TestPersistentRedBlackSharesNodes(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tr := NewPersistentRedBlack()\n\tfor i := 0; i < 1000; i++ {\n\t\tr.Put(randomKType(rnd), randomVType(rnd))\n\t}\n\t// a path has at most twice as many links as it has black links, and the\n\t// children of the nodes on the path may be copied along with them\n\tvar black int\n\tfor x := r.root; x != nil; x = x.left {\n\t\tif !x.isRed() {\n\t\t\tblack++\n\t\t}\n\t}\n\tmaxCopied := 3 * (2*black + 1)\n\n\tfor n := 0; n < 200; n++ {\n\t\tsnap := r.Snapshot()\n\t\tbefore := nodesOfPersistentRedBlack(snap.root, make(map[*persistentnode]bool))\n\t\tref := &refPersistentRedBlack{r: snap}\n\t\tsnap.Keys(func(k KType, v VType) bool {\n\t\t\tref.keys, ref.vals = append(ref.keys, k), append(ref.vals, v)\n\t\t\treturn true\n\t\t})\n\n\t\tk := randomKType(rnd)\n\t\top := \"put\"\n\t\tif min, _, _ := r.Min(); n%2 == 0 {\n\t\t\top = \"delete\"\n\t\t\tr.Delete(min)\n\t\t} else {\n\t\t\tr.Put(k, randomVType(rnd))\n\t\t}\n\t\tcheckPersistentRedBlack(t, r)\n\n\t\tafter := nodesOfPersistentRedBlack(r.root, make(map[*persistentnode]bool))\n\t\tcopied := 0\n\t\tfor x := range after {\n\t\t\tif !before[x] {\n\t\t\t\tcopied++\n\t\t\t}\n\t\t}\n\t\tif copied > maxCopied {\n\t\t\tt.Fatalf(\"%s: want at most %d nodes copied, got %d\", op, maxCopied, copied)\n\t\t}\n\t\tif diff := ref.diff(); diff != \"\" {\n\t\t\tt.Fatalf(\"%s: the snapshot changed: %s\", op, diff)\n\t\t}\n\n\t\t// without another snapshot, the nodes copied are modified in place\n\t\tif op == \"put\" {\n\t\t\tr.Put(k, randomVType(rnd))\n\t\t\tfor x := range nodesOfPersistentRedBlack(r.root, make(map[*persistentnode]bool)) {\n\t\t\t\tif !after[x] {\n\t\t\t\t\tt.Fatalf(\"put again: %v was copied again\", x.key)\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n\n// TestPersistentRedBlackSnapshotsReadConcurrently reads snapshots while the\n// sorted map is written to, which the race detector verifies.\nfunc TestPersistentRedBlackSnapshotsReadConcurrently(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tr := NewPersistentRedBlack()\n\tref := &refPersistentRedBlack{r: r}\n\n\tsnaps := make(chan *refPersistentRedBlack)\n\tvar wg sync.WaitGroup\n\tfor i := 0; i < 4; i++ {\n\t\twg.Add(1)\n\t\tgo func() {\n\t\t\tdefer wg.Done()\n\t\t\tfor snap := range snaps {\n\t\t\t\tif diff := snap.diff(); diff != \"\" {\n\t\t\t\t\tt.Errorf(\"snapshot of %d keys: %s\", len(snap.keys), diff)\n\t\t\t\t}\n\t\t\t}\n\t\t}()\n\t}\n\n\tfor n := 0; n < 2000; n++ {\n\t\tk := randomKType(rnd)\n\t\tif i, ok := ref.has(k); ok && rnd.Intn(3) == 0 {\n\t\t\tr.Delete(k)\n\t\t\tref.delete(i)\n\t\t} else {\n\t\t\tv := randomVType(rnd)\n\t\t\tr.Put(k, v)\n\t\t\tref.put(k, v)\n\t\t}\n\t\tif n%10 == 0 {\n\t\t\tsnaps <- ref.snapshot(r.Snapshot())\n\t\t}\n\t}\n\tclose(snaps)\n\twg.Wait()\n}\n"
+	persistentMapBenchSrc  = "package redblackbst\n\nimport (\n\t\"math/rand\"\n\t\"strconv\"\n\t\"testing\"\n)\n\n// The benchmarks of this file are generated along with persistent sorted\n// maps, when asked to. The keys and values are generated by benchKType and\n// benchVType.\n\n// benchPersistentRedBlackSizes are the numbers of keys in the benchmarked\n// maps.\nvar benchPersistentRedBlackSizes = []int{100, 10000, 1000000}\n\n// benchPersistentRedBlack runs bench for each size, with a map holding that\n// many random keys, and the keys and values put in it. The keys are the same\n// from one benchmark to the other.\nfunc benchPersistentRedBlack(b *testing.B, bench func(b *testing.B, r *PersistentRedBlack, keys []KType, vals []VType)) {\n\tfor _, n := range benchPersistentRedBlackSizes {\n\t\tn := n\n\t\tvar r *PersistentRedBlack\n\t\tvar keys []KType\n\t\tvar vals []VType\n\t\tb.Run(strconv.Itoa(n), func(b *testing.B) {\n\t\t\t// once for all the runs of the benchmark, and only if it's run\n\t\t\tif r == nil {\n\t\t\t\trnd := rand.New(rand.NewSource(int64(n)))\n\t\t\t\tkeys = make([]KType, n)\n\t\t\t\tvals = make([]VType, n)\n\t\t\t\tfor i := range keys {\n\t\t\t\t\tkeys[i], vals[i] = benchKType(rnd), benchVType(rnd)\n\t\t\t\t}\n\t\t\t\tr = NewPersistentRedBlack()\n\t\t\t\tfor i, k := range keys {\n\t\t\t\t\tr.Put(k, vals[i])\n\t\t\t\t}\n\t\t\t}\n\t\t\t// the benchmarks write to a snapshot, leaving r as it is\n\t\t\tbench(b, r.Snapshot(), keys, vals)\n\t\t})\n\t}\n}\n\n// BenchmarkPersistentRedBlackPut overwrites the keys, without snapshots but\n// for the first one.\nfunc BenchmarkPersistentRedBlackPut(b *testing.B) {\n\tbenchPersistentRedBlack(b, func(b *testing.B, r *PersistentRedBlack, keys []KType, vals []VType) {\n\t\tn := len(keys)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Put(keys[i%n], vals[i%n])\n\t\t}\n\t})\n}\n\n// BenchmarkPersistentRedBlackPutSnapshot overwrites the keys, taking a\n// snapshot before each put, which copies its path.\nfunc BenchmarkPersistentRedBlackPutSnapshot(b *testing.B) {\n\tbenchPersistentRedBlack(b, func(b *testing.B, r *PersistentRedBlack, keys []KType, vals []VType) {\n\t\tn := len(keys)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Snapshot()\n\t\t\tr.Put(keys[i%n], vals[i%n])\n\t\t}\n\t})\n}\n\nfunc BenchmarkPersistentRedBlackGet(b *testing.B) {\n\tbenchPersistentRedBlack(b, func(b *testing.B, r *PersistentRedBlack, keys []KType, vals []VType) {\n\t\tn := len(keys)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Get(keys[i%n])\n\t\t}\n\t})\n}\n\n// BenchmarkPersistentRedBlackDeleteSnapshot deletes a key from a snapshot of\n// the map at each iteration, leaving the map as it is.\nfunc BenchmarkPersistentRedBlackDeleteSnapshot(b *testing.B) {\n\tbenchPersistentRedBlack(b, func(b *testing.B, r *PersistentRedBlack, keys []KType, vals []VType) {\n\t\tn := len(keys)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Snapshot().Delete(keys[i%n])\n\t\t}\n\t})\n}\n"
+	multimapSrc            = "package redblackbst\n\n// The implementation was forked from the one of the sorted maps\n// (map/redblackbst/rbbst.go in datagen), its nodes holding all the values of\n// their key: a fix to the balancing of either tree is to be made to the\n// other. Get and Delete became GetAll, DeleteOne and DeleteAll, and the other\n// methods the sorted maps gained since are missing here: Floor, Ceiling,\n// Lower, Higher, ReverseKeys, RangedKeysDesc, RangeCount, BoundedKeys,\n// Cursor, DeleteMin, DeleteMax, DeleteRange, ToSlice, KeysSlice, ValuesSlice,\n// the aggregates and the constructors from sorted and unsorted slices.\n\nfunc (r MultiRedBlack) compare(a, b KType) int { return a.Compare(b) }\n\n// MultiRedBlack is a sorted multimap built on a left leaning red black\n// balanced search tree. It stores VType values, keyed by KType, keeping all\n// the values put at a key in the order they were put. Its entries are the\n// key/value pairs, ordered by key then in that order, which sizes, ranks and\n// selections count.\ntype MultiRedBlack struct {\n\troot *multinode\n}\n\n// NewMultiRedBlack creates a sorted multimap.\nfunc NewMultiRedBlack() *MultiRedBlack { return &MultiRedBlack{} }\n\n// IsEmpty tells if the sorted multimap contains no key/value.\nfunc (r MultiRedBlack) IsEmpty() bool {\n\treturn r.root == nil\n}\n\n// Size of the sorted multimap, counting each value of each key.\nfunc (r MultiRedBlack) Size() int { return r.root.size() }\n\n// Clear all the values in the sorted multimap.\nfunc (r *MultiRedBlack) Clear() { r.root = nil }\n\n// Put a value in the sorted multimap at key `k`, after the values already at\n// `k`.\nfunc (r *MultiRedBlack) Put(k KType, v VType) {\n\tr.PutAll(k, v)\n}\n\n// PutAll puts values in the sorted multimap at key `k`, in order, after the\n// values already at `k`.\nfunc (r *MultiRedBlack) PutAll(k KType, vs ...VType) {\n\tif len(vs) == 0 {\n\t\treturn\n\t}\n\tr.root = r.put(r.root, k, vs)\n\tr.root.colorRed = false\n}\n\nfunc (r *MultiRedBlack) put(h *multinode, k KType, vs []VType) *multinode {\n\tif h == nil {\n\t\tvals := append([]VType(nil), vs...)\n\t\treturn &multinode{key: k, vals: vals, n: len(vals), colorRed: true}\n\t}\n\n\tcmp := r.compare(k, h.key)\n\tif cmp < 0 {\n\t\th.left = r.put(h.left, k, vs)\n\t} else if cmp > 0 {\n\t\th.right = r.put(h.right, k, vs)\n\t} else {\n\t\th.vals = append(h.vals, vs...)\n\t}\n\n\tif h.right.isRed() && !h.left.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\th.n = h.left.size() + h.right.size() + len(h.vals)\n\treturn h\n}\n\n// GetAll returns the values at key `k` in the sorted multimap, in the order\n// they were put, or none if the key doesn't exist.\nfunc (r MultiRedBlack) GetAll(k KType) []VType {\n\th := r.find(k)\n\tif h == nil {\n\t\treturn nil\n\t}\n\treturn append([]VType(nil), h.vals...)\n}\n\n// Has tells if values exist at key `k`.\nfunc (r MultiRedBlack) Has(k KType) bool { return r.find(k) != nil }\n\n// Count is the number of values at key `k`.\nfunc (r MultiRedBlack) Count(k KType) int {\n\th := r.find(k)\n\tif h == nil {\n\t\treturn 0\n\t}\n\treturn len(h.vals)\n}\n\nfunc (r MultiRedBlack) find(k KType) *multinode {\n\tfor h := r.root; h != nil; {\n\t\tcmp := r.compare(k, h.key)\n\t\tif cmp == 0 {\n\t\t\treturn h\n\t\t} else if cmp < 0 {\n\t\t\th = h.left\n\t\t} else {\n\t\t\th = h.right\n\t\t}\n\t}\n\treturn nil\n}\n\n// Min returns the smallest key in the sorted multimap and its first value,\n// if it exists.\nfunc (r MultiRedBlack) Min() (k KType, v VType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\th := r.root\n\tfor h.left != nil {\n\t\th = h.left\n\t}\n\treturn h.key, h.vals[0], true\n}\n\n// Max returns the largest key in the sorted multimap and its last value, if\n// it exists.\nfunc (r MultiRedBlack) Max() (k KType, v VType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\th := r.root\n\tfor h.right != nil {\n\t\th = h.right\n\t}\n\treturn h.key, h.vals[len(h.vals)-1], true\n}\n\n// Select the key/value of rank `i`, meaning the i-th smallest key/value of\n// the sorted multimap, counting each value of each key.\nfunc (r MultiRedBlack) Select(i int) (k KType, v VType, ok bool) {\n\tfor h := r.root; h != nil; {\n\t\tt := h.left.size()\n\t\tif i < t {\n\t\t\th = h.left\n\t\t} else if i < t+len(h.vals) {\n\t\t\treturn h.key, h.vals[i-t], true\n\t\t} else {\n\t\t\th, i = h.right, i-t-len(h.vals)\n\t\t}\n\t}\n\treturn\n}\n\n// Rank is the number of values at keys less than `k`.\nfunc (r MultiRedBlack) Rank(k KType) int {\n\trank := 0\n\tfor h := r.root; h != nil; {\n\t\tcmp := r.compare(k, h.key)\n\t\tif cmp < 0 {\n\t\t\th = h.left\n\t\t} else if cmp > 0 {\n\t\t\trank += h.left.size() + len(h.vals)\n\t\t\th = h.right\n\t\t} else {\n\t\t\treturn rank + h.left.size()\n\t\t}\n\t}\n\treturn rank\n}\n\n// Keys visit each key/value in the sorted multimap, in order, visiting a key\n// once for each of its values. It stops when visit returns false.\nfunc (r MultiRedBlack) Keys(visit func(KType, VType) bool) {\n\tmin, _, ok := r.Min()\n\tif !ok {\n\t\treturn\n\t}\n\t// if the min exists, then the max must exist\n\tmax, _, _ := r.Max()\n\tr.RangedKeys(min, max, visit)\n}\n\n// RangedKeys visit each key/value between lo and hi in the sorted multimap,\n// in order. It stops when visit returns false.\nfunc (r MultiRedBlack) RangedKeys(lo, hi KType, visit func(KType, VType) bool) {\n\tr.keys(r.root, visit, lo, hi)\n}\n\nfunc (r MultiRedBlack) keys(h *multinode, visit func(KType, VType) bool, lo, hi KType) bool {\n\tif h == nil {\n\t\treturn true\n\t}\n\tcmplo := r.compare(lo, h.key)\n\tcmphi := r.compare(hi, h.key)\n\tif cmplo < 0 {\n\t\tif !r.keys(h.left, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmplo <= 0 && cmphi >= 0 {\n\t\tfor _, v := range h.vals {\n\t\t\tif !visit(h.key, v) {\n\t\t\t\treturn false\n\t\t\t}\n\t\t}\n\t}\n\tif cmphi > 0 {\n\t\tif !r.keys(h.right, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\n// deletions\n\n// DeleteOne removes the first value put at key `k` from the sorted multimap,\n// if it exists. The key is removed along with its last value.\nfunc (r *MultiRedBlack) DeleteOne(k KType) (old VType, ok bool) {\n\th := r.find(k)\n\tif h == nil {\n\t\treturn\n\t}\n\tif len(h.vals) == 1 {\n\t\tvals := r.delete(k)\n\t\treturn vals[0], true\n\t}\n\told = h.vals[0]\n\t// not holding on to the value removed\n\tvar zero VType\n\th.vals[0] = zero\n\th.vals = h.vals[1:]\n\t// one less value under the nodes on the path to h\n\tfor x := r.root; x != h; {\n\t\tx.n--\n\t\tif r.compare(k, x.key) < 0 {\n\t\t\tx = x.left\n\t\t} else {\n\t\t\tx = x.right\n\t\t}\n\t}\n\th.n--\n\treturn old, true\n}\n\n// DeleteAll removes key `k` and its values from the sorted multimap, and\n// returns the values in the order they were put, if it exists.\nfunc (r *MultiRedBlack) DeleteAll(k KType) (old []VType) {\n\tif !r.Has(k) {\n\t\treturn nil\n\t}\n\treturn r.delete(k)\n}\n\n// delete removes `k`, which is in the sorted multimap, and returns its values.\nfunc (r *MultiRedBlack) delete(k KType) (old []VType) {\n\tr.root, old = r.deleteNode(r.root, k)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn old\n}\n\nfunc (r *MultiRedBlack) deleteNode(h *multinode, k KType) (_ *multinode, old []VType) {\n\tif r.compare(k, h.key) < 0 {\n\t\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\t\th = r.moveRedLeft(h)\n\t\t}\n\t\th.left, old = r.deleteNode(h.left, k)\n\t\treturn r.balance(h), old\n\t}\n\n\tif h.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\n\tif r.compare(k, h.key) == 0 && h.right == nil {\n\t\treturn nil, h.vals\n\t}\n\n\tif !h.right.isRed() && !h.right.left.isRed() {\n\t\th = r.moveRedRight(h)\n\t}\n\n\tif r.compare(k, h.key) == 0 {\n\t\tvar min *multinode\n\t\th.right, min = r.deleteMin(h.right)\n\t\told, h.key, h.vals = h.vals, min.key, min.vals\n\t} else {\n\t\th.right, old = r.deleteNode(h.right, k)\n\t}\n\treturn r.balance(h), old\n}\n\n// deleteMin removes the smallest key of the tree of h, which isn't empty,\n// and returns its node.\nfunc (r *MultiRedBlack) deleteMin(h *multinode) (_, min *multinode) {\n\tif h.left == nil {\n\t\treturn nil, h\n\t}\n\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\th = r.moveRedLeft(h)\n\t}\n\th.left, min = r.deleteMin(h.left)\n\treturn r.balance(h), min\n}\n\nfunc (r *MultiRedBlack) moveRedLeft(h *multinode) *multinode {\n\tr.flipColors(h)\n\tif h.right.left.isRed() {\n\t\th.right = r.rotateRight(h.right)\n\t\th = r.rotateLeft(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *MultiRedBlack) moveRedRight(h *multinode) *multinode {\n\tr.flipColors(h)\n\tif h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *MultiRedBlack) balance(h *multinode) *multinode {\n\tif h.right.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\th.n = h.left.size() + h.right.size() + len(h.vals)\n\treturn h\n}\n\nfunc (r *MultiRedBlack) rotateLeft(h *multinode) *multinode {\n\tx := h.right\n\th.right = x.left\n\tx.left = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tx.n = h.n\n\th.n = len(h.vals) + h.left.size() + h.right.size()\n\treturn x\n}\n\nfunc (r *MultiRedBlack) rotateRight(h *multinode) *multinode {\n\tx := h.left\n\th.left = x.right\n\tx.right = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tx.n = h.n\n\th.n = len(h.vals) + h.left.size() + h.right.size()\n\treturn x\n}\n\nfunc (r *MultiRedBlack) flipColors(h *multinode) {\n\th.colorRed = !h.colorRed\n\th.left.colorRed = !h.left.colorRed\n\th.right.colorRed = !h.right.colorRed\n}\n\n// nodes\n\ntype multinode struct {\n\tkey KType\n\t// vals are the values at the key, in the order they were put, of which\n\t// there's always one at least\n\tvals        []VType\n\tleft, right *multinode\n\t// n is the number of values of the tree of the node\n\tn        int\n\tcolorRed bool\n}\n\nfunc (x *multinode) isRed() bool { return (x != nil) && (x.colorRed == true) }\n\nfunc (x *multinode) size() int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\treturn x.n\n}\n"
+	multimapTestSrc        = "package redblackbst\n\nimport (\n\t\"math/rand\"\n\t\"reflect\"\n\t\"sort\"\n\t\"testing\"\n)\n\n// The tests of this file are generated along with sorted multimaps, when\n// asked to. The keys and values are generated by randomKType and\n// randomVType.\n\n// checkMultiRedBlack verifies the invariants of the tree: the keys are in\n// order, each has a value at least, the sizes of the subtrees are right, red\n// links lean left, no node has two red links and every path from the root\n// to a leaf has as many black links.\nfunc checkMultiRedBlack(t *testing.T, r *MultiRedBlack) {\n\tif r.root.isRed() {\n\t\tt.Fatal(\"root is red\")\n\t}\n\tcheckMultiRedBlackNode(t, r, r.root)\n\n\tvar prev *KType\n\tr.Keys(func(k KType, _ VType) bool {\n\t\tif prev != nil && r.compare(*prev, k) > 0 {\n\t\t\tt.Fatalf(\"keys out of order: %v before %v\", *prev, k)\n\t\t}\n\t\tprev = &k\n\t\treturn true\n\t})\n}\n\n// checkMultiRedBlackNode returns the number of black links from x to the\n// leaves.\nfunc checkMultiRedBlackNode(t *testing.T, r *MultiRedBlack, x *multinode) int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\tif len(x.vals) == 0 {\n\t\tt.Fatalf(\"%v has no values\", x.key)\n\t}\n\tif x.right.isRed() {\n\t\tt.Fatalf(\"red link leans right under %v\", x.key)\n\t}\n\tif x.isRed() && x.left.isRed() {\n\t\tt.Fatalf(\"two red links in a row under %v\", x.key)\n\t}\n\tif want := len(x.vals) + x.left.size() + x.right.size(); x.n != want {\n\t\tt.Fatalf(\"size of %v: want %d, got %d\", x.key, want, x.n)\n\t}\n\tblack := checkMultiRedBlackNode(t, r, x.left)\n\tif right := checkMultiRedBlackNode(t, r, x.right); black != right {\n\t\tt.Fatalf(\"black links under %v: %d on the left, %d on the right\", x.key, black, right)\n\t}\n\tif !x.isRed() {\n\t\tblack++\n\t}\n\treturn black\n}\n\n// refMultiRedBlack is a naive sorted multimap keeping its keys in a sorted\n// slice, each with the slice of its values, ordered like r.\ntype refMultiRedBlack struct {\n\tr    *MultiRedBlack\n\tkeys []KType\n\tvals [][]VType\n}\n\n// search returns the index of the first key larger or equal to k.\nfunc (ref *refMultiRedBlack) search(k KType) int {\n\treturn sort.Search(len(ref.keys), func(i int) bool { return ref.r.compare(ref.keys[i], k) >= 0 })\n}\n\nfunc (ref *refMultiRedBlack) has(k KType) (int, bool) {\n\ti := ref.search(k)\n\treturn i, i < len(ref.keys) && ref.r.compare(ref.keys[i], k) == 0\n}\n\nfunc (ref *refMultiRedBlack) put(k KType, vs []VType) {\n\tif len(vs) == 0 {\n\t\treturn\n\t}\n\ti, ok := ref.has(k)\n\tif ok {\n\t\tref.vals[i] = append(ref.vals[i], vs...)\n\t\treturn\n\t}\n\tref.keys = append(ref.keys[:i], append([]KType{k}, ref.keys[i:]...)...)\n\tref.vals = append(ref.vals[:i], append([][]VType{append([]VType(nil), vs...)}, ref.vals[i:]...)...)\n}\n\nfunc (ref *refMultiRedBlack) delete(i int) {\n\tref.keys = append(ref.keys[:i], ref.keys[i+1:]...)\n\tref.vals = append(ref.vals[:i], ref.vals[i+1:]...)\n}\n\n// entries returns the keys/values from index i of the keys, each key once\n// for each of its values.\nfunc (ref *refMultiRedBlack) entries(i int) (keys []KType, vals []VType) {\n\tfor ; i < len(ref.keys); i++ {\n\t\tfor _, v := range ref.vals[i] {\n\t\t\tkeys, vals = append(keys, ref.keys[i]), append(vals, v)\n\t\t}\n\t}\n\treturn keys, vals\n}\n\nfunc TestMultiRedBlackMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tr := NewMultiRedBlack()\n\tref := &refMultiRedBlack{r: r}\n\n\tcheckEntry := func(op string, wantK KType, wantV VType, wantOK bool, k KType, v VType, ok bool) {\n\t\tt.Helper()\n\t\tif ok != wantOK {\n\t\t\tt.Fatalf(\"%s: want ok=%v, got %v\", op, wantOK, ok)\n\t\t}\n\t\tif ok && (r.compare(wantK, k) != 0 || !reflect.DeepEqual(wantV, v)) {\n\t\t\tt.Fatalf(\"%s: want %v=%v, got %v=%v\", op, wantK, wantV, k, v)\n\t\t}\n\t}\n\n\tfor n := 0; n < 5000; n++ {\n\t\t// a narrower range of keys, for them to have several values\n\t\tk := randomKType(rnd)\n\t\tif len(ref.keys) != 0 && rnd.Intn(2) == 0 {\n\t\t\tk = ref.keys[rnd.Intn(len(ref.keys))]\n\t\t}\n\t\tswitch op := rnd.Intn(12); op {\n\t\tcase 0, 1, 2:\n\t\t\tv := randomVType(rnd)\n\t\t\tr.Put(k, v)\n\t\t\tref.put(k, []VType{v})\n\t\tcase 3:\n\t\t\tvs := make([]VType, rnd.Intn(4))\n\t\t\tfor i := range vs {\n\t\t\t\tvs[i] = randomVType(rnd)\n\t\t\t}\n\t\t\tr.PutAll(k, vs...)\n\t\t\tref.put(k, vs)\n\t\tcase 4:\n\t\t\ti, ok := ref.has(k)\n\t\t\tvar want []VType\n\t\t\tif ok {\n\t\t\t\twant = ref.vals[i]\n\t\t\t}\n\t\t\tif got := r.GetAll(k); !reflect.DeepEqual(want, got) {\n\t\t\t\tt.Fatalf(\"get all %v: want %v, got %v\", k, want, got)\n\t\t\t}\n\t\t\tif r.Has(k) != ok || r.Count(k) != len(want) {\n\t\t\t\tt.Fatalf(\"has %v: want %v and %d values, got %v and %d\", k, ok, len(want), r.Has(k), r.Count(k))\n\t\t\t}\n\t\tcase 5, 6:\n\t\t\ti, ok := ref.has(k)\n\t\t\tvar want VType\n\t\t\tif ok {\n\t\t\t\twant = ref.vals[i][0]\n\t\t\t\tif ref.vals[i] = ref.vals[i][1:]; len(ref.vals[i]) == 0 {\n\t\t\t\t\tref.delete(i)\n\t\t\t\t}\n\t\t\t}\n\t\t\tv, got := r.DeleteOne(k)\n\t\t\tcheckEntry(\"delete one\", k, want, ok, k, v, got)\n\t\tcase 7:\n\t\t\ti, ok := ref.has(k)\n\t\t\tvar want []VType\n\t\t\tif ok {\n\t\t\t\twant = ref.vals[i]\n\t\t\t\tref.delete(i)\n\t\t\t}\n\t\t\tif got := r.DeleteAll(k); !reflect.DeepEqual(want, got) {\n\t\t\t\tt.Fatalf(\"delete all %v: want %v, got %v\", k, want, got)\n\t\t\t}\n\t\tcase 8:\n\t\t\tkeys, vals := ref.entries(0)\n\t\t\tmk, mv, ok := r.Min()\n\t\t\tif len(keys) == 0 {\n\t\t\t\tif _, _, maxOK := r.Max(); ok || maxOK {\n\t\t\t\t\tt.Fatalf(\"min and max of an empty multimap: want none, got %v and %v\", ok, maxOK)\n\t\t\t\t}\n\t\t\t\tbreak\n\t\t\t}\n\t\t\tcheckEntry(\"min\", keys[0], vals[0], true, mk, mv, ok)\n\t\t\tmk, mv, ok = r.Max()\n\t\t\tcheckEntry(\"max\", keys[len(keys)-1], vals[len(vals)-1], true, mk, mv, ok)\n\t\tcase 9:\n\t\t\tkeys, vals := ref.entries(0)\n\t\t\ti := rnd.Intn(len(keys) + 2)\n\t\t\tsk, sv, ok := r.Select(i)\n\t\t\tif i < len(keys) {\n\t\t\t\tcheckEntry(\"select\", keys[i], vals[i], true, sk, sv, ok)\n\t\t\t} else if ok {\n\t\t\t\tt.Fatalf(\"select %d of %d: want none, got %v=%v\", i, len(keys), sk, sv)\n\t\t\t}\n\t\t\tbefore, _ := ref.entries(ref.search(k))\n\t\t\tif want, got := len(keys)-len(before), r.Rank(k); want != got {\n\t\t\t\tt.Fatalf(\"rank %v: want %d, got %d\", k, want, got)\n\t\t\t}\n\t\tcase 10, 11:\n\t\t\tlo, hi := k, randomKType(rnd)\n\t\t\tkeys, vals := ref.entries(ref.search(lo))\n\t\t\ti := 0\n\t\t\tr.RangedKeys(lo, hi, func(k KType, v VType) bool {\n\t\t\t\tif i == len(keys) {\n\t\t\t\t\tt.Fatalf(\"ranged keys [%v, %v]: visited %v=%v past the end\", lo, hi, k, v)\n\t\t\t\t}\n\t\t\t\tcheckEntry(\"ranged keys\", keys[i], vals[i], true, k, v, true)\n\t\t\t\ti++\n\t\t\t\treturn true\n\t\t\t})\n\t\t\tif i < len(keys) && r.compare(keys[i], hi) <= 0 {\n\t\t\t\tt.Fatalf(\"ranged keys [%v, %v]: missed %v\", lo, hi, keys[i])\n\t\t\t}\n\t\t}\n\t\tkeys, _ := ref.entries(0)\n\t\tif want, got := len(keys), r.Size(); want != got {\n\t\t\tt.Fatalf(\"want size %d, got %d\", want, got)\n\t\t}\n\t\tcheckMultiRedBlack(t, r)\n\t}\n}\n"
+	multimapBenchSrc       = "package redblackbst\n\nimport (\n\t\"math/rand\"\n\t\"strconv\"\n\t\"testing\"\n)\n\n// The benchmarks of this file are generated along with sorted multimaps,\n// when asked to. The keys and values are generated by benchKType and\n// benchVType.\n\n// benchMultiRedBlackSizes are the numbers of values in the benchmarked\n// multimaps.\nvar benchMultiRedBlackSizes = []int{100, 10000, 1000000}\n\n// benchMultiRedBlack runs bench for each size, with a multimap holding that\n// many random values, at half as many keys, and the keys and values put in\n// it. The keys are the same from one benchmark to the other.\nfunc benchMultiRedBlack(b *testing.B, bench func(b *testing.B, r *MultiRedBlack, keys []KType, vals []VType)) {\n\tfor _, n := range benchMultiRedBlackSizes {\n\t\tn := n\n\t\tvar r *MultiRedBlack\n\t\tvar keys []KType\n\t\tvar vals []VType\n\t\tb.Run(strconv.Itoa(n), func(b *testing.B) {\n\t\t\t// once for all the runs of the benchmark, and only if it's run\n\t\t\tif r == nil {\n\t\t\t\trnd := rand.New(rand.NewSource(int64(n)))\n\t\t\t\tkeys = make([]KType, n)\n\t\t\t\tvals = make([]VType, n)\n\t\t\t\tfor i := range keys {\n\t\t\t\t\tkeys[i], vals[i] = benchKType(rnd), benchVType(rnd)\n\t\t\t\t\tif i%2 == 1 {\n\t\t\t\t\t\tkeys[i] = keys[i-1]\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t\tr = NewMultiRedBlack()\n\t\t\t\tfor i, k := range keys {\n\t\t\t\t\tr.Put(k, vals[i])\n\t\t\t\t}\n\t\t\t}\n\t\t\tbench(b, r, keys, vals)\n\t\t})\n\t}\n}\n\n// BenchmarkMultiRedBlackDeleteOnePut removes the first value of a key and\n// puts it back last, leaving as many values in the multimap.\nfunc BenchmarkMultiRedBlackDeleteOnePut(b *testing.B) {\n\tbenchMultiRedBlack(b, func(b *testing.B, r *MultiRedBlack, keys []KType, vals []VType) {\n\t\tn := len(keys)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tk := keys[i%n]\n\t\t\tv, _ := r.DeleteOne(k)\n\t\t\tr.Put(k, v)\n\t\t}\n\t})\n}\n\nfunc BenchmarkMultiRedBlackCount(b *testing.B) {\n\tbenchMultiRedBlack(b, func(b *testing.B, r *MultiRedBlack, keys []KType, vals []VType) {\n\t\tn := len(keys)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Count(keys[i%n])\n\t\t}\n\t})\n}\n\nfunc BenchmarkMultiRedBlackSelect(b *testing.B) {\n\tbenchMultiRedBlack(b, func(b *testing.B, r *MultiRedBlack, keys []KType, vals []VType) {\n\t\tn := r.Size()\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Select(i % n)\n\t\t}\n\t})\n}\n"
+	multisetSrc            = "package redblackbst\n\n// The implementation was forked from the one of the sorted sets\n// (set/redblackbst/rbbst.go in datagen), its nodes counting the occurrences\n// of their key: a fix to the balancing of either tree is to be made to the\n// other. Put and Delete became Add and Remove, and the other methods the\n// sorted sets gained since are missing here: Floor, Ceiling, Lower, Higher,\n// ReverseKeys, RangedKeysDesc, RangeCount, BoundedKeys, Cursor, DeleteMin,\n// DeleteMax, DeleteRange, ToSlice, the set operations and the constructors\n// from sorted and unsorted slices.\n\nfunc (r MultiRedBlack) compare(a, b KType) int { return a.Compare(b) }\n\n// MultiRedBlack is a sorted multiset built on a left leaning red black\n// balanced search tree. It stores KType keys, each with the count of its\n// occurrences. Sizes, ranks and selections count each occurrence, so that\n// percentiles are found in O(log n).\ntype MultiRedBlack struct {\n\troot *multinode\n\t// distinct is the number of keys, counting each once\n\tdistinct int\n}\n\n// NewMultiRedBlack creates a sorted multiset.\nfunc NewMultiRedBlack() *MultiRedBlack { return &MultiRedBlack{} }\n\n// IsEmpty tells if the sorted multiset contains no key.\nfunc (r MultiRedBlack) IsEmpty() bool {\n\treturn r.root == nil\n}\n\n// Size of the sorted multiset, counting each occurrence of each key.\nfunc (r MultiRedBlack) Size() int { return r.root.size() }\n\n// Distinct is the number of keys of the sorted multiset, counting each once.\nfunc (r MultiRedBlack) Distinct() int { return r.distinct }\n\n// Clear all the keys in the sorted multiset.\nfunc (r *MultiRedBlack) Clear() { r.root, r.distinct = nil, 0 }\n\n// Add `n` occurrences of the key `k` to the sorted multiset, and return how\n// many there are now. It panics if `n` is negative.\nfunc (r *MultiRedBlack) Add(k KType, n int) (count int) {\n\tif n < 0 {\n\t\tpanic(\"redblackbst: can't add a negative number of occurrences\")\n\t}\n\tif n == 0 {\n\t\treturn r.Count(k)\n\t}\n\tr.root, count = r.add(r.root, k, n)\n\tr.root.colorRed = false\n\treturn count\n}\n\nfunc (r *MultiRedBlack) add(h *multinode, k KType, n int) (_ *multinode, count int) {\n\tif h == nil {\n\t\tr.distinct++\n\t\treturn &multinode{key: k, count: n, n: n, colorRed: true}, n\n\t}\n\n\tcmp := r.compare(k, h.key)\n\tif cmp < 0 {\n\t\th.left, count = r.add(h.left, k, n)\n\t} else if cmp > 0 {\n\t\th.right, count = r.add(h.right, k, n)\n\t} else {\n\t\th.count += n\n\t\tcount = h.count\n\t}\n\n\tif h.right.isRed() && !h.left.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\th.n = h.left.size() + h.right.size() + h.count\n\treturn h, count\n}\n\n// Count is the number of occurrences of the key `k`.\nfunc (r MultiRedBlack) Count(k KType) int {\n\th := r.find(k)\n\tif h == nil {\n\t\treturn 0\n\t}\n\treturn h.count\n}\n\n// Contains tells if `k` occurs in the sorted multiset.\nfunc (r MultiRedBlack) Contains(k KType) bool { return r.find(k) != nil }\n\nfunc (r MultiRedBlack) find(k KType) *multinode {\n\tfor h := r.root; h != nil; {\n\t\tcmp := r.compare(k, h.key)\n\t\tif cmp == 0 {\n\t\t\treturn h\n\t\t} else if cmp < 0 {\n\t\t\th = h.left\n\t\t} else {\n\t\t\th = h.right\n\t\t}\n\t}\n\treturn nil\n}\n\n// Min returns the smallest key in the sorted multiset, if it exists.\nfunc (r MultiRedBlack) Min() (k KType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\th := r.root\n\tfor h.left != nil {\n\t\th = h.left\n\t}\n\treturn h.key, true\n}\n\n// Max returns the largest key in the sorted multiset, if it exists.\nfunc (r MultiRedBlack) Max() (k KType, ok bool) {\n\tif r.root == nil {\n\t\treturn\n\t}\n\th := r.root\n\tfor h.right != nil {\n\t\th = h.right\n\t}\n\treturn h.key, true\n}\n\n// Select the key of rank `i`, meaning the key of the i-th smallest\n// occurrence in the sorted multiset.\nfunc (r MultiRedBlack) Select(i int) (k KType, ok bool) {\n\tfor h := r.root; h != nil; {\n\t\tt := h.left.size()\n\t\tif i < t {\n\t\t\th = h.left\n\t\t} else if i < t+h.count {\n\t\t\treturn h.key, true\n\t\t} else {\n\t\t\th, i = h.right, i-t-h.count\n\t\t}\n\t}\n\treturn\n}\n\n// Rank is the number of occurrences of keys less than `k`.\nfunc (r MultiRedBlack) Rank(k KType) int {\n\trank := 0\n\tfor h := r.root; h != nil; {\n\t\tcmp := r.compare(k, h.key)\n\t\tif cmp < 0 {\n\t\t\th = h.left\n\t\t} else if cmp > 0 {\n\t\t\trank += h.left.size() + h.count\n\t\t\th = h.right\n\t\t} else {\n\t\t\treturn rank + h.left.size()\n\t\t}\n\t}\n\treturn rank\n}\n\n// Keys visit each key in the sorted multiset, in order, with its number of\n// occurrences. It stops when visit returns false.\nfunc (r MultiRedBlack) Keys(visit func(k KType, count int) bool) {\n\tmin, ok := r.Min()\n\tif !ok {\n\t\treturn\n\t}\n\t// if the min exists, then the max must exist\n\tmax, _ := r.Max()\n\tr.RangedKeys(min, max, visit)\n}\n\n// RangedKeys visit each key between lo and hi in the sorted multiset, in\n// order, with its number of occurrences. It stops when visit returns false.\nfunc (r MultiRedBlack) RangedKeys(lo, hi KType, visit func(k KType, count int) bool) {\n\tr.keys(r.root, visit, lo, hi)\n}\n\nfunc (r MultiRedBlack) keys(h *multinode, visit func(k KType, count int) bool, lo, hi KType) bool {\n\tif h == nil {\n\t\treturn true\n\t}\n\tcmplo := r.compare(lo, h.key)\n\tcmphi := r.compare(hi, h.key)\n\tif cmplo < 0 {\n\t\tif !r.keys(h.left, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmplo <= 0 && cmphi >= 0 {\n\t\tif !visit(h.key, h.count) {\n\t\t\treturn false\n\t\t}\n\t}\n\tif cmphi > 0 {\n\t\tif !r.keys(h.right, visit, lo, hi) {\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\n// deletions\n\n// Remove `n` occurrences of the key `k` from the sorted multiset, or all of\n// them if there are fewer, and return how many are left. The key is removed\n// along with its last occurrence. It panics if `n` is negative.\nfunc (r *MultiRedBlack) Remove(k KType, n int) (count int) {\n\tif n < 0 {\n\t\tpanic(\"redblackbst: can't remove a negative number of occurrences\")\n\t}\n\th := r.find(k)\n\tif h == nil || n == 0 {\n\t\treturn r.Count(k)\n\t}\n\tif n >= h.count {\n\t\tr.root = r.delete(r.root, k)\n\t\tif !r.IsEmpty() {\n\t\t\tr.root.colorRed = false\n\t\t}\n\t\tr.distinct--\n\t\treturn 0\n\t}\n\th.count -= n\n\t// n less occurrences under the nodes on the path to h\n\tfor x := r.root; x != h; {\n\t\tx.n -= n\n\t\tif r.compare(k, x.key) < 0 {\n\t\t\tx = x.left\n\t\t} else {\n\t\t\tx = x.right\n\t\t}\n\t}\n\th.n -= n\n\treturn h.count\n}\n\n// delete removes `k`, which is in the tree of h.\nfunc (r *MultiRedBlack) delete(h *multinode, k KType) *multinode {\n\tif r.compare(k, h.key) < 0 {\n\t\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\t\th = r.moveRedLeft(h)\n\t\t}\n\t\th.left = r.delete(h.left, k)\n\t\treturn r.balance(h)\n\t}\n\n\tif h.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\n\tif r.compare(k, h.key) == 0 && h.right == nil {\n\t\treturn nil\n\t}\n\n\tif !h.right.isRed() && !h.right.left.isRed() {\n\t\th = r.moveRedRight(h)\n\t}\n\n\tif r.compare(k, h.key) == 0 {\n\t\tvar min *multinode\n\t\th.right, min = r.deleteMin(h.right)\n\t\th.key, h.count = min.key, min.count\n\t} else {\n\t\th.right = r.delete(h.right, k)\n\t}\n\treturn r.balance(h)\n}\n\n// deleteMin removes the smallest key of the tree of h, which isn't empty,\n// and returns its node.\nfunc (r *MultiRedBlack) deleteMin(h *multinode) (_, min *multinode) {\n\tif h.left == nil {\n\t\treturn nil, h\n\t}\n\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\th = r.moveRedLeft(h)\n\t}\n\th.left, min = r.deleteMin(h.left)\n\treturn r.balance(h), min\n}\n\nfunc (r *MultiRedBlack) moveRedLeft(h *multinode) *multinode {\n\tr.flipColors(h)\n\tif h.right.left.isRed() {\n\t\th.right = r.rotateRight(h.right)\n\t\th = r.rotateLeft(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *MultiRedBlack) moveRedRight(h *multinode) *multinode {\n\tr.flipColors(h)\n\tif h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *MultiRedBlack) balance(h *multinode) *multinode {\n\tif h.right.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\th.n = h.left.size() + h.right.size() + h.count\n\treturn h\n}\n\nfunc (r *MultiRedBlack) rotateLeft(h *multinode) *multinode {\n\tx := h.right\n\th.right = x.left\n\tx.left = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tx.n = h.n\n\th.n = h.count + h.left.size() + h.right.size()\n\treturn x\n}\n\nfunc (r *MultiRedBlack) rotateRight(h *multinode) *multinode {\n\tx := h.left\n\th.left = x.right\n\tx.right = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tx.n = h.n\n\th.n = h.count + h.left.size() + h.right.size()\n\treturn x\n}\n\nfunc (r *MultiRedBlack) flipColors(h *multinode) {\n\th.colorRed = !h.colorRed\n\th.left.colorRed = !h.left.colorRed\n\th.right.colorRed = !h.right.colorRed\n}\n\n// nodes\n\ntype multinode struct {\n\tkey KType\n\t// count is the number of occurrences of the key, one at least\n\tcount       int\n\tleft, right *multinode\n\t// n is the number of occurrences of the keys of the tree of the node\n\tn        int\n\tcolorRed bool\n}\n\nfunc (x *multinode) isRed() bool { return (x != nil) && (x.colorRed == true) }\n\nfunc (x *multinode) size() int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\treturn x.n\n}\n"
+	multisetTestSrc        = "package redblackbst\n\nimport (\n\t\"math/rand\"\n\t\"sort\"\n\t\"testing\"\n)\n\n// The tests of this file are generated along with sorted multisets, when\n// asked to. The keys are generated by randomKType.\n\n// checkMultiRedBlack verifies the invariants of the tree: the keys are in\n// order, each occurs once at least, the sizes of the subtrees are right, red\n// links lean left, no node has two red links and every path from the root\n// to a leaf has as many black links.\nfunc checkMultiRedBlack(t *testing.T, r *MultiRedBlack) {\n\tif r.root.isRed() {\n\t\tt.Fatal(\"root is red\")\n\t}\n\tcheckMultiRedBlackNode(t, r, r.root)\n\n\tvar prev *KType\n\tdistinct := 0\n\tr.Keys(func(k KType, _ int) bool {\n\t\tif prev != nil && r.compare(*prev, k) >= 0 {\n\t\t\tt.Fatalf(\"keys out of order: %v before %v\", *prev, k)\n\t\t}\n\t\tprev = &k\n\t\tdistinct++\n\t\treturn true\n\t})\n\tif distinct != r.Distinct() {\n\t\tt.Fatalf(\"want %d distinct keys, got %d\", distinct, r.Distinct())\n\t}\n}\n\n// checkMultiRedBlackNode returns the number of black links from x to the\n// leaves.\nfunc checkMultiRedBlackNode(t *testing.T, r *MultiRedBlack, x *multinode) int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\tif x.count < 1 {\n\t\tt.Fatalf(\"%v occurs %d times\", x.key, x.count)\n\t}\n\tif x.right.isRed() {\n\t\tt.Fatalf(\"red link leans right under %v\", x.key)\n\t}\n\tif x.isRed() && x.left.isRed() {\n\t\tt.Fatalf(\"two red links in a row under %v\", x.key)\n\t}\n\tif want := x.count + x.left.size() + x.right.size(); x.n != want {\n\t\tt.Fatalf(\"size of %v: want %d, got %d\", x.key, want, x.n)\n\t}\n\tblack := checkMultiRedBlackNode(t, r, x.left)\n\tif right := checkMultiRedBlackNode(t, r, x.right); black != right {\n\t\tt.Fatalf(\"black links under %v: %d on the left, %d on the right\", x.key, black, right)\n\t}\n\tif !x.isRed() {\n\t\tblack++\n\t}\n\treturn black\n}\n\n// refMultiRedBlack is a naive sorted multiset keeping its keys in a sorted\n// slice, each with its count, ordered like r.\ntype refMultiRedBlack struct {\n\tr      *MultiRedBlack\n\tkeys   []KType\n\tcounts []int\n}\n\n// search returns the index of the first key larger or equal to k.\nfunc (ref *refMultiRedBlack) search(k KType) int {\n\treturn sort.Search(len(ref.keys), func(i int) bool { return ref.r.compare(ref.keys[i], k) >= 0 })\n}\n\nfunc (ref *refMultiRedBlack) has(k KType) (int, bool) {\n\ti := ref.search(k)\n\treturn i, i < len(ref.keys) && ref.r.compare(ref.keys[i], k) == 0\n}\n\nfunc (ref *refMultiRedBlack) add(k KType, n int) int {\n\ti, ok := ref.has(k)\n\tif ok {\n\t\tref.counts[i] += n\n\t\treturn ref.counts[i]\n\t}\n\tif n == 0 {\n\t\treturn 0\n\t}\n\tref.keys = append(ref.keys[:i], append([]KType{k}, ref.keys[i:]...)...)\n\tref.counts = append(ref.counts[:i], append([]int{n}, ref.counts[i:]...)...)\n\treturn n\n}\n\nfunc (ref *refMultiRedBlack) remove(k KType, n int) int {\n\ti, ok := ref.has(k)\n\tif !ok {\n\t\treturn 0\n\t}\n\tif ref.counts[i] > n {\n\t\tref.counts[i] -= n\n\t\treturn ref.counts[i]\n\t}\n\tref.keys = append(ref.keys[:i], ref.keys[i+1:]...)\n\tref.counts = append(ref.counts[:i], ref.counts[i+1:]...)\n\treturn 0\n}\n\n// occurrences returns the keys from index i, each as many times as it\n// occurs.\nfunc (ref *refMultiRedBlack) occurrences(i int) []KType {\n\tvar keys []KType\n\tfor ; i < len(ref.keys); i++ {\n\t\tfor j := 0; j < ref.counts[i]; j++ {\n\t\t\tkeys = append(keys, ref.keys[i])\n\t\t}\n\t}\n\treturn keys\n}\n\nfunc TestMultiRedBlackMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tr := NewMultiRedBlack()\n\tref := &refMultiRedBlack{r: r}\n\n\tfor n := 0; n < 5000; n++ {\n\t\tk := randomKType(rnd)\n\t\tif len(ref.keys) != 0 && rnd.Intn(2) == 0 {\n\t\t\tk = ref.keys[rnd.Intn(len(ref.keys))]\n\t\t}\n\t\tswitch op := rnd.Intn(8); op {\n\t\tcase 0, 1, 2:\n\t\t\ttimes := rnd.Intn(4)\n\t\t\tif want, got := ref.add(k, times), r.Add(k, times); want != got {\n\t\t\t\tt.Fatalf(\"add %d of %v: want %d, got %d\", times, k, want, got)\n\t\t\t}\n\t\tcase 3, 4:\n\t\t\ttimes := rnd.Intn(4)\n\t\t\tif want, got := ref.remove(k, times), r.Remove(k, times); want != got {\n\t\t\t\tt.Fatalf(\"remove %d of %v: want %d left, got %d\", times, k, want, got)\n\t\t\t}\n\t\tcase 5:\n\t\t\ti, ok := ref.has(k)\n\t\t\twant := 0\n\t\t\tif ok {\n\t\t\t\twant = ref.counts[i]\n\t\t\t}\n\t\t\tif got := r.Count(k); got != want || r.Contains(k) != ok {\n\t\t\t\tt.Fatalf(\"count of %v: want %d, %v, got %d, %v\", k, want, ok, got, r.Contains(k))\n\t\t\t}\n\t\t\tmk, minOK := r.Min()\n\t\t\txk, maxOK := r.Max()\n\t\t\tif minOK != (len(ref.keys) != 0) || maxOK != minOK {\n\t\t\t\tt.Fatalf(\"min and max: want ok=%v, got %v and %v\", len(ref.keys) != 0, minOK, maxOK)\n\t\t\t}\n\t\t\tif minOK && (r.compare(mk, ref.keys[0]) != 0 || r.compare(xk, ref.keys[len(ref.keys)-1]) != 0) {\n\t\t\t\tt.Fatalf(\"min and max: want %v and %v, got %v and %v\", ref.keys[0], ref.keys[len(ref.keys)-1], mk, xk)\n\t\t\t}\n\t\tcase 6:\n\t\t\tkeys := ref.occurrences(0)\n\t\t\ti := rnd.Intn(len(keys) + 2)\n\t\t\tsk, ok := r.Select(i)\n\t\t\tif ok != (i < len(keys)) || ok && r.compare(sk, keys[i]) != 0 {\n\t\t\t\tt.Fatalf(\"select %d of %d: want %v, got %v, %v\", i, len(keys), i < len(keys), sk, ok)\n\t\t\t}\n\t\t\tif want, got := len(keys)-len(ref.occurrences(ref.search(k))), r.Rank(k); want != got {\n\t\t\t\tt.Fatalf(\"rank %v: want %d, got %d\", k, want, got)\n\t\t\t}\n\t\tcase 7:\n\t\t\tlo, hi := k, randomKType(rnd)\n\t\t\ti := ref.search(lo)\n\t\t\tr.RangedKeys(lo, hi, func(k KType, count int) bool {\n\t\t\t\tif i == len(ref.keys) || r.compare(ref.keys[i], k) != 0 || ref.counts[i] != count {\n\t\t\t\t\tt.Fatalf(\"ranged keys [%v, %v]: got %v occurring %d times out of order\", lo, hi, k, count)\n\t\t\t\t}\n\t\t\t\ti++\n\t\t\t\treturn true\n\t\t\t})\n\t\t\tif i < len(ref.keys) && r.compare(ref.keys[i], hi) <= 0 {\n\t\t\t\tt.Fatalf(\"ranged keys [%v, %v]: missed %v\", lo, hi, ref.keys[i])\n\t\t\t}\n\t\t}\n\t\tif want, got := len(ref.occurrences(0)), r.Size(); want != got {\n\t\t\tt.Fatalf(\"want size %d, got %d\", want, got)\n\t\t}\n\t\tcheckMultiRedBlack(t, r)\n\t}\n}\n"
+	multisetBenchSrc       = "package redblackbst\n\nimport (\n\t\"math/rand\"\n\t\"strconv\"\n\t\"testing\"\n)\n\n// The benchmarks of this file are generated along with sorted multisets,\n// when asked to. The keys are generated by benchKType.\n\n// benchMultiRedBlackSizes are the numbers of occurrences in the benchmarked\n// multisets.\nvar benchMultiRedBlackSizes = []int{100, 10000, 1000000}\n\n// benchMultiRedBlack runs bench for each size, with a multiset holding that\n// many random occurrences, of half as many keys, and the keys added to it.\n// The keys are the same from one benchmark to the other.\nfunc benchMultiRedBlack(b *testing.B, bench func(b *testing.B, r *MultiRedBlack, keys []KType)) {\n\tfor _, n := range benchMultiRedBlackSizes {\n\t\tn := n\n\t\tvar r *MultiRedBlack\n\t\tvar keys []KType\n\t\tb.Run(strconv.Itoa(n), func(b *testing.B) {\n\t\t\t// once for all the runs of the benchmark, and only if it's run\n\t\t\tif r == nil {\n\t\t\t\trnd := rand.New(rand.NewSource(int64(n)))\n\t\t\t\tkeys = make([]KType, n/2)\n\t\t\t\tr = NewMultiRedBlack()\n\t\t\t\tfor i := range keys {\n\t\t\t\t\tkeys[i] = benchKType(rnd)\n\t\t\t\t\tr.Add(keys[i], 2)\n\t\t\t\t}\n\t\t\t}\n\t\t\tbench(b, r, keys)\n\t\t})\n\t}\n}\n\n// BenchmarkMultiRedBlackRemoveAdd removes an occurrence of a key and adds it\n// back, leaving as many occurrences in the multiset.\nfunc BenchmarkMultiRedBlackRemoveAdd(b *testing.B) {\n\tbenchMultiRedBlack(b, func(b *testing.B, r *MultiRedBlack, keys []KType) {\n\t\tn := len(keys)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Remove(keys[i%n], 1)\n\t\t\tr.Add(keys[i%n], 1)\n\t\t}\n\t})\n}\n\nfunc BenchmarkMultiRedBlackCount(b *testing.B) {\n\tbenchMultiRedBlack(b, func(b *testing.B, r *MultiRedBlack, keys []KType) {\n\t\tn := len(keys)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Count(keys[i%n])\n\t\t}\n\t})\n}\n\n// BenchmarkMultiRedBlackSelect selects the occurrences of each rank, such as\n// for percentiles.\nfunc BenchmarkMultiRedBlackSelect(b *testing.B) {\n\tbenchMultiRedBlack(b, func(b *testing.B, r *MultiRedBlack, keys []KType) {\n\t\tn := r.Size()\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Select(i % n)\n\t\t}\n\t})\n}\n"
+	intervalTreeSrc        = "package itree\n\nfunc (r IntervalTree) compare(a, b KType) int { return a.Compare(b) }\n\n// IntervalTree maps closed intervals of KType to VType values. It's built on\n// a left leaning red black balanced search tree, ordering the intervals by\n// their start, then by their end. Each node holds the largest end of the\n// intervals under it, so that the intervals overlapping another one, or\n// containing a point, are found in O(log n) for each of them. An interval\n// inserted several times keeps all its values, in the order they were\n// inserted, as a sorted multimap does: its entries are the interval/value\n// pairs, which sizes count.\ntype IntervalTree struct {\n\troot *intervalnode\n}\n\n// NewIntervalTree creates an interval tree.\nfunc NewIntervalTree() *IntervalTree { return &IntervalTree{} }\n\n// IsEmpty tells if the interval tree contains no interval.\nfunc (r IntervalTree) IsEmpty() bool {\n\treturn r.root == nil\n}\n\n// Size of the interval tree, counting each value of each interval.\nfunc (r IntervalTree) Size() int { return r.root.size() }\n\n// Clear all the intervals in the interval tree.\nfunc (r *IntervalTree) Clear() { r.root = nil }\n\n// Insert a value in the interval tree for the interval [lo, hi], after the\n// values already inserted for the same interval, which are kept. It panics\n// if hi is smaller than lo.\nfunc (r *IntervalTree) Insert(lo, hi KType, v VType) {\n\tif r.compare(hi, lo) < 0 {\n\t\tpanic(\"itree: interval ends before it starts\")\n\t}\n\tr.root = r.insert(r.root, lo, hi, v)\n\tr.root.colorRed = false\n}\n\nfunc (r *IntervalTree) insert(h *intervalnode, lo, hi KType, v VType) *intervalnode {\n\tif h == nil {\n\t\treturn &intervalnode{lo: lo, hi: hi, vals: []VType{v}, max: hi, n: 1, colorRed: true}\n\t}\n\n\tcmp := r.compareTo(h, lo, hi)\n\tif cmp < 0 {\n\t\th.left = r.insert(h.left, lo, hi, v)\n\t} else if cmp > 0 {\n\t\th.right = r.insert(h.right, lo, hi, v)\n\t} else {\n\t\th.vals = append(h.vals, v)\n\t}\n\n\tif h.right.isRed() && !h.left.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\tr.update(h)\n\treturn h\n}\n\n// GetAll returns the values of the interval [lo, hi] in the interval tree,\n// in the order they were inserted, or nil if the interval doesn't exist.\n// The slice returned is a copy.\nfunc (r IntervalTree) GetAll(lo, hi KType) []VType {\n\th := r.find(lo, hi)\n\tif h == nil {\n\t\treturn nil\n\t}\n\treturn append([]VType(nil), h.vals...)\n}\n\n// Count is the number of values of the interval [lo, hi].\nfunc (r IntervalTree) Count(lo, hi KType) int {\n\th := r.find(lo, hi)\n\tif h == nil {\n\t\treturn 0\n\t}\n\treturn len(h.vals)\n}\n\nfunc (r IntervalTree) find(lo, hi KType) *intervalnode {\n\tfor h := r.root; h != nil; {\n\t\tcmp := r.compareTo(h, lo, hi)\n\t\tif cmp == 0 {\n\t\t\treturn h\n\t\t} else if cmp < 0 {\n\t\t\th = h.left\n\t\t} else {\n\t\t\th = h.right\n\t\t}\n\t}\n\treturn nil\n}\n\n// Intervals visit each interval in the interval tree, in order, visiting an\n// interval once for each of its values. It stops when visit returns false.\nfunc (r IntervalTree) Intervals(visit func(lo, hi KType, v VType) bool) {\n\tr.intervals(r.root, visit)\n}\n\nfunc (r IntervalTree) intervals(h *intervalnode, visit func(lo, hi KType, v VType) bool) bool {\n\tif h == nil {\n\t\treturn true\n\t}\n\treturn r.intervals(h.left, visit) &&\n\t\tr.visitAll(h, visit) &&\n\t\tr.intervals(h.right, visit)\n}\n\n// visitAll visits the interval of h once for each of its values, in order.\nfunc (r IntervalTree) visitAll(h *intervalnode, visit func(lo, hi KType, v VType) bool) bool {\n\tfor _, v := range h.vals {\n\t\tif !visit(h.lo, h.hi, v) {\n\t\t\treturn false\n\t\t}\n\t}\n\treturn true\n}\n\n// Overlapping visits each interval of the interval tree that overlaps\n// [lo, hi], in order, meaning each interval that starts before or at hi and\n// ends at or after lo. It stops when visit returns false.\nfunc (r IntervalTree) Overlapping(lo, hi KType, visit func(lo, hi KType, v VType) bool) {\n\tr.overlapping(r.root, lo, hi, visit)\n}\n\n// Stabbing visits each interval of the interval tree that contains the point\n// p, in order. It stops when visit returns false.\nfunc (r IntervalTree) Stabbing(p KType, visit func(lo, hi KType, v VType) bool) {\n\tr.overlapping(r.root, p, p, visit)\n}\n\nfunc (r IntervalTree) overlapping(h *intervalnode, lo, hi KType, visit func(lo, hi KType, v VType) bool) bool {\n\t// none of the intervals under h ends at or after lo\n\tif h == nil || r.compare(lo, h.max) > 0 {\n\t\treturn true\n\t}\n\tif !r.overlapping(h.left, lo, hi, visit) {\n\t\treturn false\n\t}\n\t// h and the intervals on its right start after hi\n\tif r.compare(h.lo, hi) > 0 {\n\t\treturn true\n\t}\n\tif r.compare(lo, h.hi) <= 0 && !r.visitAll(h, visit) {\n\t\treturn false\n\t}\n\treturn r.overlapping(h.right, lo, hi, visit)\n}\n\n// deletions\n\n// Delete the interval [lo, hi] from the interval tree, with all its values,\n// if it exists. It tells if it did.\nfunc (r *IntervalTree) Delete(lo, hi KType) (ok bool) {\n\tif r.find(lo, hi) == nil {\n\t\treturn false\n\t}\n\tr.delete(lo, hi)\n\treturn true\n}\n\n// DeleteOne removes the first value inserted for the interval [lo, hi] from\n// the interval tree, if it exists. The interval is removed along with its\n// last value.\nfunc (r *IntervalTree) DeleteOne(lo, hi KType) (old VType, ok bool) {\n\th := r.find(lo, hi)\n\tif h == nil {\n\t\treturn\n\t}\n\tif len(h.vals) == 1 {\n\t\tvals := r.delete(lo, hi)\n\t\treturn vals[0], true\n\t}\n\told = h.vals[0]\n\t// not holding on to the value removed\n\tvar zero VType\n\th.vals[0] = zero\n\th.vals = h.vals[1:]\n\t// one less value under the nodes on the path to h\n\tfor x := r.root; x != h; {\n\t\tx.n--\n\t\tif r.compareTo(x, lo, hi) < 0 {\n\t\t\tx = x.left\n\t\t} else {\n\t\t\tx = x.right\n\t\t}\n\t}\n\th.n--\n\treturn old, true\n}\n\n// DeleteAll removes the interval [lo, hi] and its values from the interval\n// tree, and returns the values in the order they were inserted, if it\n// exists.\nfunc (r *IntervalTree) DeleteAll(lo, hi KType) (old []VType) {\n\tif r.find(lo, hi) == nil {\n\t\treturn nil\n\t}\n\treturn r.delete(lo, hi)\n}\n\n// delete removes [lo, hi], which is in the interval tree, and returns its\n// values.\nfunc (r *IntervalTree) delete(lo, hi KType) (old []VType) {\n\tr.root, old = r.deleteNode(r.root, lo, hi)\n\tif !r.IsEmpty() {\n\t\tr.root.colorRed = false\n\t}\n\treturn old\n}\n\nfunc (r *IntervalTree) deleteNode(h *intervalnode, lo, hi KType) (_ *intervalnode, old []VType) {\n\tif r.compareTo(h, lo, hi) < 0 {\n\t\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\t\th = r.moveRedLeft(h)\n\t\t}\n\t\th.left, old = r.deleteNode(h.left, lo, hi)\n\t\treturn r.balance(h), old\n\t}\n\n\tif h.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\n\tif r.compareTo(h, lo, hi) == 0 && h.right == nil {\n\t\treturn nil, h.vals\n\t}\n\n\tif !h.right.isRed() && !h.right.left.isRed() {\n\t\th = r.moveRedRight(h)\n\t}\n\n\tif r.compareTo(h, lo, hi) == 0 {\n\t\tvar min *intervalnode\n\t\th.right, min = r.deleteMin(h.right)\n\t\told, h.lo, h.hi, h.vals = h.vals, min.lo, min.hi, min.vals\n\t} else {\n\t\th.right, old = r.deleteNode(h.right, lo, hi)\n\t}\n\treturn r.balance(h), old\n}\n\n// deleteMin removes the smallest interval of the tree of h, which isn't\n// empty, and returns its node.\nfunc (r *IntervalTree) deleteMin(h *intervalnode) (_, min *intervalnode) {\n\tif h.left == nil {\n\t\treturn nil, h\n\t}\n\tif !h.left.isRed() && !h.left.left.isRed() {\n\t\th = r.moveRedLeft(h)\n\t}\n\th.left, min = r.deleteMin(h.left)\n\treturn r.balance(h), min\n}\n\n// compareTo compares the interval [lo, hi] to the one of h.\nfunc (r IntervalTree) compareTo(h *intervalnode, lo, hi KType) int {\n\tif cmp := r.compare(lo, h.lo); cmp != 0 {\n\t\treturn cmp\n\t}\n\treturn r.compare(hi, h.hi)\n}\n\n// The rotations and balancing below keep the sizes and the largest ends of\n// the nodes they move up to date.\n\nfunc (r *IntervalTree) moveRedLeft(h *intervalnode) *intervalnode {\n\tr.flipColors(h)\n\tif h.right.left.isRed() {\n\t\th.right = r.rotateRight(h.right)\n\t\th = r.rotateLeft(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *IntervalTree) moveRedRight(h *intervalnode) *intervalnode {\n\tr.flipColors(h)\n\tif h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t\tr.flipColors(h)\n\t}\n\treturn h\n}\n\nfunc (r *IntervalTree) balance(h *intervalnode) *intervalnode {\n\tif h.right.isRed() {\n\t\th = r.rotateLeft(h)\n\t}\n\tif h.left.isRed() && h.left.left.isRed() {\n\t\th = r.rotateRight(h)\n\t}\n\tif h.left.isRed() && h.right.isRed() {\n\t\tr.flipColors(h)\n\t}\n\tr.update(h)\n\treturn h\n}\n\n// The node moved up by a rotation holds the same intervals as the node it\n// replaces, so it takes its size and largest end.\n\nfunc (r *IntervalTree) rotateLeft(h *intervalnode) *intervalnode {\n\tx := h.right\n\th.right = x.left\n\tx.left = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tx.n, x.max = h.n, h.max\n\tr.update(h)\n\treturn x\n}\n\nfunc (r *IntervalTree) rotateRight(h *intervalnode) *intervalnode {\n\tx := h.left\n\th.left = x.right\n\tx.right = h\n\tx.colorRed = h.colorRed\n\th.colorRed = true\n\tx.n, x.max = h.n, h.max\n\tr.update(h)\n\treturn x\n}\n\nfunc (r *IntervalTree) flipColors(h *intervalnode) {\n\th.colorRed = !h.colorRed\n\th.left.colorRed = !h.left.colorRed\n\th.right.colorRed = !h.right.colorRed\n}\n\n// update the size and the largest end of h, out of those of its children.\nfunc (r *IntervalTree) update(h *intervalnode) {\n\th.n = len(h.vals) + h.left.size() + h.right.size()\n\th.max = h.hi\n\tif h.left != nil && r.compare(h.left.max, h.max) > 0 {\n\t\th.max = h.left.max\n\t}\n\tif h.right != nil && r.compare(h.right.max, h.max) > 0 {\n\t\th.max = h.right.max\n\t}\n}\n\n// nodes\n\ntype intervalnode struct {\n\tlo, hi KType\n\t// vals are the values of the interval, in the order they were inserted\n\tvals        []VType\n\tleft, right *intervalnode\n\t// n is the number of values of the tree of the node\n\tn        int\n\tcolorRed bool\n\t// max is the largest end of the intervals of the tree of the node\n\tmax KType\n}\n\nfunc (x *intervalnode) isRed() bool { return (x != nil) && (x.colorRed == true) }\n\nfunc (x *intervalnode) size() int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\treturn x.n\n}\n"
+	intervalTreeTestSrc    = "package itree\n\nimport (\n\t\"fmt\"\n\t\"math/rand\"\n\t\"reflect\"\n\t\"sort\"\n\t\"testing\"\n)\n\n// The tests of this file are generated along with interval trees, when asked\n// to. The ends of the intervals are generated by randomKType, and their\n// values by randomVType.\n\n// checkIntervalTree verifies the invariants of the tree: the intervals are\n// in order, the sizes and largest ends of the subtrees are right, red links\n// lean left, no node has two red links and every path from the root to a\n// leaf has as many black links.\nfunc checkIntervalTree(t *testing.T, r *IntervalTree) {\n\tif r.root.isRed() {\n\t\tt.Fatal(\"root is red\")\n\t}\n\tcheckIntervalTreeNode(t, r, r.root)\n\n\tvar prev *intervalnode\n\tfor _, x := range nodesOfIntervalTree(r.root, nil) {\n\t\tif prev != nil && r.compareTo(x, prev.lo, prev.hi) >= 0 {\n\t\t\tt.Fatalf(\"intervals out of order: [%v, %v] before [%v, %v]\", prev.lo, prev.hi, x.lo, x.hi)\n\t\t}\n\t\tprev = x\n\t}\n}\n\n// checkIntervalTreeNode returns the number of black links from x to the\n// leaves.\nfunc checkIntervalTreeNode(t *testing.T, r *IntervalTree, x *intervalnode) int {\n\tif x == nil {\n\t\treturn 0\n\t}\n\tif x.right.isRed() {\n\t\tt.Fatalf(\"red link leans right under [%v, %v]\", x.lo, x.hi)\n\t}\n\tif x.isRed() && x.left.isRed() {\n\t\tt.Fatalf(\"two red links in a row under [%v, %v]\", x.lo, x.hi)\n\t}\n\tif len(x.vals) == 0 {\n\t\tt.Fatalf(\"no values for [%v, %v]\", x.lo, x.hi)\n\t}\n\tif want := len(x.vals) + x.left.size() + x.right.size(); x.n != want {\n\t\tt.Fatalf(\"size of [%v, %v]: want %d, got %d\", x.lo, x.hi, want, x.n)\n\t}\n\tmax := x.hi\n\tfor _, child := range []*intervalnode{x.left, x.right} {\n\t\tif child != nil && r.compare(child.max, max) > 0 {\n\t\t\tmax = child.max\n\t\t}\n\t}\n\tif r.compare(x.max, max) != 0 {\n\t\tt.Fatalf(\"largest end under [%v, %v]: want %v, got %v\", x.lo, x.hi, max, x.max)\n\t}\n\tblack := checkIntervalTreeNode(t, r, x.left)\n\tif right := checkIntervalTreeNode(t, r, x.right); black != right {\n\t\tt.Fatalf(\"black links under [%v, %v]: %d on the left, %d on the right\", x.lo, x.hi, black, right)\n\t}\n\tif !x.isRed() {\n\t\tblack++\n\t}\n\treturn black\n}\n\n// nodesOfIntervalTree appends the nodes of the tree of x to nodes, in order.\nfunc nodesOfIntervalTree(x *intervalnode, nodes []*intervalnode) []*intervalnode {\n\tif x == nil {\n\t\treturn nodes\n\t}\n\tnodes = nodesOfIntervalTree(x.left, nodes)\n\tnodes = append(nodes, x)\n\treturn nodesOfIntervalTree(x.right, nodes)\n}\n\n// refIntervalTreeEntry is an interval of refIntervalTree, with its value.\ntype refIntervalTreeEntry struct {\n\tlo, hi KType\n\tval    VType\n}\n\nfunc (e refIntervalTreeEntry) String() string { return fmt.Sprintf(\"[%v, %v]=%v\", e.lo, e.hi, e.val) }\n\n// refIntervalTree is a naive interval tree keeping its intervals and their\n// values in a slice, in the order they were inserted, and scanning all of\n// them for each search.\ntype refIntervalTree struct {\n\tr       *IntervalTree\n\tentries []refIntervalTreeEntry\n}\n\n// index returns the index of the first entry of [lo, hi], or -1.\nfunc (ref *refIntervalTree) index(lo, hi KType) int {\n\tfor i, e := range ref.entries {\n\t\tif ref.r.compare(e.lo, lo) == 0 && ref.r.compare(e.hi, hi) == 0 {\n\t\t\treturn i\n\t\t}\n\t}\n\treturn -1\n}\n\n// values returns the values of [lo, hi], in the order they were inserted.\nfunc (ref *refIntervalTree) values(lo, hi KType) []VType {\n\tvar vals []VType\n\tfor _, e := range ref.entries {\n\t\tif ref.r.compare(e.lo, lo) == 0 && ref.r.compare(e.hi, hi) == 0 {\n\t\t\tvals = append(vals, e.val)\n\t\t}\n\t}\n\treturn vals\n}\n\n// overlapping returns the entries overlapping [lo, hi], in the order of the\n// interval tree.\nfunc (ref *refIntervalTree) overlapping(lo, hi KType) []refIntervalTreeEntry {\n\tvar found []refIntervalTreeEntry\n\tfor _, e := range ref.entries {\n\t\tif ref.r.compare(e.lo, hi) > 0 || ref.r.compare(e.hi, lo) < 0 {\n\t\t\tcontinue\n\t\t}\n\t\tfound = append(found, e)\n\t}\n\t// the values of an interval stay in the order they were inserted\n\tsort.SliceStable(found, func(i, j int) bool {\n\t\tif cmp := ref.r.compare(found[i].lo, found[j].lo); cmp != 0 {\n\t\t\treturn cmp < 0\n\t\t}\n\t\treturn ref.r.compare(found[i].hi, found[j].hi) < 0\n\t})\n\treturn found\n}\n\n// collectIntervalTree returns the entries visited by visit.\nfunc collectIntervalTree(visit func(func(lo, hi KType, v VType) bool)) []refIntervalTreeEntry {\n\tvar got []refIntervalTreeEntry\n\tvisit(func(lo, hi KType, v VType) bool {\n\t\tgot = append(got, refIntervalTreeEntry{lo: lo, hi: hi, val: v})\n\t\treturn true\n\t})\n\treturn got\n}\n\n// randomIntervalTreeInterval returns an interval whose ends are random, in\n// order.\nfunc randomIntervalTreeInterval(r *IntervalTree, rnd *rand.Rand) (lo, hi KType) {\n\tlo, hi = randomKType(rnd), randomKType(rnd)\n\tif r.compare(lo, hi) > 0 {\n\t\tlo, hi = hi, lo\n\t}\n\treturn lo, hi\n}\n\nfunc TestIntervalTreeMatchesBruteForce(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tr := NewIntervalTree()\n\tref := &refIntervalTree{r: r}\n\n\tcheckFound := func(op string, want, got []refIntervalTreeEntry) {\n\t\tt.Helper()\n\t\tif len(want) == 0 && len(got) == 0 {\n\t\t\treturn\n\t\t}\n\t\tif !reflect.DeepEqual(want, got) {\n\t\t\tt.Fatalf(\"%s:\\nwant %v\\n got %v\", op, want, got)\n\t\t}\n\t}\n\n\tfor n := 0; n < 5000; n++ {\n\t\tlo, hi := randomIntervalTreeInterval(r, rnd)\n\t\t// deleting the intervals inserted, since random ones rarely are\n\t\tif len(ref.entries) != 0 && rnd.Intn(4) == 0 {\n\t\t\te := ref.entries[rnd.Intn(len(ref.entries))]\n\t\t\tlo, hi = e.lo, e.hi\n\t\t}\n\t\tswitch op := rnd.Intn(8); op {\n\t\tcase 0, 1, 2:\n\t\t\tv := randomVType(rnd)\n\t\t\tref.entries = append(ref.entries, refIntervalTreeEntry{lo: lo, hi: hi, val: v})\n\t\t\tr.Insert(lo, hi, v)\n\t\tcase 3:\n\t\t\twant := ref.values(lo, hi)\n\t\t\tif got := r.GetAll(lo, hi); !reflect.DeepEqual(want, got) {\n\t\t\t\tt.Fatalf(\"get all [%v, %v]: want %v, got %v\", lo, hi, want, got)\n\t\t\t}\n\t\t\tif got := r.Count(lo, hi); got != len(want) {\n\t\t\t\tt.Fatalf(\"count [%v, %v]: want %d, got %d\", lo, hi, len(want), got)\n\t\t\t}\n\t\tcase 4:\n\t\t\ti := ref.index(lo, hi)\n\t\t\tvar want VType\n\t\t\tif i >= 0 {\n\t\t\t\twant = ref.entries[i].val\n\t\t\t\tref.entries = append(ref.entries[:i], ref.entries[i+1:]...)\n\t\t\t}\n\t\t\told, ok := r.DeleteOne(lo, hi)\n\t\t\tif ok != (i >= 0) || !reflect.DeepEqual(old, want) {\n\t\t\t\tt.Fatalf(\"delete one [%v, %v]: want %v, %v, got %v, %v\", lo, hi, want, i >= 0, old, ok)\n\t\t\t}\n\t\tcase 5:\n\t\t\twant := ref.values(lo, hi)\n\t\t\tfor i := ref.index(lo, hi); i >= 0; i = ref.index(lo, hi) {\n\t\t\t\tref.entries = append(ref.entries[:i], ref.entries[i+1:]...)\n\t\t\t}\n\t\t\tif rnd.Intn(2) == 0 {\n\t\t\t\tif ok := r.Delete(lo, hi); ok != (want != nil) {\n\t\t\t\t\tt.Fatalf(\"delete [%v, %v]: want %v, got %v\", lo, hi, want != nil, ok)\n\t\t\t\t}\n\t\t\t} else if old := r.DeleteAll(lo, hi); !reflect.DeepEqual(want, old) {\n\t\t\t\tt.Fatalf(\"delete all [%v, %v]: want %v, got %v\", lo, hi, want, old)\n\t\t\t}\n\t\tcase 6:\n\t\t\tgot := collectIntervalTree(func(visit func(lo, hi KType, v VType) bool) {\n\t\t\t\tr.Overlapping(lo, hi, visit)\n\t\t\t})\n\t\t\tcheckFound(fmt.Sprintf(\"overlapping [%v, %v]\", lo, hi), ref.overlapping(lo, hi), got)\n\t\tcase 7:\n\t\t\tp := randomKType(rnd)\n\t\t\tgot := collectIntervalTree(func(visit func(lo, hi KType, v VType) bool) {\n\t\t\t\tr.Stabbing(p, visit)\n\t\t\t})\n\t\t\tcheckFound(fmt.Sprintf(\"stabbing %v\", p), ref.overlapping(p, p), got)\n\t\t}\n\t\tif want, got := len(ref.entries), r.Size(); want != got {\n\t\t\tt.Fatalf(\"want size %d, got %d\", want, got)\n\t\t}\n\t\tcheckIntervalTree(t, r)\n\t}\n\n\tif len(ref.entries) == 0 {\n\t\tt.Fatal(\"no intervals left to compare\")\n\t}\n\tmin, max := ref.entries[0].lo, ref.entries[0].hi\n\tfor _, e := range ref.entries {\n\t\tif r.compare(e.lo, min) < 0 {\n\t\t\tmin = e.lo\n\t\t}\n\t\tif r.compare(e.hi, max) > 0 {\n\t\t\tmax = e.hi\n\t\t}\n\t}\n\tcheckFound(\"intervals\", ref.overlapping(min, max), collectIntervalTree(r.Intervals))\n}\n"
+	intervalTreeBenchSrc   = "package itree\n\nimport (\n\t\"math/rand\"\n\t\"strconv\"\n\t\"testing\"\n)\n\n// The benchmarks of this file are generated along with interval trees, when\n// asked to. The ends of the intervals are generated by benchKType, and their\n// values by benchVType.\n\n// benchIntervalTreeSizes are the numbers of intervals in the benchmarked\n// trees.\nvar benchIntervalTreeSizes = []int{100, 10000, 1000000}\n\n// benchIntervalTree runs bench for each size, with a tree holding that many\n// random intervals, and the intervals and values inserted in it. The\n// intervals are the same from one benchmark to the other.\nfunc benchIntervalTree(b *testing.B, bench func(b *testing.B, r *IntervalTree, los, his []KType, vals []VType)) {\n\tfor _, n := range benchIntervalTreeSizes {\n\t\tn := n\n\t\tvar r *IntervalTree\n\t\tvar los, his []KType\n\t\tvar vals []VType\n\t\tb.Run(strconv.Itoa(n), func(b *testing.B) {\n\t\t\t// once for all the runs of the benchmark, and only if it's run\n\t\t\tif r == nil {\n\t\t\t\trnd := rand.New(rand.NewSource(int64(n)))\n\t\t\t\tr = NewIntervalTree()\n\t\t\t\tlos, his = make([]KType, n), make([]KType, n)\n\t\t\t\tvals = make([]VType, n)\n\t\t\t\tfor i := range los {\n\t\t\t\t\tlos[i], his[i], vals[i] = benchKType(rnd), benchKType(rnd), benchVType(rnd)\n\t\t\t\t\tif r.compare(los[i], his[i]) > 0 {\n\t\t\t\t\t\tlos[i], his[i] = his[i], los[i]\n\t\t\t\t\t}\n\t\t\t\t\tr.Insert(los[i], his[i], vals[i])\n\t\t\t\t}\n\t\t\t}\n\t\t\tbench(b, r, los, his, vals)\n\t\t})\n\t}\n}\n\n// BenchmarkIntervalTreeInsert inserts the intervals again, then deletes the\n// values inserted, so that the tree doesn't grow.\nfunc BenchmarkIntervalTreeInsert(b *testing.B) {\n\tbenchIntervalTree(b, func(b *testing.B, r *IntervalTree, los, his []KType, vals []VType) {\n\t\tn := len(los)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Insert(los[i%n], his[i%n], vals[i%n])\n\t\t\tr.DeleteOne(los[i%n], his[i%n])\n\t\t}\n\t})\n}\n\n// BenchmarkIntervalTreeStabbing finds the intervals containing the start of\n// each interval, which the random intervals make many.\nfunc BenchmarkIntervalTreeStabbing(b *testing.B) {\n\tbenchIntervalTree(b, func(b *testing.B, r *IntervalTree, los, his []KType, vals []VType) {\n\t\tn := len(los)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Stabbing(los[i%n], func(lo, hi KType, v VType) bool { return true })\n\t\t}\n\t})\n}\n\n// BenchmarkIntervalTreeFirstOverlapping stops at the first interval\n// overlapping each interval.\nfunc BenchmarkIntervalTreeFirstOverlapping(b *testing.B) {\n\tbenchIntervalTree(b, func(b *testing.B, r *IntervalTree, los, his []KType, vals []VType) {\n\t\tn := len(los)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tr.Overlapping(los[i%n], his[i%n], func(lo, hi KType, v VType) bool { return false })\n\t\t}\n\t})\n}\n"
+	heapBenchSrc           = "package heap\n\nimport (\n\tstdheap \"container/heap\"\n\t\"math/rand\"\n\t\"strconv\"\n\t\"testing\"\n)\n\n// The benchmarks of this file are generated along with heaps, when asked\n// to. The keys are generated by benchKType.\n\n// benchHeapSizes are the numbers of keys in the benchmarked heaps.\nvar benchHeapSizes = []int{100, 10000, 1000000}\n\n// benchHeap runs bench for each size, with that many random keys. The keys\n// are the same from one benchmark to the other.\nfunc benchHeap(b *testing.B, bench func(b *testing.B, keys []KType)) {\n\tfor _, n := range benchHeapSizes {\n\t\tn := n\n\t\tvar keys []KType\n\t\tb.Run(strconv.Itoa(n), func(b *testing.B) {\n\t\t\t// once for all the runs of the benchmark, and only if it's run\n\t\t\tif keys == nil {\n\t\t\t\trnd := rand.New(rand.NewSource(int64(n)))\n\t\t\t\tkeys = make([]KType, n)\n\t\t\t\tfor i := range keys {\n\t\t\t\t\tkeys[i] = benchKType(rnd)\n\t\t\t\t}\n\t\t\t}\n\t\t\tb.ResetTimer()\n\t\t\tbench(b, keys)\n\t\t})\n\t}\n}\n\nfunc BenchmarkHeapNew(b *testing.B) {\n\tbenchHeap(b, func(b *testing.B, keys []KType) {\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tNewHeap(keys...)\n\t\t}\n\t})\n}\n\nfunc BenchmarkHeapPush(b *testing.B) {\n\tbenchHeap(b, func(b *testing.B, keys []KType) {\n\t\tn := len(keys)\n\t\th := NewHeap(keys...)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\th.Push(keys[i%n])\n\t\t\tif h.n == 2*n {\n\t\t\t\t// the first n keys of a heap are a heap\n\t\t\t\th.pq = h.pq[:n+1]\n\t\t\t\th.n = n\n\t\t\t}\n\t\t}\n\t})\n}\n\nfunc BenchmarkHeapPeek(b *testing.B) {\n\tbenchHeap(b, func(b *testing.B, keys []KType) {\n\t\th := NewHeap(keys...)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\th.Peek()\n\t\t}\n\t})\n}\n\nfunc BenchmarkHeapPop(b *testing.B) {\n\tbenchHeap(b, func(b *testing.B, keys []KType) {\n\t\th := NewHeap(keys...)\n\t\tfull := append([]KType(nil), h.pq...)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\th.Pop()\n\t\t\tif h.n == 0 {\n\t\t\t\th.pq = append(h.pq[:0], full...)\n\t\t\t\th.n = len(keys)\n\t\t\t}\n\t\t}\n\t})\n}\n\nfunc BenchmarkHeapRemove(b *testing.B) {\n\tbenchHeap(b, func(b *testing.B, keys []KType) {\n\t\tn := len(keys)\n\t\th := NewHeap(keys...)\n\t\tfull := append([]KType(nil), h.pq...)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\th.Remove(keys[i%n])\n\t\t\tif h.n == 0 {\n\t\t\t\th.pq = append(h.pq[:0], full...)\n\t\t\t\th.n = n\n\t\t\t}\n\t\t}\n\t})\n}\n\nfunc BenchmarkHeapFix(b *testing.B) {\n\tbenchHeap(b, func(b *testing.B, keys []KType) {\n\t\th := NewHeap(keys...)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\th.Fix()\n\t\t}\n\t})\n}\n\n// BenchmarkHeapPushPop is to be compared with\n// BenchmarkHeapPushPopContainer.\nfunc BenchmarkHeapPushPop(b *testing.B) {\n\tbenchHeap(b, func(b *testing.B, keys []KType) {\n\t\tn := len(keys)\n\t\th := NewHeap(keys...)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\th.Push(keys[i%n])\n\t\t\th.Pop()\n\t\t}\n\t})\n}\n\n// containerHeap is a container/heap holding the keys as interface{},\n// ordered like Heap.\ntype containerHeap struct {\n\th    *Heap\n\tkeys []interface{}\n}\n\nfunc (c *containerHeap) Len() int { return len(c.keys) }\nfunc (c *containerHeap) Less(i, j int) bool {\n\treturn c.h.before(c.keys[i].(KType), c.keys[j].(KType))\n}\nfunc (c *containerHeap) Swap(i, j int)      { c.keys[i], c.keys[j] = c.keys[j], c.keys[i] }\nfunc (c *containerHeap) Push(x interface{}) { c.keys = append(c.keys, x) }\nfunc (c *containerHeap) Pop() interface{} {\n\tx := c.keys[len(c.keys)-1]\n\tc.keys = c.keys[:len(c.keys)-1]\n\treturn x\n}\n\nfunc BenchmarkHeapPushPopContainer(b *testing.B) {\n\tbenchHeap(b, func(b *testing.B, keys []KType) {\n\t\tn := len(keys)\n\t\tc := &containerHeap{h: NewHeap()}\n\t\tfor _, k := range keys {\n\t\t\tc.keys = append(c.keys, k)\n\t\t}\n\t\tstdheap.Init(c)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tstdheap.Push(c, keys[i%n])\n\t\t\tstdheap.Pop(c)\n\t\t}\n\t})\n}\n"
+	queueBenchSrc          = "package queue\n\nimport (\n\t\"container/list\"\n\t\"math/rand\"\n\t\"strconv\"\n\t\"testing\"\n)\n\n// The benchmarks of this file are generated along with queues, when asked\n// to. The elements are generated by benchKType.\n\n// benchQueueSizes are the numbers of elements in the benchmarked queues.\nvar benchQueueSizes = []int{100, 10000, 1000000}\n\n// benchQueue runs bench for each size, with that many random elements. The\n// elements are the same from one benchmark to the other.\nfunc benchQueue(b *testing.B, bench func(b *testing.B, elems []KType)) {\n\tfor _, n := range benchQueueSizes {\n\t\tn := n\n\t\tvar elems []KType\n\t\tb.Run(strconv.Itoa(n), func(b *testing.B) {\n\t\t\t// once for all the runs of the benchmark, and only if it's run\n\t\t\tif elems == nil {\n\t\t\t\trnd := rand.New(rand.NewSource(int64(n)))\n\t\t\t\telems = make([]KType, n)\n\t\t\t\tfor i := range elems {\n\t\t\t\t\telems[i] = benchKType(rnd)\n\t\t\t\t}\n\t\t\t}\n\t\t\tb.ResetTimer()\n\t\t\tbench(b, elems)\n\t\t})\n\t}\n}\n\n// fillQueue returns a queue of capacity c holding elems.\nfunc fillQueue(c int, elems []KType) *Queue {\n\tq := NewQueue(c)\n\tfor _, e := range elems {\n\t\tq.Push(e)\n\t}\n\treturn q\n}\n\nfunc BenchmarkQueuePush(b *testing.B) {\n\tbenchQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := fillQueue(2*n, elems)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.Push(elems[i%n])\n\t\t\tif q.count == 2*n {\n\t\t\t\t// drop the last n elements, without resizing\n\t\t\t\tq.count = n\n\t\t\t\tq.tail = (q.head + n) % len(q.buf)\n\t\t\t}\n\t\t}\n\t})\n}\n\nfunc BenchmarkQueuePeek(b *testing.B) {\n\tbenchQueue(b, func(b *testing.B, elems []KType) {\n\t\tq := fillQueue(len(elems), elems)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.Peek()\n\t\t}\n\t})\n}\n\nfunc BenchmarkQueueGetAt(b *testing.B) {\n\tbenchQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := fillQueue(n, elems)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.Get(i % n)\n\t\t}\n\t})\n}\n\nfunc BenchmarkQueuePop(b *testing.B) {\n\tbenchQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\t// a full queue at its minimum capacity doesn't resize when popped\n\t\tq := fillQueue(n, elems)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.Pop()\n\t\t\tif q.count == 0 {\n\t\t\t\tcopy(q.buf, elems)\n\t\t\t\tq.head, q.tail, q.count = 0, n%len(q.buf), n\n\t\t\t}\n\t\t}\n\t})\n}\n\n// BenchmarkQueuePushPop is to be compared with\n// BenchmarkQueuePushPopContainer.\nfunc BenchmarkQueuePushPop(b *testing.B) {\n\tbenchQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := fillQueue(n, elems)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.Push(elems[i%n])\n\t\t\tq.Pop()\n\t\t}\n\t})\n}\n\n// BenchmarkQueuePushPopContainer queues the elements as interface{} in a\n// container/list.\nfunc BenchmarkQueuePushPopContainer(b *testing.B) {\n\tbenchQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tl := list.New()\n\t\tfor _, e := range elems {\n\t\t\tl.PushBack(e)\n\t\t}\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tl.PushBack(elems[i%n])\n\t\t\t_ = l.Remove(l.Front()).(KType)\n\t\t}\n\t})\n}\n"
+	heapSrc                = "package heap\n\n// Most of the implementation is adapted from Algorithms 4ed by Sedgewick\n// and Wayne.\n\n// Comments are adapted from `container/heap`.\n// \t Copyright 2009 The Go Authors. All rights reserved.\n// \t Use of this source code is governed by a BSD-style\n// \t license that can be found in the LICENSE file.\n\nfunc (h Heap) compare(a, b KType) int { return a.Compare(b) }\n\n// before tells if a comes out of the heap before b: this is a max-heap.\nfunc (h Heap) before(a, b KType) bool { return h.compare(a, b) > 0 }\n\n// Heap is a max-heap of KType, where the elements can be efficiently\n// retrieved in their decreasing order (according to their comparison\n// rules).\ntype Heap struct {\n\tn  int\n\tpq []KType\n}\n\n// NewHeap creates a heap, optionaly with keys already populating\n// it. The complexity is O(n) where n = len(keys).\nfunc NewHeap(keys ...KType) *Heap {\n\th := &Heap{\n\t\tn:  len(keys),\n\t\tpq: append(make([]KType, 1), keys...),\n\t}\n\th.Fix()\n\treturn h\n}\n\n// Len is the number of elements stored in the heap.\nfunc (h *Heap) Len() int { return h.n }\n\n// Peek at the largest element (according to their comparison rules), without\n// removing it from the heap. This call panics if the heap is empty.\nfunc (h *Heap) Peek() KType {\n\tif h.n == 0 {\n\t\tpanic(\"heap: empty heap\")\n\t}\n\treturn h.pq[1]\n}\n\n// TryPeek is like Peek, but tells if there was an element instead of\n// panicking on an empty heap.\nfunc (h *Heap) TryPeek() (k KType, ok bool) {\n\tif h.n == 0 {\n\t\treturn k, false\n\t}\n\treturn h.pq[1], true\n}\n\n// Fix re-establishes the heap ordering. This is useful if elements\n// of the heap have had their comparison value changed. It is equivalent to,\n// but less expenasive than, Pop'ing all the elements and Push'ing them\n// again.\n// The complexity is O(n).\nfunc (h *Heap) Fix() {\n\tfor i := (h.n) / 2; i > 0; i-- {\n\t\th.sink(i, h.n)\n\t}\n}\n\n// Push pushes the element k onto the heap. The complexity is\n// O(log(n)) where n == h.Len().\nfunc (h *Heap) Push(k KType) {\n\th.n++\n\th.pq = append(h.pq, k)\n\th.swim(h.n)\n}\n\n// Pop removes the largest element (according to their comparison rules) from\n// the heap and returns it. This call panics if the heap is empty. The\n// complexity is O(log(n)) where n == h.Len().\nfunc (h *Heap) Pop() KType {\n\tif h.n == 0 {\n\t\tpanic(\"heap: empty heap\")\n\t}\n\tval := h.pq[1]\n\th.swap(1, h.n)\n\th.pq = h.pq[:h.n]\n\th.n--\n\th.sink(1, h.n)\n\n\treturn val\n}\n\n// TryPop is like Pop, but tells if there was an element instead of panicking\n// on an empty heap.\nfunc (h *Heap) TryPop() (k KType, ok bool) {\n\tif h.n == 0 {\n\t\treturn k, false\n\t}\n\treturn h.Pop(), true\n}\n\n// Remove removes k from the heap, if it exists. Equality is defined by\n// Compare == 0.\n// The complexity is O(n+log(n)) where n == h.Len().\nfunc (h *Heap) Remove(k KType) bool {\n\tif h.n == 0 {\n\t\treturn false\n\t}\n\tif h.compare(h.pq[1], k) == 0 {\n\t\t_ = h.Pop()\n\t\treturn true\n\t}\n\tif h.before(k, h.pq[1]) {\n\t\t// larger than largest, don't try to find it\n\t\treturn false\n\t}\n\n\tfor i := 2; i <= h.n; i++ {\n\t\tif h.compare(h.pq[i], k) != 0 {\n\t\t\tcontinue\n\t\t}\n\t\t// replace k by the last element, which can then be smaller or\n\t\t// larger than its new parent and children\n\t\th.swap(i, h.n)\n\t\th.pq = h.pq[:h.n]\n\t\th.n--\n\t\tif i <= h.n {\n\t\t\th.sink(i, h.n)\n\t\t\th.swim(i)\n\t\t}\n\t\treturn true\n\t}\n\t// not in the heap\n\treturn false\n}\n\nfunc (h *Heap) swap(i, j int)      { h.pq[i], h.pq[j] = h.pq[j], h.pq[i] }\nfunc (h *Heap) less(i, j int) bool { return h.before(h.pq[j], h.pq[i]) }\n\nfunc (h *Heap) swim(k int) {\n\tfor k > 1 && h.less(k/2, k) {\n\t\th.swap(k/2, k)\n\t\tk = k / 2\n\t}\n}\n\nfunc (h *Heap) sink(k, n int) {\n\n\tfor k*2 <= n {\n\t\tj := 2 * k\n\t\tif j < n && h.less(j, j+1) {\n\t\t\tj++\n\t\t}\n\t\tif !h.less(k, j) {\n\t\t\tbreak\n\t\t}\n\t\th.swap(k, j)\n\t\tk = j\n\t}\n}\n"
+	indexedHeapSrc         = "package heap\n\n// The implementation is the one of the heaps, keeping track of where each id\n// is in the heap so that it can be found without scanning the heap.\n\nfunc (h IndexedHeap) compare(a, b KType) int { return a.Compare(b) }\n\n// before tells if a comes out of the heap before b: this is a max-heap.\nfunc (h IndexedHeap) before(a, b KType) bool { return h.compare(a, b) > 0 }\n\n// IndexedHeap is a max-heap of IDType ids, each with a KType key, where the\n// ids can be efficiently retrieved in the decreasing order of their keys\n// (according to their comparison rules). The key of an id can be updated,\n// and the id removed, without looking for it in the heap.\ntype IndexedHeap struct {\n\tn  int\n\tpq []indexedEntry\n\t// pos is the index of each id in pq\n\tpos map[IDType]int\n}\n\n// indexedEntry is an id of the heap, with its key.\ntype indexedEntry struct {\n\tid  IDType\n\tkey KType\n}\n\n// NewIndexedHeap creates an empty indexed heap.\nfunc NewIndexedHeap() *IndexedHeap {\n\treturn &IndexedHeap{\n\t\tpq:  make([]indexedEntry, 1),\n\t\tpos: make(map[IDType]int),\n\t}\n}\n\n// Len is the number of ids stored in the heap.\nfunc (h *IndexedHeap) Len() int { return h.n }\n\n// Contains tells if id is in the heap.\nfunc (h *IndexedHeap) Contains(id IDType) bool {\n\t_, ok := h.pos[id]\n\treturn ok\n}\n\n// Key returns the key of id, if id is in the heap.\nfunc (h *IndexedHeap) Key(id IDType) (k KType, ok bool) {\n\ti, ok := h.pos[id]\n\tif !ok {\n\t\treturn k, false\n\t}\n\treturn h.pq[i].key, true\n}\n\n// Peek at the id with the largest key (according to their comparison rules),\n// without removing it from the heap. This call panics if the heap is empty.\nfunc (h *IndexedHeap) Peek() (IDType, KType) {\n\tif h.n == 0 {\n\t\tpanic(\"heap: empty heap\")\n\t}\n\treturn h.pq[1].id, h.pq[1].key\n}\n\n// TryPeek is like Peek, but tells if there was an id instead of panicking on\n// an empty heap.\nfunc (h *IndexedHeap) TryPeek() (id IDType, k KType, ok bool) {\n\tif h.n == 0 {\n\t\treturn id, k, false\n\t}\n\treturn h.pq[1].id, h.pq[1].key, true\n}\n\n// Push pushes id onto the heap, with key k. If id is already in the heap, its\n// key is updated to k instead. The complexity is O(log(n)) where\n// n == h.Len().\nfunc (h *IndexedHeap) Push(id IDType, k KType) {\n\tif h.Update(id, k) {\n\t\treturn\n\t}\n\th.n++\n\th.pq = append(h.pq, indexedEntry{id: id, key: k})\n\th.pos[id] = h.n\n\th.swim(h.n)\n}\n\n// Pop removes the id with the largest key (according to their comparison\n// rules) from the heap and returns it, with its key. This call panics if the\n// heap is empty. The complexity is O(log(n)) where n == h.Len().\nfunc (h *IndexedHeap) Pop() (IDType, KType) {\n\tif h.n == 0 {\n\t\tpanic(\"heap: empty heap\")\n\t}\n\te := h.pq[1]\n\th.remove(1)\n\treturn e.id, e.key\n}\n\n// TryPop is like Pop, but tells if there was an id instead of panicking on an\n// empty heap.\nfunc (h *IndexedHeap) TryPop() (id IDType, k KType, ok bool) {\n\tif h.n == 0 {\n\t\treturn id, k, false\n\t}\n\tid, k = h.Pop()\n\treturn id, k, true\n}\n\n// Update changes the key of id to k, if id is in the heap. Whether the key is\n// increased or decreased, the id moves up or down the heap accordingly.\n// The complexity is O(log(n)) where n == h.Len().\nfunc (h *IndexedHeap) Update(id IDType, k KType) bool {\n\ti, ok := h.pos[id]\n\tif !ok {\n\t\treturn false\n\t}\n\th.pq[i].key = k\n\th.sink(i, h.n)\n\th.swim(i)\n\treturn true\n}\n\n// DecreaseKey changes the key of id to k, if id is in the heap and k isn't\n// above its key (according to their comparison rules). It's Update\n// for callers that only ever lower keys, as Dijkstra's shortest paths do.\n// The complexity is O(log(n)) where n == h.Len().\nfunc (h *IndexedHeap) DecreaseKey(id IDType, k KType) bool {\n\tif old, ok := h.Key(id); !ok || h.compare(k, old) > 0 {\n\t\treturn false\n\t}\n\treturn h.Update(id, k)\n}\n\n// IncreaseKey changes the key of id to k, if id is in the heap and k isn't\n// below its key (according to their comparison rules). It's Update\n// for callers that only ever raise keys.\n// The complexity is O(log(n)) where n == h.Len().\nfunc (h *IndexedHeap) IncreaseKey(id IDType, k KType) bool {\n\tif old, ok := h.Key(id); !ok || h.compare(k, old) < 0 {\n\t\treturn false\n\t}\n\treturn h.Update(id, k)\n}\n\n// Remove removes id from the heap, if it exists, and returns its key.\n// The complexity is O(log(n)) where n == h.Len().\nfunc (h *IndexedHeap) Remove(id IDType) (k KType, ok bool) {\n\ti, ok := h.pos[id]\n\tif !ok {\n\t\treturn k, false\n\t}\n\tk = h.pq[i].key\n\th.remove(i)\n\treturn k, true\n}\n\n// remove the entry at i, replacing it by the last entry, which can then be\n// smaller or larger than its new parent and children.\nfunc (h *IndexedHeap) remove(i int) {\n\tdelete(h.pos, h.pq[i].id)\n\th.pq[i] = h.pq[h.n]\n\th.pq = h.pq[:h.n]\n\th.n--\n\tif i <= h.n {\n\t\th.pos[h.pq[i].id] = i\n\t\th.sink(i, h.n)\n\t\th.swim(i)\n\t}\n}\n\nfunc (h *IndexedHeap) swap(i, j int) {\n\th.pq[i], h.pq[j] = h.pq[j], h.pq[i]\n\th.pos[h.pq[i].id] = i\n\th.pos[h.pq[j].id] = j\n}\n\nfunc (h *IndexedHeap) less(i, j int) bool { return h.before(h.pq[j].key, h.pq[i].key) }\n\nfunc (h *IndexedHeap) swim(k int) {\n\tfor k > 1 && h.less(k/2, k) {\n\t\th.swap(k/2, k)\n\t\tk = k / 2\n\t}\n}\n\nfunc (h *IndexedHeap) sink(k, n int) {\n\tfor k*2 <= n {\n\t\tj := 2 * k\n\t\tif j < n && h.less(j, j+1) {\n\t\t\tj++\n\t\t}\n\t\tif !h.less(k, j) {\n\t\t\tbreak\n\t\t}\n\t\th.swap(k, j)\n\t\tk = j\n\t}\n}\n"
+	indexedHeapTestSrc     = "package heap\n\nimport (\n\t\"math/rand\"\n\t\"testing\"\n)\n\n// The tests of this file are generated along with indexed heaps, when asked\n// to. The keys are generated by randomKType, the ids by randomIDType.\n\n// checkIndexedHeap verifies that no id of the heap comes out before its\n// parent, and that the position of each id is where it is in the heap.\nfunc checkIndexedHeap(t *testing.T, h *IndexedHeap) {\n\tif len(h.pq) != h.n+1 || len(h.pos) != h.n {\n\t\tt.Fatalf(\"want %d ids, got %d in the heap and %d positions\", h.n, len(h.pq)-1, len(h.pos))\n\t}\n\tfor i := 1; i <= h.n; i++ {\n\t\tif pos, ok := h.pos[h.pq[i].id]; !ok || pos != i {\n\t\t\tt.Fatalf(\"%v is at %d, but its position is %d\", h.pq[i].id, i, pos)\n\t\t}\n\t\tif i > 1 && h.before(h.pq[i].key, h.pq[i/2].key) {\n\t\t\tt.Fatalf(\"heap order violated: %v at %d comes out before its parent %v at %d\",\n\t\t\t\th.pq[i].key, i, h.pq[i/2].key, i/2)\n\t\t}\n\t}\n}\n\n// refIndexedHeap is a naive indexed heap keeping its ids in a map, ordered\n// like h. The ids are also listed in the order they were pushed, for the\n// tests to pick them the same way from one run to the other.\ntype refIndexedHeap struct {\n\th    *IndexedHeap\n\tkeys map[IDType]KType\n\tids  []IDType\n}\n\nfunc (r *refIndexedHeap) push(id IDType, k KType) {\n\tif _, ok := r.keys[id]; !ok {\n\t\tr.ids = append(r.ids, id)\n\t}\n\tr.keys[id] = k\n}\n\nfunc (r *refIndexedHeap) remove(id IDType) {\n\tif _, ok := r.keys[id]; !ok {\n\t\treturn\n\t}\n\tdelete(r.keys, id)\n\tfor i, rid := range r.ids {\n\t\tif rid == id {\n\t\t\tr.ids = append(r.ids[:i], r.ids[i+1:]...)\n\t\t\tbreak\n\t\t}\n\t}\n}\n\n// top is a key coming out first.\nfunc (r *refIndexedHeap) top() (top KType) {\n\tfirst := true\n\tfor _, k := range r.keys {\n\t\tif first || r.h.before(k, top) {\n\t\t\ttop, first = k, false\n\t\t}\n\t}\n\treturn top\n}\n\n// anyID returns one of the ids most of the time, or a random one.\nfunc (r *refIndexedHeap) anyID(rnd *rand.Rand) IDType {\n\tif len(r.ids) == 0 || rnd.Intn(4) == 0 {\n\t\treturn randomIDType(rnd)\n\t}\n\treturn r.ids[rnd.Intn(len(r.ids))]\n}\n\nfunc TestIndexedHeapMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\th := NewIndexedHeap()\n\tref := &refIndexedHeap{h: h, keys: make(map[IDType]KType)}\n\n\tfor i := 0; i < 5000; i++ {\n\t\tswitch op := rnd.Intn(10); {\n\t\tcase op < 4:\n\t\t\tid, k := randomIDType(rnd), randomKType(rnd)\n\t\t\th.Push(id, k)\n\t\t\tref.push(id, k)\n\t\tcase op < 6:\n\t\t\tif h.Len() == 0 {\n\t\t\t\tcheckIndexedHeapEmpty(t, h)\n\t\t\t\tcontinue\n\t\t\t}\n\t\t\twant := ref.top()\n\t\t\tif _, got := h.Peek(); h.compare(want, got) != 0 {\n\t\t\t\tt.Fatalf(\"peek: want %v, got %v\", want, got)\n\t\t\t}\n\t\t\tif _, got, ok := h.TryPeek(); !ok || h.compare(want, got) != 0 {\n\t\t\t\tt.Fatalf(\"try peek: want %v, true, got %v, %v\", want, got, ok)\n\t\t\t}\n\t\t\tvar id IDType\n\t\t\tvar got KType\n\t\t\tok := true\n\t\t\tif op < 5 {\n\t\t\t\tid, got = h.Pop()\n\t\t\t} else {\n\t\t\t\tid, got, ok = h.TryPop()\n\t\t\t}\n\t\t\tif !ok || h.compare(want, got) != 0 || h.compare(ref.keys[id], got) != 0 {\n\t\t\t\tt.Fatalf(\"pop: want %v, got %v for %v, which had %v\", want, got, id, ref.keys[id])\n\t\t\t}\n\t\t\tref.remove(id)\n\t\tcase op < 8:\n\t\t\tid, k := ref.anyID(rnd), randomKType(rnd)\n\t\t\told, want := ref.keys[id]\n\t\t\tvar got bool\n\t\t\tswitch rnd.Intn(3) {\n\t\t\tcase 0:\n\t\t\t\tgot = h.Update(id, k)\n\t\t\tcase 1:\n\t\t\t\twant = want && h.compare(k, old) <= 0\n\t\t\t\tgot = h.DecreaseKey(id, k)\n\t\t\tdefault:\n\t\t\t\twant = want && h.compare(k, old) >= 0\n\t\t\t\tgot = h.IncreaseKey(id, k)\n\t\t\t}\n\t\t\tif want != got {\n\t\t\t\tt.Fatalf(\"update %v from %v to %v: want %v, got %v\", id, old, k, want, got)\n\t\t\t}\n\t\t\tif want {\n\t\t\t\tref.keys[id] = k\n\t\t\t}\n\t\tcase op < 9:\n\t\t\tid := ref.anyID(rnd)\n\t\t\twant, wantOK := ref.keys[id]\n\t\t\tgot, ok := h.Remove(id)\n\t\t\tif wantOK != ok || ok && h.compare(want, got) != 0 {\n\t\t\t\tt.Fatalf(\"remove %v: want %v, %v, got %v, %v\", id, want, wantOK, got, ok)\n\t\t\t}\n\t\t\tref.remove(id)\n\t\tdefault:\n\t\t\tid := ref.anyID(rnd)\n\t\t\twant, wantOK := ref.keys[id]\n\t\t\tif got := h.Contains(id); wantOK != got {\n\t\t\t\tt.Fatalf(\"contains %v: want %v, got %v\", id, wantOK, got)\n\t\t\t}\n\t\t\tgot, ok := h.Key(id)\n\t\t\tif wantOK != ok || ok && h.compare(want, got) != 0 {\n\t\t\t\tt.Fatalf(\"key %v: want %v, %v, got %v, %v\", id, want, wantOK, got, ok)\n\t\t\t}\n\t\t}\n\t\tif want, got := len(ref.keys), h.Len(); want != got {\n\t\t\tt.Fatalf(\"want len %d, got %d\", want, got)\n\t\t}\n\t\tcheckIndexedHeap(t, h)\n\t}\n}\n\n// checkIndexedHeapEmpty verifies that the empty heap h has nothing to peek,\n// pop or remove.\nfunc checkIndexedHeapEmpty(t *testing.T, h *IndexedHeap) {\n\tif h.Len() != 0 {\n\t\tt.Fatalf(\"want len 0, got %d\", h.Len())\n\t}\n\tif id, k, ok := h.TryPeek(); ok {\n\t\tt.Fatalf(\"try peek: want nothing, got %v with %v\", id, k)\n\t}\n\tif id, k, ok := h.TryPop(); ok {\n\t\tt.Fatalf(\"try pop: want nothing, got %v with %v\", id, k)\n\t}\n\tfor _, op := range []struct {\n\t\tname string\n\t\tf    func()\n\t}{\n\t\t{\"peek\", func() { h.Peek() }},\n\t\t{\"pop\", func() { h.Pop() }},\n\t} {\n\t\tfunc() {\n\t\t\tdefer func() {\n\t\t\t\tif recover() == nil {\n\t\t\t\t\tt.Fatalf(\"%s: want a panic on an empty heap\", op.name)\n\t\t\t\t}\n\t\t\t}()\n\t\t\top.f()\n\t\t}()\n\t}\n\tcheckIndexedHeap(t, h)\n}\n\nfunc TestIndexedHeapEmpty(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\th := NewIndexedHeap()\n\tcheckIndexedHeapEmpty(t, h)\n\n\tid, k := randomIDType(rnd), randomKType(rnd)\n\th.Push(id, k)\n\tif gotID, got, ok := h.TryPop(); !ok || gotID != id || h.compare(k, got) != 0 {\n\t\tt.Fatalf(\"try pop: want %v with %v, true, got %v with %v, %v\", id, k, gotID, got, ok)\n\t}\n\tcheckIndexedHeapEmpty(t, h)\n\tif _, ok := h.Remove(id); ok {\n\t\tt.Fatalf(\"remove %v: want not found\", id)\n\t}\n\tif h.Update(id, k) {\n\t\tt.Fatalf(\"update %v: want not found\", id)\n\t}\n\tcheckIndexedHeapEmpty(t, h)\n}\n"
+	indexedHeapBenchSrc    = "package heap\n\nimport (\n\t\"math/rand\"\n\t\"strconv\"\n\t\"testing\"\n)\n\n// The benchmarks of this file are generated along with indexed heaps, when\n// asked to. The keys are generated by benchKType, the ids by benchIDType.\n\n// benchIndexedHeapSizes are the numbers of ids in the benchmarked heaps.\nvar benchIndexedHeapSizes = []int{100, 10000, 1000000}\n\n// benchIndexedHeap runs bench for each size, with a heap holding that many\n// random ids, and the ids and keys pushed in it. The ids are the same from\n// one benchmark to the other.\nfunc benchIndexedHeap(b *testing.B, bench func(b *testing.B, h *IndexedHeap, ids []IDType, keys []KType)) {\n\tfor _, n := range benchIndexedHeapSizes {\n\t\tn := n\n\t\tvar h *IndexedHeap\n\t\tvar ids []IDType\n\t\tvar keys []KType\n\t\tb.Run(strconv.Itoa(n), func(b *testing.B) {\n\t\t\t// once for all the runs of the benchmark, and only if it's run\n\t\t\tif h == nil {\n\t\t\t\trnd := rand.New(rand.NewSource(int64(n)))\n\t\t\t\tids = make([]IDType, n)\n\t\t\t\tkeys = make([]KType, n)\n\t\t\t\tfor i := range ids {\n\t\t\t\t\tids[i], keys[i] = benchIDType(rnd), benchKType(rnd)\n\t\t\t\t}\n\t\t\t\th = NewIndexedHeap()\n\t\t\t\tfillIndexedHeap(h, ids, keys)\n\t\t\t}\n\t\t\tbench(b, h, ids, keys)\n\t\t})\n\t}\n}\n\nfunc fillIndexedHeap(h *IndexedHeap, ids []IDType, keys []KType) {\n\tfor i, id := range ids {\n\t\th.Push(id, keys[i])\n\t}\n}\n\n// benchIndexedHeapRefill runs op b.N times, pushing the ids back in h every\n// n times. The refill isn't timed.\nfunc benchIndexedHeapRefill(b *testing.B, h *IndexedHeap, ids []IDType, keys []KType, op func(i int)) {\n\tn := len(ids)\n\tb.ResetTimer()\n\tfor i := 0; i < b.N; i++ {\n\t\top(i % n)\n\t\tif i%n == n-1 {\n\t\t\tb.StopTimer()\n\t\t\tfillIndexedHeap(h, ids, keys)\n\t\t\tb.StartTimer()\n\t\t}\n\t}\n\tb.StopTimer()\n\tfillIndexedHeap(h, ids, keys)\n}\n\nfunc BenchmarkIndexedHeapPush(b *testing.B) {\n\tbenchIndexedHeap(b, func(b *testing.B, h *IndexedHeap, ids []IDType, keys []KType) {\n\t\tn := len(ids)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\t// push the ids up to n, over and over\n\t\t\tif i%n == 0 {\n\t\t\t\tb.StopTimer()\n\t\t\t\tfor h.Len() > 0 {\n\t\t\t\t\th.Pop()\n\t\t\t\t}\n\t\t\t\tb.StartTimer()\n\t\t\t}\n\t\t\th.Push(ids[i%n], keys[i%n])\n\t\t}\n\t\tb.StopTimer()\n\t\tfillIndexedHeap(h, ids, keys)\n\t})\n}\n\nfunc BenchmarkIndexedHeapPop(b *testing.B) {\n\tbenchIndexedHeap(b, func(b *testing.B, h *IndexedHeap, ids []IDType, keys []KType) {\n\t\tbenchIndexedHeapRefill(b, h, ids, keys, func(int) {\n\t\t\tif h.Len() > 0 {\n\t\t\t\th.Pop()\n\t\t\t}\n\t\t})\n\t})\n}\n\nfunc BenchmarkIndexedHeapUpdate(b *testing.B) {\n\tbenchIndexedHeap(b, func(b *testing.B, h *IndexedHeap, ids []IDType, keys []KType) {\n\t\tn := len(ids)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\t// the keys of the others, so that the ids move around\n\t\t\th.Update(ids[i%n], keys[(i+1)%n])\n\t\t}\n\t})\n}\n\nfunc BenchmarkIndexedHeapRemove(b *testing.B) {\n\tbenchIndexedHeap(b, func(b *testing.B, h *IndexedHeap, ids []IDType, keys []KType) {\n\t\tbenchIndexedHeapRefill(b, h, ids, keys, func(i int) { h.Remove(ids[i]) })\n\t})\n}\n\nfunc BenchmarkIndexedHeapContains(b *testing.B) {\n\tbenchIndexedHeap(b, func(b *testing.B, h *IndexedHeap, ids []IDType, keys []KType) {\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\th.Contains(ids[i%len(ids)])\n\t\t}\n\t})\n}\n"
+	queueSrc               = "package queue\n\n// Implementation adapted from github.com/eapache/queue:\n//    The MIT License (MIT)\n//    Copyright (c) 2014 Evan Huus\n\nvar nilKType KType\n\n// Queue represents a single instance of the queue data structure.\ntype Queue struct {\n\tbuf               []KType\n\thead, tail, count int\n\tminlen            int\n}\n\n// NewQueue constructs and returns a new Queue with an initial capacity.\nfunc NewQueue(capacity int) *Queue {\n\t// min capacity of 16\n\tif capacity < 16 {\n\t\tcapacity = 16\n\t}\n\treturn &Queue{buf: make([]KType, capacity), minlen: capacity}\n}\n\n// Len returns the number of elements currently stored in the queue.\nfunc (q *Queue) Len() int {\n\treturn q.count\n}\n\n// Push puts an element on the end of the queue.\nfunc (q *Queue) Push(elem KType) {\n\tif q.count == len(q.buf) {\n\t\tq.resize()\n\t}\n\n\tq.buf[q.tail] = elem\n\tq.tail = (q.tail + 1) % len(q.buf)\n\tq.count++\n}\n\n// Peek returns the element at the head of the queue. This call panics\n// if the queue is empty.\nfunc (q *Queue) Peek() KType {\n\tif q.Len() <= 0 {\n\t\tpanic(\"queue: empty queue\")\n\t}\n\treturn q.buf[q.head]\n}\n\n// TryPeek is like Peek, but tells if there was an element instead of\n// panicking on an empty queue.\nfunc (q *Queue) TryPeek() (KType, bool) {\n\tif q.count == 0 {\n\t\treturn nilKType, false\n\t}\n\treturn q.buf[q.head], true\n}\n\n// Get returns the element at index i in the queue. If the index is\n// invalid, the call will panic.\nfunc (q *Queue) Get(i int) KType {\n\tif i >= q.Len() || i < 0 {\n\t\tpanic(\"queue: index out of range\")\n\t}\n\tmodi := (q.head + i) % len(q.buf)\n\treturn q.buf[modi]\n}\n\n// Pop removes the element from the front of the queue.\n// This call panics if the queue is empty.\nfunc (q *Queue) Pop() KType {\n\tif q.Len() <= 0 {\n\t\tpanic(\"queue: empty queue\")\n\t}\n\tv := q.buf[q.head]\n\t// set to nil to avoid keeping reference to objects\n\t// that would otherwise be garbage collected\n\tq.buf[q.head] = nilKType\n\tq.head = (q.head + 1) % len(q.buf)\n\tq.count--\n\tif len(q.buf) > q.minlen && q.count*4 <= len(q.buf) {\n\t\tq.resize()\n\t}\n\treturn v\n}\n\n// TryPop is like Pop, but tells if there was an element instead of panicking\n// on an empty queue.\nfunc (q *Queue) TryPop() (KType, bool) {\n\tif q.count == 0 {\n\t\treturn nilKType, false\n\t}\n\treturn q.Pop(), true\n}\n\nfunc (q *Queue) resize() {\n\tnewBuf := make([]KType, q.count*2)\n\n\tif q.tail > q.head {\n\t\tcopy(newBuf, q.buf[q.head:q.tail])\n\t} else {\n\t\tcopy(newBuf, q.buf[q.head:len(q.buf)])\n\t\tcopy(newBuf[len(q.buf)-q.head:], q.buf[:q.tail])\n\t}\n\n\tq.head = 0\n\tq.tail = q.count\n\tq.buf = newBuf\n}\n"
+	boundedQueueSrc        = "package queue\n\n// The ring buffer is the one of the queue, which never grows nor shrinks.\n\n// BoundedQueue represents a single instance of the queue data structure,\n// holding at most a fixed number of elements. When it's full, pushing an\n// element either fails, or overwrites the oldest element of the queue, as\n// chosen when creating it.\ntype BoundedQueue struct {\n\tbuf               []KType\n\thead, tail, count int\n\toverwrite         bool\n}\n\n// NewBoundedQueue constructs and returns a new BoundedQueue holding at most\n// capacity elements, for which the memory is allocated at once. If overwrite\n// is true, pushing onto a full queue drops its oldest element, as a circular\n// log would; otherwise the push is rejected. This call panics if the capacity\n// isn't positive.\nfunc NewBoundedQueue(capacity int, overwrite bool) *BoundedQueue {\n\tif capacity <= 0 {\n\t\tpanic(\"queue: capacity must be positive\")\n\t}\n\treturn &BoundedQueue{buf: make([]KType, capacity), overwrite: overwrite}\n}\n\n// Len returns the number of elements currently stored in the queue.\nfunc (q *BoundedQueue) Len() int {\n\treturn q.count\n}\n\n// Cap returns the maximum number of elements the queue can hold.\nfunc (q *BoundedQueue) Cap() int {\n\treturn len(q.buf)\n}\n\n// Push puts an element on the end of the queue, and tells if it did. On a\n// full queue, the push fails unless the queue overwrites its oldest element.\nfunc (q *BoundedQueue) Push(elem KType) bool {\n\tif q.count == len(q.buf) {\n\t\tif !q.overwrite {\n\t\t\treturn false\n\t\t}\n\t\t// the tail of a full queue is on its head: drop the oldest element\n\t\tq.head = (q.head + 1) % len(q.buf)\n\t\tq.count--\n\t}\n\n\tq.buf[q.tail] = elem\n\tq.tail = (q.tail + 1) % len(q.buf)\n\tq.count++\n\treturn true\n}\n\n// Peek returns the element at the head of the queue. This call panics\n// if the queue is empty.\nfunc (q *BoundedQueue) Peek() KType {\n\tif q.count <= 0 {\n\t\tpanic(\"queue: empty queue\")\n\t}\n\treturn q.buf[q.head]\n}\n\n// TryPeek is like Peek, but tells if there was an element instead of\n// panicking on an empty queue.\nfunc (q *BoundedQueue) TryPeek() (k KType, ok bool) {\n\tif q.count == 0 {\n\t\treturn k, false\n\t}\n\treturn q.buf[q.head], true\n}\n\n// Get returns the element at index i in the queue. If the index is\n// invalid, the call will panic.\nfunc (q *BoundedQueue) Get(i int) KType {\n\tif i >= q.count || i < 0 {\n\t\tpanic(\"queue: index out of range\")\n\t}\n\treturn q.buf[(q.head+i)%len(q.buf)]\n}\n\n// Pop removes the element from the front of the queue.\n// This call panics if the queue is empty.\nfunc (q *BoundedQueue) Pop() KType {\n\tif q.count <= 0 {\n\t\tpanic(\"queue: empty queue\")\n\t}\n\tv := q.buf[q.head]\n\t// set to the zero value to avoid keeping reference to objects\n\t// that would otherwise be garbage collected\n\tvar zero KType\n\tq.buf[q.head] = zero\n\tq.head = (q.head + 1) % len(q.buf)\n\tq.count--\n\treturn v\n}\n\n// TryPop is like Pop, but tells if there was an element instead of panicking\n// on an empty queue.\nfunc (q *BoundedQueue) TryPop() (k KType, ok bool) {\n\tif q.count == 0 {\n\t\treturn k, false\n\t}\n\treturn q.Pop(), true\n}\n"
+	boundedQueueTestSrc    = "package queue\n\nimport (\n\t\"math/rand\"\n\t\"reflect\"\n\t\"testing\"\n)\n\n// The tests of this file are generated along with bounded queues, when asked\n// to. The elements are generated by randomKType.\n\n// checkBoundedQueue verifies that the head, the tail and the count of the\n// queue agree, and that the slots out of the queue are cleared.\nfunc checkBoundedQueue(t *testing.T, q *BoundedQueue) {\n\tif q.count < 0 || q.count > len(q.buf) {\n\t\tt.Fatalf(\"count %d out of a buffer of %d\", q.count, len(q.buf))\n\t}\n\tif q.head < 0 || q.head >= len(q.buf) {\n\t\tt.Fatalf(\"head %d out of a buffer of %d\", q.head, len(q.buf))\n\t}\n\tif want := (q.head + q.count) % len(q.buf); q.tail != want {\n\t\tt.Fatalf(\"head %d and count %d: want tail %d, got %d\", q.head, q.count, want, q.tail)\n\t}\n\tvar zero KType\n\tfor i := q.count; i < len(q.buf); i++ {\n\t\tif at := (q.head + i) % len(q.buf); !reflect.DeepEqual(q.buf[at], zero) {\n\t\t\tt.Fatalf(\"slot %d out of the queue holds %v\", at, q.buf[at])\n\t\t}\n\t}\n}\n\n// checkBoundedQueueElems verifies that q holds the elements of ref, in order.\nfunc checkBoundedQueueElems(t *testing.T, q *BoundedQueue, ref []KType) {\n\tif q.Len() != len(ref) {\n\t\tt.Fatalf(\"want len %d, got %d\", len(ref), q.Len())\n\t}\n\tfor i, want := range ref {\n\t\tif got := q.Get(i); !reflect.DeepEqual(want, got) {\n\t\t\tt.Fatalf(\"get %d: want %v, got %v\", i, want, got)\n\t\t}\n\t}\n}\n\nfunc TestBoundedQueueMatchesReference(t *testing.T) {\n\tfor _, overwrite := range []bool{false, true} {\n\t\trnd := rand.New(rand.NewSource(42))\n\t\tq := NewBoundedQueue(50, overwrite)\n\t\tvar ref []KType\n\n\t\tfor i := 0; i < 5000; i++ {\n\t\t\t// favor pushes, then pops, so the queue fills up and empties\n\t\t\tpush := 6\n\t\t\tif i%2000 >= 1000 {\n\t\t\t\tpush = 4\n\t\t\t}\n\t\t\tif rnd.Intn(10) < push {\n\t\t\t\tk := randomKType(rnd)\n\t\t\t\tfull := len(ref) == q.Cap()\n\t\t\t\tif pushed := q.Push(k); pushed != (overwrite || !full) {\n\t\t\t\t\tt.Fatalf(\"push on a queue of %d/%d: want %v, got %v\", len(ref), q.Cap(), !pushed, pushed)\n\t\t\t\t}\n\t\t\t\tif full && overwrite {\n\t\t\t\t\tref = ref[1:]\n\t\t\t\t}\n\t\t\t\tif !full || overwrite {\n\t\t\t\t\tref = append(ref, k)\n\t\t\t\t}\n\t\t\t} else if len(ref) != 0 {\n\t\t\t\tif want, got := ref[0], q.Peek(); !reflect.DeepEqual(want, got) {\n\t\t\t\t\tt.Fatalf(\"peek: want %v, got %v\", want, got)\n\t\t\t\t}\n\t\t\t\tif i%2 == 0 {\n\t\t\t\t\tif want, got := ref[0], q.Pop(); !reflect.DeepEqual(want, got) {\n\t\t\t\t\t\tt.Fatalf(\"pop: want %v, got %v\", want, got)\n\t\t\t\t\t}\n\t\t\t\t} else if got, ok := q.TryPop(); !ok || !reflect.DeepEqual(ref[0], got) {\n\t\t\t\t\tt.Fatalf(\"try pop: want %v, true, got %v, %v\", ref[0], got, ok)\n\t\t\t\t}\n\t\t\t\tref = ref[1:]\n\t\t\t} else {\n\t\t\t\tcheckBoundedQueueEmpty(t, q)\n\t\t\t}\n\t\t\tcheckBoundedQueue(t, q)\n\t\t\tif i%100 == 0 {\n\t\t\t\tcheckBoundedQueueElems(t, q, ref)\n\t\t\t}\n\t\t}\n\t\tcheckBoundedQueueElems(t, q, ref)\n\t}\n}\n\nfunc TestBoundedQueueWrapsAroundAtCapacity(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tfor _, overwrite := range []bool{false, true} {\n\t\tq := NewBoundedQueue(16, overwrite)\n\t\tvar ref []KType\n\t\tpush := func(n int) {\n\t\t\tfor i := 0; i < n; i++ {\n\t\t\t\tk := randomKType(rnd)\n\t\t\t\tif !q.Push(k) {\n\t\t\t\t\tcontinue\n\t\t\t\t}\n\t\t\t\tif len(ref) == q.Cap() {\n\t\t\t\t\tref = ref[1:]\n\t\t\t\t}\n\t\t\t\tref = append(ref, k)\n\t\t\t\tcheckBoundedQueue(t, q)\n\t\t\t}\n\t\t}\n\n\t\t// move the head to the middle of the buffer, then fill it up, the\n\t\t// tail wrapping around to the head\n\t\tpush(10)\n\t\tfor i := 0; i < 8; i++ {\n\t\t\tq.Pop()\n\t\t\tref = ref[1:]\n\t\t}\n\t\tpush(14)\n\t\tif q.Len() != q.Cap() || q.tail != q.head || q.head != 8 {\n\t\t\tt.Fatalf(\"want a full queue from 8, got head %d, tail %d, len %d\", q.head, q.tail, q.Len())\n\t\t}\n\t\tcheckBoundedQueueElems(t, q, ref)\n\n\t\t// push past the capacity, many times around the buffer\n\t\tpush(3*q.Cap() + 5)\n\t\tif q.Len() != q.Cap() {\n\t\t\tt.Fatalf(\"want a full queue, got len %d\", q.Len())\n\t\t}\n\t\tcheckBoundedQueueElems(t, q, ref)\n\n\t\tfor len(ref) > 0 {\n\t\t\tif want, got := ref[0], q.Pop(); !reflect.DeepEqual(want, got) {\n\t\t\t\tt.Fatalf(\"pop: want %v, got %v\", want, got)\n\t\t\t}\n\t\t\tref = ref[1:]\n\t\t\tcheckBoundedQueue(t, q)\n\t\t}\n\t\tcheckBoundedQueueEmpty(t, q)\n\t}\n}\n\n// checkBoundedQueueEmpty verifies that the empty queue q has nothing to peek,\n// pop or get.\nfunc checkBoundedQueueEmpty(t *testing.T, q *BoundedQueue) {\n\tif q.Len() != 0 {\n\t\tt.Fatalf(\"want len 0, got %d\", q.Len())\n\t}\n\tif got, ok := q.TryPeek(); ok {\n\t\tt.Fatalf(\"try peek: want nothing, got %v\", got)\n\t}\n\tif got, ok := q.TryPop(); ok {\n\t\tt.Fatalf(\"try pop: want nothing, got %v\", got)\n\t}\n\tfor _, op := range []struct {\n\t\tname string\n\t\tf    func()\n\t}{\n\t\t{\"peek\", func() { q.Peek() }},\n\t\t{\"pop\", func() { q.Pop() }},\n\t\t{\"get\", func() { q.Get(0) }},\n\t} {\n\t\tfunc() {\n\t\t\tdefer func() {\n\t\t\t\tif recover() == nil {\n\t\t\t\t\tt.Fatalf(\"%s: want a panic on an empty queue\", op.name)\n\t\t\t\t}\n\t\t\t}()\n\t\t\top.f()\n\t\t}()\n\t}\n\tcheckBoundedQueue(t, q)\n}\n\nfunc TestBoundedQueueEmpty(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tq := NewBoundedQueue(1, false)\n\tcheckBoundedQueueEmpty(t, q)\n\n\tk := randomKType(rnd)\n\tq.Push(k)\n\tif got, ok := q.TryPeek(); !ok || !reflect.DeepEqual(k, got) {\n\t\tt.Fatalf(\"try peek: want %v, true, got %v, %v\", k, got, ok)\n\t}\n\tif got, ok := q.TryPop(); !ok || !reflect.DeepEqual(k, got) {\n\t\tt.Fatalf(\"try pop: want %v, true, got %v, %v\", k, got, ok)\n\t}\n\tcheckBoundedQueueEmpty(t, q)\n}\n"
+	boundedQueueBenchSrc   = "package queue\n\nimport (\n\t\"math/rand\"\n\t\"strconv\"\n\t\"testing\"\n)\n\n// The benchmarks of this file are generated along with bounded queues, when\n// asked to. The elements are generated by benchKType.\n\n// benchBoundedQueueSizes are the capacities of the benchmarked queues.\nvar benchBoundedQueueSizes = []int{100, 10000, 1000000}\n\n// benchBoundedQueue runs bench for each size, with that many random elements.\n// The elements are the same from one benchmark to the other.\nfunc benchBoundedQueue(b *testing.B, bench func(b *testing.B, elems []KType)) {\n\tfor _, n := range benchBoundedQueueSizes {\n\t\tn := n\n\t\tvar elems []KType\n\t\tb.Run(strconv.Itoa(n), func(b *testing.B) {\n\t\t\t// once for all the runs of the benchmark, and only if it's run\n\t\t\tif elems == nil {\n\t\t\t\trnd := rand.New(rand.NewSource(int64(n)))\n\t\t\t\telems = make([]KType, n)\n\t\t\t\tfor i := range elems {\n\t\t\t\t\telems[i] = benchKType(rnd)\n\t\t\t\t}\n\t\t\t}\n\t\t\tb.ResetTimer()\n\t\t\tbench(b, elems)\n\t\t})\n\t}\n}\n\n// fillBoundedQueue returns a full queue holding elems.\nfunc fillBoundedQueue(elems []KType, overwrite bool) *BoundedQueue {\n\tq := NewBoundedQueue(len(elems), overwrite)\n\tfor _, e := range elems {\n\t\tq.Push(e)\n\t}\n\treturn q\n}\n\nfunc BenchmarkBoundedQueuePushPop(b *testing.B) {\n\tbenchBoundedQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := fillBoundedQueue(elems, false)\n\t\tq.Pop()\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.Push(elems[i%n])\n\t\t\tq.Pop()\n\t\t}\n\t})\n}\n\nfunc BenchmarkBoundedQueueOverwrite(b *testing.B) {\n\tbenchBoundedQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := fillBoundedQueue(elems, true)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.Push(elems[i%n])\n\t\t}\n\t})\n}\n\nfunc BenchmarkBoundedQueueReject(b *testing.B) {\n\tbenchBoundedQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := fillBoundedQueue(elems, false)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.Push(elems[i%n])\n\t\t}\n\t})\n}\n\nfunc BenchmarkBoundedQueueGetAt(b *testing.B) {\n\tbenchBoundedQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := fillBoundedQueue(elems, false)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.Get(i % n)\n\t\t}\n\t})\n}\n"
+	blockingQueueSrc       = "package queue\n\nimport (\n\t\"context\"\n\t\"sync\"\n)\n\n// The ring buffer is the one of the bounded queue, guarded by a mutex.\n\n// BlockingQueue represents a single instance of the queue data structure,\n// safe for concurrent use, holding at most a fixed number of elements. Popping\n// from an empty queue, or pushing onto a full one, either fails right away or\n// waits for another goroutine to push or pop an element.\ntype BlockingQueue struct {\n\tmu                sync.Mutex\n\tnotEmpty, notFull *sync.Cond\n\tbuf               []KType\n\thead, tail, count int\n}\n\n// NewBlockingQueue constructs and returns a new BlockingQueue holding at most\n// capacity elements, for which the memory is allocated at once. This call\n// panics if the capacity isn't positive.\nfunc NewBlockingQueue(capacity int) *BlockingQueue {\n\tif capacity <= 0 {\n\t\tpanic(\"queue: capacity must be positive\")\n\t}\n\tq := &BlockingQueue{buf: make([]KType, capacity)}\n\tq.notEmpty = sync.NewCond(&q.mu)\n\tq.notFull = sync.NewCond(&q.mu)\n\treturn q\n}\n\n// Len returns the number of elements currently stored in the queue.\nfunc (q *BlockingQueue) Len() int {\n\tq.mu.Lock()\n\tdefer q.mu.Unlock()\n\treturn q.count\n}\n\n// Cap returns the maximum number of elements the queue can hold.\nfunc (q *BlockingQueue) Cap() int {\n\treturn len(q.buf)\n}\n\n// Push puts an element on the end of the queue, and tells if it did, which\n// it doesn't if the queue is full.\nfunc (q *BlockingQueue) Push(elem KType) bool {\n\tq.mu.Lock()\n\tdefer q.mu.Unlock()\n\tif q.count == len(q.buf) {\n\t\treturn false\n\t}\n\tq.push(elem)\n\treturn true\n}\n\n// PushWait puts an element on the end of the queue, waiting for room if the\n// queue is full. It returns the error of ctx, without pushing the element, if\n// ctx is done first.\nfunc (q *BlockingQueue) PushWait(ctx context.Context, elem KType) error {\n\tq.mu.Lock()\n\tdefer q.mu.Unlock()\n\tif q.count == len(q.buf) {\n\t\tdefer q.wakeWhenDone(ctx, q.notFull)()\n\t}\n\tfor q.count == len(q.buf) {\n\t\tif err := ctx.Err(); err != nil {\n\t\t\treturn err\n\t\t}\n\t\tq.notFull.Wait()\n\t}\n\tq.push(elem)\n\treturn nil\n}\n\n// TryPeek returns the element at the head of the queue, and tells if there\n// was one.\nfunc (q *BlockingQueue) TryPeek() (k KType, ok bool) {\n\tq.mu.Lock()\n\tdefer q.mu.Unlock()\n\tif q.count == 0 {\n\t\treturn k, false\n\t}\n\treturn q.buf[q.head], true\n}\n\n// TryPop removes the element from the front of the queue, and tells if there\n// was one.\nfunc (q *BlockingQueue) TryPop() (k KType, ok bool) {\n\tq.mu.Lock()\n\tdefer q.mu.Unlock()\n\tif q.count == 0 {\n\t\treturn k, false\n\t}\n\treturn q.pop(), true\n}\n\n// PopWait removes the element from the front of the queue, waiting for one\n// if the queue is empty. It returns the error of ctx instead if ctx is done\n// first.\nfunc (q *BlockingQueue) PopWait(ctx context.Context) (k KType, err error) {\n\tq.mu.Lock()\n\tdefer q.mu.Unlock()\n\tif q.count == 0 {\n\t\tdefer q.wakeWhenDone(ctx, q.notEmpty)()\n\t}\n\tfor q.count == 0 {\n\t\tif err := ctx.Err(); err != nil {\n\t\t\treturn k, err\n\t\t}\n\t\tq.notEmpty.Wait()\n\t}\n\treturn q.pop(), nil\n}\n\n// wakeWhenDone wakes the goroutines waiting on c up when ctx is done, so that\n// they give up, until the returned func is called. It must be called, with the\n// lock held, before waiting on c.\nfunc (q *BlockingQueue) wakeWhenDone(ctx context.Context, c *sync.Cond) (stop func()) {\n\tif ctx.Done() == nil {\n\t\t// never done\n\t\treturn func() {}\n\t}\n\tstopped := make(chan struct{})\n\tgo func() {\n\t\tselect {\n\t\tcase <-ctx.Done():\n\t\t\tq.mu.Lock()\n\t\t\tc.Broadcast()\n\t\t\tq.mu.Unlock()\n\t\tcase <-stopped:\n\t\t}\n\t}()\n\treturn func() { close(stopped) }\n}\n\n// push and pop an element, with the lock held, waking a goroutine waiting to\n// do the opposite.\n\nfunc (q *BlockingQueue) push(elem KType) {\n\tq.buf[q.tail] = elem\n\tq.tail = (q.tail + 1) % len(q.buf)\n\tq.count++\n\tq.notEmpty.Signal()\n}\n\nfunc (q *BlockingQueue) pop() KType {\n\tv := q.buf[q.head]\n\t// set to the zero value to avoid keeping reference to objects\n\t// that would otherwise be garbage collected\n\tvar zero KType\n\tq.buf[q.head] = zero\n\tq.head = (q.head + 1) % len(q.buf)\n\tq.count--\n\tq.notFull.Signal()\n\treturn v\n}\n"
+	blockingQueueTestSrc   = "package queue\n\nimport (\n\t\"context\"\n\t\"fmt\"\n\t\"math/rand\"\n\t\"reflect\"\n\t\"sync\"\n\t\"testing\"\n\t\"time\"\n)\n\n// The tests of this file are generated along with blocking queues, when asked\n// to. The elements are generated by randomKType. Run them with -race.\n\n// checkBlockingQueue verifies that the head, the tail and the count of the\n// queue agree, and that the slots out of the queue are cleared.\nfunc checkBlockingQueue(t *testing.T, q *BlockingQueue) {\n\tq.mu.Lock()\n\tdefer q.mu.Unlock()\n\tif q.count < 0 || q.count > len(q.buf) {\n\t\tt.Fatalf(\"count %d out of a buffer of %d\", q.count, len(q.buf))\n\t}\n\tif q.head < 0 || q.head >= len(q.buf) {\n\t\tt.Fatalf(\"head %d out of a buffer of %d\", q.head, len(q.buf))\n\t}\n\tif want := (q.head + q.count) % len(q.buf); q.tail != want {\n\t\tt.Fatalf(\"head %d and count %d: want tail %d, got %d\", q.head, q.count, want, q.tail)\n\t}\n\tvar zero KType\n\tfor i := q.count; i < len(q.buf); i++ {\n\t\tif at := (q.head + i) % len(q.buf); !reflect.DeepEqual(q.buf[at], zero) {\n\t\t\tt.Fatalf(\"slot %d out of the queue holds %v\", at, q.buf[at])\n\t\t}\n\t}\n}\n\nfunc TestBlockingQueueMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tq := NewBlockingQueue(50)\n\tvar ref []KType\n\n\tfor i := 0; i < 5000; i++ {\n\t\t// favor pushes, then pops, so the queue fills up and empties\n\t\tpush := 6\n\t\tif i%2000 >= 1000 {\n\t\t\tpush = 4\n\t\t}\n\t\tif rnd.Intn(10) < push {\n\t\t\tk := randomKType(rnd)\n\t\t\tfull := len(ref) == q.Cap()\n\t\t\tif pushed := q.Push(k); pushed == full {\n\t\t\t\tt.Fatalf(\"push on a queue of %d/%d: want %v, got %v\", len(ref), q.Cap(), !full, pushed)\n\t\t\t}\n\t\t\tif !full {\n\t\t\t\tref = append(ref, k)\n\t\t\t}\n\t\t} else if len(ref) != 0 {\n\t\t\tif got, ok := q.TryPeek(); !ok || !reflect.DeepEqual(ref[0], got) {\n\t\t\t\tt.Fatalf(\"try peek: want %v, true, got %v, %v\", ref[0], got, ok)\n\t\t\t}\n\t\t\tif i%2 == 0 {\n\t\t\t\tif got, err := q.PopWait(context.Background()); err != nil || !reflect.DeepEqual(ref[0], got) {\n\t\t\t\t\tt.Fatalf(\"pop wait: want %v, nil, got %v, %v\", ref[0], got, err)\n\t\t\t\t}\n\t\t\t} else if got, ok := q.TryPop(); !ok || !reflect.DeepEqual(ref[0], got) {\n\t\t\t\tt.Fatalf(\"try pop: want %v, true, got %v, %v\", ref[0], got, ok)\n\t\t\t}\n\t\t\tref = ref[1:]\n\t\t} else {\n\t\t\tif got, ok := q.TryPeek(); ok {\n\t\t\t\tt.Fatalf(\"try peek: want nothing, got %v\", got)\n\t\t\t}\n\t\t\tif got, ok := q.TryPop(); ok {\n\t\t\t\tt.Fatalf(\"try pop: want nothing, got %v\", got)\n\t\t\t}\n\t\t}\n\t\tcheckBlockingQueue(t, q)\n\t\tif q.Len() != len(ref) {\n\t\t\tt.Fatalf(\"want len %d, got %d\", len(ref), q.Len())\n\t\t}\n\t}\n}\n\n// TestBlockingQueueProducersConsumers has goroutines pushing and popping\n// through a small queue, waiting on each other, and verifies that every\n// element pushed is popped once.\nfunc TestBlockingQueueProducersConsumers(t *testing.T) {\n\tconst producers, consumers, perProducer = 4, 4, 500\n\trnd := rand.New(rand.NewSource(42))\n\telems := make([][]KType, producers)\n\tfor i := range elems {\n\t\telems[i] = make([]KType, perProducer)\n\t\tfor j := range elems[i] {\n\t\t\telems[i][j] = randomKType(rnd)\n\t\t}\n\t}\n\n\tq := NewBlockingQueue(8)\n\tctx := context.Background()\n\tvar wg sync.WaitGroup\n\tfor _, e := range elems {\n\t\twg.Add(1)\n\t\tgo func(e []KType) {\n\t\t\tdefer wg.Done()\n\t\t\tfor _, k := range e {\n\t\t\t\tif err := q.PushWait(ctx, k); err != nil {\n\t\t\t\t\tt.Errorf(\"push wait: %v\", err)\n\t\t\t\t}\n\t\t\t}\n\t\t}(e)\n\t}\n\tpopped := make([][]KType, consumers)\n\tfor i := range popped {\n\t\twg.Add(1)\n\t\tgo func(i int) {\n\t\t\tdefer wg.Done()\n\t\t\tfor j := 0; j < producers*perProducer/consumers; j++ {\n\t\t\t\tk, err := q.PopWait(ctx)\n\t\t\t\tif err != nil {\n\t\t\t\t\tt.Errorf(\"pop wait: %v\", err)\n\t\t\t\t}\n\t\t\t\tpopped[i] = append(popped[i], k)\n\t\t\t}\n\t\t}(i)\n\t}\n\twg.Wait()\n\tcheckBlockingQueue(t, q)\n\n\t// the elements of any type are told apart by how they're printed\n\tcount := make(map[string]int)\n\tfor _, e := range elems {\n\t\tfor _, k := range e {\n\t\t\tcount[fmt.Sprintf(\"%#v\", k)]++\n\t\t}\n\t}\n\tfor _, p := range popped {\n\t\tfor _, k := range p {\n\t\t\tcount[fmt.Sprintf(\"%#v\", k)]--\n\t\t}\n\t}\n\tfor k, n := range count {\n\t\tif n != 0 {\n\t\t\tt.Errorf(\"%s: pushed %d more times than popped\", k, n)\n\t\t}\n\t}\n\tif q.Len() != 0 {\n\t\tt.Errorf(\"want len 0, got %d\", q.Len())\n\t}\n}\n\nfunc TestBlockingQueueWaitIsCancelled(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tq := NewBlockingQueue(1)\n\n\tctx, cancel := context.WithCancel(context.Background())\n\tcancel()\n\tif got, err := q.PopWait(ctx); err != context.Canceled {\n\t\tt.Fatalf(\"pop wait on an empty queue: want %v, got %v, %v\", context.Canceled, got, err)\n\t}\n\n\tk := randomKType(rnd)\n\tq.Push(k)\n\tctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)\n\tdefer cancel()\n\tif err := q.PushWait(ctx, randomKType(rnd)); err != context.DeadlineExceeded {\n\t\tt.Fatalf(\"push wait on a full queue: want %v, got %v\", context.DeadlineExceeded, err)\n\t}\n\t// an element is there, the context doesn't matter\n\tif got, err := q.PopWait(ctx); err != nil || !reflect.DeepEqual(k, got) {\n\t\tt.Fatalf(\"pop wait: want %v, nil, got %v, %v\", k, got, err)\n\t}\n\tcheckBlockingQueue(t, q)\n}\n"
+	blockingQueueBenchSrc  = "package queue\n\nimport (\n\t\"context\"\n\t\"math/rand\"\n\t\"strconv\"\n\t\"testing\"\n)\n\n// The benchmarks of this file are generated along with blocking queues, when\n// asked to. The elements are generated by benchKType.\n\n// benchBlockingQueueSizes are the capacities of the benchmarked queues.\nvar benchBlockingQueueSizes = []int{100, 10000, 1000000}\n\n// benchBlockingQueue runs bench for each size, with that many random\n// elements. The elements are the same from one benchmark to the other.\nfunc benchBlockingQueue(b *testing.B, bench func(b *testing.B, elems []KType)) {\n\tfor _, n := range benchBlockingQueueSizes {\n\t\tn := n\n\t\tvar elems []KType\n\t\tb.Run(strconv.Itoa(n), func(b *testing.B) {\n\t\t\t// once for all the runs of the benchmark, and only if it's run\n\t\t\tif elems == nil {\n\t\t\t\trnd := rand.New(rand.NewSource(int64(n)))\n\t\t\t\telems = make([]KType, n)\n\t\t\t\tfor i := range elems {\n\t\t\t\t\telems[i] = benchKType(rnd)\n\t\t\t\t}\n\t\t\t}\n\t\t\tb.ResetTimer()\n\t\t\tbench(b, elems)\n\t\t})\n\t}\n}\n\nfunc BenchmarkBlockingQueuePushPop(b *testing.B) {\n\tbenchBlockingQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := NewBlockingQueue(n)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.Push(elems[i%n])\n\t\t\tq.TryPop()\n\t\t}\n\t})\n}\n\n// BenchmarkBlockingQueueProducerConsumer is to be compared with\n// BenchmarkBlockingQueueProducerConsumerChan.\nfunc BenchmarkBlockingQueueProducerConsumer(b *testing.B) {\n\tbenchBlockingQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := NewBlockingQueue(n)\n\t\tctx := context.Background()\n\t\tdone := make(chan struct{})\n\t\tgo func() {\n\t\t\tdefer close(done)\n\t\t\tfor i := 0; i < b.N; i++ {\n\t\t\t\tq.PopWait(ctx)\n\t\t\t}\n\t\t}()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.PushWait(ctx, elems[i%n])\n\t\t}\n\t\t<-done\n\t})\n}\n\n// BenchmarkBlockingQueueProducerConsumerChan sends the elements through a\n// channel of the same capacity.\nfunc BenchmarkBlockingQueueProducerConsumerChan(b *testing.B) {\n\tbenchBlockingQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tc := make(chan KType, n)\n\t\tdone := make(chan struct{})\n\t\tgo func() {\n\t\t\tdefer close(done)\n\t\t\tfor i := 0; i < b.N; i++ {\n\t\t\t\t<-c\n\t\t\t}\n\t\t}()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tc <- elems[i%n]\n\t\t}\n\t\t<-done\n\t})\n}\n"
+	dequeSrc               = "package deque\n\n// The ring buffer is the one of the queue, adapted from\n// github.com/eapache/queue:\n//    The MIT License (MIT)\n//    Copyright (c) 2014 Evan Huus\n\nvar nilKType KType\n\n// Deque represents a single instance of the double-ended queue data\n// structure. Elements are pushed and popped at both ends in O(1).\ntype Deque struct {\n\tbuf               []KType\n\thead, tail, count int\n\tminlen            int\n}\n\n// NewDeque constructs and returns a new Deque with an initial capacity.\nfunc NewDeque(capacity int) *Deque {\n\t// min capacity of 16\n\tif capacity < 16 {\n\t\tcapacity = 16\n\t}\n\treturn &Deque{buf: make([]KType, capacity), minlen: capacity}\n}\n\n// Len returns the number of elements currently stored in the deque.\nfunc (q *Deque) Len() int {\n\treturn q.count\n}\n\n// PushBack puts an element at the back of the deque.\nfunc (q *Deque) PushBack(elem KType) {\n\tif q.count == len(q.buf) {\n\t\tq.resize()\n\t}\n\n\tq.buf[q.tail] = elem\n\tq.tail = q.next(q.tail)\n\tq.count++\n}\n\n// PushFront puts an element at the front of the deque.\nfunc (q *Deque) PushFront(elem KType) {\n\tif q.count == len(q.buf) {\n\t\tq.resize()\n\t}\n\n\tq.head = q.prev(q.head)\n\tq.buf[q.head] = elem\n\tq.count++\n}\n\n// PeekFront returns the element at the front of the deque. This call panics\n// if the deque is empty.\nfunc (q *Deque) PeekFront() KType {\n\tif q.count <= 0 {\n\t\tpanic(\"deque: empty deque\")\n\t}\n\treturn q.buf[q.head]\n}\n\n// TryPeekFront is like PeekFront, but tells if there was an element instead\n// of panicking on an empty deque.\nfunc (q *Deque) TryPeekFront() (KType, bool) {\n\tif q.count == 0 {\n\t\treturn nilKType, false\n\t}\n\treturn q.buf[q.head], true\n}\n\n// PeekBack returns the element at the back of the deque. This call panics\n// if the deque is empty.\nfunc (q *Deque) PeekBack() KType {\n\tif q.count <= 0 {\n\t\tpanic(\"deque: empty deque\")\n\t}\n\treturn q.buf[q.prev(q.tail)]\n}\n\n// TryPeekBack is like PeekBack, but tells if there was an element instead of\n// panicking on an empty deque.\nfunc (q *Deque) TryPeekBack() (KType, bool) {\n\tif q.count == 0 {\n\t\treturn nilKType, false\n\t}\n\treturn q.buf[q.prev(q.tail)], true\n}\n\n// PopFront removes the element from the front of the deque. This call panics\n// if the deque is empty.\nfunc (q *Deque) PopFront() KType {\n\tif q.count <= 0 {\n\t\tpanic(\"deque: empty deque\")\n\t}\n\tv := q.buf[q.head]\n\t// set to nil to avoid keeping reference to objects\n\t// that would otherwise be garbage collected\n\tq.buf[q.head] = nilKType\n\tq.head = q.next(q.head)\n\tq.count--\n\tq.shrink()\n\treturn v\n}\n\n// TryPopFront is like PopFront, but tells if there was an element instead of\n// panicking on an empty deque.\nfunc (q *Deque) TryPopFront() (KType, bool) {\n\tif q.count == 0 {\n\t\treturn nilKType, false\n\t}\n\treturn q.PopFront(), true\n}\n\n// PopBack removes the element from the back of the deque. This call panics\n// if the deque is empty.\nfunc (q *Deque) PopBack() KType {\n\tif q.count <= 0 {\n\t\tpanic(\"deque: empty deque\")\n\t}\n\tq.tail = q.prev(q.tail)\n\tv := q.buf[q.tail]\n\tq.buf[q.tail] = nilKType\n\tq.count--\n\tq.shrink()\n\treturn v\n}\n\n// TryPopBack is like PopBack, but tells if there was an element instead of\n// panicking on an empty deque.\nfunc (q *Deque) TryPopBack() (KType, bool) {\n\tif q.count == 0 {\n\t\treturn nilKType, false\n\t}\n\treturn q.PopBack(), true\n}\n\n// Get returns the element at index i in the deque, the front being at index\n// 0. If the index is invalid, the call will panic.\nfunc (q *Deque) Get(i int) KType {\n\tif i >= q.count || i < 0 {\n\t\tpanic(\"deque: index out of range\")\n\t}\n\treturn q.buf[q.at(i)]\n}\n\n// Set replaces the element at index i in the deque by elem. If the index is\n// invalid, the call will panic.\nfunc (q *Deque) Set(i int, elem KType) {\n\tif i >= q.count || i < 0 {\n\t\tpanic(\"deque: index out of range\")\n\t}\n\tq.buf[q.at(i)] = elem\n}\n\n// Insert puts elem at index i in the deque, moving the elements after it one\n// index up. The index can be Len(), to insert at the back. If the index is\n// invalid, the call will panic. The complexity is O(min(i, n-i)) where\n// n == q.Len(), as the elements on the closest end are the ones moved.\nfunc (q *Deque) Insert(i int, elem KType) {\n\tif i > q.count || i < 0 {\n\t\tpanic(\"deque: index out of range\")\n\t}\n\tif q.count == len(q.buf) {\n\t\tq.resize()\n\t}\n\n\tif i < q.count/2 {\n\t\t// move the elements before i one step to the front\n\t\tq.head = q.prev(q.head)\n\t\tfor j := 0; j < i; j++ {\n\t\t\tq.buf[q.at(j)] = q.buf[q.at(j+1)]\n\t\t}\n\t} else {\n\t\t// move the elements from i one step to the back\n\t\tfor j := q.count; j > i; j-- {\n\t\t\tq.buf[q.at(j)] = q.buf[q.at(j-1)]\n\t\t}\n\t\tq.tail = q.next(q.tail)\n\t}\n\tq.count++\n\tq.buf[q.at(i)] = elem\n}\n\n// Remove removes the element at index i in the deque and returns it, moving\n// the elements after it one index down. If the index is invalid, the call\n// will panic. The complexity is O(min(i, n-i)) where n == q.Len(), as the\n// elements on the closest end are the ones moved.\nfunc (q *Deque) Remove(i int) KType {\n\tif i >= q.count || i < 0 {\n\t\tpanic(\"deque: index out of range\")\n\t}\n\tv := q.buf[q.at(i)]\n\n\tif i < q.count/2 {\n\t\t// move the elements before i one step to the back\n\t\tfor j := i; j > 0; j-- {\n\t\t\tq.buf[q.at(j)] = q.buf[q.at(j-1)]\n\t\t}\n\t\tq.buf[q.head] = nilKType\n\t\tq.head = q.next(q.head)\n\t} else {\n\t\t// move the elements after i one step to the front\n\t\tfor j := i; j < q.count-1; j++ {\n\t\t\tq.buf[q.at(j)] = q.buf[q.at(j+1)]\n\t\t}\n\t\tq.tail = q.prev(q.tail)\n\t\tq.buf[q.tail] = nilKType\n\t}\n\tq.count--\n\tq.shrink()\n\treturn v\n}\n\n// Rotate rotates the deque n steps to the back: the element at index i moves\n// to index (i+n) mod Len(), and the last n elements move to the front. A\n// negative n rotates the deque to the front. The complexity is\n// O(min(n, Len()-n)).\nfunc (q *Deque) Rotate(n int) {\n\tif q.count <= 1 {\n\t\treturn\n\t}\n\tn %= q.count\n\tif n < 0 {\n\t\tn += q.count\n\t}\n\tif n == 0 {\n\t\treturn\n\t}\n\tif q.count == len(q.buf) {\n\t\t// the buffer is full, the elements are already where they go\n\t\tq.head = (q.head - n + len(q.buf)) % len(q.buf)\n\t\tq.tail = q.head\n\t\treturn\n\t}\n\n\tif n <= q.count/2 {\n\t\t// move the last n elements to the front\n\t\tfor ; n > 0; n-- {\n\t\t\tq.tail = q.prev(q.tail)\n\t\t\tq.head = q.prev(q.head)\n\t\t\tq.buf[q.head] = q.buf[q.tail]\n\t\t\tq.buf[q.tail] = nilKType\n\t\t}\n\t\treturn\n\t}\n\t// move the first count-n elements to the back\n\tfor n = q.count - n; n > 0; n-- {\n\t\tq.buf[q.tail] = q.buf[q.head]\n\t\tq.buf[q.head] = nilKType\n\t\tq.head = q.next(q.head)\n\t\tq.tail = q.next(q.tail)\n\t}\n}\n\n// at is the position in the buffer of the element at index i.\nfunc (q *Deque) at(i int) int { return (q.head + i) % len(q.buf) }\n\nfunc (q *Deque) next(i int) int { return (i + 1) % len(q.buf) }\nfunc (q *Deque) prev(i int) int { return (i - 1 + len(q.buf)) % len(q.buf) }\n\n// shrink the buffer when it's mostly empty.\nfunc (q *Deque) shrink() {\n\tif len(q.buf) > q.minlen && q.count*4 <= len(q.buf) {\n\t\tq.resize()\n\t}\n}\n\nfunc (q *Deque) resize() {\n\tn := q.count * 2\n\tif n < q.minlen {\n\t\tn = q.minlen\n\t}\n\tnewBuf := make([]KType, n)\n\n\tif q.tail > q.head {\n\t\tcopy(newBuf, q.buf[q.head:q.tail])\n\t} else {\n\t\tcopy(newBuf, q.buf[q.head:len(q.buf)])\n\t\tcopy(newBuf[len(q.buf)-q.head:], q.buf[:q.tail])\n\t}\n\n\tq.head = 0\n\tq.tail = q.count % len(newBuf)\n\tq.buf = newBuf\n}\n"
+	dequeTestSrc           = "package deque\n\nimport (\n\t\"math/rand\"\n\t\"reflect\"\n\t\"testing\"\n)\n\n// The tests of this file are generated along with deques, when asked to. The\n// elements are generated by randomKType.\n\n// checkDeque verifies that the head, the tail and the count of the deque\n// agree, and that the buffer never shrinks below its minimum length.\nfunc checkDeque(t *testing.T, q *Deque) {\n\tif len(q.buf) < q.minlen {\n\t\tt.Fatalf(\"buffer of %d shrunk below %d\", len(q.buf), q.minlen)\n\t}\n\tif q.count < 0 || q.count > len(q.buf) {\n\t\tt.Fatalf(\"count %d out of a buffer of %d\", q.count, len(q.buf))\n\t}\n\tif q.head < 0 || q.head >= len(q.buf) {\n\t\tt.Fatalf(\"head %d out of a buffer of %d\", q.head, len(q.buf))\n\t}\n\tif want := (q.head + q.count) % len(q.buf); q.tail != want {\n\t\tt.Fatalf(\"head %d and count %d: want tail %d, got %d\", q.head, q.count, want, q.tail)\n\t}\n\t// the slots out of the deque don't hold on to old elements\n\tfor i := q.count; i < len(q.buf); i++ {\n\t\tif !reflect.DeepEqual(q.buf[q.at(i)], nilKType) {\n\t\t\tt.Fatalf(\"slot %d out of the deque holds %v\", q.at(i), q.buf[q.at(i)])\n\t\t}\n\t}\n}\n\n// checkDequeElems verifies that q holds the elements of ref, in order.\nfunc checkDequeElems(t *testing.T, q *Deque, ref []KType) {\n\tif q.Len() != len(ref) {\n\t\tt.Fatalf(\"want len %d, got %d\", len(ref), q.Len())\n\t}\n\tfor i, want := range ref {\n\t\tif got := q.Get(i); !reflect.DeepEqual(want, got) {\n\t\t\tt.Fatalf(\"get %d: want %v, got %v\", i, want, got)\n\t\t}\n\t}\n}\n\nfunc TestDequeMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tq := NewDeque(0)\n\tvar ref []KType\n\n\tfor i := 0; i < 5000; i++ {\n\t\t// favor pushes, then pops, so the buffer grows and shrinks\n\t\tpush := 6\n\t\tif i%2000 >= 1000 {\n\t\t\tpush = 4\n\t\t}\n\t\top := rnd.Intn(10)\n\t\tswitch {\n\t\tcase op < push:\n\t\t\tk := randomKType(rnd)\n\t\t\tswitch rnd.Intn(3) {\n\t\t\tcase 0:\n\t\t\t\tq.PushBack(k)\n\t\t\t\tref = append(ref, k)\n\t\t\tcase 1:\n\t\t\t\tq.PushFront(k)\n\t\t\t\tref = append([]KType{k}, ref...)\n\t\t\tdefault:\n\t\t\t\tat := rnd.Intn(len(ref) + 1)\n\t\t\t\tq.Insert(at, k)\n\t\t\t\tref = append(ref[:at], append([]KType{k}, ref[at:]...)...)\n\t\t\t}\n\t\tcase len(ref) == 0:\n\t\t\tcheckDequeEmpty(t, q)\n\t\tcase op < 9:\n\t\t\tvar want, got KType\n\t\t\tswitch rnd.Intn(3) {\n\t\t\tcase 0:\n\t\t\t\twant = ref[0]\n\t\t\t\tif peek := q.PeekFront(); !reflect.DeepEqual(want, peek) {\n\t\t\t\t\tt.Fatalf(\"peek front: want %v, got %v\", want, peek)\n\t\t\t\t}\n\t\t\t\tgot = q.PopFront()\n\t\t\t\tref = ref[1:]\n\t\t\tcase 1:\n\t\t\t\twant = ref[len(ref)-1]\n\t\t\t\tif peek := q.PeekBack(); !reflect.DeepEqual(want, peek) {\n\t\t\t\t\tt.Fatalf(\"peek back: want %v, got %v\", want, peek)\n\t\t\t\t}\n\t\t\t\tif peek, ok := q.TryPeekBack(); !ok || !reflect.DeepEqual(want, peek) {\n\t\t\t\t\tt.Fatalf(\"try peek back: want %v, true, got %v, %v\", want, peek, ok)\n\t\t\t\t}\n\t\t\t\tgot, _ = q.TryPopBack()\n\t\t\t\tref = ref[:len(ref)-1]\n\t\t\tdefault:\n\t\t\t\tat := rnd.Intn(len(ref))\n\t\t\t\twant = ref[at]\n\t\t\t\tgot = q.Remove(at)\n\t\t\t\tref = append(ref[:at], ref[at+1:]...)\n\t\t\t}\n\t\t\tif !reflect.DeepEqual(want, got) {\n\t\t\t\tt.Fatalf(\"pop: want %v, got %v\", want, got)\n\t\t\t}\n\t\tdefault:\n\t\t\tif rnd.Intn(2) == 0 {\n\t\t\t\tat, k := rnd.Intn(len(ref)), randomKType(rnd)\n\t\t\t\tq.Set(at, k)\n\t\t\t\tref[at] = k\n\t\t\t} else {\n\t\t\t\tn := rnd.Intn(2*len(ref)+1) - len(ref)\n\t\t\t\tq.Rotate(n)\n\t\t\t\tm := ((n % len(ref)) + len(ref)) % len(ref)\n\t\t\t\tref = append(append([]KType(nil), ref[len(ref)-m:]...), ref[:len(ref)-m]...)\n\t\t\t}\n\t\t}\n\t\tcheckDeque(t, q)\n\t\tif i%100 == 0 {\n\t\t\tcheckDequeElems(t, q, ref)\n\t\t}\n\t}\n\tcheckDequeElems(t, q, ref)\n}\n\nfunc TestDequeRotateFull(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tq := NewDeque(16)\n\tvar ref []KType\n\tfor i := 0; i < 16; i++ {\n\t\tk := randomKType(rnd)\n\t\tq.PushBack(k)\n\t\tref = append(ref, k)\n\t}\n\tfor _, n := range []int{1, -1, 5, -7, 16, 31} {\n\t\tq.Rotate(n)\n\t\tm := ((n % 16) + 16) % 16\n\t\tref = append(append([]KType(nil), ref[16-m:]...), ref[:16-m]...)\n\t\tcheckDeque(t, q)\n\t\tcheckDequeElems(t, q, ref)\n\t}\n}\n\n// checkDequeEmpty verifies that the empty deque q has nothing to peek, pop,\n// get or remove.\nfunc checkDequeEmpty(t *testing.T, q *Deque) {\n\tif q.Len() != 0 {\n\t\tt.Fatalf(\"want len 0, got %d\", q.Len())\n\t}\n\tif got, ok := q.TryPeekFront(); ok {\n\t\tt.Fatalf(\"try peek front: want nothing, got %v\", got)\n\t}\n\tif got, ok := q.TryPeekBack(); ok {\n\t\tt.Fatalf(\"try peek back: want nothing, got %v\", got)\n\t}\n\tif got, ok := q.TryPopFront(); ok {\n\t\tt.Fatalf(\"try pop front: want nothing, got %v\", got)\n\t}\n\tif got, ok := q.TryPopBack(); ok {\n\t\tt.Fatalf(\"try pop back: want nothing, got %v\", got)\n\t}\n\tq.Rotate(1)\n\tfor _, op := range []struct {\n\t\tname string\n\t\tf    func()\n\t}{\n\t\t{\"peek front\", func() { q.PeekFront() }},\n\t\t{\"peek back\", func() { q.PeekBack() }},\n\t\t{\"pop front\", func() { q.PopFront() }},\n\t\t{\"pop back\", func() { q.PopBack() }},\n\t\t{\"get\", func() { q.Get(0) }},\n\t\t{\"set\", func() { q.Set(0, nilKType) }},\n\t\t{\"remove\", func() { q.Remove(0) }},\n\t} {\n\t\tfunc() {\n\t\t\tdefer func() {\n\t\t\t\tif recover() == nil {\n\t\t\t\t\tt.Fatalf(\"%s: want a panic on an empty deque\", op.name)\n\t\t\t\t}\n\t\t\t}()\n\t\t\top.f()\n\t\t}()\n\t}\n\tcheckDeque(t, q)\n}\n\nfunc TestDequeEmpty(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tq := NewDeque(0)\n\tcheckDequeEmpty(t, q)\n\n\tk := randomKType(rnd)\n\tq.PushFront(k)\n\tif got, ok := q.TryPopBack(); !ok || !reflect.DeepEqual(k, got) {\n\t\tt.Fatalf(\"try pop back: want %v, true, got %v, %v\", k, got, ok)\n\t}\n\tcheckDequeEmpty(t, q)\n\tq.PushBack(k)\n\tif got, ok := q.TryPeekFront(); !ok || !reflect.DeepEqual(k, got) {\n\t\tt.Fatalf(\"try peek front: want %v, true, got %v, %v\", k, got, ok)\n\t}\n\tif got, ok := q.TryPopFront(); !ok || !reflect.DeepEqual(k, got) {\n\t\tt.Fatalf(\"try pop front: want %v, true, got %v, %v\", k, got, ok)\n\t}\n\tcheckDequeEmpty(t, q)\n\n\t// empty after growing, wrapping around and shrinking\n\tfor i := 0; i < 100; i++ {\n\t\tq.PushFront(randomKType(rnd))\n\t}\n\tfor q.Len() > 0 {\n\t\tq.Remove(q.Len() / 2)\n\t}\n\tcheckDequeEmpty(t, q)\n}\n"
+	dequeBenchSrc          = "package deque\n\nimport (\n\t\"container/list\"\n\t\"math/rand\"\n\t\"strconv\"\n\t\"testing\"\n)\n\n// The benchmarks of this file are generated along with deques, when asked\n// to. The elements are generated by benchKType.\n\n// benchDequeSizes are the numbers of elements in the benchmarked deques.\nvar benchDequeSizes = []int{100, 10000, 1000000}\n\n// benchDeque runs bench for each size, with that many random elements. The\n// elements are the same from one benchmark to the other.\nfunc benchDeque(b *testing.B, bench func(b *testing.B, elems []KType)) {\n\tfor _, n := range benchDequeSizes {\n\t\tn := n\n\t\tvar elems []KType\n\t\tb.Run(strconv.Itoa(n), func(b *testing.B) {\n\t\t\t// once for all the runs of the benchmark, and only if it's run\n\t\t\tif elems == nil {\n\t\t\t\trnd := rand.New(rand.NewSource(int64(n)))\n\t\t\t\telems = make([]KType, n)\n\t\t\t\tfor i := range elems {\n\t\t\t\t\telems[i] = benchKType(rnd)\n\t\t\t\t}\n\t\t\t}\n\t\t\tb.ResetTimer()\n\t\t\tbench(b, elems)\n\t\t})\n\t}\n}\n\n// fillDeque returns a deque of capacity c holding elems.\nfunc fillDeque(c int, elems []KType) *Deque {\n\tq := NewDeque(c)\n\tfor _, e := range elems {\n\t\tq.PushBack(e)\n\t}\n\treturn q\n}\n\nfunc BenchmarkDequePushBack(b *testing.B) {\n\tbenchDeque(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := fillDeque(2*n, elems)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.PushBack(elems[i%n])\n\t\t\tif q.count == 2*n {\n\t\t\t\t// drop the last n elements, without resizing\n\t\t\t\tq.count = n\n\t\t\t\tq.tail = (q.head + n) % len(q.buf)\n\t\t\t}\n\t\t}\n\t})\n}\n\nfunc BenchmarkDequePushFront(b *testing.B) {\n\tbenchDeque(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := fillDeque(2*n, elems)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.PushFront(elems[i%n])\n\t\t\tif q.count == 2*n {\n\t\t\t\t// drop the first n elements, without resizing\n\t\t\t\tq.count = n\n\t\t\t\tq.head = (q.tail - n + len(q.buf)) % len(q.buf)\n\t\t\t}\n\t\t}\n\t})\n}\n\nfunc BenchmarkDequePopFront(b *testing.B) {\n\tbenchDeque(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\t// a full deque at its minimum capacity doesn't resize when popped\n\t\tq := fillDeque(n, elems)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.PopFront()\n\t\t\tif q.count == 0 {\n\t\t\t\tcopy(q.buf, elems)\n\t\t\t\tq.head, q.tail, q.count = 0, n%len(q.buf), n\n\t\t\t}\n\t\t}\n\t})\n}\n\nfunc BenchmarkDequePopBack(b *testing.B) {\n\tbenchDeque(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := fillDeque(n, elems)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.PopBack()\n\t\t\tif q.count == 0 {\n\t\t\t\tcopy(q.buf, elems)\n\t\t\t\tq.head, q.tail, q.count = 0, n%len(q.buf), n\n\t\t\t}\n\t\t}\n\t})\n}\n\nfunc BenchmarkDequeGet(b *testing.B) {\n\tbenchDeque(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := fillDeque(n, elems)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.Get(i % n)\n\t\t}\n\t})\n}\n\n// BenchmarkDequeInsertRemove inserts and removes elements at every index, so\n// that half the elements move on average.\nfunc BenchmarkDequeInsertRemove(b *testing.B) {\n\tbenchDeque(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := fillDeque(2*n, elems)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tat := i % n\n\t\t\tq.Insert(at, elems[at])\n\t\t\tq.Remove(at)\n\t\t}\n\t})\n}\n\nfunc BenchmarkDequeRotate(b *testing.B) {\n\tbenchDeque(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := fillDeque(2*n, elems)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.Rotate(i % n)\n\t\t}\n\t})\n}\n\n// BenchmarkDequePushFrontPopBack is to be compared with\n// BenchmarkDequePushFrontPopBackContainer.\nfunc BenchmarkDequePushFrontPopBack(b *testing.B) {\n\tbenchDeque(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\t// room to push without resizing\n\t\tq := fillDeque(2*n, elems)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.PushFront(elems[i%n])\n\t\t\tq.PopBack()\n\t\t}\n\t})\n}\n\n// BenchmarkDequePushFrontPopBackContainer holds the elements as interface{}\n// in a container/list.\nfunc BenchmarkDequePushFrontPopBackContainer(b *testing.B) {\n\tbenchDeque(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tl := list.New()\n\t\tfor _, e := range elems {\n\t\t\tl.PushBack(e)\n\t\t}\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tl.PushFront(elems[i%n])\n\t\t\t_ = l.Remove(l.Back()).(KType)\n\t\t}\n\t})\n}\n"
+	spscQueueSrc           = "package ringq\n\nimport \"sync/atomic\"\n\n// The ring buffer is laid out like the one of the queue, except that the\n// head and the tail count the elements popped and pushed since the start, so\n// that each is only written by one side. The capacity is a power of two, for\n// the positions in the buffer to be masks of the counts.\n\n// SPSCQueue represents a single instance of a queue data structure, with a\n// fixed capacity, shared without locks between a single producer pushing\n// elements and a single consumer popping them, each in its own goroutine.\ntype SPSCQueue struct {\n\t// head is only written by the consumer\n\thead uintptr\n\t_    [64]byte // on its own cache line\n\t// tail is only written by the producer\n\ttail uintptr\n\t_    [64]byte\n\tbuf  []KType\n\tmask uintptr\n}\n\n// NewSPSCQueue constructs and returns a new SPSCQueue holding at most\n// capacity elements, rounded up to a power of two, for which the memory is\n// allocated at once. This call panics if the capacity isn't positive.\nfunc NewSPSCQueue(capacity int) *SPSCQueue {\n\tif capacity <= 0 {\n\t\tpanic(\"ringq: capacity must be positive\")\n\t}\n\tn := 1\n\tfor n < capacity {\n\t\tn *= 2\n\t}\n\treturn &SPSCQueue{buf: make([]KType, n), mask: uintptr(n - 1)}\n}\n\n// Len returns the number of elements stored in the queue, which may have\n// changed by the time it returns.\nfunc (q *SPSCQueue) Len() int {\n\thead := atomic.LoadUintptr(&q.head)\n\treturn int(atomic.LoadUintptr(&q.tail) - head)\n}\n\n// Cap returns the maximum number of elements the queue can hold.\nfunc (q *SPSCQueue) Cap() int {\n\treturn len(q.buf)\n}\n\n// Push puts an element on the end of the queue, and tells if it did, which\n// it doesn't if the queue is full. It must only be called by the producer.\nfunc (q *SPSCQueue) Push(elem KType) bool {\n\ttail := q.tail\n\tif tail-atomic.LoadUintptr(&q.head) == uintptr(len(q.buf)) {\n\t\treturn false\n\t}\n\tq.buf[tail&q.mask] = elem\n\t// publishes the element to the consumer\n\tatomic.StoreUintptr(&q.tail, tail+1)\n\treturn true\n}\n\n// TryPop removes the element from the front of the queue, and tells if there\n// was one. It must only be called by the consumer.\nfunc (q *SPSCQueue) TryPop() (k KType, ok bool) {\n\thead := q.head\n\tif head == atomic.LoadUintptr(&q.tail) {\n\t\treturn k, false\n\t}\n\tk = q.buf[head&q.mask]\n\t// set to the zero value to avoid keeping reference to objects\n\t// that would otherwise be garbage collected\n\tvar zero KType\n\tq.buf[head&q.mask] = zero\n\t// hands the slot back to the producer\n\tatomic.StoreUintptr(&q.head, head+1)\n\treturn k, true\n}\n"
+	spscQueueTestSrc       = "package ringq\n\nimport (\n\t\"math/rand\"\n\t\"reflect\"\n\t\"runtime\"\n\t\"testing\"\n)\n\n// The tests of this file are generated along with SPSC queues, when asked to.\n// The elements are generated by randomKType. Run them with -race.\n\n// checkSPSCQueue verifies that the queue holds at most its capacity, and\n// that the slots out of the queue are cleared. It must not be called while\n// the queue is used.\nfunc checkSPSCQueue(t *testing.T, q *SPSCQueue) {\n\tif n := q.tail - q.head; n > uintptr(len(q.buf)) {\n\t\tt.Fatalf(\"head %d and tail %d out of a buffer of %d\", q.head, q.tail, len(q.buf))\n\t}\n\tif int(q.mask) != len(q.buf)-1 || len(q.buf)&int(q.mask) != 0 {\n\t\tt.Fatalf(\"mask %#x of a buffer of %d\", q.mask, len(q.buf))\n\t}\n\tvar zero KType\n\tfor pos := q.tail; pos != q.head+uintptr(len(q.buf)); pos++ {\n\t\tif at := pos & q.mask; !reflect.DeepEqual(q.buf[at], zero) {\n\t\t\tt.Fatalf(\"slot %d out of the queue holds %v\", at, q.buf[at])\n\t\t}\n\t}\n}\n\nfunc TestSPSCQueueMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tq := NewSPSCQueue(50)\n\tvar ref []KType\n\n\tfor i := 0; i < 5000; i++ {\n\t\t// favor pushes, then pops, so the queue fills up and empties\n\t\tpush := 6\n\t\tif i%2000 >= 1000 {\n\t\t\tpush = 4\n\t\t}\n\t\tif rnd.Intn(10) < push {\n\t\t\tk := randomKType(rnd)\n\t\t\tfull := len(ref) == q.Cap()\n\t\t\tif pushed := q.Push(k); pushed == full {\n\t\t\t\tt.Fatalf(\"push on a queue of %d/%d: want %v, got %v\", len(ref), q.Cap(), !full, pushed)\n\t\t\t}\n\t\t\tif !full {\n\t\t\t\tref = append(ref, k)\n\t\t\t}\n\t\t} else if len(ref) != 0 {\n\t\t\tif got, ok := q.TryPop(); !ok || !reflect.DeepEqual(ref[0], got) {\n\t\t\t\tt.Fatalf(\"try pop: want %v, true, got %v, %v\", ref[0], got, ok)\n\t\t\t}\n\t\t\tref = ref[1:]\n\t\t} else if got, ok := q.TryPop(); ok {\n\t\t\tt.Fatalf(\"try pop: want nothing, got %v\", got)\n\t\t}\n\t\tcheckSPSCQueue(t, q)\n\t\tif q.Len() != len(ref) {\n\t\t\tt.Fatalf(\"want len %d, got %d\", len(ref), q.Len())\n\t\t}\n\t}\n}\n\n// TestSPSCQueueProducerConsumer has a producer and a consumer pushing and\n// popping through a small queue, many times around it, and verifies that\n// the elements are popped in the order they're pushed.\nfunc TestSPSCQueueProducerConsumer(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\telems := make([]KType, 20000)\n\tfor i := range elems {\n\t\telems[i] = randomKType(rnd)\n\t}\n\n\tq := NewSPSCQueue(8)\n\tdone := make(chan struct{})\n\tgo func() {\n\t\tdefer close(done)\n\t\tfor _, k := range elems {\n\t\t\tfor !q.Push(k) {\n\t\t\t\truntime.Gosched()\n\t\t\t}\n\t\t}\n\t}()\n\tfor i, want := range elems {\n\t\tgot, ok := q.TryPop()\n\t\tfor !ok {\n\t\t\truntime.Gosched()\n\t\t\tgot, ok = q.TryPop()\n\t\t}\n\t\tif !reflect.DeepEqual(want, got) {\n\t\t\tt.Fatalf(\"pop %d: want %v, got %v\", i, want, got)\n\t\t}\n\t}\n\t<-done\n\tcheckSPSCQueue(t, q)\n\tif q.Len() != 0 {\n\t\tt.Errorf(\"want len 0, got %d\", q.Len())\n\t}\n}\n"
+	spscQueueBenchSrc      = "package ringq\n\nimport (\n\t\"math/rand\"\n\t\"runtime\"\n\t\"strconv\"\n\t\"testing\"\n)\n\n// The benchmarks of this file are generated along with SPSC queues, when\n// asked to. The elements are generated by benchKType.\n\n// benchSPSCQueueSizes are the capacities of the benchmarked queues.\nvar benchSPSCQueueSizes = []int{100, 10000, 1000000}\n\n// benchSPSCQueue runs bench for each size, with that many random elements.\n// The elements are the same from one benchmark to the other.\nfunc benchSPSCQueue(b *testing.B, bench func(b *testing.B, elems []KType)) {\n\tfor _, n := range benchSPSCQueueSizes {\n\t\tn := n\n\t\tvar elems []KType\n\t\tb.Run(strconv.Itoa(n), func(b *testing.B) {\n\t\t\t// once for all the runs of the benchmark, and only if it's run\n\t\t\tif elems == nil {\n\t\t\t\trnd := rand.New(rand.NewSource(int64(n)))\n\t\t\t\telems = make([]KType, n)\n\t\t\t\tfor i := range elems {\n\t\t\t\t\telems[i] = benchKType(rnd)\n\t\t\t\t}\n\t\t\t}\n\t\t\tb.ResetTimer()\n\t\t\tbench(b, elems)\n\t\t})\n\t}\n}\n\nfunc BenchmarkSPSCQueuePushPop(b *testing.B) {\n\tbenchSPSCQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := NewSPSCQueue(n)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.Push(elems[i%n])\n\t\t\tq.TryPop()\n\t\t}\n\t})\n}\n\n// BenchmarkSPSCQueueProducerConsumer is to be compared with\n// BenchmarkSPSCQueueProducerConsumerChan.\nfunc BenchmarkSPSCQueueProducerConsumer(b *testing.B) {\n\tbenchSPSCQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := NewSPSCQueue(n)\n\t\tb.ResetTimer()\n\t\tdone := make(chan struct{})\n\t\tgo func() {\n\t\t\tdefer close(done)\n\t\t\tfor i := 0; i < b.N; i++ {\n\t\t\t\tfor _, ok := q.TryPop(); !ok; _, ok = q.TryPop() {\n\t\t\t\t\truntime.Gosched()\n\t\t\t\t}\n\t\t\t}\n\t\t}()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tfor !q.Push(elems[i%n]) {\n\t\t\t\truntime.Gosched()\n\t\t\t}\n\t\t}\n\t\t<-done\n\t})\n}\n\n// BenchmarkSPSCQueueProducerConsumerChan sends the elements through a\n// channel of the same capacity.\nfunc BenchmarkSPSCQueueProducerConsumerChan(b *testing.B) {\n\tbenchSPSCQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tc := make(chan KType, n)\n\t\tb.ResetTimer()\n\t\tdone := make(chan struct{})\n\t\tgo func() {\n\t\t\tdefer close(done)\n\t\t\tfor i := 0; i < b.N; i++ {\n\t\t\t\t<-c\n\t\t\t}\n\t\t}()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tc <- elems[i%n]\n\t\t}\n\t\t<-done\n\t})\n}\n"
+	mpmcQueueSrc           = "package ringq\n\nimport \"sync/atomic\"\n\n// The implementation is Dmitry Vyukov's bounded MPMC queue: each slot of the\n// ring buffer has a sequence number telling whether it's ready to be pushed\n// to or popped from for a given lap around the buffer, and producers and\n// consumers claim their slot by moving the tail or the head with a CAS.\n\n// MPMCQueue represents a single instance of a queue data structure, with a\n// fixed capacity, shared without locks between any number of goroutines\n// pushing and popping elements.\ntype MPMCQueue struct {\n\t// head is moved by the consumers\n\thead uintptr\n\t_    [64]byte // on its own cache line\n\t// tail is moved by the producers\n\ttail uintptr\n\t_    [64]byte\n\tbuf  []mpmcSlot\n\tmask uintptr\n}\n\n// mpmcSlot is a slot of the ring buffer. Its sequence number is the tail\n// pushing to it while it's free, and that tail plus one once the element is\n// there.\ntype mpmcSlot struct {\n\tseq  uintptr\n\telem KType\n}\n\n// NewMPMCQueue constructs and returns a new MPMCQueue holding at most\n// capacity elements, rounded up to a power of two, for which the memory is\n// allocated at once. This call panics if the capacity isn't positive.\nfunc NewMPMCQueue(capacity int) *MPMCQueue {\n\tif capacity <= 0 {\n\t\tpanic(\"ringq: capacity must be positive\")\n\t}\n\tn := 1\n\tfor n < capacity {\n\t\tn *= 2\n\t}\n\tq := &MPMCQueue{buf: make([]mpmcSlot, n), mask: uintptr(n - 1)}\n\tfor i := range q.buf {\n\t\tq.buf[i].seq = uintptr(i)\n\t}\n\treturn q\n}\n\n// Len returns the number of elements stored in the queue, which may have\n// changed by the time it returns.\nfunc (q *MPMCQueue) Len() int {\n\thead := atomic.LoadUintptr(&q.head)\n\tn := int(atomic.LoadUintptr(&q.tail) - head)\n\t// the head can move past the tail read before it\n\tif n < 0 {\n\t\treturn 0\n\t}\n\treturn n\n}\n\n// Cap returns the maximum number of elements the queue can hold.\nfunc (q *MPMCQueue) Cap() int {\n\treturn len(q.buf)\n}\n\n// Push puts an element on the end of the queue, and tells if it did, which\n// it doesn't if the queue is full.\nfunc (q *MPMCQueue) Push(elem KType) bool {\n\ttail := atomic.LoadUintptr(&q.tail)\n\tfor {\n\t\tslot := &q.buf[tail&q.mask]\n\t\tswitch seq := atomic.LoadUintptr(&slot.seq); {\n\t\tcase seq == tail:\n\t\t\t// free for this lap, unless another producer claims it first\n\t\t\tif atomic.CompareAndSwapUintptr(&q.tail, tail, tail+1) {\n\t\t\t\tslot.elem = elem\n\t\t\t\tatomic.StoreUintptr(&slot.seq, tail+1)\n\t\t\t\treturn true\n\t\t\t}\n\t\tcase int(seq-tail) < 0:\n\t\t\t// still holds the element of the previous lap\n\t\t\treturn false\n\t\t}\n\t\ttail = atomic.LoadUintptr(&q.tail)\n\t}\n}\n\n// TryPop removes the element from the front of the queue, and tells if there\n// was one.\nfunc (q *MPMCQueue) TryPop() (k KType, ok bool) {\n\thead := atomic.LoadUintptr(&q.head)\n\tfor {\n\t\tslot := &q.buf[head&q.mask]\n\t\tswitch seq := atomic.LoadUintptr(&slot.seq); {\n\t\tcase seq == head+1:\n\t\t\t// pushed to, unless another consumer claims it first\n\t\t\tif atomic.CompareAndSwapUintptr(&q.head, head, head+1) {\n\t\t\t\tk = slot.elem\n\t\t\t\t// set to the zero value to avoid keeping reference to objects\n\t\t\t\t// that would otherwise be garbage collected\n\t\t\t\tvar zero KType\n\t\t\t\tslot.elem = zero\n\t\t\t\t// free for the next lap\n\t\t\t\tatomic.StoreUintptr(&slot.seq, head+q.mask+1)\n\t\t\t\treturn k, true\n\t\t\t}\n\t\tcase int(seq-(head+1)) < 0:\n\t\t\t// not pushed to yet\n\t\t\treturn k, false\n\t\t}\n\t\thead = atomic.LoadUintptr(&q.head)\n\t}\n}\n"
+	mpmcQueueTestSrc       = "package ringq\n\nimport (\n\t\"fmt\"\n\t\"math/rand\"\n\t\"reflect\"\n\t\"runtime\"\n\t\"sync\"\n\t\"testing\"\n)\n\n// The tests of this file are generated along with MPMC queues, when asked to.\n// The elements are generated by randomKType. Run them with -race.\n\n// checkMPMCQueue verifies that the queue holds at most its capacity, that the\n// sequence numbers of the slots agree with the head and the tail, and that\n// the slots out of the queue are cleared. It must not be called while the\n// queue is used.\nfunc checkMPMCQueue(t *testing.T, q *MPMCQueue) {\n\tif n := q.tail - q.head; n > uintptr(len(q.buf)) {\n\t\tt.Fatalf(\"head %d and tail %d out of a buffer of %d\", q.head, q.tail, len(q.buf))\n\t}\n\tif int(q.mask) != len(q.buf)-1 || len(q.buf)&int(q.mask) != 0 {\n\t\tt.Fatalf(\"mask %#x of a buffer of %d\", q.mask, len(q.buf))\n\t}\n\tfor pos := q.head; pos != q.tail; pos++ {\n\t\tif slot := q.buf[pos&q.mask]; slot.seq != pos+1 {\n\t\t\tt.Fatalf(\"slot %d of the queue: want sequence %d, got %d\", pos&q.mask, pos+1, slot.seq)\n\t\t}\n\t}\n\tvar zero KType\n\tfor pos := q.tail; pos != q.head+uintptr(len(q.buf)); pos++ {\n\t\tslot := q.buf[pos&q.mask]\n\t\tif slot.seq != pos {\n\t\t\tt.Fatalf(\"slot %d out of the queue: want sequence %d, got %d\", pos&q.mask, pos, slot.seq)\n\t\t}\n\t\tif !reflect.DeepEqual(slot.elem, zero) {\n\t\t\tt.Fatalf(\"slot %d out of the queue holds %v\", pos&q.mask, slot.elem)\n\t\t}\n\t}\n}\n\nfunc TestMPMCQueueMatchesReference(t *testing.T) {\n\trnd := rand.New(rand.NewSource(42))\n\tq := NewMPMCQueue(50)\n\tvar ref []KType\n\n\tfor i := 0; i < 5000; i++ {\n\t\t// favor pushes, then pops, so the queue fills up and empties\n\t\tpush := 6\n\t\tif i%2000 >= 1000 {\n\t\t\tpush = 4\n\t\t}\n\t\tif rnd.Intn(10) < push {\n\t\t\tk := randomKType(rnd)\n\t\t\tfull := len(ref) == q.Cap()\n\t\t\tif pushed := q.Push(k); pushed == full {\n\t\t\t\tt.Fatalf(\"push on a queue of %d/%d: want %v, got %v\", len(ref), q.Cap(), !full, pushed)\n\t\t\t}\n\t\t\tif !full {\n\t\t\t\tref = append(ref, k)\n\t\t\t}\n\t\t} else if len(ref) != 0 {\n\t\t\tif got, ok := q.TryPop(); !ok || !reflect.DeepEqual(ref[0], got) {\n\t\t\t\tt.Fatalf(\"try pop: want %v, true, got %v, %v\", ref[0], got, ok)\n\t\t\t}\n\t\t\tref = ref[1:]\n\t\t} else if got, ok := q.TryPop(); ok {\n\t\t\tt.Fatalf(\"try pop: want nothing, got %v\", got)\n\t\t}\n\t\tcheckMPMCQueue(t, q)\n\t\tif q.Len() != len(ref) {\n\t\t\tt.Fatalf(\"want len %d, got %d\", len(ref), q.Len())\n\t\t}\n\t}\n}\n\n// TestMPMCQueueProducersConsumers has goroutines pushing and popping through\n// a small queue, many times around it, and verifies that every element\n// pushed is popped once.\nfunc TestMPMCQueueProducersConsumers(t *testing.T) {\n\tconst producers, consumers, perProducer = 4, 4, 5000\n\trnd := rand.New(rand.NewSource(42))\n\telems := make([][]KType, producers)\n\tfor i := range elems {\n\t\telems[i] = make([]KType, perProducer)\n\t\tfor j := range elems[i] {\n\t\t\telems[i][j] = randomKType(rnd)\n\t\t}\n\t}\n\n\tq := NewMPMCQueue(8)\n\tvar wg sync.WaitGroup\n\tfor _, e := range elems {\n\t\twg.Add(1)\n\t\tgo func(e []KType) {\n\t\t\tdefer wg.Done()\n\t\t\tfor _, k := range e {\n\t\t\t\tfor !q.Push(k) {\n\t\t\t\t\truntime.Gosched()\n\t\t\t\t}\n\t\t\t}\n\t\t}(e)\n\t}\n\tpopped := make([][]KType, consumers)\n\tfor i := range popped {\n\t\twg.Add(1)\n\t\tgo func(i int) {\n\t\t\tdefer wg.Done()\n\t\t\tfor j := 0; j < producers*perProducer/consumers; j++ {\n\t\t\t\tk, ok := q.TryPop()\n\t\t\t\tfor !ok {\n\t\t\t\t\truntime.Gosched()\n\t\t\t\t\tk, ok = q.TryPop()\n\t\t\t\t}\n\t\t\t\tpopped[i] = append(popped[i], k)\n\t\t\t}\n\t\t}(i)\n\t}\n\twg.Wait()\n\tcheckMPMCQueue(t, q)\n\n\t// the elements of any type are told apart by how they're printed\n\tcount := make(map[string]int)\n\tfor _, e := range elems {\n\t\tfor _, k := range e {\n\t\t\tcount[fmt.Sprintf(\"%#v\", k)]++\n\t\t}\n\t}\n\tfor _, p := range popped {\n\t\tfor _, k := range p {\n\t\t\tcount[fmt.Sprintf(\"%#v\", k)]--\n\t\t}\n\t}\n\tfor k, n := range count {\n\t\tif n != 0 {\n\t\t\tt.Errorf(\"%s: pushed %d more times than popped\", k, n)\n\t\t}\n\t}\n\tif q.Len() != 0 {\n\t\tt.Errorf(\"want len 0, got %d\", q.Len())\n\t}\n}\n"
+	mpmcQueueBenchSrc      = "package ringq\n\nimport (\n\t\"math/rand\"\n\t\"runtime\"\n\t\"strconv\"\n\t\"sync\"\n\t\"testing\"\n)\n\n// The benchmarks of this file are generated along with MPMC queues, when\n// asked to. The elements are generated by benchKType.\n\n// benchMPMCQueueSizes are the capacities of the benchmarked queues.\nvar benchMPMCQueueSizes = []int{100, 10000, 1000000}\n\n// benchMPMCQueue runs bench for each size, with that many random elements.\n// The elements are the same from one benchmark to the other.\nfunc benchMPMCQueue(b *testing.B, bench func(b *testing.B, elems []KType)) {\n\tfor _, n := range benchMPMCQueueSizes {\n\t\tn := n\n\t\tvar elems []KType\n\t\tb.Run(strconv.Itoa(n), func(b *testing.B) {\n\t\t\t// once for all the runs of the benchmark, and only if it's run\n\t\t\tif elems == nil {\n\t\t\t\trnd := rand.New(rand.NewSource(int64(n)))\n\t\t\t\telems = make([]KType, n)\n\t\t\t\tfor i := range elems {\n\t\t\t\t\telems[i] = benchKType(rnd)\n\t\t\t\t}\n\t\t\t}\n\t\t\tb.ResetTimer()\n\t\t\tbench(b, elems)\n\t\t})\n\t}\n}\n\nfunc BenchmarkMPMCQueuePushPop(b *testing.B) {\n\tbenchMPMCQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := NewMPMCQueue(n)\n\t\tb.ResetTimer()\n\t\tfor i := 0; i < b.N; i++ {\n\t\t\tq.Push(elems[i%n])\n\t\t\tq.TryPop()\n\t\t}\n\t})\n}\n\n// BenchmarkMPMCQueueProducersConsumers is to be compared with\n// BenchmarkMPMCQueueProducersConsumersChan. The elements are pushed and\n// popped by as many goroutines as GOMAXPROCS, half of them each.\nfunc BenchmarkMPMCQueueProducersConsumers(b *testing.B) {\n\tbenchMPMCQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tq := NewMPMCQueue(n)\n\t\tb.ResetTimer()\n\t\tworkers := runtime.GOMAXPROCS(0)/2 + 1\n\t\tvar wg sync.WaitGroup\n\t\tfor w := 0; w < workers; w++ {\n\t\t\twg.Add(2)\n\t\t\tgo func(w int) {\n\t\t\t\tdefer wg.Done()\n\t\t\t\tfor i := w; i < b.N; i += workers {\n\t\t\t\t\tfor _, ok := q.TryPop(); !ok; _, ok = q.TryPop() {\n\t\t\t\t\t\truntime.Gosched()\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}(w)\n\t\t\tgo func(w int) {\n\t\t\t\tdefer wg.Done()\n\t\t\t\tfor i := w; i < b.N; i += workers {\n\t\t\t\t\tfor !q.Push(elems[i%n]) {\n\t\t\t\t\t\truntime.Gosched()\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}(w)\n\t\t}\n\t\twg.Wait()\n\t})\n}\n\n// BenchmarkMPMCQueueProducersConsumersChan sends the elements through a\n// channel of the same capacity.\nfunc BenchmarkMPMCQueueProducersConsumersChan(b *testing.B) {\n\tbenchMPMCQueue(b, func(b *testing.B, elems []KType) {\n\t\tn := len(elems)\n\t\tc := make(chan KType, n)\n\t\tb.ResetTimer()\n\t\tworkers := runtime.GOMAXPROCS(0)/2 + 1\n\t\tvar wg sync.WaitGroup\n\t\tfor w := 0; w < workers; w++ {\n\t\t\twg.Add(2)\n\t\t\tgo func(w int) {\n\t\t\t\tdefer wg.Done()\n\t\t\t\tfor i := w; i < b.N; i += workers {\n\t\t\t\t\t<-c\n\t\t\t\t}\n\t\t\t}(w)\n\t\t\tgo func(w int) {\n\t\t\t\tdefer wg.Done()\n\t\t\t\tfor i := w; i < b.N; i += workers {\n\t\t\t\t\tc <- elems[i%n]\n\t\t\t\t}\n\t\t\t}(w)\n\t\t}\n\t\twg.Wait()\n\t})\n}\n"
 )
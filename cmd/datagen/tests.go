@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/codegangsta/cli"
+)
+
+var (
+	testsFlag = cli.BoolFlag{
+		Name:  "tests",
+		Usage: "also generate tests of the datastructure, in a _test.go file next to the file given with -o",
+	}
+	benchFlag = cli.BoolFlag{
+		Name:  "bench",
+		Usage: "also generate benchmarks of the datastructure, in a _bench_test.go file next to the file given with -o",
+	}
+	genFlag = cli.StringFlag{
+		Name:  "gen",
+		Usage: "func(*rand.Rand) KEY generating keys for the tests and benchmarks, needed when KEY isn't a builtin type",
+	}
+	genValFlag = cli.StringFlag{
+		Name:  "genval",
+		Usage: "func(*rand.Rand) VAL generating values for the tests and benchmarks, instead of zero values when VAL isn't a builtin type",
+	}
+	genIDFlag = cli.StringFlag{
+		Name:  "genid",
+		Usage: "func(*rand.Rand) ID generating ids for the tests and benchmarks, needed when ID isn't a builtin type",
+	}
+)
+
+// testFlags are understood by the commands generating tests and benchmarks
+// along with the datastructures. genValFlag and genIDFlag are left to those
+// with values and ids.
+var testFlags = []cli.Flag{testsFlag, benchFlag, genFlag}
+
+// sampled is a type held by a datastructure, whose random values the tests
+// and benchmarks get from a func named after its placeholder, such as
+// randomKType for KType.
+type sampled struct {
+	placeholder string
+	typ         *typeExpr
+	// flag gives the func for types that aren't builtin, which is needed if
+	// required is true.
+	flag     cli.StringFlag
+	required bool
+	// what the values are, naming the generated funcs.
+	what string
+}
+
+func sampledKeys(ktype *typeExpr) sampled { return sampled{"KType", ktype, genFlag, true, "Key"} }
+func sampledVals(vtype *typeExpr) sampled { return sampled{"VType", vtype, genValFlag, false, "Val"} }
+func sampledIDs(idtype *typeExpr) sampled { return sampled{"IDType", idtype, genIDFlag, true, "ID"} }
+
+// testTemplate returns the template of the tests of the datastructure
+// instantiated by tmpl, or nil if no tests were asked for. src is the
+// template of the tests, whose random values of each type come from a func
+// named after its placeholder (randomKType, randomVType). For builtin types
+// these funcs are generated, otherwise they're given with -gen, -genval or
+// -genid.
+func testTemplate(ctx *cli.Context, tmpl *template, src, typeName string, types ...sampled) *template {
+	return companionTemplate(ctx, testsFlag, "random", false, tmpl, src, typeName, types)
+}
+
+// benchTemplate is like testTemplate, for the benchmarks. Their random values
+// come from benchKType and benchVType, which rarely repeat a value when
+// generated.
+func benchTemplate(ctx *cli.Context, tmpl *template, src, typeName string, types ...sampled) *template {
+	return companionTemplate(ctx, benchFlag, "bench", true, tmpl, src, typeName, types)
+}
+
+// companionTemplate returns the template of a file asked for with flag f,
+// written next to the datastructure. Its random values come from funcs named
+// after prefix, which are wide samplers if wide is true.
+func companionTemplate(ctx *cli.Context, f cli.BoolFlag, prefix string, wide bool,
+	tmpl *template, src, typeName string, types []sampled) *template {
+
+	if !ctx.Bool(f.Name) {
+		return nil
+	}
+	if ctx.String(outFlag.Name) == "" {
+		log.Fatalf("-%s needs a file given with -%s, the generated file is written next to it", f.Name, outFlag.Name)
+	}
+	if tmpl.compareField {
+		log.Fatalf("-%s can't be used with -%s, there's no func to give to the constructor",
+			f.Name, compareFieldFlag.Name)
+	}
+
+	samplers := make(map[string]*sampler, len(types))
+	for _, s := range types {
+		samplers[prefix+s.placeholder] = newSampler(ctx.String(s.flag.Name), s, prefix+typeName+s.what, wide)
+	}
+	return tmpl.tests(src, typeName, samplers)
+}
+
+// tests returns the template of tests of the datastructure instantiated by
+// t, from their template src. The tests are written next to the
+// datastructure, in the same package. Their declarations are renamed by
+// replacing t.name with typeName, so that tests of several datastructures
+// can share a package. samplers maps the placeholders of the random funcs
+// of the tests to their replacement.
+func (t *template) tests(src, typeName string, samplers map[string]*sampler) *template {
+	tests := &template{
+		name:    t.name,
+		src:     src,
+		params:  make(map[string]string),
+		renames: declRenames(src, t.name, typeName),
+		drop:    t.drop,
+	}
+	// the declarations of the datastructure are outside the tests
+	for from, to := range t.params {
+		tests.params[from] = to
+	}
+	for from, to := range t.renames {
+		tests.params[from] = to
+	}
+	// the types the datastructure holds may need imports
+	tests.imports = append(tests.imports, t.imports...)
+
+	placeholders := make([]string, 0, len(samplers))
+	for placeholder := range samplers {
+		placeholders = append(placeholders, placeholder)
+	}
+	sort.Strings(placeholders)
+	for _, placeholder := range placeholders {
+		s := samplers[placeholder]
+		tests.params[placeholder] = s.fn
+		tests.imports = append(tests.imports, s.imports...)
+		tests.decls += s.decl
+	}
+	return tests
+}
+
+// sampler is a func generating random values for the tests.
+type sampler struct {
+	fn string
+	// decl declares fn, if it's generated.
+	decl    string
+	imports []string
+}
+
+// newSampler returns the func generating random values of the sampled type
+// for the tests. It's the func given to its flag or, failing that, a func
+// named name. Values of types that aren't builtin are zero, unless the func
+// is required.
+//
+// Samplers draw from few enough values that keys collide, as tests need.
+// Wide ones rarely repeat a value, as benchmarks need.
+func newSampler(given string, sampled sampled, name string, wide bool) *sampler {
+	typ := sampled.typ
+	if given != "" {
+		fn, imports := funcOrDefault(sampled.flag, given)
+		return &sampler{fn: fn, imports: imports}
+	}
+
+	num, numType, str := "r.Intn(100)", "int", "strconv.Itoa(r.Intn(100))"
+	if wide {
+		num, numType, str = "r.Uint64()", "uint64", "strconv.FormatUint(r.Uint64(), 36)"
+	}
+
+	s := &sampler{fn: name}
+	var body string
+	switch typ.expr {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"byte", "rune":
+		if typ.expr == numType {
+			body = "return " + num
+		} else {
+			body = fmt.Sprintf("return %s(%s)", typ.expr, num)
+		}
+	case "float32", "float64":
+		body = fmt.Sprintf("return %s(r.NormFloat64())", typ.expr)
+	case "bool":
+		body = "return r.Intn(2) == 0"
+	case "string":
+		body = "return " + str
+		s.imports = []string{"strconv"}
+	case "[]byte":
+		body = fmt.Sprintf("return []byte(%s)", str)
+		s.imports = []string{"strconv"}
+	default:
+		if sampled.required {
+			log.Fatalf("a func(*rand.Rand) %s generating %ss must be given with -%s",
+				typ.expr, strings.ToLower(sampled.what), sampled.flag.Name)
+		}
+		s.decl = fmt.Sprintf("\n\nfunc %s(r *rand.Rand) (v %s) { return }\n", name, typ.expr)
+		return s
+	}
+	s.decl = fmt.Sprintf("\n\nfunc %s(r *rand.Rand) %s { %s }\n", name, typ.expr, body)
+	return s
+}
+
+// declRenames maps the package level declarations of src whose name
+// contains from, such as TestHeapOrder, to their name with from replaced by
+// to.
+func declRenames(src, from, to string) map[string]string {
+	file, err := parser.ParseFile(token.NewFileSet(), "template.go", src, 0)
+	if err != nil {
+		log.Fatalf("parsing template: %v", err)
+	}
+	renames := make(map[string]string)
+	rename := func(id *ast.Ident) {
+		if strings.Contains(id.Name, from) {
+			renames[id.Name] = strings.Replace(id.Name, from, to, 1)
+		}
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				rename(d.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					rename(s.Name)
+				case *ast.ValueSpec:
+					for _, id := range s.Names {
+						rename(id)
+					}
+				}
+			}
+		}
+	}
+	return renames
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDeclRenames(t *testing.T) {
+	got := declRenames(heapTestSrc, "Heap", "IntHeap")
+	want := map[string]string{
+		"checkHeap":                "checkIntHeap",
+		"checkHeapEmpty":           "checkIntHeapEmpty",
+		"TestHeapEmpty":            "TestIntHeapEmpty",
+		"refHeap":                  "refIntHeap",
+		"TestHeapMatchesReference": "TestIntHeapMatchesReference",
+		"TestHeapPopsInOrder":      "TestIntHeapPopsInOrder",
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+// itemSrc declares a key type with its own order, and a func generating
+// random items for the tests. It declares the funcs aggregating the entries
+// of the augmented sorted maps as well: labels, which are in order, and
+// floating point sums.
+const itemSrc = `package gentest
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+type Item struct{ Prio, ID int }
+
+func (i Item) Compare(other Item) int { return i.Prio - other.Prio }
+
+func randomItem(r *rand.Rand) Item { return Item{Prio: r.Intn(100), ID: r.Int()} }
+
+func entryLabel(k int, v string) string { return strconv.Itoa(k) + "=" + v + ";" }
+
+func concatLabels(a, b string) string { return a + b }
+
+func entryValue(_ int, v float64) float64 { return v }
+
+func sum(a, b float64) float64 { return a + b }
+`
+
+// orderTestSrc verifies that the heaps generated in TestGeneratedTests come
+// out in the order they were asked for, which their own tests take for
+// granted.
+const orderTestSrc = `package gentest
+
+import "testing"
+
+func TestHeapOrder(t *testing.T) {
+	max, min := NewIntHeap(3, 1, 2), NewIntMinHeap(3, 1, 2)
+	for _, want := range []int{3, 2, 1} {
+		if got := max.Pop(); got != want {
+			t.Errorf("max-heap: want %d, got %d", want, got)
+		}
+	}
+	for _, want := range []int{1, 2, 3} {
+		if got := min.Pop(); got != want {
+			t.Errorf("min-heap: want %d, got %d", want, got)
+		}
+	}
+
+	ids := NewIntByFloat64IndexedMinHeap()
+	for id, k := range []float64{3, 1, 2} {
+		ids.Push(id, k)
+	}
+	ids.Update(0, 0)
+	for _, want := range []int{0, 1, 2} {
+		if got, _ := ids.Pop(); got != want {
+			t.Errorf("indexed min-heap: want %d, got %d", want, got)
+		}
+	}
+}
+`
+
+// syncTestSrc has goroutines reading and writing the sync wrappers generated
+// in TestGeneratedTests at once, for the race detector to check them.
+const syncTestSrc = `package gentest
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSyncReadersWriters(t *testing.T) {
+	const goroutines, n = 4, 1000
+	h := NewSyncIntHeap()
+	ih := NewSyncStringByItemIndexedHeap()
+	q := NewSyncFloat64Queue(0)
+	d := NewSyncStringDeque(0)
+	s := NewSyncSortedStringSet()
+	m := NewSyncSortedIntToItemMap()
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(2)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				h.Push(i)
+				ih.Push(strconv.Itoa(g*n+i), Item{Prio: i})
+				q.Push(float64(i))
+				d.PushFront(strconv.Itoa(i))
+				s.Put(strconv.Itoa(i))
+				m.Put(i, Item{ID: g})
+			}
+		}(g)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				h.TryPeek()
+				ih.Contains(strconv.Itoa(i))
+				q.TryPeek()
+				d.TryPeekBack()
+				s.Contains(strconv.Itoa(i))
+				m.Keys(func(int, Item) bool { return false })
+			}
+		}()
+	}
+	wg.Wait()
+
+	for name, size := range map[string]int{
+		"heap":         h.Len(),
+		"indexed heap": ih.Len(),
+		"queue":        q.Len(),
+		"deque":        d.Len(),
+	} {
+		if size != goroutines*n {
+			t.Errorf("%s: want len %d, got %d", name, goroutines*n, size)
+		}
+	}
+	if s.Size() != n || m.Size() != n {
+		t.Errorf("want sizes of %d, got %d for the set and %d for the map", n, s.Size(), m.Size())
+	}
+}
+`
+
+// TestGeneratedTests runs the commands of datagen, as they're invoked, to
+// generate each datastructure along with its tests and benchmarks, which are
+// then vetted and run.
+func TestGeneratedTests(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs the generated code")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), []byte("module gentest\n"))
+	writeFile(t, filepath.Join(dir, "item.go"), []byte(itemSrc))
+	writeFile(t, filepath.Join(dir, "order_test.go"), []byte(orderTestSrc))
+	writeFile(t, filepath.Join(dir, "sync_test.go"), []byte(syncTestSrc))
+
+	// each command is given -o with the file to write to, -tests and -bench
+	commands := []struct {
+		filename string
+		args     []string
+	}{
+		{"heap.go", []string{"heap", "-key=int", "-sync"}},
+		{"minheap.go", []string{"heap", "-key=int", "-order=min"}},
+		{"items.go", []string{"heap", "-key=Item", "-gen=randomItem"}},
+		{"queue.go", []string{"queue", "-key=float64", "-sync"}},
+		{"bqueue.go", []string{"queue", "-key=[]byte", "-bounded"}},
+		{"blocking.go", []string{"queue", "-key=int", "-blocking"}},
+		{"spsc.go", []string{"ringq", "-key=[]byte", "-flavor=spsc"}},
+		{"mpmc.go", []string{"ringq", "-key=Item", "-flavor=mpmc", "-gen=randomItem"}},
+		{"deque.go", []string{"deque", "-key=string", "-sync"}},
+		{"set.go", []string{"sset", "-key=string", "-sync"}},
+		{"map.go", []string{"smap", "-key=[]byte", "-val=float64"}},
+		{"iheap.go", []string{"iheap", "-key=Item", "-id=string", "-gen=randomItem", "-sync"}},
+		{"miniheap.go", []string{"iheap", "-key=float64", "-id=int", "-order=min"}},
+		{"pmap.go", []string{"smap", "-key=string", "-val=int", "-persistent", "-sync"}},
+		// the test helpers of a second map of the same template mustn't collide
+		{"ipmap.go", []string{"smap", "-key=int", "-val=string", "-persistent"}},
+		// the entries are aggregated into labels, in order
+		{"amap.go", []string{"smap", "-key=int", "-val=string",
+			"-aggregate=string", "-measure=entryLabel", "-combine=concatLabels", "-sync"}},
+		// the sums are rounded otherwise by the tree than by its tests
+		{"fmap.go", []string{"smap", "-key=int", "-val=float64",
+			"-aggregate=float64", "-measure=entryValue", "-combine=sum"}},
+		{"itree.go", []string{"itree", "-key=float64", "-val=string", "-sync"}},
+		{"multimap.go", []string{"smultimap", "-key=[]byte", "-val=string", "-sync"}},
+		{"multiset.go", []string{"smultiset", "-key=Item", "-gen=randomItem", "-sync"}},
+		{"itemmap.go", []string{"smap", "-key=int", "-val=Item", "-genval=randomItem", "-sync"}},
+	}
+	for _, c := range commands {
+		args := append([]string{"datagen"}, c.args...)
+		args = append(args, "-o", filepath.Join(dir, c.filename), "-tests", "-bench")
+		if err := newApp().Run(args); err != nil {
+			t.Fatalf("%s: %v", strings.Join(args, " "), err)
+		}
+	}
+
+	env := append(os.Environ(), "GO111MODULE=on", "GOFLAGS=")
+	cmd := exec.Command(gobin, "vet", ".")
+	cmd.Dir = dir
+	cmd.Env = env
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, output)
+	}
+
+	// the benchmarks only run on the smallest size
+	cmd = exec.Command(gobin, "test", "-bench", "/^100$", "-benchtime", "10x", ".")
+	cmd.Dir = dir
+	cmd.Env = env
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, output)
+	}
+
+	// the race detector needs cgo
+	cmd = exec.Command(gobin, "env", "CGO_ENABLED")
+	if output, err := cmd.Output(); err != nil || string(output) != "1\n" {
+		t.Skip("the datastructures safe for concurrent use can't be checked for races without cgo")
+	}
+	cmd = exec.Command(gobin, "test", "-race", "-run", "Sync|Blocking|SPSC|MPMC|SnapshotsRead", ".")
+	cmd.Dir = dir
+	cmd.Env = env
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, output)
+	}
+}
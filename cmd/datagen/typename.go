@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// typeExpr is a type given on the command line, such as `*time.Time` or
+// `[]github.com/you/pkg.Item`.
+type typeExpr struct {
+	// expr is the type as it appears in the generated source, with import
+	// paths reduced to package names.
+	expr string
+	// name is an exported identifier describing the type, used to name the
+	// generated datastructures.
+	name string
+	// imports needed to refer to the type.
+	imports []string
+}
+
+// qualifiedRegexp matches a type qualified by a full import path, such as
+// github.com/you/pkg.Item. The last element of the path has no dot but for a
+// version suffix like `.v2`, so that the path ends before the type of a
+// method expression such as github.com/you/pkg.Item.Less.
+var qualifiedRegexp = regexp.MustCompile(`((?:[\w.\-]+/)+[\w\-]+(?:\.v[0-9]+)?)\.(\w+)`)
+
+// parseType parses a type expression. Package qualifiers are either a full
+// import path, or the name of a package of the standard library.
+func parseType(s string) (*typeExpr, error) {
+	expr, imports, err := parseQualified(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid type %q: %v", s, err)
+	}
+	name, err := exprName(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid type %q: %v", s, err)
+	}
+	return &typeExpr{expr: types.ExprString(expr), name: name, imports: imports}, nil
+}
+
+// parseQualified parses an expression which can refer to other packages,
+// either by their full import path or, for packages of the standard library,
+// by their name. It returns the expression, with import paths reduced to
+// package names, and the import paths it refers to.
+func parseQualified(s string) (ast.Expr, []string, error) {
+	paths := make(map[string]string)
+	s = qualifiedRegexp.ReplaceAllStringFunc(s, func(qualified string) string {
+		m := qualifiedRegexp.FindStringSubmatch(qualified)
+		pkgname := importName(m[1])
+		paths[pkgname] = m[1]
+		return pkgname + "." + m[2]
+	})
+
+	expr, err := parser.ParseExpr(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var imports []string
+	seen := make(map[string]bool)
+	var werr error
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok {
+			// a selector of a selector, such as pkg.Item.Less
+			return true
+		}
+		if seen[pkg.Name] {
+			return false
+		}
+		seen[pkg.Name] = true
+		path, ok := paths[pkg.Name]
+		if !ok {
+			var err error
+			if path, err = stdlibImport(pkg.Name); err != nil && werr == nil {
+				werr = err
+			}
+		}
+		imports = append(imports, path)
+		return false
+	})
+	return expr, imports, werr
+}
+
+// exprName derives an exported identifier from a type expression.
+func exprName(expr ast.Expr) (string, error) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return export(e.Name), nil
+	case *ast.SelectorExpr:
+		pkg, ok := e.X.(*ast.Ident)
+		if !ok {
+			return "", fmt.Errorf("unexpected qualifier %q", types.ExprString(e.X))
+		}
+		return export(pkg.Name) + export(e.Sel.Name), nil
+	case *ast.ParenExpr:
+		return exprName(e.X)
+	case *ast.StarExpr:
+		return prefixName("Ptr", e.X)
+	case *ast.ArrayType:
+		if e.Len == nil {
+			elt, err := exprName(e.Elt)
+			return elt + "s", err
+		}
+		lit, ok := e.Len.(*ast.BasicLit)
+		if !ok || lit.Kind != token.INT {
+			return "", fmt.Errorf("array length must be a literal, got %q", types.ExprString(e.Len))
+		}
+		return prefixName("Array"+lit.Value, e.Elt)
+	case *ast.MapType:
+		key, err := exprName(e.Key)
+		if err != nil {
+			return "", err
+		}
+		return prefixName("Map"+key+"To", e.Value)
+	case *ast.ChanType:
+		return prefixName("Chan", e.Value)
+	case *ast.FuncType:
+		return "Func", nil
+	case *ast.InterfaceType:
+		return "Interface", nil
+	case *ast.StructType:
+		return "Struct", nil
+	case *ast.IndexExpr:
+		return instanceName(e.X, e.Index)
+	case *ast.IndexListExpr:
+		return instanceName(e.X, e.Indices...)
+	}
+	return "", fmt.Errorf("not a type: %q", types.ExprString(expr))
+}
+
+func prefixName(prefix string, expr ast.Expr) (string, error) {
+	name, err := exprName(expr)
+	return prefix + name, err
+}
+
+// instanceName names an instance of a generic type, such as List[int].
+func instanceName(generic ast.Expr, args ...ast.Expr) (string, error) {
+	name, err := exprName(generic)
+	if err != nil {
+		return "", err
+	}
+	for _, arg := range args {
+		argName, err := exprName(arg)
+		if err != nil {
+			return "", err
+		}
+		name += argName
+	}
+	return name, nil
+}
+
+func export(name string) string {
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+var majorVersionRegexp = regexp.MustCompile(`/v[0-9]+$`)
+
+// importName guesses the name of the package at path, from the last element
+// of the path. Major version elements, suffixes like `.v2` and prefixes like
+// `go-` are dropped.
+func importName(path string) string {
+	path = majorVersionRegexp.ReplaceAllString(path, "")
+	name := path[strings.LastIndex(path, "/")+1:]
+	if i := strings.Index(name, "."); i > 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, "-"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+var stdlib map[string][]string
+
+// stdlibImport finds the import path of a package of the standard library
+// from its name.
+func stdlibImport(name string) (string, error) {
+	if stdlib == nil {
+		stdlib = listStdlib()
+	}
+	paths := stdlib[name]
+	switch len(paths) {
+	case 0:
+		return "", fmt.Errorf("package %q is not in the standard library, qualify the type with its full import path (e.g. github.com/you/%s.Type)", name, name)
+	case 1:
+	default:
+		log.Printf("WARNING: package name %q is ambiguous (%s), using %q. Qualify the type with its full import path to pick another.",
+			name, strings.Join(paths, ", "), paths[0])
+	}
+	return paths[0], nil
+}
+
+// listStdlib maps the names of the packages of the standard library to their
+// import paths, shortest path first.
+func listStdlib() map[string][]string {
+	pkgs := make(map[string][]string)
+	root := filepath.Join(build.Default.GOROOT, "src")
+	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		switch info.Name() {
+		case "internal", "vendor", "testdata", "cmd":
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		name := importName(rel)
+		pkgs[name] = append(pkgs[name], rel)
+		return nil
+	})
+	for _, paths := range pkgs {
+		sort.Strings(paths)
+		sort.Stable(byLen(paths))
+	}
+	return pkgs
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"go/types"
+	"reflect"
+	"testing"
+)
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		in      string
+		expr    string
+		name    string
+		imports []string
+	}{
+		{in: "int", expr: "int", name: "Int"},
+		{in: "[]byte", expr: "[]byte", name: "Bytes"},
+		{in: "*Item", expr: "*Item", name: "PtrItem"},
+		{in: "time.Time", expr: "time.Time", name: "TimeTime", imports: []string{"time"}},
+		{in: "*url.URL", expr: "*url.URL", name: "PtrUrlURL", imports: []string{"net/url"}},
+		{in: "map[string]int", expr: "map[string]int", name: "MapStringToInt"},
+		{in: "[4]int", expr: "[4]int", name: "Array4Int"},
+		{in: "chan int", expr: "chan int", name: "ChanInt"},
+		{in: "List[int]", expr: "List[int]", name: "ListInt"},
+		{in: "Pair[string, int]", expr: "Pair[string, int]", name: "PairStringInt"},
+		{
+			in:      "*github.com/you/pkg.Item",
+			expr:    "*pkg.Item",
+			name:    "PtrPkgItem",
+			imports: []string{"github.com/you/pkg"},
+		},
+		{
+			in:      "map[time.Time][]gopkg.in/yaml.v2.Node",
+			expr:    "map[time.Time][]yaml.Node",
+			name:    "MapTimeTimeToYamlNodes",
+			imports: []string{"time", "gopkg.in/yaml.v2"},
+		},
+		{
+			in:      "github.com/you/go-pkg/v3.Item",
+			expr:    "pkg.Item",
+			name:    "PkgItem",
+			imports: []string{"github.com/you/go-pkg/v3"},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := parseType(tt.in)
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+			continue
+		}
+		if got.expr != tt.expr {
+			t.Errorf("%q: want expr %q, got %q", tt.in, tt.expr, got.expr)
+		}
+		if got.name != tt.name {
+			t.Errorf("%q: want name %q, got %q", tt.in, tt.name, got.name)
+		}
+		if !reflect.DeepEqual(got.imports, tt.imports) {
+			t.Errorf("%q: want imports %q, got %q", tt.in, tt.imports, got.imports)
+		}
+	}
+}
+
+func TestParseQualified(t *testing.T) {
+	tests := []struct {
+		in      string
+		expr    string
+		imports []string
+	}{
+		{in: "less", expr: "less"},
+		{in: "github.com/you/pkg.Less", expr: "pkg.Less", imports: []string{"github.com/you/pkg"}},
+		{
+			in:      "github.com/you/pkg.Item.Less",
+			expr:    "pkg.Item.Less",
+			imports: []string{"github.com/you/pkg"},
+		},
+		{
+			in:      "(*github.com/you/pkg.Item).Less",
+			expr:    "(*pkg.Item).Less",
+			imports: []string{"github.com/you/pkg"},
+		},
+		{
+			in:      "gopkg.in/yaml.v2.Node.Less",
+			expr:    "yaml.Node.Less",
+			imports: []string{"gopkg.in/yaml.v2"},
+		},
+		{in: "time.Time.Before", expr: "time.Time.Before", imports: []string{"time"}},
+	}
+
+	for _, tt := range tests {
+		expr, imports, err := parseQualified(tt.in)
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+			continue
+		}
+		if got := types.ExprString(expr); got != tt.expr {
+			t.Errorf("%q: want expr %q, got %q", tt.in, tt.expr, got)
+		}
+		if !reflect.DeepEqual(imports, tt.imports) {
+			t.Errorf("%q: want imports %q, got %q", tt.in, tt.imports, imports)
+		}
+	}
+}
+
+func TestParseTypeInvalid(t *testing.T) {
+	for _, in := range []string{"", "1+1", "[n]int", "notapkg.Item"} {
+		if got, err := parseType(in); err == nil {
+			t.Errorf("%q: should have failed, got %#v", in, got)
+		}
+	}
+}
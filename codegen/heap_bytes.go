@@ -1,5 +1,9 @@
+// Code generated by datagen; DO NOT EDIT.
+
 package codegen
 
+import "bytes"
+
 // Most of the implementation is adapted from Algorithms 4ed by Sedgewick
 // and Wayne.
 
@@ -8,13 +12,14 @@ package codegen
 // 	 Use of this source code is governed by a BSD-style
 // 	 license that can be found in the LICENSE file.
 
-import "bytes"
-
 // WARNING: using []byte as keys can lead to undefined behavior if the
 // []byte are modified after insertion!!!
 func (h BytesHeap) compare(a, b []byte) int { return bytes.Compare(a, b) }
 
-// BytesHeap is a container of []byte, where the elements can be efficiently
+// before tells if a comes out of the heap before b: this is a max-heap.
+func (h BytesHeap) before(a, b []byte) bool { return h.compare(a, b) > 0 }
+
+// BytesHeap is a max-heap of []byte, where the elements can be efficiently
 // retrieved in their decreasing order (according to their comparison
 // rules).
 type BytesHeap struct {
@@ -37,8 +42,22 @@ func NewBytesHeap(keys ...[]byte) *BytesHeap {
 func (h *BytesHeap) Len() int { return h.n }
 
 // Peek at the largest element (according to their comparison rules), without
-// removing it from the heap.
-func (h *BytesHeap) Peek() []byte { return h.pq[1] }
+// removing it from the heap. This call panics if the heap is empty.
+func (h *BytesHeap) Peek() []byte {
+	if h.n == 0 {
+		panic("heap: empty heap")
+	}
+	return h.pq[1]
+}
+
+// TryPeek is like Peek, but tells if there was an element instead of
+// panicking on an empty heap.
+func (h *BytesHeap) TryPeek() (k []byte, ok bool) {
+	if h.n == 0 {
+		return k, false
+	}
+	return h.pq[1], true
+}
 
 // Fix re-establishes the heap ordering. This is useful if elements
 // of the heap have had their comparison value changed. It is equivalent to,
@@ -60,8 +79,12 @@ func (h *BytesHeap) Push(k []byte) {
 }
 
 // Pop removes the largest element (according to their comparison rules) from
-// the heap and returns it. The complexity is O(log(n)) where n == h.Len().
+// the heap and returns it. This call panics if the heap is empty. The
+// complexity is O(log(n)) where n == h.Len().
 func (h *BytesHeap) Pop() []byte {
+	if h.n == 0 {
+		panic("heap: empty heap")
+	}
 	val := h.pq[1]
 	h.swap(1, h.n)
 	h.pq = h.pq[:h.n]
@@ -71,30 +94,44 @@ func (h *BytesHeap) Pop() []byte {
 	return val
 }
 
+// TryPop is like Pop, but tells if there was an element instead of panicking
+// on an empty heap.
+func (h *BytesHeap) TryPop() (k []byte, ok bool) {
+	if h.n == 0 {
+		return k, false
+	}
+	return h.Pop(), true
+}
+
 // Remove removes k from the heap, if it exists. Equality is defined by
 // Compare == 0.
 // The complexity is O(n+log(n)) where n == h.Len().
 func (h *BytesHeap) Remove(k []byte) bool {
-
-	cmp := h.compare(h.pq[1], k)
-	if cmp == 0 {
+	if h.n == 0 {
+		return false
+	}
+	if h.compare(h.pq[1], k) == 0 {
 		_ = h.Pop()
 		return true
 	}
-	if cmp < 0 {
+	if h.before(k, h.pq[1]) {
 		// larger than largest, don't try to find it
 		return false
 	}
 
-	i := 0
-	for _, j := range h.pq[1:] {
-		i++
-		if h.compare(j, k) != 0 {
+	for i := 2; i <= h.n; i++ {
+		if h.compare(h.pq[i], k) != 0 {
 			continue
 		}
-		h.swap(i, 1)
-		h.sink(i, h.n)
-		_ = h.Pop()
+		// replace k by the last element, which can then be smaller or
+		// larger than its new parent and children
+		h.swap(i, h.n)
+		h.pq = h.pq[:h.n]
+		h.n--
+		if i <= h.n {
+			h.sink(i, h.n)
+			h.swim(i)
+		}
 		return true
 	}
 	// not in the heap
@@ -102,7 +139,7 @@ func (h *BytesHeap) Remove(k []byte) bool {
 }
 
 func (h *BytesHeap) swap(i, j int)      { h.pq[i], h.pq[j] = h.pq[j], h.pq[i] }
-func (h *BytesHeap) less(i, j int) bool { return h.compare(h.pq[i], h.pq[j]) < 0 }
+func (h *BytesHeap) less(i, j int) bool { return h.before(h.pq[j], h.pq[i]) }
 
 func (h *BytesHeap) swim(k int) {
 	for k > 1 && h.less(k/2, k) {
@@ -125,4 +162,3 @@ func (h *BytesHeap) sink(k, n int) {
 		k = j
 	}
 }
-
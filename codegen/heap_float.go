@@ -1,3 +1,5 @@
+// Code generated by datagen; DO NOT EDIT.
+
 package codegen
 
 // Most of the implementation is adapted from Algorithms 4ed by Sedgewick
@@ -8,20 +10,29 @@ package codegen
 // 	 Use of this source code is governed by a BSD-style
 // 	 license that can be found in the LICENSE file.
 
-
+// compare orders NaNs before any other value, including -Inf, and considers
+// them equal to each other. -0 and +0 are equal.
 func (h Float64Heap) compare(a, b float64) int {
-	const e = 0.00000001
-
-    diff := (a-b)/a
-    if diff < -e {
-        return -1
-    } else if diff > e {
-        return 1
-    }
-    return 0
+	aNaN, bNaN := a != a, b != b
+	switch {
+	case aNaN && bNaN:
+		return 0
+	case aNaN:
+		return -1
+	case bNaN:
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
 }
 
-// Float64Heap is a container of float64, where the elements can be efficiently
+// before tells if a comes out of the heap before b: this is a max-heap.
+func (h Float64Heap) before(a, b float64) bool { return h.compare(a, b) > 0 }
+
+// Float64Heap is a max-heap of float64, where the elements can be efficiently
 // retrieved in their decreasing order (according to their comparison
 // rules).
 type Float64Heap struct {
@@ -44,8 +55,22 @@ func NewFloat64Heap(keys ...float64) *Float64Heap {
 func (h *Float64Heap) Len() int { return h.n }
 
 // Peek at the largest element (according to their comparison rules), without
-// removing it from the heap.
-func (h *Float64Heap) Peek() float64 { return h.pq[1] }
+// removing it from the heap. This call panics if the heap is empty.
+func (h *Float64Heap) Peek() float64 {
+	if h.n == 0 {
+		panic("heap: empty heap")
+	}
+	return h.pq[1]
+}
+
+// TryPeek is like Peek, but tells if there was an element instead of
+// panicking on an empty heap.
+func (h *Float64Heap) TryPeek() (k float64, ok bool) {
+	if h.n == 0 {
+		return k, false
+	}
+	return h.pq[1], true
+}
 
 // Fix re-establishes the heap ordering. This is useful if elements
 // of the heap have had their comparison value changed. It is equivalent to,
@@ -67,8 +92,12 @@ func (h *Float64Heap) Push(k float64) {
 }
 
 // Pop removes the largest element (according to their comparison rules) from
-// the heap and returns it. The complexity is O(log(n)) where n == h.Len().
+// the heap and returns it. This call panics if the heap is empty. The
+// complexity is O(log(n)) where n == h.Len().
 func (h *Float64Heap) Pop() float64 {
+	if h.n == 0 {
+		panic("heap: empty heap")
+	}
 	val := h.pq[1]
 	h.swap(1, h.n)
 	h.pq = h.pq[:h.n]
@@ -78,30 +107,44 @@ func (h *Float64Heap) Pop() float64 {
 	return val
 }
 
+// TryPop is like Pop, but tells if there was an element instead of panicking
+// on an empty heap.
+func (h *Float64Heap) TryPop() (k float64, ok bool) {
+	if h.n == 0 {
+		return k, false
+	}
+	return h.Pop(), true
+}
+
 // Remove removes k from the heap, if it exists. Equality is defined by
 // Compare == 0.
 // The complexity is O(n+log(n)) where n == h.Len().
 func (h *Float64Heap) Remove(k float64) bool {
-
-	cmp := h.compare(h.pq[1], k)
-	if cmp == 0 {
+	if h.n == 0 {
+		return false
+	}
+	if h.compare(h.pq[1], k) == 0 {
 		_ = h.Pop()
 		return true
 	}
-	if cmp < 0 {
+	if h.before(k, h.pq[1]) {
 		// larger than largest, don't try to find it
 		return false
 	}
 
-	i := 0
-	for _, j := range h.pq[1:] {
-		i++
-		if h.compare(j, k) != 0 {
+	for i := 2; i <= h.n; i++ {
+		if h.compare(h.pq[i], k) != 0 {
 			continue
 		}
-		h.swap(i, 1)
-		h.sink(i, h.n)
-		_ = h.Pop()
+		// replace k by the last element, which can then be smaller or
+		// larger than its new parent and children
+		h.swap(i, h.n)
+		h.pq = h.pq[:h.n]
+		h.n--
+		if i <= h.n {
+			h.sink(i, h.n)
+			h.swim(i)
+		}
 		return true
 	}
 	// not in the heap
@@ -109,7 +152,7 @@ func (h *Float64Heap) Remove(k float64) bool {
 }
 
 func (h *Float64Heap) swap(i, j int)      { h.pq[i], h.pq[j] = h.pq[j], h.pq[i] }
-func (h *Float64Heap) less(i, j int) bool { return h.compare(h.pq[i], h.pq[j]) < 0 }
+func (h *Float64Heap) less(i, j int) bool { return h.before(h.pq[j], h.pq[i]) }
 
 func (h *Float64Heap) swim(k int) {
 	for k > 1 && h.less(k/2, k) {
@@ -132,4 +175,3 @@ func (h *Float64Heap) sink(k, n int) {
 		k = j
 	}
 }
-
@@ -1,10 +1,12 @@
+// Code generated by datagen; DO NOT EDIT.
+
 package codegen
 
 // Implementation adapted from github.com/eapache/queue:
 //    The MIT License (MIT)
 //    Copyright (c) 2014 Evan Huus
 
-var nilBytes []byte
+var nilBytesQueue []byte
 
 // BytesQueue represents a single instance of the queue data structure.
 type BytesQueue struct {
@@ -47,6 +49,15 @@ func (q *BytesQueue) Peek() []byte {
 	return q.buf[q.head]
 }
 
+// TryPeek is like Peek, but tells if there was an element instead of
+// panicking on an empty queue.
+func (q *BytesQueue) TryPeek() ([]byte, bool) {
+	if q.count == 0 {
+		return nilBytesQueue, false
+	}
+	return q.buf[q.head], true
+}
+
 // Get returns the element at index i in the queue. If the index is
 // invalid, the call will panic.
 func (q *BytesQueue) Get(i int) []byte {
@@ -66,7 +77,7 @@ func (q *BytesQueue) Pop() []byte {
 	v := q.buf[q.head]
 	// set to nil to avoid keeping reference to objects
 	// that would otherwise be garbage collected
-	q.buf[q.head] = nilBytes
+	q.buf[q.head] = nilBytesQueue
 	q.head = (q.head + 1) % len(q.buf)
 	q.count--
 	if len(q.buf) > q.minlen && q.count*4 <= len(q.buf) {
@@ -75,6 +86,15 @@ func (q *BytesQueue) Pop() []byte {
 	return v
 }
 
+// TryPop is like Pop, but tells if there was an element instead of panicking
+// on an empty queue.
+func (q *BytesQueue) TryPop() ([]byte, bool) {
+	if q.count == 0 {
+		return nilBytesQueue, false
+	}
+	return q.Pop(), true
+}
+
 func (q *BytesQueue) resize() {
 	newBuf := make([][]byte, q.count*2)
 
@@ -89,4 +109,3 @@ func (q *BytesQueue) resize() {
 	q.tail = q.count
 	q.buf = newBuf
 }
-
@@ -1,10 +1,12 @@
+// Code generated by datagen; DO NOT EDIT.
+
 package codegen
 
 // Implementation adapted from github.com/eapache/queue:
 //    The MIT License (MIT)
 //    Copyright (c) 2014 Evan Huus
 
-var nilfloat64 float64
+var nilFloat64Queue float64
 
 // Float64Queue represents a single instance of the queue data structure.
 type Float64Queue struct {
@@ -47,6 +49,15 @@ func (q *Float64Queue) Peek() float64 {
 	return q.buf[q.head]
 }
 
+// TryPeek is like Peek, but tells if there was an element instead of
+// panicking on an empty queue.
+func (q *Float64Queue) TryPeek() (float64, bool) {
+	if q.count == 0 {
+		return nilFloat64Queue, false
+	}
+	return q.buf[q.head], true
+}
+
 // Get returns the element at index i in the queue. If the index is
 // invalid, the call will panic.
 func (q *Float64Queue) Get(i int) float64 {
@@ -66,7 +77,7 @@ func (q *Float64Queue) Pop() float64 {
 	v := q.buf[q.head]
 	// set to nil to avoid keeping reference to objects
 	// that would otherwise be garbage collected
-	q.buf[q.head] = nilfloat64
+	q.buf[q.head] = nilFloat64Queue
 	q.head = (q.head + 1) % len(q.buf)
 	q.count--
 	if len(q.buf) > q.minlen && q.count*4 <= len(q.buf) {
@@ -75,6 +86,15 @@ func (q *Float64Queue) Pop() float64 {
 	return v
 }
 
+// TryPop is like Pop, but tells if there was an element instead of panicking
+// on an empty queue.
+func (q *Float64Queue) TryPop() (float64, bool) {
+	if q.count == 0 {
+		return nilFloat64Queue, false
+	}
+	return q.Pop(), true
+}
+
 func (q *Float64Queue) resize() {
 	newBuf := make([]float64, q.count*2)
 
@@ -89,4 +109,3 @@ func (q *Float64Queue) resize() {
 	q.tail = q.count
 	q.buf = newBuf
 }
-
@@ -1,10 +1,12 @@
+// Code generated by datagen; DO NOT EDIT.
+
 package codegen
 
 // Implementation adapted from github.com/eapache/queue:
 //    The MIT License (MIT)
 //    Copyright (c) 2014 Evan Huus
 
-var nilint int
+var nilIntQueue int
 
 // IntQueue represents a single instance of the queue data structure.
 type IntQueue struct {
@@ -47,6 +49,15 @@ func (q *IntQueue) Peek() int {
 	return q.buf[q.head]
 }
 
+// TryPeek is like Peek, but tells if there was an element instead of
+// panicking on an empty queue.
+func (q *IntQueue) TryPeek() (int, bool) {
+	if q.count == 0 {
+		return nilIntQueue, false
+	}
+	return q.buf[q.head], true
+}
+
 // Get returns the element at index i in the queue. If the index is
 // invalid, the call will panic.
 func (q *IntQueue) Get(i int) int {
@@ -66,7 +77,7 @@ func (q *IntQueue) Pop() int {
 	v := q.buf[q.head]
 	// set to nil to avoid keeping reference to objects
 	// that would otherwise be garbage collected
-	q.buf[q.head] = nilint
+	q.buf[q.head] = nilIntQueue
 	q.head = (q.head + 1) % len(q.buf)
 	q.count--
 	if len(q.buf) > q.minlen && q.count*4 <= len(q.buf) {
@@ -75,6 +86,15 @@ func (q *IntQueue) Pop() int {
 	return v
 }
 
+// TryPop is like Pop, but tells if there was an element instead of panicking
+// on an empty queue.
+func (q *IntQueue) TryPop() (int, bool) {
+	if q.count == 0 {
+		return nilIntQueue, false
+	}
+	return q.Pop(), true
+}
+
 func (q *IntQueue) resize() {
 	newBuf := make([]int, q.count*2)
 
@@ -89,4 +109,3 @@ func (q *IntQueue) resize() {
 	q.tail = q.count
 	q.buf = newBuf
 }
-
@@ -1,10 +1,12 @@
+// Code generated by datagen; DO NOT EDIT.
+
 package codegen
 
 // Implementation adapted from github.com/eapache/queue:
 //    The MIT License (MIT)
 //    Copyright (c) 2014 Evan Huus
 
-var nilstring string
+var nilStringQueue string
 
 // StringQueue represents a single instance of the queue data structure.
 type StringQueue struct {
@@ -47,6 +49,15 @@ func (q *StringQueue) Peek() string {
 	return q.buf[q.head]
 }
 
+// TryPeek is like Peek, but tells if there was an element instead of
+// panicking on an empty queue.
+func (q *StringQueue) TryPeek() (string, bool) {
+	if q.count == 0 {
+		return nilStringQueue, false
+	}
+	return q.buf[q.head], true
+}
+
 // Get returns the element at index i in the queue. If the index is
 // invalid, the call will panic.
 func (q *StringQueue) Get(i int) string {
@@ -66,7 +77,7 @@ func (q *StringQueue) Pop() string {
 	v := q.buf[q.head]
 	// set to nil to avoid keeping reference to objects
 	// that would otherwise be garbage collected
-	q.buf[q.head] = nilstring
+	q.buf[q.head] = nilStringQueue
 	q.head = (q.head + 1) % len(q.buf)
 	q.count--
 	if len(q.buf) > q.minlen && q.count*4 <= len(q.buf) {
@@ -75,6 +86,15 @@ func (q *StringQueue) Pop() string {
 	return v
 }
 
+// TryPop is like Pop, but tells if there was an element instead of panicking
+// on an empty queue.
+func (q *StringQueue) TryPop() (string, bool) {
+	if q.count == 0 {
+		return nilStringQueue, false
+	}
+	return q.Pop(), true
+}
+
 func (q *StringQueue) resize() {
 	newBuf := make([]string, q.count*2)
 
@@ -89,4 +109,3 @@ func (q *StringQueue) resize() {
 	q.tail = q.count
 	q.buf = newBuf
 }
-
@@ -1,7 +1,11 @@
+// Code generated by datagen; DO NOT EDIT.
+
 package codegen
 
 import "bytes"
 
+import "sort"
+
 // WARNING: using []byte as keys can lead to undefined behavior if the
 // []byte are modified after insertion!!!
 func (r SortedBytesToStringMap) compare(a, b []byte) int { return bytes.Compare(a, b) }
@@ -9,12 +13,65 @@ func (r SortedBytesToStringMap) compare(a, b []byte) int { return bytes.Compare(
 // SortedBytesToStringMap is a sorted map built on a left leaning red black balanced
 // search sorted map. It stores string values, keyed by []byte.
 type SortedBytesToStringMap struct {
-	root *nodeBytesToString
+	root *nodeSortedBytesToStringMap
 }
 
 // NewSortedBytesToStringMap creates a sorted map.
 func NewSortedBytesToStringMap() *SortedBytesToStringMap { return &SortedBytesToStringMap{} }
 
+// NewSortedBytesToStringMapFromSorted creates a sorted map of the keys, given in order, and
+// of their values, in O(n) rather than the O(n log n) of putting them one by
+// one. Of equal keys, the first one is kept with the value of the last, as
+// putting them would. It panics if the keys aren't in order, or if there
+// isn't a value for each key.
+func NewSortedBytesToStringMapFromSorted(keys [][]byte, vals []string) *SortedBytesToStringMap {
+	r := &SortedBytesToStringMap{}
+	if len(keys) != len(vals) {
+		panic("redblackbst: as many keys as values are needed")
+	}
+	var dups bool
+	for i := 1; i < len(keys); i++ {
+		cmp := r.compare(keys[i-1], keys[i])
+		if cmp > 0 {
+			panic("redblackbst: keys aren't sorted")
+		}
+		dups = dups || cmp == 0
+	}
+	if dups {
+		// not to modify the slices given
+		keys, vals = r.dedup(append([][]byte(nil), keys...), append([]string(nil), vals...))
+	}
+	r.root = r.build(keys, vals)
+	return r
+}
+
+// NewSortedBytesToStringMapFromUnsorted creates a sorted map of the keys and their values,
+// sorting them first, in O(n log n) but faster than putting them one by one.
+// Of equal keys, the first one is kept with the value of the last, as putting
+// them would. The slices aren't modified. It panics if there isn't a value
+// for each key.
+func NewSortedBytesToStringMapFromUnsorted(keys [][]byte, vals []string) *SortedBytesToStringMap {
+	r := &SortedBytesToStringMap{}
+	if len(keys) != len(vals) {
+		panic("redblackbst: as many keys as values are needed")
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return r.compare(keys[order[i]], keys[order[j]]) < 0
+	})
+	sortedKeys := make([][]byte, len(keys))
+	sortedVals := make([]string, len(vals))
+	for i, at := range order {
+		sortedKeys[i], sortedVals[i] = keys[at], vals[at]
+	}
+	sortedKeys, sortedVals = r.dedup(sortedKeys, sortedVals)
+	r.root = r.build(sortedKeys, sortedVals)
+	return r
+}
+
 // IsEmpty tells if the sorted map contains no key/value.
 func (r SortedBytesToStringMap) IsEmpty() bool {
 	return r.root == nil
@@ -30,13 +87,15 @@ func (r *SortedBytesToStringMap) Clear() { r.root = nil }
 // if the key was already present.
 func (r *SortedBytesToStringMap) Put(k []byte, v string) (old string, overwrite bool) {
 	r.root, old, overwrite = r.put(r.root, k, v)
+	r.root.colorRed = false
 	return
 }
 
-func (r *SortedBytesToStringMap) put(h *nodeBytesToString, k []byte, v string) (_ *nodeBytesToString, old string, overwrite bool) {
+func (r *SortedBytesToStringMap) put(h *nodeSortedBytesToStringMap, k []byte, v string) (_ *nodeSortedBytesToStringMap, old string, overwrite bool) {
 	if h == nil {
-		n := &nodeBytesToString{key: k, val: v, n: 1, colorRed: true}
-		return n, old, overwrite
+		h = &nodeSortedBytesToStringMap{key: k, val: v, colorRed: true}
+		r.update(h)
+		return h, old, overwrite
 	}
 
 	cmp := r.compare(k, h.key)
@@ -59,7 +118,7 @@ func (r *SortedBytesToStringMap) put(h *nodeBytesToString, k []byte, v string) (
 	if h.left.isRed() && h.right.isRed() {
 		r.flipColors(h)
 	}
-	h.n = h.left.size() + h.right.size() + 1
+	r.update(h)
 	return h, old, overwrite
 }
 
@@ -69,7 +128,7 @@ func (r SortedBytesToStringMap) Get(k []byte) (string, bool) {
 	return r.loopGet(r.root, k)
 }
 
-func (r SortedBytesToStringMap) loopGet(h *nodeBytesToString, k []byte) (v string, ok bool) {
+func (r SortedBytesToStringMap) loopGet(h *nodeSortedBytesToStringMap, k []byte) (v string, ok bool) {
 	for h != nil {
 		cmp := r.compare(k, h.key)
 		if cmp == 0 {
@@ -98,7 +157,7 @@ func (r SortedBytesToStringMap) Min() (k []byte, v string, ok bool) {
 	return h.key, h.val, true
 }
 
-func (r SortedBytesToStringMap) min(x *nodeBytesToString) *nodeBytesToString {
+func (r SortedBytesToStringMap) min(x *nodeSortedBytesToStringMap) *nodeSortedBytesToStringMap {
 	if x.left == nil {
 		return x
 	}
@@ -114,7 +173,7 @@ func (r SortedBytesToStringMap) Max() (k []byte, v string, ok bool) {
 	return h.key, h.val, true
 }
 
-func (r SortedBytesToStringMap) max(x *nodeBytesToString) *nodeBytesToString {
+func (r SortedBytesToStringMap) max(x *nodeSortedBytesToStringMap) *nodeSortedBytesToStringMap {
 	if x.right == nil {
 		return x
 	}
@@ -122,7 +181,7 @@ func (r SortedBytesToStringMap) max(x *nodeBytesToString) *nodeBytesToString {
 }
 
 // Floor returns the largest key/value in the sorted map that is smaller than
-// `k`.
+// or equal to `k`, if it exists.
 func (r SortedBytesToStringMap) Floor(key []byte) (k []byte, v string, ok bool) {
 	x := r.floor(r.root, key)
 	if x == nil {
@@ -131,7 +190,7 @@ func (r SortedBytesToStringMap) Floor(key []byte) (k []byte, v string, ok bool)
 	return x.key, x.val, true
 }
 
-func (r SortedBytesToStringMap) floor(h *nodeBytesToString, k []byte) *nodeBytesToString {
+func (r SortedBytesToStringMap) floor(h *nodeSortedBytesToStringMap, k []byte) *nodeSortedBytesToStringMap {
 	if h == nil {
 		return nil
 	}
@@ -150,7 +209,7 @@ func (r SortedBytesToStringMap) floor(h *nodeBytesToString, k []byte) *nodeBytes
 }
 
 // Ceiling returns the smallest key/value in the sorted map that is larger than
-// `k`.
+// or equal to `k`, if it exists.
 func (r SortedBytesToStringMap) Ceiling(key []byte) (k []byte, v string, ok bool) {
 	x := r.ceiling(r.root, key)
 	if x == nil {
@@ -159,7 +218,7 @@ func (r SortedBytesToStringMap) Ceiling(key []byte) (k []byte, v string, ok bool
 	return x.key, x.val, true
 }
 
-func (r SortedBytesToStringMap) ceiling(h *nodeBytesToString, k []byte) *nodeBytesToString {
+func (r SortedBytesToStringMap) ceiling(h *nodeSortedBytesToStringMap, k []byte) *nodeSortedBytesToStringMap {
 	if h == nil {
 		return nil
 	}
@@ -177,6 +236,54 @@ func (r SortedBytesToStringMap) ceiling(h *nodeBytesToString, k []byte) *nodeByt
 	return h
 }
 
+// Lower returns the largest key/value in the sorted map that is strictly
+// smaller than `k`, its predecessor, if it exists.
+func (r SortedBytesToStringMap) Lower(key []byte) (k []byte, v string, ok bool) {
+	x := r.lower(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, x.val, true
+}
+
+func (r SortedBytesToStringMap) lower(h *nodeSortedBytesToStringMap, k []byte) *nodeSortedBytesToStringMap {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) <= 0 {
+		return r.lower(h.left, k)
+	}
+	t := r.lower(h.right, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
+// Higher returns the smallest key/value in the sorted map that is strictly
+// larger than `k`, its successor, if it exists.
+func (r SortedBytesToStringMap) Higher(key []byte) (k []byte, v string, ok bool) {
+	x := r.higher(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, x.val, true
+}
+
+func (r SortedBytesToStringMap) higher(h *nodeSortedBytesToStringMap, k []byte) *nodeSortedBytesToStringMap {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) >= 0 {
+		return r.higher(h.right, k)
+	}
+	t := r.higher(h.left, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
 // Select key of rank k, meaning the k-th biggest []byte in the sorted map.
 func (r SortedBytesToStringMap) Select(key int) (k []byte, v string, ok bool) {
 	x := r.nodeselect(r.root, key)
@@ -186,7 +293,7 @@ func (r SortedBytesToStringMap) Select(key int) (k []byte, v string, ok bool) {
 	return x.key, x.val, true
 }
 
-func (r SortedBytesToStringMap) nodeselect(x *nodeBytesToString, k int) *nodeBytesToString {
+func (r SortedBytesToStringMap) nodeselect(x *nodeSortedBytesToStringMap, k int) *nodeSortedBytesToStringMap {
 	if x == nil {
 		return nil
 	}
@@ -205,7 +312,7 @@ func (r SortedBytesToStringMap) Rank(k []byte) int {
 	return r.keyrank(k, r.root)
 }
 
-func (r SortedBytesToStringMap) keyrank(k []byte, h *nodeBytesToString) int {
+func (r SortedBytesToStringMap) keyrank(k []byte, h *nodeSortedBytesToStringMap) int {
 	if h == nil {
 		return 0
 	}
@@ -237,7 +344,7 @@ func (r SortedBytesToStringMap) RangedKeys(lo, hi []byte, visit func([]byte, str
 	r.keys(r.root, visit, lo, hi)
 }
 
-func (r SortedBytesToStringMap) keys(h *nodeBytesToString, visit func([]byte, string) bool, lo, hi []byte) bool {
+func (r SortedBytesToStringMap) keys(h *nodeSortedBytesToStringMap, visit func([]byte, string) bool, lo, hi []byte) bool {
 	if h == nil {
 		return true
 	}
@@ -261,6 +368,136 @@ func (r SortedBytesToStringMap) keys(h *nodeBytesToString, visit func([]byte, st
 	return true
 }
 
+// ReverseKeys visit each keys in the sorted map, in reverse order.
+// It stops when visit returns false.
+func (r SortedBytesToStringMap) ReverseKeys(visit func([]byte, string) bool) {
+	min, _, ok := r.Min()
+	if !ok {
+		return
+	}
+	// if the min exists, then the max must exist
+	max, _, _ := r.Max()
+	r.RangedKeysDesc(min, max, visit)
+}
+
+// RangedKeysDesc visit each keys between lo and hi in the sorted map, in
+// reverse order. It stops when visit returns false.
+func (r SortedBytesToStringMap) RangedKeysDesc(lo, hi []byte, visit func([]byte, string) bool) {
+	r.keysDesc(r.root, visit, lo, hi)
+}
+
+func (r SortedBytesToStringMap) keysDesc(h *nodeSortedBytesToStringMap, visit func([]byte, string) bool, lo, hi []byte) bool {
+	if h == nil {
+		return true
+	}
+	cmplo := r.compare(lo, h.key)
+	cmphi := r.compare(hi, h.key)
+	if cmphi > 0 {
+		if !r.keysDesc(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	if cmplo <= 0 && cmphi >= 0 {
+		if !visit(h.key, h.val) {
+			return false
+		}
+	}
+	if cmplo < 0 {
+		if !r.keysDesc(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
+// SortedBytesToStringMapBound is an end of a range of keys: its Key, included in the range unless
+// it's Exclusive. An Unbounded end doesn't limit the range, whatever its Key.
+type SortedBytesToStringMapBound struct {
+	Key       []byte
+	Exclusive bool
+	Unbounded bool
+}
+
+// RangeCount is the number of keys between lo and hi in the sorted map. It
+// doesn't visit them, and takes O(log n).
+func (r SortedBytesToStringMap) RangeCount(lo, hi SortedBytesToStringMapBound) int {
+	n := r.boundrank(hi, true) - r.boundrank(lo, false)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// boundrank is the number of keys below b, or up to b for an upper bound.
+func (r SortedBytesToStringMap) boundrank(b SortedBytesToStringMapBound, upper bool) int {
+	if b.Unbounded {
+		if upper {
+			return r.Size()
+		}
+		return 0
+	}
+	// the key itself is below an exclusive lower bound or an inclusive
+	// upper bound
+	return r.keyrankBound(b.Key, r.root, upper != b.Exclusive)
+}
+
+func (r SortedBytesToStringMap) keyrankBound(k []byte, h *nodeSortedBytesToStringMap, withKey bool) int {
+	if h == nil {
+		return 0
+	}
+	cmp := r.compare(k, h.key)
+	if cmp < 0 {
+		return r.keyrankBound(k, h.left, withKey)
+	} else if cmp > 0 {
+		return 1 + h.left.size() + r.keyrankBound(k, h.right, withKey)
+	} else if withKey {
+		return h.left.size() + 1
+	} else {
+		return h.left.size()
+	}
+}
+
+// BoundedKeys visit each keys between lo and hi in the sorted map, in order.
+// It stops when visit returns false.
+func (r SortedBytesToStringMap) BoundedKeys(lo, hi SortedBytesToStringMapBound, visit func([]byte, string) bool) {
+	r.boundedKeys(r.root, visit, lo, hi)
+}
+
+func (r SortedBytesToStringMap) boundedKeys(h *nodeSortedBytesToStringMap, visit func([]byte, string) bool, lo, hi SortedBytesToStringMapBound) bool {
+	if h == nil {
+		return true
+	}
+	// whether the keys left and right of h can be in the range
+	left, right := true, true
+	inside := true
+	if !lo.Unbounded {
+		cmp := r.compare(lo.Key, h.key)
+		left = cmp < 0
+		inside = cmp < 0 || (cmp == 0 && !lo.Exclusive)
+	}
+	if !hi.Unbounded {
+		cmp := r.compare(hi.Key, h.key)
+		right = cmp > 0
+		inside = inside && (cmp > 0 || (cmp == 0 && !hi.Exclusive))
+	}
+	if left {
+		if !r.boundedKeys(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	if inside {
+		if !visit(h.key, h.val) {
+			return false
+		}
+	}
+	if right {
+		if !r.boundedKeys(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
 // DeleteMin removes the smallest key and its value from the sorted map.
 func (r *SortedBytesToStringMap) DeleteMin() (oldk []byte, oldv string, ok bool) {
 	r.root, oldk, oldv, ok = r.deleteMin(r.root)
@@ -270,7 +507,7 @@ func (r *SortedBytesToStringMap) DeleteMin() (oldk []byte, oldv string, ok bool)
 	return
 }
 
-func (r *SortedBytesToStringMap) deleteMin(h *nodeBytesToString) (_ *nodeBytesToString, oldk []byte, oldv string, ok bool) {
+func (r *SortedBytesToStringMap) deleteMin(h *nodeSortedBytesToStringMap) (_ *nodeSortedBytesToStringMap, oldk []byte, oldv string, ok bool) {
 	if h == nil {
 		return nil, oldk, oldv, false
 	}
@@ -294,7 +531,7 @@ func (r *SortedBytesToStringMap) DeleteMax() (oldk []byte, oldv string, ok bool)
 	return
 }
 
-func (r *SortedBytesToStringMap) deleteMax(h *nodeBytesToString) (_ *nodeBytesToString, oldk []byte, oldv string, ok bool) {
+func (r *SortedBytesToStringMap) deleteMax(h *nodeSortedBytesToStringMap) (_ *nodeSortedBytesToStringMap, oldk []byte, oldv string, ok bool) {
 	if h == nil {
 		return nil, oldk, oldv, ok
 	}
@@ -323,7 +560,7 @@ func (r *SortedBytesToStringMap) Delete(k []byte) (old string, ok bool) {
 	return
 }
 
-func (r *SortedBytesToStringMap) delete(h *nodeBytesToString, k []byte) (_ *nodeBytesToString, old string, ok bool) {
+func (r *SortedBytesToStringMap) delete(h *nodeSortedBytesToStringMap, k []byte) (_ *nodeSortedBytesToStringMap, old string, ok bool) {
 
 	if h == nil {
 		return h, old, false
@@ -371,9 +608,123 @@ func (r *SortedBytesToStringMap) delete(h *nodeBytesToString, k []byte) (_ *node
 	return h, old, ok
 }
 
+// ToSlice returns the keys of the sorted map, in order, and their values.
+func (r SortedBytesToStringMap) ToSlice() (keys [][]byte, vals []string) {
+	keys = make([][]byte, 0, r.Size())
+	vals = make([]string, 0, r.Size())
+	r.Keys(func(k []byte, v string) bool {
+		keys = append(keys, k)
+		vals = append(vals, v)
+		return true
+	})
+	return keys, vals
+}
+
+// KeysSlice returns the keys of the sorted map, in order.
+func (r SortedBytesToStringMap) KeysSlice() [][]byte {
+	keys := make([][]byte, 0, r.Size())
+	r.Keys(func(k []byte, _ string) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+// ValuesSlice returns the values of the sorted map, in the order of their
+// keys.
+func (r SortedBytesToStringMap) ValuesSlice() []string {
+	vals := make([]string, 0, r.Size())
+	r.Keys(func(_ []byte, v string) bool {
+		vals = append(vals, v)
+		return true
+	})
+	return vals
+}
+
+// DeleteRange removes the keys between lo and hi and their values from the sorted map,
+// and returns how many it removed. It takes O(m log n) to remove m keys, or
+// O(n) when the range is a large part of the sorted map, which is then
+// rebuilt out of the keys left.
+func (r *SortedBytesToStringMap) DeleteRange(lo, hi SortedBytesToStringMapBound) int {
+	from, n, size := r.boundrank(lo, false), r.RangeCount(lo, hi), r.Size()
+	depth := 0
+	for s := size; s > 1; s /= 2 {
+		depth++
+	}
+	if n*depth < size {
+		for i := 0; i < n; i++ {
+			// the keys after those removed take their ranks
+			r.Delete(r.nodeselect(r.root, from).key)
+		}
+		return n
+	}
+
+	keys := make([][]byte, 0, size-n)
+	vals := make([]string, 0, size-n)
+	i := 0
+	r.Keys(func(k []byte, v string) bool {
+		if i < from || i >= from+n {
+			keys = append(keys, k)
+			vals = append(vals, v)
+		}
+		i++
+		return true
+	})
+	r.root = r.build(keys, vals)
+	return n
+}
+
+// construction
+
+// dedup removes the duplicates from the sorted keys in place, keeping the
+// first of equal keys with the value of the last, as putting them would.
+func (r SortedBytesToStringMap) dedup(keys [][]byte, vals []string) ([][]byte, []string) {
+	n := 0
+	for i := range keys {
+		if n > 0 && r.compare(keys[n-1], keys[i]) == 0 {
+			vals[n-1] = vals[i]
+			continue
+		}
+		keys[n], vals[n] = keys[i], vals[i]
+		n++
+	}
+	return keys[:n], vals[:n]
+}
+
+// build returns the root of a tree holding the sorted keys and their values,
+// in O(n). The tree is as balanced as can be, with the nodes of its last
+// level red if it's not full, and is fixed bottom up so that its red links
+// lean left.
+func (r *SortedBytesToStringMap) build(keys [][]byte, vals []string) *nodeSortedBytesToStringMap {
+	if len(keys) == 0 {
+		return nil
+	}
+	// the depth of the last level, counting from 0, if it's not full
+	redDepth := -1
+	if n := len(keys); n&(n+1) != 0 {
+		for redDepth = 0; n > 1; n /= 2 {
+			redDepth++
+		}
+	}
+	root := r.buildNode(keys, vals, 0, redDepth)
+	root.colorRed = false
+	return root
+}
+
+func (r *SortedBytesToStringMap) buildNode(keys [][]byte, vals []string, depth, redDepth int) *nodeSortedBytesToStringMap {
+	if len(keys) == 0 {
+		return nil
+	}
+	mid := len(keys) / 2
+	h := &nodeSortedBytesToStringMap{key: keys[mid], val: vals[mid], colorRed: depth == redDepth}
+	h.left = r.buildNode(keys[:mid], vals[:mid], depth+1, redDepth)
+	h.right = r.buildNode(keys[mid+1:], vals[mid+1:], depth+1, redDepth)
+	return r.balance(h)
+}
+
 // deletions
 
-func (r *SortedBytesToStringMap) moveRedLeft(h *nodeBytesToString) *nodeBytesToString {
+func (r *SortedBytesToStringMap) moveRedLeft(h *nodeSortedBytesToStringMap) *nodeSortedBytesToStringMap {
 	r.flipColors(h)
 	if h.right.left.isRed() {
 		h.right = r.rotateRight(h.right)
@@ -383,7 +734,7 @@ func (r *SortedBytesToStringMap) moveRedLeft(h *nodeBytesToString) *nodeBytesToS
 	return h
 }
 
-func (r *SortedBytesToStringMap) moveRedRight(h *nodeBytesToString) *nodeBytesToString {
+func (r *SortedBytesToStringMap) moveRedRight(h *nodeSortedBytesToStringMap) *nodeSortedBytesToStringMap {
 	r.flipColors(h)
 	if h.left.left.isRed() {
 		h = r.rotateRight(h)
@@ -392,7 +743,7 @@ func (r *SortedBytesToStringMap) moveRedRight(h *nodeBytesToString) *nodeBytesTo
 	return h
 }
 
-func (r *SortedBytesToStringMap) balance(h *nodeBytesToString) *nodeBytesToString {
+func (r *SortedBytesToStringMap) balance(h *nodeSortedBytesToStringMap) *nodeSortedBytesToStringMap {
 	if h.right.isRed() {
 		h = r.rotateLeft(h)
 	}
@@ -402,54 +753,182 @@ func (r *SortedBytesToStringMap) balance(h *nodeBytesToString) *nodeBytesToStrin
 	if h.left.isRed() && h.right.isRed() {
 		r.flipColors(h)
 	}
-	h.n = h.left.size() + h.right.size() + 1
+	r.update(h)
 	return h
 }
 
-func (r *SortedBytesToStringMap) rotateLeft(h *nodeBytesToString) *nodeBytesToString {
+func (r *SortedBytesToStringMap) rotateLeft(h *nodeSortedBytesToStringMap) *nodeSortedBytesToStringMap {
 	x := h.right
 	h.right = x.left
 	x.left = h
 	x.colorRed = h.colorRed
 	h.colorRed = true
-	x.n = h.n
-	h.n = 1 + h.left.size() + h.right.size()
+	r.update(h)
+	r.update(x)
 	return x
 }
 
-func (r *SortedBytesToStringMap) rotateRight(h *nodeBytesToString) *nodeBytesToString {
+func (r *SortedBytesToStringMap) rotateRight(h *nodeSortedBytesToStringMap) *nodeSortedBytesToStringMap {
 	x := h.left
 	h.left = x.right
 	x.right = h
 	x.colorRed = h.colorRed
 	h.colorRed = true
-	x.n = h.n
-	h.n = 1 + h.left.size() + h.right.size()
+	r.update(h)
+	r.update(x)
 	return x
 }
 
-func (r *SortedBytesToStringMap) flipColors(h *nodeBytesToString) {
+func (r *SortedBytesToStringMap) flipColors(h *nodeSortedBytesToStringMap) {
 	h.colorRed = !h.colorRed
 	h.left.colorRed = !h.left.colorRed
 	h.right.colorRed = !h.right.colorRed
 }
 
+// update what h holds about its tree, out of what its children hold.
+func (r *SortedBytesToStringMap) update(h *nodeSortedBytesToStringMap) {
+	h.n = 1 + h.left.size() + h.right.size()
+}
+
+// cursors
+
+// SortedBytesToStringMapCursor is a position among the keys of a sorted map, moved from key to key
+// in either order. Unlike Keys, it can be paused, or moved along the cursor
+// of another sorted map. It's only valid until the sorted map is modified.
+type SortedBytesToStringMapCursor struct {
+	r SortedBytesToStringMap
+	// path from the root to the node under the cursor, empty when the cursor
+	// isn't on a key
+	path []*nodeSortedBytesToStringMap
+}
+
+// NewCursor returns a cursor of the sorted map, which isn't on a key until it's
+// moved with First, Last or Seek.
+func (r SortedBytesToStringMap) NewCursor() *SortedBytesToStringMapCursor {
+	return &SortedBytesToStringMapCursor{r: r}
+}
+
+// Valid tells if the cursor is on a key.
+func (c *SortedBytesToStringMapCursor) Valid() bool { return len(c.path) != 0 }
+
+// First moves the cursor on the smallest key, and tells if there is one.
+func (c *SortedBytesToStringMapCursor) First() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.left {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Last moves the cursor on the largest key, and tells if there is one.
+func (c *SortedBytesToStringMapCursor) Last() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.right {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Seek moves the cursor on the smallest key larger than or equal to `k`, and
+// tells if there is one.
+func (c *SortedBytesToStringMapCursor) Seek(k []byte) bool {
+	c.path = c.path[:0]
+	// the ceiling is the last node left of which the search goes
+	ceiling := 0
+	for h := c.r.root; h != nil; {
+		c.path = append(c.path, h)
+		cmp := c.r.compare(k, h.key)
+		if cmp == 0 {
+			return true
+		} else if cmp < 0 {
+			ceiling = len(c.path)
+			h = h.left
+		} else {
+			h = h.right
+		}
+	}
+	c.path = c.path[:ceiling]
+	return c.Valid()
+}
+
+// Next moves the cursor on the following key, and tells if there is one.
+// Once past the largest key, the cursor isn't on a key anymore.
+func (c *SortedBytesToStringMapCursor) Next() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].right; h != nil {
+		for ; h != nil; h = h.left {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the left of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].left == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Prev moves the cursor on the preceding key, and tells if there is one.
+// Once past the smallest key, the cursor isn't on a key anymore.
+func (c *SortedBytesToStringMapCursor) Prev() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].left; h != nil {
+		for ; h != nil; h = h.right {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the right of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].right == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Key under the cursor. It panics if the cursor isn't on a key.
+func (c *SortedBytesToStringMapCursor) Key() []byte {
+	return c.node().key
+}
+
+// Value under the cursor. It panics if the cursor isn't on a key.
+func (c *SortedBytesToStringMapCursor) Value() string {
+	return c.node().val
+}
+
+func (c *SortedBytesToStringMapCursor) node() *nodeSortedBytesToStringMap {
+	if !c.Valid() {
+		panic("redblackbst: cursor isn't on a key")
+	}
+	return c.path[len(c.path)-1]
+}
+
 // nodes
 
-type nodeBytesToString struct {
+type nodeSortedBytesToStringMap struct {
 	key         []byte
 	val         string
-	left, right *nodeBytesToString
+	left, right *nodeSortedBytesToStringMap
 	n           int
 	colorRed    bool
 }
 
-func (x *nodeBytesToString) isRed() bool { return (x != nil) && (x.colorRed == true) }
+func (x *nodeSortedBytesToStringMap) isRed() bool { return (x != nil) && (x.colorRed == true) }
 
-func (x *nodeBytesToString) size() int {
+func (x *nodeSortedBytesToStringMap) size() int {
 	if x == nil {
 		return 0
 	}
 	return x.n
 }
-
@@ -1,27 +1,90 @@
+// Code generated by datagen; DO NOT EDIT.
+
 package codegen
 
+import "sort"
 
+// compare orders NaNs before any other value, including -Inf, and considers
+// them equal to each other. -0 and +0 are equal.
 func (r SortedFloat64ToStringMap) compare(a, b float64) int {
-	const e = 0.00000001
-
-    diff := (a-b)/a
-    if diff < -e {
-        return -1
-    } else if diff > e {
-        return 1
-    }
-    return 0
+	aNaN, bNaN := a != a, b != b
+	switch {
+	case aNaN && bNaN:
+		return 0
+	case aNaN:
+		return -1
+	case bNaN:
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
 }
 
 // SortedFloat64ToStringMap is a sorted map built on a left leaning red black balanced
 // search sorted map. It stores string values, keyed by float64.
 type SortedFloat64ToStringMap struct {
-	root *nodeFloat64ToString
+	root *nodeSortedFloat64ToStringMap
 }
 
 // NewSortedFloat64ToStringMap creates a sorted map.
 func NewSortedFloat64ToStringMap() *SortedFloat64ToStringMap { return &SortedFloat64ToStringMap{} }
 
+// NewSortedFloat64ToStringMapFromSorted creates a sorted map of the keys, given in order, and
+// of their values, in O(n) rather than the O(n log n) of putting them one by
+// one. Of equal keys, the first one is kept with the value of the last, as
+// putting them would. It panics if the keys aren't in order, or if there
+// isn't a value for each key.
+func NewSortedFloat64ToStringMapFromSorted(keys []float64, vals []string) *SortedFloat64ToStringMap {
+	r := &SortedFloat64ToStringMap{}
+	if len(keys) != len(vals) {
+		panic("redblackbst: as many keys as values are needed")
+	}
+	var dups bool
+	for i := 1; i < len(keys); i++ {
+		cmp := r.compare(keys[i-1], keys[i])
+		if cmp > 0 {
+			panic("redblackbst: keys aren't sorted")
+		}
+		dups = dups || cmp == 0
+	}
+	if dups {
+		// not to modify the slices given
+		keys, vals = r.dedup(append([]float64(nil), keys...), append([]string(nil), vals...))
+	}
+	r.root = r.build(keys, vals)
+	return r
+}
+
+// NewSortedFloat64ToStringMapFromUnsorted creates a sorted map of the keys and their values,
+// sorting them first, in O(n log n) but faster than putting them one by one.
+// Of equal keys, the first one is kept with the value of the last, as putting
+// them would. The slices aren't modified. It panics if there isn't a value
+// for each key.
+func NewSortedFloat64ToStringMapFromUnsorted(keys []float64, vals []string) *SortedFloat64ToStringMap {
+	r := &SortedFloat64ToStringMap{}
+	if len(keys) != len(vals) {
+		panic("redblackbst: as many keys as values are needed")
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return r.compare(keys[order[i]], keys[order[j]]) < 0
+	})
+	sortedKeys := make([]float64, len(keys))
+	sortedVals := make([]string, len(vals))
+	for i, at := range order {
+		sortedKeys[i], sortedVals[i] = keys[at], vals[at]
+	}
+	sortedKeys, sortedVals = r.dedup(sortedKeys, sortedVals)
+	r.root = r.build(sortedKeys, sortedVals)
+	return r
+}
+
 // IsEmpty tells if the sorted map contains no key/value.
 func (r SortedFloat64ToStringMap) IsEmpty() bool {
 	return r.root == nil
@@ -37,13 +100,15 @@ func (r *SortedFloat64ToStringMap) Clear() { r.root = nil }
 // if the key was already present.
 func (r *SortedFloat64ToStringMap) Put(k float64, v string) (old string, overwrite bool) {
 	r.root, old, overwrite = r.put(r.root, k, v)
+	r.root.colorRed = false
 	return
 }
 
-func (r *SortedFloat64ToStringMap) put(h *nodeFloat64ToString, k float64, v string) (_ *nodeFloat64ToString, old string, overwrite bool) {
+func (r *SortedFloat64ToStringMap) put(h *nodeSortedFloat64ToStringMap, k float64, v string) (_ *nodeSortedFloat64ToStringMap, old string, overwrite bool) {
 	if h == nil {
-		n := &nodeFloat64ToString{key: k, val: v, n: 1, colorRed: true}
-		return n, old, overwrite
+		h = &nodeSortedFloat64ToStringMap{key: k, val: v, colorRed: true}
+		r.update(h)
+		return h, old, overwrite
 	}
 
 	cmp := r.compare(k, h.key)
@@ -66,7 +131,7 @@ func (r *SortedFloat64ToStringMap) put(h *nodeFloat64ToString, k float64, v stri
 	if h.left.isRed() && h.right.isRed() {
 		r.flipColors(h)
 	}
-	h.n = h.left.size() + h.right.size() + 1
+	r.update(h)
 	return h, old, overwrite
 }
 
@@ -76,7 +141,7 @@ func (r SortedFloat64ToStringMap) Get(k float64) (string, bool) {
 	return r.loopGet(r.root, k)
 }
 
-func (r SortedFloat64ToStringMap) loopGet(h *nodeFloat64ToString, k float64) (v string, ok bool) {
+func (r SortedFloat64ToStringMap) loopGet(h *nodeSortedFloat64ToStringMap, k float64) (v string, ok bool) {
 	for h != nil {
 		cmp := r.compare(k, h.key)
 		if cmp == 0 {
@@ -105,7 +170,7 @@ func (r SortedFloat64ToStringMap) Min() (k float64, v string, ok bool) {
 	return h.key, h.val, true
 }
 
-func (r SortedFloat64ToStringMap) min(x *nodeFloat64ToString) *nodeFloat64ToString {
+func (r SortedFloat64ToStringMap) min(x *nodeSortedFloat64ToStringMap) *nodeSortedFloat64ToStringMap {
 	if x.left == nil {
 		return x
 	}
@@ -121,7 +186,7 @@ func (r SortedFloat64ToStringMap) Max() (k float64, v string, ok bool) {
 	return h.key, h.val, true
 }
 
-func (r SortedFloat64ToStringMap) max(x *nodeFloat64ToString) *nodeFloat64ToString {
+func (r SortedFloat64ToStringMap) max(x *nodeSortedFloat64ToStringMap) *nodeSortedFloat64ToStringMap {
 	if x.right == nil {
 		return x
 	}
@@ -129,7 +194,7 @@ func (r SortedFloat64ToStringMap) max(x *nodeFloat64ToString) *nodeFloat64ToStri
 }
 
 // Floor returns the largest key/value in the sorted map that is smaller than
-// `k`.
+// or equal to `k`, if it exists.
 func (r SortedFloat64ToStringMap) Floor(key float64) (k float64, v string, ok bool) {
 	x := r.floor(r.root, key)
 	if x == nil {
@@ -138,7 +203,7 @@ func (r SortedFloat64ToStringMap) Floor(key float64) (k float64, v string, ok bo
 	return x.key, x.val, true
 }
 
-func (r SortedFloat64ToStringMap) floor(h *nodeFloat64ToString, k float64) *nodeFloat64ToString {
+func (r SortedFloat64ToStringMap) floor(h *nodeSortedFloat64ToStringMap, k float64) *nodeSortedFloat64ToStringMap {
 	if h == nil {
 		return nil
 	}
@@ -157,7 +222,7 @@ func (r SortedFloat64ToStringMap) floor(h *nodeFloat64ToString, k float64) *node
 }
 
 // Ceiling returns the smallest key/value in the sorted map that is larger than
-// `k`.
+// or equal to `k`, if it exists.
 func (r SortedFloat64ToStringMap) Ceiling(key float64) (k float64, v string, ok bool) {
 	x := r.ceiling(r.root, key)
 	if x == nil {
@@ -166,7 +231,7 @@ func (r SortedFloat64ToStringMap) Ceiling(key float64) (k float64, v string, ok
 	return x.key, x.val, true
 }
 
-func (r SortedFloat64ToStringMap) ceiling(h *nodeFloat64ToString, k float64) *nodeFloat64ToString {
+func (r SortedFloat64ToStringMap) ceiling(h *nodeSortedFloat64ToStringMap, k float64) *nodeSortedFloat64ToStringMap {
 	if h == nil {
 		return nil
 	}
@@ -184,6 +249,54 @@ func (r SortedFloat64ToStringMap) ceiling(h *nodeFloat64ToString, k float64) *no
 	return h
 }
 
+// Lower returns the largest key/value in the sorted map that is strictly
+// smaller than `k`, its predecessor, if it exists.
+func (r SortedFloat64ToStringMap) Lower(key float64) (k float64, v string, ok bool) {
+	x := r.lower(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, x.val, true
+}
+
+func (r SortedFloat64ToStringMap) lower(h *nodeSortedFloat64ToStringMap, k float64) *nodeSortedFloat64ToStringMap {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) <= 0 {
+		return r.lower(h.left, k)
+	}
+	t := r.lower(h.right, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
+// Higher returns the smallest key/value in the sorted map that is strictly
+// larger than `k`, its successor, if it exists.
+func (r SortedFloat64ToStringMap) Higher(key float64) (k float64, v string, ok bool) {
+	x := r.higher(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, x.val, true
+}
+
+func (r SortedFloat64ToStringMap) higher(h *nodeSortedFloat64ToStringMap, k float64) *nodeSortedFloat64ToStringMap {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) >= 0 {
+		return r.higher(h.right, k)
+	}
+	t := r.higher(h.left, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
 // Select key of rank k, meaning the k-th biggest float64 in the sorted map.
 func (r SortedFloat64ToStringMap) Select(key int) (k float64, v string, ok bool) {
 	x := r.nodeselect(r.root, key)
@@ -193,7 +306,7 @@ func (r SortedFloat64ToStringMap) Select(key int) (k float64, v string, ok bool)
 	return x.key, x.val, true
 }
 
-func (r SortedFloat64ToStringMap) nodeselect(x *nodeFloat64ToString, k int) *nodeFloat64ToString {
+func (r SortedFloat64ToStringMap) nodeselect(x *nodeSortedFloat64ToStringMap, k int) *nodeSortedFloat64ToStringMap {
 	if x == nil {
 		return nil
 	}
@@ -212,7 +325,7 @@ func (r SortedFloat64ToStringMap) Rank(k float64) int {
 	return r.keyrank(k, r.root)
 }
 
-func (r SortedFloat64ToStringMap) keyrank(k float64, h *nodeFloat64ToString) int {
+func (r SortedFloat64ToStringMap) keyrank(k float64, h *nodeSortedFloat64ToStringMap) int {
 	if h == nil {
 		return 0
 	}
@@ -244,7 +357,7 @@ func (r SortedFloat64ToStringMap) RangedKeys(lo, hi float64, visit func(float64,
 	r.keys(r.root, visit, lo, hi)
 }
 
-func (r SortedFloat64ToStringMap) keys(h *nodeFloat64ToString, visit func(float64, string) bool, lo, hi float64) bool {
+func (r SortedFloat64ToStringMap) keys(h *nodeSortedFloat64ToStringMap, visit func(float64, string) bool, lo, hi float64) bool {
 	if h == nil {
 		return true
 	}
@@ -268,6 +381,136 @@ func (r SortedFloat64ToStringMap) keys(h *nodeFloat64ToString, visit func(float6
 	return true
 }
 
+// ReverseKeys visit each keys in the sorted map, in reverse order.
+// It stops when visit returns false.
+func (r SortedFloat64ToStringMap) ReverseKeys(visit func(float64, string) bool) {
+	min, _, ok := r.Min()
+	if !ok {
+		return
+	}
+	// if the min exists, then the max must exist
+	max, _, _ := r.Max()
+	r.RangedKeysDesc(min, max, visit)
+}
+
+// RangedKeysDesc visit each keys between lo and hi in the sorted map, in
+// reverse order. It stops when visit returns false.
+func (r SortedFloat64ToStringMap) RangedKeysDesc(lo, hi float64, visit func(float64, string) bool) {
+	r.keysDesc(r.root, visit, lo, hi)
+}
+
+func (r SortedFloat64ToStringMap) keysDesc(h *nodeSortedFloat64ToStringMap, visit func(float64, string) bool, lo, hi float64) bool {
+	if h == nil {
+		return true
+	}
+	cmplo := r.compare(lo, h.key)
+	cmphi := r.compare(hi, h.key)
+	if cmphi > 0 {
+		if !r.keysDesc(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	if cmplo <= 0 && cmphi >= 0 {
+		if !visit(h.key, h.val) {
+			return false
+		}
+	}
+	if cmplo < 0 {
+		if !r.keysDesc(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
+// SortedFloat64ToStringMapBound is an end of a range of keys: its Key, included in the range unless
+// it's Exclusive. An Unbounded end doesn't limit the range, whatever its Key.
+type SortedFloat64ToStringMapBound struct {
+	Key       float64
+	Exclusive bool
+	Unbounded bool
+}
+
+// RangeCount is the number of keys between lo and hi in the sorted map. It
+// doesn't visit them, and takes O(log n).
+func (r SortedFloat64ToStringMap) RangeCount(lo, hi SortedFloat64ToStringMapBound) int {
+	n := r.boundrank(hi, true) - r.boundrank(lo, false)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// boundrank is the number of keys below b, or up to b for an upper bound.
+func (r SortedFloat64ToStringMap) boundrank(b SortedFloat64ToStringMapBound, upper bool) int {
+	if b.Unbounded {
+		if upper {
+			return r.Size()
+		}
+		return 0
+	}
+	// the key itself is below an exclusive lower bound or an inclusive
+	// upper bound
+	return r.keyrankBound(b.Key, r.root, upper != b.Exclusive)
+}
+
+func (r SortedFloat64ToStringMap) keyrankBound(k float64, h *nodeSortedFloat64ToStringMap, withKey bool) int {
+	if h == nil {
+		return 0
+	}
+	cmp := r.compare(k, h.key)
+	if cmp < 0 {
+		return r.keyrankBound(k, h.left, withKey)
+	} else if cmp > 0 {
+		return 1 + h.left.size() + r.keyrankBound(k, h.right, withKey)
+	} else if withKey {
+		return h.left.size() + 1
+	} else {
+		return h.left.size()
+	}
+}
+
+// BoundedKeys visit each keys between lo and hi in the sorted map, in order.
+// It stops when visit returns false.
+func (r SortedFloat64ToStringMap) BoundedKeys(lo, hi SortedFloat64ToStringMapBound, visit func(float64, string) bool) {
+	r.boundedKeys(r.root, visit, lo, hi)
+}
+
+func (r SortedFloat64ToStringMap) boundedKeys(h *nodeSortedFloat64ToStringMap, visit func(float64, string) bool, lo, hi SortedFloat64ToStringMapBound) bool {
+	if h == nil {
+		return true
+	}
+	// whether the keys left and right of h can be in the range
+	left, right := true, true
+	inside := true
+	if !lo.Unbounded {
+		cmp := r.compare(lo.Key, h.key)
+		left = cmp < 0
+		inside = cmp < 0 || (cmp == 0 && !lo.Exclusive)
+	}
+	if !hi.Unbounded {
+		cmp := r.compare(hi.Key, h.key)
+		right = cmp > 0
+		inside = inside && (cmp > 0 || (cmp == 0 && !hi.Exclusive))
+	}
+	if left {
+		if !r.boundedKeys(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	if inside {
+		if !visit(h.key, h.val) {
+			return false
+		}
+	}
+	if right {
+		if !r.boundedKeys(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
 // DeleteMin removes the smallest key and its value from the sorted map.
 func (r *SortedFloat64ToStringMap) DeleteMin() (oldk float64, oldv string, ok bool) {
 	r.root, oldk, oldv, ok = r.deleteMin(r.root)
@@ -277,7 +520,7 @@ func (r *SortedFloat64ToStringMap) DeleteMin() (oldk float64, oldv string, ok bo
 	return
 }
 
-func (r *SortedFloat64ToStringMap) deleteMin(h *nodeFloat64ToString) (_ *nodeFloat64ToString, oldk float64, oldv string, ok bool) {
+func (r *SortedFloat64ToStringMap) deleteMin(h *nodeSortedFloat64ToStringMap) (_ *nodeSortedFloat64ToStringMap, oldk float64, oldv string, ok bool) {
 	if h == nil {
 		return nil, oldk, oldv, false
 	}
@@ -301,7 +544,7 @@ func (r *SortedFloat64ToStringMap) DeleteMax() (oldk float64, oldv string, ok bo
 	return
 }
 
-func (r *SortedFloat64ToStringMap) deleteMax(h *nodeFloat64ToString) (_ *nodeFloat64ToString, oldk float64, oldv string, ok bool) {
+func (r *SortedFloat64ToStringMap) deleteMax(h *nodeSortedFloat64ToStringMap) (_ *nodeSortedFloat64ToStringMap, oldk float64, oldv string, ok bool) {
 	if h == nil {
 		return nil, oldk, oldv, ok
 	}
@@ -330,7 +573,7 @@ func (r *SortedFloat64ToStringMap) Delete(k float64) (old string, ok bool) {
 	return
 }
 
-func (r *SortedFloat64ToStringMap) delete(h *nodeFloat64ToString, k float64) (_ *nodeFloat64ToString, old string, ok bool) {
+func (r *SortedFloat64ToStringMap) delete(h *nodeSortedFloat64ToStringMap, k float64) (_ *nodeSortedFloat64ToStringMap, old string, ok bool) {
 
 	if h == nil {
 		return h, old, false
@@ -378,9 +621,123 @@ func (r *SortedFloat64ToStringMap) delete(h *nodeFloat64ToString, k float64) (_
 	return h, old, ok
 }
 
+// ToSlice returns the keys of the sorted map, in order, and their values.
+func (r SortedFloat64ToStringMap) ToSlice() (keys []float64, vals []string) {
+	keys = make([]float64, 0, r.Size())
+	vals = make([]string, 0, r.Size())
+	r.Keys(func(k float64, v string) bool {
+		keys = append(keys, k)
+		vals = append(vals, v)
+		return true
+	})
+	return keys, vals
+}
+
+// KeysSlice returns the keys of the sorted map, in order.
+func (r SortedFloat64ToStringMap) KeysSlice() []float64 {
+	keys := make([]float64, 0, r.Size())
+	r.Keys(func(k float64, _ string) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+// ValuesSlice returns the values of the sorted map, in the order of their
+// keys.
+func (r SortedFloat64ToStringMap) ValuesSlice() []string {
+	vals := make([]string, 0, r.Size())
+	r.Keys(func(_ float64, v string) bool {
+		vals = append(vals, v)
+		return true
+	})
+	return vals
+}
+
+// DeleteRange removes the keys between lo and hi and their values from the sorted map,
+// and returns how many it removed. It takes O(m log n) to remove m keys, or
+// O(n) when the range is a large part of the sorted map, which is then
+// rebuilt out of the keys left.
+func (r *SortedFloat64ToStringMap) DeleteRange(lo, hi SortedFloat64ToStringMapBound) int {
+	from, n, size := r.boundrank(lo, false), r.RangeCount(lo, hi), r.Size()
+	depth := 0
+	for s := size; s > 1; s /= 2 {
+		depth++
+	}
+	if n*depth < size {
+		for i := 0; i < n; i++ {
+			// the keys after those removed take their ranks
+			r.Delete(r.nodeselect(r.root, from).key)
+		}
+		return n
+	}
+
+	keys := make([]float64, 0, size-n)
+	vals := make([]string, 0, size-n)
+	i := 0
+	r.Keys(func(k float64, v string) bool {
+		if i < from || i >= from+n {
+			keys = append(keys, k)
+			vals = append(vals, v)
+		}
+		i++
+		return true
+	})
+	r.root = r.build(keys, vals)
+	return n
+}
+
+// construction
+
+// dedup removes the duplicates from the sorted keys in place, keeping the
+// first of equal keys with the value of the last, as putting them would.
+func (r SortedFloat64ToStringMap) dedup(keys []float64, vals []string) ([]float64, []string) {
+	n := 0
+	for i := range keys {
+		if n > 0 && r.compare(keys[n-1], keys[i]) == 0 {
+			vals[n-1] = vals[i]
+			continue
+		}
+		keys[n], vals[n] = keys[i], vals[i]
+		n++
+	}
+	return keys[:n], vals[:n]
+}
+
+// build returns the root of a tree holding the sorted keys and their values,
+// in O(n). The tree is as balanced as can be, with the nodes of its last
+// level red if it's not full, and is fixed bottom up so that its red links
+// lean left.
+func (r *SortedFloat64ToStringMap) build(keys []float64, vals []string) *nodeSortedFloat64ToStringMap {
+	if len(keys) == 0 {
+		return nil
+	}
+	// the depth of the last level, counting from 0, if it's not full
+	redDepth := -1
+	if n := len(keys); n&(n+1) != 0 {
+		for redDepth = 0; n > 1; n /= 2 {
+			redDepth++
+		}
+	}
+	root := r.buildNode(keys, vals, 0, redDepth)
+	root.colorRed = false
+	return root
+}
+
+func (r *SortedFloat64ToStringMap) buildNode(keys []float64, vals []string, depth, redDepth int) *nodeSortedFloat64ToStringMap {
+	if len(keys) == 0 {
+		return nil
+	}
+	mid := len(keys) / 2
+	h := &nodeSortedFloat64ToStringMap{key: keys[mid], val: vals[mid], colorRed: depth == redDepth}
+	h.left = r.buildNode(keys[:mid], vals[:mid], depth+1, redDepth)
+	h.right = r.buildNode(keys[mid+1:], vals[mid+1:], depth+1, redDepth)
+	return r.balance(h)
+}
+
 // deletions
 
-func (r *SortedFloat64ToStringMap) moveRedLeft(h *nodeFloat64ToString) *nodeFloat64ToString {
+func (r *SortedFloat64ToStringMap) moveRedLeft(h *nodeSortedFloat64ToStringMap) *nodeSortedFloat64ToStringMap {
 	r.flipColors(h)
 	if h.right.left.isRed() {
 		h.right = r.rotateRight(h.right)
@@ -390,7 +747,7 @@ func (r *SortedFloat64ToStringMap) moveRedLeft(h *nodeFloat64ToString) *nodeFloa
 	return h
 }
 
-func (r *SortedFloat64ToStringMap) moveRedRight(h *nodeFloat64ToString) *nodeFloat64ToString {
+func (r *SortedFloat64ToStringMap) moveRedRight(h *nodeSortedFloat64ToStringMap) *nodeSortedFloat64ToStringMap {
 	r.flipColors(h)
 	if h.left.left.isRed() {
 		h = r.rotateRight(h)
@@ -399,7 +756,7 @@ func (r *SortedFloat64ToStringMap) moveRedRight(h *nodeFloat64ToString) *nodeFlo
 	return h
 }
 
-func (r *SortedFloat64ToStringMap) balance(h *nodeFloat64ToString) *nodeFloat64ToString {
+func (r *SortedFloat64ToStringMap) balance(h *nodeSortedFloat64ToStringMap) *nodeSortedFloat64ToStringMap {
 	if h.right.isRed() {
 		h = r.rotateLeft(h)
 	}
@@ -409,54 +766,182 @@ func (r *SortedFloat64ToStringMap) balance(h *nodeFloat64ToString) *nodeFloat64T
 	if h.left.isRed() && h.right.isRed() {
 		r.flipColors(h)
 	}
-	h.n = h.left.size() + h.right.size() + 1
+	r.update(h)
 	return h
 }
 
-func (r *SortedFloat64ToStringMap) rotateLeft(h *nodeFloat64ToString) *nodeFloat64ToString {
+func (r *SortedFloat64ToStringMap) rotateLeft(h *nodeSortedFloat64ToStringMap) *nodeSortedFloat64ToStringMap {
 	x := h.right
 	h.right = x.left
 	x.left = h
 	x.colorRed = h.colorRed
 	h.colorRed = true
-	x.n = h.n
-	h.n = 1 + h.left.size() + h.right.size()
+	r.update(h)
+	r.update(x)
 	return x
 }
 
-func (r *SortedFloat64ToStringMap) rotateRight(h *nodeFloat64ToString) *nodeFloat64ToString {
+func (r *SortedFloat64ToStringMap) rotateRight(h *nodeSortedFloat64ToStringMap) *nodeSortedFloat64ToStringMap {
 	x := h.left
 	h.left = x.right
 	x.right = h
 	x.colorRed = h.colorRed
 	h.colorRed = true
-	x.n = h.n
-	h.n = 1 + h.left.size() + h.right.size()
+	r.update(h)
+	r.update(x)
 	return x
 }
 
-func (r *SortedFloat64ToStringMap) flipColors(h *nodeFloat64ToString) {
+func (r *SortedFloat64ToStringMap) flipColors(h *nodeSortedFloat64ToStringMap) {
 	h.colorRed = !h.colorRed
 	h.left.colorRed = !h.left.colorRed
 	h.right.colorRed = !h.right.colorRed
 }
 
+// update what h holds about its tree, out of what its children hold.
+func (r *SortedFloat64ToStringMap) update(h *nodeSortedFloat64ToStringMap) {
+	h.n = 1 + h.left.size() + h.right.size()
+}
+
+// cursors
+
+// SortedFloat64ToStringMapCursor is a position among the keys of a sorted map, moved from key to key
+// in either order. Unlike Keys, it can be paused, or moved along the cursor
+// of another sorted map. It's only valid until the sorted map is modified.
+type SortedFloat64ToStringMapCursor struct {
+	r SortedFloat64ToStringMap
+	// path from the root to the node under the cursor, empty when the cursor
+	// isn't on a key
+	path []*nodeSortedFloat64ToStringMap
+}
+
+// NewCursor returns a cursor of the sorted map, which isn't on a key until it's
+// moved with First, Last or Seek.
+func (r SortedFloat64ToStringMap) NewCursor() *SortedFloat64ToStringMapCursor {
+	return &SortedFloat64ToStringMapCursor{r: r}
+}
+
+// Valid tells if the cursor is on a key.
+func (c *SortedFloat64ToStringMapCursor) Valid() bool { return len(c.path) != 0 }
+
+// First moves the cursor on the smallest key, and tells if there is one.
+func (c *SortedFloat64ToStringMapCursor) First() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.left {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Last moves the cursor on the largest key, and tells if there is one.
+func (c *SortedFloat64ToStringMapCursor) Last() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.right {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Seek moves the cursor on the smallest key larger than or equal to `k`, and
+// tells if there is one.
+func (c *SortedFloat64ToStringMapCursor) Seek(k float64) bool {
+	c.path = c.path[:0]
+	// the ceiling is the last node left of which the search goes
+	ceiling := 0
+	for h := c.r.root; h != nil; {
+		c.path = append(c.path, h)
+		cmp := c.r.compare(k, h.key)
+		if cmp == 0 {
+			return true
+		} else if cmp < 0 {
+			ceiling = len(c.path)
+			h = h.left
+		} else {
+			h = h.right
+		}
+	}
+	c.path = c.path[:ceiling]
+	return c.Valid()
+}
+
+// Next moves the cursor on the following key, and tells if there is one.
+// Once past the largest key, the cursor isn't on a key anymore.
+func (c *SortedFloat64ToStringMapCursor) Next() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].right; h != nil {
+		for ; h != nil; h = h.left {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the left of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].left == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Prev moves the cursor on the preceding key, and tells if there is one.
+// Once past the smallest key, the cursor isn't on a key anymore.
+func (c *SortedFloat64ToStringMapCursor) Prev() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].left; h != nil {
+		for ; h != nil; h = h.right {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the right of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].right == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Key under the cursor. It panics if the cursor isn't on a key.
+func (c *SortedFloat64ToStringMapCursor) Key() float64 {
+	return c.node().key
+}
+
+// Value under the cursor. It panics if the cursor isn't on a key.
+func (c *SortedFloat64ToStringMapCursor) Value() string {
+	return c.node().val
+}
+
+func (c *SortedFloat64ToStringMapCursor) node() *nodeSortedFloat64ToStringMap {
+	if !c.Valid() {
+		panic("redblackbst: cursor isn't on a key")
+	}
+	return c.path[len(c.path)-1]
+}
+
 // nodes
 
-type nodeFloat64ToString struct {
+type nodeSortedFloat64ToStringMap struct {
 	key         float64
 	val         string
-	left, right *nodeFloat64ToString
+	left, right *nodeSortedFloat64ToStringMap
 	n           int
 	colorRed    bool
 }
 
-func (x *nodeFloat64ToString) isRed() bool { return (x != nil) && (x.colorRed == true) }
+func (x *nodeSortedFloat64ToStringMap) isRed() bool { return (x != nil) && (x.colorRed == true) }
 
-func (x *nodeFloat64ToString) size() int {
+func (x *nodeSortedFloat64ToStringMap) size() int {
 	if x == nil {
 		return 0
 	}
 	return x.n
 }
-
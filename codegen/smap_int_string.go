@@ -1,16 +1,81 @@
+// Code generated by datagen; DO NOT EDIT.
+
 package codegen
 
-func (r SortedIntToStringMap) compare(a, b int) int { return int(a) - int(b) }
+import "sort"
+
+func (r SortedIntToStringMap) compare(a, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
 
 // SortedIntToStringMap is a sorted map built on a left leaning red black balanced
 // search sorted map. It stores string values, keyed by int.
 type SortedIntToStringMap struct {
-	root *nodeIntToString
+	root *nodeSortedIntToStringMap
 }
 
 // NewSortedIntToStringMap creates a sorted map.
 func NewSortedIntToStringMap() *SortedIntToStringMap { return &SortedIntToStringMap{} }
 
+// NewSortedIntToStringMapFromSorted creates a sorted map of the keys, given in order, and
+// of their values, in O(n) rather than the O(n log n) of putting them one by
+// one. Of equal keys, the first one is kept with the value of the last, as
+// putting them would. It panics if the keys aren't in order, or if there
+// isn't a value for each key.
+func NewSortedIntToStringMapFromSorted(keys []int, vals []string) *SortedIntToStringMap {
+	r := &SortedIntToStringMap{}
+	if len(keys) != len(vals) {
+		panic("redblackbst: as many keys as values are needed")
+	}
+	var dups bool
+	for i := 1; i < len(keys); i++ {
+		cmp := r.compare(keys[i-1], keys[i])
+		if cmp > 0 {
+			panic("redblackbst: keys aren't sorted")
+		}
+		dups = dups || cmp == 0
+	}
+	if dups {
+		// not to modify the slices given
+		keys, vals = r.dedup(append([]int(nil), keys...), append([]string(nil), vals...))
+	}
+	r.root = r.build(keys, vals)
+	return r
+}
+
+// NewSortedIntToStringMapFromUnsorted creates a sorted map of the keys and their values,
+// sorting them first, in O(n log n) but faster than putting them one by one.
+// Of equal keys, the first one is kept with the value of the last, as putting
+// them would. The slices aren't modified. It panics if there isn't a value
+// for each key.
+func NewSortedIntToStringMapFromUnsorted(keys []int, vals []string) *SortedIntToStringMap {
+	r := &SortedIntToStringMap{}
+	if len(keys) != len(vals) {
+		panic("redblackbst: as many keys as values are needed")
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return r.compare(keys[order[i]], keys[order[j]]) < 0
+	})
+	sortedKeys := make([]int, len(keys))
+	sortedVals := make([]string, len(vals))
+	for i, at := range order {
+		sortedKeys[i], sortedVals[i] = keys[at], vals[at]
+	}
+	sortedKeys, sortedVals = r.dedup(sortedKeys, sortedVals)
+	r.root = r.build(sortedKeys, sortedVals)
+	return r
+}
+
 // IsEmpty tells if the sorted map contains no key/value.
 func (r SortedIntToStringMap) IsEmpty() bool {
 	return r.root == nil
@@ -26,13 +91,15 @@ func (r *SortedIntToStringMap) Clear() { r.root = nil }
 // if the key was already present.
 func (r *SortedIntToStringMap) Put(k int, v string) (old string, overwrite bool) {
 	r.root, old, overwrite = r.put(r.root, k, v)
+	r.root.colorRed = false
 	return
 }
 
-func (r *SortedIntToStringMap) put(h *nodeIntToString, k int, v string) (_ *nodeIntToString, old string, overwrite bool) {
+func (r *SortedIntToStringMap) put(h *nodeSortedIntToStringMap, k int, v string) (_ *nodeSortedIntToStringMap, old string, overwrite bool) {
 	if h == nil {
-		n := &nodeIntToString{key: k, val: v, n: 1, colorRed: true}
-		return n, old, overwrite
+		h = &nodeSortedIntToStringMap{key: k, val: v, colorRed: true}
+		r.update(h)
+		return h, old, overwrite
 	}
 
 	cmp := r.compare(k, h.key)
@@ -55,7 +122,7 @@ func (r *SortedIntToStringMap) put(h *nodeIntToString, k int, v string) (_ *node
 	if h.left.isRed() && h.right.isRed() {
 		r.flipColors(h)
 	}
-	h.n = h.left.size() + h.right.size() + 1
+	r.update(h)
 	return h, old, overwrite
 }
 
@@ -65,7 +132,7 @@ func (r SortedIntToStringMap) Get(k int) (string, bool) {
 	return r.loopGet(r.root, k)
 }
 
-func (r SortedIntToStringMap) loopGet(h *nodeIntToString, k int) (v string, ok bool) {
+func (r SortedIntToStringMap) loopGet(h *nodeSortedIntToStringMap, k int) (v string, ok bool) {
 	for h != nil {
 		cmp := r.compare(k, h.key)
 		if cmp == 0 {
@@ -94,7 +161,7 @@ func (r SortedIntToStringMap) Min() (k int, v string, ok bool) {
 	return h.key, h.val, true
 }
 
-func (r SortedIntToStringMap) min(x *nodeIntToString) *nodeIntToString {
+func (r SortedIntToStringMap) min(x *nodeSortedIntToStringMap) *nodeSortedIntToStringMap {
 	if x.left == nil {
 		return x
 	}
@@ -110,7 +177,7 @@ func (r SortedIntToStringMap) Max() (k int, v string, ok bool) {
 	return h.key, h.val, true
 }
 
-func (r SortedIntToStringMap) max(x *nodeIntToString) *nodeIntToString {
+func (r SortedIntToStringMap) max(x *nodeSortedIntToStringMap) *nodeSortedIntToStringMap {
 	if x.right == nil {
 		return x
 	}
@@ -118,7 +185,7 @@ func (r SortedIntToStringMap) max(x *nodeIntToString) *nodeIntToString {
 }
 
 // Floor returns the largest key/value in the sorted map that is smaller than
-// `k`.
+// or equal to `k`, if it exists.
 func (r SortedIntToStringMap) Floor(key int) (k int, v string, ok bool) {
 	x := r.floor(r.root, key)
 	if x == nil {
@@ -127,7 +194,7 @@ func (r SortedIntToStringMap) Floor(key int) (k int, v string, ok bool) {
 	return x.key, x.val, true
 }
 
-func (r SortedIntToStringMap) floor(h *nodeIntToString, k int) *nodeIntToString {
+func (r SortedIntToStringMap) floor(h *nodeSortedIntToStringMap, k int) *nodeSortedIntToStringMap {
 	if h == nil {
 		return nil
 	}
@@ -146,7 +213,7 @@ func (r SortedIntToStringMap) floor(h *nodeIntToString, k int) *nodeIntToString
 }
 
 // Ceiling returns the smallest key/value in the sorted map that is larger than
-// `k`.
+// or equal to `k`, if it exists.
 func (r SortedIntToStringMap) Ceiling(key int) (k int, v string, ok bool) {
 	x := r.ceiling(r.root, key)
 	if x == nil {
@@ -155,7 +222,7 @@ func (r SortedIntToStringMap) Ceiling(key int) (k int, v string, ok bool) {
 	return x.key, x.val, true
 }
 
-func (r SortedIntToStringMap) ceiling(h *nodeIntToString, k int) *nodeIntToString {
+func (r SortedIntToStringMap) ceiling(h *nodeSortedIntToStringMap, k int) *nodeSortedIntToStringMap {
 	if h == nil {
 		return nil
 	}
@@ -173,6 +240,54 @@ func (r SortedIntToStringMap) ceiling(h *nodeIntToString, k int) *nodeIntToStrin
 	return h
 }
 
+// Lower returns the largest key/value in the sorted map that is strictly
+// smaller than `k`, its predecessor, if it exists.
+func (r SortedIntToStringMap) Lower(key int) (k int, v string, ok bool) {
+	x := r.lower(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, x.val, true
+}
+
+func (r SortedIntToStringMap) lower(h *nodeSortedIntToStringMap, k int) *nodeSortedIntToStringMap {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) <= 0 {
+		return r.lower(h.left, k)
+	}
+	t := r.lower(h.right, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
+// Higher returns the smallest key/value in the sorted map that is strictly
+// larger than `k`, its successor, if it exists.
+func (r SortedIntToStringMap) Higher(key int) (k int, v string, ok bool) {
+	x := r.higher(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, x.val, true
+}
+
+func (r SortedIntToStringMap) higher(h *nodeSortedIntToStringMap, k int) *nodeSortedIntToStringMap {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) >= 0 {
+		return r.higher(h.right, k)
+	}
+	t := r.higher(h.left, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
 // Select key of rank k, meaning the k-th biggest int in the sorted map.
 func (r SortedIntToStringMap) Select(key int) (k int, v string, ok bool) {
 	x := r.nodeselect(r.root, key)
@@ -182,7 +297,7 @@ func (r SortedIntToStringMap) Select(key int) (k int, v string, ok bool) {
 	return x.key, x.val, true
 }
 
-func (r SortedIntToStringMap) nodeselect(x *nodeIntToString, k int) *nodeIntToString {
+func (r SortedIntToStringMap) nodeselect(x *nodeSortedIntToStringMap, k int) *nodeSortedIntToStringMap {
 	if x == nil {
 		return nil
 	}
@@ -201,7 +316,7 @@ func (r SortedIntToStringMap) Rank(k int) int {
 	return r.keyrank(k, r.root)
 }
 
-func (r SortedIntToStringMap) keyrank(k int, h *nodeIntToString) int {
+func (r SortedIntToStringMap) keyrank(k int, h *nodeSortedIntToStringMap) int {
 	if h == nil {
 		return 0
 	}
@@ -233,7 +348,7 @@ func (r SortedIntToStringMap) RangedKeys(lo, hi int, visit func(int, string) boo
 	r.keys(r.root, visit, lo, hi)
 }
 
-func (r SortedIntToStringMap) keys(h *nodeIntToString, visit func(int, string) bool, lo, hi int) bool {
+func (r SortedIntToStringMap) keys(h *nodeSortedIntToStringMap, visit func(int, string) bool, lo, hi int) bool {
 	if h == nil {
 		return true
 	}
@@ -257,6 +372,136 @@ func (r SortedIntToStringMap) keys(h *nodeIntToString, visit func(int, string) b
 	return true
 }
 
+// ReverseKeys visit each keys in the sorted map, in reverse order.
+// It stops when visit returns false.
+func (r SortedIntToStringMap) ReverseKeys(visit func(int, string) bool) {
+	min, _, ok := r.Min()
+	if !ok {
+		return
+	}
+	// if the min exists, then the max must exist
+	max, _, _ := r.Max()
+	r.RangedKeysDesc(min, max, visit)
+}
+
+// RangedKeysDesc visit each keys between lo and hi in the sorted map, in
+// reverse order. It stops when visit returns false.
+func (r SortedIntToStringMap) RangedKeysDesc(lo, hi int, visit func(int, string) bool) {
+	r.keysDesc(r.root, visit, lo, hi)
+}
+
+func (r SortedIntToStringMap) keysDesc(h *nodeSortedIntToStringMap, visit func(int, string) bool, lo, hi int) bool {
+	if h == nil {
+		return true
+	}
+	cmplo := r.compare(lo, h.key)
+	cmphi := r.compare(hi, h.key)
+	if cmphi > 0 {
+		if !r.keysDesc(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	if cmplo <= 0 && cmphi >= 0 {
+		if !visit(h.key, h.val) {
+			return false
+		}
+	}
+	if cmplo < 0 {
+		if !r.keysDesc(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
+// SortedIntToStringMapBound is an end of a range of keys: its Key, included in the range unless
+// it's Exclusive. An Unbounded end doesn't limit the range, whatever its Key.
+type SortedIntToStringMapBound struct {
+	Key       int
+	Exclusive bool
+	Unbounded bool
+}
+
+// RangeCount is the number of keys between lo and hi in the sorted map. It
+// doesn't visit them, and takes O(log n).
+func (r SortedIntToStringMap) RangeCount(lo, hi SortedIntToStringMapBound) int {
+	n := r.boundrank(hi, true) - r.boundrank(lo, false)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// boundrank is the number of keys below b, or up to b for an upper bound.
+func (r SortedIntToStringMap) boundrank(b SortedIntToStringMapBound, upper bool) int {
+	if b.Unbounded {
+		if upper {
+			return r.Size()
+		}
+		return 0
+	}
+	// the key itself is below an exclusive lower bound or an inclusive
+	// upper bound
+	return r.keyrankBound(b.Key, r.root, upper != b.Exclusive)
+}
+
+func (r SortedIntToStringMap) keyrankBound(k int, h *nodeSortedIntToStringMap, withKey bool) int {
+	if h == nil {
+		return 0
+	}
+	cmp := r.compare(k, h.key)
+	if cmp < 0 {
+		return r.keyrankBound(k, h.left, withKey)
+	} else if cmp > 0 {
+		return 1 + h.left.size() + r.keyrankBound(k, h.right, withKey)
+	} else if withKey {
+		return h.left.size() + 1
+	} else {
+		return h.left.size()
+	}
+}
+
+// BoundedKeys visit each keys between lo and hi in the sorted map, in order.
+// It stops when visit returns false.
+func (r SortedIntToStringMap) BoundedKeys(lo, hi SortedIntToStringMapBound, visit func(int, string) bool) {
+	r.boundedKeys(r.root, visit, lo, hi)
+}
+
+func (r SortedIntToStringMap) boundedKeys(h *nodeSortedIntToStringMap, visit func(int, string) bool, lo, hi SortedIntToStringMapBound) bool {
+	if h == nil {
+		return true
+	}
+	// whether the keys left and right of h can be in the range
+	left, right := true, true
+	inside := true
+	if !lo.Unbounded {
+		cmp := r.compare(lo.Key, h.key)
+		left = cmp < 0
+		inside = cmp < 0 || (cmp == 0 && !lo.Exclusive)
+	}
+	if !hi.Unbounded {
+		cmp := r.compare(hi.Key, h.key)
+		right = cmp > 0
+		inside = inside && (cmp > 0 || (cmp == 0 && !hi.Exclusive))
+	}
+	if left {
+		if !r.boundedKeys(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	if inside {
+		if !visit(h.key, h.val) {
+			return false
+		}
+	}
+	if right {
+		if !r.boundedKeys(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
 // DeleteMin removes the smallest key and its value from the sorted map.
 func (r *SortedIntToStringMap) DeleteMin() (oldk int, oldv string, ok bool) {
 	r.root, oldk, oldv, ok = r.deleteMin(r.root)
@@ -266,7 +511,7 @@ func (r *SortedIntToStringMap) DeleteMin() (oldk int, oldv string, ok bool) {
 	return
 }
 
-func (r *SortedIntToStringMap) deleteMin(h *nodeIntToString) (_ *nodeIntToString, oldk int, oldv string, ok bool) {
+func (r *SortedIntToStringMap) deleteMin(h *nodeSortedIntToStringMap) (_ *nodeSortedIntToStringMap, oldk int, oldv string, ok bool) {
 	if h == nil {
 		return nil, oldk, oldv, false
 	}
@@ -290,7 +535,7 @@ func (r *SortedIntToStringMap) DeleteMax() (oldk int, oldv string, ok bool) {
 	return
 }
 
-func (r *SortedIntToStringMap) deleteMax(h *nodeIntToString) (_ *nodeIntToString, oldk int, oldv string, ok bool) {
+func (r *SortedIntToStringMap) deleteMax(h *nodeSortedIntToStringMap) (_ *nodeSortedIntToStringMap, oldk int, oldv string, ok bool) {
 	if h == nil {
 		return nil, oldk, oldv, ok
 	}
@@ -319,7 +564,7 @@ func (r *SortedIntToStringMap) Delete(k int) (old string, ok bool) {
 	return
 }
 
-func (r *SortedIntToStringMap) delete(h *nodeIntToString, k int) (_ *nodeIntToString, old string, ok bool) {
+func (r *SortedIntToStringMap) delete(h *nodeSortedIntToStringMap, k int) (_ *nodeSortedIntToStringMap, old string, ok bool) {
 
 	if h == nil {
 		return h, old, false
@@ -367,9 +612,123 @@ func (r *SortedIntToStringMap) delete(h *nodeIntToString, k int) (_ *nodeIntToSt
 	return h, old, ok
 }
 
+// ToSlice returns the keys of the sorted map, in order, and their values.
+func (r SortedIntToStringMap) ToSlice() (keys []int, vals []string) {
+	keys = make([]int, 0, r.Size())
+	vals = make([]string, 0, r.Size())
+	r.Keys(func(k int, v string) bool {
+		keys = append(keys, k)
+		vals = append(vals, v)
+		return true
+	})
+	return keys, vals
+}
+
+// KeysSlice returns the keys of the sorted map, in order.
+func (r SortedIntToStringMap) KeysSlice() []int {
+	keys := make([]int, 0, r.Size())
+	r.Keys(func(k int, _ string) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+// ValuesSlice returns the values of the sorted map, in the order of their
+// keys.
+func (r SortedIntToStringMap) ValuesSlice() []string {
+	vals := make([]string, 0, r.Size())
+	r.Keys(func(_ int, v string) bool {
+		vals = append(vals, v)
+		return true
+	})
+	return vals
+}
+
+// DeleteRange removes the keys between lo and hi and their values from the sorted map,
+// and returns how many it removed. It takes O(m log n) to remove m keys, or
+// O(n) when the range is a large part of the sorted map, which is then
+// rebuilt out of the keys left.
+func (r *SortedIntToStringMap) DeleteRange(lo, hi SortedIntToStringMapBound) int {
+	from, n, size := r.boundrank(lo, false), r.RangeCount(lo, hi), r.Size()
+	depth := 0
+	for s := size; s > 1; s /= 2 {
+		depth++
+	}
+	if n*depth < size {
+		for i := 0; i < n; i++ {
+			// the keys after those removed take their ranks
+			r.Delete(r.nodeselect(r.root, from).key)
+		}
+		return n
+	}
+
+	keys := make([]int, 0, size-n)
+	vals := make([]string, 0, size-n)
+	i := 0
+	r.Keys(func(k int, v string) bool {
+		if i < from || i >= from+n {
+			keys = append(keys, k)
+			vals = append(vals, v)
+		}
+		i++
+		return true
+	})
+	r.root = r.build(keys, vals)
+	return n
+}
+
+// construction
+
+// dedup removes the duplicates from the sorted keys in place, keeping the
+// first of equal keys with the value of the last, as putting them would.
+func (r SortedIntToStringMap) dedup(keys []int, vals []string) ([]int, []string) {
+	n := 0
+	for i := range keys {
+		if n > 0 && r.compare(keys[n-1], keys[i]) == 0 {
+			vals[n-1] = vals[i]
+			continue
+		}
+		keys[n], vals[n] = keys[i], vals[i]
+		n++
+	}
+	return keys[:n], vals[:n]
+}
+
+// build returns the root of a tree holding the sorted keys and their values,
+// in O(n). The tree is as balanced as can be, with the nodes of its last
+// level red if it's not full, and is fixed bottom up so that its red links
+// lean left.
+func (r *SortedIntToStringMap) build(keys []int, vals []string) *nodeSortedIntToStringMap {
+	if len(keys) == 0 {
+		return nil
+	}
+	// the depth of the last level, counting from 0, if it's not full
+	redDepth := -1
+	if n := len(keys); n&(n+1) != 0 {
+		for redDepth = 0; n > 1; n /= 2 {
+			redDepth++
+		}
+	}
+	root := r.buildNode(keys, vals, 0, redDepth)
+	root.colorRed = false
+	return root
+}
+
+func (r *SortedIntToStringMap) buildNode(keys []int, vals []string, depth, redDepth int) *nodeSortedIntToStringMap {
+	if len(keys) == 0 {
+		return nil
+	}
+	mid := len(keys) / 2
+	h := &nodeSortedIntToStringMap{key: keys[mid], val: vals[mid], colorRed: depth == redDepth}
+	h.left = r.buildNode(keys[:mid], vals[:mid], depth+1, redDepth)
+	h.right = r.buildNode(keys[mid+1:], vals[mid+1:], depth+1, redDepth)
+	return r.balance(h)
+}
+
 // deletions
 
-func (r *SortedIntToStringMap) moveRedLeft(h *nodeIntToString) *nodeIntToString {
+func (r *SortedIntToStringMap) moveRedLeft(h *nodeSortedIntToStringMap) *nodeSortedIntToStringMap {
 	r.flipColors(h)
 	if h.right.left.isRed() {
 		h.right = r.rotateRight(h.right)
@@ -379,7 +738,7 @@ func (r *SortedIntToStringMap) moveRedLeft(h *nodeIntToString) *nodeIntToString
 	return h
 }
 
-func (r *SortedIntToStringMap) moveRedRight(h *nodeIntToString) *nodeIntToString {
+func (r *SortedIntToStringMap) moveRedRight(h *nodeSortedIntToStringMap) *nodeSortedIntToStringMap {
 	r.flipColors(h)
 	if h.left.left.isRed() {
 		h = r.rotateRight(h)
@@ -388,7 +747,7 @@ func (r *SortedIntToStringMap) moveRedRight(h *nodeIntToString) *nodeIntToString
 	return h
 }
 
-func (r *SortedIntToStringMap) balance(h *nodeIntToString) *nodeIntToString {
+func (r *SortedIntToStringMap) balance(h *nodeSortedIntToStringMap) *nodeSortedIntToStringMap {
 	if h.right.isRed() {
 		h = r.rotateLeft(h)
 	}
@@ -398,54 +757,182 @@ func (r *SortedIntToStringMap) balance(h *nodeIntToString) *nodeIntToString {
 	if h.left.isRed() && h.right.isRed() {
 		r.flipColors(h)
 	}
-	h.n = h.left.size() + h.right.size() + 1
+	r.update(h)
 	return h
 }
 
-func (r *SortedIntToStringMap) rotateLeft(h *nodeIntToString) *nodeIntToString {
+func (r *SortedIntToStringMap) rotateLeft(h *nodeSortedIntToStringMap) *nodeSortedIntToStringMap {
 	x := h.right
 	h.right = x.left
 	x.left = h
 	x.colorRed = h.colorRed
 	h.colorRed = true
-	x.n = h.n
-	h.n = 1 + h.left.size() + h.right.size()
+	r.update(h)
+	r.update(x)
 	return x
 }
 
-func (r *SortedIntToStringMap) rotateRight(h *nodeIntToString) *nodeIntToString {
+func (r *SortedIntToStringMap) rotateRight(h *nodeSortedIntToStringMap) *nodeSortedIntToStringMap {
 	x := h.left
 	h.left = x.right
 	x.right = h
 	x.colorRed = h.colorRed
 	h.colorRed = true
-	x.n = h.n
-	h.n = 1 + h.left.size() + h.right.size()
+	r.update(h)
+	r.update(x)
 	return x
 }
 
-func (r *SortedIntToStringMap) flipColors(h *nodeIntToString) {
+func (r *SortedIntToStringMap) flipColors(h *nodeSortedIntToStringMap) {
 	h.colorRed = !h.colorRed
 	h.left.colorRed = !h.left.colorRed
 	h.right.colorRed = !h.right.colorRed
 }
 
+// update what h holds about its tree, out of what its children hold.
+func (r *SortedIntToStringMap) update(h *nodeSortedIntToStringMap) {
+	h.n = 1 + h.left.size() + h.right.size()
+}
+
+// cursors
+
+// SortedIntToStringMapCursor is a position among the keys of a sorted map, moved from key to key
+// in either order. Unlike Keys, it can be paused, or moved along the cursor
+// of another sorted map. It's only valid until the sorted map is modified.
+type SortedIntToStringMapCursor struct {
+	r SortedIntToStringMap
+	// path from the root to the node under the cursor, empty when the cursor
+	// isn't on a key
+	path []*nodeSortedIntToStringMap
+}
+
+// NewCursor returns a cursor of the sorted map, which isn't on a key until it's
+// moved with First, Last or Seek.
+func (r SortedIntToStringMap) NewCursor() *SortedIntToStringMapCursor {
+	return &SortedIntToStringMapCursor{r: r}
+}
+
+// Valid tells if the cursor is on a key.
+func (c *SortedIntToStringMapCursor) Valid() bool { return len(c.path) != 0 }
+
+// First moves the cursor on the smallest key, and tells if there is one.
+func (c *SortedIntToStringMapCursor) First() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.left {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Last moves the cursor on the largest key, and tells if there is one.
+func (c *SortedIntToStringMapCursor) Last() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.right {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Seek moves the cursor on the smallest key larger than or equal to `k`, and
+// tells if there is one.
+func (c *SortedIntToStringMapCursor) Seek(k int) bool {
+	c.path = c.path[:0]
+	// the ceiling is the last node left of which the search goes
+	ceiling := 0
+	for h := c.r.root; h != nil; {
+		c.path = append(c.path, h)
+		cmp := c.r.compare(k, h.key)
+		if cmp == 0 {
+			return true
+		} else if cmp < 0 {
+			ceiling = len(c.path)
+			h = h.left
+		} else {
+			h = h.right
+		}
+	}
+	c.path = c.path[:ceiling]
+	return c.Valid()
+}
+
+// Next moves the cursor on the following key, and tells if there is one.
+// Once past the largest key, the cursor isn't on a key anymore.
+func (c *SortedIntToStringMapCursor) Next() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].right; h != nil {
+		for ; h != nil; h = h.left {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the left of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].left == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Prev moves the cursor on the preceding key, and tells if there is one.
+// Once past the smallest key, the cursor isn't on a key anymore.
+func (c *SortedIntToStringMapCursor) Prev() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].left; h != nil {
+		for ; h != nil; h = h.right {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the right of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].right == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Key under the cursor. It panics if the cursor isn't on a key.
+func (c *SortedIntToStringMapCursor) Key() int {
+	return c.node().key
+}
+
+// Value under the cursor. It panics if the cursor isn't on a key.
+func (c *SortedIntToStringMapCursor) Value() string {
+	return c.node().val
+}
+
+func (c *SortedIntToStringMapCursor) node() *nodeSortedIntToStringMap {
+	if !c.Valid() {
+		panic("redblackbst: cursor isn't on a key")
+	}
+	return c.path[len(c.path)-1]
+}
+
 // nodes
 
-type nodeIntToString struct {
+type nodeSortedIntToStringMap struct {
 	key         int
 	val         string
-	left, right *nodeIntToString
+	left, right *nodeSortedIntToStringMap
 	n           int
 	colorRed    bool
 }
 
-func (x *nodeIntToString) isRed() bool { return (x != nil) && (x.colorRed == true) }
+func (x *nodeSortedIntToStringMap) isRed() bool { return (x != nil) && (x.colorRed == true) }
 
-func (x *nodeIntToString) size() int {
+func (x *nodeSortedIntToStringMap) size() int {
 	if x == nil {
 		return 0
 	}
 	return x.n
 }
-
@@ -1,7 +1,11 @@
+// Code generated by datagen; DO NOT EDIT.
+
 package codegen
 
 import "bytes"
 
+import "sort"
+
 // WARNING: using []byte as keys can lead to undefined behavior if the
 // []byte are modified after insertion!!!
 func (r SortedBytesSet) compare(a, b []byte) int { return bytes.Compare(a, b) }
@@ -9,12 +13,48 @@ func (r SortedBytesSet) compare(a, b []byte) int { return bytes.Compare(a, b) }
 // SortedBytesSet is a sorted set built on a left leaning red black balanced
 // search sorted set. It stores unique []byte values.
 type SortedBytesSet struct {
-	root *nodeBytes
+	root *nodeSortedBytesSet
 }
 
 // NewSortedBytesSet creates a sorted set.
 func NewSortedBytesSet() *SortedBytesSet { return &SortedBytesSet{} }
 
+// NewSortedBytesSetFromSorted creates a sorted set of the keys, given in order, in
+// O(n) rather than the O(n log n) of putting them one by one. Of equal keys,
+// the first one is kept, as putting them would. It panics if the keys aren't
+// in order.
+func NewSortedBytesSetFromSorted(keys [][]byte) *SortedBytesSet {
+	r := &SortedBytesSet{}
+	var dups bool
+	for i := 1; i < len(keys); i++ {
+		cmp := r.compare(keys[i-1], keys[i])
+		if cmp > 0 {
+			panic("redblackbst: keys aren't sorted")
+		}
+		dups = dups || cmp == 0
+	}
+	if dups {
+		// not to modify the slice given
+		keys = r.dedup(append([][]byte(nil), keys...))
+	}
+	r.root = r.build(keys)
+	return r
+}
+
+// NewSortedBytesSetFromUnsorted creates a sorted set of the keys, sorting them
+// first, in O(n log n) but faster than putting them one by one. Of equal
+// keys, the first one is kept, as putting them would. The slice isn't
+// modified.
+func NewSortedBytesSetFromUnsorted(keys [][]byte) *SortedBytesSet {
+	r := &SortedBytesSet{}
+	sorted := append([][]byte(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return r.compare(sorted[i], sorted[j]) < 0
+	})
+	r.root = r.build(r.dedup(sorted))
+	return r
+}
+
 // IsEmpty tells if the sorted set contains no key.
 func (r SortedBytesSet) IsEmpty() bool {
 	return r.root == nil
@@ -30,12 +70,13 @@ func (r *SortedBytesSet) Clear() { r.root = nil }
 // true is returned.
 func (r *SortedBytesSet) Put(k []byte) (already bool) {
 	r.root, already = r.put(r.root, k)
+	r.root.colorRed = false
 	return
 }
 
-func (r *SortedBytesSet) put(h *nodeBytes, k []byte) (_ *nodeBytes, already bool) {
+func (r *SortedBytesSet) put(h *nodeSortedBytesSet, k []byte) (_ *nodeSortedBytesSet, already bool) {
 	if h == nil {
-		n := &nodeBytes{key: k, n: 1, colorRed: true}
+		n := &nodeSortedBytesSet{key: k, n: 1, colorRed: true}
 		return n, already
 	}
 
@@ -66,7 +107,7 @@ func (r SortedBytesSet) Contains(k []byte) bool {
 	return r.loopContains(r.root, k)
 }
 
-func (r SortedBytesSet) loopContains(h *nodeBytes, k []byte) (ok bool) {
+func (r SortedBytesSet) loopContains(h *nodeSortedBytesSet, k []byte) (ok bool) {
 	for h != nil {
 		cmp := r.compare(k, h.key)
 		if cmp == 0 {
@@ -89,7 +130,7 @@ func (r SortedBytesSet) Min() (k []byte, ok bool) {
 	return h.key, true
 }
 
-func (r SortedBytesSet) min(x *nodeBytes) *nodeBytes {
+func (r SortedBytesSet) min(x *nodeSortedBytesSet) *nodeSortedBytesSet {
 	if x.left == nil {
 		return x
 	}
@@ -105,7 +146,7 @@ func (r SortedBytesSet) Max() (k []byte, ok bool) {
 	return h.key, true
 }
 
-func (r SortedBytesSet) max(x *nodeBytes) *nodeBytes {
+func (r SortedBytesSet) max(x *nodeSortedBytesSet) *nodeSortedBytesSet {
 	if x.right == nil {
 		return x
 	}
@@ -113,7 +154,7 @@ func (r SortedBytesSet) max(x *nodeBytes) *nodeBytes {
 }
 
 // Floor returns the largest key in the sorted set that is smaller than
-// `k`.
+// or equal to `k`, if it exists.
 func (r SortedBytesSet) Floor(key []byte) (k []byte, ok bool) {
 	x := r.floor(r.root, key)
 	if x == nil {
@@ -122,7 +163,7 @@ func (r SortedBytesSet) Floor(key []byte) (k []byte, ok bool) {
 	return x.key, true
 }
 
-func (r SortedBytesSet) floor(h *nodeBytes, k []byte) *nodeBytes {
+func (r SortedBytesSet) floor(h *nodeSortedBytesSet, k []byte) *nodeSortedBytesSet {
 	if h == nil {
 		return nil
 	}
@@ -141,7 +182,7 @@ func (r SortedBytesSet) floor(h *nodeBytes, k []byte) *nodeBytes {
 }
 
 // Ceiling returns the smallest key in the sorted set that is larger than
-// `k`.
+// or equal to `k`, if it exists.
 func (r SortedBytesSet) Ceiling(key []byte) (k []byte, ok bool) {
 	x := r.ceiling(r.root, key)
 	if x == nil {
@@ -150,7 +191,7 @@ func (r SortedBytesSet) Ceiling(key []byte) (k []byte, ok bool) {
 	return x.key, true
 }
 
-func (r SortedBytesSet) ceiling(h *nodeBytes, k []byte) *nodeBytes {
+func (r SortedBytesSet) ceiling(h *nodeSortedBytesSet, k []byte) *nodeSortedBytesSet {
 	if h == nil {
 		return nil
 	}
@@ -168,6 +209,54 @@ func (r SortedBytesSet) ceiling(h *nodeBytes, k []byte) *nodeBytes {
 	return h
 }
 
+// Lower returns the largest key in the sorted set that is strictly
+// smaller than `k`, its predecessor, if it exists.
+func (r SortedBytesSet) Lower(key []byte) (k []byte, ok bool) {
+	x := r.lower(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, true
+}
+
+func (r SortedBytesSet) lower(h *nodeSortedBytesSet, k []byte) *nodeSortedBytesSet {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) <= 0 {
+		return r.lower(h.left, k)
+	}
+	t := r.lower(h.right, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
+// Higher returns the smallest key in the sorted set that is strictly
+// larger than `k`, its successor, if it exists.
+func (r SortedBytesSet) Higher(key []byte) (k []byte, ok bool) {
+	x := r.higher(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, true
+}
+
+func (r SortedBytesSet) higher(h *nodeSortedBytesSet, k []byte) *nodeSortedBytesSet {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) >= 0 {
+		return r.higher(h.right, k)
+	}
+	t := r.higher(h.left, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
 // Select key of rank k, meaning the k-th biggest []byte in the sorted set.
 func (r SortedBytesSet) Select(key int) (k []byte, ok bool) {
 	x := r.nodeselect(r.root, key)
@@ -177,7 +266,7 @@ func (r SortedBytesSet) Select(key int) (k []byte, ok bool) {
 	return x.key, true
 }
 
-func (r SortedBytesSet) nodeselect(x *nodeBytes, k int) *nodeBytes {
+func (r SortedBytesSet) nodeselect(x *nodeSortedBytesSet, k int) *nodeSortedBytesSet {
 	if x == nil {
 		return nil
 	}
@@ -196,7 +285,7 @@ func (r SortedBytesSet) Rank(k []byte) int {
 	return r.keyrank(k, r.root)
 }
 
-func (r SortedBytesSet) keyrank(k []byte, h *nodeBytes) int {
+func (r SortedBytesSet) keyrank(k []byte, h *nodeSortedBytesSet) int {
 	if h == nil {
 		return 0
 	}
@@ -228,7 +317,7 @@ func (r SortedBytesSet) RangedKeys(lo, hi []byte, visit func([]byte) bool) {
 	r.keys(r.root, visit, lo, hi)
 }
 
-func (r SortedBytesSet) keys(h *nodeBytes, visit func([]byte) bool, lo, hi []byte) bool {
+func (r SortedBytesSet) keys(h *nodeSortedBytesSet, visit func([]byte) bool, lo, hi []byte) bool {
 	if h == nil {
 		return true
 	}
@@ -252,6 +341,136 @@ func (r SortedBytesSet) keys(h *nodeBytes, visit func([]byte) bool, lo, hi []byt
 	return true
 }
 
+// ReverseKeys visit each keys in the sorted set, in reverse order.
+// It stops when visit returns false.
+func (r SortedBytesSet) ReverseKeys(visit func([]byte) bool) {
+	min, ok := r.Min()
+	if !ok {
+		return
+	}
+	// if the min exists, then the max must exist
+	max, _ := r.Max()
+	r.RangedKeysDesc(min, max, visit)
+}
+
+// RangedKeysDesc visit each keys between lo and hi in the sorted set, in
+// reverse order. It stops when visit returns false.
+func (r SortedBytesSet) RangedKeysDesc(lo, hi []byte, visit func([]byte) bool) {
+	r.keysDesc(r.root, visit, lo, hi)
+}
+
+func (r SortedBytesSet) keysDesc(h *nodeSortedBytesSet, visit func([]byte) bool, lo, hi []byte) bool {
+	if h == nil {
+		return true
+	}
+	cmplo := r.compare(lo, h.key)
+	cmphi := r.compare(hi, h.key)
+	if cmphi > 0 {
+		if !r.keysDesc(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	if cmplo <= 0 && cmphi >= 0 {
+		if !visit(h.key) {
+			return false
+		}
+	}
+	if cmplo < 0 {
+		if !r.keysDesc(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
+// SortedBytesSetBound is an end of a range of keys: its Key, included in the range unless
+// it's Exclusive. An Unbounded end doesn't limit the range, whatever its Key.
+type SortedBytesSetBound struct {
+	Key       []byte
+	Exclusive bool
+	Unbounded bool
+}
+
+// RangeCount is the number of keys between lo and hi in the sorted set. It
+// doesn't visit them, and takes O(log n).
+func (r SortedBytesSet) RangeCount(lo, hi SortedBytesSetBound) int {
+	n := r.boundrank(hi, true) - r.boundrank(lo, false)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// boundrank is the number of keys below b, or up to b for an upper bound.
+func (r SortedBytesSet) boundrank(b SortedBytesSetBound, upper bool) int {
+	if b.Unbounded {
+		if upper {
+			return r.Size()
+		}
+		return 0
+	}
+	// the key itself is below an exclusive lower bound or an inclusive
+	// upper bound
+	return r.keyrankBound(b.Key, r.root, upper != b.Exclusive)
+}
+
+func (r SortedBytesSet) keyrankBound(k []byte, h *nodeSortedBytesSet, withKey bool) int {
+	if h == nil {
+		return 0
+	}
+	cmp := r.compare(k, h.key)
+	if cmp < 0 {
+		return r.keyrankBound(k, h.left, withKey)
+	} else if cmp > 0 {
+		return 1 + h.left.size() + r.keyrankBound(k, h.right, withKey)
+	} else if withKey {
+		return h.left.size() + 1
+	} else {
+		return h.left.size()
+	}
+}
+
+// BoundedKeys visit each keys between lo and hi in the sorted set, in order.
+// It stops when visit returns false.
+func (r SortedBytesSet) BoundedKeys(lo, hi SortedBytesSetBound, visit func([]byte) bool) {
+	r.boundedKeys(r.root, visit, lo, hi)
+}
+
+func (r SortedBytesSet) boundedKeys(h *nodeSortedBytesSet, visit func([]byte) bool, lo, hi SortedBytesSetBound) bool {
+	if h == nil {
+		return true
+	}
+	// whether the keys left and right of h can be in the range
+	left, right := true, true
+	inside := true
+	if !lo.Unbounded {
+		cmp := r.compare(lo.Key, h.key)
+		left = cmp < 0
+		inside = cmp < 0 || (cmp == 0 && !lo.Exclusive)
+	}
+	if !hi.Unbounded {
+		cmp := r.compare(hi.Key, h.key)
+		right = cmp > 0
+		inside = inside && (cmp > 0 || (cmp == 0 && !hi.Exclusive))
+	}
+	if left {
+		if !r.boundedKeys(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	if inside {
+		if !visit(h.key) {
+			return false
+		}
+	}
+	if right {
+		if !r.boundedKeys(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
 // DeleteMin removes the smallest key from the sorted set.
 func (r *SortedBytesSet) DeleteMin() (oldk []byte, ok bool) {
 	r.root, oldk, ok = r.deleteMin(r.root)
@@ -261,7 +480,7 @@ func (r *SortedBytesSet) DeleteMin() (oldk []byte, ok bool) {
 	return
 }
 
-func (r *SortedBytesSet) deleteMin(h *nodeBytes) (_ *nodeBytes, oldk []byte, ok bool) {
+func (r *SortedBytesSet) deleteMin(h *nodeSortedBytesSet) (_ *nodeSortedBytesSet, oldk []byte, ok bool) {
 	if h == nil {
 		return nil, oldk, false
 	}
@@ -285,7 +504,7 @@ func (r *SortedBytesSet) DeleteMax() (oldk []byte, ok bool) {
 	return
 }
 
-func (r *SortedBytesSet) deleteMax(h *nodeBytes) (_ *nodeBytes, oldk []byte, ok bool) {
+func (r *SortedBytesSet) deleteMax(h *nodeSortedBytesSet) (_ *nodeSortedBytesSet, oldk []byte, ok bool) {
 	if h == nil {
 		return nil, oldk, ok
 	}
@@ -314,7 +533,7 @@ func (r *SortedBytesSet) Delete(k []byte) (ok bool) {
 	return
 }
 
-func (r *SortedBytesSet) delete(h *nodeBytes, k []byte) (_ *nodeBytes, ok bool) {
+func (r *SortedBytesSet) delete(h *nodeSortedBytesSet, k []byte) (_ *nodeSortedBytesSet, ok bool) {
 
 	if h == nil {
 		return h, false
@@ -360,9 +579,216 @@ func (r *SortedBytesSet) delete(h *nodeBytes, k []byte) (_ *nodeBytes, ok bool)
 	return h, ok
 }
 
+// ToSlice returns the keys of the sorted set, in order.
+func (r SortedBytesSet) ToSlice() [][]byte {
+	keys := make([][]byte, 0, r.Size())
+	r.Keys(func(k []byte) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+// DeleteRange removes the keys between lo and hi from the sorted set,
+// and returns how many it removed. It takes O(m log n) to remove m keys, or
+// O(n) when the range is a large part of the sorted set, which is then
+// rebuilt out of the keys left.
+func (r *SortedBytesSet) DeleteRange(lo, hi SortedBytesSetBound) int {
+	from, n, size := r.boundrank(lo, false), r.RangeCount(lo, hi), r.Size()
+	depth := 0
+	for s := size; s > 1; s /= 2 {
+		depth++
+	}
+	if n*depth < size {
+		for i := 0; i < n; i++ {
+			// the keys after those removed take their ranks
+			r.Delete(r.nodeselect(r.root, from).key)
+		}
+		return n
+	}
+
+	keys := make([][]byte, 0, size-n)
+	i := 0
+	r.Keys(func(k []byte) bool {
+		if i < from || i >= from+n {
+			keys = append(keys, k)
+		}
+		i++
+		return true
+	})
+	r.root = r.build(keys)
+	return n
+}
+
+// set algebra
+
+// Union returns a new sorted set of the keys in either r or other, which must
+// order its keys like r. It takes O(n+m).
+func (r SortedBytesSet) Union(other *SortedBytesSet) *SortedBytesSet {
+	return r.with(r.merge(other, true, true, true))
+}
+
+// Intersection returns a new sorted set of the keys in both r and other,
+// which must order its keys like r. It takes O(n+m).
+func (r SortedBytesSet) Intersection(other *SortedBytesSet) *SortedBytesSet {
+	return r.with(r.merge(other, false, true, false))
+}
+
+// Difference returns a new sorted set of the keys in r but not in other,
+// which must order its keys like r. It takes O(n+m).
+func (r SortedBytesSet) Difference(other *SortedBytesSet) *SortedBytesSet {
+	return r.with(r.merge(other, true, false, false))
+}
+
+// SymmetricDifference returns a new sorted set of the keys in either r or
+// other but not in both, other ordering its keys like r. It takes O(n+m).
+func (r SortedBytesSet) SymmetricDifference(other *SortedBytesSet) *SortedBytesSet {
+	return r.with(r.merge(other, true, false, true))
+}
+
+// UnionWith puts the keys of other in the sorted set, like Union but in
+// place.
+func (r *SortedBytesSet) UnionWith(other *SortedBytesSet) {
+	r.root = r.build(r.merge(other, true, true, true))
+}
+
+// IntersectionWith deletes the keys that aren't in other from the sorted set,
+// like Intersection but in place.
+func (r *SortedBytesSet) IntersectionWith(other *SortedBytesSet) {
+	r.root = r.build(r.merge(other, false, true, false))
+}
+
+// DifferenceWith deletes the keys of other from the sorted set, like
+// Difference but in place.
+func (r *SortedBytesSet) DifferenceWith(other *SortedBytesSet) {
+	r.root = r.build(r.merge(other, true, false, false))
+}
+
+// SymmetricDifferenceWith deletes the keys of other from the sorted set, and
+// puts those it didn't have, like SymmetricDifference but in place.
+func (r *SortedBytesSet) SymmetricDifferenceWith(other *SortedBytesSet) {
+	r.root = r.build(r.merge(other, true, false, true))
+}
+
+// IsSubset tells if every key of the sorted set is in other, which must
+// order its keys like r. It takes O(n+m).
+func (r SortedBytesSet) IsSubset(other *SortedBytesSet) bool {
+	if r.Size() > other.Size() {
+		return false
+	}
+	return len(r.merge(other, true, false, false)) == 0
+}
+
+// Equal tells if the sorted set and other have the same keys, other ordering
+// its keys like r. It takes O(n).
+func (r SortedBytesSet) Equal(other *SortedBytesSet) bool {
+	if r.Size() != other.Size() {
+		return false
+	}
+	a, b := r.NewCursor(), other.NewCursor()
+	for ok := a.First() && b.First(); ok; ok = a.Next() && b.Next() {
+		if r.compare(a.Key(), b.Key()) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// merge walks the keys of r and other in order, and returns those only in
+// r, in both or only in other, as asked.
+func (r SortedBytesSet) merge(other *SortedBytesSet, onlyR, both, onlyOther bool) [][]byte {
+	var keys [][]byte
+	a, b := r.NewCursor(), other.NewCursor()
+	a.First()
+	b.First()
+	for a.Valid() || b.Valid() {
+		if (!a.Valid() && !onlyOther) || (!b.Valid() && !onlyR) {
+			break
+		}
+		cmp := 0
+		if !a.Valid() {
+			cmp = 1
+		} else if !b.Valid() {
+			cmp = -1
+		} else {
+			cmp = r.compare(a.Key(), b.Key())
+		}
+		if cmp < 0 {
+			if onlyR {
+				keys = append(keys, a.Key())
+			}
+			a.Next()
+		} else if cmp > 0 {
+			if onlyOther {
+				keys = append(keys, b.Key())
+			}
+			b.Next()
+		} else {
+			if both {
+				keys = append(keys, a.Key())
+			}
+			a.Next()
+			b.Next()
+		}
+	}
+	return keys
+}
+
+// with returns a sorted set ordered like r, holding the sorted keys.
+func (r SortedBytesSet) with(keys [][]byte) *SortedBytesSet {
+	r.root = r.build(keys)
+	return &r
+}
+
+// construction
+
+// dedup removes the duplicates from the sorted keys in place, keeping the
+// first of equal keys, as putting them would.
+func (r SortedBytesSet) dedup(keys [][]byte) [][]byte {
+	n := 0
+	for i := range keys {
+		if n > 0 && r.compare(keys[n-1], keys[i]) == 0 {
+			continue
+		}
+		keys[n] = keys[i]
+		n++
+	}
+	return keys[:n]
+}
+
+// build returns the root of a tree holding the sorted keys, in O(n). The tree
+// is as balanced as can be, with the nodes of its last level red if it's not
+// full, and is fixed bottom up so that its red links lean left.
+func (r *SortedBytesSet) build(keys [][]byte) *nodeSortedBytesSet {
+	if len(keys) == 0 {
+		return nil
+	}
+	// the depth of the last level, counting from 0, if it's not full
+	redDepth := -1
+	if n := len(keys); n&(n+1) != 0 {
+		for redDepth = 0; n > 1; n /= 2 {
+			redDepth++
+		}
+	}
+	root := r.buildNode(keys, 0, redDepth)
+	root.colorRed = false
+	return root
+}
+
+func (r *SortedBytesSet) buildNode(keys [][]byte, depth, redDepth int) *nodeSortedBytesSet {
+	if len(keys) == 0 {
+		return nil
+	}
+	mid := len(keys) / 2
+	h := &nodeSortedBytesSet{key: keys[mid], colorRed: depth == redDepth}
+	h.left = r.buildNode(keys[:mid], depth+1, redDepth)
+	h.right = r.buildNode(keys[mid+1:], depth+1, redDepth)
+	return r.balance(h)
+}
+
 // deletions
 
-func (r *SortedBytesSet) moveRedLeft(h *nodeBytes) *nodeBytes {
+func (r *SortedBytesSet) moveRedLeft(h *nodeSortedBytesSet) *nodeSortedBytesSet {
 	r.flipColors(h)
 	if h.right.left.isRed() {
 		h.right = r.rotateRight(h.right)
@@ -372,7 +798,7 @@ func (r *SortedBytesSet) moveRedLeft(h *nodeBytes) *nodeBytes {
 	return h
 }
 
-func (r *SortedBytesSet) moveRedRight(h *nodeBytes) *nodeBytes {
+func (r *SortedBytesSet) moveRedRight(h *nodeSortedBytesSet) *nodeSortedBytesSet {
 	r.flipColors(h)
 	if h.left.left.isRed() {
 		h = r.rotateRight(h)
@@ -381,7 +807,7 @@ func (r *SortedBytesSet) moveRedRight(h *nodeBytes) *nodeBytes {
 	return h
 }
 
-func (r *SortedBytesSet) balance(h *nodeBytes) *nodeBytes {
+func (r *SortedBytesSet) balance(h *nodeSortedBytesSet) *nodeSortedBytesSet {
 	if h.right.isRed() {
 		h = r.rotateLeft(h)
 	}
@@ -395,7 +821,7 @@ func (r *SortedBytesSet) balance(h *nodeBytes) *nodeBytes {
 	return h
 }
 
-func (r *SortedBytesSet) rotateLeft(h *nodeBytes) *nodeBytes {
+func (r *SortedBytesSet) rotateLeft(h *nodeSortedBytesSet) *nodeSortedBytesSet {
 	x := h.right
 	h.right = x.left
 	x.left = h
@@ -406,7 +832,7 @@ func (r *SortedBytesSet) rotateLeft(h *nodeBytes) *nodeBytes {
 	return x
 }
 
-func (r *SortedBytesSet) rotateRight(h *nodeBytes) *nodeBytes {
+func (r *SortedBytesSet) rotateRight(h *nodeSortedBytesSet) *nodeSortedBytesSet {
 	x := h.left
 	h.left = x.right
 	x.right = h
@@ -417,27 +843,143 @@ func (r *SortedBytesSet) rotateRight(h *nodeBytes) *nodeBytes {
 	return x
 }
 
-func (r *SortedBytesSet) flipColors(h *nodeBytes) {
+func (r *SortedBytesSet) flipColors(h *nodeSortedBytesSet) {
 	h.colorRed = !h.colorRed
 	h.left.colorRed = !h.left.colorRed
 	h.right.colorRed = !h.right.colorRed
 }
 
+// cursors
+
+// SortedBytesSetCursor is a position among the keys of a sorted set, moved from key to key
+// in either order. Unlike Keys, it can be paused, or moved along the cursor
+// of another sorted set. It's only valid until the sorted set is modified.
+type SortedBytesSetCursor struct {
+	r SortedBytesSet
+	// path from the root to the node under the cursor, empty when the cursor
+	// isn't on a key
+	path []*nodeSortedBytesSet
+}
+
+// NewCursor returns a cursor of the sorted set, which isn't on a key until it's
+// moved with First, Last or Seek.
+func (r SortedBytesSet) NewCursor() *SortedBytesSetCursor { return &SortedBytesSetCursor{r: r} }
+
+// Valid tells if the cursor is on a key.
+func (c *SortedBytesSetCursor) Valid() bool { return len(c.path) != 0 }
+
+// First moves the cursor on the smallest key, and tells if there is one.
+func (c *SortedBytesSetCursor) First() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.left {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Last moves the cursor on the largest key, and tells if there is one.
+func (c *SortedBytesSetCursor) Last() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.right {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Seek moves the cursor on the smallest key larger than or equal to `k`, and
+// tells if there is one.
+func (c *SortedBytesSetCursor) Seek(k []byte) bool {
+	c.path = c.path[:0]
+	// the ceiling is the last node left of which the search goes
+	ceiling := 0
+	for h := c.r.root; h != nil; {
+		c.path = append(c.path, h)
+		cmp := c.r.compare(k, h.key)
+		if cmp == 0 {
+			return true
+		} else if cmp < 0 {
+			ceiling = len(c.path)
+			h = h.left
+		} else {
+			h = h.right
+		}
+	}
+	c.path = c.path[:ceiling]
+	return c.Valid()
+}
+
+// Next moves the cursor on the following key, and tells if there is one.
+// Once past the largest key, the cursor isn't on a key anymore.
+func (c *SortedBytesSetCursor) Next() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].right; h != nil {
+		for ; h != nil; h = h.left {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the left of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].left == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Prev moves the cursor on the preceding key, and tells if there is one.
+// Once past the smallest key, the cursor isn't on a key anymore.
+func (c *SortedBytesSetCursor) Prev() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].left; h != nil {
+		for ; h != nil; h = h.right {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the right of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].right == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Key under the cursor. It panics if the cursor isn't on a key.
+func (c *SortedBytesSetCursor) Key() []byte {
+	return c.node().key
+}
+
+func (c *SortedBytesSetCursor) node() *nodeSortedBytesSet {
+	if !c.Valid() {
+		panic("redblackbst: cursor isn't on a key")
+	}
+	return c.path[len(c.path)-1]
+}
+
 // nodes
 
-type nodeBytes struct {
+type nodeSortedBytesSet struct {
 	key         []byte
-	left, right *nodeBytes
+	left, right *nodeSortedBytesSet
 	n           int
 	colorRed    bool
 }
 
-func (x *nodeBytes) isRed() bool { return (x != nil) && (x.colorRed == true) }
+func (x *nodeSortedBytesSet) isRed() bool { return (x != nil) && (x.colorRed == true) }
 
-func (x *nodeBytes) size() int {
+func (x *nodeSortedBytesSet) size() int {
 	if x == nil {
 		return 0
 	}
 	return x.n
 }
-
@@ -1,27 +1,73 @@
+// Code generated by datagen; DO NOT EDIT.
+
 package codegen
 
+import "sort"
 
+// compare orders NaNs before any other value, including -Inf, and considers
+// them equal to each other. -0 and +0 are equal.
 func (r SortedFloat64Set) compare(a, b float64) int {
-	const e = 0.00000001
-
-    diff := (a-b)/a
-    if diff < -e {
-        return -1
-    } else if diff > e {
-        return 1
-    }
-    return 0
+	aNaN, bNaN := a != a, b != b
+	switch {
+	case aNaN && bNaN:
+		return 0
+	case aNaN:
+		return -1
+	case bNaN:
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
 }
 
 // SortedFloat64Set is a sorted set built on a left leaning red black balanced
 // search sorted set. It stores unique float64 values.
 type SortedFloat64Set struct {
-	root *nodeFloat64
+	root *nodeSortedFloat64Set
 }
 
 // NewSortedFloat64Set creates a sorted set.
 func NewSortedFloat64Set() *SortedFloat64Set { return &SortedFloat64Set{} }
 
+// NewSortedFloat64SetFromSorted creates a sorted set of the keys, given in order, in
+// O(n) rather than the O(n log n) of putting them one by one. Of equal keys,
+// the first one is kept, as putting them would. It panics if the keys aren't
+// in order.
+func NewSortedFloat64SetFromSorted(keys []float64) *SortedFloat64Set {
+	r := &SortedFloat64Set{}
+	var dups bool
+	for i := 1; i < len(keys); i++ {
+		cmp := r.compare(keys[i-1], keys[i])
+		if cmp > 0 {
+			panic("redblackbst: keys aren't sorted")
+		}
+		dups = dups || cmp == 0
+	}
+	if dups {
+		// not to modify the slice given
+		keys = r.dedup(append([]float64(nil), keys...))
+	}
+	r.root = r.build(keys)
+	return r
+}
+
+// NewSortedFloat64SetFromUnsorted creates a sorted set of the keys, sorting them
+// first, in O(n log n) but faster than putting them one by one. Of equal
+// keys, the first one is kept, as putting them would. The slice isn't
+// modified.
+func NewSortedFloat64SetFromUnsorted(keys []float64) *SortedFloat64Set {
+	r := &SortedFloat64Set{}
+	sorted := append([]float64(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return r.compare(sorted[i], sorted[j]) < 0
+	})
+	r.root = r.build(r.dedup(sorted))
+	return r
+}
+
 // IsEmpty tells if the sorted set contains no key.
 func (r SortedFloat64Set) IsEmpty() bool {
 	return r.root == nil
@@ -37,12 +83,13 @@ func (r *SortedFloat64Set) Clear() { r.root = nil }
 // true is returned.
 func (r *SortedFloat64Set) Put(k float64) (already bool) {
 	r.root, already = r.put(r.root, k)
+	r.root.colorRed = false
 	return
 }
 
-func (r *SortedFloat64Set) put(h *nodeFloat64, k float64) (_ *nodeFloat64, already bool) {
+func (r *SortedFloat64Set) put(h *nodeSortedFloat64Set, k float64) (_ *nodeSortedFloat64Set, already bool) {
 	if h == nil {
-		n := &nodeFloat64{key: k, n: 1, colorRed: true}
+		n := &nodeSortedFloat64Set{key: k, n: 1, colorRed: true}
 		return n, already
 	}
 
@@ -73,7 +120,7 @@ func (r SortedFloat64Set) Contains(k float64) bool {
 	return r.loopContains(r.root, k)
 }
 
-func (r SortedFloat64Set) loopContains(h *nodeFloat64, k float64) (ok bool) {
+func (r SortedFloat64Set) loopContains(h *nodeSortedFloat64Set, k float64) (ok bool) {
 	for h != nil {
 		cmp := r.compare(k, h.key)
 		if cmp == 0 {
@@ -96,7 +143,7 @@ func (r SortedFloat64Set) Min() (k float64, ok bool) {
 	return h.key, true
 }
 
-func (r SortedFloat64Set) min(x *nodeFloat64) *nodeFloat64 {
+func (r SortedFloat64Set) min(x *nodeSortedFloat64Set) *nodeSortedFloat64Set {
 	if x.left == nil {
 		return x
 	}
@@ -112,7 +159,7 @@ func (r SortedFloat64Set) Max() (k float64, ok bool) {
 	return h.key, true
 }
 
-func (r SortedFloat64Set) max(x *nodeFloat64) *nodeFloat64 {
+func (r SortedFloat64Set) max(x *nodeSortedFloat64Set) *nodeSortedFloat64Set {
 	if x.right == nil {
 		return x
 	}
@@ -120,7 +167,7 @@ func (r SortedFloat64Set) max(x *nodeFloat64) *nodeFloat64 {
 }
 
 // Floor returns the largest key in the sorted set that is smaller than
-// `k`.
+// or equal to `k`, if it exists.
 func (r SortedFloat64Set) Floor(key float64) (k float64, ok bool) {
 	x := r.floor(r.root, key)
 	if x == nil {
@@ -129,7 +176,7 @@ func (r SortedFloat64Set) Floor(key float64) (k float64, ok bool) {
 	return x.key, true
 }
 
-func (r SortedFloat64Set) floor(h *nodeFloat64, k float64) *nodeFloat64 {
+func (r SortedFloat64Set) floor(h *nodeSortedFloat64Set, k float64) *nodeSortedFloat64Set {
 	if h == nil {
 		return nil
 	}
@@ -148,7 +195,7 @@ func (r SortedFloat64Set) floor(h *nodeFloat64, k float64) *nodeFloat64 {
 }
 
 // Ceiling returns the smallest key in the sorted set that is larger than
-// `k`.
+// or equal to `k`, if it exists.
 func (r SortedFloat64Set) Ceiling(key float64) (k float64, ok bool) {
 	x := r.ceiling(r.root, key)
 	if x == nil {
@@ -157,7 +204,7 @@ func (r SortedFloat64Set) Ceiling(key float64) (k float64, ok bool) {
 	return x.key, true
 }
 
-func (r SortedFloat64Set) ceiling(h *nodeFloat64, k float64) *nodeFloat64 {
+func (r SortedFloat64Set) ceiling(h *nodeSortedFloat64Set, k float64) *nodeSortedFloat64Set {
 	if h == nil {
 		return nil
 	}
@@ -175,6 +222,54 @@ func (r SortedFloat64Set) ceiling(h *nodeFloat64, k float64) *nodeFloat64 {
 	return h
 }
 
+// Lower returns the largest key in the sorted set that is strictly
+// smaller than `k`, its predecessor, if it exists.
+func (r SortedFloat64Set) Lower(key float64) (k float64, ok bool) {
+	x := r.lower(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, true
+}
+
+func (r SortedFloat64Set) lower(h *nodeSortedFloat64Set, k float64) *nodeSortedFloat64Set {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) <= 0 {
+		return r.lower(h.left, k)
+	}
+	t := r.lower(h.right, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
+// Higher returns the smallest key in the sorted set that is strictly
+// larger than `k`, its successor, if it exists.
+func (r SortedFloat64Set) Higher(key float64) (k float64, ok bool) {
+	x := r.higher(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, true
+}
+
+func (r SortedFloat64Set) higher(h *nodeSortedFloat64Set, k float64) *nodeSortedFloat64Set {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) >= 0 {
+		return r.higher(h.right, k)
+	}
+	t := r.higher(h.left, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
 // Select key of rank k, meaning the k-th biggest float64 in the sorted set.
 func (r SortedFloat64Set) Select(key int) (k float64, ok bool) {
 	x := r.nodeselect(r.root, key)
@@ -184,7 +279,7 @@ func (r SortedFloat64Set) Select(key int) (k float64, ok bool) {
 	return x.key, true
 }
 
-func (r SortedFloat64Set) nodeselect(x *nodeFloat64, k int) *nodeFloat64 {
+func (r SortedFloat64Set) nodeselect(x *nodeSortedFloat64Set, k int) *nodeSortedFloat64Set {
 	if x == nil {
 		return nil
 	}
@@ -203,7 +298,7 @@ func (r SortedFloat64Set) Rank(k float64) int {
 	return r.keyrank(k, r.root)
 }
 
-func (r SortedFloat64Set) keyrank(k float64, h *nodeFloat64) int {
+func (r SortedFloat64Set) keyrank(k float64, h *nodeSortedFloat64Set) int {
 	if h == nil {
 		return 0
 	}
@@ -235,7 +330,7 @@ func (r SortedFloat64Set) RangedKeys(lo, hi float64, visit func(float64) bool) {
 	r.keys(r.root, visit, lo, hi)
 }
 
-func (r SortedFloat64Set) keys(h *nodeFloat64, visit func(float64) bool, lo, hi float64) bool {
+func (r SortedFloat64Set) keys(h *nodeSortedFloat64Set, visit func(float64) bool, lo, hi float64) bool {
 	if h == nil {
 		return true
 	}
@@ -259,6 +354,136 @@ func (r SortedFloat64Set) keys(h *nodeFloat64, visit func(float64) bool, lo, hi
 	return true
 }
 
+// ReverseKeys visit each keys in the sorted set, in reverse order.
+// It stops when visit returns false.
+func (r SortedFloat64Set) ReverseKeys(visit func(float64) bool) {
+	min, ok := r.Min()
+	if !ok {
+		return
+	}
+	// if the min exists, then the max must exist
+	max, _ := r.Max()
+	r.RangedKeysDesc(min, max, visit)
+}
+
+// RangedKeysDesc visit each keys between lo and hi in the sorted set, in
+// reverse order. It stops when visit returns false.
+func (r SortedFloat64Set) RangedKeysDesc(lo, hi float64, visit func(float64) bool) {
+	r.keysDesc(r.root, visit, lo, hi)
+}
+
+func (r SortedFloat64Set) keysDesc(h *nodeSortedFloat64Set, visit func(float64) bool, lo, hi float64) bool {
+	if h == nil {
+		return true
+	}
+	cmplo := r.compare(lo, h.key)
+	cmphi := r.compare(hi, h.key)
+	if cmphi > 0 {
+		if !r.keysDesc(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	if cmplo <= 0 && cmphi >= 0 {
+		if !visit(h.key) {
+			return false
+		}
+	}
+	if cmplo < 0 {
+		if !r.keysDesc(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
+// SortedFloat64SetBound is an end of a range of keys: its Key, included in the range unless
+// it's Exclusive. An Unbounded end doesn't limit the range, whatever its Key.
+type SortedFloat64SetBound struct {
+	Key       float64
+	Exclusive bool
+	Unbounded bool
+}
+
+// RangeCount is the number of keys between lo and hi in the sorted set. It
+// doesn't visit them, and takes O(log n).
+func (r SortedFloat64Set) RangeCount(lo, hi SortedFloat64SetBound) int {
+	n := r.boundrank(hi, true) - r.boundrank(lo, false)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// boundrank is the number of keys below b, or up to b for an upper bound.
+func (r SortedFloat64Set) boundrank(b SortedFloat64SetBound, upper bool) int {
+	if b.Unbounded {
+		if upper {
+			return r.Size()
+		}
+		return 0
+	}
+	// the key itself is below an exclusive lower bound or an inclusive
+	// upper bound
+	return r.keyrankBound(b.Key, r.root, upper != b.Exclusive)
+}
+
+func (r SortedFloat64Set) keyrankBound(k float64, h *nodeSortedFloat64Set, withKey bool) int {
+	if h == nil {
+		return 0
+	}
+	cmp := r.compare(k, h.key)
+	if cmp < 0 {
+		return r.keyrankBound(k, h.left, withKey)
+	} else if cmp > 0 {
+		return 1 + h.left.size() + r.keyrankBound(k, h.right, withKey)
+	} else if withKey {
+		return h.left.size() + 1
+	} else {
+		return h.left.size()
+	}
+}
+
+// BoundedKeys visit each keys between lo and hi in the sorted set, in order.
+// It stops when visit returns false.
+func (r SortedFloat64Set) BoundedKeys(lo, hi SortedFloat64SetBound, visit func(float64) bool) {
+	r.boundedKeys(r.root, visit, lo, hi)
+}
+
+func (r SortedFloat64Set) boundedKeys(h *nodeSortedFloat64Set, visit func(float64) bool, lo, hi SortedFloat64SetBound) bool {
+	if h == nil {
+		return true
+	}
+	// whether the keys left and right of h can be in the range
+	left, right := true, true
+	inside := true
+	if !lo.Unbounded {
+		cmp := r.compare(lo.Key, h.key)
+		left = cmp < 0
+		inside = cmp < 0 || (cmp == 0 && !lo.Exclusive)
+	}
+	if !hi.Unbounded {
+		cmp := r.compare(hi.Key, h.key)
+		right = cmp > 0
+		inside = inside && (cmp > 0 || (cmp == 0 && !hi.Exclusive))
+	}
+	if left {
+		if !r.boundedKeys(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	if inside {
+		if !visit(h.key) {
+			return false
+		}
+	}
+	if right {
+		if !r.boundedKeys(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
 // DeleteMin removes the smallest key from the sorted set.
 func (r *SortedFloat64Set) DeleteMin() (oldk float64, ok bool) {
 	r.root, oldk, ok = r.deleteMin(r.root)
@@ -268,7 +493,7 @@ func (r *SortedFloat64Set) DeleteMin() (oldk float64, ok bool) {
 	return
 }
 
-func (r *SortedFloat64Set) deleteMin(h *nodeFloat64) (_ *nodeFloat64, oldk float64, ok bool) {
+func (r *SortedFloat64Set) deleteMin(h *nodeSortedFloat64Set) (_ *nodeSortedFloat64Set, oldk float64, ok bool) {
 	if h == nil {
 		return nil, oldk, false
 	}
@@ -292,7 +517,7 @@ func (r *SortedFloat64Set) DeleteMax() (oldk float64, ok bool) {
 	return
 }
 
-func (r *SortedFloat64Set) deleteMax(h *nodeFloat64) (_ *nodeFloat64, oldk float64, ok bool) {
+func (r *SortedFloat64Set) deleteMax(h *nodeSortedFloat64Set) (_ *nodeSortedFloat64Set, oldk float64, ok bool) {
 	if h == nil {
 		return nil, oldk, ok
 	}
@@ -321,7 +546,7 @@ func (r *SortedFloat64Set) Delete(k float64) (ok bool) {
 	return
 }
 
-func (r *SortedFloat64Set) delete(h *nodeFloat64, k float64) (_ *nodeFloat64, ok bool) {
+func (r *SortedFloat64Set) delete(h *nodeSortedFloat64Set, k float64) (_ *nodeSortedFloat64Set, ok bool) {
 
 	if h == nil {
 		return h, false
@@ -367,9 +592,216 @@ func (r *SortedFloat64Set) delete(h *nodeFloat64, k float64) (_ *nodeFloat64, ok
 	return h, ok
 }
 
+// ToSlice returns the keys of the sorted set, in order.
+func (r SortedFloat64Set) ToSlice() []float64 {
+	keys := make([]float64, 0, r.Size())
+	r.Keys(func(k float64) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+// DeleteRange removes the keys between lo and hi from the sorted set,
+// and returns how many it removed. It takes O(m log n) to remove m keys, or
+// O(n) when the range is a large part of the sorted set, which is then
+// rebuilt out of the keys left.
+func (r *SortedFloat64Set) DeleteRange(lo, hi SortedFloat64SetBound) int {
+	from, n, size := r.boundrank(lo, false), r.RangeCount(lo, hi), r.Size()
+	depth := 0
+	for s := size; s > 1; s /= 2 {
+		depth++
+	}
+	if n*depth < size {
+		for i := 0; i < n; i++ {
+			// the keys after those removed take their ranks
+			r.Delete(r.nodeselect(r.root, from).key)
+		}
+		return n
+	}
+
+	keys := make([]float64, 0, size-n)
+	i := 0
+	r.Keys(func(k float64) bool {
+		if i < from || i >= from+n {
+			keys = append(keys, k)
+		}
+		i++
+		return true
+	})
+	r.root = r.build(keys)
+	return n
+}
+
+// set algebra
+
+// Union returns a new sorted set of the keys in either r or other, which must
+// order its keys like r. It takes O(n+m).
+func (r SortedFloat64Set) Union(other *SortedFloat64Set) *SortedFloat64Set {
+	return r.with(r.merge(other, true, true, true))
+}
+
+// Intersection returns a new sorted set of the keys in both r and other,
+// which must order its keys like r. It takes O(n+m).
+func (r SortedFloat64Set) Intersection(other *SortedFloat64Set) *SortedFloat64Set {
+	return r.with(r.merge(other, false, true, false))
+}
+
+// Difference returns a new sorted set of the keys in r but not in other,
+// which must order its keys like r. It takes O(n+m).
+func (r SortedFloat64Set) Difference(other *SortedFloat64Set) *SortedFloat64Set {
+	return r.with(r.merge(other, true, false, false))
+}
+
+// SymmetricDifference returns a new sorted set of the keys in either r or
+// other but not in both, other ordering its keys like r. It takes O(n+m).
+func (r SortedFloat64Set) SymmetricDifference(other *SortedFloat64Set) *SortedFloat64Set {
+	return r.with(r.merge(other, true, false, true))
+}
+
+// UnionWith puts the keys of other in the sorted set, like Union but in
+// place.
+func (r *SortedFloat64Set) UnionWith(other *SortedFloat64Set) {
+	r.root = r.build(r.merge(other, true, true, true))
+}
+
+// IntersectionWith deletes the keys that aren't in other from the sorted set,
+// like Intersection but in place.
+func (r *SortedFloat64Set) IntersectionWith(other *SortedFloat64Set) {
+	r.root = r.build(r.merge(other, false, true, false))
+}
+
+// DifferenceWith deletes the keys of other from the sorted set, like
+// Difference but in place.
+func (r *SortedFloat64Set) DifferenceWith(other *SortedFloat64Set) {
+	r.root = r.build(r.merge(other, true, false, false))
+}
+
+// SymmetricDifferenceWith deletes the keys of other from the sorted set, and
+// puts those it didn't have, like SymmetricDifference but in place.
+func (r *SortedFloat64Set) SymmetricDifferenceWith(other *SortedFloat64Set) {
+	r.root = r.build(r.merge(other, true, false, true))
+}
+
+// IsSubset tells if every key of the sorted set is in other, which must
+// order its keys like r. It takes O(n+m).
+func (r SortedFloat64Set) IsSubset(other *SortedFloat64Set) bool {
+	if r.Size() > other.Size() {
+		return false
+	}
+	return len(r.merge(other, true, false, false)) == 0
+}
+
+// Equal tells if the sorted set and other have the same keys, other ordering
+// its keys like r. It takes O(n).
+func (r SortedFloat64Set) Equal(other *SortedFloat64Set) bool {
+	if r.Size() != other.Size() {
+		return false
+	}
+	a, b := r.NewCursor(), other.NewCursor()
+	for ok := a.First() && b.First(); ok; ok = a.Next() && b.Next() {
+		if r.compare(a.Key(), b.Key()) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// merge walks the keys of r and other in order, and returns those only in
+// r, in both or only in other, as asked.
+func (r SortedFloat64Set) merge(other *SortedFloat64Set, onlyR, both, onlyOther bool) []float64 {
+	var keys []float64
+	a, b := r.NewCursor(), other.NewCursor()
+	a.First()
+	b.First()
+	for a.Valid() || b.Valid() {
+		if (!a.Valid() && !onlyOther) || (!b.Valid() && !onlyR) {
+			break
+		}
+		cmp := 0
+		if !a.Valid() {
+			cmp = 1
+		} else if !b.Valid() {
+			cmp = -1
+		} else {
+			cmp = r.compare(a.Key(), b.Key())
+		}
+		if cmp < 0 {
+			if onlyR {
+				keys = append(keys, a.Key())
+			}
+			a.Next()
+		} else if cmp > 0 {
+			if onlyOther {
+				keys = append(keys, b.Key())
+			}
+			b.Next()
+		} else {
+			if both {
+				keys = append(keys, a.Key())
+			}
+			a.Next()
+			b.Next()
+		}
+	}
+	return keys
+}
+
+// with returns a sorted set ordered like r, holding the sorted keys.
+func (r SortedFloat64Set) with(keys []float64) *SortedFloat64Set {
+	r.root = r.build(keys)
+	return &r
+}
+
+// construction
+
+// dedup removes the duplicates from the sorted keys in place, keeping the
+// first of equal keys, as putting them would.
+func (r SortedFloat64Set) dedup(keys []float64) []float64 {
+	n := 0
+	for i := range keys {
+		if n > 0 && r.compare(keys[n-1], keys[i]) == 0 {
+			continue
+		}
+		keys[n] = keys[i]
+		n++
+	}
+	return keys[:n]
+}
+
+// build returns the root of a tree holding the sorted keys, in O(n). The tree
+// is as balanced as can be, with the nodes of its last level red if it's not
+// full, and is fixed bottom up so that its red links lean left.
+func (r *SortedFloat64Set) build(keys []float64) *nodeSortedFloat64Set {
+	if len(keys) == 0 {
+		return nil
+	}
+	// the depth of the last level, counting from 0, if it's not full
+	redDepth := -1
+	if n := len(keys); n&(n+1) != 0 {
+		for redDepth = 0; n > 1; n /= 2 {
+			redDepth++
+		}
+	}
+	root := r.buildNode(keys, 0, redDepth)
+	root.colorRed = false
+	return root
+}
+
+func (r *SortedFloat64Set) buildNode(keys []float64, depth, redDepth int) *nodeSortedFloat64Set {
+	if len(keys) == 0 {
+		return nil
+	}
+	mid := len(keys) / 2
+	h := &nodeSortedFloat64Set{key: keys[mid], colorRed: depth == redDepth}
+	h.left = r.buildNode(keys[:mid], depth+1, redDepth)
+	h.right = r.buildNode(keys[mid+1:], depth+1, redDepth)
+	return r.balance(h)
+}
+
 // deletions
 
-func (r *SortedFloat64Set) moveRedLeft(h *nodeFloat64) *nodeFloat64 {
+func (r *SortedFloat64Set) moveRedLeft(h *nodeSortedFloat64Set) *nodeSortedFloat64Set {
 	r.flipColors(h)
 	if h.right.left.isRed() {
 		h.right = r.rotateRight(h.right)
@@ -379,7 +811,7 @@ func (r *SortedFloat64Set) moveRedLeft(h *nodeFloat64) *nodeFloat64 {
 	return h
 }
 
-func (r *SortedFloat64Set) moveRedRight(h *nodeFloat64) *nodeFloat64 {
+func (r *SortedFloat64Set) moveRedRight(h *nodeSortedFloat64Set) *nodeSortedFloat64Set {
 	r.flipColors(h)
 	if h.left.left.isRed() {
 		h = r.rotateRight(h)
@@ -388,7 +820,7 @@ func (r *SortedFloat64Set) moveRedRight(h *nodeFloat64) *nodeFloat64 {
 	return h
 }
 
-func (r *SortedFloat64Set) balance(h *nodeFloat64) *nodeFloat64 {
+func (r *SortedFloat64Set) balance(h *nodeSortedFloat64Set) *nodeSortedFloat64Set {
 	if h.right.isRed() {
 		h = r.rotateLeft(h)
 	}
@@ -402,7 +834,7 @@ func (r *SortedFloat64Set) balance(h *nodeFloat64) *nodeFloat64 {
 	return h
 }
 
-func (r *SortedFloat64Set) rotateLeft(h *nodeFloat64) *nodeFloat64 {
+func (r *SortedFloat64Set) rotateLeft(h *nodeSortedFloat64Set) *nodeSortedFloat64Set {
 	x := h.right
 	h.right = x.left
 	x.left = h
@@ -413,7 +845,7 @@ func (r *SortedFloat64Set) rotateLeft(h *nodeFloat64) *nodeFloat64 {
 	return x
 }
 
-func (r *SortedFloat64Set) rotateRight(h *nodeFloat64) *nodeFloat64 {
+func (r *SortedFloat64Set) rotateRight(h *nodeSortedFloat64Set) *nodeSortedFloat64Set {
 	x := h.left
 	h.left = x.right
 	x.right = h
@@ -424,27 +856,143 @@ func (r *SortedFloat64Set) rotateRight(h *nodeFloat64) *nodeFloat64 {
 	return x
 }
 
-func (r *SortedFloat64Set) flipColors(h *nodeFloat64) {
+func (r *SortedFloat64Set) flipColors(h *nodeSortedFloat64Set) {
 	h.colorRed = !h.colorRed
 	h.left.colorRed = !h.left.colorRed
 	h.right.colorRed = !h.right.colorRed
 }
 
+// cursors
+
+// SortedFloat64SetCursor is a position among the keys of a sorted set, moved from key to key
+// in either order. Unlike Keys, it can be paused, or moved along the cursor
+// of another sorted set. It's only valid until the sorted set is modified.
+type SortedFloat64SetCursor struct {
+	r SortedFloat64Set
+	// path from the root to the node under the cursor, empty when the cursor
+	// isn't on a key
+	path []*nodeSortedFloat64Set
+}
+
+// NewCursor returns a cursor of the sorted set, which isn't on a key until it's
+// moved with First, Last or Seek.
+func (r SortedFloat64Set) NewCursor() *SortedFloat64SetCursor { return &SortedFloat64SetCursor{r: r} }
+
+// Valid tells if the cursor is on a key.
+func (c *SortedFloat64SetCursor) Valid() bool { return len(c.path) != 0 }
+
+// First moves the cursor on the smallest key, and tells if there is one.
+func (c *SortedFloat64SetCursor) First() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.left {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Last moves the cursor on the largest key, and tells if there is one.
+func (c *SortedFloat64SetCursor) Last() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.right {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Seek moves the cursor on the smallest key larger than or equal to `k`, and
+// tells if there is one.
+func (c *SortedFloat64SetCursor) Seek(k float64) bool {
+	c.path = c.path[:0]
+	// the ceiling is the last node left of which the search goes
+	ceiling := 0
+	for h := c.r.root; h != nil; {
+		c.path = append(c.path, h)
+		cmp := c.r.compare(k, h.key)
+		if cmp == 0 {
+			return true
+		} else if cmp < 0 {
+			ceiling = len(c.path)
+			h = h.left
+		} else {
+			h = h.right
+		}
+	}
+	c.path = c.path[:ceiling]
+	return c.Valid()
+}
+
+// Next moves the cursor on the following key, and tells if there is one.
+// Once past the largest key, the cursor isn't on a key anymore.
+func (c *SortedFloat64SetCursor) Next() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].right; h != nil {
+		for ; h != nil; h = h.left {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the left of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].left == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Prev moves the cursor on the preceding key, and tells if there is one.
+// Once past the smallest key, the cursor isn't on a key anymore.
+func (c *SortedFloat64SetCursor) Prev() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].left; h != nil {
+		for ; h != nil; h = h.right {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the right of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].right == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Key under the cursor. It panics if the cursor isn't on a key.
+func (c *SortedFloat64SetCursor) Key() float64 {
+	return c.node().key
+}
+
+func (c *SortedFloat64SetCursor) node() *nodeSortedFloat64Set {
+	if !c.Valid() {
+		panic("redblackbst: cursor isn't on a key")
+	}
+	return c.path[len(c.path)-1]
+}
+
 // nodes
 
-type nodeFloat64 struct {
+type nodeSortedFloat64Set struct {
 	key         float64
-	left, right *nodeFloat64
+	left, right *nodeSortedFloat64Set
 	n           int
 	colorRed    bool
 }
 
-func (x *nodeFloat64) isRed() bool { return (x != nil) && (x.colorRed == true) }
+func (x *nodeSortedFloat64Set) isRed() bool { return (x != nil) && (x.colorRed == true) }
 
-func (x *nodeFloat64) size() int {
+func (x *nodeSortedFloat64Set) size() int {
 	if x == nil {
 		return 0
 	}
 	return x.n
 }
-
@@ -1,16 +1,64 @@
+// Code generated by datagen; DO NOT EDIT.
+
 package codegen
 
-func (r SortedIntSet) compare(a, b int) int { return int(a) - int(b) }
+import "sort"
+
+func (r SortedIntSet) compare(a, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
 
 // SortedIntSet is a sorted set built on a left leaning red black balanced
 // search sorted set. It stores unique int values.
 type SortedIntSet struct {
-	root *nodeInt
+	root *nodeSortedIntSet
 }
 
 // NewSortedIntSet creates a sorted set.
 func NewSortedIntSet() *SortedIntSet { return &SortedIntSet{} }
 
+// NewSortedIntSetFromSorted creates a sorted set of the keys, given in order, in
+// O(n) rather than the O(n log n) of putting them one by one. Of equal keys,
+// the first one is kept, as putting them would. It panics if the keys aren't
+// in order.
+func NewSortedIntSetFromSorted(keys []int) *SortedIntSet {
+	r := &SortedIntSet{}
+	var dups bool
+	for i := 1; i < len(keys); i++ {
+		cmp := r.compare(keys[i-1], keys[i])
+		if cmp > 0 {
+			panic("redblackbst: keys aren't sorted")
+		}
+		dups = dups || cmp == 0
+	}
+	if dups {
+		// not to modify the slice given
+		keys = r.dedup(append([]int(nil), keys...))
+	}
+	r.root = r.build(keys)
+	return r
+}
+
+// NewSortedIntSetFromUnsorted creates a sorted set of the keys, sorting them
+// first, in O(n log n) but faster than putting them one by one. Of equal
+// keys, the first one is kept, as putting them would. The slice isn't
+// modified.
+func NewSortedIntSetFromUnsorted(keys []int) *SortedIntSet {
+	r := &SortedIntSet{}
+	sorted := append([]int(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return r.compare(sorted[i], sorted[j]) < 0
+	})
+	r.root = r.build(r.dedup(sorted))
+	return r
+}
+
 // IsEmpty tells if the sorted set contains no key.
 func (r SortedIntSet) IsEmpty() bool {
 	return r.root == nil
@@ -26,12 +74,13 @@ func (r *SortedIntSet) Clear() { r.root = nil }
 // true is returned.
 func (r *SortedIntSet) Put(k int) (already bool) {
 	r.root, already = r.put(r.root, k)
+	r.root.colorRed = false
 	return
 }
 
-func (r *SortedIntSet) put(h *nodeInt, k int) (_ *nodeInt, already bool) {
+func (r *SortedIntSet) put(h *nodeSortedIntSet, k int) (_ *nodeSortedIntSet, already bool) {
 	if h == nil {
-		n := &nodeInt{key: k, n: 1, colorRed: true}
+		n := &nodeSortedIntSet{key: k, n: 1, colorRed: true}
 		return n, already
 	}
 
@@ -62,7 +111,7 @@ func (r SortedIntSet) Contains(k int) bool {
 	return r.loopContains(r.root, k)
 }
 
-func (r SortedIntSet) loopContains(h *nodeInt, k int) (ok bool) {
+func (r SortedIntSet) loopContains(h *nodeSortedIntSet, k int) (ok bool) {
 	for h != nil {
 		cmp := r.compare(k, h.key)
 		if cmp == 0 {
@@ -85,7 +134,7 @@ func (r SortedIntSet) Min() (k int, ok bool) {
 	return h.key, true
 }
 
-func (r SortedIntSet) min(x *nodeInt) *nodeInt {
+func (r SortedIntSet) min(x *nodeSortedIntSet) *nodeSortedIntSet {
 	if x.left == nil {
 		return x
 	}
@@ -101,7 +150,7 @@ func (r SortedIntSet) Max() (k int, ok bool) {
 	return h.key, true
 }
 
-func (r SortedIntSet) max(x *nodeInt) *nodeInt {
+func (r SortedIntSet) max(x *nodeSortedIntSet) *nodeSortedIntSet {
 	if x.right == nil {
 		return x
 	}
@@ -109,7 +158,7 @@ func (r SortedIntSet) max(x *nodeInt) *nodeInt {
 }
 
 // Floor returns the largest key in the sorted set that is smaller than
-// `k`.
+// or equal to `k`, if it exists.
 func (r SortedIntSet) Floor(key int) (k int, ok bool) {
 	x := r.floor(r.root, key)
 	if x == nil {
@@ -118,7 +167,7 @@ func (r SortedIntSet) Floor(key int) (k int, ok bool) {
 	return x.key, true
 }
 
-func (r SortedIntSet) floor(h *nodeInt, k int) *nodeInt {
+func (r SortedIntSet) floor(h *nodeSortedIntSet, k int) *nodeSortedIntSet {
 	if h == nil {
 		return nil
 	}
@@ -137,7 +186,7 @@ func (r SortedIntSet) floor(h *nodeInt, k int) *nodeInt {
 }
 
 // Ceiling returns the smallest key in the sorted set that is larger than
-// `k`.
+// or equal to `k`, if it exists.
 func (r SortedIntSet) Ceiling(key int) (k int, ok bool) {
 	x := r.ceiling(r.root, key)
 	if x == nil {
@@ -146,7 +195,7 @@ func (r SortedIntSet) Ceiling(key int) (k int, ok bool) {
 	return x.key, true
 }
 
-func (r SortedIntSet) ceiling(h *nodeInt, k int) *nodeInt {
+func (r SortedIntSet) ceiling(h *nodeSortedIntSet, k int) *nodeSortedIntSet {
 	if h == nil {
 		return nil
 	}
@@ -164,6 +213,54 @@ func (r SortedIntSet) ceiling(h *nodeInt, k int) *nodeInt {
 	return h
 }
 
+// Lower returns the largest key in the sorted set that is strictly
+// smaller than `k`, its predecessor, if it exists.
+func (r SortedIntSet) Lower(key int) (k int, ok bool) {
+	x := r.lower(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, true
+}
+
+func (r SortedIntSet) lower(h *nodeSortedIntSet, k int) *nodeSortedIntSet {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) <= 0 {
+		return r.lower(h.left, k)
+	}
+	t := r.lower(h.right, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
+// Higher returns the smallest key in the sorted set that is strictly
+// larger than `k`, its successor, if it exists.
+func (r SortedIntSet) Higher(key int) (k int, ok bool) {
+	x := r.higher(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, true
+}
+
+func (r SortedIntSet) higher(h *nodeSortedIntSet, k int) *nodeSortedIntSet {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) >= 0 {
+		return r.higher(h.right, k)
+	}
+	t := r.higher(h.left, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
 // Select key of rank k, meaning the k-th biggest int in the sorted set.
 func (r SortedIntSet) Select(key int) (k int, ok bool) {
 	x := r.nodeselect(r.root, key)
@@ -173,7 +270,7 @@ func (r SortedIntSet) Select(key int) (k int, ok bool) {
 	return x.key, true
 }
 
-func (r SortedIntSet) nodeselect(x *nodeInt, k int) *nodeInt {
+func (r SortedIntSet) nodeselect(x *nodeSortedIntSet, k int) *nodeSortedIntSet {
 	if x == nil {
 		return nil
 	}
@@ -192,7 +289,7 @@ func (r SortedIntSet) Rank(k int) int {
 	return r.keyrank(k, r.root)
 }
 
-func (r SortedIntSet) keyrank(k int, h *nodeInt) int {
+func (r SortedIntSet) keyrank(k int, h *nodeSortedIntSet) int {
 	if h == nil {
 		return 0
 	}
@@ -224,7 +321,7 @@ func (r SortedIntSet) RangedKeys(lo, hi int, visit func(int) bool) {
 	r.keys(r.root, visit, lo, hi)
 }
 
-func (r SortedIntSet) keys(h *nodeInt, visit func(int) bool, lo, hi int) bool {
+func (r SortedIntSet) keys(h *nodeSortedIntSet, visit func(int) bool, lo, hi int) bool {
 	if h == nil {
 		return true
 	}
@@ -248,6 +345,136 @@ func (r SortedIntSet) keys(h *nodeInt, visit func(int) bool, lo, hi int) bool {
 	return true
 }
 
+// ReverseKeys visit each keys in the sorted set, in reverse order.
+// It stops when visit returns false.
+func (r SortedIntSet) ReverseKeys(visit func(int) bool) {
+	min, ok := r.Min()
+	if !ok {
+		return
+	}
+	// if the min exists, then the max must exist
+	max, _ := r.Max()
+	r.RangedKeysDesc(min, max, visit)
+}
+
+// RangedKeysDesc visit each keys between lo and hi in the sorted set, in
+// reverse order. It stops when visit returns false.
+func (r SortedIntSet) RangedKeysDesc(lo, hi int, visit func(int) bool) {
+	r.keysDesc(r.root, visit, lo, hi)
+}
+
+func (r SortedIntSet) keysDesc(h *nodeSortedIntSet, visit func(int) bool, lo, hi int) bool {
+	if h == nil {
+		return true
+	}
+	cmplo := r.compare(lo, h.key)
+	cmphi := r.compare(hi, h.key)
+	if cmphi > 0 {
+		if !r.keysDesc(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	if cmplo <= 0 && cmphi >= 0 {
+		if !visit(h.key) {
+			return false
+		}
+	}
+	if cmplo < 0 {
+		if !r.keysDesc(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
+// SortedIntSetBound is an end of a range of keys: its Key, included in the range unless
+// it's Exclusive. An Unbounded end doesn't limit the range, whatever its Key.
+type SortedIntSetBound struct {
+	Key       int
+	Exclusive bool
+	Unbounded bool
+}
+
+// RangeCount is the number of keys between lo and hi in the sorted set. It
+// doesn't visit them, and takes O(log n).
+func (r SortedIntSet) RangeCount(lo, hi SortedIntSetBound) int {
+	n := r.boundrank(hi, true) - r.boundrank(lo, false)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// boundrank is the number of keys below b, or up to b for an upper bound.
+func (r SortedIntSet) boundrank(b SortedIntSetBound, upper bool) int {
+	if b.Unbounded {
+		if upper {
+			return r.Size()
+		}
+		return 0
+	}
+	// the key itself is below an exclusive lower bound or an inclusive
+	// upper bound
+	return r.keyrankBound(b.Key, r.root, upper != b.Exclusive)
+}
+
+func (r SortedIntSet) keyrankBound(k int, h *nodeSortedIntSet, withKey bool) int {
+	if h == nil {
+		return 0
+	}
+	cmp := r.compare(k, h.key)
+	if cmp < 0 {
+		return r.keyrankBound(k, h.left, withKey)
+	} else if cmp > 0 {
+		return 1 + h.left.size() + r.keyrankBound(k, h.right, withKey)
+	} else if withKey {
+		return h.left.size() + 1
+	} else {
+		return h.left.size()
+	}
+}
+
+// BoundedKeys visit each keys between lo and hi in the sorted set, in order.
+// It stops when visit returns false.
+func (r SortedIntSet) BoundedKeys(lo, hi SortedIntSetBound, visit func(int) bool) {
+	r.boundedKeys(r.root, visit, lo, hi)
+}
+
+func (r SortedIntSet) boundedKeys(h *nodeSortedIntSet, visit func(int) bool, lo, hi SortedIntSetBound) bool {
+	if h == nil {
+		return true
+	}
+	// whether the keys left and right of h can be in the range
+	left, right := true, true
+	inside := true
+	if !lo.Unbounded {
+		cmp := r.compare(lo.Key, h.key)
+		left = cmp < 0
+		inside = cmp < 0 || (cmp == 0 && !lo.Exclusive)
+	}
+	if !hi.Unbounded {
+		cmp := r.compare(hi.Key, h.key)
+		right = cmp > 0
+		inside = inside && (cmp > 0 || (cmp == 0 && !hi.Exclusive))
+	}
+	if left {
+		if !r.boundedKeys(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	if inside {
+		if !visit(h.key) {
+			return false
+		}
+	}
+	if right {
+		if !r.boundedKeys(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
 // DeleteMin removes the smallest key from the sorted set.
 func (r *SortedIntSet) DeleteMin() (oldk int, ok bool) {
 	r.root, oldk, ok = r.deleteMin(r.root)
@@ -257,7 +484,7 @@ func (r *SortedIntSet) DeleteMin() (oldk int, ok bool) {
 	return
 }
 
-func (r *SortedIntSet) deleteMin(h *nodeInt) (_ *nodeInt, oldk int, ok bool) {
+func (r *SortedIntSet) deleteMin(h *nodeSortedIntSet) (_ *nodeSortedIntSet, oldk int, ok bool) {
 	if h == nil {
 		return nil, oldk, false
 	}
@@ -281,7 +508,7 @@ func (r *SortedIntSet) DeleteMax() (oldk int, ok bool) {
 	return
 }
 
-func (r *SortedIntSet) deleteMax(h *nodeInt) (_ *nodeInt, oldk int, ok bool) {
+func (r *SortedIntSet) deleteMax(h *nodeSortedIntSet) (_ *nodeSortedIntSet, oldk int, ok bool) {
 	if h == nil {
 		return nil, oldk, ok
 	}
@@ -310,7 +537,7 @@ func (r *SortedIntSet) Delete(k int) (ok bool) {
 	return
 }
 
-func (r *SortedIntSet) delete(h *nodeInt, k int) (_ *nodeInt, ok bool) {
+func (r *SortedIntSet) delete(h *nodeSortedIntSet, k int) (_ *nodeSortedIntSet, ok bool) {
 
 	if h == nil {
 		return h, false
@@ -356,9 +583,216 @@ func (r *SortedIntSet) delete(h *nodeInt, k int) (_ *nodeInt, ok bool) {
 	return h, ok
 }
 
+// ToSlice returns the keys of the sorted set, in order.
+func (r SortedIntSet) ToSlice() []int {
+	keys := make([]int, 0, r.Size())
+	r.Keys(func(k int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+// DeleteRange removes the keys between lo and hi from the sorted set,
+// and returns how many it removed. It takes O(m log n) to remove m keys, or
+// O(n) when the range is a large part of the sorted set, which is then
+// rebuilt out of the keys left.
+func (r *SortedIntSet) DeleteRange(lo, hi SortedIntSetBound) int {
+	from, n, size := r.boundrank(lo, false), r.RangeCount(lo, hi), r.Size()
+	depth := 0
+	for s := size; s > 1; s /= 2 {
+		depth++
+	}
+	if n*depth < size {
+		for i := 0; i < n; i++ {
+			// the keys after those removed take their ranks
+			r.Delete(r.nodeselect(r.root, from).key)
+		}
+		return n
+	}
+
+	keys := make([]int, 0, size-n)
+	i := 0
+	r.Keys(func(k int) bool {
+		if i < from || i >= from+n {
+			keys = append(keys, k)
+		}
+		i++
+		return true
+	})
+	r.root = r.build(keys)
+	return n
+}
+
+// set algebra
+
+// Union returns a new sorted set of the keys in either r or other, which must
+// order its keys like r. It takes O(n+m).
+func (r SortedIntSet) Union(other *SortedIntSet) *SortedIntSet {
+	return r.with(r.merge(other, true, true, true))
+}
+
+// Intersection returns a new sorted set of the keys in both r and other,
+// which must order its keys like r. It takes O(n+m).
+func (r SortedIntSet) Intersection(other *SortedIntSet) *SortedIntSet {
+	return r.with(r.merge(other, false, true, false))
+}
+
+// Difference returns a new sorted set of the keys in r but not in other,
+// which must order its keys like r. It takes O(n+m).
+func (r SortedIntSet) Difference(other *SortedIntSet) *SortedIntSet {
+	return r.with(r.merge(other, true, false, false))
+}
+
+// SymmetricDifference returns a new sorted set of the keys in either r or
+// other but not in both, other ordering its keys like r. It takes O(n+m).
+func (r SortedIntSet) SymmetricDifference(other *SortedIntSet) *SortedIntSet {
+	return r.with(r.merge(other, true, false, true))
+}
+
+// UnionWith puts the keys of other in the sorted set, like Union but in
+// place.
+func (r *SortedIntSet) UnionWith(other *SortedIntSet) {
+	r.root = r.build(r.merge(other, true, true, true))
+}
+
+// IntersectionWith deletes the keys that aren't in other from the sorted set,
+// like Intersection but in place.
+func (r *SortedIntSet) IntersectionWith(other *SortedIntSet) {
+	r.root = r.build(r.merge(other, false, true, false))
+}
+
+// DifferenceWith deletes the keys of other from the sorted set, like
+// Difference but in place.
+func (r *SortedIntSet) DifferenceWith(other *SortedIntSet) {
+	r.root = r.build(r.merge(other, true, false, false))
+}
+
+// SymmetricDifferenceWith deletes the keys of other from the sorted set, and
+// puts those it didn't have, like SymmetricDifference but in place.
+func (r *SortedIntSet) SymmetricDifferenceWith(other *SortedIntSet) {
+	r.root = r.build(r.merge(other, true, false, true))
+}
+
+// IsSubset tells if every key of the sorted set is in other, which must
+// order its keys like r. It takes O(n+m).
+func (r SortedIntSet) IsSubset(other *SortedIntSet) bool {
+	if r.Size() > other.Size() {
+		return false
+	}
+	return len(r.merge(other, true, false, false)) == 0
+}
+
+// Equal tells if the sorted set and other have the same keys, other ordering
+// its keys like r. It takes O(n).
+func (r SortedIntSet) Equal(other *SortedIntSet) bool {
+	if r.Size() != other.Size() {
+		return false
+	}
+	a, b := r.NewCursor(), other.NewCursor()
+	for ok := a.First() && b.First(); ok; ok = a.Next() && b.Next() {
+		if r.compare(a.Key(), b.Key()) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// merge walks the keys of r and other in order, and returns those only in
+// r, in both or only in other, as asked.
+func (r SortedIntSet) merge(other *SortedIntSet, onlyR, both, onlyOther bool) []int {
+	var keys []int
+	a, b := r.NewCursor(), other.NewCursor()
+	a.First()
+	b.First()
+	for a.Valid() || b.Valid() {
+		if (!a.Valid() && !onlyOther) || (!b.Valid() && !onlyR) {
+			break
+		}
+		cmp := 0
+		if !a.Valid() {
+			cmp = 1
+		} else if !b.Valid() {
+			cmp = -1
+		} else {
+			cmp = r.compare(a.Key(), b.Key())
+		}
+		if cmp < 0 {
+			if onlyR {
+				keys = append(keys, a.Key())
+			}
+			a.Next()
+		} else if cmp > 0 {
+			if onlyOther {
+				keys = append(keys, b.Key())
+			}
+			b.Next()
+		} else {
+			if both {
+				keys = append(keys, a.Key())
+			}
+			a.Next()
+			b.Next()
+		}
+	}
+	return keys
+}
+
+// with returns a sorted set ordered like r, holding the sorted keys.
+func (r SortedIntSet) with(keys []int) *SortedIntSet {
+	r.root = r.build(keys)
+	return &r
+}
+
+// construction
+
+// dedup removes the duplicates from the sorted keys in place, keeping the
+// first of equal keys, as putting them would.
+func (r SortedIntSet) dedup(keys []int) []int {
+	n := 0
+	for i := range keys {
+		if n > 0 && r.compare(keys[n-1], keys[i]) == 0 {
+			continue
+		}
+		keys[n] = keys[i]
+		n++
+	}
+	return keys[:n]
+}
+
+// build returns the root of a tree holding the sorted keys, in O(n). The tree
+// is as balanced as can be, with the nodes of its last level red if it's not
+// full, and is fixed bottom up so that its red links lean left.
+func (r *SortedIntSet) build(keys []int) *nodeSortedIntSet {
+	if len(keys) == 0 {
+		return nil
+	}
+	// the depth of the last level, counting from 0, if it's not full
+	redDepth := -1
+	if n := len(keys); n&(n+1) != 0 {
+		for redDepth = 0; n > 1; n /= 2 {
+			redDepth++
+		}
+	}
+	root := r.buildNode(keys, 0, redDepth)
+	root.colorRed = false
+	return root
+}
+
+func (r *SortedIntSet) buildNode(keys []int, depth, redDepth int) *nodeSortedIntSet {
+	if len(keys) == 0 {
+		return nil
+	}
+	mid := len(keys) / 2
+	h := &nodeSortedIntSet{key: keys[mid], colorRed: depth == redDepth}
+	h.left = r.buildNode(keys[:mid], depth+1, redDepth)
+	h.right = r.buildNode(keys[mid+1:], depth+1, redDepth)
+	return r.balance(h)
+}
+
 // deletions
 
-func (r *SortedIntSet) moveRedLeft(h *nodeInt) *nodeInt {
+func (r *SortedIntSet) moveRedLeft(h *nodeSortedIntSet) *nodeSortedIntSet {
 	r.flipColors(h)
 	if h.right.left.isRed() {
 		h.right = r.rotateRight(h.right)
@@ -368,7 +802,7 @@ func (r *SortedIntSet) moveRedLeft(h *nodeInt) *nodeInt {
 	return h
 }
 
-func (r *SortedIntSet) moveRedRight(h *nodeInt) *nodeInt {
+func (r *SortedIntSet) moveRedRight(h *nodeSortedIntSet) *nodeSortedIntSet {
 	r.flipColors(h)
 	if h.left.left.isRed() {
 		h = r.rotateRight(h)
@@ -377,7 +811,7 @@ func (r *SortedIntSet) moveRedRight(h *nodeInt) *nodeInt {
 	return h
 }
 
-func (r *SortedIntSet) balance(h *nodeInt) *nodeInt {
+func (r *SortedIntSet) balance(h *nodeSortedIntSet) *nodeSortedIntSet {
 	if h.right.isRed() {
 		h = r.rotateLeft(h)
 	}
@@ -391,7 +825,7 @@ func (r *SortedIntSet) balance(h *nodeInt) *nodeInt {
 	return h
 }
 
-func (r *SortedIntSet) rotateLeft(h *nodeInt) *nodeInt {
+func (r *SortedIntSet) rotateLeft(h *nodeSortedIntSet) *nodeSortedIntSet {
 	x := h.right
 	h.right = x.left
 	x.left = h
@@ -402,7 +836,7 @@ func (r *SortedIntSet) rotateLeft(h *nodeInt) *nodeInt {
 	return x
 }
 
-func (r *SortedIntSet) rotateRight(h *nodeInt) *nodeInt {
+func (r *SortedIntSet) rotateRight(h *nodeSortedIntSet) *nodeSortedIntSet {
 	x := h.left
 	h.left = x.right
 	x.right = h
@@ -413,27 +847,143 @@ func (r *SortedIntSet) rotateRight(h *nodeInt) *nodeInt {
 	return x
 }
 
-func (r *SortedIntSet) flipColors(h *nodeInt) {
+func (r *SortedIntSet) flipColors(h *nodeSortedIntSet) {
 	h.colorRed = !h.colorRed
 	h.left.colorRed = !h.left.colorRed
 	h.right.colorRed = !h.right.colorRed
 }
 
+// cursors
+
+// SortedIntSetCursor is a position among the keys of a sorted set, moved from key to key
+// in either order. Unlike Keys, it can be paused, or moved along the cursor
+// of another sorted set. It's only valid until the sorted set is modified.
+type SortedIntSetCursor struct {
+	r SortedIntSet
+	// path from the root to the node under the cursor, empty when the cursor
+	// isn't on a key
+	path []*nodeSortedIntSet
+}
+
+// NewCursor returns a cursor of the sorted set, which isn't on a key until it's
+// moved with First, Last or Seek.
+func (r SortedIntSet) NewCursor() *SortedIntSetCursor { return &SortedIntSetCursor{r: r} }
+
+// Valid tells if the cursor is on a key.
+func (c *SortedIntSetCursor) Valid() bool { return len(c.path) != 0 }
+
+// First moves the cursor on the smallest key, and tells if there is one.
+func (c *SortedIntSetCursor) First() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.left {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Last moves the cursor on the largest key, and tells if there is one.
+func (c *SortedIntSetCursor) Last() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.right {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Seek moves the cursor on the smallest key larger than or equal to `k`, and
+// tells if there is one.
+func (c *SortedIntSetCursor) Seek(k int) bool {
+	c.path = c.path[:0]
+	// the ceiling is the last node left of which the search goes
+	ceiling := 0
+	for h := c.r.root; h != nil; {
+		c.path = append(c.path, h)
+		cmp := c.r.compare(k, h.key)
+		if cmp == 0 {
+			return true
+		} else if cmp < 0 {
+			ceiling = len(c.path)
+			h = h.left
+		} else {
+			h = h.right
+		}
+	}
+	c.path = c.path[:ceiling]
+	return c.Valid()
+}
+
+// Next moves the cursor on the following key, and tells if there is one.
+// Once past the largest key, the cursor isn't on a key anymore.
+func (c *SortedIntSetCursor) Next() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].right; h != nil {
+		for ; h != nil; h = h.left {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the left of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].left == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Prev moves the cursor on the preceding key, and tells if there is one.
+// Once past the smallest key, the cursor isn't on a key anymore.
+func (c *SortedIntSetCursor) Prev() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].left; h != nil {
+		for ; h != nil; h = h.right {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the right of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].right == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Key under the cursor. It panics if the cursor isn't on a key.
+func (c *SortedIntSetCursor) Key() int {
+	return c.node().key
+}
+
+func (c *SortedIntSetCursor) node() *nodeSortedIntSet {
+	if !c.Valid() {
+		panic("redblackbst: cursor isn't on a key")
+	}
+	return c.path[len(c.path)-1]
+}
+
 // nodes
 
-type nodeInt struct {
+type nodeSortedIntSet struct {
 	key         int
-	left, right *nodeInt
+	left, right *nodeSortedIntSet
 	n           int
 	colorRed    bool
 }
 
-func (x *nodeInt) isRed() bool { return (x != nil) && (x.colorRed == true) }
+func (x *nodeSortedIntSet) isRed() bool { return (x != nil) && (x.colorRed == true) }
 
-func (x *nodeInt) size() int {
+func (x *nodeSortedIntSet) size() int {
 	if x == nil {
 		return 0
 	}
 	return x.n
 }
-
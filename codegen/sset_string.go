@@ -1,25 +1,64 @@
+// Code generated by datagen; DO NOT EDIT.
+
 package codegen
 
+import "sort"
 
 func (r SortedStringSet) compare(a, b string) int {
-    if a < b {
-        return -1
-    }
-    if a > b {
-        return 1
-    }
-    return 0
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
 }
 
 // SortedStringSet is a sorted set built on a left leaning red black balanced
 // search sorted set. It stores unique string values.
 type SortedStringSet struct {
-	root *nodeString
+	root *nodeSortedStringSet
 }
 
 // NewSortedStringSet creates a sorted set.
 func NewSortedStringSet() *SortedStringSet { return &SortedStringSet{} }
 
+// NewSortedStringSetFromSorted creates a sorted set of the keys, given in order, in
+// O(n) rather than the O(n log n) of putting them one by one. Of equal keys,
+// the first one is kept, as putting them would. It panics if the keys aren't
+// in order.
+func NewSortedStringSetFromSorted(keys []string) *SortedStringSet {
+	r := &SortedStringSet{}
+	var dups bool
+	for i := 1; i < len(keys); i++ {
+		cmp := r.compare(keys[i-1], keys[i])
+		if cmp > 0 {
+			panic("redblackbst: keys aren't sorted")
+		}
+		dups = dups || cmp == 0
+	}
+	if dups {
+		// not to modify the slice given
+		keys = r.dedup(append([]string(nil), keys...))
+	}
+	r.root = r.build(keys)
+	return r
+}
+
+// NewSortedStringSetFromUnsorted creates a sorted set of the keys, sorting them
+// first, in O(n log n) but faster than putting them one by one. Of equal
+// keys, the first one is kept, as putting them would. The slice isn't
+// modified.
+func NewSortedStringSetFromUnsorted(keys []string) *SortedStringSet {
+	r := &SortedStringSet{}
+	sorted := append([]string(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return r.compare(sorted[i], sorted[j]) < 0
+	})
+	r.root = r.build(r.dedup(sorted))
+	return r
+}
+
 // IsEmpty tells if the sorted set contains no key.
 func (r SortedStringSet) IsEmpty() bool {
 	return r.root == nil
@@ -35,12 +74,13 @@ func (r *SortedStringSet) Clear() { r.root = nil }
 // true is returned.
 func (r *SortedStringSet) Put(k string) (already bool) {
 	r.root, already = r.put(r.root, k)
+	r.root.colorRed = false
 	return
 }
 
-func (r *SortedStringSet) put(h *nodeString, k string) (_ *nodeString, already bool) {
+func (r *SortedStringSet) put(h *nodeSortedStringSet, k string) (_ *nodeSortedStringSet, already bool) {
 	if h == nil {
-		n := &nodeString{key: k, n: 1, colorRed: true}
+		n := &nodeSortedStringSet{key: k, n: 1, colorRed: true}
 		return n, already
 	}
 
@@ -71,7 +111,7 @@ func (r SortedStringSet) Contains(k string) bool {
 	return r.loopContains(r.root, k)
 }
 
-func (r SortedStringSet) loopContains(h *nodeString, k string) (ok bool) {
+func (r SortedStringSet) loopContains(h *nodeSortedStringSet, k string) (ok bool) {
 	for h != nil {
 		cmp := r.compare(k, h.key)
 		if cmp == 0 {
@@ -94,7 +134,7 @@ func (r SortedStringSet) Min() (k string, ok bool) {
 	return h.key, true
 }
 
-func (r SortedStringSet) min(x *nodeString) *nodeString {
+func (r SortedStringSet) min(x *nodeSortedStringSet) *nodeSortedStringSet {
 	if x.left == nil {
 		return x
 	}
@@ -110,7 +150,7 @@ func (r SortedStringSet) Max() (k string, ok bool) {
 	return h.key, true
 }
 
-func (r SortedStringSet) max(x *nodeString) *nodeString {
+func (r SortedStringSet) max(x *nodeSortedStringSet) *nodeSortedStringSet {
 	if x.right == nil {
 		return x
 	}
@@ -118,7 +158,7 @@ func (r SortedStringSet) max(x *nodeString) *nodeString {
 }
 
 // Floor returns the largest key in the sorted set that is smaller than
-// `k`.
+// or equal to `k`, if it exists.
 func (r SortedStringSet) Floor(key string) (k string, ok bool) {
 	x := r.floor(r.root, key)
 	if x == nil {
@@ -127,7 +167,7 @@ func (r SortedStringSet) Floor(key string) (k string, ok bool) {
 	return x.key, true
 }
 
-func (r SortedStringSet) floor(h *nodeString, k string) *nodeString {
+func (r SortedStringSet) floor(h *nodeSortedStringSet, k string) *nodeSortedStringSet {
 	if h == nil {
 		return nil
 	}
@@ -146,7 +186,7 @@ func (r SortedStringSet) floor(h *nodeString, k string) *nodeString {
 }
 
 // Ceiling returns the smallest key in the sorted set that is larger than
-// `k`.
+// or equal to `k`, if it exists.
 func (r SortedStringSet) Ceiling(key string) (k string, ok bool) {
 	x := r.ceiling(r.root, key)
 	if x == nil {
@@ -155,7 +195,7 @@ func (r SortedStringSet) Ceiling(key string) (k string, ok bool) {
 	return x.key, true
 }
 
-func (r SortedStringSet) ceiling(h *nodeString, k string) *nodeString {
+func (r SortedStringSet) ceiling(h *nodeSortedStringSet, k string) *nodeSortedStringSet {
 	if h == nil {
 		return nil
 	}
@@ -173,6 +213,54 @@ func (r SortedStringSet) ceiling(h *nodeString, k string) *nodeString {
 	return h
 }
 
+// Lower returns the largest key in the sorted set that is strictly
+// smaller than `k`, its predecessor, if it exists.
+func (r SortedStringSet) Lower(key string) (k string, ok bool) {
+	x := r.lower(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, true
+}
+
+func (r SortedStringSet) lower(h *nodeSortedStringSet, k string) *nodeSortedStringSet {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) <= 0 {
+		return r.lower(h.left, k)
+	}
+	t := r.lower(h.right, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
+// Higher returns the smallest key in the sorted set that is strictly
+// larger than `k`, its successor, if it exists.
+func (r SortedStringSet) Higher(key string) (k string, ok bool) {
+	x := r.higher(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, true
+}
+
+func (r SortedStringSet) higher(h *nodeSortedStringSet, k string) *nodeSortedStringSet {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) >= 0 {
+		return r.higher(h.right, k)
+	}
+	t := r.higher(h.left, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
 // Select key of rank k, meaning the k-th biggest string in the sorted set.
 func (r SortedStringSet) Select(key int) (k string, ok bool) {
 	x := r.nodeselect(r.root, key)
@@ -182,7 +270,7 @@ func (r SortedStringSet) Select(key int) (k string, ok bool) {
 	return x.key, true
 }
 
-func (r SortedStringSet) nodeselect(x *nodeString, k int) *nodeString {
+func (r SortedStringSet) nodeselect(x *nodeSortedStringSet, k int) *nodeSortedStringSet {
 	if x == nil {
 		return nil
 	}
@@ -201,7 +289,7 @@ func (r SortedStringSet) Rank(k string) int {
 	return r.keyrank(k, r.root)
 }
 
-func (r SortedStringSet) keyrank(k string, h *nodeString) int {
+func (r SortedStringSet) keyrank(k string, h *nodeSortedStringSet) int {
 	if h == nil {
 		return 0
 	}
@@ -233,7 +321,7 @@ func (r SortedStringSet) RangedKeys(lo, hi string, visit func(string) bool) {
 	r.keys(r.root, visit, lo, hi)
 }
 
-func (r SortedStringSet) keys(h *nodeString, visit func(string) bool, lo, hi string) bool {
+func (r SortedStringSet) keys(h *nodeSortedStringSet, visit func(string) bool, lo, hi string) bool {
 	if h == nil {
 		return true
 	}
@@ -257,6 +345,136 @@ func (r SortedStringSet) keys(h *nodeString, visit func(string) bool, lo, hi str
 	return true
 }
 
+// ReverseKeys visit each keys in the sorted set, in reverse order.
+// It stops when visit returns false.
+func (r SortedStringSet) ReverseKeys(visit func(string) bool) {
+	min, ok := r.Min()
+	if !ok {
+		return
+	}
+	// if the min exists, then the max must exist
+	max, _ := r.Max()
+	r.RangedKeysDesc(min, max, visit)
+}
+
+// RangedKeysDesc visit each keys between lo and hi in the sorted set, in
+// reverse order. It stops when visit returns false.
+func (r SortedStringSet) RangedKeysDesc(lo, hi string, visit func(string) bool) {
+	r.keysDesc(r.root, visit, lo, hi)
+}
+
+func (r SortedStringSet) keysDesc(h *nodeSortedStringSet, visit func(string) bool, lo, hi string) bool {
+	if h == nil {
+		return true
+	}
+	cmplo := r.compare(lo, h.key)
+	cmphi := r.compare(hi, h.key)
+	if cmphi > 0 {
+		if !r.keysDesc(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	if cmplo <= 0 && cmphi >= 0 {
+		if !visit(h.key) {
+			return false
+		}
+	}
+	if cmplo < 0 {
+		if !r.keysDesc(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
+// SortedStringSetBound is an end of a range of keys: its Key, included in the range unless
+// it's Exclusive. An Unbounded end doesn't limit the range, whatever its Key.
+type SortedStringSetBound struct {
+	Key       string
+	Exclusive bool
+	Unbounded bool
+}
+
+// RangeCount is the number of keys between lo and hi in the sorted set. It
+// doesn't visit them, and takes O(log n).
+func (r SortedStringSet) RangeCount(lo, hi SortedStringSetBound) int {
+	n := r.boundrank(hi, true) - r.boundrank(lo, false)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// boundrank is the number of keys below b, or up to b for an upper bound.
+func (r SortedStringSet) boundrank(b SortedStringSetBound, upper bool) int {
+	if b.Unbounded {
+		if upper {
+			return r.Size()
+		}
+		return 0
+	}
+	// the key itself is below an exclusive lower bound or an inclusive
+	// upper bound
+	return r.keyrankBound(b.Key, r.root, upper != b.Exclusive)
+}
+
+func (r SortedStringSet) keyrankBound(k string, h *nodeSortedStringSet, withKey bool) int {
+	if h == nil {
+		return 0
+	}
+	cmp := r.compare(k, h.key)
+	if cmp < 0 {
+		return r.keyrankBound(k, h.left, withKey)
+	} else if cmp > 0 {
+		return 1 + h.left.size() + r.keyrankBound(k, h.right, withKey)
+	} else if withKey {
+		return h.left.size() + 1
+	} else {
+		return h.left.size()
+	}
+}
+
+// BoundedKeys visit each keys between lo and hi in the sorted set, in order.
+// It stops when visit returns false.
+func (r SortedStringSet) BoundedKeys(lo, hi SortedStringSetBound, visit func(string) bool) {
+	r.boundedKeys(r.root, visit, lo, hi)
+}
+
+func (r SortedStringSet) boundedKeys(h *nodeSortedStringSet, visit func(string) bool, lo, hi SortedStringSetBound) bool {
+	if h == nil {
+		return true
+	}
+	// whether the keys left and right of h can be in the range
+	left, right := true, true
+	inside := true
+	if !lo.Unbounded {
+		cmp := r.compare(lo.Key, h.key)
+		left = cmp < 0
+		inside = cmp < 0 || (cmp == 0 && !lo.Exclusive)
+	}
+	if !hi.Unbounded {
+		cmp := r.compare(hi.Key, h.key)
+		right = cmp > 0
+		inside = inside && (cmp > 0 || (cmp == 0 && !hi.Exclusive))
+	}
+	if left {
+		if !r.boundedKeys(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	if inside {
+		if !visit(h.key) {
+			return false
+		}
+	}
+	if right {
+		if !r.boundedKeys(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
 // DeleteMin removes the smallest key from the sorted set.
 func (r *SortedStringSet) DeleteMin() (oldk string, ok bool) {
 	r.root, oldk, ok = r.deleteMin(r.root)
@@ -266,7 +484,7 @@ func (r *SortedStringSet) DeleteMin() (oldk string, ok bool) {
 	return
 }
 
-func (r *SortedStringSet) deleteMin(h *nodeString) (_ *nodeString, oldk string, ok bool) {
+func (r *SortedStringSet) deleteMin(h *nodeSortedStringSet) (_ *nodeSortedStringSet, oldk string, ok bool) {
 	if h == nil {
 		return nil, oldk, false
 	}
@@ -290,7 +508,7 @@ func (r *SortedStringSet) DeleteMax() (oldk string, ok bool) {
 	return
 }
 
-func (r *SortedStringSet) deleteMax(h *nodeString) (_ *nodeString, oldk string, ok bool) {
+func (r *SortedStringSet) deleteMax(h *nodeSortedStringSet) (_ *nodeSortedStringSet, oldk string, ok bool) {
 	if h == nil {
 		return nil, oldk, ok
 	}
@@ -319,7 +537,7 @@ func (r *SortedStringSet) Delete(k string) (ok bool) {
 	return
 }
 
-func (r *SortedStringSet) delete(h *nodeString, k string) (_ *nodeString, ok bool) {
+func (r *SortedStringSet) delete(h *nodeSortedStringSet, k string) (_ *nodeSortedStringSet, ok bool) {
 
 	if h == nil {
 		return h, false
@@ -365,9 +583,216 @@ func (r *SortedStringSet) delete(h *nodeString, k string) (_ *nodeString, ok boo
 	return h, ok
 }
 
+// ToSlice returns the keys of the sorted set, in order.
+func (r SortedStringSet) ToSlice() []string {
+	keys := make([]string, 0, r.Size())
+	r.Keys(func(k string) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+// DeleteRange removes the keys between lo and hi from the sorted set,
+// and returns how many it removed. It takes O(m log n) to remove m keys, or
+// O(n) when the range is a large part of the sorted set, which is then
+// rebuilt out of the keys left.
+func (r *SortedStringSet) DeleteRange(lo, hi SortedStringSetBound) int {
+	from, n, size := r.boundrank(lo, false), r.RangeCount(lo, hi), r.Size()
+	depth := 0
+	for s := size; s > 1; s /= 2 {
+		depth++
+	}
+	if n*depth < size {
+		for i := 0; i < n; i++ {
+			// the keys after those removed take their ranks
+			r.Delete(r.nodeselect(r.root, from).key)
+		}
+		return n
+	}
+
+	keys := make([]string, 0, size-n)
+	i := 0
+	r.Keys(func(k string) bool {
+		if i < from || i >= from+n {
+			keys = append(keys, k)
+		}
+		i++
+		return true
+	})
+	r.root = r.build(keys)
+	return n
+}
+
+// set algebra
+
+// Union returns a new sorted set of the keys in either r or other, which must
+// order its keys like r. It takes O(n+m).
+func (r SortedStringSet) Union(other *SortedStringSet) *SortedStringSet {
+	return r.with(r.merge(other, true, true, true))
+}
+
+// Intersection returns a new sorted set of the keys in both r and other,
+// which must order its keys like r. It takes O(n+m).
+func (r SortedStringSet) Intersection(other *SortedStringSet) *SortedStringSet {
+	return r.with(r.merge(other, false, true, false))
+}
+
+// Difference returns a new sorted set of the keys in r but not in other,
+// which must order its keys like r. It takes O(n+m).
+func (r SortedStringSet) Difference(other *SortedStringSet) *SortedStringSet {
+	return r.with(r.merge(other, true, false, false))
+}
+
+// SymmetricDifference returns a new sorted set of the keys in either r or
+// other but not in both, other ordering its keys like r. It takes O(n+m).
+func (r SortedStringSet) SymmetricDifference(other *SortedStringSet) *SortedStringSet {
+	return r.with(r.merge(other, true, false, true))
+}
+
+// UnionWith puts the keys of other in the sorted set, like Union but in
+// place.
+func (r *SortedStringSet) UnionWith(other *SortedStringSet) {
+	r.root = r.build(r.merge(other, true, true, true))
+}
+
+// IntersectionWith deletes the keys that aren't in other from the sorted set,
+// like Intersection but in place.
+func (r *SortedStringSet) IntersectionWith(other *SortedStringSet) {
+	r.root = r.build(r.merge(other, false, true, false))
+}
+
+// DifferenceWith deletes the keys of other from the sorted set, like
+// Difference but in place.
+func (r *SortedStringSet) DifferenceWith(other *SortedStringSet) {
+	r.root = r.build(r.merge(other, true, false, false))
+}
+
+// SymmetricDifferenceWith deletes the keys of other from the sorted set, and
+// puts those it didn't have, like SymmetricDifference but in place.
+func (r *SortedStringSet) SymmetricDifferenceWith(other *SortedStringSet) {
+	r.root = r.build(r.merge(other, true, false, true))
+}
+
+// IsSubset tells if every key of the sorted set is in other, which must
+// order its keys like r. It takes O(n+m).
+func (r SortedStringSet) IsSubset(other *SortedStringSet) bool {
+	if r.Size() > other.Size() {
+		return false
+	}
+	return len(r.merge(other, true, false, false)) == 0
+}
+
+// Equal tells if the sorted set and other have the same keys, other ordering
+// its keys like r. It takes O(n).
+func (r SortedStringSet) Equal(other *SortedStringSet) bool {
+	if r.Size() != other.Size() {
+		return false
+	}
+	a, b := r.NewCursor(), other.NewCursor()
+	for ok := a.First() && b.First(); ok; ok = a.Next() && b.Next() {
+		if r.compare(a.Key(), b.Key()) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// merge walks the keys of r and other in order, and returns those only in
+// r, in both or only in other, as asked.
+func (r SortedStringSet) merge(other *SortedStringSet, onlyR, both, onlyOther bool) []string {
+	var keys []string
+	a, b := r.NewCursor(), other.NewCursor()
+	a.First()
+	b.First()
+	for a.Valid() || b.Valid() {
+		if (!a.Valid() && !onlyOther) || (!b.Valid() && !onlyR) {
+			break
+		}
+		cmp := 0
+		if !a.Valid() {
+			cmp = 1
+		} else if !b.Valid() {
+			cmp = -1
+		} else {
+			cmp = r.compare(a.Key(), b.Key())
+		}
+		if cmp < 0 {
+			if onlyR {
+				keys = append(keys, a.Key())
+			}
+			a.Next()
+		} else if cmp > 0 {
+			if onlyOther {
+				keys = append(keys, b.Key())
+			}
+			b.Next()
+		} else {
+			if both {
+				keys = append(keys, a.Key())
+			}
+			a.Next()
+			b.Next()
+		}
+	}
+	return keys
+}
+
+// with returns a sorted set ordered like r, holding the sorted keys.
+func (r SortedStringSet) with(keys []string) *SortedStringSet {
+	r.root = r.build(keys)
+	return &r
+}
+
+// construction
+
+// dedup removes the duplicates from the sorted keys in place, keeping the
+// first of equal keys, as putting them would.
+func (r SortedStringSet) dedup(keys []string) []string {
+	n := 0
+	for i := range keys {
+		if n > 0 && r.compare(keys[n-1], keys[i]) == 0 {
+			continue
+		}
+		keys[n] = keys[i]
+		n++
+	}
+	return keys[:n]
+}
+
+// build returns the root of a tree holding the sorted keys, in O(n). The tree
+// is as balanced as can be, with the nodes of its last level red if it's not
+// full, and is fixed bottom up so that its red links lean left.
+func (r *SortedStringSet) build(keys []string) *nodeSortedStringSet {
+	if len(keys) == 0 {
+		return nil
+	}
+	// the depth of the last level, counting from 0, if it's not full
+	redDepth := -1
+	if n := len(keys); n&(n+1) != 0 {
+		for redDepth = 0; n > 1; n /= 2 {
+			redDepth++
+		}
+	}
+	root := r.buildNode(keys, 0, redDepth)
+	root.colorRed = false
+	return root
+}
+
+func (r *SortedStringSet) buildNode(keys []string, depth, redDepth int) *nodeSortedStringSet {
+	if len(keys) == 0 {
+		return nil
+	}
+	mid := len(keys) / 2
+	h := &nodeSortedStringSet{key: keys[mid], colorRed: depth == redDepth}
+	h.left = r.buildNode(keys[:mid], depth+1, redDepth)
+	h.right = r.buildNode(keys[mid+1:], depth+1, redDepth)
+	return r.balance(h)
+}
+
 // deletions
 
-func (r *SortedStringSet) moveRedLeft(h *nodeString) *nodeString {
+func (r *SortedStringSet) moveRedLeft(h *nodeSortedStringSet) *nodeSortedStringSet {
 	r.flipColors(h)
 	if h.right.left.isRed() {
 		h.right = r.rotateRight(h.right)
@@ -377,7 +802,7 @@ func (r *SortedStringSet) moveRedLeft(h *nodeString) *nodeString {
 	return h
 }
 
-func (r *SortedStringSet) moveRedRight(h *nodeString) *nodeString {
+func (r *SortedStringSet) moveRedRight(h *nodeSortedStringSet) *nodeSortedStringSet {
 	r.flipColors(h)
 	if h.left.left.isRed() {
 		h = r.rotateRight(h)
@@ -386,7 +811,7 @@ func (r *SortedStringSet) moveRedRight(h *nodeString) *nodeString {
 	return h
 }
 
-func (r *SortedStringSet) balance(h *nodeString) *nodeString {
+func (r *SortedStringSet) balance(h *nodeSortedStringSet) *nodeSortedStringSet {
 	if h.right.isRed() {
 		h = r.rotateLeft(h)
 	}
@@ -400,7 +825,7 @@ func (r *SortedStringSet) balance(h *nodeString) *nodeString {
 	return h
 }
 
-func (r *SortedStringSet) rotateLeft(h *nodeString) *nodeString {
+func (r *SortedStringSet) rotateLeft(h *nodeSortedStringSet) *nodeSortedStringSet {
 	x := h.right
 	h.right = x.left
 	x.left = h
@@ -411,7 +836,7 @@ func (r *SortedStringSet) rotateLeft(h *nodeString) *nodeString {
 	return x
 }
 
-func (r *SortedStringSet) rotateRight(h *nodeString) *nodeString {
+func (r *SortedStringSet) rotateRight(h *nodeSortedStringSet) *nodeSortedStringSet {
 	x := h.left
 	h.left = x.right
 	x.right = h
@@ -422,27 +847,143 @@ func (r *SortedStringSet) rotateRight(h *nodeString) *nodeString {
 	return x
 }
 
-func (r *SortedStringSet) flipColors(h *nodeString) {
+func (r *SortedStringSet) flipColors(h *nodeSortedStringSet) {
 	h.colorRed = !h.colorRed
 	h.left.colorRed = !h.left.colorRed
 	h.right.colorRed = !h.right.colorRed
 }
 
+// cursors
+
+// SortedStringSetCursor is a position among the keys of a sorted set, moved from key to key
+// in either order. Unlike Keys, it can be paused, or moved along the cursor
+// of another sorted set. It's only valid until the sorted set is modified.
+type SortedStringSetCursor struct {
+	r SortedStringSet
+	// path from the root to the node under the cursor, empty when the cursor
+	// isn't on a key
+	path []*nodeSortedStringSet
+}
+
+// NewCursor returns a cursor of the sorted set, which isn't on a key until it's
+// moved with First, Last or Seek.
+func (r SortedStringSet) NewCursor() *SortedStringSetCursor { return &SortedStringSetCursor{r: r} }
+
+// Valid tells if the cursor is on a key.
+func (c *SortedStringSetCursor) Valid() bool { return len(c.path) != 0 }
+
+// First moves the cursor on the smallest key, and tells if there is one.
+func (c *SortedStringSetCursor) First() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.left {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Last moves the cursor on the largest key, and tells if there is one.
+func (c *SortedStringSetCursor) Last() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.right {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Seek moves the cursor on the smallest key larger than or equal to `k`, and
+// tells if there is one.
+func (c *SortedStringSetCursor) Seek(k string) bool {
+	c.path = c.path[:0]
+	// the ceiling is the last node left of which the search goes
+	ceiling := 0
+	for h := c.r.root; h != nil; {
+		c.path = append(c.path, h)
+		cmp := c.r.compare(k, h.key)
+		if cmp == 0 {
+			return true
+		} else if cmp < 0 {
+			ceiling = len(c.path)
+			h = h.left
+		} else {
+			h = h.right
+		}
+	}
+	c.path = c.path[:ceiling]
+	return c.Valid()
+}
+
+// Next moves the cursor on the following key, and tells if there is one.
+// Once past the largest key, the cursor isn't on a key anymore.
+func (c *SortedStringSetCursor) Next() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].right; h != nil {
+		for ; h != nil; h = h.left {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the left of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].left == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Prev moves the cursor on the preceding key, and tells if there is one.
+// Once past the smallest key, the cursor isn't on a key anymore.
+func (c *SortedStringSetCursor) Prev() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].left; h != nil {
+		for ; h != nil; h = h.right {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the right of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].right == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Key under the cursor. It panics if the cursor isn't on a key.
+func (c *SortedStringSetCursor) Key() string {
+	return c.node().key
+}
+
+func (c *SortedStringSetCursor) node() *nodeSortedStringSet {
+	if !c.Valid() {
+		panic("redblackbst: cursor isn't on a key")
+	}
+	return c.path[len(c.path)-1]
+}
+
 // nodes
 
-type nodeString struct {
+type nodeSortedStringSet struct {
 	key         string
-	left, right *nodeString
+	left, right *nodeSortedStringSet
 	n           int
 	colorRed    bool
 }
 
-func (x *nodeString) isRed() bool { return (x != nil) && (x.colorRed == true) }
+func (x *nodeSortedStringSet) isRed() bool { return (x != nil) && (x.colorRed == true) }
 
-func (x *nodeString) size() int {
+func (x *nodeSortedStringSet) size() int {
 	if x == nil {
 		return 0
 	}
 	return x.n
 }
-
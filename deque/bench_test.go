@@ -0,0 +1,176 @@
+package deque
+
+import (
+	"container/list"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// The benchmarks of this file are generated along with deques, when asked
+// to. The elements are generated by benchKType.
+
+// benchDequeSizes are the numbers of elements in the benchmarked deques.
+var benchDequeSizes = []int{100, 10000, 1000000}
+
+// benchDeque runs bench for each size, with that many random elements. The
+// elements are the same from one benchmark to the other.
+func benchDeque(b *testing.B, bench func(b *testing.B, elems []KType)) {
+	for _, n := range benchDequeSizes {
+		n := n
+		var elems []KType
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			// once for all the runs of the benchmark, and only if it's run
+			if elems == nil {
+				rnd := rand.New(rand.NewSource(int64(n)))
+				elems = make([]KType, n)
+				for i := range elems {
+					elems[i] = benchKType(rnd)
+				}
+			}
+			b.ResetTimer()
+			bench(b, elems)
+		})
+	}
+}
+
+// fillDeque returns a deque of capacity c holding elems.
+func fillDeque(c int, elems []KType) *Deque {
+	q := NewDeque(c)
+	for _, e := range elems {
+		q.PushBack(e)
+	}
+	return q
+}
+
+func BenchmarkDequePushBack(b *testing.B) {
+	benchDeque(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := fillDeque(2*n, elems)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.PushBack(elems[i%n])
+			if q.count == 2*n {
+				// drop the last n elements, without resizing
+				q.count = n
+				q.tail = (q.head + n) % len(q.buf)
+			}
+		}
+	})
+}
+
+func BenchmarkDequePushFront(b *testing.B) {
+	benchDeque(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := fillDeque(2*n, elems)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.PushFront(elems[i%n])
+			if q.count == 2*n {
+				// drop the first n elements, without resizing
+				q.count = n
+				q.head = (q.tail - n + len(q.buf)) % len(q.buf)
+			}
+		}
+	})
+}
+
+func BenchmarkDequePopFront(b *testing.B) {
+	benchDeque(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		// a full deque at its minimum capacity doesn't resize when popped
+		q := fillDeque(n, elems)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.PopFront()
+			if q.count == 0 {
+				copy(q.buf, elems)
+				q.head, q.tail, q.count = 0, n%len(q.buf), n
+			}
+		}
+	})
+}
+
+func BenchmarkDequePopBack(b *testing.B) {
+	benchDeque(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := fillDeque(n, elems)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.PopBack()
+			if q.count == 0 {
+				copy(q.buf, elems)
+				q.head, q.tail, q.count = 0, n%len(q.buf), n
+			}
+		}
+	})
+}
+
+func BenchmarkDequeGet(b *testing.B) {
+	benchDeque(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := fillDeque(n, elems)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Get(i % n)
+		}
+	})
+}
+
+// BenchmarkDequeInsertRemove inserts and removes elements at every index, so
+// that half the elements move on average.
+func BenchmarkDequeInsertRemove(b *testing.B) {
+	benchDeque(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := fillDeque(2*n, elems)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			at := i % n
+			q.Insert(at, elems[at])
+			q.Remove(at)
+		}
+	})
+}
+
+func BenchmarkDequeRotate(b *testing.B) {
+	benchDeque(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := fillDeque(2*n, elems)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Rotate(i % n)
+		}
+	})
+}
+
+// BenchmarkDequePushFrontPopBack is to be compared with
+// BenchmarkDequePushFrontPopBackContainer.
+func BenchmarkDequePushFrontPopBack(b *testing.B) {
+	benchDeque(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		// room to push without resizing
+		q := fillDeque(2*n, elems)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.PushFront(elems[i%n])
+			q.PopBack()
+		}
+	})
+}
+
+// BenchmarkDequePushFrontPopBackContainer holds the elements as interface{}
+// in a container/list.
+func BenchmarkDequePushFrontPopBackContainer(b *testing.B) {
+	benchDeque(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		l := list.New()
+		for _, e := range elems {
+			l.PushBack(e)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			l.PushFront(elems[i%n])
+			_ = l.Remove(l.Back()).(KType)
+		}
+	})
+}
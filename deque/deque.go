@@ -0,0 +1,286 @@
+package deque
+
+// The ring buffer is the one of the queue, adapted from
+// github.com/eapache/queue:
+//    The MIT License (MIT)
+//    Copyright (c) 2014 Evan Huus
+
+var nilKType KType
+
+// Deque represents a single instance of the double-ended queue data
+// structure. Elements are pushed and popped at both ends in O(1).
+type Deque struct {
+	buf               []KType
+	head, tail, count int
+	minlen            int
+}
+
+// NewDeque constructs and returns a new Deque with an initial capacity.
+func NewDeque(capacity int) *Deque {
+	// min capacity of 16
+	if capacity < 16 {
+		capacity = 16
+	}
+	return &Deque{buf: make([]KType, capacity), minlen: capacity}
+}
+
+// Len returns the number of elements currently stored in the deque.
+func (q *Deque) Len() int {
+	return q.count
+}
+
+// PushBack puts an element at the back of the deque.
+func (q *Deque) PushBack(elem KType) {
+	if q.count == len(q.buf) {
+		q.resize()
+	}
+
+	q.buf[q.tail] = elem
+	q.tail = q.next(q.tail)
+	q.count++
+}
+
+// PushFront puts an element at the front of the deque.
+func (q *Deque) PushFront(elem KType) {
+	if q.count == len(q.buf) {
+		q.resize()
+	}
+
+	q.head = q.prev(q.head)
+	q.buf[q.head] = elem
+	q.count++
+}
+
+// PeekFront returns the element at the front of the deque. This call panics
+// if the deque is empty.
+func (q *Deque) PeekFront() KType {
+	if q.count <= 0 {
+		panic("deque: empty deque")
+	}
+	return q.buf[q.head]
+}
+
+// TryPeekFront is like PeekFront, but tells if there was an element instead
+// of panicking on an empty deque.
+func (q *Deque) TryPeekFront() (KType, bool) {
+	if q.count == 0 {
+		return nilKType, false
+	}
+	return q.buf[q.head], true
+}
+
+// PeekBack returns the element at the back of the deque. This call panics
+// if the deque is empty.
+func (q *Deque) PeekBack() KType {
+	if q.count <= 0 {
+		panic("deque: empty deque")
+	}
+	return q.buf[q.prev(q.tail)]
+}
+
+// TryPeekBack is like PeekBack, but tells if there was an element instead of
+// panicking on an empty deque.
+func (q *Deque) TryPeekBack() (KType, bool) {
+	if q.count == 0 {
+		return nilKType, false
+	}
+	return q.buf[q.prev(q.tail)], true
+}
+
+// PopFront removes the element from the front of the deque. This call panics
+// if the deque is empty.
+func (q *Deque) PopFront() KType {
+	if q.count <= 0 {
+		panic("deque: empty deque")
+	}
+	v := q.buf[q.head]
+	// set to nil to avoid keeping reference to objects
+	// that would otherwise be garbage collected
+	q.buf[q.head] = nilKType
+	q.head = q.next(q.head)
+	q.count--
+	q.shrink()
+	return v
+}
+
+// TryPopFront is like PopFront, but tells if there was an element instead of
+// panicking on an empty deque.
+func (q *Deque) TryPopFront() (KType, bool) {
+	if q.count == 0 {
+		return nilKType, false
+	}
+	return q.PopFront(), true
+}
+
+// PopBack removes the element from the back of the deque. This call panics
+// if the deque is empty.
+func (q *Deque) PopBack() KType {
+	if q.count <= 0 {
+		panic("deque: empty deque")
+	}
+	q.tail = q.prev(q.tail)
+	v := q.buf[q.tail]
+	q.buf[q.tail] = nilKType
+	q.count--
+	q.shrink()
+	return v
+}
+
+// TryPopBack is like PopBack, but tells if there was an element instead of
+// panicking on an empty deque.
+func (q *Deque) TryPopBack() (KType, bool) {
+	if q.count == 0 {
+		return nilKType, false
+	}
+	return q.PopBack(), true
+}
+
+// Get returns the element at index i in the deque, the front being at index
+// 0. If the index is invalid, the call will panic.
+func (q *Deque) Get(i int) KType {
+	if i >= q.count || i < 0 {
+		panic("deque: index out of range")
+	}
+	return q.buf[q.at(i)]
+}
+
+// Set replaces the element at index i in the deque by elem. If the index is
+// invalid, the call will panic.
+func (q *Deque) Set(i int, elem KType) {
+	if i >= q.count || i < 0 {
+		panic("deque: index out of range")
+	}
+	q.buf[q.at(i)] = elem
+}
+
+// Insert puts elem at index i in the deque, moving the elements after it one
+// index up. The index can be Len(), to insert at the back. If the index is
+// invalid, the call will panic. The complexity is O(min(i, n-i)) where
+// n == q.Len(), as the elements on the closest end are the ones moved.
+func (q *Deque) Insert(i int, elem KType) {
+	if i > q.count || i < 0 {
+		panic("deque: index out of range")
+	}
+	if q.count == len(q.buf) {
+		q.resize()
+	}
+
+	if i < q.count/2 {
+		// move the elements before i one step to the front
+		q.head = q.prev(q.head)
+		for j := 0; j < i; j++ {
+			q.buf[q.at(j)] = q.buf[q.at(j+1)]
+		}
+	} else {
+		// move the elements from i one step to the back
+		for j := q.count; j > i; j-- {
+			q.buf[q.at(j)] = q.buf[q.at(j-1)]
+		}
+		q.tail = q.next(q.tail)
+	}
+	q.count++
+	q.buf[q.at(i)] = elem
+}
+
+// Remove removes the element at index i in the deque and returns it, moving
+// the elements after it one index down. If the index is invalid, the call
+// will panic. The complexity is O(min(i, n-i)) where n == q.Len(), as the
+// elements on the closest end are the ones moved.
+func (q *Deque) Remove(i int) KType {
+	if i >= q.count || i < 0 {
+		panic("deque: index out of range")
+	}
+	v := q.buf[q.at(i)]
+
+	if i < q.count/2 {
+		// move the elements before i one step to the back
+		for j := i; j > 0; j-- {
+			q.buf[q.at(j)] = q.buf[q.at(j-1)]
+		}
+		q.buf[q.head] = nilKType
+		q.head = q.next(q.head)
+	} else {
+		// move the elements after i one step to the front
+		for j := i; j < q.count-1; j++ {
+			q.buf[q.at(j)] = q.buf[q.at(j+1)]
+		}
+		q.tail = q.prev(q.tail)
+		q.buf[q.tail] = nilKType
+	}
+	q.count--
+	q.shrink()
+	return v
+}
+
+// Rotate rotates the deque n steps to the back: the element at index i moves
+// to index (i+n) mod Len(), and the last n elements move to the front. A
+// negative n rotates the deque to the front. The complexity is
+// O(min(n, Len()-n)).
+func (q *Deque) Rotate(n int) {
+	if q.count <= 1 {
+		return
+	}
+	n %= q.count
+	if n < 0 {
+		n += q.count
+	}
+	if n == 0 {
+		return
+	}
+	if q.count == len(q.buf) {
+		// the buffer is full, the elements are already where they go
+		q.head = (q.head - n + len(q.buf)) % len(q.buf)
+		q.tail = q.head
+		return
+	}
+
+	if n <= q.count/2 {
+		// move the last n elements to the front
+		for ; n > 0; n-- {
+			q.tail = q.prev(q.tail)
+			q.head = q.prev(q.head)
+			q.buf[q.head] = q.buf[q.tail]
+			q.buf[q.tail] = nilKType
+		}
+		return
+	}
+	// move the first count-n elements to the back
+	for n = q.count - n; n > 0; n-- {
+		q.buf[q.tail] = q.buf[q.head]
+		q.buf[q.head] = nilKType
+		q.head = q.next(q.head)
+		q.tail = q.next(q.tail)
+	}
+}
+
+// at is the position in the buffer of the element at index i.
+func (q *Deque) at(i int) int { return (q.head + i) % len(q.buf) }
+
+func (q *Deque) next(i int) int { return (i + 1) % len(q.buf) }
+func (q *Deque) prev(i int) int { return (i - 1 + len(q.buf)) % len(q.buf) }
+
+// shrink the buffer when it's mostly empty.
+func (q *Deque) shrink() {
+	if len(q.buf) > q.minlen && q.count*4 <= len(q.buf) {
+		q.resize()
+	}
+}
+
+func (q *Deque) resize() {
+	n := q.count * 2
+	if n < q.minlen {
+		n = q.minlen
+	}
+	newBuf := make([]KType, n)
+
+	if q.tail > q.head {
+		copy(newBuf, q.buf[q.head:q.tail])
+	} else {
+		copy(newBuf, q.buf[q.head:len(q.buf)])
+		copy(newBuf[len(q.buf)-q.head:], q.buf[:q.tail])
+	}
+
+	q.head = 0
+	q.tail = q.count % len(newBuf)
+	q.buf = newBuf
+}
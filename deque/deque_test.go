@@ -0,0 +1,133 @@
+package deque
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomKType(r *rand.Rand) KType { return r.Intn(1000) }
+
+func benchKType(r *rand.Rand) KType { return r.Int() }
+
+func TestDequeBothEnds(t *testing.T) {
+	q := NewDeque(0)
+	for i := 0; i < 100; i++ {
+		q.PushBack(i)
+		q.PushFront(-i - 1)
+	}
+	if q.Len() != 200 {
+		t.Fatalf("want len 200, got %d", q.Len())
+	}
+	for i := 0; i < 200; i++ {
+		if want, got := i-100, q.Get(i).(int); want != got {
+			t.Errorf("index %d: want %d, got %d", i, want, got)
+		}
+	}
+	for i := 99; i >= 0; i-- {
+		if got := q.PopBack().(int); got != i {
+			t.Errorf("pop back: want %d, got %d", i, got)
+		}
+		if got := q.PopFront().(int); got != -i-1 {
+			t.Errorf("pop front: want %d, got %d", -i-1, got)
+		}
+	}
+	if q.Len() != 0 {
+		t.Errorf("want len 0, got %d", q.Len())
+	}
+}
+
+func TestDequeSet(t *testing.T) {
+	q := NewDeque(0)
+	for i := 0; i < 20; i++ {
+		q.PushFront(0)
+	}
+	for i := 0; i < 20; i++ {
+		q.Set(i, i)
+	}
+	for i := 0; i < 20; i++ {
+		if got := q.Get(i).(int); got != i {
+			t.Errorf("index %d doesn't contain %d", i, got)
+		}
+	}
+}
+
+func TestDequeInsertRemove(t *testing.T) {
+	q := NewDeque(0)
+	q.Insert(0, 1)
+	q.Insert(0, 0)
+	q.Insert(2, 3)
+	q.Insert(2, 2)
+	for i := 0; i < 4; i++ {
+		if got := q.Get(i).(int); got != i {
+			t.Errorf("index %d: want %d, got %d", i, i, got)
+		}
+	}
+
+	if got := q.Remove(1).(int); got != 1 {
+		t.Errorf("remove 1: got %d", got)
+	}
+	if got := q.Remove(2).(int); got != 3 {
+		t.Errorf("remove 2: got %d", got)
+	}
+	if q.Len() != 2 || q.Get(0).(int) != 0 || q.Get(1).(int) != 2 {
+		t.Errorf("want [0 2], got len %d", q.Len())
+	}
+}
+
+func TestDequeRotate(t *testing.T) {
+	for _, capacity := range []int{0, 5} {
+		q := NewDeque(capacity)
+		// a full buffer of 16, then a half full one
+		n := 16
+		if capacity != 0 {
+			n = 8
+		}
+		for i := 0; i < n; i++ {
+			q.PushBack(i)
+		}
+		for _, rot := range []int{0, 1, -1, 3, -3, n - 1, n, 2*n + 1} {
+			q.Rotate(rot)
+			for i := 0; i < n; i++ {
+				want := ((i-rot)%n + n) % n
+				if got := q.Get(i).(int); got != want {
+					t.Fatalf("rotate %d, index %d: want %d, got %d", rot, i, want, got)
+				}
+			}
+			q.Rotate(-rot)
+		}
+	}
+}
+
+func TestDequeOutOfRangePanics(t *testing.T) {
+	q := NewDeque(0)
+
+	for name, f := range map[string]func(){
+		"peek front": func() { q.PeekFront() },
+		"peek back":  func() { q.PeekBack() },
+		"pop front":  func() { q.PopFront() },
+		"pop back":   func() { q.PopBack() },
+		"get":        func() { q.Get(0) },
+		"set":        func() { q.Set(0, 0) },
+		"insert":     func() { q.Insert(1, 0) },
+		"remove":     func() { q.Remove(0) },
+	} {
+		assertPanics(t, name+" on an empty deque", f)
+	}
+
+	q.PushBack(1)
+	assertPanics(t, "get with a negative index", func() { q.Get(-1) })
+	assertPanics(t, "insert with a negative index", func() { q.Insert(-1, 0) })
+	assertPanics(t, "remove past the end", func() { q.Remove(1) })
+}
+
+func assertPanics(t *testing.T, name string, f func()) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("%s: didn't panic as expected", name)
+		} else {
+			t.Logf("%s: got panic as expected: %v", name, r)
+		}
+	}()
+
+	f()
+}
@@ -0,0 +1,3 @@
+package deque
+
+type KType interface{}
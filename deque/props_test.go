@@ -0,0 +1,214 @@
+package deque
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// The tests of this file are generated along with deques, when asked to. The
+// elements are generated by randomKType.
+
+// checkDeque verifies that the head, the tail and the count of the deque
+// agree, and that the buffer never shrinks below its minimum length.
+func checkDeque(t *testing.T, q *Deque) {
+	if len(q.buf) < q.minlen {
+		t.Fatalf("buffer of %d shrunk below %d", len(q.buf), q.minlen)
+	}
+	if q.count < 0 || q.count > len(q.buf) {
+		t.Fatalf("count %d out of a buffer of %d", q.count, len(q.buf))
+	}
+	if q.head < 0 || q.head >= len(q.buf) {
+		t.Fatalf("head %d out of a buffer of %d", q.head, len(q.buf))
+	}
+	if want := (q.head + q.count) % len(q.buf); q.tail != want {
+		t.Fatalf("head %d and count %d: want tail %d, got %d", q.head, q.count, want, q.tail)
+	}
+	// the slots out of the deque don't hold on to old elements
+	for i := q.count; i < len(q.buf); i++ {
+		if !reflect.DeepEqual(q.buf[q.at(i)], nilKType) {
+			t.Fatalf("slot %d out of the deque holds %v", q.at(i), q.buf[q.at(i)])
+		}
+	}
+}
+
+// checkDequeElems verifies that q holds the elements of ref, in order.
+func checkDequeElems(t *testing.T, q *Deque, ref []KType) {
+	if q.Len() != len(ref) {
+		t.Fatalf("want len %d, got %d", len(ref), q.Len())
+	}
+	for i, want := range ref {
+		if got := q.Get(i); !reflect.DeepEqual(want, got) {
+			t.Fatalf("get %d: want %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestDequeMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	q := NewDeque(0)
+	var ref []KType
+
+	for i := 0; i < 5000; i++ {
+		// favor pushes, then pops, so the buffer grows and shrinks
+		push := 6
+		if i%2000 >= 1000 {
+			push = 4
+		}
+		op := rnd.Intn(10)
+		switch {
+		case op < push:
+			k := randomKType(rnd)
+			switch rnd.Intn(3) {
+			case 0:
+				q.PushBack(k)
+				ref = append(ref, k)
+			case 1:
+				q.PushFront(k)
+				ref = append([]KType{k}, ref...)
+			default:
+				at := rnd.Intn(len(ref) + 1)
+				q.Insert(at, k)
+				ref = append(ref[:at], append([]KType{k}, ref[at:]...)...)
+			}
+		case len(ref) == 0:
+			checkDequeEmpty(t, q)
+		case op < 9:
+			var want, got KType
+			switch rnd.Intn(3) {
+			case 0:
+				want = ref[0]
+				if peek := q.PeekFront(); !reflect.DeepEqual(want, peek) {
+					t.Fatalf("peek front: want %v, got %v", want, peek)
+				}
+				got = q.PopFront()
+				ref = ref[1:]
+			case 1:
+				want = ref[len(ref)-1]
+				if peek := q.PeekBack(); !reflect.DeepEqual(want, peek) {
+					t.Fatalf("peek back: want %v, got %v", want, peek)
+				}
+				if peek, ok := q.TryPeekBack(); !ok || !reflect.DeepEqual(want, peek) {
+					t.Fatalf("try peek back: want %v, true, got %v, %v", want, peek, ok)
+				}
+				got, _ = q.TryPopBack()
+				ref = ref[:len(ref)-1]
+			default:
+				at := rnd.Intn(len(ref))
+				want = ref[at]
+				got = q.Remove(at)
+				ref = append(ref[:at], ref[at+1:]...)
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Fatalf("pop: want %v, got %v", want, got)
+			}
+		default:
+			if rnd.Intn(2) == 0 {
+				at, k := rnd.Intn(len(ref)), randomKType(rnd)
+				q.Set(at, k)
+				ref[at] = k
+			} else {
+				n := rnd.Intn(2*len(ref)+1) - len(ref)
+				q.Rotate(n)
+				m := ((n % len(ref)) + len(ref)) % len(ref)
+				ref = append(append([]KType(nil), ref[len(ref)-m:]...), ref[:len(ref)-m]...)
+			}
+		}
+		checkDeque(t, q)
+		if i%100 == 0 {
+			checkDequeElems(t, q, ref)
+		}
+	}
+	checkDequeElems(t, q, ref)
+}
+
+func TestDequeRotateFull(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	q := NewDeque(16)
+	var ref []KType
+	for i := 0; i < 16; i++ {
+		k := randomKType(rnd)
+		q.PushBack(k)
+		ref = append(ref, k)
+	}
+	for _, n := range []int{1, -1, 5, -7, 16, 31} {
+		q.Rotate(n)
+		m := ((n % 16) + 16) % 16
+		ref = append(append([]KType(nil), ref[16-m:]...), ref[:16-m]...)
+		checkDeque(t, q)
+		checkDequeElems(t, q, ref)
+	}
+}
+
+// checkDequeEmpty verifies that the empty deque q has nothing to peek, pop,
+// get or remove.
+func checkDequeEmpty(t *testing.T, q *Deque) {
+	if q.Len() != 0 {
+		t.Fatalf("want len 0, got %d", q.Len())
+	}
+	if got, ok := q.TryPeekFront(); ok {
+		t.Fatalf("try peek front: want nothing, got %v", got)
+	}
+	if got, ok := q.TryPeekBack(); ok {
+		t.Fatalf("try peek back: want nothing, got %v", got)
+	}
+	if got, ok := q.TryPopFront(); ok {
+		t.Fatalf("try pop front: want nothing, got %v", got)
+	}
+	if got, ok := q.TryPopBack(); ok {
+		t.Fatalf("try pop back: want nothing, got %v", got)
+	}
+	q.Rotate(1)
+	for _, op := range []struct {
+		name string
+		f    func()
+	}{
+		{"peek front", func() { q.PeekFront() }},
+		{"peek back", func() { q.PeekBack() }},
+		{"pop front", func() { q.PopFront() }},
+		{"pop back", func() { q.PopBack() }},
+		{"get", func() { q.Get(0) }},
+		{"set", func() { q.Set(0, nilKType) }},
+		{"remove", func() { q.Remove(0) }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s: want a panic on an empty deque", op.name)
+				}
+			}()
+			op.f()
+		}()
+	}
+	checkDeque(t, q)
+}
+
+func TestDequeEmpty(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	q := NewDeque(0)
+	checkDequeEmpty(t, q)
+
+	k := randomKType(rnd)
+	q.PushFront(k)
+	if got, ok := q.TryPopBack(); !ok || !reflect.DeepEqual(k, got) {
+		t.Fatalf("try pop back: want %v, true, got %v, %v", k, got, ok)
+	}
+	checkDequeEmpty(t, q)
+	q.PushBack(k)
+	if got, ok := q.TryPeekFront(); !ok || !reflect.DeepEqual(k, got) {
+		t.Fatalf("try peek front: want %v, true, got %v, %v", k, got, ok)
+	}
+	if got, ok := q.TryPopFront(); !ok || !reflect.DeepEqual(k, got) {
+		t.Fatalf("try pop front: want %v, true, got %v, %v", k, got, ok)
+	}
+	checkDequeEmpty(t, q)
+
+	// empty after growing, wrapping around and shrinking
+	for i := 0; i < 100; i++ {
+		q.PushFront(randomKType(rnd))
+	}
+	for q.Len() > 0 {
+		q.Remove(q.Len() / 2)
+	}
+	checkDequeEmpty(t, q)
+}
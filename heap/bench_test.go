@@ -0,0 +1,159 @@
+package heap
+
+import (
+	stdheap "container/heap"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// The benchmarks of this file are generated along with heaps, when asked
+// to. The keys are generated by benchKType.
+
+// benchHeapSizes are the numbers of keys in the benchmarked heaps.
+var benchHeapSizes = []int{100, 10000, 1000000}
+
+// benchHeap runs bench for each size, with that many random keys. The keys
+// are the same from one benchmark to the other.
+func benchHeap(b *testing.B, bench func(b *testing.B, keys []KType)) {
+	for _, n := range benchHeapSizes {
+		n := n
+		var keys []KType
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			// once for all the runs of the benchmark, and only if it's run
+			if keys == nil {
+				rnd := rand.New(rand.NewSource(int64(n)))
+				keys = make([]KType, n)
+				for i := range keys {
+					keys[i] = benchKType(rnd)
+				}
+			}
+			b.ResetTimer()
+			bench(b, keys)
+		})
+	}
+}
+
+func BenchmarkHeapNew(b *testing.B) {
+	benchHeap(b, func(b *testing.B, keys []KType) {
+		for i := 0; i < b.N; i++ {
+			NewHeap(keys...)
+		}
+	})
+}
+
+func BenchmarkHeapPush(b *testing.B) {
+	benchHeap(b, func(b *testing.B, keys []KType) {
+		n := len(keys)
+		h := NewHeap(keys...)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h.Push(keys[i%n])
+			if h.n == 2*n {
+				// the first n keys of a heap are a heap
+				h.pq = h.pq[:n+1]
+				h.n = n
+			}
+		}
+	})
+}
+
+func BenchmarkHeapPeek(b *testing.B) {
+	benchHeap(b, func(b *testing.B, keys []KType) {
+		h := NewHeap(keys...)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h.Peek()
+		}
+	})
+}
+
+func BenchmarkHeapPop(b *testing.B) {
+	benchHeap(b, func(b *testing.B, keys []KType) {
+		h := NewHeap(keys...)
+		full := append([]KType(nil), h.pq...)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h.Pop()
+			if h.n == 0 {
+				h.pq = append(h.pq[:0], full...)
+				h.n = len(keys)
+			}
+		}
+	})
+}
+
+func BenchmarkHeapRemove(b *testing.B) {
+	benchHeap(b, func(b *testing.B, keys []KType) {
+		n := len(keys)
+		h := NewHeap(keys...)
+		full := append([]KType(nil), h.pq...)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h.Remove(keys[i%n])
+			if h.n == 0 {
+				h.pq = append(h.pq[:0], full...)
+				h.n = n
+			}
+		}
+	})
+}
+
+func BenchmarkHeapFix(b *testing.B) {
+	benchHeap(b, func(b *testing.B, keys []KType) {
+		h := NewHeap(keys...)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h.Fix()
+		}
+	})
+}
+
+// BenchmarkHeapPushPop is to be compared with
+// BenchmarkHeapPushPopContainer.
+func BenchmarkHeapPushPop(b *testing.B) {
+	benchHeap(b, func(b *testing.B, keys []KType) {
+		n := len(keys)
+		h := NewHeap(keys...)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h.Push(keys[i%n])
+			h.Pop()
+		}
+	})
+}
+
+// containerHeap is a container/heap holding the keys as interface{},
+// ordered like Heap.
+type containerHeap struct {
+	h    *Heap
+	keys []interface{}
+}
+
+func (c *containerHeap) Len() int { return len(c.keys) }
+func (c *containerHeap) Less(i, j int) bool {
+	return c.h.before(c.keys[i].(KType), c.keys[j].(KType))
+}
+func (c *containerHeap) Swap(i, j int)      { c.keys[i], c.keys[j] = c.keys[j], c.keys[i] }
+func (c *containerHeap) Push(x interface{}) { c.keys = append(c.keys, x) }
+func (c *containerHeap) Pop() interface{} {
+	x := c.keys[len(c.keys)-1]
+	c.keys = c.keys[:len(c.keys)-1]
+	return x
+}
+
+func BenchmarkHeapPushPopContainer(b *testing.B) {
+	benchHeap(b, func(b *testing.B, keys []KType) {
+		n := len(keys)
+		c := &containerHeap{h: NewHeap()}
+		for _, k := range keys {
+			c.keys = append(c.keys, k)
+		}
+		stdheap.Init(c)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			stdheap.Push(c, keys[i%n])
+			stdheap.Pop(c)
+		}
+	})
+}
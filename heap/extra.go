@@ -1,6 +1,6 @@
 // Package heap provides a heap container for KType. A heap is a tree
-// with the property that each node is the minimum-valued node in its
-// subtree.
+// with the property that each node is the maximum-valued node in its
+// subtree (the minimum-valued, in a min-heap).
 //
 // Heaps are useful when one needs to always retrieve the largest or
 // smallest value from a set of values. A common example is in priority
@@ -10,3 +10,6 @@ package heap
 type KType interface {
 	Compare(other KType) int
 }
+
+// IDType identifies the keys of an IndexedHeap.
+type IDType interface{}
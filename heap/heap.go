@@ -10,7 +10,10 @@ package heap
 
 func (h Heap) compare(a, b KType) int { return a.Compare(b) }
 
-// Heap is a container of KType, where the elements can be efficiently
+// before tells if a comes out of the heap before b: this is a max-heap.
+func (h Heap) before(a, b KType) bool { return h.compare(a, b) > 0 }
+
+// Heap is a max-heap of KType, where the elements can be efficiently
 // retrieved in their decreasing order (according to their comparison
 // rules).
 type Heap struct {
@@ -33,8 +36,22 @@ func NewHeap(keys ...KType) *Heap {
 func (h *Heap) Len() int { return h.n }
 
 // Peek at the largest element (according to their comparison rules), without
-// removing it from the heap.
-func (h *Heap) Peek() KType { return h.pq[1] }
+// removing it from the heap. This call panics if the heap is empty.
+func (h *Heap) Peek() KType {
+	if h.n == 0 {
+		panic("heap: empty heap")
+	}
+	return h.pq[1]
+}
+
+// TryPeek is like Peek, but tells if there was an element instead of
+// panicking on an empty heap.
+func (h *Heap) TryPeek() (k KType, ok bool) {
+	if h.n == 0 {
+		return k, false
+	}
+	return h.pq[1], true
+}
 
 // Fix re-establishes the heap ordering. This is useful if elements
 // of the heap have had their comparison value changed. It is equivalent to,
@@ -56,8 +73,12 @@ func (h *Heap) Push(k KType) {
 }
 
 // Pop removes the largest element (according to their comparison rules) from
-// the heap and returns it. The complexity is O(log(n)) where n == h.Len().
+// the heap and returns it. This call panics if the heap is empty. The
+// complexity is O(log(n)) where n == h.Len().
 func (h *Heap) Pop() KType {
+	if h.n == 0 {
+		panic("heap: empty heap")
+	}
 	val := h.pq[1]
 	h.swap(1, h.n)
 	h.pq = h.pq[:h.n]
@@ -67,30 +88,44 @@ func (h *Heap) Pop() KType {
 	return val
 }
 
+// TryPop is like Pop, but tells if there was an element instead of panicking
+// on an empty heap.
+func (h *Heap) TryPop() (k KType, ok bool) {
+	if h.n == 0 {
+		return k, false
+	}
+	return h.Pop(), true
+}
+
 // Remove removes k from the heap, if it exists. Equality is defined by
 // Compare == 0.
 // The complexity is O(n+log(n)) where n == h.Len().
 func (h *Heap) Remove(k KType) bool {
-
-	cmp := h.compare(h.pq[1], k)
-	if cmp == 0 {
+	if h.n == 0 {
+		return false
+	}
+	if h.compare(h.pq[1], k) == 0 {
 		_ = h.Pop()
 		return true
 	}
-	if cmp < 0 {
+	if h.before(k, h.pq[1]) {
 		// larger than largest, don't try to find it
 		return false
 	}
 
-	i := 0
-	for _, j := range h.pq[1:] {
-		i++
-		if h.compare(j, k) != 0 {
+	for i := 2; i <= h.n; i++ {
+		if h.compare(h.pq[i], k) != 0 {
 			continue
 		}
-		h.swap(i, 1)
-		h.sink(i, h.n)
-		_ = h.Pop()
+		// replace k by the last element, which can then be smaller or
+		// larger than its new parent and children
+		h.swap(i, h.n)
+		h.pq = h.pq[:h.n]
+		h.n--
+		if i <= h.n {
+			h.sink(i, h.n)
+			h.swim(i)
+		}
 		return true
 	}
 	// not in the heap
@@ -98,7 +133,7 @@ func (h *Heap) Remove(k KType) bool {
 }
 
 func (h *Heap) swap(i, j int)      { h.pq[i], h.pq[j] = h.pq[j], h.pq[i] }
-func (h *Heap) less(i, j int) bool { return h.compare(h.pq[i], h.pq[j]) < 0 }
+func (h *Heap) less(i, j int) bool { return h.before(h.pq[j], h.pq[i]) }
 
 func (h *Heap) swim(k int) {
 	for k > 1 && h.less(k/2, k) {
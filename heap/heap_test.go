@@ -9,6 +9,14 @@ type Int int
 
 func (i Int) Compare(other KType) int { return int(i - other.(Int)) }
 
+func randomKType(r *rand.Rand) KType { return Int(r.Intn(100)) }
+
+func benchKType(r *rand.Rand) KType { return Int(r.Int31()) }
+
+func randomIDType(r *rand.Rand) IDType { return r.Intn(100) }
+
+func benchIDType(r *rand.Rand) IDType { return r.Int() }
+
 // Adapted from `container/heap`.
 
 // Copyright 2009 The Go Authors. All rights reserved.
@@ -169,6 +177,26 @@ func TestHeapRemoveNotThere(t *testing.T) {
 	}
 }
 
+// Removing a key below the top used to swap the top in its place, where it
+// was stuck under smaller keys.
+func TestHeapRemoveKeepsOrder(t *testing.T) {
+	h := NewHeap()
+	for i := 0; i < 20; i++ {
+		h.Push(Int(i))
+	}
+	if !h.Remove(Int(5)) {
+		t.Fatalf("should have removed %d", 5)
+	}
+	for i := 19; i >= 0; i-- {
+		if i == 5 {
+			continue
+		}
+		if x := h.Pop(); x != Int(i) {
+			t.Fatalf("pop got %v; want %v", x, i)
+		}
+	}
+}
+
 func BenchmarkDup(b *testing.B) {
 	const n = 10000
 	buf := make([]KType, 0, n)
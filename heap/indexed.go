@@ -0,0 +1,196 @@
+package heap
+
+// The implementation is the one of the heaps, keeping track of where each id
+// is in the heap so that it can be found without scanning the heap.
+
+func (h IndexedHeap) compare(a, b KType) int { return a.Compare(b) }
+
+// before tells if a comes out of the heap before b: this is a max-heap.
+func (h IndexedHeap) before(a, b KType) bool { return h.compare(a, b) > 0 }
+
+// IndexedHeap is a max-heap of IDType ids, each with a KType key, where the
+// ids can be efficiently retrieved in the decreasing order of their keys
+// (according to their comparison rules). The key of an id can be updated,
+// and the id removed, without looking for it in the heap.
+type IndexedHeap struct {
+	n  int
+	pq []indexedEntry
+	// pos is the index of each id in pq
+	pos map[IDType]int
+}
+
+// indexedEntry is an id of the heap, with its key.
+type indexedEntry struct {
+	id  IDType
+	key KType
+}
+
+// NewIndexedHeap creates an empty indexed heap.
+func NewIndexedHeap() *IndexedHeap {
+	return &IndexedHeap{
+		pq:  make([]indexedEntry, 1),
+		pos: make(map[IDType]int),
+	}
+}
+
+// Len is the number of ids stored in the heap.
+func (h *IndexedHeap) Len() int { return h.n }
+
+// Contains tells if id is in the heap.
+func (h *IndexedHeap) Contains(id IDType) bool {
+	_, ok := h.pos[id]
+	return ok
+}
+
+// Key returns the key of id, if id is in the heap.
+func (h *IndexedHeap) Key(id IDType) (k KType, ok bool) {
+	i, ok := h.pos[id]
+	if !ok {
+		return k, false
+	}
+	return h.pq[i].key, true
+}
+
+// Peek at the id with the largest key (according to their comparison rules),
+// without removing it from the heap. This call panics if the heap is empty.
+func (h *IndexedHeap) Peek() (IDType, KType) {
+	if h.n == 0 {
+		panic("heap: empty heap")
+	}
+	return h.pq[1].id, h.pq[1].key
+}
+
+// TryPeek is like Peek, but tells if there was an id instead of panicking on
+// an empty heap.
+func (h *IndexedHeap) TryPeek() (id IDType, k KType, ok bool) {
+	if h.n == 0 {
+		return id, k, false
+	}
+	return h.pq[1].id, h.pq[1].key, true
+}
+
+// Push pushes id onto the heap, with key k. If id is already in the heap, its
+// key is updated to k instead. The complexity is O(log(n)) where
+// n == h.Len().
+func (h *IndexedHeap) Push(id IDType, k KType) {
+	if h.Update(id, k) {
+		return
+	}
+	h.n++
+	h.pq = append(h.pq, indexedEntry{id: id, key: k})
+	h.pos[id] = h.n
+	h.swim(h.n)
+}
+
+// Pop removes the id with the largest key (according to their comparison
+// rules) from the heap and returns it, with its key. This call panics if the
+// heap is empty. The complexity is O(log(n)) where n == h.Len().
+func (h *IndexedHeap) Pop() (IDType, KType) {
+	if h.n == 0 {
+		panic("heap: empty heap")
+	}
+	e := h.pq[1]
+	h.remove(1)
+	return e.id, e.key
+}
+
+// TryPop is like Pop, but tells if there was an id instead of panicking on an
+// empty heap.
+func (h *IndexedHeap) TryPop() (id IDType, k KType, ok bool) {
+	if h.n == 0 {
+		return id, k, false
+	}
+	id, k = h.Pop()
+	return id, k, true
+}
+
+// Update changes the key of id to k, if id is in the heap. Whether the key is
+// increased or decreased, the id moves up or down the heap accordingly.
+// The complexity is O(log(n)) where n == h.Len().
+func (h *IndexedHeap) Update(id IDType, k KType) bool {
+	i, ok := h.pos[id]
+	if !ok {
+		return false
+	}
+	h.pq[i].key = k
+	h.sink(i, h.n)
+	h.swim(i)
+	return true
+}
+
+// DecreaseKey changes the key of id to k, if id is in the heap and k isn't
+// above its key (according to their comparison rules). It's Update
+// for callers that only ever lower keys, as Dijkstra's shortest paths do.
+// The complexity is O(log(n)) where n == h.Len().
+func (h *IndexedHeap) DecreaseKey(id IDType, k KType) bool {
+	if old, ok := h.Key(id); !ok || h.compare(k, old) > 0 {
+		return false
+	}
+	return h.Update(id, k)
+}
+
+// IncreaseKey changes the key of id to k, if id is in the heap and k isn't
+// below its key (according to their comparison rules). It's Update
+// for callers that only ever raise keys.
+// The complexity is O(log(n)) where n == h.Len().
+func (h *IndexedHeap) IncreaseKey(id IDType, k KType) bool {
+	if old, ok := h.Key(id); !ok || h.compare(k, old) < 0 {
+		return false
+	}
+	return h.Update(id, k)
+}
+
+// Remove removes id from the heap, if it exists, and returns its key.
+// The complexity is O(log(n)) where n == h.Len().
+func (h *IndexedHeap) Remove(id IDType) (k KType, ok bool) {
+	i, ok := h.pos[id]
+	if !ok {
+		return k, false
+	}
+	k = h.pq[i].key
+	h.remove(i)
+	return k, true
+}
+
+// remove the entry at i, replacing it by the last entry, which can then be
+// smaller or larger than its new parent and children.
+func (h *IndexedHeap) remove(i int) {
+	delete(h.pos, h.pq[i].id)
+	h.pq[i] = h.pq[h.n]
+	h.pq = h.pq[:h.n]
+	h.n--
+	if i <= h.n {
+		h.pos[h.pq[i].id] = i
+		h.sink(i, h.n)
+		h.swim(i)
+	}
+}
+
+func (h *IndexedHeap) swap(i, j int) {
+	h.pq[i], h.pq[j] = h.pq[j], h.pq[i]
+	h.pos[h.pq[i].id] = i
+	h.pos[h.pq[j].id] = j
+}
+
+func (h *IndexedHeap) less(i, j int) bool { return h.before(h.pq[j].key, h.pq[i].key) }
+
+func (h *IndexedHeap) swim(k int) {
+	for k > 1 && h.less(k/2, k) {
+		h.swap(k/2, k)
+		k = k / 2
+	}
+}
+
+func (h *IndexedHeap) sink(k, n int) {
+	for k*2 <= n {
+		j := 2 * k
+		if j < n && h.less(j, j+1) {
+			j++
+		}
+		if !h.less(k, j) {
+			break
+		}
+		h.swap(k, j)
+		k = j
+	}
+}
@@ -0,0 +1,118 @@
+package heap
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// The benchmarks of this file are generated along with indexed heaps, when
+// asked to. The keys are generated by benchKType, the ids by benchIDType.
+
+// benchIndexedHeapSizes are the numbers of ids in the benchmarked heaps.
+var benchIndexedHeapSizes = []int{100, 10000, 1000000}
+
+// benchIndexedHeap runs bench for each size, with a heap holding that many
+// random ids, and the ids and keys pushed in it. The ids are the same from
+// one benchmark to the other.
+func benchIndexedHeap(b *testing.B, bench func(b *testing.B, h *IndexedHeap, ids []IDType, keys []KType)) {
+	for _, n := range benchIndexedHeapSizes {
+		n := n
+		var h *IndexedHeap
+		var ids []IDType
+		var keys []KType
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			// once for all the runs of the benchmark, and only if it's run
+			if h == nil {
+				rnd := rand.New(rand.NewSource(int64(n)))
+				ids = make([]IDType, n)
+				keys = make([]KType, n)
+				for i := range ids {
+					ids[i], keys[i] = benchIDType(rnd), benchKType(rnd)
+				}
+				h = NewIndexedHeap()
+				fillIndexedHeap(h, ids, keys)
+			}
+			bench(b, h, ids, keys)
+		})
+	}
+}
+
+func fillIndexedHeap(h *IndexedHeap, ids []IDType, keys []KType) {
+	for i, id := range ids {
+		h.Push(id, keys[i])
+	}
+}
+
+// benchIndexedHeapRefill runs op b.N times, pushing the ids back in h every
+// n times. The refill isn't timed.
+func benchIndexedHeapRefill(b *testing.B, h *IndexedHeap, ids []IDType, keys []KType, op func(i int)) {
+	n := len(ids)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		op(i % n)
+		if i%n == n-1 {
+			b.StopTimer()
+			fillIndexedHeap(h, ids, keys)
+			b.StartTimer()
+		}
+	}
+	b.StopTimer()
+	fillIndexedHeap(h, ids, keys)
+}
+
+func BenchmarkIndexedHeapPush(b *testing.B) {
+	benchIndexedHeap(b, func(b *testing.B, h *IndexedHeap, ids []IDType, keys []KType) {
+		n := len(ids)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// push the ids up to n, over and over
+			if i%n == 0 {
+				b.StopTimer()
+				for h.Len() > 0 {
+					h.Pop()
+				}
+				b.StartTimer()
+			}
+			h.Push(ids[i%n], keys[i%n])
+		}
+		b.StopTimer()
+		fillIndexedHeap(h, ids, keys)
+	})
+}
+
+func BenchmarkIndexedHeapPop(b *testing.B) {
+	benchIndexedHeap(b, func(b *testing.B, h *IndexedHeap, ids []IDType, keys []KType) {
+		benchIndexedHeapRefill(b, h, ids, keys, func(int) {
+			if h.Len() > 0 {
+				h.Pop()
+			}
+		})
+	})
+}
+
+func BenchmarkIndexedHeapUpdate(b *testing.B) {
+	benchIndexedHeap(b, func(b *testing.B, h *IndexedHeap, ids []IDType, keys []KType) {
+		n := len(ids)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// the keys of the others, so that the ids move around
+			h.Update(ids[i%n], keys[(i+1)%n])
+		}
+	})
+}
+
+func BenchmarkIndexedHeapRemove(b *testing.B) {
+	benchIndexedHeap(b, func(b *testing.B, h *IndexedHeap, ids []IDType, keys []KType) {
+		benchIndexedHeapRefill(b, h, ids, keys, func(i int) { h.Remove(ids[i]) })
+	})
+}
+
+func BenchmarkIndexedHeapContains(b *testing.B) {
+	benchIndexedHeap(b, func(b *testing.B, h *IndexedHeap, ids []IDType, keys []KType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h.Contains(ids[i%len(ids)])
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package heap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// The tests of this file are generated along with indexed heaps, when asked
+// to. The keys are generated by randomKType, the ids by randomIDType.
+
+// checkIndexedHeap verifies that no id of the heap comes out before its
+// parent, and that the position of each id is where it is in the heap.
+func checkIndexedHeap(t *testing.T, h *IndexedHeap) {
+	if len(h.pq) != h.n+1 || len(h.pos) != h.n {
+		t.Fatalf("want %d ids, got %d in the heap and %d positions", h.n, len(h.pq)-1, len(h.pos))
+	}
+	for i := 1; i <= h.n; i++ {
+		if pos, ok := h.pos[h.pq[i].id]; !ok || pos != i {
+			t.Fatalf("%v is at %d, but its position is %d", h.pq[i].id, i, pos)
+		}
+		if i > 1 && h.before(h.pq[i].key, h.pq[i/2].key) {
+			t.Fatalf("heap order violated: %v at %d comes out before its parent %v at %d",
+				h.pq[i].key, i, h.pq[i/2].key, i/2)
+		}
+	}
+}
+
+// refIndexedHeap is a naive indexed heap keeping its ids in a map, ordered
+// like h. The ids are also listed in the order they were pushed, for the
+// tests to pick them the same way from one run to the other.
+type refIndexedHeap struct {
+	h    *IndexedHeap
+	keys map[IDType]KType
+	ids  []IDType
+}
+
+func (r *refIndexedHeap) push(id IDType, k KType) {
+	if _, ok := r.keys[id]; !ok {
+		r.ids = append(r.ids, id)
+	}
+	r.keys[id] = k
+}
+
+func (r *refIndexedHeap) remove(id IDType) {
+	if _, ok := r.keys[id]; !ok {
+		return
+	}
+	delete(r.keys, id)
+	for i, rid := range r.ids {
+		if rid == id {
+			r.ids = append(r.ids[:i], r.ids[i+1:]...)
+			break
+		}
+	}
+}
+
+// top is a key coming out first.
+func (r *refIndexedHeap) top() (top KType) {
+	first := true
+	for _, k := range r.keys {
+		if first || r.h.before(k, top) {
+			top, first = k, false
+		}
+	}
+	return top
+}
+
+// anyID returns one of the ids most of the time, or a random one.
+func (r *refIndexedHeap) anyID(rnd *rand.Rand) IDType {
+	if len(r.ids) == 0 || rnd.Intn(4) == 0 {
+		return randomIDType(rnd)
+	}
+	return r.ids[rnd.Intn(len(r.ids))]
+}
+
+func TestIndexedHeapMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	h := NewIndexedHeap()
+	ref := &refIndexedHeap{h: h, keys: make(map[IDType]KType)}
+
+	for i := 0; i < 5000; i++ {
+		switch op := rnd.Intn(10); {
+		case op < 4:
+			id, k := randomIDType(rnd), randomKType(rnd)
+			h.Push(id, k)
+			ref.push(id, k)
+		case op < 6:
+			if h.Len() == 0 {
+				checkIndexedHeapEmpty(t, h)
+				continue
+			}
+			want := ref.top()
+			if _, got := h.Peek(); h.compare(want, got) != 0 {
+				t.Fatalf("peek: want %v, got %v", want, got)
+			}
+			if _, got, ok := h.TryPeek(); !ok || h.compare(want, got) != 0 {
+				t.Fatalf("try peek: want %v, true, got %v, %v", want, got, ok)
+			}
+			var id IDType
+			var got KType
+			ok := true
+			if op < 5 {
+				id, got = h.Pop()
+			} else {
+				id, got, ok = h.TryPop()
+			}
+			if !ok || h.compare(want, got) != 0 || h.compare(ref.keys[id], got) != 0 {
+				t.Fatalf("pop: want %v, got %v for %v, which had %v", want, got, id, ref.keys[id])
+			}
+			ref.remove(id)
+		case op < 8:
+			id, k := ref.anyID(rnd), randomKType(rnd)
+			old, want := ref.keys[id]
+			var got bool
+			switch rnd.Intn(3) {
+			case 0:
+				got = h.Update(id, k)
+			case 1:
+				want = want && h.compare(k, old) <= 0
+				got = h.DecreaseKey(id, k)
+			default:
+				want = want && h.compare(k, old) >= 0
+				got = h.IncreaseKey(id, k)
+			}
+			if want != got {
+				t.Fatalf("update %v from %v to %v: want %v, got %v", id, old, k, want, got)
+			}
+			if want {
+				ref.keys[id] = k
+			}
+		case op < 9:
+			id := ref.anyID(rnd)
+			want, wantOK := ref.keys[id]
+			got, ok := h.Remove(id)
+			if wantOK != ok || ok && h.compare(want, got) != 0 {
+				t.Fatalf("remove %v: want %v, %v, got %v, %v", id, want, wantOK, got, ok)
+			}
+			ref.remove(id)
+		default:
+			id := ref.anyID(rnd)
+			want, wantOK := ref.keys[id]
+			if got := h.Contains(id); wantOK != got {
+				t.Fatalf("contains %v: want %v, got %v", id, wantOK, got)
+			}
+			got, ok := h.Key(id)
+			if wantOK != ok || ok && h.compare(want, got) != 0 {
+				t.Fatalf("key %v: want %v, %v, got %v, %v", id, want, wantOK, got, ok)
+			}
+		}
+		if want, got := len(ref.keys), h.Len(); want != got {
+			t.Fatalf("want len %d, got %d", want, got)
+		}
+		checkIndexedHeap(t, h)
+	}
+}
+
+// checkIndexedHeapEmpty verifies that the empty heap h has nothing to peek,
+// pop or remove.
+func checkIndexedHeapEmpty(t *testing.T, h *IndexedHeap) {
+	if h.Len() != 0 {
+		t.Fatalf("want len 0, got %d", h.Len())
+	}
+	if id, k, ok := h.TryPeek(); ok {
+		t.Fatalf("try peek: want nothing, got %v with %v", id, k)
+	}
+	if id, k, ok := h.TryPop(); ok {
+		t.Fatalf("try pop: want nothing, got %v with %v", id, k)
+	}
+	for _, op := range []struct {
+		name string
+		f    func()
+	}{
+		{"peek", func() { h.Peek() }},
+		{"pop", func() { h.Pop() }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s: want a panic on an empty heap", op.name)
+				}
+			}()
+			op.f()
+		}()
+	}
+	checkIndexedHeap(t, h)
+}
+
+func TestIndexedHeapEmpty(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	h := NewIndexedHeap()
+	checkIndexedHeapEmpty(t, h)
+
+	id, k := randomIDType(rnd), randomKType(rnd)
+	h.Push(id, k)
+	if gotID, got, ok := h.TryPop(); !ok || gotID != id || h.compare(k, got) != 0 {
+		t.Fatalf("try pop: want %v with %v, true, got %v with %v, %v", id, k, gotID, got, ok)
+	}
+	checkIndexedHeapEmpty(t, h)
+	if _, ok := h.Remove(id); ok {
+		t.Fatalf("remove %v: want not found", id)
+	}
+	if h.Update(id, k) {
+		t.Fatalf("update %v: want not found", id)
+	}
+	checkIndexedHeapEmpty(t, h)
+}
@@ -0,0 +1,153 @@
+package heap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func verifyIndexed(t *testing.T, h *IndexedHeap) {
+	if len(h.pos) != h.n {
+		t.Fatalf("want %d positions, got %d", h.n, len(h.pos))
+	}
+	for i := 1; i <= h.n; i++ {
+		if pos := h.pos[h.pq[i].id]; pos != i {
+			t.Fatalf("id %v is at %d, its position is %d", h.pq[i].id, i, pos)
+		}
+		if i > 1 && h.less(i/2, i) {
+			t.Fatalf("heap invariant invalidated [%d] = %v < [%d] = %v", i/2, h.pq[i/2].key, i, h.pq[i].key)
+		}
+	}
+}
+
+func TestIndexedHeapPopsInOrder(t *testing.T) {
+	h := NewIndexedHeap()
+	for _, i := range rand.Perm(20) {
+		h.Push(i, Int(i))
+		verifyIndexed(t, h)
+	}
+	for i := 19; i >= 0; i-- {
+		if want, id := i, h.Len()-1; id != want {
+			t.Fatalf("want len %d, got %d", want+1, h.Len())
+		}
+		id, k := h.Pop()
+		verifyIndexed(t, h)
+		if id != i || k != Int(i) {
+			t.Errorf("pop got %v:%v; want %v:%v", id, k, i, i)
+		}
+		if h.Contains(id) {
+			t.Errorf("popped %v is still contained", id)
+		}
+	}
+}
+
+func TestIndexedHeapPushUpdates(t *testing.T) {
+	h := NewIndexedHeap()
+	h.Push("a", Int(1))
+	h.Push("b", Int(2))
+	h.Push("a", Int(3))
+	verifyIndexed(t, h)
+
+	if h.Len() != 2 {
+		t.Fatalf("want len 2, got %d", h.Len())
+	}
+	if id, k := h.Peek(); id != "a" || k != Int(3) {
+		t.Errorf("peek got %v:%v; want a:3", id, k)
+	}
+}
+
+func TestIndexedHeapUpdate(t *testing.T) {
+	h := NewIndexedHeap()
+	for i := 0; i < 20; i++ {
+		h.Push(i, Int(i))
+	}
+
+	// increase the key of the bottom, decrease the one of the top
+	if !h.Update(0, Int(100)) {
+		t.Fatal("should have updated 0")
+	}
+	verifyIndexed(t, h)
+	if id, _ := h.Peek(); id != 0 {
+		t.Errorf("peek got %v; want 0", id)
+	}
+	if !h.Update(0, Int(-1)) {
+		t.Fatal("should have updated 0")
+	}
+	verifyIndexed(t, h)
+	if id, _ := h.Peek(); id != 19 {
+		t.Errorf("peek got %v; want 19", id)
+	}
+	if k, ok := h.Key(0); !ok || k != Int(-1) {
+		t.Errorf("key of 0: got %v, %v; want -1, true", k, ok)
+	}
+
+	if h.Update(20, Int(0)) {
+		t.Error("should not have updated 20")
+	}
+	if h.Contains(20) {
+		t.Error("update should not have added 20")
+	}
+}
+
+func TestIndexedHeapDecreaseIncreaseKey(t *testing.T) {
+	h := NewIndexedHeap()
+	for i := 0; i < 20; i++ {
+		h.Push(i, Int(i))
+	}
+
+	if h.DecreaseKey(10, Int(11)) {
+		t.Error("should not have increased the key of 10")
+	}
+	if h.IncreaseKey(10, Int(9)) {
+		t.Error("should not have decreased the key of 10")
+	}
+	if k, _ := h.Key(10); k != Int(10) {
+		t.Errorf("key of 10: got %v; want 10", k)
+	}
+
+	if !h.IncreaseKey(0, Int(100)) {
+		t.Fatal("should have increased the key of 0")
+	}
+	verifyIndexed(t, h)
+	if id, _ := h.Peek(); id != 0 {
+		t.Errorf("peek got %v; want 0", id)
+	}
+	if !h.DecreaseKey(0, Int(-1)) {
+		t.Fatal("should have decreased the key of 0")
+	}
+	verifyIndexed(t, h)
+	if id, _ := h.Peek(); id != 19 {
+		t.Errorf("peek got %v; want 19", id)
+	}
+
+	if h.DecreaseKey(20, Int(0)) || h.IncreaseKey(20, Int(0)) {
+		t.Error("should not have updated 20")
+	}
+}
+
+func TestIndexedHeapRemove(t *testing.T) {
+	h := NewIndexedHeap()
+	for i := 0; i < 40; i++ {
+		h.Push(i, Int(i))
+	}
+
+	for _, n := range rand.Perm(10) {
+		i := n + 10
+		k, ok := h.Remove(i)
+		if !ok || k != Int(i) {
+			t.Errorf("remove %d: got %v, %v; want %d, true", i, k, ok, i)
+		}
+		verifyIndexed(t, h)
+		if h.Contains(i) {
+			t.Errorf("removed %d is still contained", i)
+		}
+	}
+	if _, ok := h.Remove(10); ok {
+		t.Error("should not have removed 10 twice")
+	}
+	if _, ok := h.Key(10); ok {
+		t.Error("removed 10 should have no key")
+	}
+	if h.Len() != 30 {
+		t.Errorf("want len 30, got %d", h.Len())
+	}
+}
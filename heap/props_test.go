@@ -0,0 +1,176 @@
+package heap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// The tests of this file are generated along with heaps, when asked to. The
+// keys are generated by randomKType.
+
+// checkHeap verifies that no key of the heap comes out before its parent.
+func checkHeap(t *testing.T, h *Heap) {
+	if len(h.pq) != h.n+1 {
+		t.Fatalf("want %d keys, got %d", h.n, len(h.pq)-1)
+	}
+	for k := 2; k <= h.n; k++ {
+		if h.before(h.pq[k], h.pq[k/2]) {
+			t.Fatalf("heap order violated: %v at %d comes out before its parent %v at %d",
+				h.pq[k], k, h.pq[k/2], k/2)
+		}
+	}
+}
+
+// refHeap is a naive heap keeping its keys in a slice, ordered like h.
+type refHeap struct {
+	h    *Heap
+	keys []KType
+}
+
+func (r *refHeap) push(k KType) { r.keys = append(r.keys, k) }
+
+// top is the index of the key coming out first.
+func (r *refHeap) top() int {
+	top := 0
+	for i, k := range r.keys {
+		if r.h.before(k, r.keys[top]) {
+			top = i
+		}
+	}
+	return top
+}
+
+func (r *refHeap) remove(i int) KType {
+	k := r.keys[i]
+	r.keys = append(r.keys[:i], r.keys[i+1:]...)
+	return k
+}
+
+func TestHeapMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	h := NewHeap()
+	ref := &refHeap{h: h}
+
+	for i := 0; i < 5000; i++ {
+		switch op := rnd.Intn(10); {
+		case op < 5:
+			k := randomKType(rnd)
+			h.Push(k)
+			ref.push(k)
+		case op < 8:
+			if h.Len() == 0 {
+				checkHeapEmpty(t, h, randomKType(rnd))
+				continue
+			}
+			if want, got := ref.keys[ref.top()], h.Peek(); h.compare(want, got) != 0 {
+				t.Fatalf("peek: want %v, got %v", want, got)
+			}
+			if got, ok := h.TryPeek(); !ok || h.compare(ref.keys[ref.top()], got) != 0 {
+				t.Fatalf("try peek: want %v, true, got %v, %v", ref.keys[ref.top()], got, ok)
+			}
+			want := ref.remove(ref.top())
+			if op < 7 {
+				if got := h.Pop(); h.compare(want, got) != 0 {
+					t.Fatalf("pop: want %v, got %v", want, got)
+				}
+			} else if got, ok := h.TryPop(); !ok || h.compare(want, got) != 0 {
+				t.Fatalf("try pop: want %v, true, got %v, %v", want, got, ok)
+			}
+		default:
+			if h.Len() == 0 {
+				continue
+			}
+			k := ref.keys[rnd.Intn(len(ref.keys))]
+			if !h.Remove(k) {
+				t.Fatalf("remove %v: want found", k)
+			}
+			for j, rk := range ref.keys {
+				if h.compare(rk, k) == 0 {
+					ref.remove(j)
+					break
+				}
+			}
+		}
+		if want, got := len(ref.keys), h.Len(); want != got {
+			t.Fatalf("want len %d, got %d", want, got)
+		}
+		checkHeap(t, h)
+	}
+}
+
+func TestHeapPopsInOrder(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	for _, n := range []int{0, 1, 2, 3, 10, 100, 1000} {
+		keys := make([]KType, n)
+		for i := range keys {
+			keys[i] = randomKType(rnd)
+		}
+		h := NewHeap(keys...)
+		checkHeap(t, h)
+		if h.Len() != n {
+			t.Fatalf("want len %d, got %d", n, h.Len())
+		}
+		for i := 1; i < n; i++ {
+			prev := h.Pop()
+			if h.before(h.Peek(), prev) {
+				t.Fatalf("popped %v before %v", prev, h.Peek())
+			}
+			checkHeap(t, h)
+		}
+	}
+}
+
+// checkHeapEmpty verifies that the empty heap h has nothing to peek, pop or
+// remove, such as k.
+func checkHeapEmpty(t *testing.T, h *Heap, k KType) {
+	if h.Len() != 0 {
+		t.Fatalf("want len 0, got %d", h.Len())
+	}
+	if got, ok := h.TryPeek(); ok {
+		t.Fatalf("try peek: want nothing, got %v", got)
+	}
+	if got, ok := h.TryPop(); ok {
+		t.Fatalf("try pop: want nothing, got %v", got)
+	}
+	if h.Remove(k) {
+		t.Fatalf("remove %v: want not found", k)
+	}
+	for _, op := range []struct {
+		name string
+		f    func()
+	}{
+		{"peek", func() { h.Peek() }},
+		{"pop", func() { h.Pop() }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s: want a panic on an empty heap", op.name)
+				}
+			}()
+			op.f()
+		}()
+	}
+	checkHeap(t, h)
+}
+
+func TestHeapEmpty(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	h := NewHeap()
+	checkHeapEmpty(t, h, randomKType(rnd))
+
+	k := randomKType(rnd)
+	h.Push(k)
+	if got, ok := h.TryPop(); !ok || h.compare(k, got) != 0 {
+		t.Fatalf("try pop: want %v, true, got %v, %v", k, got, ok)
+	}
+	checkHeapEmpty(t, h, k)
+
+	for i := 0; i < 10; i++ {
+		h.Push(randomKType(rnd))
+	}
+	for h.Len() > 0 {
+		h.Pop()
+	}
+	checkHeapEmpty(t, h, k)
+}
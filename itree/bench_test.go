@@ -0,0 +1,81 @@
+package itree
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// The benchmarks of this file are generated along with interval trees, when
+// asked to. The ends of the intervals are generated by benchKType, and their
+// values by benchVType.
+
+// benchIntervalTreeSizes are the numbers of intervals in the benchmarked
+// trees.
+var benchIntervalTreeSizes = []int{100, 10000, 1000000}
+
+// benchIntervalTree runs bench for each size, with a tree holding that many
+// random intervals, and the intervals and values inserted in it. The
+// intervals are the same from one benchmark to the other.
+func benchIntervalTree(b *testing.B, bench func(b *testing.B, r *IntervalTree, los, his []KType, vals []VType)) {
+	for _, n := range benchIntervalTreeSizes {
+		n := n
+		var r *IntervalTree
+		var los, his []KType
+		var vals []VType
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			// once for all the runs of the benchmark, and only if it's run
+			if r == nil {
+				rnd := rand.New(rand.NewSource(int64(n)))
+				r = NewIntervalTree()
+				los, his = make([]KType, n), make([]KType, n)
+				vals = make([]VType, n)
+				for i := range los {
+					los[i], his[i], vals[i] = benchKType(rnd), benchKType(rnd), benchVType(rnd)
+					if r.compare(los[i], his[i]) > 0 {
+						los[i], his[i] = his[i], los[i]
+					}
+					r.Insert(los[i], his[i], vals[i])
+				}
+			}
+			bench(b, r, los, his, vals)
+		})
+	}
+}
+
+// BenchmarkIntervalTreeInsert inserts the intervals again, then deletes the
+// values inserted, so that the tree doesn't grow.
+func BenchmarkIntervalTreeInsert(b *testing.B) {
+	benchIntervalTree(b, func(b *testing.B, r *IntervalTree, los, his []KType, vals []VType) {
+		n := len(los)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Insert(los[i%n], his[i%n], vals[i%n])
+			r.DeleteOne(los[i%n], his[i%n])
+		}
+	})
+}
+
+// BenchmarkIntervalTreeStabbing finds the intervals containing the start of
+// each interval, which the random intervals make many.
+func BenchmarkIntervalTreeStabbing(b *testing.B) {
+	benchIntervalTree(b, func(b *testing.B, r *IntervalTree, los, his []KType, vals []VType) {
+		n := len(los)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Stabbing(los[i%n], func(lo, hi KType, v VType) bool { return true })
+		}
+	})
+}
+
+// BenchmarkIntervalTreeFirstOverlapping stops at the first interval
+// overlapping each interval.
+func BenchmarkIntervalTreeFirstOverlapping(b *testing.B) {
+	benchIntervalTree(b, func(b *testing.B, r *IntervalTree, los, his []KType, vals []VType) {
+		n := len(los)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Overlapping(los[i%n], his[i%n], func(lo, hi KType, v VType) bool { return false })
+		}
+	})
+}
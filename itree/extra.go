@@ -0,0 +1,13 @@
+// Package itree implements an interval tree, on the left leaning red black
+// balanced search tree of map/redblackbst. Each node holds the largest end
+// of the intervals under it, which tells what subtrees hold no interval
+// overlapping the one searched for.
+package itree
+
+// ugly type names to avoid collisions, for easy find/replace.
+
+type KType interface {
+	Compare(other KType) int
+}
+
+type VType interface{}
@@ -0,0 +1,373 @@
+package itree
+
+func (r IntervalTree) compare(a, b KType) int { return a.Compare(b) }
+
+// IntervalTree maps closed intervals of KType to VType values. It's built on
+// a left leaning red black balanced search tree, ordering the intervals by
+// their start, then by their end. Each node holds the largest end of the
+// intervals under it, so that the intervals overlapping another one, or
+// containing a point, are found in O(log n) for each of them. An interval
+// inserted several times keeps all its values, in the order they were
+// inserted, as a sorted multimap does: its entries are the interval/value
+// pairs, which sizes count.
+type IntervalTree struct {
+	root *intervalnode
+}
+
+// NewIntervalTree creates an interval tree.
+func NewIntervalTree() *IntervalTree { return &IntervalTree{} }
+
+// IsEmpty tells if the interval tree contains no interval.
+func (r IntervalTree) IsEmpty() bool {
+	return r.root == nil
+}
+
+// Size of the interval tree, counting each value of each interval.
+func (r IntervalTree) Size() int { return r.root.size() }
+
+// Clear all the intervals in the interval tree.
+func (r *IntervalTree) Clear() { r.root = nil }
+
+// Insert a value in the interval tree for the interval [lo, hi], after the
+// values already inserted for the same interval, which are kept. It panics
+// if hi is smaller than lo.
+func (r *IntervalTree) Insert(lo, hi KType, v VType) {
+	if r.compare(hi, lo) < 0 {
+		panic("itree: interval ends before it starts")
+	}
+	r.root = r.insert(r.root, lo, hi, v)
+	r.root.colorRed = false
+}
+
+func (r *IntervalTree) insert(h *intervalnode, lo, hi KType, v VType) *intervalnode {
+	if h == nil {
+		return &intervalnode{lo: lo, hi: hi, vals: []VType{v}, max: hi, n: 1, colorRed: true}
+	}
+
+	cmp := r.compareTo(h, lo, hi)
+	if cmp < 0 {
+		h.left = r.insert(h.left, lo, hi, v)
+	} else if cmp > 0 {
+		h.right = r.insert(h.right, lo, hi, v)
+	} else {
+		h.vals = append(h.vals, v)
+	}
+
+	if h.right.isRed() && !h.left.isRed() {
+		h = r.rotateLeft(h)
+	}
+	if h.left.isRed() && h.left.left.isRed() {
+		h = r.rotateRight(h)
+	}
+	if h.left.isRed() && h.right.isRed() {
+		r.flipColors(h)
+	}
+	r.update(h)
+	return h
+}
+
+// GetAll returns the values of the interval [lo, hi] in the interval tree,
+// in the order they were inserted, or nil if the interval doesn't exist.
+// The slice returned is a copy.
+func (r IntervalTree) GetAll(lo, hi KType) []VType {
+	h := r.find(lo, hi)
+	if h == nil {
+		return nil
+	}
+	return append([]VType(nil), h.vals...)
+}
+
+// Count is the number of values of the interval [lo, hi].
+func (r IntervalTree) Count(lo, hi KType) int {
+	h := r.find(lo, hi)
+	if h == nil {
+		return 0
+	}
+	return len(h.vals)
+}
+
+func (r IntervalTree) find(lo, hi KType) *intervalnode {
+	for h := r.root; h != nil; {
+		cmp := r.compareTo(h, lo, hi)
+		if cmp == 0 {
+			return h
+		} else if cmp < 0 {
+			h = h.left
+		} else {
+			h = h.right
+		}
+	}
+	return nil
+}
+
+// Intervals visit each interval in the interval tree, in order, visiting an
+// interval once for each of its values. It stops when visit returns false.
+func (r IntervalTree) Intervals(visit func(lo, hi KType, v VType) bool) {
+	r.intervals(r.root, visit)
+}
+
+func (r IntervalTree) intervals(h *intervalnode, visit func(lo, hi KType, v VType) bool) bool {
+	if h == nil {
+		return true
+	}
+	return r.intervals(h.left, visit) &&
+		r.visitAll(h, visit) &&
+		r.intervals(h.right, visit)
+}
+
+// visitAll visits the interval of h once for each of its values, in order.
+func (r IntervalTree) visitAll(h *intervalnode, visit func(lo, hi KType, v VType) bool) bool {
+	for _, v := range h.vals {
+		if !visit(h.lo, h.hi, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Overlapping visits each interval of the interval tree that overlaps
+// [lo, hi], in order, meaning each interval that starts before or at hi and
+// ends at or after lo. It stops when visit returns false.
+func (r IntervalTree) Overlapping(lo, hi KType, visit func(lo, hi KType, v VType) bool) {
+	r.overlapping(r.root, lo, hi, visit)
+}
+
+// Stabbing visits each interval of the interval tree that contains the point
+// p, in order. It stops when visit returns false.
+func (r IntervalTree) Stabbing(p KType, visit func(lo, hi KType, v VType) bool) {
+	r.overlapping(r.root, p, p, visit)
+}
+
+func (r IntervalTree) overlapping(h *intervalnode, lo, hi KType, visit func(lo, hi KType, v VType) bool) bool {
+	// none of the intervals under h ends at or after lo
+	if h == nil || r.compare(lo, h.max) > 0 {
+		return true
+	}
+	if !r.overlapping(h.left, lo, hi, visit) {
+		return false
+	}
+	// h and the intervals on its right start after hi
+	if r.compare(h.lo, hi) > 0 {
+		return true
+	}
+	if r.compare(lo, h.hi) <= 0 && !r.visitAll(h, visit) {
+		return false
+	}
+	return r.overlapping(h.right, lo, hi, visit)
+}
+
+// deletions
+
+// Delete the interval [lo, hi] from the interval tree, with all its values,
+// if it exists. It tells if it did.
+func (r *IntervalTree) Delete(lo, hi KType) (ok bool) {
+	if r.find(lo, hi) == nil {
+		return false
+	}
+	r.delete(lo, hi)
+	return true
+}
+
+// DeleteOne removes the first value inserted for the interval [lo, hi] from
+// the interval tree, if it exists. The interval is removed along with its
+// last value.
+func (r *IntervalTree) DeleteOne(lo, hi KType) (old VType, ok bool) {
+	h := r.find(lo, hi)
+	if h == nil {
+		return
+	}
+	if len(h.vals) == 1 {
+		vals := r.delete(lo, hi)
+		return vals[0], true
+	}
+	old = h.vals[0]
+	// not holding on to the value removed
+	var zero VType
+	h.vals[0] = zero
+	h.vals = h.vals[1:]
+	// one less value under the nodes on the path to h
+	for x := r.root; x != h; {
+		x.n--
+		if r.compareTo(x, lo, hi) < 0 {
+			x = x.left
+		} else {
+			x = x.right
+		}
+	}
+	h.n--
+	return old, true
+}
+
+// DeleteAll removes the interval [lo, hi] and its values from the interval
+// tree, and returns the values in the order they were inserted, if it
+// exists.
+func (r *IntervalTree) DeleteAll(lo, hi KType) (old []VType) {
+	if r.find(lo, hi) == nil {
+		return nil
+	}
+	return r.delete(lo, hi)
+}
+
+// delete removes [lo, hi], which is in the interval tree, and returns its
+// values.
+func (r *IntervalTree) delete(lo, hi KType) (old []VType) {
+	r.root, old = r.deleteNode(r.root, lo, hi)
+	if !r.IsEmpty() {
+		r.root.colorRed = false
+	}
+	return old
+}
+
+func (r *IntervalTree) deleteNode(h *intervalnode, lo, hi KType) (_ *intervalnode, old []VType) {
+	if r.compareTo(h, lo, hi) < 0 {
+		if !h.left.isRed() && !h.left.left.isRed() {
+			h = r.moveRedLeft(h)
+		}
+		h.left, old = r.deleteNode(h.left, lo, hi)
+		return r.balance(h), old
+	}
+
+	if h.left.isRed() {
+		h = r.rotateRight(h)
+	}
+
+	if r.compareTo(h, lo, hi) == 0 && h.right == nil {
+		return nil, h.vals
+	}
+
+	if !h.right.isRed() && !h.right.left.isRed() {
+		h = r.moveRedRight(h)
+	}
+
+	if r.compareTo(h, lo, hi) == 0 {
+		var min *intervalnode
+		h.right, min = r.deleteMin(h.right)
+		old, h.lo, h.hi, h.vals = h.vals, min.lo, min.hi, min.vals
+	} else {
+		h.right, old = r.deleteNode(h.right, lo, hi)
+	}
+	return r.balance(h), old
+}
+
+// deleteMin removes the smallest interval of the tree of h, which isn't
+// empty, and returns its node.
+func (r *IntervalTree) deleteMin(h *intervalnode) (_, min *intervalnode) {
+	if h.left == nil {
+		return nil, h
+	}
+	if !h.left.isRed() && !h.left.left.isRed() {
+		h = r.moveRedLeft(h)
+	}
+	h.left, min = r.deleteMin(h.left)
+	return r.balance(h), min
+}
+
+// compareTo compares the interval [lo, hi] to the one of h.
+func (r IntervalTree) compareTo(h *intervalnode, lo, hi KType) int {
+	if cmp := r.compare(lo, h.lo); cmp != 0 {
+		return cmp
+	}
+	return r.compare(hi, h.hi)
+}
+
+// The rotations and balancing below keep the sizes and the largest ends of
+// the nodes they move up to date.
+
+func (r *IntervalTree) moveRedLeft(h *intervalnode) *intervalnode {
+	r.flipColors(h)
+	if h.right.left.isRed() {
+		h.right = r.rotateRight(h.right)
+		h = r.rotateLeft(h)
+		r.flipColors(h)
+	}
+	return h
+}
+
+func (r *IntervalTree) moveRedRight(h *intervalnode) *intervalnode {
+	r.flipColors(h)
+	if h.left.left.isRed() {
+		h = r.rotateRight(h)
+		r.flipColors(h)
+	}
+	return h
+}
+
+func (r *IntervalTree) balance(h *intervalnode) *intervalnode {
+	if h.right.isRed() {
+		h = r.rotateLeft(h)
+	}
+	if h.left.isRed() && h.left.left.isRed() {
+		h = r.rotateRight(h)
+	}
+	if h.left.isRed() && h.right.isRed() {
+		r.flipColors(h)
+	}
+	r.update(h)
+	return h
+}
+
+// The node moved up by a rotation holds the same intervals as the node it
+// replaces, so it takes its size and largest end.
+
+func (r *IntervalTree) rotateLeft(h *intervalnode) *intervalnode {
+	x := h.right
+	h.right = x.left
+	x.left = h
+	x.colorRed = h.colorRed
+	h.colorRed = true
+	x.n, x.max = h.n, h.max
+	r.update(h)
+	return x
+}
+
+func (r *IntervalTree) rotateRight(h *intervalnode) *intervalnode {
+	x := h.left
+	h.left = x.right
+	x.right = h
+	x.colorRed = h.colorRed
+	h.colorRed = true
+	x.n, x.max = h.n, h.max
+	r.update(h)
+	return x
+}
+
+func (r *IntervalTree) flipColors(h *intervalnode) {
+	h.colorRed = !h.colorRed
+	h.left.colorRed = !h.left.colorRed
+	h.right.colorRed = !h.right.colorRed
+}
+
+// update the size and the largest end of h, out of those of its children.
+func (r *IntervalTree) update(h *intervalnode) {
+	h.n = len(h.vals) + h.left.size() + h.right.size()
+	h.max = h.hi
+	if h.left != nil && r.compare(h.left.max, h.max) > 0 {
+		h.max = h.left.max
+	}
+	if h.right != nil && r.compare(h.right.max, h.max) > 0 {
+		h.max = h.right.max
+	}
+}
+
+// nodes
+
+type intervalnode struct {
+	lo, hi KType
+	// vals are the values of the interval, in the order they were inserted
+	vals        []VType
+	left, right *intervalnode
+	// n is the number of values of the tree of the node
+	n        int
+	colorRed bool
+	// max is the largest end of the intervals of the tree of the node
+	max KType
+}
+
+func (x *intervalnode) isRed() bool { return (x != nil) && (x.colorRed == true) }
+
+func (x *intervalnode) size() int {
+	if x == nil {
+		return 0
+	}
+	return x.n
+}
@@ -0,0 +1,182 @@
+package itree
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+type Int int
+
+func (i Int) Compare(other KType) int {
+	switch j := other.(Int); {
+	case i < j:
+		return -1
+	case i > j:
+		return 1
+	}
+	return 0
+}
+
+func randomKType(r *rand.Rand) KType { return Int(r.Intn(1000)) }
+
+func randomVType(r *rand.Rand) VType { return r.Intn(1000) }
+
+func benchKType(r *rand.Rand) KType { return Int(r.Int31()) }
+
+func benchVType(r *rand.Rand) VType { return r.Int() }
+
+// meetings are named after their hours.
+func meetings() *IntervalTree {
+	tree := NewIntervalTree()
+	tree.Insert(Int(9), Int(10), "standup")
+	tree.Insert(Int(10), Int(12), "review")
+	tree.Insert(Int(13), Int(17), "workshop")
+	tree.Insert(Int(14), Int(15), "interview")
+	tree.Insert(Int(9), Int(17), "oncall")
+	return tree
+}
+
+func visited(visit func(func(lo, hi KType, v VType) bool)) []string {
+	got := []string{}
+	visit(func(_, _ KType, v VType) bool {
+		got = append(got, v.(string))
+		return true
+	})
+	return got
+}
+
+func TestCanFindOverlappingIntervals(t *testing.T) {
+	tree := meetings()
+
+	for _, tt := range []struct {
+		lo, hi int
+		want   []string
+	}{
+		{lo: 0, hi: 8, want: []string{}},
+		{lo: 0, hi: 9, want: []string{"standup", "oncall"}},
+		{lo: 10, hi: 10, want: []string{"standup", "oncall", "review"}},
+		{lo: 12, hi: 13, want: []string{"oncall", "review", "workshop"}},
+		{lo: 15, hi: 20, want: []string{"oncall", "workshop", "interview"}},
+		{lo: 18, hi: 20, want: []string{}},
+	} {
+		got := visited(func(visit func(lo, hi KType, v VType) bool) {
+			tree.Overlapping(Int(tt.lo), Int(tt.hi), visit)
+		})
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("[%d, %d]: want %v, got %v", tt.lo, tt.hi, tt.want, got)
+		}
+	}
+
+	got := visited(func(visit func(lo, hi KType, v VType) bool) {
+		tree.Stabbing(Int(14), visit)
+	})
+	if want := []string{"oncall", "workshop", "interview"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("stabbing 14: want %v, got %v", want, got)
+	}
+}
+
+func TestIntervalsAreKeyedByBothEnds(t *testing.T) {
+	tree := meetings()
+	if got := tree.GetAll(Int(9), Int(17)); !reflect.DeepEqual([]VType{"oncall"}, got) {
+		t.Errorf("getting [9, 17]: want [oncall], got %v", got)
+	}
+	if got := tree.GetAll(Int(9), Int(12)); got != nil {
+		t.Errorf("[9, 12] should not be there, got %v", got)
+	}
+	if tree.Delete(Int(9), Int(12)) {
+		t.Errorf("[9, 12] should not be deleted")
+	}
+	if !tree.Delete(Int(9), Int(17)) {
+		t.Errorf("[9, 17] should be deleted")
+	}
+
+	got := visited(tree.Intervals)
+	if want := []string{"standup", "review", "workshop", "interview"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if tree.Size() != 4 {
+		t.Errorf("want size 4, got %d", tree.Size())
+	}
+}
+
+func TestIdenticalIntervalsAreAllKept(t *testing.T) {
+	tree := meetings()
+	tree.Insert(Int(9), Int(10), "sync")
+	tree.Insert(Int(9), Int(10), "coffee")
+	if got := tree.Count(Int(9), Int(10)); got != 3 {
+		t.Errorf("count of [9, 10]: want 3, got %d", got)
+	}
+	if tree.Size() != 7 {
+		t.Errorf("want size 7, got %d", tree.Size())
+	}
+	got := visited(func(visit func(lo, hi KType, v VType) bool) {
+		tree.Stabbing(Int(10), visit)
+	})
+	if want := []string{"standup", "sync", "coffee", "oncall", "review"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("stabbing 10: want %v, got %v", want, got)
+	}
+
+	if old, ok := tree.DeleteOne(Int(9), Int(10)); !ok || old != "standup" {
+		t.Errorf("deleting one of [9, 10]: want standup, true, got %v, %v", old, ok)
+	}
+	if got := tree.GetAll(Int(9), Int(10)); !reflect.DeepEqual([]VType{"sync", "coffee"}, got) {
+		t.Errorf("getting [9, 10]: want [sync coffee], got %v", got)
+	}
+	if old := tree.DeleteAll(Int(9), Int(10)); !reflect.DeepEqual([]VType{"sync", "coffee"}, old) {
+		t.Errorf("deleting [9, 10]: want [sync coffee], got %v", old)
+	}
+	if tree.Count(Int(9), Int(10)) != 0 || tree.Size() != 4 {
+		t.Errorf("want [9, 10] gone and size 4, got %d and %d", tree.Count(Int(9), Int(10)), tree.Size())
+	}
+
+	tree.Insert(Int(9), Int(17), "escalation")
+	if !tree.Delete(Int(9), Int(17)) || tree.Count(Int(9), Int(17)) != 0 || tree.Size() != 3 {
+		t.Errorf("want [9, 17] deleted with its values and size 3, got %d and %d", tree.Count(Int(9), Int(17)), tree.Size())
+	}
+}
+
+func TestCanAbortVisitingIntervals(t *testing.T) {
+	tree := meetings()
+	var got []string
+	tree.Overlapping(Int(0), Int(20), func(_, _ KType, v VType) bool {
+		got = append(got, v.(string))
+		return len(got) < 2
+	})
+	if want := []string{"standup", "oncall"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestInsertingBackwardIntervalPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("want a panic")
+		}
+	}()
+	NewIntervalTree().Insert(Int(2), Int(1), "backward")
+}
+
+func TestIntervalTreeEmpty(t *testing.T) {
+	tree := NewIntervalTree()
+	if !tree.IsEmpty() || tree.Size() != 0 {
+		t.Errorf("want empty tree, got %d intervals", tree.Size())
+	}
+	if tree.Delete(Int(1), Int(2)) {
+		t.Errorf("empty tree should have nothing to delete")
+	}
+	if _, ok := tree.DeleteOne(Int(1), Int(2)); ok {
+		t.Errorf("empty tree should have nothing to delete")
+	}
+	if old := tree.DeleteAll(Int(1), Int(2)); old != nil {
+		t.Errorf("empty tree should have nothing to delete, got %v", old)
+	}
+	if got := visited(tree.Intervals); len(got) != 0 {
+		t.Errorf("want no intervals, got %v", got)
+	}
+	meetings := meetings()
+	meetings.Clear()
+	if !meetings.IsEmpty() {
+		t.Errorf("want cleared tree empty, got %d intervals", meetings.Size())
+	}
+}
@@ -0,0 +1,247 @@
+package itree
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// The tests of this file are generated along with interval trees, when asked
+// to. The ends of the intervals are generated by randomKType, and their
+// values by randomVType.
+
+// checkIntervalTree verifies the invariants of the tree: the intervals are
+// in order, the sizes and largest ends of the subtrees are right, red links
+// lean left, no node has two red links and every path from the root to a
+// leaf has as many black links.
+func checkIntervalTree(t *testing.T, r *IntervalTree) {
+	if r.root.isRed() {
+		t.Fatal("root is red")
+	}
+	checkIntervalTreeNode(t, r, r.root)
+
+	var prev *intervalnode
+	for _, x := range nodesOfIntervalTree(r.root, nil) {
+		if prev != nil && r.compareTo(x, prev.lo, prev.hi) >= 0 {
+			t.Fatalf("intervals out of order: [%v, %v] before [%v, %v]", prev.lo, prev.hi, x.lo, x.hi)
+		}
+		prev = x
+	}
+}
+
+// checkIntervalTreeNode returns the number of black links from x to the
+// leaves.
+func checkIntervalTreeNode(t *testing.T, r *IntervalTree, x *intervalnode) int {
+	if x == nil {
+		return 0
+	}
+	if x.right.isRed() {
+		t.Fatalf("red link leans right under [%v, %v]", x.lo, x.hi)
+	}
+	if x.isRed() && x.left.isRed() {
+		t.Fatalf("two red links in a row under [%v, %v]", x.lo, x.hi)
+	}
+	if len(x.vals) == 0 {
+		t.Fatalf("no values for [%v, %v]", x.lo, x.hi)
+	}
+	if want := len(x.vals) + x.left.size() + x.right.size(); x.n != want {
+		t.Fatalf("size of [%v, %v]: want %d, got %d", x.lo, x.hi, want, x.n)
+	}
+	max := x.hi
+	for _, child := range []*intervalnode{x.left, x.right} {
+		if child != nil && r.compare(child.max, max) > 0 {
+			max = child.max
+		}
+	}
+	if r.compare(x.max, max) != 0 {
+		t.Fatalf("largest end under [%v, %v]: want %v, got %v", x.lo, x.hi, max, x.max)
+	}
+	black := checkIntervalTreeNode(t, r, x.left)
+	if right := checkIntervalTreeNode(t, r, x.right); black != right {
+		t.Fatalf("black links under [%v, %v]: %d on the left, %d on the right", x.lo, x.hi, black, right)
+	}
+	if !x.isRed() {
+		black++
+	}
+	return black
+}
+
+// nodesOfIntervalTree appends the nodes of the tree of x to nodes, in order.
+func nodesOfIntervalTree(x *intervalnode, nodes []*intervalnode) []*intervalnode {
+	if x == nil {
+		return nodes
+	}
+	nodes = nodesOfIntervalTree(x.left, nodes)
+	nodes = append(nodes, x)
+	return nodesOfIntervalTree(x.right, nodes)
+}
+
+// refIntervalTreeEntry is an interval of refIntervalTree, with its value.
+type refIntervalTreeEntry struct {
+	lo, hi KType
+	val    VType
+}
+
+func (e refIntervalTreeEntry) String() string { return fmt.Sprintf("[%v, %v]=%v", e.lo, e.hi, e.val) }
+
+// refIntervalTree is a naive interval tree keeping its intervals and their
+// values in a slice, in the order they were inserted, and scanning all of
+// them for each search.
+type refIntervalTree struct {
+	r       *IntervalTree
+	entries []refIntervalTreeEntry
+}
+
+// index returns the index of the first entry of [lo, hi], or -1.
+func (ref *refIntervalTree) index(lo, hi KType) int {
+	for i, e := range ref.entries {
+		if ref.r.compare(e.lo, lo) == 0 && ref.r.compare(e.hi, hi) == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// values returns the values of [lo, hi], in the order they were inserted.
+func (ref *refIntervalTree) values(lo, hi KType) []VType {
+	var vals []VType
+	for _, e := range ref.entries {
+		if ref.r.compare(e.lo, lo) == 0 && ref.r.compare(e.hi, hi) == 0 {
+			vals = append(vals, e.val)
+		}
+	}
+	return vals
+}
+
+// overlapping returns the entries overlapping [lo, hi], in the order of the
+// interval tree.
+func (ref *refIntervalTree) overlapping(lo, hi KType) []refIntervalTreeEntry {
+	var found []refIntervalTreeEntry
+	for _, e := range ref.entries {
+		if ref.r.compare(e.lo, hi) > 0 || ref.r.compare(e.hi, lo) < 0 {
+			continue
+		}
+		found = append(found, e)
+	}
+	// the values of an interval stay in the order they were inserted
+	sort.SliceStable(found, func(i, j int) bool {
+		if cmp := ref.r.compare(found[i].lo, found[j].lo); cmp != 0 {
+			return cmp < 0
+		}
+		return ref.r.compare(found[i].hi, found[j].hi) < 0
+	})
+	return found
+}
+
+// collectIntervalTree returns the entries visited by visit.
+func collectIntervalTree(visit func(func(lo, hi KType, v VType) bool)) []refIntervalTreeEntry {
+	var got []refIntervalTreeEntry
+	visit(func(lo, hi KType, v VType) bool {
+		got = append(got, refIntervalTreeEntry{lo: lo, hi: hi, val: v})
+		return true
+	})
+	return got
+}
+
+// randomIntervalTreeInterval returns an interval whose ends are random, in
+// order.
+func randomIntervalTreeInterval(r *IntervalTree, rnd *rand.Rand) (lo, hi KType) {
+	lo, hi = randomKType(rnd), randomKType(rnd)
+	if r.compare(lo, hi) > 0 {
+		lo, hi = hi, lo
+	}
+	return lo, hi
+}
+
+func TestIntervalTreeMatchesBruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	r := NewIntervalTree()
+	ref := &refIntervalTree{r: r}
+
+	checkFound := func(op string, want, got []refIntervalTreeEntry) {
+		t.Helper()
+		if len(want) == 0 && len(got) == 0 {
+			return
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("%s:\nwant %v\n got %v", op, want, got)
+		}
+	}
+
+	for n := 0; n < 5000; n++ {
+		lo, hi := randomIntervalTreeInterval(r, rnd)
+		// deleting the intervals inserted, since random ones rarely are
+		if len(ref.entries) != 0 && rnd.Intn(4) == 0 {
+			e := ref.entries[rnd.Intn(len(ref.entries))]
+			lo, hi = e.lo, e.hi
+		}
+		switch op := rnd.Intn(8); op {
+		case 0, 1, 2:
+			v := randomVType(rnd)
+			ref.entries = append(ref.entries, refIntervalTreeEntry{lo: lo, hi: hi, val: v})
+			r.Insert(lo, hi, v)
+		case 3:
+			want := ref.values(lo, hi)
+			if got := r.GetAll(lo, hi); !reflect.DeepEqual(want, got) {
+				t.Fatalf("get all [%v, %v]: want %v, got %v", lo, hi, want, got)
+			}
+			if got := r.Count(lo, hi); got != len(want) {
+				t.Fatalf("count [%v, %v]: want %d, got %d", lo, hi, len(want), got)
+			}
+		case 4:
+			i := ref.index(lo, hi)
+			var want VType
+			if i >= 0 {
+				want = ref.entries[i].val
+				ref.entries = append(ref.entries[:i], ref.entries[i+1:]...)
+			}
+			old, ok := r.DeleteOne(lo, hi)
+			if ok != (i >= 0) || !reflect.DeepEqual(old, want) {
+				t.Fatalf("delete one [%v, %v]: want %v, %v, got %v, %v", lo, hi, want, i >= 0, old, ok)
+			}
+		case 5:
+			want := ref.values(lo, hi)
+			for i := ref.index(lo, hi); i >= 0; i = ref.index(lo, hi) {
+				ref.entries = append(ref.entries[:i], ref.entries[i+1:]...)
+			}
+			if rnd.Intn(2) == 0 {
+				if ok := r.Delete(lo, hi); ok != (want != nil) {
+					t.Fatalf("delete [%v, %v]: want %v, got %v", lo, hi, want != nil, ok)
+				}
+			} else if old := r.DeleteAll(lo, hi); !reflect.DeepEqual(want, old) {
+				t.Fatalf("delete all [%v, %v]: want %v, got %v", lo, hi, want, old)
+			}
+		case 6:
+			got := collectIntervalTree(func(visit func(lo, hi KType, v VType) bool) {
+				r.Overlapping(lo, hi, visit)
+			})
+			checkFound(fmt.Sprintf("overlapping [%v, %v]", lo, hi), ref.overlapping(lo, hi), got)
+		case 7:
+			p := randomKType(rnd)
+			got := collectIntervalTree(func(visit func(lo, hi KType, v VType) bool) {
+				r.Stabbing(p, visit)
+			})
+			checkFound(fmt.Sprintf("stabbing %v", p), ref.overlapping(p, p), got)
+		}
+		if want, got := len(ref.entries), r.Size(); want != got {
+			t.Fatalf("want size %d, got %d", want, got)
+		}
+		checkIntervalTree(t, r)
+	}
+
+	if len(ref.entries) == 0 {
+		t.Fatal("no intervals left to compare")
+	}
+	min, max := ref.entries[0].lo, ref.entries[0].hi
+	for _, e := range ref.entries {
+		if r.compare(e.lo, min) < 0 {
+			min = e.lo
+		}
+		if r.compare(e.hi, max) > 0 {
+			max = e.hi
+		}
+	}
+	checkFound("intervals", ref.overlapping(min, max), collectIntervalTree(r.Intervals))
+}
@@ -0,0 +1,366 @@
+package redblackbst
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// The benchmarks of this file are generated along with sorted maps, when
+// asked to. The keys and values are generated by benchKType and benchVType.
+
+// benchRedBlackSizes are the numbers of keys in the benchmarked maps.
+var benchRedBlackSizes = []int{100, 10000, 1000000}
+
+// benchRedBlack runs bench for each size, with a map holding that many
+// random keys, and the keys and values put in it. The keys are the same from
+// one benchmark to the other.
+func benchRedBlack(b *testing.B, bench func(b *testing.B, r *RedBlack, keys []KType, vals []VType)) {
+	for _, n := range benchRedBlackSizes {
+		n := n
+		var r *RedBlack
+		var keys []KType
+		var vals []VType
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			// once for all the runs of the benchmark, and only if it's run
+			if r == nil {
+				rnd := rand.New(rand.NewSource(int64(n)))
+				keys = make([]KType, n)
+				vals = make([]VType, n)
+				for i := range keys {
+					keys[i], vals[i] = benchKType(rnd), benchVType(rnd)
+				}
+				r = NewRedBlack()
+				fillRedBlack(r, keys, vals)
+			}
+			bench(b, r, keys, vals)
+		})
+	}
+}
+
+func fillRedBlack(r *RedBlack, keys []KType, vals []VType) {
+	for i, k := range keys {
+		r.Put(k, vals[i])
+	}
+}
+
+// benchRedBlackRefill runs op b.N times, putting the keys back in r every n
+// times. The refill isn't timed.
+func benchRedBlackRefill(b *testing.B, r *RedBlack, keys []KType, vals []VType, op func(i int)) {
+	n := len(keys)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		op(i % n)
+		if i%n == n-1 {
+			b.StopTimer()
+			fillRedBlack(r, keys, vals)
+			b.StartTimer()
+		}
+	}
+	b.StopTimer()
+	fillRedBlack(r, keys, vals)
+}
+
+// BenchmarkRedBlackPut is to be compared with BenchmarkRedBlackPutLLRB.
+func BenchmarkRedBlackPut(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		n := len(keys)
+		r.Clear()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// fill the map up to n keys, over and over
+			if i%n == 0 {
+				r.Clear()
+			}
+			r.Put(keys[i%n], vals[i%n])
+		}
+		b.StopTimer()
+		fillRedBlack(r, keys, vals)
+	})
+}
+
+// BenchmarkRedBlackGet is to be compared with BenchmarkRedBlackGetLLRB.
+func BenchmarkRedBlackGet(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Get(keys[i%len(keys)])
+		}
+	})
+}
+
+// llrbRedBlackItem is an item of llrbRedBlack, held as an interface and
+// ordered by its Less method, like the items of GoLLRB.
+type llrbRedBlackItem interface {
+	Less(than llrbRedBlackItem) bool
+}
+
+// llrbRedBlackEntry is a key and its value, ordered like RedBlack.
+type llrbRedBlackEntry struct {
+	r *RedBlack
+	k KType
+	v VType
+}
+
+func (e llrbRedBlackEntry) Less(than llrbRedBlackItem) bool {
+	return e.r.compare(e.k, than.(llrbRedBlackEntry).k) < 0
+}
+
+// llrbRedBlack is a left leaning red black tree of interface{} items, as
+// implemented by GoLLRB. It only puts and gets, which is all it's
+// benchmarked for.
+type llrbRedBlack struct {
+	root *llrbRedBlackNode
+}
+
+type llrbRedBlackNode struct {
+	item        llrbRedBlackItem
+	left, right *llrbRedBlackNode
+	black       bool
+}
+
+func (t *llrbRedBlack) put(item llrbRedBlackItem) {
+	t.root = t.root.insert(item)
+	t.root.black = true
+}
+
+func (t *llrbRedBlack) get(item llrbRedBlackItem) llrbRedBlackItem {
+	h := t.root
+	for h != nil {
+		switch {
+		case item.Less(h.item):
+			h = h.left
+		case h.item.Less(item):
+			h = h.right
+		default:
+			return h.item
+		}
+	}
+	return nil
+}
+
+func (h *llrbRedBlackNode) isRed() bool { return h != nil && !h.black }
+
+func (h *llrbRedBlackNode) insert(item llrbRedBlackItem) *llrbRedBlackNode {
+	if h == nil {
+		return &llrbRedBlackNode{item: item}
+	}
+	switch {
+	case item.Less(h.item):
+		h.left = h.left.insert(item)
+	case h.item.Less(item):
+		h.right = h.right.insert(item)
+	default:
+		h.item = item
+	}
+	if h.right.isRed() && !h.left.isRed() {
+		h = h.rotateLeft()
+	}
+	if h.left.isRed() && h.left.left.isRed() {
+		h = h.rotateRight()
+	}
+	if h.left.isRed() && h.right.isRed() {
+		h.black = !h.black
+		h.left.black = true
+		h.right.black = true
+	}
+	return h
+}
+
+func (h *llrbRedBlackNode) rotateLeft() *llrbRedBlackNode {
+	x := h.right
+	h.right = x.left
+	x.left = h
+	x.black = h.black
+	h.black = false
+	return x
+}
+
+func (h *llrbRedBlackNode) rotateRight() *llrbRedBlackNode {
+	x := h.left
+	h.left = x.right
+	x.right = h
+	x.black = h.black
+	h.black = false
+	return x
+}
+
+func BenchmarkRedBlackPutLLRB(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		n := len(keys)
+		t := &llrbRedBlack{}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// fill the tree up to n keys, over and over
+			if i%n == 0 {
+				t.root = nil
+			}
+			t.put(llrbRedBlackEntry{r: r, k: keys[i%n], v: vals[i%n]})
+		}
+	})
+}
+
+func BenchmarkRedBlackGetLLRB(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		t := &llrbRedBlack{}
+		for i, k := range keys {
+			t.put(llrbRedBlackEntry{r: r, k: k, v: vals[i]})
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			t.get(llrbRedBlackEntry{r: r, k: keys[i%len(keys)]})
+		}
+	})
+}
+
+func BenchmarkRedBlackHas(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Has(keys[i%len(keys)])
+		}
+	})
+}
+
+func BenchmarkRedBlackDelete(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		benchRedBlackRefill(b, r, keys, vals, func(i int) { r.Delete(keys[i]) })
+	})
+}
+
+func BenchmarkRedBlackDeleteMin(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		benchRedBlackRefill(b, r, keys, vals, func(int) { r.DeleteMin() })
+	})
+}
+
+func BenchmarkRedBlackDeleteMax(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		benchRedBlackRefill(b, r, keys, vals, func(int) { r.DeleteMax() })
+	})
+}
+
+func BenchmarkRedBlackMin(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Min()
+		}
+	})
+}
+
+func BenchmarkRedBlackMax(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Max()
+		}
+	})
+}
+
+func BenchmarkRedBlackFloor(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Floor(keys[i%len(keys)])
+		}
+	})
+}
+
+func BenchmarkRedBlackCeiling(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Ceiling(keys[i%len(keys)])
+		}
+	})
+}
+
+func BenchmarkRedBlackRank(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Rank(keys[i%len(keys)])
+		}
+	})
+}
+
+func BenchmarkRedBlackSelect(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		n := r.Size()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Select(i % n)
+		}
+	})
+}
+
+func BenchmarkRedBlackKeys(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		visit := func(KType, VType) bool { return true }
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Keys(visit)
+		}
+	})
+}
+
+// BenchmarkRedBlackRangedKeys visits a quarter of the keys.
+func BenchmarkRedBlackRangedKeys(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		n := r.Size()
+		lo, _, _ := r.Select(n / 4)
+		hi, _, _ := r.Select(n / 2)
+		visit := func(KType, VType) bool { return true }
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.RangedKeys(lo, hi, visit)
+		}
+	})
+}
+
+// BenchmarkRedBlackRangeAggregate aggregates the ranges between consecutive
+// keys put, of random lengths, in augmented sorted maps.
+func BenchmarkRedBlackRangeAggregate(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		n := len(keys)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			lo, hi := keys[i%n], keys[(i+1)%n]
+			if r.compare(lo, hi) > 0 {
+				lo, hi = hi, lo
+			}
+			r.RangeAggregate(lo, hi)
+		}
+	})
+}
+
+// BenchmarkRedBlackFromSorted builds the whole map at each iteration.
+func BenchmarkRedBlackFromSorted(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		sortedKeys, sortedVals := r.ToSlice()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			NewRedBlackFromSorted(sortedKeys, sortedVals)
+		}
+	})
+}
+
+// BenchmarkRedBlackFromUnsorted builds the whole map at each iteration.
+func BenchmarkRedBlackFromUnsorted(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			NewRedBlackFromUnsorted(keys, vals)
+		}
+	})
+}
+
+func BenchmarkRedBlackToSlice(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType, vals []VType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.ToSlice()
+		}
+	})
+}
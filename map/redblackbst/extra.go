@@ -24,6 +24,11 @@ type KType interface {
 
 type VType interface{}
 
+// AType is the type of the aggregates of RedBlack, which only augmented
+// sorted maps are generated with. Here, the aggregate of keys is their count,
+// an int.
+type AType interface{}
+
 // debugging
 
 // DotGraph exports the sorted map into DOT format.
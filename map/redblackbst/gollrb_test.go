@@ -17,6 +17,14 @@ func (i Int) Compare(other KType) int {
 	return int(i - other.(Int))
 }
 
+func randomKType(r *rand.Rand) KType { return Int(r.Intn(1000)) }
+
+func randomVType(r *rand.Rand) VType { return r.Intn(1000) }
+
+func benchKType(r *rand.Rand) KType { return Int(r.Int31()) }
+
+func benchVType(r *rand.Rand) VType { return r.Int() }
+
 func TestCases(t *testing.T) {
 	tree := NewRedBlack()
 	tree.Put(Int(1), 1)
@@ -0,0 +1,394 @@
+package redblackbst
+
+// The implementation was forked from the one of the sorted maps
+// (map/redblackbst/rbbst.go in datagen), its nodes holding all the values of
+// their key: a fix to the balancing of either tree is to be made to the
+// other. Get and Delete became GetAll, DeleteOne and DeleteAll, and the other
+// methods the sorted maps gained since are missing here: Floor, Ceiling,
+// Lower, Higher, ReverseKeys, RangedKeysDesc, RangeCount, BoundedKeys,
+// Cursor, DeleteMin, DeleteMax, DeleteRange, ToSlice, KeysSlice, ValuesSlice,
+// the aggregates and the constructors from sorted and unsorted slices.
+
+func (r MultiRedBlack) compare(a, b KType) int { return a.Compare(b) }
+
+// MultiRedBlack is a sorted multimap built on a left leaning red black
+// balanced search tree. It stores VType values, keyed by KType, keeping all
+// the values put at a key in the order they were put. Its entries are the
+// key/value pairs, ordered by key then in that order, which sizes, ranks and
+// selections count.
+type MultiRedBlack struct {
+	root *multinode
+}
+
+// NewMultiRedBlack creates a sorted multimap.
+func NewMultiRedBlack() *MultiRedBlack { return &MultiRedBlack{} }
+
+// IsEmpty tells if the sorted multimap contains no key/value.
+func (r MultiRedBlack) IsEmpty() bool {
+	return r.root == nil
+}
+
+// Size of the sorted multimap, counting each value of each key.
+func (r MultiRedBlack) Size() int { return r.root.size() }
+
+// Clear all the values in the sorted multimap.
+func (r *MultiRedBlack) Clear() { r.root = nil }
+
+// Put a value in the sorted multimap at key `k`, after the values already at
+// `k`.
+func (r *MultiRedBlack) Put(k KType, v VType) {
+	r.PutAll(k, v)
+}
+
+// PutAll puts values in the sorted multimap at key `k`, in order, after the
+// values already at `k`.
+func (r *MultiRedBlack) PutAll(k KType, vs ...VType) {
+	if len(vs) == 0 {
+		return
+	}
+	r.root = r.put(r.root, k, vs)
+	r.root.colorRed = false
+}
+
+func (r *MultiRedBlack) put(h *multinode, k KType, vs []VType) *multinode {
+	if h == nil {
+		vals := append([]VType(nil), vs...)
+		return &multinode{key: k, vals: vals, n: len(vals), colorRed: true}
+	}
+
+	cmp := r.compare(k, h.key)
+	if cmp < 0 {
+		h.left = r.put(h.left, k, vs)
+	} else if cmp > 0 {
+		h.right = r.put(h.right, k, vs)
+	} else {
+		h.vals = append(h.vals, vs...)
+	}
+
+	if h.right.isRed() && !h.left.isRed() {
+		h = r.rotateLeft(h)
+	}
+	if h.left.isRed() && h.left.left.isRed() {
+		h = r.rotateRight(h)
+	}
+	if h.left.isRed() && h.right.isRed() {
+		r.flipColors(h)
+	}
+	h.n = h.left.size() + h.right.size() + len(h.vals)
+	return h
+}
+
+// GetAll returns the values at key `k` in the sorted multimap, in the order
+// they were put, or none if the key doesn't exist.
+func (r MultiRedBlack) GetAll(k KType) []VType {
+	h := r.find(k)
+	if h == nil {
+		return nil
+	}
+	return append([]VType(nil), h.vals...)
+}
+
+// Has tells if values exist at key `k`.
+func (r MultiRedBlack) Has(k KType) bool { return r.find(k) != nil }
+
+// Count is the number of values at key `k`.
+func (r MultiRedBlack) Count(k KType) int {
+	h := r.find(k)
+	if h == nil {
+		return 0
+	}
+	return len(h.vals)
+}
+
+func (r MultiRedBlack) find(k KType) *multinode {
+	for h := r.root; h != nil; {
+		cmp := r.compare(k, h.key)
+		if cmp == 0 {
+			return h
+		} else if cmp < 0 {
+			h = h.left
+		} else {
+			h = h.right
+		}
+	}
+	return nil
+}
+
+// Min returns the smallest key in the sorted multimap and its first value,
+// if it exists.
+func (r MultiRedBlack) Min() (k KType, v VType, ok bool) {
+	if r.root == nil {
+		return
+	}
+	h := r.root
+	for h.left != nil {
+		h = h.left
+	}
+	return h.key, h.vals[0], true
+}
+
+// Max returns the largest key in the sorted multimap and its last value, if
+// it exists.
+func (r MultiRedBlack) Max() (k KType, v VType, ok bool) {
+	if r.root == nil {
+		return
+	}
+	h := r.root
+	for h.right != nil {
+		h = h.right
+	}
+	return h.key, h.vals[len(h.vals)-1], true
+}
+
+// Select the key/value of rank `i`, meaning the i-th smallest key/value of
+// the sorted multimap, counting each value of each key.
+func (r MultiRedBlack) Select(i int) (k KType, v VType, ok bool) {
+	for h := r.root; h != nil; {
+		t := h.left.size()
+		if i < t {
+			h = h.left
+		} else if i < t+len(h.vals) {
+			return h.key, h.vals[i-t], true
+		} else {
+			h, i = h.right, i-t-len(h.vals)
+		}
+	}
+	return
+}
+
+// Rank is the number of values at keys less than `k`.
+func (r MultiRedBlack) Rank(k KType) int {
+	rank := 0
+	for h := r.root; h != nil; {
+		cmp := r.compare(k, h.key)
+		if cmp < 0 {
+			h = h.left
+		} else if cmp > 0 {
+			rank += h.left.size() + len(h.vals)
+			h = h.right
+		} else {
+			return rank + h.left.size()
+		}
+	}
+	return rank
+}
+
+// Keys visit each key/value in the sorted multimap, in order, visiting a key
+// once for each of its values. It stops when visit returns false.
+func (r MultiRedBlack) Keys(visit func(KType, VType) bool) {
+	min, _, ok := r.Min()
+	if !ok {
+		return
+	}
+	// if the min exists, then the max must exist
+	max, _, _ := r.Max()
+	r.RangedKeys(min, max, visit)
+}
+
+// RangedKeys visit each key/value between lo and hi in the sorted multimap,
+// in order. It stops when visit returns false.
+func (r MultiRedBlack) RangedKeys(lo, hi KType, visit func(KType, VType) bool) {
+	r.keys(r.root, visit, lo, hi)
+}
+
+func (r MultiRedBlack) keys(h *multinode, visit func(KType, VType) bool, lo, hi KType) bool {
+	if h == nil {
+		return true
+	}
+	cmplo := r.compare(lo, h.key)
+	cmphi := r.compare(hi, h.key)
+	if cmplo < 0 {
+		if !r.keys(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	if cmplo <= 0 && cmphi >= 0 {
+		for _, v := range h.vals {
+			if !visit(h.key, v) {
+				return false
+			}
+		}
+	}
+	if cmphi > 0 {
+		if !r.keys(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
+// deletions
+
+// DeleteOne removes the first value put at key `k` from the sorted multimap,
+// if it exists. The key is removed along with its last value.
+func (r *MultiRedBlack) DeleteOne(k KType) (old VType, ok bool) {
+	h := r.find(k)
+	if h == nil {
+		return
+	}
+	if len(h.vals) == 1 {
+		vals := r.delete(k)
+		return vals[0], true
+	}
+	old = h.vals[0]
+	// not holding on to the value removed
+	var zero VType
+	h.vals[0] = zero
+	h.vals = h.vals[1:]
+	// one less value under the nodes on the path to h
+	for x := r.root; x != h; {
+		x.n--
+		if r.compare(k, x.key) < 0 {
+			x = x.left
+		} else {
+			x = x.right
+		}
+	}
+	h.n--
+	return old, true
+}
+
+// DeleteAll removes key `k` and its values from the sorted multimap, and
+// returns the values in the order they were put, if it exists.
+func (r *MultiRedBlack) DeleteAll(k KType) (old []VType) {
+	if !r.Has(k) {
+		return nil
+	}
+	return r.delete(k)
+}
+
+// delete removes `k`, which is in the sorted multimap, and returns its values.
+func (r *MultiRedBlack) delete(k KType) (old []VType) {
+	r.root, old = r.deleteNode(r.root, k)
+	if !r.IsEmpty() {
+		r.root.colorRed = false
+	}
+	return old
+}
+
+func (r *MultiRedBlack) deleteNode(h *multinode, k KType) (_ *multinode, old []VType) {
+	if r.compare(k, h.key) < 0 {
+		if !h.left.isRed() && !h.left.left.isRed() {
+			h = r.moveRedLeft(h)
+		}
+		h.left, old = r.deleteNode(h.left, k)
+		return r.balance(h), old
+	}
+
+	if h.left.isRed() {
+		h = r.rotateRight(h)
+	}
+
+	if r.compare(k, h.key) == 0 && h.right == nil {
+		return nil, h.vals
+	}
+
+	if !h.right.isRed() && !h.right.left.isRed() {
+		h = r.moveRedRight(h)
+	}
+
+	if r.compare(k, h.key) == 0 {
+		var min *multinode
+		h.right, min = r.deleteMin(h.right)
+		old, h.key, h.vals = h.vals, min.key, min.vals
+	} else {
+		h.right, old = r.deleteNode(h.right, k)
+	}
+	return r.balance(h), old
+}
+
+// deleteMin removes the smallest key of the tree of h, which isn't empty,
+// and returns its node.
+func (r *MultiRedBlack) deleteMin(h *multinode) (_, min *multinode) {
+	if h.left == nil {
+		return nil, h
+	}
+	if !h.left.isRed() && !h.left.left.isRed() {
+		h = r.moveRedLeft(h)
+	}
+	h.left, min = r.deleteMin(h.left)
+	return r.balance(h), min
+}
+
+func (r *MultiRedBlack) moveRedLeft(h *multinode) *multinode {
+	r.flipColors(h)
+	if h.right.left.isRed() {
+		h.right = r.rotateRight(h.right)
+		h = r.rotateLeft(h)
+		r.flipColors(h)
+	}
+	return h
+}
+
+func (r *MultiRedBlack) moveRedRight(h *multinode) *multinode {
+	r.flipColors(h)
+	if h.left.left.isRed() {
+		h = r.rotateRight(h)
+		r.flipColors(h)
+	}
+	return h
+}
+
+func (r *MultiRedBlack) balance(h *multinode) *multinode {
+	if h.right.isRed() {
+		h = r.rotateLeft(h)
+	}
+	if h.left.isRed() && h.left.left.isRed() {
+		h = r.rotateRight(h)
+	}
+	if h.left.isRed() && h.right.isRed() {
+		r.flipColors(h)
+	}
+	h.n = h.left.size() + h.right.size() + len(h.vals)
+	return h
+}
+
+func (r *MultiRedBlack) rotateLeft(h *multinode) *multinode {
+	x := h.right
+	h.right = x.left
+	x.left = h
+	x.colorRed = h.colorRed
+	h.colorRed = true
+	x.n = h.n
+	h.n = len(h.vals) + h.left.size() + h.right.size()
+	return x
+}
+
+func (r *MultiRedBlack) rotateRight(h *multinode) *multinode {
+	x := h.left
+	h.left = x.right
+	x.right = h
+	x.colorRed = h.colorRed
+	h.colorRed = true
+	x.n = h.n
+	h.n = len(h.vals) + h.left.size() + h.right.size()
+	return x
+}
+
+func (r *MultiRedBlack) flipColors(h *multinode) {
+	h.colorRed = !h.colorRed
+	h.left.colorRed = !h.left.colorRed
+	h.right.colorRed = !h.right.colorRed
+}
+
+// nodes
+
+type multinode struct {
+	key KType
+	// vals are the values at the key, in the order they were put, of which
+	// there's always one at least
+	vals        []VType
+	left, right *multinode
+	// n is the number of values of the tree of the node
+	n        int
+	colorRed bool
+}
+
+func (x *multinode) isRed() bool { return (x != nil) && (x.colorRed == true) }
+
+func (x *multinode) size() int {
+	if x == nil {
+		return 0
+	}
+	return x.n
+}
@@ -0,0 +1,80 @@
+package redblackbst
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// The benchmarks of this file are generated along with sorted multimaps,
+// when asked to. The keys and values are generated by benchKType and
+// benchVType.
+
+// benchMultiRedBlackSizes are the numbers of values in the benchmarked
+// multimaps.
+var benchMultiRedBlackSizes = []int{100, 10000, 1000000}
+
+// benchMultiRedBlack runs bench for each size, with a multimap holding that
+// many random values, at half as many keys, and the keys and values put in
+// it. The keys are the same from one benchmark to the other.
+func benchMultiRedBlack(b *testing.B, bench func(b *testing.B, r *MultiRedBlack, keys []KType, vals []VType)) {
+	for _, n := range benchMultiRedBlackSizes {
+		n := n
+		var r *MultiRedBlack
+		var keys []KType
+		var vals []VType
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			// once for all the runs of the benchmark, and only if it's run
+			if r == nil {
+				rnd := rand.New(rand.NewSource(int64(n)))
+				keys = make([]KType, n)
+				vals = make([]VType, n)
+				for i := range keys {
+					keys[i], vals[i] = benchKType(rnd), benchVType(rnd)
+					if i%2 == 1 {
+						keys[i] = keys[i-1]
+					}
+				}
+				r = NewMultiRedBlack()
+				for i, k := range keys {
+					r.Put(k, vals[i])
+				}
+			}
+			bench(b, r, keys, vals)
+		})
+	}
+}
+
+// BenchmarkMultiRedBlackDeleteOnePut removes the first value of a key and
+// puts it back last, leaving as many values in the multimap.
+func BenchmarkMultiRedBlackDeleteOnePut(b *testing.B) {
+	benchMultiRedBlack(b, func(b *testing.B, r *MultiRedBlack, keys []KType, vals []VType) {
+		n := len(keys)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			k := keys[i%n]
+			v, _ := r.DeleteOne(k)
+			r.Put(k, v)
+		}
+	})
+}
+
+func BenchmarkMultiRedBlackCount(b *testing.B) {
+	benchMultiRedBlack(b, func(b *testing.B, r *MultiRedBlack, keys []KType, vals []VType) {
+		n := len(keys)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Count(keys[i%n])
+		}
+	})
+}
+
+func BenchmarkMultiRedBlackSelect(b *testing.B) {
+	benchMultiRedBlack(b, func(b *testing.B, r *MultiRedBlack, keys []KType, vals []VType) {
+		n := r.Size()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Select(i % n)
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package redblackbst
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// The tests of this file are generated along with sorted multimaps, when
+// asked to. The keys and values are generated by randomKType and
+// randomVType.
+
+// checkMultiRedBlack verifies the invariants of the tree: the keys are in
+// order, each has a value at least, the sizes of the subtrees are right, red
+// links lean left, no node has two red links and every path from the root
+// to a leaf has as many black links.
+func checkMultiRedBlack(t *testing.T, r *MultiRedBlack) {
+	if r.root.isRed() {
+		t.Fatal("root is red")
+	}
+	checkMultiRedBlackNode(t, r, r.root)
+
+	var prev *KType
+	r.Keys(func(k KType, _ VType) bool {
+		if prev != nil && r.compare(*prev, k) > 0 {
+			t.Fatalf("keys out of order: %v before %v", *prev, k)
+		}
+		prev = &k
+		return true
+	})
+}
+
+// checkMultiRedBlackNode returns the number of black links from x to the
+// leaves.
+func checkMultiRedBlackNode(t *testing.T, r *MultiRedBlack, x *multinode) int {
+	if x == nil {
+		return 0
+	}
+	if len(x.vals) == 0 {
+		t.Fatalf("%v has no values", x.key)
+	}
+	if x.right.isRed() {
+		t.Fatalf("red link leans right under %v", x.key)
+	}
+	if x.isRed() && x.left.isRed() {
+		t.Fatalf("two red links in a row under %v", x.key)
+	}
+	if want := len(x.vals) + x.left.size() + x.right.size(); x.n != want {
+		t.Fatalf("size of %v: want %d, got %d", x.key, want, x.n)
+	}
+	black := checkMultiRedBlackNode(t, r, x.left)
+	if right := checkMultiRedBlackNode(t, r, x.right); black != right {
+		t.Fatalf("black links under %v: %d on the left, %d on the right", x.key, black, right)
+	}
+	if !x.isRed() {
+		black++
+	}
+	return black
+}
+
+// refMultiRedBlack is a naive sorted multimap keeping its keys in a sorted
+// slice, each with the slice of its values, ordered like r.
+type refMultiRedBlack struct {
+	r    *MultiRedBlack
+	keys []KType
+	vals [][]VType
+}
+
+// search returns the index of the first key larger or equal to k.
+func (ref *refMultiRedBlack) search(k KType) int {
+	return sort.Search(len(ref.keys), func(i int) bool { return ref.r.compare(ref.keys[i], k) >= 0 })
+}
+
+func (ref *refMultiRedBlack) has(k KType) (int, bool) {
+	i := ref.search(k)
+	return i, i < len(ref.keys) && ref.r.compare(ref.keys[i], k) == 0
+}
+
+func (ref *refMultiRedBlack) put(k KType, vs []VType) {
+	if len(vs) == 0 {
+		return
+	}
+	i, ok := ref.has(k)
+	if ok {
+		ref.vals[i] = append(ref.vals[i], vs...)
+		return
+	}
+	ref.keys = append(ref.keys[:i], append([]KType{k}, ref.keys[i:]...)...)
+	ref.vals = append(ref.vals[:i], append([][]VType{append([]VType(nil), vs...)}, ref.vals[i:]...)...)
+}
+
+func (ref *refMultiRedBlack) delete(i int) {
+	ref.keys = append(ref.keys[:i], ref.keys[i+1:]...)
+	ref.vals = append(ref.vals[:i], ref.vals[i+1:]...)
+}
+
+// entries returns the keys/values from index i of the keys, each key once
+// for each of its values.
+func (ref *refMultiRedBlack) entries(i int) (keys []KType, vals []VType) {
+	for ; i < len(ref.keys); i++ {
+		for _, v := range ref.vals[i] {
+			keys, vals = append(keys, ref.keys[i]), append(vals, v)
+		}
+	}
+	return keys, vals
+}
+
+func TestMultiRedBlackMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	r := NewMultiRedBlack()
+	ref := &refMultiRedBlack{r: r}
+
+	checkEntry := func(op string, wantK KType, wantV VType, wantOK bool, k KType, v VType, ok bool) {
+		t.Helper()
+		if ok != wantOK {
+			t.Fatalf("%s: want ok=%v, got %v", op, wantOK, ok)
+		}
+		if ok && (r.compare(wantK, k) != 0 || !reflect.DeepEqual(wantV, v)) {
+			t.Fatalf("%s: want %v=%v, got %v=%v", op, wantK, wantV, k, v)
+		}
+	}
+
+	for n := 0; n < 5000; n++ {
+		// a narrower range of keys, for them to have several values
+		k := randomKType(rnd)
+		if len(ref.keys) != 0 && rnd.Intn(2) == 0 {
+			k = ref.keys[rnd.Intn(len(ref.keys))]
+		}
+		switch op := rnd.Intn(12); op {
+		case 0, 1, 2:
+			v := randomVType(rnd)
+			r.Put(k, v)
+			ref.put(k, []VType{v})
+		case 3:
+			vs := make([]VType, rnd.Intn(4))
+			for i := range vs {
+				vs[i] = randomVType(rnd)
+			}
+			r.PutAll(k, vs...)
+			ref.put(k, vs)
+		case 4:
+			i, ok := ref.has(k)
+			var want []VType
+			if ok {
+				want = ref.vals[i]
+			}
+			if got := r.GetAll(k); !reflect.DeepEqual(want, got) {
+				t.Fatalf("get all %v: want %v, got %v", k, want, got)
+			}
+			if r.Has(k) != ok || r.Count(k) != len(want) {
+				t.Fatalf("has %v: want %v and %d values, got %v and %d", k, ok, len(want), r.Has(k), r.Count(k))
+			}
+		case 5, 6:
+			i, ok := ref.has(k)
+			var want VType
+			if ok {
+				want = ref.vals[i][0]
+				if ref.vals[i] = ref.vals[i][1:]; len(ref.vals[i]) == 0 {
+					ref.delete(i)
+				}
+			}
+			v, got := r.DeleteOne(k)
+			checkEntry("delete one", k, want, ok, k, v, got)
+		case 7:
+			i, ok := ref.has(k)
+			var want []VType
+			if ok {
+				want = ref.vals[i]
+				ref.delete(i)
+			}
+			if got := r.DeleteAll(k); !reflect.DeepEqual(want, got) {
+				t.Fatalf("delete all %v: want %v, got %v", k, want, got)
+			}
+		case 8:
+			keys, vals := ref.entries(0)
+			mk, mv, ok := r.Min()
+			if len(keys) == 0 {
+				if _, _, maxOK := r.Max(); ok || maxOK {
+					t.Fatalf("min and max of an empty multimap: want none, got %v and %v", ok, maxOK)
+				}
+				break
+			}
+			checkEntry("min", keys[0], vals[0], true, mk, mv, ok)
+			mk, mv, ok = r.Max()
+			checkEntry("max", keys[len(keys)-1], vals[len(vals)-1], true, mk, mv, ok)
+		case 9:
+			keys, vals := ref.entries(0)
+			i := rnd.Intn(len(keys) + 2)
+			sk, sv, ok := r.Select(i)
+			if i < len(keys) {
+				checkEntry("select", keys[i], vals[i], true, sk, sv, ok)
+			} else if ok {
+				t.Fatalf("select %d of %d: want none, got %v=%v", i, len(keys), sk, sv)
+			}
+			before, _ := ref.entries(ref.search(k))
+			if want, got := len(keys)-len(before), r.Rank(k); want != got {
+				t.Fatalf("rank %v: want %d, got %d", k, want, got)
+			}
+		case 10, 11:
+			lo, hi := k, randomKType(rnd)
+			keys, vals := ref.entries(ref.search(lo))
+			i := 0
+			r.RangedKeys(lo, hi, func(k KType, v VType) bool {
+				if i == len(keys) {
+					t.Fatalf("ranged keys [%v, %v]: visited %v=%v past the end", lo, hi, k, v)
+				}
+				checkEntry("ranged keys", keys[i], vals[i], true, k, v, true)
+				i++
+				return true
+			})
+			if i < len(keys) && r.compare(keys[i], hi) <= 0 {
+				t.Fatalf("ranged keys [%v, %v]: missed %v", lo, hi, keys[i])
+			}
+		}
+		keys, _ := ref.entries(0)
+		if want, got := len(keys), r.Size(); want != got {
+			t.Fatalf("want size %d, got %d", want, got)
+		}
+		checkMultiRedBlack(t, r)
+	}
+}
@@ -0,0 +1,62 @@
+package redblackbst
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCanKeepValuesOfSameKey(t *testing.T) {
+	log := NewMultiRedBlack()
+	log.Put(Int(2), "b1")
+	log.PutAll(Int(1), "a1", "a2")
+	log.Put(Int(2), "b2")
+	log.PutAll(Int(3))
+
+	if want, got := []VType{"b1", "b2"}, log.GetAll(Int(2)); !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if log.Count(Int(1)) != 2 || log.Count(Int(3)) != 0 || log.Has(Int(3)) {
+		t.Errorf("want 2 values at 1 and none at 3, got %d and %d", log.Count(Int(1)), log.Count(Int(3)))
+	}
+	if log.Size() != 4 {
+		t.Errorf("want size 4, got %d", log.Size())
+	}
+
+	var got []VType
+	log.Keys(func(_ KType, v VType) bool {
+		got = append(got, v)
+		return true
+	})
+	if want := []VType{"a1", "a2", "b1", "b2"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	// ranks and selections count the values
+	if log.Rank(Int(2)) != 2 || log.Rank(Int(3)) != 4 {
+		t.Errorf("want ranks 2 and 4, got %d and %d", log.Rank(Int(2)), log.Rank(Int(3)))
+	}
+	if k, v, _ := log.Select(3); k != Int(2) || v != "b2" {
+		t.Errorf("select 3: want 2=b2, got %v=%v", k, v)
+	}
+}
+
+func TestCanDeleteValuesOfSameKey(t *testing.T) {
+	log := NewMultiRedBlack()
+	log.PutAll(Int(1), "a1", "a2", "a3")
+	log.PutAll(Int(2), "b1", "b2")
+
+	if v, ok := log.DeleteOne(Int(1)); !ok || v != "a1" {
+		t.Errorf("delete one: want a1, true, got %v, %v", v, ok)
+	}
+	if want, got := []VType{"a2", "a3"}, log.DeleteAll(Int(1)); !reflect.DeepEqual(want, got) {
+		t.Errorf("delete all: want %v, got %v", want, got)
+	}
+	if log.DeleteAll(Int(1)) != nil {
+		t.Errorf("delete all again: want nothing")
+	}
+	log.DeleteOne(Int(2))
+	log.DeleteOne(Int(2))
+	if _, ok := log.DeleteOne(Int(2)); ok || !log.IsEmpty() {
+		t.Errorf("want multimap empty, got %d values", log.Size())
+	}
+}
@@ -0,0 +1,462 @@
+package redblackbst
+
+// The implementation was forked from the one of the sorted maps
+// (map/redblackbst/rbbst.go in datagen), since every write has to copy the
+// nodes the map doesn't own: a fix to the balancing of either tree is to be
+// made to the other. The methods the sorted maps gained since are missing
+// here: Lower, Higher, ReverseKeys, RangedKeysDesc, RangeCount, BoundedKeys,
+// Cursor, DeleteRange, ToSlice, KeysSlice, ValuesSlice and the constructors
+// from sorted and unsorted slices.
+
+func (r PersistentRedBlack) compare(a, b KType) int { return a.Compare(b) }
+
+// PersistentRedBlack is a sorted map built on a left leaning red black
+// balanced search tree, whose versions are kept for free. It stores VType
+// values, keyed by KType. Writing to the sorted map copies the nodes on the
+// path to the key instead of modifying them, once they're shared with a
+// snapshot, so that the snapshots aren't affected by the writes.
+type PersistentRedBlack struct {
+	root *persistentnode
+	// owner marks the nodes created by the sorted map since its last
+	// snapshot, which nothing else refers to, and which are modified in
+	// place. The other nodes are copied before being modified.
+	owner *int
+}
+
+// NewPersistentRedBlack creates a persistent sorted map.
+func NewPersistentRedBlack() *PersistentRedBlack { return &PersistentRedBlack{} }
+
+// Snapshot returns the sorted map as it is, in O(1). The writes to the
+// sorted map and to the snapshot don't affect each other, so that the
+// snapshot can be read while the sorted map is written to, from another
+// goroutine, as long as the snapshot is taken by the writer.
+func (r *PersistentRedBlack) Snapshot() *PersistentRedBlack {
+	// the nodes are now shared, neither of them owns them anymore
+	r.owner = nil
+	snap := *r
+	return &snap
+}
+
+// IsEmpty tells if the sorted map contains no key/value.
+func (r PersistentRedBlack) IsEmpty() bool {
+	return r.root == nil
+}
+
+// Size of the sorted map.
+func (r PersistentRedBlack) Size() int { return r.root.size() }
+
+// Clear all the values in the sorted map.
+func (r *PersistentRedBlack) Clear() { r.root = nil }
+
+// Put a value in the sorted map at key `k`. The old value at `k` is returned
+// if the key was already present.
+func (r *PersistentRedBlack) Put(k KType, v VType) (old VType, overwrite bool) {
+	r.root, old, overwrite = r.put(r.root, k, v)
+	r.root.colorRed = false
+	return
+}
+
+func (r *PersistentRedBlack) put(h *persistentnode, k KType, v VType) (_ *persistentnode, old VType, overwrite bool) {
+	if h == nil {
+		n := &persistentnode{key: k, val: v, n: 1, colorRed: true, owner: r.ownerToken()}
+		return n, old, overwrite
+	}
+
+	h = r.own(h)
+	cmp := r.compare(k, h.key)
+	if cmp < 0 {
+		h.left, old, overwrite = r.put(h.left, k, v)
+	} else if cmp > 0 {
+		h.right, old, overwrite = r.put(h.right, k, v)
+	} else {
+		overwrite = true
+		old = h.val
+		h.val = v
+	}
+
+	if h.right.isRed() && !h.left.isRed() {
+		h = r.rotateLeft(h)
+	}
+	if h.left.isRed() && h.left.left.isRed() {
+		h = r.rotateRight(h)
+	}
+	if h.left.isRed() && h.right.isRed() {
+		r.flipColors(h)
+	}
+	h.n = h.left.size() + h.right.size() + 1
+	return h, old, overwrite
+}
+
+// Get a value from the sorted map at key `k`. Returns false
+// if the key doesn't exist.
+func (r PersistentRedBlack) Get(k KType) (VType, bool) {
+	return r.loopGet(r.root, k)
+}
+
+func (r PersistentRedBlack) loopGet(h *persistentnode, k KType) (v VType, ok bool) {
+	for h != nil {
+		cmp := r.compare(k, h.key)
+		if cmp == 0 {
+			return h.val, true
+		} else if cmp < 0 {
+			h = h.left
+		} else {
+			h = h.right
+		}
+	}
+	return
+}
+
+// Has tells if a value exists at key `k`. This is short hand for `Get.
+func (r PersistentRedBlack) Has(k KType) bool {
+	_, ok := r.loopGet(r.root, k)
+	return ok
+}
+
+// Min returns the smallest key/value in the sorted map, if it exists.
+func (r PersistentRedBlack) Min() (k KType, v VType, ok bool) {
+	if r.root == nil {
+		return
+	}
+	h := r.root
+	for h.left != nil {
+		h = h.left
+	}
+	return h.key, h.val, true
+}
+
+// Max returns the largest key/value in the sorted map, if it exists.
+func (r PersistentRedBlack) Max() (k KType, v VType, ok bool) {
+	if r.root == nil {
+		return
+	}
+	h := r.root
+	for h.right != nil {
+		h = h.right
+	}
+	return h.key, h.val, true
+}
+
+// Floor returns the largest key/value in the sorted map that is smaller than
+// or equal to `k`, if it exists.
+func (r PersistentRedBlack) Floor(key KType) (k KType, v VType, ok bool) {
+	var floor *persistentnode
+	for h := r.root; h != nil; {
+		cmp := r.compare(key, h.key)
+		if cmp < 0 {
+			h = h.left
+			continue
+		}
+		floor = h
+		if cmp == 0 {
+			break
+		}
+		h = h.right
+	}
+	if floor == nil {
+		return
+	}
+	return floor.key, floor.val, true
+}
+
+// Ceiling returns the smallest key/value in the sorted map that is larger than
+// or equal to `k`, if it exists.
+func (r PersistentRedBlack) Ceiling(key KType) (k KType, v VType, ok bool) {
+	var ceiling *persistentnode
+	for h := r.root; h != nil; {
+		cmp := r.compare(key, h.key)
+		if cmp > 0 {
+			h = h.right
+			continue
+		}
+		ceiling = h
+		if cmp == 0 {
+			break
+		}
+		h = h.left
+	}
+	if ceiling == nil {
+		return
+	}
+	return ceiling.key, ceiling.val, true
+}
+
+// Select key of rank k, meaning the k-th biggest KType in the sorted map.
+func (r PersistentRedBlack) Select(key int) (k KType, v VType, ok bool) {
+	for h := r.root; h != nil; {
+		t := h.left.size()
+		if t > key {
+			h = h.left
+		} else if t < key {
+			h, key = h.right, key-t-1
+		} else {
+			return h.key, h.val, true
+		}
+	}
+	return
+}
+
+// Rank is the number of keys less than `k`.
+func (r PersistentRedBlack) Rank(k KType) int {
+	rank := 0
+	for h := r.root; h != nil; {
+		cmp := r.compare(k, h.key)
+		if cmp < 0 {
+			h = h.left
+		} else if cmp > 0 {
+			rank += 1 + h.left.size()
+			h = h.right
+		} else {
+			return rank + h.left.size()
+		}
+	}
+	return rank
+}
+
+// Keys visit each keys in the sorted map, in order.
+// It stops when visit returns false.
+func (r PersistentRedBlack) Keys(visit func(KType, VType) bool) {
+	min, _, ok := r.Min()
+	if !ok {
+		return
+	}
+	// if the min exists, then the max must exist
+	max, _, _ := r.Max()
+	r.RangedKeys(min, max, visit)
+}
+
+// RangedKeys visit each keys between lo and hi in the sorted map, in order.
+// It stops when visit returns false.
+func (r PersistentRedBlack) RangedKeys(lo, hi KType, visit func(KType, VType) bool) {
+	r.keys(r.root, visit, lo, hi)
+}
+
+func (r PersistentRedBlack) keys(h *persistentnode, visit func(KType, VType) bool, lo, hi KType) bool {
+	if h == nil {
+		return true
+	}
+	cmplo := r.compare(lo, h.key)
+	cmphi := r.compare(hi, h.key)
+	if cmplo < 0 {
+		if !r.keys(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	if cmplo <= 0 && cmphi >= 0 {
+		if !visit(h.key, h.val) {
+			return false
+		}
+	}
+	if cmphi > 0 {
+		if !r.keys(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
+// DeleteMin removes the smallest key and its value from the sorted map.
+func (r *PersistentRedBlack) DeleteMin() (oldk KType, oldv VType, ok bool) {
+	r.root, oldk, oldv, ok = r.deleteMin(r.root)
+	if !r.IsEmpty() {
+		r.root.colorRed = false
+	}
+	return
+}
+
+func (r *PersistentRedBlack) deleteMin(h *persistentnode) (_ *persistentnode, oldk KType, oldv VType, ok bool) {
+	if h == nil {
+		return nil, oldk, oldv, false
+	}
+
+	if h.left == nil {
+		return nil, h.key, h.val, true
+	}
+	h = r.own(h)
+	if !h.left.isRed() && !h.left.left.isRed() {
+		h = r.moveRedLeft(h)
+	}
+	h.left, oldk, oldv, ok = r.deleteMin(h.left)
+	return r.balance(h), oldk, oldv, ok
+}
+
+// DeleteMax removes the largest key and its value from the sorted map.
+func (r *PersistentRedBlack) DeleteMax() (oldk KType, oldv VType, ok bool) {
+	r.root, oldk, oldv, ok = r.deleteMax(r.root)
+	if !r.IsEmpty() {
+		r.root.colorRed = false
+	}
+	return
+}
+
+func (r *PersistentRedBlack) deleteMax(h *persistentnode) (_ *persistentnode, oldk KType, oldv VType, ok bool) {
+	if h == nil {
+		return nil, oldk, oldv, ok
+	}
+	h = r.own(h)
+	if h.left.isRed() {
+		h = r.rotateRight(h)
+	}
+	if h.right == nil {
+		return nil, h.key, h.val, true
+	}
+	if !h.right.isRed() && !h.right.left.isRed() {
+		h = r.moveRedRight(h)
+	}
+	h.right, oldk, oldv, ok = r.deleteMax(h.right)
+	return r.balance(h), oldk, oldv, ok
+}
+
+// Delete key `k` from sorted map, if it exists. The nodes aren't copied if
+// it doesn't.
+func (r *PersistentRedBlack) Delete(k KType) (old VType, ok bool) {
+	if !r.Has(k) {
+		return
+	}
+	r.root, old, ok = r.delete(r.root, k)
+	if !r.IsEmpty() {
+		r.root.colorRed = false
+	}
+	return
+}
+
+// delete removes `k`, which is in the tree of h.
+func (r *PersistentRedBlack) delete(h *persistentnode, k KType) (_ *persistentnode, old VType, ok bool) {
+	h = r.own(h)
+	if r.compare(k, h.key) < 0 {
+		if !h.left.isRed() && !h.left.left.isRed() {
+			h = r.moveRedLeft(h)
+		}
+		h.left, old, ok = r.delete(h.left, k)
+		return r.balance(h), old, ok
+	}
+
+	if h.left.isRed() {
+		h = r.rotateRight(h)
+	}
+
+	if r.compare(k, h.key) == 0 && h.right == nil {
+		return nil, h.val, true
+	}
+
+	if !h.right.isRed() && !h.right.left.isRed() {
+		h = r.moveRedRight(h)
+	}
+
+	if r.compare(k, h.key) == 0 {
+		var subk KType
+		var subv VType
+		h.right, subk, subv, ok = r.deleteMin(h.right)
+		old, h.key, h.val = h.val, subk, subv
+	} else {
+		h.right, old, ok = r.delete(h.right, k)
+	}
+	return r.balance(h), old, ok
+}
+
+// copying
+
+// ownerToken marks the nodes the sorted map creates from now on as its own.
+func (r *PersistentRedBlack) ownerToken() *int {
+	if r.owner == nil {
+		r.owner = new(int)
+	}
+	return r.owner
+}
+
+// own returns h if the sorted map owns it, and otherwise a copy of h it owns,
+// which is modified instead of h.
+func (r *PersistentRedBlack) own(h *persistentnode) *persistentnode {
+	if h.owner == r.ownerToken() {
+		return h
+	}
+	c := *h
+	c.owner = r.owner
+	return &c
+}
+
+// The rotations and color flips below are given nodes the sorted map owns,
+// and own the nodes under them they modify.
+
+func (r *PersistentRedBlack) moveRedLeft(h *persistentnode) *persistentnode {
+	r.flipColors(h)
+	if h.right.left.isRed() {
+		h.right = r.rotateRight(h.right)
+		h = r.rotateLeft(h)
+		r.flipColors(h)
+	}
+	return h
+}
+
+func (r *PersistentRedBlack) moveRedRight(h *persistentnode) *persistentnode {
+	r.flipColors(h)
+	if h.left.left.isRed() {
+		h = r.rotateRight(h)
+		r.flipColors(h)
+	}
+	return h
+}
+
+func (r *PersistentRedBlack) balance(h *persistentnode) *persistentnode {
+	if h.right.isRed() {
+		h = r.rotateLeft(h)
+	}
+	if h.left.isRed() && h.left.left.isRed() {
+		h = r.rotateRight(h)
+	}
+	if h.left.isRed() && h.right.isRed() {
+		r.flipColors(h)
+	}
+	h.n = h.left.size() + h.right.size() + 1
+	return h
+}
+
+func (r *PersistentRedBlack) rotateLeft(h *persistentnode) *persistentnode {
+	x := r.own(h.right)
+	h.right = x.left
+	x.left = h
+	x.colorRed = h.colorRed
+	h.colorRed = true
+	x.n = h.n
+	h.n = 1 + h.left.size() + h.right.size()
+	return x
+}
+
+func (r *PersistentRedBlack) rotateRight(h *persistentnode) *persistentnode {
+	x := r.own(h.left)
+	h.left = x.right
+	x.right = h
+	x.colorRed = h.colorRed
+	h.colorRed = true
+	x.n = h.n
+	h.n = 1 + h.left.size() + h.right.size()
+	return x
+}
+
+func (r *PersistentRedBlack) flipColors(h *persistentnode) {
+	h.left, h.right = r.own(h.left), r.own(h.right)
+	h.colorRed = !h.colorRed
+	h.left.colorRed = !h.left.colorRed
+	h.right.colorRed = !h.right.colorRed
+}
+
+// nodes
+
+type persistentnode struct {
+	key         KType
+	val         VType
+	left, right *persistentnode
+	n           int
+	colorRed    bool
+	// owner is the owner of the sorted map that created the node
+	owner *int
+}
+
+func (x *persistentnode) isRed() bool { return (x != nil) && (x.colorRed == true) }
+
+func (x *persistentnode) size() int {
+	if x == nil {
+		return 0
+	}
+	return x.n
+}
@@ -0,0 +1,91 @@
+package redblackbst
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// The benchmarks of this file are generated along with persistent sorted
+// maps, when asked to. The keys and values are generated by benchKType and
+// benchVType.
+
+// benchPersistentRedBlackSizes are the numbers of keys in the benchmarked
+// maps.
+var benchPersistentRedBlackSizes = []int{100, 10000, 1000000}
+
+// benchPersistentRedBlack runs bench for each size, with a map holding that
+// many random keys, and the keys and values put in it. The keys are the same
+// from one benchmark to the other.
+func benchPersistentRedBlack(b *testing.B, bench func(b *testing.B, r *PersistentRedBlack, keys []KType, vals []VType)) {
+	for _, n := range benchPersistentRedBlackSizes {
+		n := n
+		var r *PersistentRedBlack
+		var keys []KType
+		var vals []VType
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			// once for all the runs of the benchmark, and only if it's run
+			if r == nil {
+				rnd := rand.New(rand.NewSource(int64(n)))
+				keys = make([]KType, n)
+				vals = make([]VType, n)
+				for i := range keys {
+					keys[i], vals[i] = benchKType(rnd), benchVType(rnd)
+				}
+				r = NewPersistentRedBlack()
+				for i, k := range keys {
+					r.Put(k, vals[i])
+				}
+			}
+			// the benchmarks write to a snapshot, leaving r as it is
+			bench(b, r.Snapshot(), keys, vals)
+		})
+	}
+}
+
+// BenchmarkPersistentRedBlackPut overwrites the keys, without snapshots but
+// for the first one.
+func BenchmarkPersistentRedBlackPut(b *testing.B) {
+	benchPersistentRedBlack(b, func(b *testing.B, r *PersistentRedBlack, keys []KType, vals []VType) {
+		n := len(keys)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Put(keys[i%n], vals[i%n])
+		}
+	})
+}
+
+// BenchmarkPersistentRedBlackPutSnapshot overwrites the keys, taking a
+// snapshot before each put, which copies its path.
+func BenchmarkPersistentRedBlackPutSnapshot(b *testing.B) {
+	benchPersistentRedBlack(b, func(b *testing.B, r *PersistentRedBlack, keys []KType, vals []VType) {
+		n := len(keys)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Snapshot()
+			r.Put(keys[i%n], vals[i%n])
+		}
+	})
+}
+
+func BenchmarkPersistentRedBlackGet(b *testing.B) {
+	benchPersistentRedBlack(b, func(b *testing.B, r *PersistentRedBlack, keys []KType, vals []VType) {
+		n := len(keys)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Get(keys[i%n])
+		}
+	})
+}
+
+// BenchmarkPersistentRedBlackDeleteSnapshot deletes a key from a snapshot of
+// the map at each iteration, leaving the map as it is.
+func BenchmarkPersistentRedBlackDeleteSnapshot(b *testing.B) {
+	benchPersistentRedBlack(b, func(b *testing.B, r *PersistentRedBlack, keys []KType, vals []VType) {
+		n := len(keys)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Snapshot().Delete(keys[i%n])
+		}
+	})
+}
@@ -0,0 +1,377 @@
+package redblackbst
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// The tests of this file are generated along with persistent sorted maps,
+// when asked to. The keys and values are generated by randomKType and
+// randomVType.
+
+// checkPersistentRedBlack verifies the invariants of the tree: the keys are
+// in order, the sizes of the subtrees are right, red links lean left, no node
+// has two red links and every path from the root to a leaf has as many black
+// links.
+func checkPersistentRedBlack(t *testing.T, r *PersistentRedBlack) {
+	if r.root.isRed() {
+		t.Fatal("root is red")
+	}
+	checkPersistentRedBlackNode(t, r, r.root)
+
+	var prev *KType
+	r.Keys(func(k KType, _ VType) bool {
+		if prev != nil && r.compare(*prev, k) >= 0 {
+			t.Fatalf("keys out of order: %v before %v", *prev, k)
+		}
+		prev = &k
+		return true
+	})
+}
+
+// checkPersistentRedBlackNode returns the number of black links from x to
+// the leaves.
+func checkPersistentRedBlackNode(t *testing.T, r *PersistentRedBlack, x *persistentnode) int {
+	if x == nil {
+		return 0
+	}
+	if x.right.isRed() {
+		t.Fatalf("red link leans right under %v", x.key)
+	}
+	if x.isRed() && x.left.isRed() {
+		t.Fatalf("two red links in a row under %v", x.key)
+	}
+	if want := 1 + x.left.size() + x.right.size(); x.n != want {
+		t.Fatalf("size of %v: want %d, got %d", x.key, want, x.n)
+	}
+	black := checkPersistentRedBlackNode(t, r, x.left)
+	if right := checkPersistentRedBlackNode(t, r, x.right); black != right {
+		t.Fatalf("black links under %v: %d on the left, %d on the right", x.key, black, right)
+	}
+	if !x.isRed() {
+		black++
+	}
+	return black
+}
+
+// refPersistentRedBlack is a naive sorted map keeping its entries in a sorted
+// slice, ordered like r.
+type refPersistentRedBlack struct {
+	r    *PersistentRedBlack
+	keys []KType
+	vals []VType
+}
+
+// search returns the index of the first key larger or equal to k.
+func (ref *refPersistentRedBlack) search(k KType) int {
+	return sort.Search(len(ref.keys), func(i int) bool { return ref.r.compare(ref.keys[i], k) >= 0 })
+}
+
+func (ref *refPersistentRedBlack) has(k KType) (int, bool) {
+	i := ref.search(k)
+	return i, i < len(ref.keys) && ref.r.compare(ref.keys[i], k) == 0
+}
+
+func (ref *refPersistentRedBlack) put(k KType, v VType) {
+	i, ok := ref.has(k)
+	if ok {
+		ref.vals[i] = v
+		return
+	}
+	ref.keys = append(ref.keys[:i], append([]KType{k}, ref.keys[i:]...)...)
+	ref.vals = append(ref.vals[:i], append([]VType{v}, ref.vals[i:]...)...)
+}
+
+func (ref *refPersistentRedBlack) delete(i int) {
+	ref.keys = append(ref.keys[:i], ref.keys[i+1:]...)
+	ref.vals = append(ref.vals[:i], ref.vals[i+1:]...)
+}
+
+// snapshot returns a copy of ref, for a snapshot of its sorted map.
+func (ref *refPersistentRedBlack) snapshot(r *PersistentRedBlack) *refPersistentRedBlack {
+	return &refPersistentRedBlack{
+		r:    r,
+		keys: append([]KType(nil), ref.keys...),
+		vals: append([]VType(nil), ref.vals...),
+	}
+}
+
+// diff returns a description of the first difference between the entries of
+// ref and those of its sorted map, or an empty string if they're the same.
+func (ref *refPersistentRedBlack) diff() string {
+	if want, got := len(ref.keys), ref.r.Size(); want != got {
+		return fmt.Sprintf("want size %d, got %d", want, got)
+	}
+	var diff string
+	i := 0
+	ref.r.Keys(func(k KType, v VType) bool {
+		if ref.r.compare(ref.keys[i], k) != 0 || !reflect.DeepEqual(ref.vals[i], v) {
+			diff = fmt.Sprintf("entry %d: want %v=%v, got %v=%v", i, ref.keys[i], ref.vals[i], k, v)
+			return false
+		}
+		i++
+		return true
+	})
+	return diff
+}
+
+func TestPersistentRedBlackMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	r := NewPersistentRedBlack()
+	ref := &refPersistentRedBlack{r: r}
+	// the snapshots taken along the way, each with its own reference
+	var snaps []*refPersistentRedBlack
+
+	checkEntry := func(op string, i int, k KType, v VType, ok bool) {
+		t.Helper()
+		wantOK := i >= 0 && i < len(ref.keys)
+		if ok != wantOK {
+			t.Fatalf("%s: want ok=%v, got %v", op, wantOK, ok)
+		}
+		if !ok {
+			return
+		}
+		if r.compare(ref.keys[i], k) != 0 || !reflect.DeepEqual(ref.vals[i], v) {
+			t.Fatalf("%s: want %v=%v, got %v=%v", op, ref.keys[i], ref.vals[i], k, v)
+		}
+	}
+
+	for n := 0; n < 5000; n++ {
+		k := randomKType(rnd)
+		switch op := rnd.Intn(13); op {
+		case 0, 1, 2, 3:
+			v := randomVType(rnd)
+			i, had := ref.has(k)
+			var want VType
+			if had {
+				want = ref.vals[i]
+			}
+			old, overwrite := r.Put(k, v)
+			if overwrite != had || !reflect.DeepEqual(old, want) {
+				t.Fatalf("put %v: want %v, %v, got %v, %v", k, want, had, old, overwrite)
+			}
+			ref.put(k, v)
+		case 4:
+			i, ok := ref.has(k)
+			v, got := r.Get(k)
+			if !ok {
+				i = -1
+			}
+			checkEntry("get", i, k, v, got)
+			if r.Has(k) != ok {
+				t.Fatalf("has %v: want %v", k, ok)
+			}
+		case 5:
+			i, ok := ref.has(k)
+			v, got := r.Delete(k)
+			if !ok {
+				i = -1
+			}
+			checkEntry("delete", i, k, v, got)
+			if ok {
+				ref.delete(i)
+			}
+		case 6:
+			dk, dv, ok := r.DeleteMin()
+			checkEntry("delete min", 0, dk, dv, ok)
+			if ok {
+				ref.delete(0)
+			}
+		case 7:
+			dk, dv, ok := r.DeleteMax()
+			checkEntry("delete max", len(ref.keys)-1, dk, dv, ok)
+			if ok {
+				ref.delete(len(ref.keys) - 1)
+			}
+		case 8:
+			mk, mv, ok := r.Min()
+			checkEntry("min", 0, mk, mv, ok)
+			mk, mv, ok = r.Max()
+			checkEntry("max", len(ref.keys)-1, mk, mv, ok)
+			i, ok := ref.has(k)
+			if !ok {
+				i--
+			}
+			fk, fv, ok := r.Floor(k)
+			checkEntry("floor", i, fk, fv, ok)
+			ck, cv, ok := r.Ceiling(k)
+			checkEntry("ceiling", ref.search(k), ck, cv, ok)
+		case 9:
+			if want, got := ref.search(k), r.Rank(k); want != got {
+				t.Fatalf("rank %v: want %d, got %d", k, want, got)
+			}
+			i := rnd.Intn(len(ref.keys) + 2)
+			sk, sv, ok := r.Select(i)
+			checkEntry("select", i, sk, sv, ok)
+		case 10:
+			lo, hi := k, randomKType(rnd)
+			i := ref.search(lo)
+			r.RangedKeys(lo, hi, func(k KType, v VType) bool {
+				checkEntry("ranged keys", i, k, v, true)
+				i++
+				return true
+			})
+			if i < len(ref.keys) && r.compare(ref.keys[i], hi) <= 0 {
+				t.Fatalf("ranged keys [%v, %v]: missed %v", lo, hi, ref.keys[i])
+			}
+		case 11:
+			snap := r.Snapshot()
+			snaps = append(snaps, ref.snapshot(snap))
+		case 12:
+			if len(snaps) == 0 {
+				break
+			}
+			// the snapshots can be written to as well, without affecting
+			// the sorted map nor the other snapshots
+			snap := snaps[rnd.Intn(len(snaps))]
+			if i, ok := snap.has(k); ok && rnd.Intn(2) == 0 {
+				snap.r.Delete(k)
+				snap.delete(i)
+			} else {
+				v := randomVType(rnd)
+				snap.r.Put(k, v)
+				snap.put(k, v)
+			}
+			checkPersistentRedBlack(t, snap.r)
+		}
+		if want, got := len(ref.keys), r.Size(); want != got {
+			t.Fatalf("want size %d, got %d", want, got)
+		}
+		checkPersistentRedBlack(t, r)
+
+		if n%500 == 0 {
+			for i, snap := range snaps {
+				if diff := snap.diff(); diff != "" {
+					t.Fatalf("snapshot %d of %d: %s", i, len(snaps), diff)
+				}
+			}
+		}
+	}
+
+	if diff := ref.diff(); diff != "" {
+		t.Fatal(diff)
+	}
+	for i, snap := range snaps {
+		checkPersistentRedBlack(t, snap.r)
+		if diff := snap.diff(); diff != "" {
+			t.Fatalf("snapshot %d of %d: %s", i, len(snaps), diff)
+		}
+	}
+}
+
+// nodesOfPersistentRedBlack returns the nodes of the tree of x.
+func nodesOfPersistentRedBlack(x *persistentnode, nodes map[*persistentnode]bool) map[*persistentnode]bool {
+	if x != nil {
+		nodes[x] = true
+		nodesOfPersistentRedBlack(x.left, nodes)
+		nodesOfPersistentRedBlack(x.right, nodes)
+	}
+	return nodes
+}
+
+// TestPersistentRedBlackSharesNodes verifies that a write after a snapshot
+// only copies the nodes around the path to its key, the sorted map and the
+// snapshot sharing the others.
+func TestPersistentRedBlackSharesNodes(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	r := NewPersistentRedBlack()
+	for i := 0; i < 1000; i++ {
+		r.Put(randomKType(rnd), randomVType(rnd))
+	}
+	// a path has at most twice as many links as it has black links, and the
+	// children of the nodes on the path may be copied along with them
+	var black int
+	for x := r.root; x != nil; x = x.left {
+		if !x.isRed() {
+			black++
+		}
+	}
+	maxCopied := 3 * (2*black + 1)
+
+	for n := 0; n < 200; n++ {
+		snap := r.Snapshot()
+		before := nodesOfPersistentRedBlack(snap.root, make(map[*persistentnode]bool))
+		ref := &refPersistentRedBlack{r: snap}
+		snap.Keys(func(k KType, v VType) bool {
+			ref.keys, ref.vals = append(ref.keys, k), append(ref.vals, v)
+			return true
+		})
+
+		k := randomKType(rnd)
+		op := "put"
+		if min, _, _ := r.Min(); n%2 == 0 {
+			op = "delete"
+			r.Delete(min)
+		} else {
+			r.Put(k, randomVType(rnd))
+		}
+		checkPersistentRedBlack(t, r)
+
+		after := nodesOfPersistentRedBlack(r.root, make(map[*persistentnode]bool))
+		copied := 0
+		for x := range after {
+			if !before[x] {
+				copied++
+			}
+		}
+		if copied > maxCopied {
+			t.Fatalf("%s: want at most %d nodes copied, got %d", op, maxCopied, copied)
+		}
+		if diff := ref.diff(); diff != "" {
+			t.Fatalf("%s: the snapshot changed: %s", op, diff)
+		}
+
+		// without another snapshot, the nodes copied are modified in place
+		if op == "put" {
+			r.Put(k, randomVType(rnd))
+			for x := range nodesOfPersistentRedBlack(r.root, make(map[*persistentnode]bool)) {
+				if !after[x] {
+					t.Fatalf("put again: %v was copied again", x.key)
+				}
+			}
+		}
+	}
+}
+
+// TestPersistentRedBlackSnapshotsReadConcurrently reads snapshots while the
+// sorted map is written to, which the race detector verifies.
+func TestPersistentRedBlackSnapshotsReadConcurrently(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	r := NewPersistentRedBlack()
+	ref := &refPersistentRedBlack{r: r}
+
+	snaps := make(chan *refPersistentRedBlack)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for snap := range snaps {
+				if diff := snap.diff(); diff != "" {
+					t.Errorf("snapshot of %d keys: %s", len(snap.keys), diff)
+				}
+			}
+		}()
+	}
+
+	for n := 0; n < 2000; n++ {
+		k := randomKType(rnd)
+		if i, ok := ref.has(k); ok && rnd.Intn(3) == 0 {
+			r.Delete(k)
+			ref.delete(i)
+		} else {
+			v := randomVType(rnd)
+			r.Put(k, v)
+			ref.put(k, v)
+		}
+		if n%10 == 0 {
+			snaps <- ref.snapshot(r.Snapshot())
+		}
+	}
+	close(snaps)
+	wg.Wait()
+}
@@ -0,0 +1,90 @@
+package redblackbst
+
+import (
+	"reflect"
+	"testing"
+)
+
+func persistentKeys(r *PersistentRedBlack) []int {
+	keys := []int{}
+	r.Keys(func(k KType, _ VType) bool {
+		keys = append(keys, int(k.(Int)))
+		return true
+	})
+	return keys
+}
+
+func TestSnapshotIsUnaffectedByWrites(t *testing.T) {
+	tree := NewPersistentRedBlack()
+	for i := 1; i <= 5; i++ {
+		tree.Put(Int(i), "v1")
+	}
+	snap := tree.Snapshot()
+
+	tree.Put(Int(6), "v2")
+	tree.Put(Int(3), "v2")
+	tree.Delete(Int(1))
+	tree.DeleteMax()
+
+	if want, got := []int{1, 2, 3, 4, 5}, persistentKeys(snap); !reflect.DeepEqual(want, got) {
+		t.Logf("want=%#v", want)
+		t.Logf(" got=%#v", got)
+		t.Errorf("mismatch!")
+	}
+	if v, _ := snap.Get(Int(3)); v != "v1" {
+		t.Errorf("snapshot value at 3: want v1, got %v", v)
+	}
+	if want, got := []int{2, 3, 4, 5}, persistentKeys(tree); !reflect.DeepEqual(want, got) {
+		t.Logf("want=%#v", want)
+		t.Logf(" got=%#v", got)
+		t.Errorf("mismatch!")
+	}
+	if v, _ := tree.Get(Int(3)); v != "v2" {
+		t.Errorf("value at 3: want v2, got %v", v)
+	}
+
+	// and the other way around
+	snap.Clear()
+	if !snap.IsEmpty() || tree.Size() != 4 {
+		t.Errorf("clearing the snapshot: want it empty and 4 keys left, got %d and %d", snap.Size(), tree.Size())
+	}
+}
+
+func TestPersistentTreeEmpty(t *testing.T) {
+	tree := NewPersistentRedBlack()
+	if _, _, ok := tree.Min(); ok {
+		t.Errorf("empty tree should have no min")
+	}
+	if _, _, ok := tree.Max(); ok {
+		t.Errorf("empty tree should have no max")
+	}
+	if _, _, ok := tree.DeleteMin(); ok {
+		t.Errorf("empty tree should have no min to delete")
+	}
+	if _, _, ok := tree.DeleteMax(); ok {
+		t.Errorf("empty tree should have no max to delete")
+	}
+	if _, ok := tree.Delete(Int(1)); ok {
+		t.Errorf("empty tree should have nothing to delete")
+	}
+	if got := persistentKeys(tree.Snapshot()); len(got) != 0 {
+		t.Errorf("want no keys, got %v", got)
+	}
+}
+
+func TestCanAbortVisitingPersistentKeys(t *testing.T) {
+	tree := NewPersistentRedBlack()
+	for i := 0; i < 100; i++ {
+		tree.Put(Int(i), i)
+	}
+	var got []int
+	tree.Keys(func(k KType, _ VType) bool {
+		got = append(got, int(k.(Int)))
+		return len(got) < 10
+	})
+	if want := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}; !reflect.DeepEqual(want, got) {
+		t.Logf("want=%#v", want)
+		t.Logf(" got=%#v", got)
+		t.Errorf("mismatch!")
+	}
+}
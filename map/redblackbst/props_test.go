@@ -0,0 +1,602 @@
+package redblackbst
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// The tests of this file are generated along with sorted maps, when asked
+// to. The keys and values are generated by randomKType and randomVType. The
+// aggregates of augmented sorted maps are computed with the measure and
+// combine methods of the sorted map, which must be associative for the
+// aggregates to be compared, exactly unless they're floating point numbers.
+
+// checkRedBlack verifies the invariants of the tree: the keys are in order,
+// the sizes of the subtrees are right, red links lean left, no node has two
+// red links and every path from the root to a leaf has as many black links.
+func checkRedBlack(t *testing.T, r *RedBlack) {
+	if r.root.isRed() {
+		t.Fatal("root is red")
+	}
+	checkRedBlackNode(t, r, r.root)
+
+	var prev *KType
+	r.Keys(func(k KType, _ VType) bool {
+		if prev != nil && r.compare(*prev, k) >= 0 {
+			t.Fatalf("keys out of order: %v before %v", *prev, k)
+		}
+		prev = &k
+		return true
+	})
+}
+
+// checkRedBlackNode returns the number of black links from x to the leaves.
+func checkRedBlackNode(t *testing.T, r *RedBlack, x *mapnode) int {
+	if x == nil {
+		return 0
+	}
+	if x.right.isRed() {
+		t.Fatalf("red link leans right under %v", x.key)
+	}
+	if x.isRed() && x.left.isRed() {
+		t.Fatalf("two red links in a row under %v", x.key)
+	}
+	if want := 1 + x.left.size() + x.right.size(); x.n != want {
+		t.Fatalf("size of %v: want %d, got %d", x.key, want, x.n)
+	}
+	black := checkRedBlackNode(t, r, x.left)
+	if right := checkRedBlackNode(t, r, x.right); black != right {
+		t.Fatalf("black links under %v: %d on the left, %d on the right", x.key, black, right)
+	}
+	if !x.isRed() {
+		black++
+	}
+	return black
+}
+
+// refRedBlack is a naive sorted map keeping its entries in a sorted slice,
+// ordered like r.
+type refRedBlack struct {
+	r    *RedBlack
+	keys []KType
+	vals []VType
+}
+
+// search returns the index of the first key larger or equal to k.
+func (ref *refRedBlack) search(k KType) int {
+	return sort.Search(len(ref.keys), func(i int) bool { return ref.r.compare(ref.keys[i], k) >= 0 })
+}
+
+func (ref *refRedBlack) has(k KType) (int, bool) {
+	i := ref.search(k)
+	return i, i < len(ref.keys) && ref.r.compare(ref.keys[i], k) == 0
+}
+
+func (ref *refRedBlack) put(k KType, v VType) {
+	i, ok := ref.has(k)
+	if ok {
+		ref.vals[i] = v
+		return
+	}
+	ref.keys = append(ref.keys[:i], append([]KType{k}, ref.keys[i:]...)...)
+	ref.vals = append(ref.vals[:i], append([]VType{v}, ref.vals[i:]...)...)
+}
+
+func (ref *refRedBlack) delete(i int) {
+	ref.keys = append(ref.keys[:i], ref.keys[i+1:]...)
+	ref.vals = append(ref.vals[:i], ref.vals[i+1:]...)
+}
+
+// bounds returns the indexes of the first key between lo and hi, and of the
+// first key past them.
+func (ref *refRedBlack) bounds(lo, hi Bound) (from, to int) {
+	to = len(ref.keys)
+	if !lo.Unbounded {
+		var has bool
+		if from, has = ref.has(lo.Key); has && lo.Exclusive {
+			from++
+		}
+	}
+	if !hi.Unbounded {
+		var has bool
+		if to, has = ref.has(hi.Key); has && !hi.Exclusive {
+			to++
+		}
+	}
+	if to < from {
+		to = from
+	}
+	return from, to
+}
+
+// randomRedBlackBound returns an end of a range of keys, of any kind.
+func randomRedBlackBound(rnd *rand.Rand) Bound {
+	switch rnd.Intn(5) {
+	case 0:
+		return Bound{Unbounded: true}
+	case 1:
+		return Bound{Key: randomKType(rnd), Exclusive: true}
+	default:
+		return Bound{Key: randomKType(rnd)}
+	}
+}
+
+func TestRedBlackMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	r := NewRedBlack()
+	ref := &refRedBlack{r: r}
+
+	checkEntry := func(op string, i int, k KType, v VType, ok bool) {
+		t.Helper()
+		wantOK := i >= 0 && i < len(ref.keys)
+		if ok != wantOK {
+			t.Fatalf("%s: want ok=%v, got %v", op, wantOK, ok)
+		}
+		if !ok {
+			return
+		}
+		if r.compare(ref.keys[i], k) != 0 || !reflect.DeepEqual(ref.vals[i], v) {
+			t.Fatalf("%s: want %v=%v, got %v=%v", op, ref.keys[i], ref.vals[i], k, v)
+		}
+	}
+
+	for n := 0; n < 5000; n++ {
+		k := randomKType(rnd)
+		switch op := rnd.Intn(14); op {
+		case 0, 1, 2, 3:
+			v := randomVType(rnd)
+			i, had := ref.has(k)
+			var want VType
+			if had {
+				want = ref.vals[i]
+			}
+			old, overwrite := r.Put(k, v)
+			if overwrite != had || !reflect.DeepEqual(old, want) {
+				t.Fatalf("put %v: want %v, %v, got %v, %v", k, want, had, old, overwrite)
+			}
+			ref.put(k, v)
+		case 4:
+			i, ok := ref.has(k)
+			v, got := r.Get(k)
+			if !ok {
+				i = -1
+			}
+			checkEntry("get", i, k, v, got)
+			if r.Has(k) != ok {
+				t.Fatalf("has %v: want %v", k, ok)
+			}
+		case 5:
+			i, ok := ref.has(k)
+			v, got := r.Delete(k)
+			if !ok {
+				i = -1
+			}
+			checkEntry("delete", i, k, v, got)
+			if ok {
+				ref.delete(i)
+			}
+		case 6:
+			dk, dv, ok := r.DeleteMin()
+			checkEntry("delete min", 0, dk, dv, ok)
+			if ok {
+				ref.delete(0)
+			}
+		case 7:
+			dk, dv, ok := r.DeleteMax()
+			checkEntry("delete max", len(ref.keys)-1, dk, dv, ok)
+			if ok {
+				ref.delete(len(ref.keys) - 1)
+			}
+		case 8:
+			mk, mv, ok := r.Min()
+			checkEntry("min", 0, mk, mv, ok)
+			mk, mv, ok = r.Max()
+			checkEntry("max", len(ref.keys)-1, mk, mv, ok)
+		case 9:
+			i, ok := ref.has(k)
+			if !ok {
+				i--
+			}
+			fk, fv, ok := r.Floor(k)
+			checkEntry("floor", i, fk, fv, ok)
+			ck, cv, ok := r.Ceiling(k)
+			checkEntry("ceiling", ref.search(k), ck, cv, ok)
+			lk, lv, ok := r.Lower(k)
+			checkEntry("lower", ref.search(k)-1, lk, lv, ok)
+			i, ok = ref.has(k)
+			if ok {
+				i++
+			}
+			hk, hv, ok := r.Higher(k)
+			checkEntry("higher", i, hk, hv, ok)
+		case 10:
+			if want, got := ref.search(k), r.Rank(k); want != got {
+				t.Fatalf("rank %v: want %d, got %d", k, want, got)
+			}
+			i := rnd.Intn(len(ref.keys) + 2)
+			sk, sv, ok := r.Select(i)
+			checkEntry("select", i, sk, sv, ok)
+		case 11:
+			lo, hi := k, randomKType(rnd)
+			i := ref.search(lo)
+			r.RangedKeys(lo, hi, func(k KType, v VType) bool {
+				checkEntry("ranged keys", i, k, v, true)
+				i++
+				return true
+			})
+			if i < len(ref.keys) && r.compare(ref.keys[i], hi) <= 0 {
+				t.Fatalf("ranged keys [%v, %v]: missed %v", lo, hi, ref.keys[i])
+			}
+		case 12:
+			lo, hi := randomRedBlackBound(rnd), randomRedBlackBound(rnd)
+			from, to := ref.bounds(lo, hi)
+			if got := r.RangeCount(lo, hi); got != to-from {
+				t.Fatalf("range count %v, %v: want %d, got %d", lo, hi, to-from, got)
+			}
+			i := from
+			r.BoundedKeys(lo, hi, func(k KType, v VType) bool {
+				if i == to {
+					t.Fatalf("bounded keys %v, %v: want no more keys, got %v", lo, hi, k)
+				}
+				checkEntry("bounded keys", i, k, v, true)
+				i++
+				return true
+			})
+			if i != to {
+				t.Fatalf("bounded keys %v, %v: missed %v", lo, hi, ref.keys[i])
+			}
+		case 13:
+			if len(ref.keys) == 0 {
+				break
+			}
+			// a few neighbouring keys, so that the tree keeps growing
+			i := rnd.Intn(len(ref.keys))
+			j := i + rnd.Intn(2)
+			if j >= len(ref.keys) {
+				j = len(ref.keys) - 1
+			}
+			lo := Bound{Key: ref.keys[i], Exclusive: rnd.Intn(2) == 0}
+			hi := Bound{Key: ref.keys[j], Exclusive: rnd.Intn(2) == 0}
+			if rnd.Intn(100) == 0 {
+				lo, hi = randomRedBlackBound(rnd), randomRedBlackBound(rnd)
+			}
+			from, to := ref.bounds(lo, hi)
+			if got := r.DeleteRange(lo, hi); got != to-from {
+				t.Fatalf("delete range %v, %v: want %d deleted, got %d", lo, hi, to-from, got)
+			}
+			ref.keys = append(ref.keys[:from], ref.keys[to:]...)
+			ref.vals = append(ref.vals[:from], ref.vals[to:]...)
+		}
+		if want, got := len(ref.keys), r.Size(); want != got {
+			t.Fatalf("want size %d, got %d", want, got)
+		}
+		checkRedBlack(t, r)
+	}
+
+	i := 0
+	r.Keys(func(k KType, v VType) bool {
+		checkEntry("keys", i, k, v, true)
+		i++
+		return true
+	})
+	if i != len(ref.keys) {
+		t.Fatalf("keys: want %d keys, got %d", len(ref.keys), i)
+	}
+}
+
+// checkRedBlackAggregates verifies that the nodes of the tree of x hold the
+// aggregates of the keys/values of their trees.
+func checkRedBlackAggregates(t *testing.T, r *RedBlack, x *mapnode) {
+	if x == nil {
+		return
+	}
+	checkRedBlackAggregates(t, r, x.left)
+	checkRedBlackAggregates(t, r, x.right)
+	want := r.measure(x.key, x.val)
+	if x.left != nil {
+		want = r.combine(x.left.agg, want)
+	}
+	if x.right != nil {
+		want = r.combine(want, x.right.agg)
+	}
+	if !reflect.DeepEqual(want, x.agg) {
+		t.Fatalf("aggregate of %v: want %v, got %v", x.key, want, x.agg)
+	}
+}
+
+// aggregate combines the entries from i to j excluded, one by one. scale is
+// the largest magnitude of the floating point aggregates measured and
+// combined along the way, if they are.
+func (ref *refRedBlack) aggregate(i, j int) (agg AType, ok bool, scale float64) {
+	for ; i < j; i++ {
+		m := ref.r.measure(ref.keys[i], ref.vals[i])
+		scale = scaleOfRedBlackAggregates(scale, m)
+		if ok {
+			m = ref.r.combine(agg, m)
+		}
+		agg, ok = m, true
+		scale = scaleOfRedBlackAggregates(scale, agg)
+	}
+	return
+}
+
+// scaleOfRedBlackAggregates returns the largest of scale and the magnitude
+// of a, if it's a floating point number.
+func scaleOfRedBlackAggregates(scale float64, a AType) float64 {
+	f, isFloat := floatOfRedBlackAggregate(a)
+	if f < 0 {
+		f = -f
+	}
+	if isFloat && f > scale {
+		return f
+	}
+	return scale
+}
+
+// floatOfRedBlackAggregate returns the value of a, if it's a floating point
+// number.
+func floatOfRedBlackAggregate(a AType) (float64, bool) {
+	v := reflect.ValueOf(a)
+	if v.IsValid() && (v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64) {
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// equalRedBlackAggregates tells if the aggregate got is the one wanted.
+// Floating point aggregates combined in another order than the reference are
+// rounded otherwise, so they only need to be close, relative to the scale of
+// the aggregates the reference combined.
+func equalRedBlackAggregates(want, got AType, scale float64) bool {
+	w, isFloat := floatOfRedBlackAggregate(want)
+	if !isFloat {
+		return reflect.DeepEqual(want, got)
+	}
+	g, _ := floatOfRedBlackAggregate(got)
+	tolerance := 1e-9
+	if reflect.ValueOf(want).Kind() == reflect.Float32 {
+		tolerance = 1e-3
+	}
+	d := w - g
+	if d < 0 {
+		d = -d
+	}
+	return w == g || d <= tolerance*scale
+}
+
+// TestRedBlackAggregatesMatchReference verifies the aggregates of augmented
+// sorted maps, which are compared to those combined one entry at a time, and
+// must then be the same, or close for floating point aggregates.
+func TestRedBlackAggregatesMatchReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	r := NewRedBlack()
+	ref := &refRedBlack{r: r}
+
+	checkAggregate := func(op string, want AType, wantOK bool, scale float64, got AType, ok bool) {
+		t.Helper()
+		if ok != wantOK || !equalRedBlackAggregates(want, got, scale) {
+			t.Fatalf("%s: want %v, %v, got %v, %v", op, want, wantOK, got, ok)
+		}
+	}
+
+	for n := 0; n < 5000; n++ {
+		k := randomKType(rnd)
+		switch op := rnd.Intn(8); op {
+		case 0, 1, 2:
+			v := randomVType(rnd)
+			r.Put(k, v)
+			ref.put(k, v)
+		case 3:
+			if i, ok := ref.has(k); ok {
+				ref.delete(i)
+			}
+			r.Delete(k)
+		case 4:
+			if _, _, ok := r.DeleteMin(); ok {
+				ref.delete(0)
+			}
+			if _, _, ok := r.DeleteMax(); ok {
+				ref.delete(len(ref.keys) - 1)
+			}
+		case 5:
+			if len(ref.keys) == 0 {
+				break
+			}
+			// a few neighbouring keys, so that the tree keeps growing
+			i := rnd.Intn(len(ref.keys))
+			lo, hi := Bound{Key: ref.keys[i]}, Bound{Key: ref.keys[i], Exclusive: true}
+			if i+1 < len(ref.keys) {
+				hi.Key = ref.keys[i+1]
+			}
+			from, to := ref.bounds(lo, hi)
+			r.DeleteRange(lo, hi)
+			ref.keys = append(ref.keys[:from], ref.keys[to:]...)
+			ref.vals = append(ref.vals[:from], ref.vals[to:]...)
+		case 6:
+			if rnd.Intn(20) == 0 {
+				r = NewRedBlackFromSorted(r.ToSlice())
+				ref.r = r
+			}
+		case 7:
+			lo, hi := k, randomKType(rnd)
+			if rnd.Intn(4) != 0 && r.compare(lo, hi) > 0 {
+				lo, hi = hi, lo
+			}
+			i, j := ref.search(lo), ref.search(hi)
+			if _, ok := ref.has(hi); ok {
+				j++
+			}
+			if i > j {
+				j = i
+			}
+			want, wantOK, scale := ref.aggregate(i, j)
+			got, ok := r.RangeAggregate(lo, hi)
+			checkAggregate("range aggregate", want, wantOK, scale, got, ok)
+		}
+		want, wantOK, scale := ref.aggregate(0, len(ref.keys))
+		got, ok := r.Aggregate()
+		checkAggregate("aggregate", want, wantOK, scale, got, ok)
+		checkRedBlack(t, r)
+		checkRedBlackAggregates(t, r, r.root)
+	}
+}
+
+// TestRedBlackCursorMatchesKeys verifies that cursors, and the visits in
+// reverse order, go over the keys in the order of Keys, or in reverse.
+func TestRedBlackCursorMatchesKeys(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	r := NewRedBlack()
+	for n := 0; n < 1000; n++ {
+		r.Put(randomKType(rnd), randomVType(rnd))
+	}
+	var keys []KType
+	var vals []VType
+	r.Keys(func(k KType, v VType) bool {
+		keys = append(keys, k)
+		vals = append(vals, v)
+		return true
+	})
+
+	checkEntry := func(op string, i int, k KType, v VType) {
+		t.Helper()
+		if i < 0 || i >= len(keys) {
+			t.Fatalf("%s: want no more keys, got %v", op, k)
+		}
+		if r.compare(keys[i], k) != 0 || !reflect.DeepEqual(vals[i], v) {
+			t.Fatalf("%s: want %v=%v, got %v=%v", op, keys[i], vals[i], k, v)
+		}
+	}
+	// checkCursor verifies that c is on the i-th key, or on none if there's
+	// no such key
+	checkCursor := func(op string, c *Cursor, i int) {
+		t.Helper()
+		if want := i >= 0 && i < len(keys); c.Valid() != want {
+			t.Fatalf("%s: want the cursor on a key: %v, got %v", op, want, c.Valid())
+		}
+		if c.Valid() && (r.compare(keys[i], c.Key()) != 0 || !reflect.DeepEqual(vals[i], c.Value())) {
+			t.Fatalf("%s: want %v=%v, got %v=%v", op, keys[i], vals[i], c.Key(), c.Value())
+		}
+	}
+
+	c := r.NewCursor()
+	checkCursor("new cursor", c, -1)
+	i := 0
+	for ok := c.First(); ok; ok = c.Next() {
+		checkCursor("next", c, i)
+		i++
+	}
+	if i != len(keys) {
+		t.Fatalf("next: want %d keys, got %d", len(keys), i)
+	}
+	checkCursor("past the last key", c, i)
+	i = len(keys) - 1
+	for ok := c.Last(); ok; ok = c.Prev() {
+		checkCursor("prev", c, i)
+		i--
+	}
+	if i != -1 {
+		t.Fatalf("prev: want %d keys, got %d", len(keys), len(keys)-1-i)
+	}
+
+	i = len(keys) - 1
+	r.ReverseKeys(func(k KType, v VType) bool {
+		checkEntry("reverse keys", i, k, v)
+		i--
+		return true
+	})
+	if i != -1 {
+		t.Fatalf("reverse keys: want %d keys, got %d", len(keys), len(keys)-1-i)
+	}
+
+	for n := 0; n < 1000; n++ {
+		lo, hi := randomKType(rnd), randomKType(rnd)
+		// the rank of a key is the index of its ceiling
+		i := r.Rank(lo)
+		if ok := c.Seek(lo); ok != (i < len(keys)) {
+			t.Fatalf("seek %v: want %v, got %v", lo, !ok, ok)
+		}
+		checkCursor("seek", c, i)
+		for step := 0; step < 10 && c.Valid(); step++ {
+			if rnd.Intn(2) == 0 {
+				c.Next()
+				i++
+			} else {
+				c.Prev()
+				i--
+			}
+			checkCursor("step", c, i)
+		}
+
+		first, last := r.Rank(lo), r.Rank(hi)-1
+		if r.Has(hi) {
+			last++
+		}
+		r.RangedKeysDesc(lo, hi, func(k KType, v VType) bool {
+			checkEntry("ranged keys desc", last, k, v)
+			last--
+			return true
+		})
+		if last >= first {
+			t.Fatalf("ranged keys desc [%v, %v]: missed %v", lo, hi, keys[last])
+		}
+	}
+}
+
+// checkRedBlackSlices verifies that r is a valid tree holding the entries of
+// ref, and that it exports them in order.
+func checkRedBlackSlices(t *testing.T, r *RedBlack, ref *refRedBlack) {
+	checkRedBlack(t, r)
+	if r.Size() != len(ref.keys) {
+		t.Fatalf("want size %d, got %d", len(ref.keys), r.Size())
+	}
+	keys, vals := r.ToSlice()
+	if len(keys) != len(ref.keys) || len(vals) != len(ref.vals) {
+		t.Fatalf("want %d keys and values, got %d and %d", len(ref.keys), len(keys), len(vals))
+	}
+	for i, k := range keys {
+		if r.compare(ref.keys[i], k) != 0 || !reflect.DeepEqual(ref.vals[i], vals[i]) {
+			t.Fatalf("entry %d: want %v=%v, got %v=%v", i, ref.keys[i], ref.vals[i], k, vals[i])
+		}
+	}
+	if got := r.KeysSlice(); !reflect.DeepEqual(keys, got) {
+		t.Fatalf("keys slice: want %v, got %v", keys, got)
+	}
+	if got := r.ValuesSlice(); !reflect.DeepEqual(vals, got) {
+		t.Fatalf("values slice: want %v, got %v", vals, got)
+	}
+}
+
+func TestRedBlackFromSortedMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	for n := 0; n < 200; n++ {
+		ref := &refRedBlack{r: NewRedBlack()}
+		var keys []KType
+		var vals []VType
+		for i := rnd.Intn(5*n + 1); i > 0; i-- {
+			k := randomKType(rnd)
+			if len(keys) != 0 && rnd.Intn(5) == 0 {
+				k = keys[rnd.Intn(len(keys))]
+			}
+			v := randomVType(rnd)
+			keys, vals = append(keys, k), append(vals, v)
+			ref.put(k, v)
+		}
+		given := append([]KType(nil), keys...)
+		checkRedBlackSlices(t, NewRedBlackFromUnsorted(keys, vals), ref)
+		if !reflect.DeepEqual(given, keys) {
+			t.Fatalf("the keys given were modified: want %v, got %v", given, keys)
+		}
+
+		// the keys in order, some of them repeated before their last value
+		keys, vals = keys[:0], vals[:0]
+		for i, k := range ref.keys {
+			for j := rnd.Intn(3) - 1; j > 0; j-- {
+				keys, vals = append(keys, k), append(vals, randomVType(rnd))
+			}
+			keys, vals = append(keys, k), append(vals, ref.vals[i])
+		}
+		checkRedBlackSlices(t, NewRedBlackFromSorted(keys, vals), ref)
+	}
+}
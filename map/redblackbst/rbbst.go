@@ -1,7 +1,16 @@
 package redblackbst
 
+import "sort"
+
 func (r RedBlack) compare(a, b KType) int { return a.Compare(b) }
 
+// measure is the aggregate of the single key/value `k`, `v`.
+func (r RedBlack) measure(k KType, v VType) AType { return 1 }
+
+// combine is the aggregate of two consecutive ranges of keys, whose
+// aggregates are `a` and `b`, in order. It must be associative.
+func (r RedBlack) combine(a, b AType) AType { return a.(int) + b.(int) }
+
 // RedBlack is a sorted map built on a left leaning red black balanced
 // search sorted map. It stores VType values, keyed by KType.
 type RedBlack struct {
@@ -11,6 +20,59 @@ type RedBlack struct {
 // NewRedBlack creates a sorted map.
 func NewRedBlack() *RedBlack { return &RedBlack{} }
 
+// NewRedBlackFromSorted creates a sorted map of the keys, given in order, and
+// of their values, in O(n) rather than the O(n log n) of putting them one by
+// one. Of equal keys, the first one is kept with the value of the last, as
+// putting them would. It panics if the keys aren't in order, or if there
+// isn't a value for each key.
+func NewRedBlackFromSorted(keys []KType, vals []VType) *RedBlack {
+	r := &RedBlack{}
+	if len(keys) != len(vals) {
+		panic("redblackbst: as many keys as values are needed")
+	}
+	var dups bool
+	for i := 1; i < len(keys); i++ {
+		cmp := r.compare(keys[i-1], keys[i])
+		if cmp > 0 {
+			panic("redblackbst: keys aren't sorted")
+		}
+		dups = dups || cmp == 0
+	}
+	if dups {
+		// not to modify the slices given
+		keys, vals = r.dedup(append([]KType(nil), keys...), append([]VType(nil), vals...))
+	}
+	r.root = r.build(keys, vals)
+	return r
+}
+
+// NewRedBlackFromUnsorted creates a sorted map of the keys and their values,
+// sorting them first, in O(n log n) but faster than putting them one by one.
+// Of equal keys, the first one is kept with the value of the last, as putting
+// them would. The slices aren't modified. It panics if there isn't a value
+// for each key.
+func NewRedBlackFromUnsorted(keys []KType, vals []VType) *RedBlack {
+	r := &RedBlack{}
+	if len(keys) != len(vals) {
+		panic("redblackbst: as many keys as values are needed")
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return r.compare(keys[order[i]], keys[order[j]]) < 0
+	})
+	sortedKeys := make([]KType, len(keys))
+	sortedVals := make([]VType, len(vals))
+	for i, at := range order {
+		sortedKeys[i], sortedVals[i] = keys[at], vals[at]
+	}
+	sortedKeys, sortedVals = r.dedup(sortedKeys, sortedVals)
+	r.root = r.build(sortedKeys, sortedVals)
+	return r
+}
+
 // IsEmpty tells if the sorted map contains no key/value.
 func (r RedBlack) IsEmpty() bool {
 	return r.root == nil
@@ -26,13 +88,15 @@ func (r *RedBlack) Clear() { r.root = nil }
 // if the key was already present.
 func (r *RedBlack) Put(k KType, v VType) (old VType, overwrite bool) {
 	r.root, old, overwrite = r.put(r.root, k, v)
+	r.root.colorRed = false
 	return
 }
 
 func (r *RedBlack) put(h *mapnode, k KType, v VType) (_ *mapnode, old VType, overwrite bool) {
 	if h == nil {
-		n := &mapnode{key: k, val: v, n: 1, colorRed: true}
-		return n, old, overwrite
+		h = &mapnode{key: k, val: v, colorRed: true}
+		r.update(h)
+		return h, old, overwrite
 	}
 
 	cmp := r.compare(k, h.key)
@@ -55,7 +119,7 @@ func (r *RedBlack) put(h *mapnode, k KType, v VType) (_ *mapnode, old VType, ove
 	if h.left.isRed() && h.right.isRed() {
 		r.flipColors(h)
 	}
-	h.n = h.left.size() + h.right.size() + 1
+	r.update(h)
 	return h, old, overwrite
 }
 
@@ -118,7 +182,7 @@ func (r RedBlack) max(x *mapnode) *mapnode {
 }
 
 // Floor returns the largest key/value in the sorted map that is smaller than
-// `k`.
+// or equal to `k`, if it exists.
 func (r RedBlack) Floor(key KType) (k KType, v VType, ok bool) {
 	x := r.floor(r.root, key)
 	if x == nil {
@@ -146,7 +210,7 @@ func (r RedBlack) floor(h *mapnode, k KType) *mapnode {
 }
 
 // Ceiling returns the smallest key/value in the sorted map that is larger than
-// `k`.
+// or equal to `k`, if it exists.
 func (r RedBlack) Ceiling(key KType) (k KType, v VType, ok bool) {
 	x := r.ceiling(r.root, key)
 	if x == nil {
@@ -173,6 +237,54 @@ func (r RedBlack) ceiling(h *mapnode, k KType) *mapnode {
 	return h
 }
 
+// Lower returns the largest key/value in the sorted map that is strictly
+// smaller than `k`, its predecessor, if it exists.
+func (r RedBlack) Lower(key KType) (k KType, v VType, ok bool) {
+	x := r.lower(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, x.val, true
+}
+
+func (r RedBlack) lower(h *mapnode, k KType) *mapnode {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) <= 0 {
+		return r.lower(h.left, k)
+	}
+	t := r.lower(h.right, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
+// Higher returns the smallest key/value in the sorted map that is strictly
+// larger than `k`, its successor, if it exists.
+func (r RedBlack) Higher(key KType) (k KType, v VType, ok bool) {
+	x := r.higher(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, x.val, true
+}
+
+func (r RedBlack) higher(h *mapnode, k KType) *mapnode {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) >= 0 {
+		return r.higher(h.right, k)
+	}
+	t := r.higher(h.left, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
 // Select key of rank k, meaning the k-th biggest KType in the sorted map.
 func (r RedBlack) Select(key int) (k KType, v VType, ok bool) {
 	x := r.nodeselect(r.root, key)
@@ -257,6 +369,214 @@ func (r RedBlack) keys(h *mapnode, visit func(KType, VType) bool, lo, hi KType)
 	return true
 }
 
+// ReverseKeys visit each keys in the sorted map, in reverse order.
+// It stops when visit returns false.
+func (r RedBlack) ReverseKeys(visit func(KType, VType) bool) {
+	min, _, ok := r.Min()
+	if !ok {
+		return
+	}
+	// if the min exists, then the max must exist
+	max, _, _ := r.Max()
+	r.RangedKeysDesc(min, max, visit)
+}
+
+// RangedKeysDesc visit each keys between lo and hi in the sorted map, in
+// reverse order. It stops when visit returns false.
+func (r RedBlack) RangedKeysDesc(lo, hi KType, visit func(KType, VType) bool) {
+	r.keysDesc(r.root, visit, lo, hi)
+}
+
+func (r RedBlack) keysDesc(h *mapnode, visit func(KType, VType) bool, lo, hi KType) bool {
+	if h == nil {
+		return true
+	}
+	cmplo := r.compare(lo, h.key)
+	cmphi := r.compare(hi, h.key)
+	if cmphi > 0 {
+		if !r.keysDesc(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	if cmplo <= 0 && cmphi >= 0 {
+		if !visit(h.key, h.val) {
+			return false
+		}
+	}
+	if cmplo < 0 {
+		if !r.keysDesc(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
+// Bound is an end of a range of keys: its Key, included in the range unless
+// it's Exclusive. An Unbounded end doesn't limit the range, whatever its Key.
+type Bound struct {
+	Key       KType
+	Exclusive bool
+	Unbounded bool
+}
+
+// RangeCount is the number of keys between lo and hi in the sorted map. It
+// doesn't visit them, and takes O(log n).
+func (r RedBlack) RangeCount(lo, hi Bound) int {
+	n := r.boundrank(hi, true) - r.boundrank(lo, false)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// boundrank is the number of keys below b, or up to b for an upper bound.
+func (r RedBlack) boundrank(b Bound, upper bool) int {
+	if b.Unbounded {
+		if upper {
+			return r.Size()
+		}
+		return 0
+	}
+	// the key itself is below an exclusive lower bound or an inclusive
+	// upper bound
+	return r.keyrankBound(b.Key, r.root, upper != b.Exclusive)
+}
+
+func (r RedBlack) keyrankBound(k KType, h *mapnode, withKey bool) int {
+	if h == nil {
+		return 0
+	}
+	cmp := r.compare(k, h.key)
+	if cmp < 0 {
+		return r.keyrankBound(k, h.left, withKey)
+	} else if cmp > 0 {
+		return 1 + h.left.size() + r.keyrankBound(k, h.right, withKey)
+	} else if withKey {
+		return h.left.size() + 1
+	} else {
+		return h.left.size()
+	}
+}
+
+// BoundedKeys visit each keys between lo and hi in the sorted map, in order.
+// It stops when visit returns false.
+func (r RedBlack) BoundedKeys(lo, hi Bound, visit func(KType, VType) bool) {
+	r.boundedKeys(r.root, visit, lo, hi)
+}
+
+func (r RedBlack) boundedKeys(h *mapnode, visit func(KType, VType) bool, lo, hi Bound) bool {
+	if h == nil {
+		return true
+	}
+	// whether the keys left and right of h can be in the range
+	left, right := true, true
+	inside := true
+	if !lo.Unbounded {
+		cmp := r.compare(lo.Key, h.key)
+		left = cmp < 0
+		inside = cmp < 0 || (cmp == 0 && !lo.Exclusive)
+	}
+	if !hi.Unbounded {
+		cmp := r.compare(hi.Key, h.key)
+		right = cmp > 0
+		inside = inside && (cmp > 0 || (cmp == 0 && !hi.Exclusive))
+	}
+	if left {
+		if !r.boundedKeys(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	if inside {
+		if !visit(h.key, h.val) {
+			return false
+		}
+	}
+	if right {
+		if !r.boundedKeys(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
+// Aggregate returns the aggregate of all the keys/values of the sorted map,
+// in O(1). It's not ok if the sorted map is empty.
+func (r RedBlack) Aggregate() (agg AType, ok bool) {
+	if r.root == nil {
+		return
+	}
+	return r.root.agg, true
+}
+
+// RangeAggregate returns the aggregate of the keys/values between lo and hi
+// in the sorted map, in O(log n). It's not ok if there are none.
+func (r RedBlack) RangeAggregate(lo, hi KType) (agg AType, ok bool) {
+	// down to the first node in the range, under which the keys of the
+	// range are
+	h := r.root
+	for h != nil {
+		if r.compare(hi, h.key) < 0 {
+			h = h.left
+		} else if r.compare(lo, h.key) > 0 {
+			h = h.right
+		} else {
+			break
+		}
+	}
+	if h == nil {
+		return
+	}
+	agg, ok = r.fromLo(h.left, lo)
+	agg, ok = r.join(agg, ok, r.measure(h.key, h.val), true)
+	hiAgg, hiOK := r.upToHi(h.right, hi)
+	return r.join(agg, ok, hiAgg, hiOK)
+}
+
+// fromLo is the aggregate of the keys of the tree of h that are larger than
+// or equal to lo. The subtrees on the right of the path to lo are entirely
+// in range.
+func (r RedBlack) fromLo(h *mapnode, lo KType) (agg AType, ok bool) {
+	for h != nil {
+		if r.compare(lo, h.key) > 0 {
+			h = h.right
+			continue
+		}
+		rightAgg, rightOK := h.right.aggregate()
+		sub, _ := r.join(r.measure(h.key, h.val), true, rightAgg, rightOK)
+		agg, ok = r.join(sub, true, agg, ok)
+		h = h.left
+	}
+	return
+}
+
+// upToHi is the aggregate of the keys of the tree of h that are smaller than
+// or equal to hi. The subtrees on the left of the path to hi are entirely
+// in range.
+func (r RedBlack) upToHi(h *mapnode, hi KType) (agg AType, ok bool) {
+	for h != nil {
+		if r.compare(hi, h.key) < 0 {
+			h = h.left
+			continue
+		}
+		leftAgg, leftOK := h.left.aggregate()
+		sub, _ := r.join(leftAgg, leftOK, r.measure(h.key, h.val), true)
+		agg, ok = r.join(agg, ok, sub, true)
+		h = h.right
+	}
+	return
+}
+
+// join combines the aggregates a and b that are ok, in order.
+func (r RedBlack) join(a AType, aok bool, b AType, bok bool) (AType, bool) {
+	switch {
+	case aok && bok:
+		return r.combine(a, b), true
+	case aok:
+		return a, true
+	}
+	return b, bok
+}
+
 // DeleteMin removes the smallest key and its value from the sorted map.
 func (r *RedBlack) DeleteMin() (oldk KType, oldv VType, ok bool) {
 	r.root, oldk, oldv, ok = r.deleteMin(r.root)
@@ -367,6 +687,120 @@ func (r *RedBlack) delete(h *mapnode, k KType) (_ *mapnode, old VType, ok bool)
 	return h, old, ok
 }
 
+// ToSlice returns the keys of the sorted map, in order, and their values.
+func (r RedBlack) ToSlice() (keys []KType, vals []VType) {
+	keys = make([]KType, 0, r.Size())
+	vals = make([]VType, 0, r.Size())
+	r.Keys(func(k KType, v VType) bool {
+		keys = append(keys, k)
+		vals = append(vals, v)
+		return true
+	})
+	return keys, vals
+}
+
+// KeysSlice returns the keys of the sorted map, in order.
+func (r RedBlack) KeysSlice() []KType {
+	keys := make([]KType, 0, r.Size())
+	r.Keys(func(k KType, _ VType) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+// ValuesSlice returns the values of the sorted map, in the order of their
+// keys.
+func (r RedBlack) ValuesSlice() []VType {
+	vals := make([]VType, 0, r.Size())
+	r.Keys(func(_ KType, v VType) bool {
+		vals = append(vals, v)
+		return true
+	})
+	return vals
+}
+
+// DeleteRange removes the keys between lo and hi and their values from the sorted map,
+// and returns how many it removed. It takes O(m log n) to remove m keys, or
+// O(n) when the range is a large part of the sorted map, which is then
+// rebuilt out of the keys left.
+func (r *RedBlack) DeleteRange(lo, hi Bound) int {
+	from, n, size := r.boundrank(lo, false), r.RangeCount(lo, hi), r.Size()
+	depth := 0
+	for s := size; s > 1; s /= 2 {
+		depth++
+	}
+	if n*depth < size {
+		for i := 0; i < n; i++ {
+			// the keys after those removed take their ranks
+			r.Delete(r.nodeselect(r.root, from).key)
+		}
+		return n
+	}
+
+	keys := make([]KType, 0, size-n)
+	vals := make([]VType, 0, size-n)
+	i := 0
+	r.Keys(func(k KType, v VType) bool {
+		if i < from || i >= from+n {
+			keys = append(keys, k)
+			vals = append(vals, v)
+		}
+		i++
+		return true
+	})
+	r.root = r.build(keys, vals)
+	return n
+}
+
+// construction
+
+// dedup removes the duplicates from the sorted keys in place, keeping the
+// first of equal keys with the value of the last, as putting them would.
+func (r RedBlack) dedup(keys []KType, vals []VType) ([]KType, []VType) {
+	n := 0
+	for i := range keys {
+		if n > 0 && r.compare(keys[n-1], keys[i]) == 0 {
+			vals[n-1] = vals[i]
+			continue
+		}
+		keys[n], vals[n] = keys[i], vals[i]
+		n++
+	}
+	return keys[:n], vals[:n]
+}
+
+// build returns the root of a tree holding the sorted keys and their values,
+// in O(n). The tree is as balanced as can be, with the nodes of its last
+// level red if it's not full, and is fixed bottom up so that its red links
+// lean left.
+func (r *RedBlack) build(keys []KType, vals []VType) *mapnode {
+	if len(keys) == 0 {
+		return nil
+	}
+	// the depth of the last level, counting from 0, if it's not full
+	redDepth := -1
+	if n := len(keys); n&(n+1) != 0 {
+		for redDepth = 0; n > 1; n /= 2 {
+			redDepth++
+		}
+	}
+	root := r.buildNode(keys, vals, 0, redDepth)
+	root.colorRed = false
+	return root
+}
+
+func (r *RedBlack) buildNode(keys []KType, vals []VType, depth, redDepth int) *mapnode {
+	if len(keys) == 0 {
+		return nil
+	}
+	mid := len(keys) / 2
+	h := &mapnode{key: keys[mid], val: vals[mid], colorRed: depth == redDepth}
+	h.left = r.buildNode(keys[:mid], vals[:mid], depth+1, redDepth)
+	h.right = r.buildNode(keys[mid+1:], vals[mid+1:], depth+1, redDepth)
+	return r.balance(h)
+}
+
 // deletions
 
 func (r *RedBlack) moveRedLeft(h *mapnode) *mapnode {
@@ -398,7 +832,7 @@ func (r *RedBlack) balance(h *mapnode) *mapnode {
 	if h.left.isRed() && h.right.isRed() {
 		r.flipColors(h)
 	}
-	h.n = h.left.size() + h.right.size() + 1
+	r.update(h)
 	return h
 }
 
@@ -408,8 +842,8 @@ func (r *RedBlack) rotateLeft(h *mapnode) *mapnode {
 	x.left = h
 	x.colorRed = h.colorRed
 	h.colorRed = true
-	x.n = h.n
-	h.n = 1 + h.left.size() + h.right.size()
+	r.update(h)
+	r.update(x)
 	return x
 }
 
@@ -419,8 +853,8 @@ func (r *RedBlack) rotateRight(h *mapnode) *mapnode {
 	x.right = h
 	x.colorRed = h.colorRed
 	h.colorRed = true
-	x.n = h.n
-	h.n = 1 + h.left.size() + h.right.size()
+	r.update(h)
+	r.update(x)
 	return x
 }
 
@@ -430,6 +864,140 @@ func (r *RedBlack) flipColors(h *mapnode) {
 	h.right.colorRed = !h.right.colorRed
 }
 
+// update what h holds about its tree, out of what its children hold.
+func (r *RedBlack) update(h *mapnode) {
+	h.n = 1 + h.left.size() + h.right.size()
+	h.agg = r.measure(h.key, h.val)
+	if h.left != nil {
+		h.agg = r.combine(h.left.agg, h.agg)
+	}
+	if h.right != nil {
+		h.agg = r.combine(h.agg, h.right.agg)
+	}
+}
+
+// cursors
+
+// Cursor is a position among the keys of a sorted map, moved from key to key
+// in either order. Unlike Keys, it can be paused, or moved along the cursor
+// of another sorted map. It's only valid until the sorted map is modified.
+type Cursor struct {
+	r RedBlack
+	// path from the root to the node under the cursor, empty when the cursor
+	// isn't on a key
+	path []*mapnode
+}
+
+// NewCursor returns a cursor of the sorted map, which isn't on a key until it's
+// moved with First, Last or Seek.
+func (r RedBlack) NewCursor() *Cursor { return &Cursor{r: r} }
+
+// Valid tells if the cursor is on a key.
+func (c *Cursor) Valid() bool { return len(c.path) != 0 }
+
+// First moves the cursor on the smallest key, and tells if there is one.
+func (c *Cursor) First() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.left {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Last moves the cursor on the largest key, and tells if there is one.
+func (c *Cursor) Last() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.right {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Seek moves the cursor on the smallest key larger than or equal to `k`, and
+// tells if there is one.
+func (c *Cursor) Seek(k KType) bool {
+	c.path = c.path[:0]
+	// the ceiling is the last node left of which the search goes
+	ceiling := 0
+	for h := c.r.root; h != nil; {
+		c.path = append(c.path, h)
+		cmp := c.r.compare(k, h.key)
+		if cmp == 0 {
+			return true
+		} else if cmp < 0 {
+			ceiling = len(c.path)
+			h = h.left
+		} else {
+			h = h.right
+		}
+	}
+	c.path = c.path[:ceiling]
+	return c.Valid()
+}
+
+// Next moves the cursor on the following key, and tells if there is one.
+// Once past the largest key, the cursor isn't on a key anymore.
+func (c *Cursor) Next() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].right; h != nil {
+		for ; h != nil; h = h.left {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the left of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].left == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Prev moves the cursor on the preceding key, and tells if there is one.
+// Once past the smallest key, the cursor isn't on a key anymore.
+func (c *Cursor) Prev() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].left; h != nil {
+		for ; h != nil; h = h.right {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the right of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].right == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Key under the cursor. It panics if the cursor isn't on a key.
+func (c *Cursor) Key() KType {
+	return c.node().key
+}
+
+// Value under the cursor. It panics if the cursor isn't on a key.
+func (c *Cursor) Value() VType {
+	return c.node().val
+}
+
+func (c *Cursor) node() *mapnode {
+	if !c.Valid() {
+		panic("redblackbst: cursor isn't on a key")
+	}
+	return c.path[len(c.path)-1]
+}
+
 // nodes
 
 type mapnode struct {
@@ -438,6 +1006,8 @@ type mapnode struct {
 	left, right *mapnode
 	n           int
 	colorRed    bool
+	// agg is the aggregate of the keys/values of the tree of the node
+	agg AType
 }
 
 func (x *mapnode) isRed() bool { return (x != nil) && (x.colorRed == true) }
@@ -448,3 +1018,11 @@ func (x *mapnode) size() int {
 	}
 	return x.n
 }
+
+// aggregate of the tree of x, which isn't ok if x is nil.
+func (x *mapnode) aggregate() (agg AType, ok bool) {
+	if x == nil {
+		return
+	}
+	return x.agg, true
+}
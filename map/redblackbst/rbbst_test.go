@@ -368,6 +368,45 @@ func TestGetCorrectCeiling(t *testing.T) {
 	}
 }
 
+func TestGetCorrectLowerAndHigher(t *testing.T) {
+	tree := NewRedBlack()
+	// none means there's no lower or higher key
+	const none = -1
+	check := func(tests []struct{ k, lower, higher int }) {
+		t.Helper()
+		for _, tt := range tests {
+			if k, _, ok := tree.Lower(Int(tt.k)); ok != (tt.lower != none) || ok && k != Int(tt.lower) {
+				t.Errorf("lower %d: want %d, got %v, %v", tt.k, tt.lower, k, ok)
+			}
+			if k, _, ok := tree.Higher(Int(tt.k)); ok != (tt.higher != none) || ok && k != Int(tt.higher) {
+				t.Errorf("higher %d: want %d, got %v, %v", tt.k, tt.higher, k, ok)
+			}
+		}
+	}
+
+	check([]struct{ k, lower, higher int }{{0, none, none}})
+
+	tree.Put(Int(1), Int(1))
+	check([]struct{ k, lower, higher int }{
+		{0, none, 1},
+		{1, none, none},
+		{2, 1, none},
+	})
+
+	for i := 0; i < 200; i += 2 {
+		tree.Put(Int(i), Int(i))
+	}
+	check([]struct{ k, lower, higher int }{
+		{0, none, 1},
+		{1, 0, 2},
+		{50, 48, 52},
+		{51, 50, 52},
+		{198, 196, none},
+		{199, 198, none},
+		{1000, 198, none},
+	})
+}
+
 func TestGetCorrectSelect(t *testing.T) {
 
 	tree := NewRedBlack()
@@ -578,6 +617,260 @@ func TestCanVisitEmptyTree(t *testing.T) {
 
 }
 
+func TestCanVisitAllKeysInReverse(t *testing.T) {
+	tree := NewRedBlack()
+	want := []Int{}
+	for i := 0; i < 100; i++ {
+		want = append([]Int{Int(i)}, want...)
+		tree.Put(Int(i), Int(i))
+	}
+
+	got := []Int{}
+	tree.ReverseKeys(func(k KType, v VType) bool {
+		got = append(got, k.(Int))
+		return true
+	})
+
+	if !reflect.DeepEqual(want, got) {
+		t.Logf("want=%#v", want)
+		t.Logf(" got=%#v", got)
+		t.Errorf("mismatch!")
+	}
+
+	got = []Int{}
+	tree.RangedKeysDesc(Int(10), Int(19), func(k KType, v VType) bool {
+		got = append(got, k.(Int))
+		return len(got) < 5
+	})
+	if want := []Int{19, 18, 17, 16, 15}; !reflect.DeepEqual(want, got) {
+		t.Logf("want=%#v", want)
+		t.Logf(" got=%#v", got)
+		t.Errorf("mismatch!")
+	}
+}
+
+func TestCanMergeWithCursors(t *testing.T) {
+	evens, odds := NewRedBlack(), NewRedBlack()
+	want := []Int{}
+	for i := 0; i < 100; i += 2 {
+		evens.Put(Int(i), Int(i))
+		odds.Put(Int(i+1), Int(i+1))
+		want = append(want, Int(i), Int(i+1))
+	}
+
+	got := []Int{}
+	e, o := evens.NewCursor(), odds.NewCursor()
+	e.First()
+	o.First()
+	for e.Valid() || o.Valid() {
+		if !o.Valid() || (e.Valid() && e.Key().Compare(o.Key()) < 0) {
+			got = append(got, e.Key().(Int))
+			e.Next()
+		} else {
+			got = append(got, o.Key().(Int))
+			o.Next()
+		}
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Logf("want=%#v", want)
+		t.Logf(" got=%#v", got)
+		t.Errorf("mismatch!")
+	}
+}
+
+func TestCursorOnEmptyTree(t *testing.T) {
+	tree := NewRedBlack()
+	c := tree.NewCursor()
+	if c.First() || c.Last() || c.Seek(Int(0)) || c.Next() || c.Prev() {
+		t.Fatalf("cursor moved on a key of an empty tree")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("should panic when the cursor isn't on a key")
+		}
+	}()
+	c.Key()
+}
+
+func TestCanCountAndDeleteRanges(t *testing.T) {
+	tree := NewRedBlack()
+	for i := 0; i < 100; i++ {
+		tree.Put(Int(i), Int(i))
+	}
+
+	for _, tt := range []struct {
+		lo, hi Bound
+		want   int
+	}{
+		{Bound{Key: Int(10)}, Bound{Key: Int(19)}, 10},
+		{Bound{Key: Int(10), Exclusive: true}, Bound{Key: Int(19)}, 9},
+		{Bound{Key: Int(10)}, Bound{Key: Int(19), Exclusive: true}, 9},
+		{Bound{Key: Int(10), Exclusive: true}, Bound{Key: Int(11), Exclusive: true}, 0},
+		{Bound{Unbounded: true}, Bound{Key: Int(9)}, 10},
+		{Bound{Key: Int(90)}, Bound{Unbounded: true}, 10},
+		{Bound{Unbounded: true}, Bound{Unbounded: true}, 100},
+		{Bound{Key: Int(19)}, Bound{Key: Int(10)}, 0},
+		{Bound{Key: Int(-5)}, Bound{Key: Int(500)}, 100},
+	} {
+		if got := tree.RangeCount(tt.lo, tt.hi); got != tt.want {
+			t.Errorf("range count %v, %v: want %d, got %d", tt.lo, tt.hi, tt.want, got)
+		}
+		got := 0
+		tree.BoundedKeys(tt.lo, tt.hi, func(k KType, v VType) bool {
+			got++
+			return true
+		})
+		if got != tt.want {
+			t.Errorf("bounded keys %v, %v: want %d keys, got %d", tt.lo, tt.hi, tt.want, got)
+		}
+	}
+
+	if n := tree.DeleteRange(Bound{Key: Int(10), Exclusive: true}, Bound{Key: Int(20)}); n != 10 {
+		t.Fatalf("want 10 keys deleted, got %d", n)
+	}
+	for i := 0; i < 100; i++ {
+		if want := i <= 10 || i > 20; tree.Has(Int(i)) != want {
+			t.Errorf("has %d: want %v", i, want)
+		}
+	}
+	if n := tree.DeleteRange(Bound{Unbounded: true}, Bound{Unbounded: true}); n != 90 || !tree.IsEmpty() {
+		t.Fatalf("want 90 keys deleted and an empty tree, got %d and %d keys", n, tree.Size())
+	}
+}
+
+func TestCanDeleteMostOfTheKeys(t *testing.T) {
+	tree := NewRedBlack()
+	for i := 0; i < 100000; i++ {
+		tree.Put(Int(i), Int(i))
+	}
+	// the tree is rebuilt out of the keys left
+	if n := tree.DeleteRange(Bound{Key: Int(10)}, Bound{Key: Int(99990), Exclusive: true}); n != 99980 {
+		t.Fatalf("want 99980 keys deleted, got %d", n)
+	}
+	checkRedBlack(t, tree)
+	var keys []KType
+	var vals []VType
+	for i := 0; i < 100000; i++ {
+		if i < 10 || i >= 99990 {
+			keys, vals = append(keys, Int(i)), append(vals, Int(i))
+		}
+	}
+	if got := tree.KeysSlice(); !reflect.DeepEqual(keys, got) {
+		t.Fatalf("want keys %v, got %v", keys, got)
+	}
+	if got := tree.ValuesSlice(); !reflect.DeepEqual(vals, got) {
+		t.Fatalf("want values %v, got %v", vals, got)
+	}
+
+	// and a few keys are deleted in place
+	if n := tree.DeleteRange(Bound{Key: Int(5)}, Bound{Key: Int(6)}); n != 2 {
+		t.Fatalf("want 2 keys deleted, got %d", n)
+	}
+	checkRedBlack(t, tree)
+	if tree.Has(Int(5)) || tree.Has(Int(6)) || !tree.Has(Int(7)) || tree.Size() != 18 {
+		t.Errorf("want 5 and 6 deleted, and 18 keys left, got %v", tree.KeysSlice())
+	}
+}
+
+func TestCanBuildFromSlices(t *testing.T) {
+	keys := []KType{Int(1), Int(2), Int(2), Int(3)}
+	vals := []VType{"a", "b", "c", "d"}
+	tree := NewRedBlackFromSorted(keys, vals)
+	gotKeys, gotVals := tree.ToSlice()
+	wantKeys, wantVals := []KType{Int(1), Int(2), Int(3)}, []VType{"a", "c", "d"}
+	if !reflect.DeepEqual(wantKeys, gotKeys) || !reflect.DeepEqual(wantVals, gotVals) {
+		t.Logf("want=%#v %#v", wantKeys, wantVals)
+		t.Logf(" got=%#v %#v", gotKeys, gotVals)
+		t.Errorf("mismatch!")
+	}
+
+	tree = NewRedBlackFromUnsorted([]KType{Int(3), Int(2), Int(1), Int(2)}, []VType{"d", "b", "a", "c"})
+	if got := tree.KeysSlice(); !reflect.DeepEqual(wantKeys, got) {
+		t.Errorf("keys slice: want %v, got %v", wantKeys, got)
+	}
+	if got := tree.ValuesSlice(); !reflect.DeepEqual(wantVals, got) {
+		t.Errorf("values slice: want %v, got %v", wantVals, got)
+	}
+
+	if tree := NewRedBlackFromSorted(nil, nil); !tree.IsEmpty() || len(tree.KeysSlice()) != 0 {
+		t.Errorf("want an empty tree, got %d keys", tree.Size())
+	}
+
+	for _, tt := range []struct {
+		name string
+		f    func()
+	}{
+		{"unsorted keys", func() { NewRedBlackFromSorted([]KType{Int(2), Int(1)}, []VType{"b", "a"}) }},
+		{"missing values", func() { NewRedBlackFromSorted([]KType{Int(1), Int(2)}, []VType{"a"}) }},
+		{"missing unsorted values", func() { NewRedBlackFromUnsorted([]KType{Int(1)}, nil) }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: should panic", tt.name)
+				}
+			}()
+			tt.f()
+		}()
+	}
+}
+
+// Here, the aggregate of a range of keys is their count.
+func TestCanAggregateRanges(t *testing.T) {
+	tree := NewRedBlack()
+	if _, ok := tree.Aggregate(); ok {
+		t.Errorf("empty tree should have no aggregate")
+	}
+	if _, ok := tree.RangeAggregate(Int(0), Int(10)); ok {
+		t.Errorf("empty tree should have no aggregate in range")
+	}
+	for i := 1; i <= 10; i++ {
+		tree.Put(Int(i), i*i)
+	}
+
+	for _, tt := range []struct {
+		lo, hi int
+		want   int
+		ok     bool
+	}{
+		{lo: 1, hi: 10, want: 10, ok: true},
+		{lo: 3, hi: 5, want: 3, ok: true},
+		{lo: -5, hi: 2, want: 2, ok: true},
+		{lo: 10, hi: 20, want: 1, ok: true},
+		{lo: 4, hi: 4, want: 1, ok: true},
+		{lo: 11, hi: 20},
+		{lo: 5, hi: 3},
+	} {
+		got, ok := tree.RangeAggregate(Int(tt.lo), Int(tt.hi))
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("[%d, %d]: want %v, %v, got %v, %v", tt.lo, tt.hi, tt.want, tt.ok, got, ok)
+		}
+	}
+
+	tree.Put(Int(4), 0)
+	tree.Delete(Int(5))
+	tree.DeleteRange(Bound{Key: Int(9)}, Bound{Unbounded: true})
+	if got, _ := tree.RangeAggregate(Int(3), Int(5)); got != 2 {
+		t.Errorf("[3, 5] after writing: want 2, got %v", got)
+	}
+	if got, _ := tree.Aggregate(); got != 7 {
+		t.Errorf("aggregate after writing: want 7, got %v", got)
+	}
+}
+
+// Put used to leave a red root, which later puts could follow with another
+// red link.
+func TestPutLeavesRootBlack(t *testing.T) {
+	tree := NewRedBlack()
+	for i := 0; i < 10; i++ {
+		tree.Put(Int(i), i)
+		if tree.root.isRed() {
+			t.Fatalf("root is red after putting %d", i)
+		}
+	}
+}
+
 func TestCanExportToDot(t *testing.T) {
 	tree := NewRedBlack()
 	for i := 0; i < 100; i++ {
@@ -0,0 +1,128 @@
+package queue
+
+import (
+	"container/list"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// The benchmarks of this file are generated along with queues, when asked
+// to. The elements are generated by benchKType.
+
+// benchQueueSizes are the numbers of elements in the benchmarked queues.
+var benchQueueSizes = []int{100, 10000, 1000000}
+
+// benchQueue runs bench for each size, with that many random elements. The
+// elements are the same from one benchmark to the other.
+func benchQueue(b *testing.B, bench func(b *testing.B, elems []KType)) {
+	for _, n := range benchQueueSizes {
+		n := n
+		var elems []KType
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			// once for all the runs of the benchmark, and only if it's run
+			if elems == nil {
+				rnd := rand.New(rand.NewSource(int64(n)))
+				elems = make([]KType, n)
+				for i := range elems {
+					elems[i] = benchKType(rnd)
+				}
+			}
+			b.ResetTimer()
+			bench(b, elems)
+		})
+	}
+}
+
+// fillQueue returns a queue of capacity c holding elems.
+func fillQueue(c int, elems []KType) *Queue {
+	q := NewQueue(c)
+	for _, e := range elems {
+		q.Push(e)
+	}
+	return q
+}
+
+func BenchmarkQueuePush(b *testing.B) {
+	benchQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := fillQueue(2*n, elems)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Push(elems[i%n])
+			if q.count == 2*n {
+				// drop the last n elements, without resizing
+				q.count = n
+				q.tail = (q.head + n) % len(q.buf)
+			}
+		}
+	})
+}
+
+func BenchmarkQueuePeek(b *testing.B) {
+	benchQueue(b, func(b *testing.B, elems []KType) {
+		q := fillQueue(len(elems), elems)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Peek()
+		}
+	})
+}
+
+func BenchmarkQueueGetAt(b *testing.B) {
+	benchQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := fillQueue(n, elems)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Get(i % n)
+		}
+	})
+}
+
+func BenchmarkQueuePop(b *testing.B) {
+	benchQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		// a full queue at its minimum capacity doesn't resize when popped
+		q := fillQueue(n, elems)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Pop()
+			if q.count == 0 {
+				copy(q.buf, elems)
+				q.head, q.tail, q.count = 0, n%len(q.buf), n
+			}
+		}
+	})
+}
+
+// BenchmarkQueuePushPop is to be compared with
+// BenchmarkQueuePushPopContainer.
+func BenchmarkQueuePushPop(b *testing.B) {
+	benchQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := fillQueue(n, elems)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Push(elems[i%n])
+			q.Pop()
+		}
+	})
+}
+
+// BenchmarkQueuePushPopContainer queues the elements as interface{} in a
+// container/list.
+func BenchmarkQueuePushPopContainer(b *testing.B) {
+	benchQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		l := list.New()
+		for _, e := range elems {
+			l.PushBack(e)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			l.PushBack(elems[i%n])
+			_ = l.Remove(l.Front()).(KType)
+		}
+	})
+}
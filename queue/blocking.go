@@ -0,0 +1,158 @@
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+// The ring buffer is the one of the bounded queue, guarded by a mutex.
+
+// BlockingQueue represents a single instance of the queue data structure,
+// safe for concurrent use, holding at most a fixed number of elements. Popping
+// from an empty queue, or pushing onto a full one, either fails right away or
+// waits for another goroutine to push or pop an element.
+type BlockingQueue struct {
+	mu                sync.Mutex
+	notEmpty, notFull *sync.Cond
+	buf               []KType
+	head, tail, count int
+}
+
+// NewBlockingQueue constructs and returns a new BlockingQueue holding at most
+// capacity elements, for which the memory is allocated at once. This call
+// panics if the capacity isn't positive.
+func NewBlockingQueue(capacity int) *BlockingQueue {
+	if capacity <= 0 {
+		panic("queue: capacity must be positive")
+	}
+	q := &BlockingQueue{buf: make([]KType, capacity)}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	return q
+}
+
+// Len returns the number of elements currently stored in the queue.
+func (q *BlockingQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.count
+}
+
+// Cap returns the maximum number of elements the queue can hold.
+func (q *BlockingQueue) Cap() int {
+	return len(q.buf)
+}
+
+// Push puts an element on the end of the queue, and tells if it did, which
+// it doesn't if the queue is full.
+func (q *BlockingQueue) Push(elem KType) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.count == len(q.buf) {
+		return false
+	}
+	q.push(elem)
+	return true
+}
+
+// PushWait puts an element on the end of the queue, waiting for room if the
+// queue is full. It returns the error of ctx, without pushing the element, if
+// ctx is done first.
+func (q *BlockingQueue) PushWait(ctx context.Context, elem KType) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.count == len(q.buf) {
+		defer q.wakeWhenDone(ctx, q.notFull)()
+	}
+	for q.count == len(q.buf) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q.notFull.Wait()
+	}
+	q.push(elem)
+	return nil
+}
+
+// TryPeek returns the element at the head of the queue, and tells if there
+// was one.
+func (q *BlockingQueue) TryPeek() (k KType, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.count == 0 {
+		return k, false
+	}
+	return q.buf[q.head], true
+}
+
+// TryPop removes the element from the front of the queue, and tells if there
+// was one.
+func (q *BlockingQueue) TryPop() (k KType, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.count == 0 {
+		return k, false
+	}
+	return q.pop(), true
+}
+
+// PopWait removes the element from the front of the queue, waiting for one
+// if the queue is empty. It returns the error of ctx instead if ctx is done
+// first.
+func (q *BlockingQueue) PopWait(ctx context.Context) (k KType, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.count == 0 {
+		defer q.wakeWhenDone(ctx, q.notEmpty)()
+	}
+	for q.count == 0 {
+		if err := ctx.Err(); err != nil {
+			return k, err
+		}
+		q.notEmpty.Wait()
+	}
+	return q.pop(), nil
+}
+
+// wakeWhenDone wakes the goroutines waiting on c up when ctx is done, so that
+// they give up, until the returned func is called. It must be called, with the
+// lock held, before waiting on c.
+func (q *BlockingQueue) wakeWhenDone(ctx context.Context, c *sync.Cond) (stop func()) {
+	if ctx.Done() == nil {
+		// never done
+		return func() {}
+	}
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			q.mu.Lock()
+			c.Broadcast()
+			q.mu.Unlock()
+		case <-stopped:
+		}
+	}()
+	return func() { close(stopped) }
+}
+
+// push and pop an element, with the lock held, waking a goroutine waiting to
+// do the opposite.
+
+func (q *BlockingQueue) push(elem KType) {
+	q.buf[q.tail] = elem
+	q.tail = (q.tail + 1) % len(q.buf)
+	q.count++
+	q.notEmpty.Signal()
+}
+
+func (q *BlockingQueue) pop() KType {
+	v := q.buf[q.head]
+	// set to the zero value to avoid keeping reference to objects
+	// that would otherwise be garbage collected
+	var zero KType
+	q.buf[q.head] = zero
+	q.head = (q.head + 1) % len(q.buf)
+	q.count--
+	q.notFull.Signal()
+	return v
+}
@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// The benchmarks of this file are generated along with blocking queues, when
+// asked to. The elements are generated by benchKType.
+
+// benchBlockingQueueSizes are the capacities of the benchmarked queues.
+var benchBlockingQueueSizes = []int{100, 10000, 1000000}
+
+// benchBlockingQueue runs bench for each size, with that many random
+// elements. The elements are the same from one benchmark to the other.
+func benchBlockingQueue(b *testing.B, bench func(b *testing.B, elems []KType)) {
+	for _, n := range benchBlockingQueueSizes {
+		n := n
+		var elems []KType
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			// once for all the runs of the benchmark, and only if it's run
+			if elems == nil {
+				rnd := rand.New(rand.NewSource(int64(n)))
+				elems = make([]KType, n)
+				for i := range elems {
+					elems[i] = benchKType(rnd)
+				}
+			}
+			b.ResetTimer()
+			bench(b, elems)
+		})
+	}
+}
+
+func BenchmarkBlockingQueuePushPop(b *testing.B) {
+	benchBlockingQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := NewBlockingQueue(n)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Push(elems[i%n])
+			q.TryPop()
+		}
+	})
+}
+
+// BenchmarkBlockingQueueProducerConsumer is to be compared with
+// BenchmarkBlockingQueueProducerConsumerChan.
+func BenchmarkBlockingQueueProducerConsumer(b *testing.B) {
+	benchBlockingQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := NewBlockingQueue(n)
+		ctx := context.Background()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < b.N; i++ {
+				q.PopWait(ctx)
+			}
+		}()
+		for i := 0; i < b.N; i++ {
+			q.PushWait(ctx, elems[i%n])
+		}
+		<-done
+	})
+}
+
+// BenchmarkBlockingQueueProducerConsumerChan sends the elements through a
+// channel of the same capacity.
+func BenchmarkBlockingQueueProducerConsumerChan(b *testing.B) {
+	benchBlockingQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		c := make(chan KType, n)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < b.N; i++ {
+				<-c
+			}
+		}()
+		for i := 0; i < b.N; i++ {
+			c <- elems[i%n]
+		}
+		<-done
+	})
+}
@@ -0,0 +1,174 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The tests of this file are generated along with blocking queues, when asked
+// to. The elements are generated by randomKType. Run them with -race.
+
+// checkBlockingQueue verifies that the head, the tail and the count of the
+// queue agree, and that the slots out of the queue are cleared.
+func checkBlockingQueue(t *testing.T, q *BlockingQueue) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.count < 0 || q.count > len(q.buf) {
+		t.Fatalf("count %d out of a buffer of %d", q.count, len(q.buf))
+	}
+	if q.head < 0 || q.head >= len(q.buf) {
+		t.Fatalf("head %d out of a buffer of %d", q.head, len(q.buf))
+	}
+	if want := (q.head + q.count) % len(q.buf); q.tail != want {
+		t.Fatalf("head %d and count %d: want tail %d, got %d", q.head, q.count, want, q.tail)
+	}
+	var zero KType
+	for i := q.count; i < len(q.buf); i++ {
+		if at := (q.head + i) % len(q.buf); !reflect.DeepEqual(q.buf[at], zero) {
+			t.Fatalf("slot %d out of the queue holds %v", at, q.buf[at])
+		}
+	}
+}
+
+func TestBlockingQueueMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	q := NewBlockingQueue(50)
+	var ref []KType
+
+	for i := 0; i < 5000; i++ {
+		// favor pushes, then pops, so the queue fills up and empties
+		push := 6
+		if i%2000 >= 1000 {
+			push = 4
+		}
+		if rnd.Intn(10) < push {
+			k := randomKType(rnd)
+			full := len(ref) == q.Cap()
+			if pushed := q.Push(k); pushed == full {
+				t.Fatalf("push on a queue of %d/%d: want %v, got %v", len(ref), q.Cap(), !full, pushed)
+			}
+			if !full {
+				ref = append(ref, k)
+			}
+		} else if len(ref) != 0 {
+			if got, ok := q.TryPeek(); !ok || !reflect.DeepEqual(ref[0], got) {
+				t.Fatalf("try peek: want %v, true, got %v, %v", ref[0], got, ok)
+			}
+			if i%2 == 0 {
+				if got, err := q.PopWait(context.Background()); err != nil || !reflect.DeepEqual(ref[0], got) {
+					t.Fatalf("pop wait: want %v, nil, got %v, %v", ref[0], got, err)
+				}
+			} else if got, ok := q.TryPop(); !ok || !reflect.DeepEqual(ref[0], got) {
+				t.Fatalf("try pop: want %v, true, got %v, %v", ref[0], got, ok)
+			}
+			ref = ref[1:]
+		} else {
+			if got, ok := q.TryPeek(); ok {
+				t.Fatalf("try peek: want nothing, got %v", got)
+			}
+			if got, ok := q.TryPop(); ok {
+				t.Fatalf("try pop: want nothing, got %v", got)
+			}
+		}
+		checkBlockingQueue(t, q)
+		if q.Len() != len(ref) {
+			t.Fatalf("want len %d, got %d", len(ref), q.Len())
+		}
+	}
+}
+
+// TestBlockingQueueProducersConsumers has goroutines pushing and popping
+// through a small queue, waiting on each other, and verifies that every
+// element pushed is popped once.
+func TestBlockingQueueProducersConsumers(t *testing.T) {
+	const producers, consumers, perProducer = 4, 4, 500
+	rnd := rand.New(rand.NewSource(42))
+	elems := make([][]KType, producers)
+	for i := range elems {
+		elems[i] = make([]KType, perProducer)
+		for j := range elems[i] {
+			elems[i][j] = randomKType(rnd)
+		}
+	}
+
+	q := NewBlockingQueue(8)
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for _, e := range elems {
+		wg.Add(1)
+		go func(e []KType) {
+			defer wg.Done()
+			for _, k := range e {
+				if err := q.PushWait(ctx, k); err != nil {
+					t.Errorf("push wait: %v", err)
+				}
+			}
+		}(e)
+	}
+	popped := make([][]KType, consumers)
+	for i := range popped {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < producers*perProducer/consumers; j++ {
+				k, err := q.PopWait(ctx)
+				if err != nil {
+					t.Errorf("pop wait: %v", err)
+				}
+				popped[i] = append(popped[i], k)
+			}
+		}(i)
+	}
+	wg.Wait()
+	checkBlockingQueue(t, q)
+
+	// the elements of any type are told apart by how they're printed
+	count := make(map[string]int)
+	for _, e := range elems {
+		for _, k := range e {
+			count[fmt.Sprintf("%#v", k)]++
+		}
+	}
+	for _, p := range popped {
+		for _, k := range p {
+			count[fmt.Sprintf("%#v", k)]--
+		}
+	}
+	for k, n := range count {
+		if n != 0 {
+			t.Errorf("%s: pushed %d more times than popped", k, n)
+		}
+	}
+	if q.Len() != 0 {
+		t.Errorf("want len 0, got %d", q.Len())
+	}
+}
+
+func TestBlockingQueueWaitIsCancelled(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	q := NewBlockingQueue(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got, err := q.PopWait(ctx); err != context.Canceled {
+		t.Fatalf("pop wait on an empty queue: want %v, got %v, %v", context.Canceled, got, err)
+	}
+
+	k := randomKType(rnd)
+	q.Push(k)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.PushWait(ctx, randomKType(rnd)); err != context.DeadlineExceeded {
+		t.Fatalf("push wait on a full queue: want %v, got %v", context.DeadlineExceeded, err)
+	}
+	// an element is there, the context doesn't matter
+	if got, err := q.PopWait(ctx); err != nil || !reflect.DeepEqual(k, got) {
+		t.Fatalf("pop wait: want %v, nil, got %v, %v", k, got, err)
+	}
+	checkBlockingQueue(t, q)
+}
@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBlockingQueuePopWaitsForPush(t *testing.T) {
+	q := NewBlockingQueue(1)
+	popped := make(chan int)
+	go func() {
+		k, err := q.PopWait(context.Background())
+		if err != nil {
+			t.Errorf("pop wait: %v", err)
+		}
+		popped <- k.(int)
+	}()
+
+	select {
+	case k := <-popped:
+		t.Fatalf("popped %d from an empty queue", k)
+	case <-time.After(10 * time.Millisecond):
+	}
+	q.Push(1)
+	if k := <-popped; k != 1 {
+		t.Errorf("pop wait: want 1, got %d", k)
+	}
+}
+
+func TestBlockingQueuePushWaitsForPop(t *testing.T) {
+	q := NewBlockingQueue(1)
+	q.Push(1)
+	if q.Push(2) {
+		t.Fatal("push accepted by a full queue")
+	}
+	pushed := make(chan struct{})
+	go func() {
+		if err := q.PushWait(context.Background(), 2); err != nil {
+			t.Errorf("push wait: %v", err)
+		}
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("pushed onto a full queue")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if k, _ := q.TryPop(); k.(int) != 1 {
+		t.Errorf("try pop: want 1, got %d", k)
+	}
+	<-pushed
+	if k, _ := q.TryPeek(); k.(int) != 2 {
+		t.Errorf("try peek: want 2, got %d", k)
+	}
+}
+
+func TestBlockingQueueCancelWakesWaiters(t *testing.T) {
+	q := NewBlockingQueue(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := q.PopWait(ctx); err != context.Canceled {
+				t.Errorf("pop wait: want %v, got %v", context.Canceled, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	// the queue still works for those not cancelled
+	q.Push(1)
+	if k, err := q.PopWait(context.Background()); err != nil || k.(int) != 1 {
+		t.Errorf("pop wait: want 1, nil, got %v, %v", k, err)
+	}
+}
+
+func TestBlockingQueueCapacityPanics(t *testing.T) {
+	assertPanics(t, "should panic with no capacity", func() {
+		NewBlockingQueue(0)
+	})
+}
@@ -0,0 +1,105 @@
+package queue
+
+// The ring buffer is the one of the queue, which never grows nor shrinks.
+
+// BoundedQueue represents a single instance of the queue data structure,
+// holding at most a fixed number of elements. When it's full, pushing an
+// element either fails, or overwrites the oldest element of the queue, as
+// chosen when creating it.
+type BoundedQueue struct {
+	buf               []KType
+	head, tail, count int
+	overwrite         bool
+}
+
+// NewBoundedQueue constructs and returns a new BoundedQueue holding at most
+// capacity elements, for which the memory is allocated at once. If overwrite
+// is true, pushing onto a full queue drops its oldest element, as a circular
+// log would; otherwise the push is rejected. This call panics if the capacity
+// isn't positive.
+func NewBoundedQueue(capacity int, overwrite bool) *BoundedQueue {
+	if capacity <= 0 {
+		panic("queue: capacity must be positive")
+	}
+	return &BoundedQueue{buf: make([]KType, capacity), overwrite: overwrite}
+}
+
+// Len returns the number of elements currently stored in the queue.
+func (q *BoundedQueue) Len() int {
+	return q.count
+}
+
+// Cap returns the maximum number of elements the queue can hold.
+func (q *BoundedQueue) Cap() int {
+	return len(q.buf)
+}
+
+// Push puts an element on the end of the queue, and tells if it did. On a
+// full queue, the push fails unless the queue overwrites its oldest element.
+func (q *BoundedQueue) Push(elem KType) bool {
+	if q.count == len(q.buf) {
+		if !q.overwrite {
+			return false
+		}
+		// the tail of a full queue is on its head: drop the oldest element
+		q.head = (q.head + 1) % len(q.buf)
+		q.count--
+	}
+
+	q.buf[q.tail] = elem
+	q.tail = (q.tail + 1) % len(q.buf)
+	q.count++
+	return true
+}
+
+// Peek returns the element at the head of the queue. This call panics
+// if the queue is empty.
+func (q *BoundedQueue) Peek() KType {
+	if q.count <= 0 {
+		panic("queue: empty queue")
+	}
+	return q.buf[q.head]
+}
+
+// TryPeek is like Peek, but tells if there was an element instead of
+// panicking on an empty queue.
+func (q *BoundedQueue) TryPeek() (k KType, ok bool) {
+	if q.count == 0 {
+		return k, false
+	}
+	return q.buf[q.head], true
+}
+
+// Get returns the element at index i in the queue. If the index is
+// invalid, the call will panic.
+func (q *BoundedQueue) Get(i int) KType {
+	if i >= q.count || i < 0 {
+		panic("queue: index out of range")
+	}
+	return q.buf[(q.head+i)%len(q.buf)]
+}
+
+// Pop removes the element from the front of the queue.
+// This call panics if the queue is empty.
+func (q *BoundedQueue) Pop() KType {
+	if q.count <= 0 {
+		panic("queue: empty queue")
+	}
+	v := q.buf[q.head]
+	// set to the zero value to avoid keeping reference to objects
+	// that would otherwise be garbage collected
+	var zero KType
+	q.buf[q.head] = zero
+	q.head = (q.head + 1) % len(q.buf)
+	q.count--
+	return v
+}
+
+// TryPop is like Pop, but tells if there was an element instead of panicking
+// on an empty queue.
+func (q *BoundedQueue) TryPop() (k KType, ok bool) {
+	if q.count == 0 {
+		return k, false
+	}
+	return q.Pop(), true
+}
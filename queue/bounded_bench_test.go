@@ -0,0 +1,89 @@
+package queue
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// The benchmarks of this file are generated along with bounded queues, when
+// asked to. The elements are generated by benchKType.
+
+// benchBoundedQueueSizes are the capacities of the benchmarked queues.
+var benchBoundedQueueSizes = []int{100, 10000, 1000000}
+
+// benchBoundedQueue runs bench for each size, with that many random elements.
+// The elements are the same from one benchmark to the other.
+func benchBoundedQueue(b *testing.B, bench func(b *testing.B, elems []KType)) {
+	for _, n := range benchBoundedQueueSizes {
+		n := n
+		var elems []KType
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			// once for all the runs of the benchmark, and only if it's run
+			if elems == nil {
+				rnd := rand.New(rand.NewSource(int64(n)))
+				elems = make([]KType, n)
+				for i := range elems {
+					elems[i] = benchKType(rnd)
+				}
+			}
+			b.ResetTimer()
+			bench(b, elems)
+		})
+	}
+}
+
+// fillBoundedQueue returns a full queue holding elems.
+func fillBoundedQueue(elems []KType, overwrite bool) *BoundedQueue {
+	q := NewBoundedQueue(len(elems), overwrite)
+	for _, e := range elems {
+		q.Push(e)
+	}
+	return q
+}
+
+func BenchmarkBoundedQueuePushPop(b *testing.B) {
+	benchBoundedQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := fillBoundedQueue(elems, false)
+		q.Pop()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Push(elems[i%n])
+			q.Pop()
+		}
+	})
+}
+
+func BenchmarkBoundedQueueOverwrite(b *testing.B) {
+	benchBoundedQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := fillBoundedQueue(elems, true)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Push(elems[i%n])
+		}
+	})
+}
+
+func BenchmarkBoundedQueueReject(b *testing.B) {
+	benchBoundedQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := fillBoundedQueue(elems, false)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Push(elems[i%n])
+		}
+	})
+}
+
+func BenchmarkBoundedQueueGetAt(b *testing.B) {
+	benchBoundedQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := fillBoundedQueue(elems, false)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Get(i % n)
+		}
+	})
+}
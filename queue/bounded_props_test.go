@@ -0,0 +1,188 @@
+package queue
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// The tests of this file are generated along with bounded queues, when asked
+// to. The elements are generated by randomKType.
+
+// checkBoundedQueue verifies that the head, the tail and the count of the
+// queue agree, and that the slots out of the queue are cleared.
+func checkBoundedQueue(t *testing.T, q *BoundedQueue) {
+	if q.count < 0 || q.count > len(q.buf) {
+		t.Fatalf("count %d out of a buffer of %d", q.count, len(q.buf))
+	}
+	if q.head < 0 || q.head >= len(q.buf) {
+		t.Fatalf("head %d out of a buffer of %d", q.head, len(q.buf))
+	}
+	if want := (q.head + q.count) % len(q.buf); q.tail != want {
+		t.Fatalf("head %d and count %d: want tail %d, got %d", q.head, q.count, want, q.tail)
+	}
+	var zero KType
+	for i := q.count; i < len(q.buf); i++ {
+		if at := (q.head + i) % len(q.buf); !reflect.DeepEqual(q.buf[at], zero) {
+			t.Fatalf("slot %d out of the queue holds %v", at, q.buf[at])
+		}
+	}
+}
+
+// checkBoundedQueueElems verifies that q holds the elements of ref, in order.
+func checkBoundedQueueElems(t *testing.T, q *BoundedQueue, ref []KType) {
+	if q.Len() != len(ref) {
+		t.Fatalf("want len %d, got %d", len(ref), q.Len())
+	}
+	for i, want := range ref {
+		if got := q.Get(i); !reflect.DeepEqual(want, got) {
+			t.Fatalf("get %d: want %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestBoundedQueueMatchesReference(t *testing.T) {
+	for _, overwrite := range []bool{false, true} {
+		rnd := rand.New(rand.NewSource(42))
+		q := NewBoundedQueue(50, overwrite)
+		var ref []KType
+
+		for i := 0; i < 5000; i++ {
+			// favor pushes, then pops, so the queue fills up and empties
+			push := 6
+			if i%2000 >= 1000 {
+				push = 4
+			}
+			if rnd.Intn(10) < push {
+				k := randomKType(rnd)
+				full := len(ref) == q.Cap()
+				if pushed := q.Push(k); pushed != (overwrite || !full) {
+					t.Fatalf("push on a queue of %d/%d: want %v, got %v", len(ref), q.Cap(), !pushed, pushed)
+				}
+				if full && overwrite {
+					ref = ref[1:]
+				}
+				if !full || overwrite {
+					ref = append(ref, k)
+				}
+			} else if len(ref) != 0 {
+				if want, got := ref[0], q.Peek(); !reflect.DeepEqual(want, got) {
+					t.Fatalf("peek: want %v, got %v", want, got)
+				}
+				if i%2 == 0 {
+					if want, got := ref[0], q.Pop(); !reflect.DeepEqual(want, got) {
+						t.Fatalf("pop: want %v, got %v", want, got)
+					}
+				} else if got, ok := q.TryPop(); !ok || !reflect.DeepEqual(ref[0], got) {
+					t.Fatalf("try pop: want %v, true, got %v, %v", ref[0], got, ok)
+				}
+				ref = ref[1:]
+			} else {
+				checkBoundedQueueEmpty(t, q)
+			}
+			checkBoundedQueue(t, q)
+			if i%100 == 0 {
+				checkBoundedQueueElems(t, q, ref)
+			}
+		}
+		checkBoundedQueueElems(t, q, ref)
+	}
+}
+
+func TestBoundedQueueWrapsAroundAtCapacity(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	for _, overwrite := range []bool{false, true} {
+		q := NewBoundedQueue(16, overwrite)
+		var ref []KType
+		push := func(n int) {
+			for i := 0; i < n; i++ {
+				k := randomKType(rnd)
+				if !q.Push(k) {
+					continue
+				}
+				if len(ref) == q.Cap() {
+					ref = ref[1:]
+				}
+				ref = append(ref, k)
+				checkBoundedQueue(t, q)
+			}
+		}
+
+		// move the head to the middle of the buffer, then fill it up, the
+		// tail wrapping around to the head
+		push(10)
+		for i := 0; i < 8; i++ {
+			q.Pop()
+			ref = ref[1:]
+		}
+		push(14)
+		if q.Len() != q.Cap() || q.tail != q.head || q.head != 8 {
+			t.Fatalf("want a full queue from 8, got head %d, tail %d, len %d", q.head, q.tail, q.Len())
+		}
+		checkBoundedQueueElems(t, q, ref)
+
+		// push past the capacity, many times around the buffer
+		push(3*q.Cap() + 5)
+		if q.Len() != q.Cap() {
+			t.Fatalf("want a full queue, got len %d", q.Len())
+		}
+		checkBoundedQueueElems(t, q, ref)
+
+		for len(ref) > 0 {
+			if want, got := ref[0], q.Pop(); !reflect.DeepEqual(want, got) {
+				t.Fatalf("pop: want %v, got %v", want, got)
+			}
+			ref = ref[1:]
+			checkBoundedQueue(t, q)
+		}
+		checkBoundedQueueEmpty(t, q)
+	}
+}
+
+// checkBoundedQueueEmpty verifies that the empty queue q has nothing to peek,
+// pop or get.
+func checkBoundedQueueEmpty(t *testing.T, q *BoundedQueue) {
+	if q.Len() != 0 {
+		t.Fatalf("want len 0, got %d", q.Len())
+	}
+	if got, ok := q.TryPeek(); ok {
+		t.Fatalf("try peek: want nothing, got %v", got)
+	}
+	if got, ok := q.TryPop(); ok {
+		t.Fatalf("try pop: want nothing, got %v", got)
+	}
+	for _, op := range []struct {
+		name string
+		f    func()
+	}{
+		{"peek", func() { q.Peek() }},
+		{"pop", func() { q.Pop() }},
+		{"get", func() { q.Get(0) }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s: want a panic on an empty queue", op.name)
+				}
+			}()
+			op.f()
+		}()
+	}
+	checkBoundedQueue(t, q)
+}
+
+func TestBoundedQueueEmpty(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	q := NewBoundedQueue(1, false)
+	checkBoundedQueueEmpty(t, q)
+
+	k := randomKType(rnd)
+	q.Push(k)
+	if got, ok := q.TryPeek(); !ok || !reflect.DeepEqual(k, got) {
+		t.Fatalf("try peek: want %v, true, got %v, %v", k, got, ok)
+	}
+	if got, ok := q.TryPop(); !ok || !reflect.DeepEqual(k, got) {
+		t.Fatalf("try pop: want %v, true, got %v, %v", k, got, ok)
+	}
+	checkBoundedQueueEmpty(t, q)
+}
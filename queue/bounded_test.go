@@ -0,0 +1,62 @@
+package queue
+
+import "testing"
+
+func TestBoundedQueueRejects(t *testing.T) {
+	q := NewBoundedQueue(3, false)
+	for i := 0; i < 3; i++ {
+		if !q.Push(i) {
+			t.Fatalf("push %d rejected by a queue of %d/%d", i, q.Len(), q.Cap())
+		}
+	}
+	if q.Push(3) {
+		t.Fatal("push accepted by a full queue")
+	}
+	if got := q.Pop().(int); got != 0 {
+		t.Errorf("pop: want 0, got %d", got)
+	}
+	if !q.Push(3) {
+		t.Fatal("push rejected after a pop")
+	}
+	for i := 1; i <= 3; i++ {
+		if got := q.Pop().(int); got != i {
+			t.Errorf("pop: want %d, got %d", i, got)
+		}
+	}
+}
+
+func TestBoundedQueueOverwrites(t *testing.T) {
+	q := NewBoundedQueue(3, true)
+	for i := 0; i < 10; i++ {
+		if !q.Push(i) {
+			t.Fatalf("push %d rejected by an overwriting queue", i)
+		}
+	}
+	if q.Len() != 3 {
+		t.Fatalf("want len 3, got %d", q.Len())
+	}
+	// the last 3 elements are kept, oldest first
+	for i := 0; i < 3; i++ {
+		if got := q.Get(i).(int); got != 7+i {
+			t.Errorf("index %d: want %d, got %d", i, 7+i, got)
+		}
+	}
+}
+
+func TestBoundedQueuePanics(t *testing.T) {
+	assertPanics(t, "should panic with no capacity", func() {
+		NewBoundedQueue(0, true)
+	})
+
+	q := NewBoundedQueue(2, false)
+	assertPanics(t, "should panic when peeking empty queue", func() {
+		q.Peek()
+	})
+	assertPanics(t, "should panic when removing empty queue", func() {
+		q.Pop()
+	})
+	q.Push(1)
+	assertPanics(t, "should panic when index greater than length", func() {
+		q.Get(1)
+	})
+}
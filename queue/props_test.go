@@ -0,0 +1,181 @@
+package queue
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// The tests of this file are generated along with queues, when asked to. The
+// elements are generated by randomKType.
+
+// checkQueue verifies that the head, the tail and the count of the queue
+// agree, and that the buffer never shrinks below its minimum length.
+func checkQueue(t *testing.T, q *Queue) {
+	if len(q.buf) < q.minlen {
+		t.Fatalf("buffer of %d shrunk below %d", len(q.buf), q.minlen)
+	}
+	if q.count < 0 || q.count > len(q.buf) {
+		t.Fatalf("count %d out of a buffer of %d", q.count, len(q.buf))
+	}
+	if q.head < 0 || q.head >= len(q.buf) {
+		t.Fatalf("head %d out of a buffer of %d", q.head, len(q.buf))
+	}
+	if want := (q.head + q.count) % len(q.buf); q.tail != want {
+		t.Fatalf("head %d and count %d: want tail %d, got %d", q.head, q.count, want, q.tail)
+	}
+}
+
+// checkQueueElems verifies that q holds the elements of ref, in order.
+func checkQueueElems(t *testing.T, q *Queue, ref []KType) {
+	if q.Len() != len(ref) {
+		t.Fatalf("want len %d, got %d", len(ref), q.Len())
+	}
+	for i, want := range ref {
+		if got := q.Get(i); !reflect.DeepEqual(want, got) {
+			t.Fatalf("get %d: want %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestQueueMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	q := NewQueue(0)
+	var ref []KType
+
+	for i := 0; i < 5000; i++ {
+		// favor pushes, then pops, so the buffer grows and shrinks
+		push := 6
+		if i%2000 >= 1000 {
+			push = 4
+		}
+		if rnd.Intn(10) < push {
+			k := randomKType(rnd)
+			q.Push(k)
+			ref = append(ref, k)
+		} else if len(ref) != 0 {
+			if want, got := ref[0], q.Peek(); !reflect.DeepEqual(want, got) {
+				t.Fatalf("peek: want %v, got %v", want, got)
+			}
+			if got, ok := q.TryPeek(); !ok || !reflect.DeepEqual(ref[0], got) {
+				t.Fatalf("try peek: want %v, true, got %v, %v", ref[0], got, ok)
+			}
+			if i%2 == 0 {
+				if want, got := ref[0], q.Pop(); !reflect.DeepEqual(want, got) {
+					t.Fatalf("pop: want %v, got %v", want, got)
+				}
+			} else if got, ok := q.TryPop(); !ok || !reflect.DeepEqual(ref[0], got) {
+				t.Fatalf("try pop: want %v, true, got %v, %v", ref[0], got, ok)
+			}
+			ref = ref[1:]
+		} else {
+			checkQueueEmpty(t, q)
+		}
+		checkQueue(t, q)
+		if i%100 == 0 {
+			checkQueueElems(t, q, ref)
+		}
+	}
+	checkQueueElems(t, q, ref)
+}
+
+func TestQueueWrapsAround(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	q := NewQueue(16)
+	var ref []KType
+	push := func(n int) {
+		for i := 0; i < n; i++ {
+			k := randomKType(rnd)
+			q.Push(k)
+			ref = append(ref, k)
+			checkQueue(t, q)
+		}
+	}
+	pop := func(n int) {
+		for i := 0; i < n; i++ {
+			if want, got := ref[0], q.Pop(); !reflect.DeepEqual(want, got) {
+				t.Fatalf("pop: want %v, got %v", want, got)
+			}
+			ref = ref[1:]
+			checkQueue(t, q)
+		}
+	}
+
+	// move the head to the middle of the buffer, then wrap the tail around it
+	push(10)
+	pop(8)
+	push(12)
+	if q.tail >= q.head {
+		t.Fatalf("want the tail wrapped before the head, got head %d, tail %d", q.head, q.tail)
+	}
+	checkQueueElems(t, q, ref)
+
+	// fill the buffer while wrapped, growing it
+	push(len(q.buf) - q.count + 1)
+	checkQueueElems(t, q, ref)
+
+	// wrap again and shrink
+	pop(q.count - 4)
+	push(len(q.buf) - q.head)
+	pop(q.count - 2)
+	checkQueueElems(t, q, ref)
+	pop(q.count)
+	checkQueueElems(t, q, ref)
+}
+
+// checkQueueEmpty verifies that the empty queue q has nothing to peek, pop or
+// get.
+func checkQueueEmpty(t *testing.T, q *Queue) {
+	if q.Len() != 0 {
+		t.Fatalf("want len 0, got %d", q.Len())
+	}
+	if got, ok := q.TryPeek(); ok {
+		t.Fatalf("try peek: want nothing, got %v", got)
+	}
+	if got, ok := q.TryPop(); ok {
+		t.Fatalf("try pop: want nothing, got %v", got)
+	}
+	for _, op := range []struct {
+		name string
+		f    func()
+	}{
+		{"peek", func() { q.Peek() }},
+		{"pop", func() { q.Pop() }},
+		{"get", func() { q.Get(0) }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s: want a panic on an empty queue", op.name)
+				}
+			}()
+			op.f()
+		}()
+	}
+	checkQueue(t, q)
+}
+
+func TestQueueEmpty(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	q := NewQueue(0)
+	checkQueueEmpty(t, q)
+
+	k := randomKType(rnd)
+	q.Push(k)
+	if got, ok := q.TryPop(); !ok || !reflect.DeepEqual(k, got) {
+		t.Fatalf("try pop: want %v, true, got %v, %v", k, got, ok)
+	}
+	checkQueueEmpty(t, q)
+
+	// empty after wrapping around, and after shrinking
+	for i := 0; i < 100; i++ {
+		q.Push(randomKType(rnd))
+		if i%3 == 0 {
+			q.Pop()
+		}
+	}
+	for q.Len() > 0 {
+		q.Pop()
+	}
+	checkQueueEmpty(t, q)
+}
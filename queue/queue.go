@@ -47,6 +47,15 @@ func (q *Queue) Peek() KType {
 	return q.buf[q.head]
 }
 
+// TryPeek is like Peek, but tells if there was an element instead of
+// panicking on an empty queue.
+func (q *Queue) TryPeek() (KType, bool) {
+	if q.count == 0 {
+		return nilKType, false
+	}
+	return q.buf[q.head], true
+}
+
 // Get returns the element at index i in the queue. If the index is
 // invalid, the call will panic.
 func (q *Queue) Get(i int) KType {
@@ -75,6 +84,15 @@ func (q *Queue) Pop() KType {
 	return v
 }
 
+// TryPop is like Pop, but tells if there was an element instead of panicking
+// on an empty queue.
+func (q *Queue) TryPop() (KType, bool) {
+	if q.count == 0 {
+		return nilKType, false
+	}
+	return q.Pop(), true
+}
+
 func (q *Queue) resize() {
 	newBuf := make([]KType, q.count*2)
 
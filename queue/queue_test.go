@@ -1,6 +1,13 @@
 package queue
 
-import "testing"
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomKType(r *rand.Rand) KType { return r.Intn(1000) }
+
+func benchKType(r *rand.Rand) KType { return r.Int() }
 
 func TestQueueLen(t *testing.T) {
 	q := NewQueue(0)
@@ -0,0 +1,6 @@
+// Package ringq provides fixed-capacity queues of KType that goroutines
+// share without locks: an SPSCQueue between a single producer and a single
+// consumer, and an MPMCQueue between any number of them.
+package ringq
+
+type KType interface{}
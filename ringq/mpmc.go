@@ -0,0 +1,114 @@
+package ringq
+
+import "sync/atomic"
+
+// The implementation is Dmitry Vyukov's bounded MPMC queue: each slot of the
+// ring buffer has a sequence number telling whether it's ready to be pushed
+// to or popped from for a given lap around the buffer, and producers and
+// consumers claim their slot by moving the tail or the head with a CAS.
+
+// MPMCQueue represents a single instance of a queue data structure, with a
+// fixed capacity, shared without locks between any number of goroutines
+// pushing and popping elements.
+type MPMCQueue struct {
+	// head is moved by the consumers
+	head uintptr
+	_    [64]byte // on its own cache line
+	// tail is moved by the producers
+	tail uintptr
+	_    [64]byte
+	buf  []mpmcSlot
+	mask uintptr
+}
+
+// mpmcSlot is a slot of the ring buffer. Its sequence number is the tail
+// pushing to it while it's free, and that tail plus one once the element is
+// there.
+type mpmcSlot struct {
+	seq  uintptr
+	elem KType
+}
+
+// NewMPMCQueue constructs and returns a new MPMCQueue holding at most
+// capacity elements, rounded up to a power of two, for which the memory is
+// allocated at once. This call panics if the capacity isn't positive.
+func NewMPMCQueue(capacity int) *MPMCQueue {
+	if capacity <= 0 {
+		panic("ringq: capacity must be positive")
+	}
+	n := 1
+	for n < capacity {
+		n *= 2
+	}
+	q := &MPMCQueue{buf: make([]mpmcSlot, n), mask: uintptr(n - 1)}
+	for i := range q.buf {
+		q.buf[i].seq = uintptr(i)
+	}
+	return q
+}
+
+// Len returns the number of elements stored in the queue, which may have
+// changed by the time it returns.
+func (q *MPMCQueue) Len() int {
+	head := atomic.LoadUintptr(&q.head)
+	n := int(atomic.LoadUintptr(&q.tail) - head)
+	// the head can move past the tail read before it
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// Cap returns the maximum number of elements the queue can hold.
+func (q *MPMCQueue) Cap() int {
+	return len(q.buf)
+}
+
+// Push puts an element on the end of the queue, and tells if it did, which
+// it doesn't if the queue is full.
+func (q *MPMCQueue) Push(elem KType) bool {
+	tail := atomic.LoadUintptr(&q.tail)
+	for {
+		slot := &q.buf[tail&q.mask]
+		switch seq := atomic.LoadUintptr(&slot.seq); {
+		case seq == tail:
+			// free for this lap, unless another producer claims it first
+			if atomic.CompareAndSwapUintptr(&q.tail, tail, tail+1) {
+				slot.elem = elem
+				atomic.StoreUintptr(&slot.seq, tail+1)
+				return true
+			}
+		case int(seq-tail) < 0:
+			// still holds the element of the previous lap
+			return false
+		}
+		tail = atomic.LoadUintptr(&q.tail)
+	}
+}
+
+// TryPop removes the element from the front of the queue, and tells if there
+// was one.
+func (q *MPMCQueue) TryPop() (k KType, ok bool) {
+	head := atomic.LoadUintptr(&q.head)
+	for {
+		slot := &q.buf[head&q.mask]
+		switch seq := atomic.LoadUintptr(&slot.seq); {
+		case seq == head+1:
+			// pushed to, unless another consumer claims it first
+			if atomic.CompareAndSwapUintptr(&q.head, head, head+1) {
+				k = slot.elem
+				// set to the zero value to avoid keeping reference to objects
+				// that would otherwise be garbage collected
+				var zero KType
+				slot.elem = zero
+				// free for the next lap
+				atomic.StoreUintptr(&slot.seq, head+q.mask+1)
+				return k, true
+			}
+		case int(seq-(head+1)) < 0:
+			// not pushed to yet
+			return k, false
+		}
+		head = atomic.LoadUintptr(&q.head)
+	}
+}
@@ -0,0 +1,109 @@
+package ringq
+
+import (
+	"math/rand"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// The benchmarks of this file are generated along with MPMC queues, when
+// asked to. The elements are generated by benchKType.
+
+// benchMPMCQueueSizes are the capacities of the benchmarked queues.
+var benchMPMCQueueSizes = []int{100, 10000, 1000000}
+
+// benchMPMCQueue runs bench for each size, with that many random elements.
+// The elements are the same from one benchmark to the other.
+func benchMPMCQueue(b *testing.B, bench func(b *testing.B, elems []KType)) {
+	for _, n := range benchMPMCQueueSizes {
+		n := n
+		var elems []KType
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			// once for all the runs of the benchmark, and only if it's run
+			if elems == nil {
+				rnd := rand.New(rand.NewSource(int64(n)))
+				elems = make([]KType, n)
+				for i := range elems {
+					elems[i] = benchKType(rnd)
+				}
+			}
+			b.ResetTimer()
+			bench(b, elems)
+		})
+	}
+}
+
+func BenchmarkMPMCQueuePushPop(b *testing.B) {
+	benchMPMCQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := NewMPMCQueue(n)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Push(elems[i%n])
+			q.TryPop()
+		}
+	})
+}
+
+// BenchmarkMPMCQueueProducersConsumers is to be compared with
+// BenchmarkMPMCQueueProducersConsumersChan. The elements are pushed and
+// popped by as many goroutines as GOMAXPROCS, half of them each.
+func BenchmarkMPMCQueueProducersConsumers(b *testing.B) {
+	benchMPMCQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := NewMPMCQueue(n)
+		b.ResetTimer()
+		workers := runtime.GOMAXPROCS(0)/2 + 1
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(2)
+			go func(w int) {
+				defer wg.Done()
+				for i := w; i < b.N; i += workers {
+					for _, ok := q.TryPop(); !ok; _, ok = q.TryPop() {
+						runtime.Gosched()
+					}
+				}
+			}(w)
+			go func(w int) {
+				defer wg.Done()
+				for i := w; i < b.N; i += workers {
+					for !q.Push(elems[i%n]) {
+						runtime.Gosched()
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+	})
+}
+
+// BenchmarkMPMCQueueProducersConsumersChan sends the elements through a
+// channel of the same capacity.
+func BenchmarkMPMCQueueProducersConsumersChan(b *testing.B) {
+	benchMPMCQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		c := make(chan KType, n)
+		b.ResetTimer()
+		workers := runtime.GOMAXPROCS(0)/2 + 1
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(2)
+			go func(w int) {
+				defer wg.Done()
+				for i := w; i < b.N; i += workers {
+					<-c
+				}
+			}(w)
+			go func(w int) {
+				defer wg.Done()
+				for i := w; i < b.N; i += workers {
+					c <- elems[i%n]
+				}
+			}(w)
+		}
+		wg.Wait()
+	})
+}
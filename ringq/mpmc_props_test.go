@@ -0,0 +1,143 @@
+package ringq
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// The tests of this file are generated along with MPMC queues, when asked to.
+// The elements are generated by randomKType. Run them with -race.
+
+// checkMPMCQueue verifies that the queue holds at most its capacity, that the
+// sequence numbers of the slots agree with the head and the tail, and that
+// the slots out of the queue are cleared. It must not be called while the
+// queue is used.
+func checkMPMCQueue(t *testing.T, q *MPMCQueue) {
+	if n := q.tail - q.head; n > uintptr(len(q.buf)) {
+		t.Fatalf("head %d and tail %d out of a buffer of %d", q.head, q.tail, len(q.buf))
+	}
+	if int(q.mask) != len(q.buf)-1 || len(q.buf)&int(q.mask) != 0 {
+		t.Fatalf("mask %#x of a buffer of %d", q.mask, len(q.buf))
+	}
+	for pos := q.head; pos != q.tail; pos++ {
+		if slot := q.buf[pos&q.mask]; slot.seq != pos+1 {
+			t.Fatalf("slot %d of the queue: want sequence %d, got %d", pos&q.mask, pos+1, slot.seq)
+		}
+	}
+	var zero KType
+	for pos := q.tail; pos != q.head+uintptr(len(q.buf)); pos++ {
+		slot := q.buf[pos&q.mask]
+		if slot.seq != pos {
+			t.Fatalf("slot %d out of the queue: want sequence %d, got %d", pos&q.mask, pos, slot.seq)
+		}
+		if !reflect.DeepEqual(slot.elem, zero) {
+			t.Fatalf("slot %d out of the queue holds %v", pos&q.mask, slot.elem)
+		}
+	}
+}
+
+func TestMPMCQueueMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	q := NewMPMCQueue(50)
+	var ref []KType
+
+	for i := 0; i < 5000; i++ {
+		// favor pushes, then pops, so the queue fills up and empties
+		push := 6
+		if i%2000 >= 1000 {
+			push = 4
+		}
+		if rnd.Intn(10) < push {
+			k := randomKType(rnd)
+			full := len(ref) == q.Cap()
+			if pushed := q.Push(k); pushed == full {
+				t.Fatalf("push on a queue of %d/%d: want %v, got %v", len(ref), q.Cap(), !full, pushed)
+			}
+			if !full {
+				ref = append(ref, k)
+			}
+		} else if len(ref) != 0 {
+			if got, ok := q.TryPop(); !ok || !reflect.DeepEqual(ref[0], got) {
+				t.Fatalf("try pop: want %v, true, got %v, %v", ref[0], got, ok)
+			}
+			ref = ref[1:]
+		} else if got, ok := q.TryPop(); ok {
+			t.Fatalf("try pop: want nothing, got %v", got)
+		}
+		checkMPMCQueue(t, q)
+		if q.Len() != len(ref) {
+			t.Fatalf("want len %d, got %d", len(ref), q.Len())
+		}
+	}
+}
+
+// TestMPMCQueueProducersConsumers has goroutines pushing and popping through
+// a small queue, many times around it, and verifies that every element
+// pushed is popped once.
+func TestMPMCQueueProducersConsumers(t *testing.T) {
+	const producers, consumers, perProducer = 4, 4, 5000
+	rnd := rand.New(rand.NewSource(42))
+	elems := make([][]KType, producers)
+	for i := range elems {
+		elems[i] = make([]KType, perProducer)
+		for j := range elems[i] {
+			elems[i][j] = randomKType(rnd)
+		}
+	}
+
+	q := NewMPMCQueue(8)
+	var wg sync.WaitGroup
+	for _, e := range elems {
+		wg.Add(1)
+		go func(e []KType) {
+			defer wg.Done()
+			for _, k := range e {
+				for !q.Push(k) {
+					runtime.Gosched()
+				}
+			}
+		}(e)
+	}
+	popped := make([][]KType, consumers)
+	for i := range popped {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < producers*perProducer/consumers; j++ {
+				k, ok := q.TryPop()
+				for !ok {
+					runtime.Gosched()
+					k, ok = q.TryPop()
+				}
+				popped[i] = append(popped[i], k)
+			}
+		}(i)
+	}
+	wg.Wait()
+	checkMPMCQueue(t, q)
+
+	// the elements of any type are told apart by how they're printed
+	count := make(map[string]int)
+	for _, e := range elems {
+		for _, k := range e {
+			count[fmt.Sprintf("%#v", k)]++
+		}
+	}
+	for _, p := range popped {
+		for _, k := range p {
+			count[fmt.Sprintf("%#v", k)]--
+		}
+	}
+	for k, n := range count {
+		if n != 0 {
+			t.Errorf("%s: pushed %d more times than popped", k, n)
+		}
+	}
+	if q.Len() != 0 {
+		t.Errorf("want len 0, got %d", q.Len())
+	}
+}
@@ -0,0 +1,82 @@
+package ringq
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomKType(r *rand.Rand) KType { return r.Intn(1000) }
+
+func benchKType(r *rand.Rand) KType { return r.Int() }
+
+func TestSPSCQueueRoundsCapacity(t *testing.T) {
+	for capacity, want := range map[int]int{1: 1, 2: 2, 3: 4, 5: 8, 64: 64, 100: 128} {
+		if got := NewSPSCQueue(capacity).Cap(); got != want {
+			t.Errorf("capacity %d: want %d, got %d", capacity, want, got)
+		}
+		if got := NewMPMCQueue(capacity).Cap(); got != want {
+			t.Errorf("capacity %d: want %d, got %d", capacity, want, got)
+		}
+	}
+}
+
+func TestSPSCQueueRejectsWhenFull(t *testing.T) {
+	q := NewSPSCQueue(4)
+	for i := 0; i < 4; i++ {
+		if !q.Push(i) {
+			t.Fatalf("push %d rejected by a queue of %d/%d", i, q.Len(), q.Cap())
+		}
+	}
+	if q.Push(4) {
+		t.Fatal("push accepted by a full queue")
+	}
+	for i := 0; i < 4; i++ {
+		if k, ok := q.TryPop(); !ok || k.(int) != i {
+			t.Errorf("try pop: want %d, true, got %v, %v", i, k, ok)
+		}
+	}
+	if k, ok := q.TryPop(); ok {
+		t.Errorf("try pop: want nothing, got %v", k)
+	}
+}
+
+func TestMPMCQueueRejectsWhenFull(t *testing.T) {
+	q := NewMPMCQueue(4)
+	for i := 0; i < 4; i++ {
+		if !q.Push(i) {
+			t.Fatalf("push %d rejected by a queue of %d/%d", i, q.Len(), q.Cap())
+		}
+	}
+	if q.Push(4) {
+		t.Fatal("push accepted by a full queue")
+	}
+	for i := 0; i < 4; i++ {
+		if k, ok := q.TryPop(); !ok || k.(int) != i {
+			t.Errorf("try pop: want %d, true, got %v, %v", i, k, ok)
+		}
+	}
+	if k, ok := q.TryPop(); ok {
+		t.Errorf("try pop: want nothing, got %v", k)
+	}
+}
+
+func TestRingQueuesPanic(t *testing.T) {
+	assertPanics(t, "should panic with no capacity", func() {
+		NewSPSCQueue(0)
+	})
+	assertPanics(t, "should panic with a negative capacity", func() {
+		NewMPMCQueue(-1)
+	})
+}
+
+func assertPanics(t *testing.T, name string, f func()) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("%s: didn't panic as expected", name)
+		} else {
+			t.Logf("%s: got panic as expected: %v", name, r)
+		}
+	}()
+
+	f()
+}
@@ -0,0 +1,78 @@
+package ringq
+
+import "sync/atomic"
+
+// The ring buffer is laid out like the one of the queue, except that the
+// head and the tail count the elements popped and pushed since the start, so
+// that each is only written by one side. The capacity is a power of two, for
+// the positions in the buffer to be masks of the counts.
+
+// SPSCQueue represents a single instance of a queue data structure, with a
+// fixed capacity, shared without locks between a single producer pushing
+// elements and a single consumer popping them, each in its own goroutine.
+type SPSCQueue struct {
+	// head is only written by the consumer
+	head uintptr
+	_    [64]byte // on its own cache line
+	// tail is only written by the producer
+	tail uintptr
+	_    [64]byte
+	buf  []KType
+	mask uintptr
+}
+
+// NewSPSCQueue constructs and returns a new SPSCQueue holding at most
+// capacity elements, rounded up to a power of two, for which the memory is
+// allocated at once. This call panics if the capacity isn't positive.
+func NewSPSCQueue(capacity int) *SPSCQueue {
+	if capacity <= 0 {
+		panic("ringq: capacity must be positive")
+	}
+	n := 1
+	for n < capacity {
+		n *= 2
+	}
+	return &SPSCQueue{buf: make([]KType, n), mask: uintptr(n - 1)}
+}
+
+// Len returns the number of elements stored in the queue, which may have
+// changed by the time it returns.
+func (q *SPSCQueue) Len() int {
+	head := atomic.LoadUintptr(&q.head)
+	return int(atomic.LoadUintptr(&q.tail) - head)
+}
+
+// Cap returns the maximum number of elements the queue can hold.
+func (q *SPSCQueue) Cap() int {
+	return len(q.buf)
+}
+
+// Push puts an element on the end of the queue, and tells if it did, which
+// it doesn't if the queue is full. It must only be called by the producer.
+func (q *SPSCQueue) Push(elem KType) bool {
+	tail := q.tail
+	if tail-atomic.LoadUintptr(&q.head) == uintptr(len(q.buf)) {
+		return false
+	}
+	q.buf[tail&q.mask] = elem
+	// publishes the element to the consumer
+	atomic.StoreUintptr(&q.tail, tail+1)
+	return true
+}
+
+// TryPop removes the element from the front of the queue, and tells if there
+// was one. It must only be called by the consumer.
+func (q *SPSCQueue) TryPop() (k KType, ok bool) {
+	head := q.head
+	if head == atomic.LoadUintptr(&q.tail) {
+		return k, false
+	}
+	k = q.buf[head&q.mask]
+	// set to the zero value to avoid keeping reference to objects
+	// that would otherwise be garbage collected
+	var zero KType
+	q.buf[head&q.mask] = zero
+	// hands the slot back to the producer
+	atomic.StoreUintptr(&q.head, head+1)
+	return k, true
+}
@@ -0,0 +1,93 @@
+package ringq
+
+import (
+	"math/rand"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+// The benchmarks of this file are generated along with SPSC queues, when
+// asked to. The elements are generated by benchKType.
+
+// benchSPSCQueueSizes are the capacities of the benchmarked queues.
+var benchSPSCQueueSizes = []int{100, 10000, 1000000}
+
+// benchSPSCQueue runs bench for each size, with that many random elements.
+// The elements are the same from one benchmark to the other.
+func benchSPSCQueue(b *testing.B, bench func(b *testing.B, elems []KType)) {
+	for _, n := range benchSPSCQueueSizes {
+		n := n
+		var elems []KType
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			// once for all the runs of the benchmark, and only if it's run
+			if elems == nil {
+				rnd := rand.New(rand.NewSource(int64(n)))
+				elems = make([]KType, n)
+				for i := range elems {
+					elems[i] = benchKType(rnd)
+				}
+			}
+			b.ResetTimer()
+			bench(b, elems)
+		})
+	}
+}
+
+func BenchmarkSPSCQueuePushPop(b *testing.B) {
+	benchSPSCQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := NewSPSCQueue(n)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			q.Push(elems[i%n])
+			q.TryPop()
+		}
+	})
+}
+
+// BenchmarkSPSCQueueProducerConsumer is to be compared with
+// BenchmarkSPSCQueueProducerConsumerChan.
+func BenchmarkSPSCQueueProducerConsumer(b *testing.B) {
+	benchSPSCQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		q := NewSPSCQueue(n)
+		b.ResetTimer()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < b.N; i++ {
+				for _, ok := q.TryPop(); !ok; _, ok = q.TryPop() {
+					runtime.Gosched()
+				}
+			}
+		}()
+		for i := 0; i < b.N; i++ {
+			for !q.Push(elems[i%n]) {
+				runtime.Gosched()
+			}
+		}
+		<-done
+	})
+}
+
+// BenchmarkSPSCQueueProducerConsumerChan sends the elements through a
+// channel of the same capacity.
+func BenchmarkSPSCQueueProducerConsumerChan(b *testing.B) {
+	benchSPSCQueue(b, func(b *testing.B, elems []KType) {
+		n := len(elems)
+		c := make(chan KType, n)
+		b.ResetTimer()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < b.N; i++ {
+				<-c
+			}
+		}()
+		for i := 0; i < b.N; i++ {
+			c <- elems[i%n]
+		}
+		<-done
+	})
+}
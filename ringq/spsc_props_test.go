@@ -0,0 +1,101 @@
+package ringq
+
+import (
+	"math/rand"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// The tests of this file are generated along with SPSC queues, when asked to.
+// The elements are generated by randomKType. Run them with -race.
+
+// checkSPSCQueue verifies that the queue holds at most its capacity, and
+// that the slots out of the queue are cleared. It must not be called while
+// the queue is used.
+func checkSPSCQueue(t *testing.T, q *SPSCQueue) {
+	if n := q.tail - q.head; n > uintptr(len(q.buf)) {
+		t.Fatalf("head %d and tail %d out of a buffer of %d", q.head, q.tail, len(q.buf))
+	}
+	if int(q.mask) != len(q.buf)-1 || len(q.buf)&int(q.mask) != 0 {
+		t.Fatalf("mask %#x of a buffer of %d", q.mask, len(q.buf))
+	}
+	var zero KType
+	for pos := q.tail; pos != q.head+uintptr(len(q.buf)); pos++ {
+		if at := pos & q.mask; !reflect.DeepEqual(q.buf[at], zero) {
+			t.Fatalf("slot %d out of the queue holds %v", at, q.buf[at])
+		}
+	}
+}
+
+func TestSPSCQueueMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	q := NewSPSCQueue(50)
+	var ref []KType
+
+	for i := 0; i < 5000; i++ {
+		// favor pushes, then pops, so the queue fills up and empties
+		push := 6
+		if i%2000 >= 1000 {
+			push = 4
+		}
+		if rnd.Intn(10) < push {
+			k := randomKType(rnd)
+			full := len(ref) == q.Cap()
+			if pushed := q.Push(k); pushed == full {
+				t.Fatalf("push on a queue of %d/%d: want %v, got %v", len(ref), q.Cap(), !full, pushed)
+			}
+			if !full {
+				ref = append(ref, k)
+			}
+		} else if len(ref) != 0 {
+			if got, ok := q.TryPop(); !ok || !reflect.DeepEqual(ref[0], got) {
+				t.Fatalf("try pop: want %v, true, got %v, %v", ref[0], got, ok)
+			}
+			ref = ref[1:]
+		} else if got, ok := q.TryPop(); ok {
+			t.Fatalf("try pop: want nothing, got %v", got)
+		}
+		checkSPSCQueue(t, q)
+		if q.Len() != len(ref) {
+			t.Fatalf("want len %d, got %d", len(ref), q.Len())
+		}
+	}
+}
+
+// TestSPSCQueueProducerConsumer has a producer and a consumer pushing and
+// popping through a small queue, many times around it, and verifies that
+// the elements are popped in the order they're pushed.
+func TestSPSCQueueProducerConsumer(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	elems := make([]KType, 20000)
+	for i := range elems {
+		elems[i] = randomKType(rnd)
+	}
+
+	q := NewSPSCQueue(8)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, k := range elems {
+			for !q.Push(k) {
+				runtime.Gosched()
+			}
+		}
+	}()
+	for i, want := range elems {
+		got, ok := q.TryPop()
+		for !ok {
+			runtime.Gosched()
+			got, ok = q.TryPop()
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Fatalf("pop %d: want %v, got %v", i, want, got)
+		}
+	}
+	<-done
+	checkSPSCQueue(t, q)
+	if q.Len() != 0 {
+		t.Errorf("want len 0, got %d", q.Len())
+	}
+}
@@ -0,0 +1,339 @@
+package redblackbst
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// The benchmarks of this file are generated along with sorted sets, when
+// asked to. The keys are generated by benchKType.
+
+// benchRedBlackSizes are the numbers of keys in the benchmarked sets.
+var benchRedBlackSizes = []int{100, 10000, 1000000}
+
+// benchRedBlack runs bench for each size, with a set holding that many
+// random keys, and the keys put in it. The keys are the same from one
+// benchmark to the other.
+func benchRedBlack(b *testing.B, bench func(b *testing.B, r *RedBlack, keys []KType)) {
+	for _, n := range benchRedBlackSizes {
+		n := n
+		var r *RedBlack
+		var keys []KType
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			// once for all the runs of the benchmark, and only if it's run
+			if r == nil {
+				rnd := rand.New(rand.NewSource(int64(n)))
+				keys = make([]KType, n)
+				for i := range keys {
+					keys[i] = benchKType(rnd)
+				}
+				r = NewRedBlack()
+				fillRedBlack(r, keys)
+			}
+			bench(b, r, keys)
+		})
+	}
+}
+
+func fillRedBlack(r *RedBlack, keys []KType) {
+	for _, k := range keys {
+		r.Put(k)
+	}
+}
+
+// benchRedBlackRefill runs op b.N times, putting the keys back in r every n
+// times. The refill isn't timed.
+func benchRedBlackRefill(b *testing.B, r *RedBlack, keys []KType, op func(i int)) {
+	n := len(keys)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		op(i % n)
+		if i%n == n-1 {
+			b.StopTimer()
+			fillRedBlack(r, keys)
+			b.StartTimer()
+		}
+	}
+	b.StopTimer()
+	fillRedBlack(r, keys)
+}
+
+// BenchmarkRedBlackPut is to be compared with BenchmarkRedBlackPutLLRB.
+func BenchmarkRedBlackPut(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		n := len(keys)
+		r.Clear()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// fill the set up to n keys, over and over
+			if i%n == 0 {
+				r.Clear()
+			}
+			r.Put(keys[i%n])
+		}
+		b.StopTimer()
+		fillRedBlack(r, keys)
+	})
+}
+
+// BenchmarkRedBlackContains is to be compared with
+// BenchmarkRedBlackContainsLLRB.
+func BenchmarkRedBlackContains(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Contains(keys[i%len(keys)])
+		}
+	})
+}
+
+// llrbRedBlackItem is an item of llrbRedBlack, held as an interface and
+// ordered by its Less method, like the items of GoLLRB.
+type llrbRedBlackItem interface {
+	Less(than llrbRedBlackItem) bool
+}
+
+// llrbRedBlackEntry is a key, ordered like RedBlack.
+type llrbRedBlackEntry struct {
+	r *RedBlack
+	k KType
+}
+
+func (e llrbRedBlackEntry) Less(than llrbRedBlackItem) bool {
+	return e.r.compare(e.k, than.(llrbRedBlackEntry).k) < 0
+}
+
+// llrbRedBlack is a left leaning red black tree of interface{} items, as
+// implemented by GoLLRB. It only puts and gets, which is all it's
+// benchmarked for.
+type llrbRedBlack struct {
+	root *llrbRedBlackNode
+}
+
+type llrbRedBlackNode struct {
+	item        llrbRedBlackItem
+	left, right *llrbRedBlackNode
+	black       bool
+}
+
+func (t *llrbRedBlack) put(item llrbRedBlackItem) {
+	t.root = t.root.insert(item)
+	t.root.black = true
+}
+
+func (t *llrbRedBlack) get(item llrbRedBlackItem) llrbRedBlackItem {
+	h := t.root
+	for h != nil {
+		switch {
+		case item.Less(h.item):
+			h = h.left
+		case h.item.Less(item):
+			h = h.right
+		default:
+			return h.item
+		}
+	}
+	return nil
+}
+
+func (h *llrbRedBlackNode) isRed() bool { return h != nil && !h.black }
+
+func (h *llrbRedBlackNode) insert(item llrbRedBlackItem) *llrbRedBlackNode {
+	if h == nil {
+		return &llrbRedBlackNode{item: item}
+	}
+	switch {
+	case item.Less(h.item):
+		h.left = h.left.insert(item)
+	case h.item.Less(item):
+		h.right = h.right.insert(item)
+	default:
+		h.item = item
+	}
+	if h.right.isRed() && !h.left.isRed() {
+		h = h.rotateLeft()
+	}
+	if h.left.isRed() && h.left.left.isRed() {
+		h = h.rotateRight()
+	}
+	if h.left.isRed() && h.right.isRed() {
+		h.black = !h.black
+		h.left.black = true
+		h.right.black = true
+	}
+	return h
+}
+
+func (h *llrbRedBlackNode) rotateLeft() *llrbRedBlackNode {
+	x := h.right
+	h.right = x.left
+	x.left = h
+	x.black = h.black
+	h.black = false
+	return x
+}
+
+func (h *llrbRedBlackNode) rotateRight() *llrbRedBlackNode {
+	x := h.left
+	h.left = x.right
+	x.right = h
+	x.black = h.black
+	h.black = false
+	return x
+}
+
+func BenchmarkRedBlackPutLLRB(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		n := len(keys)
+		t := &llrbRedBlack{}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// fill the tree up to n keys, over and over
+			if i%n == 0 {
+				t.root = nil
+			}
+			t.put(llrbRedBlackEntry{r: r, k: keys[i%n]})
+		}
+	})
+}
+
+func BenchmarkRedBlackContainsLLRB(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		t := &llrbRedBlack{}
+		for _, k := range keys {
+			t.put(llrbRedBlackEntry{r: r, k: k})
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			t.get(llrbRedBlackEntry{r: r, k: keys[i%len(keys)]})
+		}
+	})
+}
+
+func BenchmarkRedBlackDelete(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		benchRedBlackRefill(b, r, keys, func(i int) { r.Delete(keys[i]) })
+	})
+}
+
+func BenchmarkRedBlackDeleteMin(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		benchRedBlackRefill(b, r, keys, func(int) { r.DeleteMin() })
+	})
+}
+
+func BenchmarkRedBlackDeleteMax(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		benchRedBlackRefill(b, r, keys, func(int) { r.DeleteMax() })
+	})
+}
+
+func BenchmarkRedBlackMin(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Min()
+		}
+	})
+}
+
+func BenchmarkRedBlackMax(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Max()
+		}
+	})
+}
+
+func BenchmarkRedBlackFloor(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Floor(keys[i%len(keys)])
+		}
+	})
+}
+
+func BenchmarkRedBlackCeiling(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Ceiling(keys[i%len(keys)])
+		}
+	})
+}
+
+func BenchmarkRedBlackRank(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Rank(keys[i%len(keys)])
+		}
+	})
+}
+
+func BenchmarkRedBlackSelect(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		n := r.Size()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Select(i % n)
+		}
+	})
+}
+
+func BenchmarkRedBlackKeys(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		visit := func(KType) bool { return true }
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Keys(visit)
+		}
+	})
+}
+
+// BenchmarkRedBlackRangedKeys visits a quarter of the keys.
+func BenchmarkRedBlackRangedKeys(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		n := r.Size()
+		lo, _ := r.Select(n / 4)
+		hi, _ := r.Select(n / 2)
+		visit := func(KType) bool { return true }
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.RangedKeys(lo, hi, visit)
+		}
+	})
+}
+
+// BenchmarkRedBlackFromSorted builds the whole set at each iteration.
+func BenchmarkRedBlackFromSorted(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		sorted := r.ToSlice()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			NewRedBlackFromSorted(sorted)
+		}
+	})
+}
+
+// BenchmarkRedBlackFromUnsorted builds the whole set at each iteration.
+func BenchmarkRedBlackFromUnsorted(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			NewRedBlackFromUnsorted(keys)
+		}
+	})
+}
+
+func BenchmarkRedBlackToSlice(b *testing.B) {
+	benchRedBlack(b, func(b *testing.B, r *RedBlack, keys []KType) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.ToSlice()
+		}
+	})
+}
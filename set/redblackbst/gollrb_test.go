@@ -17,6 +17,10 @@ func (i Int) Compare(other KType) int {
 	return int(i - other.(Int))
 }
 
+func randomKType(r *rand.Rand) KType { return Int(r.Intn(1000)) }
+
+func benchKType(r *rand.Rand) KType { return Int(r.Int31()) }
+
 func TestCases(t *testing.T) {
 	tree := NewRedBlack()
 	tree.Put(Int(1))
@@ -0,0 +1,369 @@
+package redblackbst
+
+// The implementation was forked from the one of the sorted sets
+// (set/redblackbst/rbbst.go in datagen), its nodes counting the occurrences
+// of their key: a fix to the balancing of either tree is to be made to the
+// other. Put and Delete became Add and Remove, and the other methods the
+// sorted sets gained since are missing here: Floor, Ceiling, Lower, Higher,
+// ReverseKeys, RangedKeysDesc, RangeCount, BoundedKeys, Cursor, DeleteMin,
+// DeleteMax, DeleteRange, ToSlice, the set operations and the constructors
+// from sorted and unsorted slices.
+
+func (r MultiRedBlack) compare(a, b KType) int { return a.Compare(b) }
+
+// MultiRedBlack is a sorted multiset built on a left leaning red black
+// balanced search tree. It stores KType keys, each with the count of its
+// occurrences. Sizes, ranks and selections count each occurrence, so that
+// percentiles are found in O(log n).
+type MultiRedBlack struct {
+	root *multinode
+	// distinct is the number of keys, counting each once
+	distinct int
+}
+
+// NewMultiRedBlack creates a sorted multiset.
+func NewMultiRedBlack() *MultiRedBlack { return &MultiRedBlack{} }
+
+// IsEmpty tells if the sorted multiset contains no key.
+func (r MultiRedBlack) IsEmpty() bool {
+	return r.root == nil
+}
+
+// Size of the sorted multiset, counting each occurrence of each key.
+func (r MultiRedBlack) Size() int { return r.root.size() }
+
+// Distinct is the number of keys of the sorted multiset, counting each once.
+func (r MultiRedBlack) Distinct() int { return r.distinct }
+
+// Clear all the keys in the sorted multiset.
+func (r *MultiRedBlack) Clear() { r.root, r.distinct = nil, 0 }
+
+// Add `n` occurrences of the key `k` to the sorted multiset, and return how
+// many there are now. It panics if `n` is negative.
+func (r *MultiRedBlack) Add(k KType, n int) (count int) {
+	if n < 0 {
+		panic("redblackbst: can't add a negative number of occurrences")
+	}
+	if n == 0 {
+		return r.Count(k)
+	}
+	r.root, count = r.add(r.root, k, n)
+	r.root.colorRed = false
+	return count
+}
+
+func (r *MultiRedBlack) add(h *multinode, k KType, n int) (_ *multinode, count int) {
+	if h == nil {
+		r.distinct++
+		return &multinode{key: k, count: n, n: n, colorRed: true}, n
+	}
+
+	cmp := r.compare(k, h.key)
+	if cmp < 0 {
+		h.left, count = r.add(h.left, k, n)
+	} else if cmp > 0 {
+		h.right, count = r.add(h.right, k, n)
+	} else {
+		h.count += n
+		count = h.count
+	}
+
+	if h.right.isRed() && !h.left.isRed() {
+		h = r.rotateLeft(h)
+	}
+	if h.left.isRed() && h.left.left.isRed() {
+		h = r.rotateRight(h)
+	}
+	if h.left.isRed() && h.right.isRed() {
+		r.flipColors(h)
+	}
+	h.n = h.left.size() + h.right.size() + h.count
+	return h, count
+}
+
+// Count is the number of occurrences of the key `k`.
+func (r MultiRedBlack) Count(k KType) int {
+	h := r.find(k)
+	if h == nil {
+		return 0
+	}
+	return h.count
+}
+
+// Contains tells if `k` occurs in the sorted multiset.
+func (r MultiRedBlack) Contains(k KType) bool { return r.find(k) != nil }
+
+func (r MultiRedBlack) find(k KType) *multinode {
+	for h := r.root; h != nil; {
+		cmp := r.compare(k, h.key)
+		if cmp == 0 {
+			return h
+		} else if cmp < 0 {
+			h = h.left
+		} else {
+			h = h.right
+		}
+	}
+	return nil
+}
+
+// Min returns the smallest key in the sorted multiset, if it exists.
+func (r MultiRedBlack) Min() (k KType, ok bool) {
+	if r.root == nil {
+		return
+	}
+	h := r.root
+	for h.left != nil {
+		h = h.left
+	}
+	return h.key, true
+}
+
+// Max returns the largest key in the sorted multiset, if it exists.
+func (r MultiRedBlack) Max() (k KType, ok bool) {
+	if r.root == nil {
+		return
+	}
+	h := r.root
+	for h.right != nil {
+		h = h.right
+	}
+	return h.key, true
+}
+
+// Select the key of rank `i`, meaning the key of the i-th smallest
+// occurrence in the sorted multiset.
+func (r MultiRedBlack) Select(i int) (k KType, ok bool) {
+	for h := r.root; h != nil; {
+		t := h.left.size()
+		if i < t {
+			h = h.left
+		} else if i < t+h.count {
+			return h.key, true
+		} else {
+			h, i = h.right, i-t-h.count
+		}
+	}
+	return
+}
+
+// Rank is the number of occurrences of keys less than `k`.
+func (r MultiRedBlack) Rank(k KType) int {
+	rank := 0
+	for h := r.root; h != nil; {
+		cmp := r.compare(k, h.key)
+		if cmp < 0 {
+			h = h.left
+		} else if cmp > 0 {
+			rank += h.left.size() + h.count
+			h = h.right
+		} else {
+			return rank + h.left.size()
+		}
+	}
+	return rank
+}
+
+// Keys visit each key in the sorted multiset, in order, with its number of
+// occurrences. It stops when visit returns false.
+func (r MultiRedBlack) Keys(visit func(k KType, count int) bool) {
+	min, ok := r.Min()
+	if !ok {
+		return
+	}
+	// if the min exists, then the max must exist
+	max, _ := r.Max()
+	r.RangedKeys(min, max, visit)
+}
+
+// RangedKeys visit each key between lo and hi in the sorted multiset, in
+// order, with its number of occurrences. It stops when visit returns false.
+func (r MultiRedBlack) RangedKeys(lo, hi KType, visit func(k KType, count int) bool) {
+	r.keys(r.root, visit, lo, hi)
+}
+
+func (r MultiRedBlack) keys(h *multinode, visit func(k KType, count int) bool, lo, hi KType) bool {
+	if h == nil {
+		return true
+	}
+	cmplo := r.compare(lo, h.key)
+	cmphi := r.compare(hi, h.key)
+	if cmplo < 0 {
+		if !r.keys(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	if cmplo <= 0 && cmphi >= 0 {
+		if !visit(h.key, h.count) {
+			return false
+		}
+	}
+	if cmphi > 0 {
+		if !r.keys(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
+// deletions
+
+// Remove `n` occurrences of the key `k` from the sorted multiset, or all of
+// them if there are fewer, and return how many are left. The key is removed
+// along with its last occurrence. It panics if `n` is negative.
+func (r *MultiRedBlack) Remove(k KType, n int) (count int) {
+	if n < 0 {
+		panic("redblackbst: can't remove a negative number of occurrences")
+	}
+	h := r.find(k)
+	if h == nil || n == 0 {
+		return r.Count(k)
+	}
+	if n >= h.count {
+		r.root = r.delete(r.root, k)
+		if !r.IsEmpty() {
+			r.root.colorRed = false
+		}
+		r.distinct--
+		return 0
+	}
+	h.count -= n
+	// n less occurrences under the nodes on the path to h
+	for x := r.root; x != h; {
+		x.n -= n
+		if r.compare(k, x.key) < 0 {
+			x = x.left
+		} else {
+			x = x.right
+		}
+	}
+	h.n -= n
+	return h.count
+}
+
+// delete removes `k`, which is in the tree of h.
+func (r *MultiRedBlack) delete(h *multinode, k KType) *multinode {
+	if r.compare(k, h.key) < 0 {
+		if !h.left.isRed() && !h.left.left.isRed() {
+			h = r.moveRedLeft(h)
+		}
+		h.left = r.delete(h.left, k)
+		return r.balance(h)
+	}
+
+	if h.left.isRed() {
+		h = r.rotateRight(h)
+	}
+
+	if r.compare(k, h.key) == 0 && h.right == nil {
+		return nil
+	}
+
+	if !h.right.isRed() && !h.right.left.isRed() {
+		h = r.moveRedRight(h)
+	}
+
+	if r.compare(k, h.key) == 0 {
+		var min *multinode
+		h.right, min = r.deleteMin(h.right)
+		h.key, h.count = min.key, min.count
+	} else {
+		h.right = r.delete(h.right, k)
+	}
+	return r.balance(h)
+}
+
+// deleteMin removes the smallest key of the tree of h, which isn't empty,
+// and returns its node.
+func (r *MultiRedBlack) deleteMin(h *multinode) (_, min *multinode) {
+	if h.left == nil {
+		return nil, h
+	}
+	if !h.left.isRed() && !h.left.left.isRed() {
+		h = r.moveRedLeft(h)
+	}
+	h.left, min = r.deleteMin(h.left)
+	return r.balance(h), min
+}
+
+func (r *MultiRedBlack) moveRedLeft(h *multinode) *multinode {
+	r.flipColors(h)
+	if h.right.left.isRed() {
+		h.right = r.rotateRight(h.right)
+		h = r.rotateLeft(h)
+		r.flipColors(h)
+	}
+	return h
+}
+
+func (r *MultiRedBlack) moveRedRight(h *multinode) *multinode {
+	r.flipColors(h)
+	if h.left.left.isRed() {
+		h = r.rotateRight(h)
+		r.flipColors(h)
+	}
+	return h
+}
+
+func (r *MultiRedBlack) balance(h *multinode) *multinode {
+	if h.right.isRed() {
+		h = r.rotateLeft(h)
+	}
+	if h.left.isRed() && h.left.left.isRed() {
+		h = r.rotateRight(h)
+	}
+	if h.left.isRed() && h.right.isRed() {
+		r.flipColors(h)
+	}
+	h.n = h.left.size() + h.right.size() + h.count
+	return h
+}
+
+func (r *MultiRedBlack) rotateLeft(h *multinode) *multinode {
+	x := h.right
+	h.right = x.left
+	x.left = h
+	x.colorRed = h.colorRed
+	h.colorRed = true
+	x.n = h.n
+	h.n = h.count + h.left.size() + h.right.size()
+	return x
+}
+
+func (r *MultiRedBlack) rotateRight(h *multinode) *multinode {
+	x := h.left
+	h.left = x.right
+	x.right = h
+	x.colorRed = h.colorRed
+	h.colorRed = true
+	x.n = h.n
+	h.n = h.count + h.left.size() + h.right.size()
+	return x
+}
+
+func (r *MultiRedBlack) flipColors(h *multinode) {
+	h.colorRed = !h.colorRed
+	h.left.colorRed = !h.left.colorRed
+	h.right.colorRed = !h.right.colorRed
+}
+
+// nodes
+
+type multinode struct {
+	key KType
+	// count is the number of occurrences of the key, one at least
+	count       int
+	left, right *multinode
+	// n is the number of occurrences of the keys of the tree of the node
+	n        int
+	colorRed bool
+}
+
+func (x *multinode) isRed() bool { return (x != nil) && (x.colorRed == true) }
+
+func (x *multinode) size() int {
+	if x == nil {
+		return 0
+	}
+	return x.n
+}
@@ -0,0 +1,73 @@
+package redblackbst
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// The benchmarks of this file are generated along with sorted multisets,
+// when asked to. The keys are generated by benchKType.
+
+// benchMultiRedBlackSizes are the numbers of occurrences in the benchmarked
+// multisets.
+var benchMultiRedBlackSizes = []int{100, 10000, 1000000}
+
+// benchMultiRedBlack runs bench for each size, with a multiset holding that
+// many random occurrences, of half as many keys, and the keys added to it.
+// The keys are the same from one benchmark to the other.
+func benchMultiRedBlack(b *testing.B, bench func(b *testing.B, r *MultiRedBlack, keys []KType)) {
+	for _, n := range benchMultiRedBlackSizes {
+		n := n
+		var r *MultiRedBlack
+		var keys []KType
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			// once for all the runs of the benchmark, and only if it's run
+			if r == nil {
+				rnd := rand.New(rand.NewSource(int64(n)))
+				keys = make([]KType, n/2)
+				r = NewMultiRedBlack()
+				for i := range keys {
+					keys[i] = benchKType(rnd)
+					r.Add(keys[i], 2)
+				}
+			}
+			bench(b, r, keys)
+		})
+	}
+}
+
+// BenchmarkMultiRedBlackRemoveAdd removes an occurrence of a key and adds it
+// back, leaving as many occurrences in the multiset.
+func BenchmarkMultiRedBlackRemoveAdd(b *testing.B) {
+	benchMultiRedBlack(b, func(b *testing.B, r *MultiRedBlack, keys []KType) {
+		n := len(keys)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Remove(keys[i%n], 1)
+			r.Add(keys[i%n], 1)
+		}
+	})
+}
+
+func BenchmarkMultiRedBlackCount(b *testing.B) {
+	benchMultiRedBlack(b, func(b *testing.B, r *MultiRedBlack, keys []KType) {
+		n := len(keys)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Count(keys[i%n])
+		}
+	})
+}
+
+// BenchmarkMultiRedBlackSelect selects the occurrences of each rank, such as
+// for percentiles.
+func BenchmarkMultiRedBlackSelect(b *testing.B) {
+	benchMultiRedBlack(b, func(b *testing.B, r *MultiRedBlack, keys []KType) {
+		n := r.Size()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r.Select(i % n)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package redblackbst
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// The tests of this file are generated along with sorted multisets, when
+// asked to. The keys are generated by randomKType.
+
+// checkMultiRedBlack verifies the invariants of the tree: the keys are in
+// order, each occurs once at least, the sizes of the subtrees are right, red
+// links lean left, no node has two red links and every path from the root
+// to a leaf has as many black links.
+func checkMultiRedBlack(t *testing.T, r *MultiRedBlack) {
+	if r.root.isRed() {
+		t.Fatal("root is red")
+	}
+	checkMultiRedBlackNode(t, r, r.root)
+
+	var prev *KType
+	distinct := 0
+	r.Keys(func(k KType, _ int) bool {
+		if prev != nil && r.compare(*prev, k) >= 0 {
+			t.Fatalf("keys out of order: %v before %v", *prev, k)
+		}
+		prev = &k
+		distinct++
+		return true
+	})
+	if distinct != r.Distinct() {
+		t.Fatalf("want %d distinct keys, got %d", distinct, r.Distinct())
+	}
+}
+
+// checkMultiRedBlackNode returns the number of black links from x to the
+// leaves.
+func checkMultiRedBlackNode(t *testing.T, r *MultiRedBlack, x *multinode) int {
+	if x == nil {
+		return 0
+	}
+	if x.count < 1 {
+		t.Fatalf("%v occurs %d times", x.key, x.count)
+	}
+	if x.right.isRed() {
+		t.Fatalf("red link leans right under %v", x.key)
+	}
+	if x.isRed() && x.left.isRed() {
+		t.Fatalf("two red links in a row under %v", x.key)
+	}
+	if want := x.count + x.left.size() + x.right.size(); x.n != want {
+		t.Fatalf("size of %v: want %d, got %d", x.key, want, x.n)
+	}
+	black := checkMultiRedBlackNode(t, r, x.left)
+	if right := checkMultiRedBlackNode(t, r, x.right); black != right {
+		t.Fatalf("black links under %v: %d on the left, %d on the right", x.key, black, right)
+	}
+	if !x.isRed() {
+		black++
+	}
+	return black
+}
+
+// refMultiRedBlack is a naive sorted multiset keeping its keys in a sorted
+// slice, each with its count, ordered like r.
+type refMultiRedBlack struct {
+	r      *MultiRedBlack
+	keys   []KType
+	counts []int
+}
+
+// search returns the index of the first key larger or equal to k.
+func (ref *refMultiRedBlack) search(k KType) int {
+	return sort.Search(len(ref.keys), func(i int) bool { return ref.r.compare(ref.keys[i], k) >= 0 })
+}
+
+func (ref *refMultiRedBlack) has(k KType) (int, bool) {
+	i := ref.search(k)
+	return i, i < len(ref.keys) && ref.r.compare(ref.keys[i], k) == 0
+}
+
+func (ref *refMultiRedBlack) add(k KType, n int) int {
+	i, ok := ref.has(k)
+	if ok {
+		ref.counts[i] += n
+		return ref.counts[i]
+	}
+	if n == 0 {
+		return 0
+	}
+	ref.keys = append(ref.keys[:i], append([]KType{k}, ref.keys[i:]...)...)
+	ref.counts = append(ref.counts[:i], append([]int{n}, ref.counts[i:]...)...)
+	return n
+}
+
+func (ref *refMultiRedBlack) remove(k KType, n int) int {
+	i, ok := ref.has(k)
+	if !ok {
+		return 0
+	}
+	if ref.counts[i] > n {
+		ref.counts[i] -= n
+		return ref.counts[i]
+	}
+	ref.keys = append(ref.keys[:i], ref.keys[i+1:]...)
+	ref.counts = append(ref.counts[:i], ref.counts[i+1:]...)
+	return 0
+}
+
+// occurrences returns the keys from index i, each as many times as it
+// occurs.
+func (ref *refMultiRedBlack) occurrences(i int) []KType {
+	var keys []KType
+	for ; i < len(ref.keys); i++ {
+		for j := 0; j < ref.counts[i]; j++ {
+			keys = append(keys, ref.keys[i])
+		}
+	}
+	return keys
+}
+
+func TestMultiRedBlackMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	r := NewMultiRedBlack()
+	ref := &refMultiRedBlack{r: r}
+
+	for n := 0; n < 5000; n++ {
+		k := randomKType(rnd)
+		if len(ref.keys) != 0 && rnd.Intn(2) == 0 {
+			k = ref.keys[rnd.Intn(len(ref.keys))]
+		}
+		switch op := rnd.Intn(8); op {
+		case 0, 1, 2:
+			times := rnd.Intn(4)
+			if want, got := ref.add(k, times), r.Add(k, times); want != got {
+				t.Fatalf("add %d of %v: want %d, got %d", times, k, want, got)
+			}
+		case 3, 4:
+			times := rnd.Intn(4)
+			if want, got := ref.remove(k, times), r.Remove(k, times); want != got {
+				t.Fatalf("remove %d of %v: want %d left, got %d", times, k, want, got)
+			}
+		case 5:
+			i, ok := ref.has(k)
+			want := 0
+			if ok {
+				want = ref.counts[i]
+			}
+			if got := r.Count(k); got != want || r.Contains(k) != ok {
+				t.Fatalf("count of %v: want %d, %v, got %d, %v", k, want, ok, got, r.Contains(k))
+			}
+			mk, minOK := r.Min()
+			xk, maxOK := r.Max()
+			if minOK != (len(ref.keys) != 0) || maxOK != minOK {
+				t.Fatalf("min and max: want ok=%v, got %v and %v", len(ref.keys) != 0, minOK, maxOK)
+			}
+			if minOK && (r.compare(mk, ref.keys[0]) != 0 || r.compare(xk, ref.keys[len(ref.keys)-1]) != 0) {
+				t.Fatalf("min and max: want %v and %v, got %v and %v", ref.keys[0], ref.keys[len(ref.keys)-1], mk, xk)
+			}
+		case 6:
+			keys := ref.occurrences(0)
+			i := rnd.Intn(len(keys) + 2)
+			sk, ok := r.Select(i)
+			if ok != (i < len(keys)) || ok && r.compare(sk, keys[i]) != 0 {
+				t.Fatalf("select %d of %d: want %v, got %v, %v", i, len(keys), i < len(keys), sk, ok)
+			}
+			if want, got := len(keys)-len(ref.occurrences(ref.search(k))), r.Rank(k); want != got {
+				t.Fatalf("rank %v: want %d, got %d", k, want, got)
+			}
+		case 7:
+			lo, hi := k, randomKType(rnd)
+			i := ref.search(lo)
+			r.RangedKeys(lo, hi, func(k KType, count int) bool {
+				if i == len(ref.keys) || r.compare(ref.keys[i], k) != 0 || ref.counts[i] != count {
+					t.Fatalf("ranged keys [%v, %v]: got %v occurring %d times out of order", lo, hi, k, count)
+				}
+				i++
+				return true
+			})
+			if i < len(ref.keys) && r.compare(ref.keys[i], hi) <= 0 {
+				t.Fatalf("ranged keys [%v, %v]: missed %v", lo, hi, ref.keys[i])
+			}
+		}
+		if want, got := len(ref.occurrences(0)), r.Size(); want != got {
+			t.Fatalf("want size %d, got %d", want, got)
+		}
+		checkMultiRedBlack(t, r)
+	}
+}
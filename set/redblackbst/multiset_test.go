@@ -0,0 +1,63 @@
+package redblackbst
+
+import "testing"
+
+func TestCanCountOccurrences(t *testing.T) {
+	scores := NewMultiRedBlack()
+	scores.Add(Int(10), 2)
+	scores.Add(Int(30), 1)
+	if got := scores.Add(Int(10), 1); got != 3 {
+		t.Errorf("want 3 occurrences of 10, got %d", got)
+	}
+	scores.Add(Int(20), 0)
+
+	if scores.Size() != 4 || scores.Distinct() != 2 {
+		t.Errorf("want size 4 and 2 distinct keys, got %d and %d", scores.Size(), scores.Distinct())
+	}
+	if scores.Contains(Int(20)) || scores.Count(Int(20)) != 0 {
+		t.Errorf("adding nothing shouldn't add the key")
+	}
+
+	// the median and percentiles count the occurrences
+	for i, want := range []Int{10, 10, 10, 30} {
+		if k, ok := scores.Select(i); !ok || k != want {
+			t.Errorf("select %d: want %v, got %v, %v", i, want, k, ok)
+		}
+	}
+	if scores.Rank(Int(30)) != 3 || scores.Rank(Int(20)) != 3 || scores.Rank(Int(10)) != 0 {
+		t.Errorf("want ranks 3, 3 and 0, got %d, %d and %d", scores.Rank(Int(30)), scores.Rank(Int(20)), scores.Rank(Int(10)))
+	}
+
+	if got := scores.Remove(Int(10), 2); got != 1 {
+		t.Errorf("want 1 occurrence of 10 left, got %d", got)
+	}
+	if got := scores.Remove(Int(10), 5); got != 0 || scores.Contains(Int(10)) {
+		t.Errorf("want 10 removed, got %d left", got)
+	}
+	if got := scores.Remove(Int(10), 1); got != 0 {
+		t.Errorf("want nothing to remove, got %d left", got)
+	}
+	if scores.Size() != 1 || scores.Distinct() != 1 {
+		t.Errorf("want size 1 and 1 distinct key, got %d and %d", scores.Size(), scores.Distinct())
+	}
+	scores.Clear()
+	if !scores.IsEmpty() || scores.Distinct() != 0 {
+		t.Errorf("want cleared multiset empty")
+	}
+}
+
+func TestAddingNegativeOccurrencesPanics(t *testing.T) {
+	for name, op := range map[string]func(*MultiRedBlack){
+		"add":    func(r *MultiRedBlack) { r.Add(Int(1), -1) },
+		"remove": func(r *MultiRedBlack) { r.Remove(Int(1), -1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: want a panic", name)
+				}
+			}()
+			op(NewMultiRedBlack())
+		}()
+	}
+}
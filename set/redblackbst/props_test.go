@@ -0,0 +1,501 @@
+package redblackbst
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// The tests of this file are generated along with sorted sets, when asked
+// to. The keys are generated by randomKType.
+
+// checkRedBlack verifies the invariants of the tree: the keys are in order,
+// the sizes of the subtrees are right, red links lean left, no node has two
+// red links and every path from the root to a leaf has as many black links.
+func checkRedBlack(t *testing.T, r *RedBlack) {
+	if r.root.isRed() {
+		t.Fatal("root is red")
+	}
+	checkRedBlackNode(t, r, r.root)
+
+	var prev *KType
+	r.Keys(func(k KType) bool {
+		if prev != nil && r.compare(*prev, k) >= 0 {
+			t.Fatalf("keys out of order: %v before %v", *prev, k)
+		}
+		prev = &k
+		return true
+	})
+}
+
+// checkRedBlackNode returns the number of black links from x to the leaves.
+func checkRedBlackNode(t *testing.T, r *RedBlack, x *treenode) int {
+	if x == nil {
+		return 0
+	}
+	if x.right.isRed() {
+		t.Fatalf("red link leans right under %v", x.key)
+	}
+	if x.isRed() && x.left.isRed() {
+		t.Fatalf("two red links in a row under %v", x.key)
+	}
+	if want := 1 + x.left.size() + x.right.size(); x.n != want {
+		t.Fatalf("size of %v: want %d, got %d", x.key, want, x.n)
+	}
+	black := checkRedBlackNode(t, r, x.left)
+	if right := checkRedBlackNode(t, r, x.right); black != right {
+		t.Fatalf("black links under %v: %d on the left, %d on the right", x.key, black, right)
+	}
+	if !x.isRed() {
+		black++
+	}
+	return black
+}
+
+// refRedBlack is a naive sorted set keeping its keys in a sorted slice,
+// ordered like r.
+type refRedBlack struct {
+	r    *RedBlack
+	keys []KType
+}
+
+// search returns the index of the first key larger or equal to k.
+func (ref *refRedBlack) search(k KType) int {
+	return sort.Search(len(ref.keys), func(i int) bool { return ref.r.compare(ref.keys[i], k) >= 0 })
+}
+
+func (ref *refRedBlack) has(k KType) (int, bool) {
+	i := ref.search(k)
+	return i, i < len(ref.keys) && ref.r.compare(ref.keys[i], k) == 0
+}
+
+func (ref *refRedBlack) put(k KType) {
+	if i, ok := ref.has(k); !ok {
+		ref.keys = append(ref.keys[:i], append([]KType{k}, ref.keys[i:]...)...)
+	}
+}
+
+func (ref *refRedBlack) delete(i int) {
+	ref.keys = append(ref.keys[:i], ref.keys[i+1:]...)
+}
+
+// bounds returns the indexes of the first key between lo and hi, and of the
+// first key past them.
+func (ref *refRedBlack) bounds(lo, hi Bound) (from, to int) {
+	to = len(ref.keys)
+	if !lo.Unbounded {
+		var has bool
+		if from, has = ref.has(lo.Key); has && lo.Exclusive {
+			from++
+		}
+	}
+	if !hi.Unbounded {
+		var has bool
+		if to, has = ref.has(hi.Key); has && !hi.Exclusive {
+			to++
+		}
+	}
+	if to < from {
+		to = from
+	}
+	return from, to
+}
+
+// randomRedBlackBound returns an end of a range of keys, of any kind.
+func randomRedBlackBound(rnd *rand.Rand) Bound {
+	switch rnd.Intn(5) {
+	case 0:
+		return Bound{Unbounded: true}
+	case 1:
+		return Bound{Key: randomKType(rnd), Exclusive: true}
+	default:
+		return Bound{Key: randomKType(rnd)}
+	}
+}
+
+func TestRedBlackMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	r := NewRedBlack()
+	ref := &refRedBlack{r: r}
+
+	checkKey := func(op string, i int, k KType, ok bool) {
+		t.Helper()
+		wantOK := i >= 0 && i < len(ref.keys)
+		if ok != wantOK {
+			t.Fatalf("%s: want ok=%v, got %v", op, wantOK, ok)
+		}
+		if ok && r.compare(ref.keys[i], k) != 0 {
+			t.Fatalf("%s: want %v, got %v", op, ref.keys[i], k)
+		}
+	}
+
+	for n := 0; n < 5000; n++ {
+		k := randomKType(rnd)
+		switch op := rnd.Intn(14); op {
+		case 0, 1, 2, 3:
+			_, had := ref.has(k)
+			if already := r.Put(k); already != had {
+				t.Fatalf("put %v: want %v, got %v", k, had, already)
+			}
+			ref.put(k)
+		case 4:
+			if _, ok := ref.has(k); r.Contains(k) != ok {
+				t.Fatalf("contains %v: want %v", k, ok)
+			}
+		case 5:
+			i, ok := ref.has(k)
+			if got := r.Delete(k); got != ok {
+				t.Fatalf("delete %v: want %v, got %v", k, ok, got)
+			}
+			if ok {
+				ref.delete(i)
+			}
+		case 6:
+			dk, ok := r.DeleteMin()
+			checkKey("delete min", 0, dk, ok)
+			if ok {
+				ref.delete(0)
+			}
+		case 7:
+			dk, ok := r.DeleteMax()
+			checkKey("delete max", len(ref.keys)-1, dk, ok)
+			if ok {
+				ref.delete(len(ref.keys) - 1)
+			}
+		case 8:
+			mk, ok := r.Min()
+			checkKey("min", 0, mk, ok)
+			mk, ok = r.Max()
+			checkKey("max", len(ref.keys)-1, mk, ok)
+		case 9:
+			i, ok := ref.has(k)
+			if !ok {
+				i--
+			}
+			fk, ok := r.Floor(k)
+			checkKey("floor", i, fk, ok)
+			ck, ok := r.Ceiling(k)
+			checkKey("ceiling", ref.search(k), ck, ok)
+			lk, ok := r.Lower(k)
+			checkKey("lower", ref.search(k)-1, lk, ok)
+			i, ok = ref.has(k)
+			if ok {
+				i++
+			}
+			hk, ok := r.Higher(k)
+			checkKey("higher", i, hk, ok)
+		case 10:
+			if want, got := ref.search(k), r.Rank(k); want != got {
+				t.Fatalf("rank %v: want %d, got %d", k, want, got)
+			}
+			i := rnd.Intn(len(ref.keys) + 2)
+			sk, ok := r.Select(i)
+			checkKey("select", i, sk, ok)
+		case 11:
+			lo, hi := k, randomKType(rnd)
+			i := ref.search(lo)
+			r.RangedKeys(lo, hi, func(k KType) bool {
+				checkKey("ranged keys", i, k, true)
+				i++
+				return true
+			})
+			if i < len(ref.keys) && r.compare(ref.keys[i], hi) <= 0 {
+				t.Fatalf("ranged keys [%v, %v]: missed %v", lo, hi, ref.keys[i])
+			}
+		case 12:
+			lo, hi := randomRedBlackBound(rnd), randomRedBlackBound(rnd)
+			from, to := ref.bounds(lo, hi)
+			if got := r.RangeCount(lo, hi); got != to-from {
+				t.Fatalf("range count %v, %v: want %d, got %d", lo, hi, to-from, got)
+			}
+			i := from
+			r.BoundedKeys(lo, hi, func(k KType) bool {
+				if i == to {
+					t.Fatalf("bounded keys %v, %v: want no more keys, got %v", lo, hi, k)
+				}
+				checkKey("bounded keys", i, k, true)
+				i++
+				return true
+			})
+			if i != to {
+				t.Fatalf("bounded keys %v, %v: missed %v", lo, hi, ref.keys[i])
+			}
+		case 13:
+			if len(ref.keys) == 0 {
+				break
+			}
+			// a few neighbouring keys, so that the tree keeps growing
+			i := rnd.Intn(len(ref.keys))
+			j := i + rnd.Intn(2)
+			if j >= len(ref.keys) {
+				j = len(ref.keys) - 1
+			}
+			lo := Bound{Key: ref.keys[i], Exclusive: rnd.Intn(2) == 0}
+			hi := Bound{Key: ref.keys[j], Exclusive: rnd.Intn(2) == 0}
+			if rnd.Intn(100) == 0 {
+				lo, hi = randomRedBlackBound(rnd), randomRedBlackBound(rnd)
+			}
+			from, to := ref.bounds(lo, hi)
+			if got := r.DeleteRange(lo, hi); got != to-from {
+				t.Fatalf("delete range %v, %v: want %d deleted, got %d", lo, hi, to-from, got)
+			}
+			ref.keys = append(ref.keys[:from], ref.keys[to:]...)
+		}
+		if want, got := len(ref.keys), r.Size(); want != got {
+			t.Fatalf("want size %d, got %d", want, got)
+		}
+		checkRedBlack(t, r)
+	}
+
+	i := 0
+	r.Keys(func(k KType) bool {
+		checkKey("keys", i, k, true)
+		i++
+		return true
+	})
+	if i != len(ref.keys) {
+		t.Fatalf("keys: want %d keys, got %d", len(ref.keys), i)
+	}
+}
+
+// TestRedBlackCursorMatchesKeys verifies that cursors, and the visits in
+// reverse order, go over the keys in the order of Keys, or in reverse.
+func TestRedBlackCursorMatchesKeys(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	r := NewRedBlack()
+	for n := 0; n < 1000; n++ {
+		r.Put(randomKType(rnd))
+	}
+	var keys []KType
+	r.Keys(func(k KType) bool {
+		keys = append(keys, k)
+		return true
+	})
+
+	checkEntry := func(op string, i int, k KType) {
+		t.Helper()
+		if i < 0 || i >= len(keys) {
+			t.Fatalf("%s: want no more keys, got %v", op, k)
+		}
+		if r.compare(keys[i], k) != 0 {
+			t.Fatalf("%s: want %v, got %v", op, keys[i], k)
+		}
+	}
+	// checkCursor verifies that c is on the i-th key, or on none if there's
+	// no such key
+	checkCursor := func(op string, c *Cursor, i int) {
+		t.Helper()
+		if want := i >= 0 && i < len(keys); c.Valid() != want {
+			t.Fatalf("%s: want the cursor on a key: %v, got %v", op, want, c.Valid())
+		}
+		if c.Valid() && r.compare(keys[i], c.Key()) != 0 {
+			t.Fatalf("%s: want %v, got %v", op, keys[i], c.Key())
+		}
+	}
+
+	c := r.NewCursor()
+	checkCursor("new cursor", c, -1)
+	i := 0
+	for ok := c.First(); ok; ok = c.Next() {
+		checkCursor("next", c, i)
+		i++
+	}
+	if i != len(keys) {
+		t.Fatalf("next: want %d keys, got %d", len(keys), i)
+	}
+	checkCursor("past the last key", c, i)
+	i = len(keys) - 1
+	for ok := c.Last(); ok; ok = c.Prev() {
+		checkCursor("prev", c, i)
+		i--
+	}
+	if i != -1 {
+		t.Fatalf("prev: want %d keys, got %d", len(keys), len(keys)-1-i)
+	}
+
+	i = len(keys) - 1
+	r.ReverseKeys(func(k KType) bool {
+		checkEntry("reverse keys", i, k)
+		i--
+		return true
+	})
+	if i != -1 {
+		t.Fatalf("reverse keys: want %d keys, got %d", len(keys), len(keys)-1-i)
+	}
+
+	for n := 0; n < 1000; n++ {
+		lo, hi := randomKType(rnd), randomKType(rnd)
+		// the rank of a key is the index of its ceiling
+		i := r.Rank(lo)
+		if ok := c.Seek(lo); ok != (i < len(keys)) {
+			t.Fatalf("seek %v: want %v, got %v", lo, !ok, ok)
+		}
+		checkCursor("seek", c, i)
+		for step := 0; step < 10 && c.Valid(); step++ {
+			if rnd.Intn(2) == 0 {
+				c.Next()
+				i++
+			} else {
+				c.Prev()
+				i--
+			}
+			checkCursor("step", c, i)
+		}
+
+		first, last := r.Rank(lo), r.Rank(hi)-1
+		if r.Contains(hi) {
+			last++
+		}
+		r.RangedKeysDesc(lo, hi, func(k KType) bool {
+			checkEntry("ranged keys desc", last, k)
+			last--
+			return true
+		})
+		if last >= first {
+			t.Fatalf("ranged keys desc [%v, %v]: missed %v", lo, hi, keys[last])
+		}
+	}
+}
+
+// TestRedBlackSetAlgebraMatchesReference verifies the set operations, and
+// the trees they build, against the keys of both sets.
+func TestRedBlackSetAlgebraMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	for n := 0; n < 200; n++ {
+		a, b := NewRedBlack(), NewRedBlack()
+		refA, refB := &refRedBlack{r: a}, &refRedBlack{r: b}
+		for i := rnd.Intn(300); i > 0; i-- {
+			k := randomKType(rnd)
+			a.Put(k)
+			refA.put(k)
+		}
+		// b is often a subset or a superset of a
+		for _, k := range refA.keys {
+			if n%4 == 0 || (n%4 == 1 && rnd.Intn(2) == 0) {
+				b.Put(k)
+				refB.put(k)
+			}
+		}
+		for i := rnd.Intn(300); n%4 != 0 && i > 0; i-- {
+			k := randomKType(rnd)
+			b.Put(k)
+			refB.put(k)
+		}
+
+		// all the keys, and whether each is in a and in b
+		all := &refRedBlack{r: a}
+		for _, k := range append(append([]KType{}, refA.keys...), refB.keys...) {
+			all.put(k)
+		}
+		inA := make([]bool, len(all.keys))
+		inB := make([]bool, len(all.keys))
+		for i, k := range all.keys {
+			_, inA[i] = refA.has(k)
+			_, inB[i] = refB.has(k)
+		}
+
+		inPlace := func(op func(c *RedBlack)) func() *RedBlack {
+			return func() *RedBlack {
+				c := NewRedBlack()
+				c.UnionWith(a)
+				op(c)
+				return c
+			}
+		}
+		for _, op := range []struct {
+			name string
+			set  func() *RedBlack
+			keep func(inA, inB bool) bool
+		}{
+			{"union", func() *RedBlack { return a.Union(b) }, func(x, y bool) bool { return x || y }},
+			{"intersection", func() *RedBlack { return a.Intersection(b) }, func(x, y bool) bool { return x && y }},
+			{"difference", func() *RedBlack { return a.Difference(b) }, func(x, y bool) bool { return x && !y }},
+			{"symmetric difference", func() *RedBlack { return a.SymmetricDifference(b) }, func(x, y bool) bool { return x != y }},
+			{"union with", inPlace(func(c *RedBlack) { c.UnionWith(b) }), func(x, y bool) bool { return x || y }},
+			{"intersection with", inPlace(func(c *RedBlack) { c.IntersectionWith(b) }), func(x, y bool) bool { return x && y }},
+			{"difference with", inPlace(func(c *RedBlack) { c.DifferenceWith(b) }), func(x, y bool) bool { return x && !y }},
+			{"symmetric difference with", inPlace(func(c *RedBlack) { c.SymmetricDifferenceWith(b) }), func(x, y bool) bool { return x != y }},
+		} {
+			var want []KType
+			for i, k := range all.keys {
+				if op.keep(inA[i], inB[i]) {
+					want = append(want, k)
+				}
+			}
+			got := op.set()
+			checkRedBlack(t, got)
+			if got.Size() != len(want) {
+				t.Fatalf("%s: want %d keys, got %d", op.name, len(want), got.Size())
+			}
+			i := 0
+			got.Keys(func(k KType) bool {
+				if a.compare(want[i], k) != 0 {
+					t.Fatalf("%s: key %d: want %v, got %v", op.name, i, want[i], k)
+				}
+				i++
+				return true
+			})
+		}
+
+		subset, equal := true, len(refA.keys) == len(refB.keys)
+		for i := range all.keys {
+			subset = subset && (!inA[i] || inB[i])
+			equal = equal && inA[i] == inB[i]
+		}
+		if got := a.IsSubset(b); got != subset {
+			t.Fatalf("is subset: want %v, got %v", subset, got)
+		}
+		if got := a.Equal(b); got != equal {
+			t.Fatalf("equal: want %v, got %v", equal, got)
+		}
+	}
+}
+
+// checkRedBlackSlice verifies that r is a valid tree holding the keys of
+// ref, and that it exports them in order.
+func checkRedBlackSlice(t *testing.T, r *RedBlack, ref *refRedBlack) {
+	checkRedBlack(t, r)
+	if r.Size() != len(ref.keys) {
+		t.Fatalf("want size %d, got %d", len(ref.keys), r.Size())
+	}
+	keys := r.ToSlice()
+	if len(keys) != len(ref.keys) {
+		t.Fatalf("want %d keys, got %d", len(ref.keys), len(keys))
+	}
+	for i, k := range keys {
+		if r.compare(ref.keys[i], k) != 0 {
+			t.Fatalf("key %d: want %v, got %v", i, ref.keys[i], k)
+		}
+	}
+}
+
+func TestRedBlackFromSortedMatchesReference(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	for n := 0; n < 200; n++ {
+		ref := &refRedBlack{r: NewRedBlack()}
+		var keys []KType
+		for i := rnd.Intn(5*n + 1); i > 0; i-- {
+			k := randomKType(rnd)
+			if len(keys) != 0 && rnd.Intn(5) == 0 {
+				k = keys[rnd.Intn(len(keys))]
+			}
+			keys = append(keys, k)
+			ref.put(k)
+		}
+		given := append([]KType(nil), keys...)
+		checkRedBlackSlice(t, NewRedBlackFromUnsorted(keys), ref)
+		if !reflect.DeepEqual(given, keys) {
+			t.Fatalf("the keys given were modified: want %v, got %v", given, keys)
+		}
+
+		// the keys in order, some of them repeated
+		keys = keys[:0]
+		for _, k := range ref.keys {
+			for j := rnd.Intn(3); j >= 0; j-- {
+				keys = append(keys, k)
+			}
+		}
+		checkRedBlackSlice(t, NewRedBlackFromSorted(keys), ref)
+	}
+}
@@ -1,5 +1,7 @@
 package redblackbst
 
+import "sort"
+
 func (r RedBlack) compare(a, b KType) int { return a.Compare(b) }
 
 // RedBlack is a sorted set built on a left leaning red black balanced
@@ -11,6 +13,42 @@ type RedBlack struct {
 // NewRedBlack creates a sorted set.
 func NewRedBlack() *RedBlack { return &RedBlack{} }
 
+// NewRedBlackFromSorted creates a sorted set of the keys, given in order, in
+// O(n) rather than the O(n log n) of putting them one by one. Of equal keys,
+// the first one is kept, as putting them would. It panics if the keys aren't
+// in order.
+func NewRedBlackFromSorted(keys []KType) *RedBlack {
+	r := &RedBlack{}
+	var dups bool
+	for i := 1; i < len(keys); i++ {
+		cmp := r.compare(keys[i-1], keys[i])
+		if cmp > 0 {
+			panic("redblackbst: keys aren't sorted")
+		}
+		dups = dups || cmp == 0
+	}
+	if dups {
+		// not to modify the slice given
+		keys = r.dedup(append([]KType(nil), keys...))
+	}
+	r.root = r.build(keys)
+	return r
+}
+
+// NewRedBlackFromUnsorted creates a sorted set of the keys, sorting them
+// first, in O(n log n) but faster than putting them one by one. Of equal
+// keys, the first one is kept, as putting them would. The slice isn't
+// modified.
+func NewRedBlackFromUnsorted(keys []KType) *RedBlack {
+	r := &RedBlack{}
+	sorted := append([]KType(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return r.compare(sorted[i], sorted[j]) < 0
+	})
+	r.root = r.build(r.dedup(sorted))
+	return r
+}
+
 // IsEmpty tells if the sorted set contains no key.
 func (r RedBlack) IsEmpty() bool {
 	return r.root == nil
@@ -26,6 +64,7 @@ func (r *RedBlack) Clear() { r.root = nil }
 // true is returned.
 func (r *RedBlack) Put(k KType) (already bool) {
 	r.root, already = r.put(r.root, k)
+	r.root.colorRed = false
 	return
 }
 
@@ -109,7 +148,7 @@ func (r RedBlack) max(x *treenode) *treenode {
 }
 
 // Floor returns the largest key in the sorted set that is smaller than
-// `k`.
+// or equal to `k`, if it exists.
 func (r RedBlack) Floor(key KType) (k KType, ok bool) {
 	x := r.floor(r.root, key)
 	if x == nil {
@@ -137,7 +176,7 @@ func (r RedBlack) floor(h *treenode, k KType) *treenode {
 }
 
 // Ceiling returns the smallest key in the sorted set that is larger than
-// `k`.
+// or equal to `k`, if it exists.
 func (r RedBlack) Ceiling(key KType) (k KType, ok bool) {
 	x := r.ceiling(r.root, key)
 	if x == nil {
@@ -164,6 +203,54 @@ func (r RedBlack) ceiling(h *treenode, k KType) *treenode {
 	return h
 }
 
+// Lower returns the largest key in the sorted set that is strictly
+// smaller than `k`, its predecessor, if it exists.
+func (r RedBlack) Lower(key KType) (k KType, ok bool) {
+	x := r.lower(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, true
+}
+
+func (r RedBlack) lower(h *treenode, k KType) *treenode {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) <= 0 {
+		return r.lower(h.left, k)
+	}
+	t := r.lower(h.right, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
+// Higher returns the smallest key in the sorted set that is strictly
+// larger than `k`, its successor, if it exists.
+func (r RedBlack) Higher(key KType) (k KType, ok bool) {
+	x := r.higher(r.root, key)
+	if x == nil {
+		return
+	}
+	return x.key, true
+}
+
+func (r RedBlack) higher(h *treenode, k KType) *treenode {
+	if h == nil {
+		return nil
+	}
+	if r.compare(k, h.key) >= 0 {
+		return r.higher(h.right, k)
+	}
+	t := r.higher(h.left, k)
+	if t != nil {
+		return t
+	}
+	return h
+}
+
 // Select key of rank k, meaning the k-th biggest KType in the sorted set.
 func (r RedBlack) Select(key int) (k KType, ok bool) {
 	x := r.nodeselect(r.root, key)
@@ -248,6 +335,136 @@ func (r RedBlack) keys(h *treenode, visit func(KType) bool, lo, hi KType) bool {
 	return true
 }
 
+// ReverseKeys visit each keys in the sorted set, in reverse order.
+// It stops when visit returns false.
+func (r RedBlack) ReverseKeys(visit func(KType) bool) {
+	min, ok := r.Min()
+	if !ok {
+		return
+	}
+	// if the min exists, then the max must exist
+	max, _ := r.Max()
+	r.RangedKeysDesc(min, max, visit)
+}
+
+// RangedKeysDesc visit each keys between lo and hi in the sorted set, in
+// reverse order. It stops when visit returns false.
+func (r RedBlack) RangedKeysDesc(lo, hi KType, visit func(KType) bool) {
+	r.keysDesc(r.root, visit, lo, hi)
+}
+
+func (r RedBlack) keysDesc(h *treenode, visit func(KType) bool, lo, hi KType) bool {
+	if h == nil {
+		return true
+	}
+	cmplo := r.compare(lo, h.key)
+	cmphi := r.compare(hi, h.key)
+	if cmphi > 0 {
+		if !r.keysDesc(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	if cmplo <= 0 && cmphi >= 0 {
+		if !visit(h.key) {
+			return false
+		}
+	}
+	if cmplo < 0 {
+		if !r.keysDesc(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
+// Bound is an end of a range of keys: its Key, included in the range unless
+// it's Exclusive. An Unbounded end doesn't limit the range, whatever its Key.
+type Bound struct {
+	Key       KType
+	Exclusive bool
+	Unbounded bool
+}
+
+// RangeCount is the number of keys between lo and hi in the sorted set. It
+// doesn't visit them, and takes O(log n).
+func (r RedBlack) RangeCount(lo, hi Bound) int {
+	n := r.boundrank(hi, true) - r.boundrank(lo, false)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// boundrank is the number of keys below b, or up to b for an upper bound.
+func (r RedBlack) boundrank(b Bound, upper bool) int {
+	if b.Unbounded {
+		if upper {
+			return r.Size()
+		}
+		return 0
+	}
+	// the key itself is below an exclusive lower bound or an inclusive
+	// upper bound
+	return r.keyrankBound(b.Key, r.root, upper != b.Exclusive)
+}
+
+func (r RedBlack) keyrankBound(k KType, h *treenode, withKey bool) int {
+	if h == nil {
+		return 0
+	}
+	cmp := r.compare(k, h.key)
+	if cmp < 0 {
+		return r.keyrankBound(k, h.left, withKey)
+	} else if cmp > 0 {
+		return 1 + h.left.size() + r.keyrankBound(k, h.right, withKey)
+	} else if withKey {
+		return h.left.size() + 1
+	} else {
+		return h.left.size()
+	}
+}
+
+// BoundedKeys visit each keys between lo and hi in the sorted set, in order.
+// It stops when visit returns false.
+func (r RedBlack) BoundedKeys(lo, hi Bound, visit func(KType) bool) {
+	r.boundedKeys(r.root, visit, lo, hi)
+}
+
+func (r RedBlack) boundedKeys(h *treenode, visit func(KType) bool, lo, hi Bound) bool {
+	if h == nil {
+		return true
+	}
+	// whether the keys left and right of h can be in the range
+	left, right := true, true
+	inside := true
+	if !lo.Unbounded {
+		cmp := r.compare(lo.Key, h.key)
+		left = cmp < 0
+		inside = cmp < 0 || (cmp == 0 && !lo.Exclusive)
+	}
+	if !hi.Unbounded {
+		cmp := r.compare(hi.Key, h.key)
+		right = cmp > 0
+		inside = inside && (cmp > 0 || (cmp == 0 && !hi.Exclusive))
+	}
+	if left {
+		if !r.boundedKeys(h.left, visit, lo, hi) {
+			return false
+		}
+	}
+	if inside {
+		if !visit(h.key) {
+			return false
+		}
+	}
+	if right {
+		if !r.boundedKeys(h.right, visit, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
 // DeleteMin removes the smallest key from the sorted set.
 func (r *RedBlack) DeleteMin() (oldk KType, ok bool) {
 	r.root, oldk, ok = r.deleteMin(r.root)
@@ -356,6 +573,213 @@ func (r *RedBlack) delete(h *treenode, k KType) (_ *treenode, ok bool) {
 	return h, ok
 }
 
+// ToSlice returns the keys of the sorted set, in order.
+func (r RedBlack) ToSlice() []KType {
+	keys := make([]KType, 0, r.Size())
+	r.Keys(func(k KType) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+// DeleteRange removes the keys between lo and hi from the sorted set,
+// and returns how many it removed. It takes O(m log n) to remove m keys, or
+// O(n) when the range is a large part of the sorted set, which is then
+// rebuilt out of the keys left.
+func (r *RedBlack) DeleteRange(lo, hi Bound) int {
+	from, n, size := r.boundrank(lo, false), r.RangeCount(lo, hi), r.Size()
+	depth := 0
+	for s := size; s > 1; s /= 2 {
+		depth++
+	}
+	if n*depth < size {
+		for i := 0; i < n; i++ {
+			// the keys after those removed take their ranks
+			r.Delete(r.nodeselect(r.root, from).key)
+		}
+		return n
+	}
+
+	keys := make([]KType, 0, size-n)
+	i := 0
+	r.Keys(func(k KType) bool {
+		if i < from || i >= from+n {
+			keys = append(keys, k)
+		}
+		i++
+		return true
+	})
+	r.root = r.build(keys)
+	return n
+}
+
+// set algebra
+
+// Union returns a new sorted set of the keys in either r or other, which must
+// order its keys like r. It takes O(n+m).
+func (r RedBlack) Union(other *RedBlack) *RedBlack {
+	return r.with(r.merge(other, true, true, true))
+}
+
+// Intersection returns a new sorted set of the keys in both r and other,
+// which must order its keys like r. It takes O(n+m).
+func (r RedBlack) Intersection(other *RedBlack) *RedBlack {
+	return r.with(r.merge(other, false, true, false))
+}
+
+// Difference returns a new sorted set of the keys in r but not in other,
+// which must order its keys like r. It takes O(n+m).
+func (r RedBlack) Difference(other *RedBlack) *RedBlack {
+	return r.with(r.merge(other, true, false, false))
+}
+
+// SymmetricDifference returns a new sorted set of the keys in either r or
+// other but not in both, other ordering its keys like r. It takes O(n+m).
+func (r RedBlack) SymmetricDifference(other *RedBlack) *RedBlack {
+	return r.with(r.merge(other, true, false, true))
+}
+
+// UnionWith puts the keys of other in the sorted set, like Union but in
+// place.
+func (r *RedBlack) UnionWith(other *RedBlack) {
+	r.root = r.build(r.merge(other, true, true, true))
+}
+
+// IntersectionWith deletes the keys that aren't in other from the sorted set,
+// like Intersection but in place.
+func (r *RedBlack) IntersectionWith(other *RedBlack) {
+	r.root = r.build(r.merge(other, false, true, false))
+}
+
+// DifferenceWith deletes the keys of other from the sorted set, like
+// Difference but in place.
+func (r *RedBlack) DifferenceWith(other *RedBlack) {
+	r.root = r.build(r.merge(other, true, false, false))
+}
+
+// SymmetricDifferenceWith deletes the keys of other from the sorted set, and
+// puts those it didn't have, like SymmetricDifference but in place.
+func (r *RedBlack) SymmetricDifferenceWith(other *RedBlack) {
+	r.root = r.build(r.merge(other, true, false, true))
+}
+
+// IsSubset tells if every key of the sorted set is in other, which must
+// order its keys like r. It takes O(n+m).
+func (r RedBlack) IsSubset(other *RedBlack) bool {
+	if r.Size() > other.Size() {
+		return false
+	}
+	return len(r.merge(other, true, false, false)) == 0
+}
+
+// Equal tells if the sorted set and other have the same keys, other ordering
+// its keys like r. It takes O(n).
+func (r RedBlack) Equal(other *RedBlack) bool {
+	if r.Size() != other.Size() {
+		return false
+	}
+	a, b := r.NewCursor(), other.NewCursor()
+	for ok := a.First() && b.First(); ok; ok = a.Next() && b.Next() {
+		if r.compare(a.Key(), b.Key()) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// merge walks the keys of r and other in order, and returns those only in
+// r, in both or only in other, as asked.
+func (r RedBlack) merge(other *RedBlack, onlyR, both, onlyOther bool) []KType {
+	var keys []KType
+	a, b := r.NewCursor(), other.NewCursor()
+	a.First()
+	b.First()
+	for a.Valid() || b.Valid() {
+		if (!a.Valid() && !onlyOther) || (!b.Valid() && !onlyR) {
+			break
+		}
+		cmp := 0
+		if !a.Valid() {
+			cmp = 1
+		} else if !b.Valid() {
+			cmp = -1
+		} else {
+			cmp = r.compare(a.Key(), b.Key())
+		}
+		if cmp < 0 {
+			if onlyR {
+				keys = append(keys, a.Key())
+			}
+			a.Next()
+		} else if cmp > 0 {
+			if onlyOther {
+				keys = append(keys, b.Key())
+			}
+			b.Next()
+		} else {
+			if both {
+				keys = append(keys, a.Key())
+			}
+			a.Next()
+			b.Next()
+		}
+	}
+	return keys
+}
+
+// with returns a sorted set ordered like r, holding the sorted keys.
+func (r RedBlack) with(keys []KType) *RedBlack {
+	r.root = r.build(keys)
+	return &r
+}
+
+// construction
+
+// dedup removes the duplicates from the sorted keys in place, keeping the
+// first of equal keys, as putting them would.
+func (r RedBlack) dedup(keys []KType) []KType {
+	n := 0
+	for i := range keys {
+		if n > 0 && r.compare(keys[n-1], keys[i]) == 0 {
+			continue
+		}
+		keys[n] = keys[i]
+		n++
+	}
+	return keys[:n]
+}
+
+// build returns the root of a tree holding the sorted keys, in O(n). The tree
+// is as balanced as can be, with the nodes of its last level red if it's not
+// full, and is fixed bottom up so that its red links lean left.
+func (r *RedBlack) build(keys []KType) *treenode {
+	if len(keys) == 0 {
+		return nil
+	}
+	// the depth of the last level, counting from 0, if it's not full
+	redDepth := -1
+	if n := len(keys); n&(n+1) != 0 {
+		for redDepth = 0; n > 1; n /= 2 {
+			redDepth++
+		}
+	}
+	root := r.buildNode(keys, 0, redDepth)
+	root.colorRed = false
+	return root
+}
+
+func (r *RedBlack) buildNode(keys []KType, depth, redDepth int) *treenode {
+	if len(keys) == 0 {
+		return nil
+	}
+	mid := len(keys) / 2
+	h := &treenode{key: keys[mid], colorRed: depth == redDepth}
+	h.left = r.buildNode(keys[:mid], depth+1, redDepth)
+	h.right = r.buildNode(keys[mid+1:], depth+1, redDepth)
+	return r.balance(h)
+}
+
 // deletions
 
 func (r *RedBlack) moveRedLeft(h *treenode) *treenode {
@@ -419,6 +843,123 @@ func (r *RedBlack) flipColors(h *treenode) {
 	h.right.colorRed = !h.right.colorRed
 }
 
+// cursors
+
+// Cursor is a position among the keys of a sorted set, moved from key to key
+// in either order. Unlike Keys, it can be paused, or moved along the cursor
+// of another sorted set. It's only valid until the sorted set is modified.
+type Cursor struct {
+	r RedBlack
+	// path from the root to the node under the cursor, empty when the cursor
+	// isn't on a key
+	path []*treenode
+}
+
+// NewCursor returns a cursor of the sorted set, which isn't on a key until it's
+// moved with First, Last or Seek.
+func (r RedBlack) NewCursor() *Cursor { return &Cursor{r: r} }
+
+// Valid tells if the cursor is on a key.
+func (c *Cursor) Valid() bool { return len(c.path) != 0 }
+
+// First moves the cursor on the smallest key, and tells if there is one.
+func (c *Cursor) First() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.left {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Last moves the cursor on the largest key, and tells if there is one.
+func (c *Cursor) Last() bool {
+	c.path = c.path[:0]
+	for h := c.r.root; h != nil; h = h.right {
+		c.path = append(c.path, h)
+	}
+	return c.Valid()
+}
+
+// Seek moves the cursor on the smallest key larger than or equal to `k`, and
+// tells if there is one.
+func (c *Cursor) Seek(k KType) bool {
+	c.path = c.path[:0]
+	// the ceiling is the last node left of which the search goes
+	ceiling := 0
+	for h := c.r.root; h != nil; {
+		c.path = append(c.path, h)
+		cmp := c.r.compare(k, h.key)
+		if cmp == 0 {
+			return true
+		} else if cmp < 0 {
+			ceiling = len(c.path)
+			h = h.left
+		} else {
+			h = h.right
+		}
+	}
+	c.path = c.path[:ceiling]
+	return c.Valid()
+}
+
+// Next moves the cursor on the following key, and tells if there is one.
+// Once past the largest key, the cursor isn't on a key anymore.
+func (c *Cursor) Next() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].right; h != nil {
+		for ; h != nil; h = h.left {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the left of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].left == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Prev moves the cursor on the preceding key, and tells if there is one.
+// Once past the smallest key, the cursor isn't on a key anymore.
+func (c *Cursor) Prev() bool {
+	if !c.Valid() {
+		return false
+	}
+	if h := c.path[len(c.path)-1].left; h != nil {
+		for ; h != nil; h = h.right {
+			c.path = append(c.path, h)
+		}
+		return true
+	}
+	// up to the first node the path comes from the right of
+	for n := len(c.path) - 1; n > 0; n-- {
+		if c.path[n-1].right == c.path[n] {
+			c.path = c.path[:n]
+			return true
+		}
+	}
+	c.path = c.path[:0]
+	return false
+}
+
+// Key under the cursor. It panics if the cursor isn't on a key.
+func (c *Cursor) Key() KType {
+	return c.node().key
+}
+
+func (c *Cursor) node() *treenode {
+	if !c.Valid() {
+		panic("redblackbst: cursor isn't on a key")
+	}
+	return c.path[len(c.path)-1]
+}
+
 // nodes
 
 type treenode struct {
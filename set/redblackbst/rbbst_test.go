@@ -345,6 +345,45 @@ func TestGetCorrectCeiling(t *testing.T) {
 	}
 }
 
+func TestGetCorrectLowerAndHigher(t *testing.T) {
+	tree := NewRedBlack()
+	// none means there's no lower or higher key
+	const none = -1
+	check := func(tests []struct{ k, lower, higher int }) {
+		t.Helper()
+		for _, tt := range tests {
+			if k, ok := tree.Lower(Int(tt.k)); ok != (tt.lower != none) || ok && k != Int(tt.lower) {
+				t.Errorf("lower %d: want %d, got %v, %v", tt.k, tt.lower, k, ok)
+			}
+			if k, ok := tree.Higher(Int(tt.k)); ok != (tt.higher != none) || ok && k != Int(tt.higher) {
+				t.Errorf("higher %d: want %d, got %v, %v", tt.k, tt.higher, k, ok)
+			}
+		}
+	}
+
+	check([]struct{ k, lower, higher int }{{0, none, none}})
+
+	tree.Put(Int(1))
+	check([]struct{ k, lower, higher int }{
+		{0, none, 1},
+		{1, none, none},
+		{2, 1, none},
+	})
+
+	for i := 0; i < 200; i += 2 {
+		tree.Put(Int(i))
+	}
+	check([]struct{ k, lower, higher int }{
+		{0, none, 1},
+		{1, 0, 2},
+		{50, 48, 52},
+		{51, 50, 52},
+		{198, 196, none},
+		{199, 198, none},
+		{1000, 198, none},
+	})
+}
+
 func TestGetCorrectSelect(t *testing.T) {
 
 	tree := NewRedBlack()
@@ -549,6 +588,250 @@ func TestCanVisitEmptyTree(t *testing.T) {
 
 }
 
+func TestCanVisitAllKeysInReverse(t *testing.T) {
+	tree := NewRedBlack()
+	want := []Int{}
+	for i := 0; i < 100; i++ {
+		want = append([]Int{Int(i)}, want...)
+		tree.Put(Int(i))
+	}
+
+	got := []Int{}
+	tree.ReverseKeys(func(k KType) bool {
+		got = append(got, k.(Int))
+		return true
+	})
+
+	if !reflect.DeepEqual(want, got) {
+		t.Logf("want=%#v", want)
+		t.Logf(" got=%#v", got)
+		t.Errorf("mismatch!")
+	}
+
+	got = []Int{}
+	tree.RangedKeysDesc(Int(10), Int(19), func(k KType) bool {
+		got = append(got, k.(Int))
+		return len(got) < 5
+	})
+	if want := []Int{19, 18, 17, 16, 15}; !reflect.DeepEqual(want, got) {
+		t.Logf("want=%#v", want)
+		t.Logf(" got=%#v", got)
+		t.Errorf("mismatch!")
+	}
+}
+
+func TestCanMergeWithCursors(t *testing.T) {
+	evens, odds := NewRedBlack(), NewRedBlack()
+	want := []Int{}
+	for i := 0; i < 100; i += 2 {
+		evens.Put(Int(i))
+		odds.Put(Int(i + 1))
+		want = append(want, Int(i), Int(i+1))
+	}
+
+	got := []Int{}
+	e, o := evens.NewCursor(), odds.NewCursor()
+	e.First()
+	o.First()
+	for e.Valid() || o.Valid() {
+		if !o.Valid() || (e.Valid() && e.Key().Compare(o.Key()) < 0) {
+			got = append(got, e.Key().(Int))
+			e.Next()
+		} else {
+			got = append(got, o.Key().(Int))
+			o.Next()
+		}
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Logf("want=%#v", want)
+		t.Logf(" got=%#v", got)
+		t.Errorf("mismatch!")
+	}
+}
+
+func TestCursorOnEmptyTree(t *testing.T) {
+	tree := NewRedBlack()
+	c := tree.NewCursor()
+	if c.First() || c.Last() || c.Seek(Int(0)) || c.Next() || c.Prev() {
+		t.Fatalf("cursor moved on a key of an empty tree")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("should panic when the cursor isn't on a key")
+		}
+	}()
+	c.Key()
+}
+
+func TestCanCountAndDeleteRanges(t *testing.T) {
+	tree := NewRedBlack()
+	for i := 0; i < 100; i++ {
+		tree.Put(Int(i))
+	}
+
+	for _, tt := range []struct {
+		lo, hi Bound
+		want   int
+	}{
+		{Bound{Key: Int(10)}, Bound{Key: Int(19)}, 10},
+		{Bound{Key: Int(10), Exclusive: true}, Bound{Key: Int(19)}, 9},
+		{Bound{Key: Int(10)}, Bound{Key: Int(19), Exclusive: true}, 9},
+		{Bound{Key: Int(10), Exclusive: true}, Bound{Key: Int(11), Exclusive: true}, 0},
+		{Bound{Unbounded: true}, Bound{Key: Int(9)}, 10},
+		{Bound{Key: Int(90)}, Bound{Unbounded: true}, 10},
+		{Bound{Unbounded: true}, Bound{Unbounded: true}, 100},
+		{Bound{Key: Int(19)}, Bound{Key: Int(10)}, 0},
+		{Bound{Key: Int(-5)}, Bound{Key: Int(500)}, 100},
+	} {
+		if got := tree.RangeCount(tt.lo, tt.hi); got != tt.want {
+			t.Errorf("range count %v, %v: want %d, got %d", tt.lo, tt.hi, tt.want, got)
+		}
+		got := 0
+		tree.BoundedKeys(tt.lo, tt.hi, func(k KType) bool {
+			got++
+			return true
+		})
+		if got != tt.want {
+			t.Errorf("bounded keys %v, %v: want %d keys, got %d", tt.lo, tt.hi, tt.want, got)
+		}
+	}
+
+	if n := tree.DeleteRange(Bound{Key: Int(10), Exclusive: true}, Bound{Key: Int(20)}); n != 10 {
+		t.Fatalf("want 10 keys deleted, got %d", n)
+	}
+	for i := 0; i < 100; i++ {
+		if want := i <= 10 || i > 20; tree.Contains(Int(i)) != want {
+			t.Errorf("has %d: want %v", i, want)
+		}
+	}
+	if n := tree.DeleteRange(Bound{Unbounded: true}, Bound{Unbounded: true}); n != 90 || !tree.IsEmpty() {
+		t.Fatalf("want 90 keys deleted and an empty tree, got %d and %d keys", n, tree.Size())
+	}
+}
+
+func TestSetAlgebra(t *testing.T) {
+	set := func(keys ...int) *RedBlack {
+		tree := NewRedBlack()
+		for _, k := range keys {
+			tree.Put(Int(k))
+		}
+		return tree
+	}
+	keys := func(tree *RedBlack) []Int {
+		got := []Int{}
+		tree.Keys(func(k KType) bool {
+			got = append(got, k.(Int))
+			return true
+		})
+		return got
+	}
+	a, b := set(1, 2, 3, 4), set(3, 4, 5)
+
+	for _, tt := range []struct {
+		name string
+		got  *RedBlack
+		want []Int
+	}{
+		{"union", a.Union(b), []Int{1, 2, 3, 4, 5}},
+		{"intersection", a.Intersection(b), []Int{3, 4}},
+		{"difference", a.Difference(b), []Int{1, 2}},
+		{"symmetric difference", a.SymmetricDifference(b), []Int{1, 2, 5}},
+		{"union with empty", a.Union(set()), []Int{1, 2, 3, 4}},
+		{"intersection with empty", a.Intersection(set()), []Int{}},
+	} {
+		if got := keys(tt.got); !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("%s: want %v, got %v", tt.name, tt.want, got)
+		}
+	}
+	if got := keys(a); !reflect.DeepEqual([]Int{1, 2, 3, 4}, got) {
+		t.Errorf("the operations modified their set: %v", got)
+	}
+
+	a.DifferenceWith(b)
+	if got := keys(a); !reflect.DeepEqual([]Int{1, 2}, got) {
+		t.Errorf("difference with: want [1 2], got %v", got)
+	}
+	a.UnionWith(b)
+	if got := keys(a); !reflect.DeepEqual([]Int{1, 2, 3, 4, 5}, got) {
+		t.Errorf("union with: want [1 2 3 4 5], got %v", got)
+	}
+
+	if !b.IsSubset(a) || a.IsSubset(b) || !set().IsSubset(b) {
+		t.Errorf("is subset: want %v ⊆ %v only", keys(b), keys(a))
+	}
+	if !a.Equal(set(5, 4, 3, 2, 1)) || a.Equal(b) || !set().Equal(set()) {
+		t.Errorf("equal: want %v equal to the same keys only", keys(a))
+	}
+}
+
+func TestCanDeleteMostOfTheKeys(t *testing.T) {
+	tree := NewRedBlack()
+	for i := 0; i < 100000; i++ {
+		tree.Put(Int(i))
+	}
+	// the tree is rebuilt out of the keys left
+	if n := tree.DeleteRange(Bound{Key: Int(10)}, Bound{Key: Int(99990), Exclusive: true}); n != 99980 {
+		t.Fatalf("want 99980 keys deleted, got %d", n)
+	}
+	checkRedBlack(t, tree)
+	var want []KType
+	for i := 0; i < 100000; i++ {
+		if i < 10 || i >= 99990 {
+			want = append(want, Int(i))
+		}
+	}
+	if got := tree.ToSlice(); !reflect.DeepEqual(want, got) {
+		t.Fatalf("want keys %v, got %v", want, got)
+	}
+
+	// and a few keys are deleted in place
+	if n := tree.DeleteRange(Bound{Key: Int(5)}, Bound{Key: Int(6)}); n != 2 {
+		t.Fatalf("want 2 keys deleted, got %d", n)
+	}
+	checkRedBlack(t, tree)
+	if tree.Contains(Int(5)) || tree.Contains(Int(6)) || !tree.Contains(Int(7)) || tree.Size() != 18 {
+		t.Errorf("want 5 and 6 deleted, and 18 keys left, got %v", tree.ToSlice())
+	}
+}
+
+func TestCanBuildFromSlices(t *testing.T) {
+	want := []KType{Int(1), Int(2), Int(3)}
+	for _, tt := range []struct {
+		name string
+		tree *RedBlack
+	}{
+		{"from sorted", NewRedBlackFromSorted([]KType{Int(1), Int(2), Int(2), Int(3)})},
+		{"from unsorted", NewRedBlackFromUnsorted([]KType{Int(3), Int(2), Int(1), Int(2)})},
+	} {
+		if got := tt.tree.ToSlice(); !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: want %v, got %v", tt.name, want, got)
+		}
+	}
+	if tree := NewRedBlackFromUnsorted(nil); !tree.IsEmpty() || len(tree.ToSlice()) != 0 {
+		t.Errorf("want an empty tree, got %d keys", tree.Size())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("should panic when the keys aren't sorted")
+		}
+	}()
+	NewRedBlackFromSorted([]KType{Int(2), Int(1)})
+}
+
+// Put used to leave a red root, which later puts could follow with another
+// red link.
+func TestPutLeavesRootBlack(t *testing.T) {
+	tree := NewRedBlack()
+	for i := 0; i < 10; i++ {
+		tree.Put(Int(i))
+		if tree.root.isRed() {
+			t.Fatalf("root is red after putting %d", i)
+		}
+	}
+}
+
 func TestCanExportToDot(t *testing.T) {
 	tree := NewRedBlack()
 	for i := 0; i < 100; i++ {
@@ -20,6 +20,35 @@ for i in "int" "float64" "string" "[]byte" "[]string"; do
     rm gen_smap.go
 done
 
+echo "!! Verifying code generated for persistent sorted map"
+for i in "int" "float64" "string" "[]byte" "[]string"; do
+    echo " -key=$i -val=$i -persistent"
+
+    go run cmd/datagen/*.go smap -key=$i -val=$i -persistent > gen_pmap.go 2>/dev/null
+    go build gen_pmap.go || rm gen_pmap.go
+    go vet gen_pmap.go || rm gen_pmap.go
+    golint gen_pmap.go || rm gen_pmap.go
+    rm gen_pmap.go
+done
+
+echo "!! Verifying code generated for augmented sorted map"
+echo " -key=float64 -val=float64 -aggregate=float64 -measure=math.Max -combine=math.Max"
+go run cmd/datagen/*.go smap -key=float64 -val=float64 -aggregate=float64 -measure=math.Max -combine=math.Max > gen_amap.go 2>/dev/null
+go build gen_amap.go || rm gen_amap.go
+go vet gen_amap.go || rm gen_amap.go
+golint gen_amap.go || rm gen_amap.go
+rm gen_amap.go
+
+echo "!! Verifying code generated for sorted multimap"
+for i in "int" "float64" "string" "[]byte" "[]string"; do
+    echo " -key=$i -val=$i"
+    go run cmd/datagen/*.go smultimap -key=$i -val=$i > gen_smultimap.go 2>/dev/null
+    go build gen_smultimap.go || rm gen_smultimap.go
+    go vet gen_smultimap.go || rm gen_smultimap.go
+    golint gen_smultimap.go || rm gen_smultimap.go
+    rm gen_smultimap.go
+done
+
 echo "!! Verifying code generated for sorted set"
 for i in "int" "float64" "string" "[]byte" "[]string"; do
     echo " -key=$i"
@@ -30,6 +59,26 @@ for i in "int" "float64" "string" "[]byte" "[]string"; do
     rm gen_sset.go
 done
 
+echo "!! Verifying code generated for sorted multiset"
+for i in "int" "float64" "string" "[]byte" "[]string"; do
+    echo " -key=$i"
+    go run cmd/datagen/*.go smultiset -key=$i > gen_smultiset.go 2>/dev/null
+    go build gen_smultiset.go || rm gen_smultiset.go
+    go vet gen_smultiset.go || rm gen_smultiset.go
+    golint gen_smultiset.go || rm gen_smultiset.go
+    rm gen_smultiset.go
+done
+
+echo "!! Verifying code generated for interval tree"
+for i in "int" "float64" "string" "[]byte" "[]string"; do
+    echo " -key=$i -val=$i"
+    go run cmd/datagen/*.go itree -key=$i -val=$i > gen_itree.go 2>/dev/null
+    go build gen_itree.go || rm gen_itree.go
+    go vet gen_itree.go || rm gen_itree.go
+    golint gen_itree.go || rm gen_itree.go
+    rm gen_itree.go
+done
+
 echo "!! Verifying code generated for heap"
 for i in "int" "float64" "string" "[]byte" "[]string"; do
     echo " -key=$i"
@@ -40,6 +89,16 @@ for i in "int" "float64" "string" "[]byte" "[]string"; do
     rm gen_heap.go
 done
 
+echo "!! Verifying code generated for indexed heap"
+for i in "int" "float64" "string" "[]byte" "[]string"; do
+    echo " -key=$i -id=string"
+    go run cmd/datagen/*.go iheap -key=$i -id=string > gen_iheap.go 2>/dev/null
+    go build gen_iheap.go || rm gen_iheap.go
+    go vet gen_iheap.go || rm gen_iheap.go
+    golint gen_iheap.go || rm gen_iheap.go
+    rm gen_iheap.go
+done
+
 echo "!! Verifying code generated for queue"
 for i in "int" "float64" "string" "[]byte" "[]string"; do
     echo " -key=$i"
@@ -50,6 +109,58 @@ for i in "int" "float64" "string" "[]byte" "[]string"; do
     rm gen_queue.go
 done
 
+echo "!! Verifying code generated for bounded queue"
+for i in "int" "float64" "string" "[]byte" "[]string"; do
+    echo " -key=$i -bounded"
+    go run cmd/datagen/*.go queue -key=$i -bounded > gen_bqueue.go 2>/dev/null
+    go build gen_bqueue.go || rm gen_bqueue.go
+    go vet gen_bqueue.go || rm gen_bqueue.go
+    golint gen_bqueue.go || rm gen_bqueue.go
+    rm gen_bqueue.go
+done
+
+echo "!! Verifying code generated for deque"
+for i in "int" "float64" "string" "[]byte" "[]string"; do
+    echo " -key=$i"
+    go run cmd/datagen/*.go deque -key=$i > gen_deque.go 2>/dev/null
+    go build gen_deque.go || rm gen_deque.go
+    go vet gen_deque.go || rm gen_deque.go
+    golint gen_deque.go || rm gen_deque.go
+    rm gen_deque.go
+done
+
+echo "!! Verifying code generated for blocking queue"
+for i in "int" "float64" "string" "[]byte" "[]string"; do
+    echo " -key=$i -blocking"
+    go run cmd/datagen/*.go queue -key=$i -blocking > gen_blocking.go 2>/dev/null
+    go build gen_blocking.go || rm gen_blocking.go
+    go vet gen_blocking.go || rm gen_blocking.go
+    golint gen_blocking.go || rm gen_blocking.go
+    rm gen_blocking.go
+done
+
+echo "!! Verifying code generated for ring queues"
+for i in "int" "float64" "string" "[]byte" "[]string"; do
+    for flavor in "spsc" "mpmc"; do
+        echo " -key=$i -flavor=$flavor"
+        go run cmd/datagen/*.go ringq -key=$i -flavor=$flavor > gen_ringq.go 2>/dev/null
+        go build gen_ringq.go || rm gen_ringq.go
+        go vet gen_ringq.go || rm gen_ringq.go
+        golint gen_ringq.go || rm gen_ringq.go
+        rm gen_ringq.go
+    done
+done
+
+echo "!! Verifying sync wrappers"
+for cmd in "smap -val=string" "smap -val=string -persistent" "smultimap -val=string" "sset" "smultiset" "itree -val=string" "heap" "iheap -id=string" "queue" "queue -bounded" "deque"; do
+    echo " $cmd -key=int -sync"
+    go run cmd/datagen/*.go $cmd -key=int -sync > gen_sync.go 2>/dev/null
+    go build gen_sync.go || rm gen_sync.go
+    go vet gen_sync.go || rm gen_sync.go
+    golint gen_sync.go || rm gen_sync.go
+    rm gen_sync.go
+done
+
 pushd codegen
 echo "!! Generating benchmarked sorted maps"
 go run ../cmd/datagen/*.go smap -key string  -val string > smap_string_string.go